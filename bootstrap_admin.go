@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/admin"
+	admindb "github.com/Gkemhcs/taskpilot/internal/admin/gen"
+)
+
+// bootstrapAdminCommand is the subcommand that appoints the first administrator.
+const bootstrapAdminCommand = "bootstrap-admin"
+
+// bootstrapAdmin promotes the user with the email given in args to administrator.
+// It only works while the instance has no administrator; later admins are
+// appointed through POST /api/v1/admin/users/{id}/admin.
+func bootstrapAdmin(dbConn *sql.DB, args []string) error {
+	if len(args) != 1 || args[0] == "" {
+		return errors.New("usage: taskpilot " + bootstrapAdminCommand + " <email>")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// users are not protected by row-level security, so the plain connection is enough
+	return admin.NewAdminService(admindb.New(dbConn)).BootstrapAdmin(ctx, args[0])
+}
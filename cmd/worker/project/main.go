@@ -8,6 +8,9 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
 
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	database "github.com/Gkemhcs/taskpilot/internal/db"
 	"github.com/Gkemhcs/taskpilot/internal/exporter"
 	exporterdb "github.com/Gkemhcs/taskpilot/internal/exporter/gen"
 	"github.com/Gkemhcs/taskpilot/internal/importer"
@@ -78,20 +81,23 @@ func main() {
 		logger.Fatalf("❌ Failed to initialize storage: %v", err)
 	}
 
-	// Set up dependencies for project import/export
-	projectRepo := projectdb.New(db)
-	projectService := project.NewProjectService(projectRepo)
-	importRepo := importerdb.New(db)
+	// Set up dependencies for project import/export; repositories query through
+	// the tenant wrapper so every job only sees the rows of the user who queued it
+	tenantDB := database.NewTenantDB(db)
+	projectRepo := projectdb.New(tenantDB)
+	projectService := project.NewProjectService(projectRepo, authz.NewAuthorizationService(authzdb.New(tenantDB)))
+	projectService.UseTransactions(tenantDB)
+	importRepo := importerdb.New(tenantDB)
 	expectedHeaders := []string{"name", "description", "color"}
 
 	// Handler for each row in the imported Excel file
-	rowHandler := func(data map[string]string, userID int) error {
-		ctx := context.Background()
+	rowHandler := func(ctx context.Context, data map[string]string, userID int, orgID int) error {
 		project := project.Project{
-			Name:        data["name"],
-			Description: data["description"],
-			Color:       data["color"],
-			User:        int(userID), // set properly below
+			Name:         data["name"],
+			Description:  data["description"],
+			Color:        data["color"],
+			User:         int(userID), // set properly below
+			Organization: orgID,
 		}
 		_, err := projectService.CreateProject(ctx, project)
 		if err != nil {
@@ -104,7 +110,7 @@ func main() {
 	excelImporter := importer.NewExcelImporter(expectedHeaders, rowHandler)
 	sheetName := "projects"
 	excelExporter := exporter.NewExcelExporter(expectedHeaders, sheetName)
-	exportRepo := exporterdb.New(db)
+	exportRepo := exporterdb.New(tenantDB)
 	localDir := cfg.StorageConfig.ProcessDir
 
 	// Construct the worker with all dependencies
@@ -115,6 +121,7 @@ func main() {
 		*projectService,
 		importRepo,
 		exportRepo,
+		tenantDB,
 		logger,
 		expectedHeaders,
 		sheetName,
@@ -12,6 +12,7 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
 
+	database "github.com/Gkemhcs/taskpilot/internal/db"
 	"github.com/Gkemhcs/taskpilot/internal/exporter"
 	exporterdb "github.com/Gkemhcs/taskpilot/internal/exporter/gen"
 	"github.com/Gkemhcs/taskpilot/internal/importer"
@@ -29,6 +30,7 @@ type ProjectWorker struct {
 	ProjectSvc project.ProjectService // Unified service for project CRUD
 	ImportRepo importerdb.Querier     // DB access for import jobs
 	ExportRepo exporterdb.Querier     // DB access for export jobs
+	DB         *database.TenantDB     // Binds each job's queries to the user who queued it
 	Logger     *logrus.Logger         // Structured logger
 	Headers    []string               // Expected Excel headers
 	SheetName  string                 // Excel sheet name
@@ -43,6 +45,7 @@ func NewProjectWorker(
 	projectSvc project.ProjectService,
 	importRepo importerdb.Querier,
 	exportRepo exporterdb.Querier,
+	tenantDB *database.TenantDB,
 	logger *logrus.Logger,
 	headers []string,
 	sheetName string,
@@ -55,6 +58,7 @@ func NewProjectWorker(
 		ProjectSvc: projectSvc,
 		ImportRepo: importRepo,
 		ExportRepo: exportRepo,
+		DB:         tenantDB,
 		Logger:     logger,
 		Headers:    headers,
 		SheetName:  sheetName,
@@ -78,7 +82,11 @@ func (w *ProjectWorker) StartConsuming(ch *amqp.Channel, queueName string) error
 				msg.Nack(false, false)
 				return
 			}
-			ctx := context.Background()
+			// Run the job's queries as the user who queued it so row-level security applies,
+			// the audit log records the job id as the request id of every write
+			jobCtx := database.WithRequestInfo(context.Background(), database.RequestInfo{ID: payload.JobID})
+			ctx, release := w.DB.WithUser(jobCtx, int(payload.UserID))
+			defer release()
 			w.Logger.Infof("📦 Import Job Received: %s", payload.JobID)
 			localPath, err := w.Storage.Download(payload.FileName)
 			// Always attempt to delete the file after processing
@@ -93,7 +101,7 @@ func (w *ProjectWorker) StartConsuming(ch *amqp.Channel, queueName string) error
 				return
 			}
 			// Import project data from the downloaded file
-			err = w.Importer.Import(localPath, w.Headers, int(payload.UserID))
+			err = w.Importer.Import(ctx, localPath, w.Headers, int(payload.UserID), int(payload.OrganizationID))
 			if err != nil {
 				w.failImport(ctx, payload, err)
 				msg.Nack(false, false)
@@ -129,7 +137,11 @@ func (w *ProjectWorker) StartConsumingExport(ch *amqp.Channel, queueName string)
 				msg.Nack(false, false)
 				return
 			}
-			ctx := context.Background()
+			// Run the job's queries as the user who queued it so row-level security applies,
+			// the audit log records the job id as the request id of every write
+			jobCtx := database.WithRequestInfo(context.Background(), database.RequestInfo{ID: payload.JobID})
+			ctx, release := w.DB.WithUser(jobCtx, int(payload.UserID))
+			defer release()
 			w.Logger.Infof("📦 Export Job Received: %s", payload.JobID)
 			// Prepare Excel file for export
 			if err := w.Exporter.Open(payload.Filename); err != nil {
@@ -139,9 +151,9 @@ func (w *ProjectWorker) StartConsumingExport(ch *amqp.Channel, queueName string)
 			}
 
 			// Fetch projects for the user
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			defer cancel()
-			projects, err := w.ProjectSvc.GetProjectsByUserId(ctx, int(payload.UserID))
+			projects, err := w.ProjectSvc.GetProjectsByUserId(ctx, int(payload.OrganizationID), int(payload.UserID))
 			if err != nil {
 				w.failExport(ctx, payload, err)
 				msg.Nack(false, false)
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Gkemhcs/taskpilot/internal/storage"
+)
+
+// AttachmentDeletionService is the part of the task service the attachment
+// cleaner uses.
+type AttachmentDeletionService interface {
+	QueuedAttachmentDeletions(ctx context.Context, limit int32) ([]string, error)
+	DequeueAttachmentDeletion(ctx context.Context, key string) error
+}
+
+// AttachmentCleaner deletes the files of deleted attachments, including those
+// deleted with their task, project or organization.
+type AttachmentCleaner struct {
+	TaskSvc   AttachmentDeletionService
+	Storage   storage.StorageClient
+	Logger    *logrus.Logger
+	Interval  time.Duration
+	BatchSize int32
+}
+
+func NewAttachmentCleaner(taskSvc AttachmentDeletionService, storageClient storage.StorageClient, logger *logrus.Logger, interval time.Duration, batchSize int32) *AttachmentCleaner {
+	return &AttachmentCleaner{
+		TaskSvc:   taskSvc,
+		Storage:   storageClient,
+		Logger:    logger,
+		Interval:  interval,
+		BatchSize: batchSize,
+	}
+}
+
+// Start runs the cleaner until ctx is cancelled.
+func (c *AttachmentCleaner) Start(ctx context.Context) {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	c.Logger.Infof("🧹 Deleting the files of deleted attachments every %s", c.Interval)
+	for {
+		c.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce deletes the files of up to BatchSize deleted attachments. A file
+// that cannot be deleted stays queued for the next run.
+func (c *AttachmentCleaner) RunOnce(ctx context.Context) {
+	keys, err := c.TaskSvc.QueuedAttachmentDeletions(ctx, c.BatchSize)
+	if err != nil {
+		c.Logger.Errorf("❌ Failed to list deleted attachments: %v", err)
+		return
+	}
+	for _, key := range keys {
+		if err := c.Storage.Delete(key); err != nil {
+			c.Logger.Errorf("❌ Failed to delete attachment file %s: %v", key, err)
+			continue
+		}
+		if err := c.TaskSvc.DequeueAttachmentDeletion(ctx, key); err != nil {
+			c.Logger.Errorf("❌ Failed to dequeue attachment file %s: %v", key, err)
+		}
+	}
+}
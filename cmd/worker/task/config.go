@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/Gkemhcs/taskpilot/internal/storage"
 	"github.com/spf13/viper"
@@ -10,16 +11,20 @@ import (
 // WorkerConfig holds configuration values for the task worker process.
 // Includes database, RabbitMQ, storage, and queue settings.
 type WorkerConfig struct {
-	DBHost          string                // Database host
-	DBPort          string                // Database port
-	DBUser          string                // Database user
-	DBPassword      string                // Database password
-	DBName          string                // Database name
-	RabbitMQURL     string                // RabbitMQ connection URL
-	TaskQueue       string                // Import queue name
-	TaskExportQueue string                // Export queue name
-	StorageType     string                // Storage type (local/gcp)
-	StorageConfig   storage.StorageConfig // Storage configuration
+	DBHost              string                // Database host
+	DBPort              string                // Database port
+	DBUser              string                // Database user
+	DBPassword          string                // Database password
+	DBName              string                // Database name
+	RabbitMQURL         string                // RabbitMQ connection URL
+	TaskQueue           string                // Import queue name
+	TaskExportQueue     string                // Export queue name
+	StorageType         string                // Storage type (local/gcp)
+	StorageConfig       storage.StorageConfig // Storage configuration
+	RecurrenceInterval  time.Duration         // How often due recurring tasks are created
+	RecurrenceBatchSize int32                 // Series handled per scheduler run
+	CleanupInterval     time.Duration         // How often files of deleted attachments are deleted
+	CleanupBatchSize    int32                 // Files deleted per cleaner run
 }
 
 // LoadWorkerConfig loads configuration for the task worker from environment variables and .env file.
@@ -42,6 +47,10 @@ func LoadWorkerConfig() *WorkerConfig {
 	viper.SetDefault("GCP_BUCKET", "")
 	viper.SetDefault("GCP_PREFIX", "")
 	viper.SetDefault("TASK_EXPORT_QUEUE", "task_export_queue")
+	viper.SetDefault("RECURRENCE_INTERVAL", "1m")
+	viper.SetDefault("RECURRENCE_BATCH_SIZE", 100)
+	viper.SetDefault("ATTACHMENT_CLEANUP_INTERVAL", "5m")
+	viper.SetDefault("ATTACHMENT_CLEANUP_BATCH_SIZE", 100)
 
 	// Build storage config
 	storageCfg := storage.StorageConfig{
@@ -53,15 +62,19 @@ func LoadWorkerConfig() *WorkerConfig {
 
 	// Build and return WorkerConfig
 	return &WorkerConfig{
-		DBHost:          viper.GetString("DB_HOST"),
-		DBPort:          viper.GetString("DB_PORT"),
-		DBUser:          viper.GetString("DB_USER"),
-		DBPassword:      viper.GetString("DB_PASSWORD"),
-		DBName:          viper.GetString("DB_NAME"),
-		RabbitMQURL:     viper.GetString("RABBITMQ_URL"),
-		TaskQueue:       viper.GetString("TASK_IMPORT_QUEUE"),
-		StorageType:     viper.GetString("STORAGE_TYPE"),
-		StorageConfig:   storageCfg,
-		TaskExportQueue: viper.GetString("TASK_EXPORT_QUEUE"),
+		DBHost:              viper.GetString("DB_HOST"),
+		DBPort:              viper.GetString("DB_PORT"),
+		DBUser:              viper.GetString("DB_USER"),
+		DBPassword:          viper.GetString("DB_PASSWORD"),
+		DBName:              viper.GetString("DB_NAME"),
+		RabbitMQURL:         viper.GetString("RABBITMQ_URL"),
+		TaskQueue:           viper.GetString("TASK_IMPORT_QUEUE"),
+		StorageType:         viper.GetString("STORAGE_TYPE"),
+		StorageConfig:       storageCfg,
+		TaskExportQueue:     viper.GetString("TASK_EXPORT_QUEUE"),
+		RecurrenceInterval:  viper.GetDuration("RECURRENCE_INTERVAL"),
+		RecurrenceBatchSize: viper.GetInt32("RECURRENCE_BATCH_SIZE"),
+		CleanupInterval:     viper.GetDuration("ATTACHMENT_CLEANUP_INTERVAL"),
+		CleanupBatchSize:    viper.GetInt32("ATTACHMENT_CLEANUP_BATCH_SIZE"),
 	}
 }
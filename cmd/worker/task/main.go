@@ -4,20 +4,26 @@ import (
 	"context"
 	"database/sql"
 	"log"
-
+	"slices"
 	"strconv"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
 
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	database "github.com/Gkemhcs/taskpilot/internal/db"
 	"github.com/Gkemhcs/taskpilot/internal/exporter"
 	exporterdb "github.com/Gkemhcs/taskpilot/internal/exporter/gen"
 	"github.com/Gkemhcs/taskpilot/internal/importer"
 	importerdb "github.com/Gkemhcs/taskpilot/internal/importer/gen"
+	"github.com/Gkemhcs/taskpilot/internal/notification"
+	notificationdb "github.com/Gkemhcs/taskpilot/internal/notification/gen"
 	"github.com/Gkemhcs/taskpilot/internal/storage"
 	"github.com/Gkemhcs/taskpilot/internal/task"
 	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
 	"github.com/Gkemhcs/taskpilot/internal/user"
 	userdb "github.com/Gkemhcs/taskpilot/internal/user/gen"
 )
@@ -80,20 +86,24 @@ func main() {
 	}
 
 	// ---------- Dependencies ----------
-	taskRepo := taskdb.New(db)
-	taskService := task.NewTaskService(taskRepo)
-
-	userRepo := userdb.New(db)
+	// repositories query through the tenant wrapper so every job only sees the
+	// rows of the user who queued it
+	tenantDB := database.NewTenantDB(db)
+	taskRepo := taskdb.New(tenantDB)
+	taskService := task.NewTaskService(taskRepo, authz.NewAuthorizationService(authzdb.New(tenantDB)))
+	taskService.UseTransactions(tenantDB)
+	// imported tasks notify their assignees like tasks created through the API
+	taskService.Subscribe(notification.NewNotificationService(notificationdb.New(tenantDB), logger))
+
+	userRepo := userdb.New(tenantDB)
 	userService := user.NewUserService(userRepo)
 
-	importRepo := importerdb.New(db)
-	exportRepo := exporterdb.New(db)
+	importRepo := importerdb.New(tenantDB)
+	exportRepo := exporterdb.New(tenantDB)
 
-	expectedHeaders := []string{"project_id", "title", "assignee_email", "description", "status", "priority", "due_date"}
-
-	rowHandler := func(data map[string]string, userID int) error {
-		ctx := context.Background()
+	expectedHeaders := types.TaskImportHeaders
 
+	rowHandler := func(ctx context.Context, data map[string]string, userID int, orgID int) error {
 		user, err := userService.GetUserByEmail(ctx, data["assignee_email"])
 		if err != nil {
 
@@ -118,8 +128,39 @@ func main() {
 			Priority:    data["priority"],
 			DueDate:     dueDate,
 		}
+		// parent_title is optional, the parent must be an earlier row or an existing task of the project
+		if parentTitle := data["parent_title"]; parentTitle != "" {
+			parent, err := taskService.FindTaskByTitle(ctx, userID, orgID, projectID, parentTitle)
+			if err != nil {
+				return err
+			}
+			taskInput.ParentTaskID = &parent.ID
+		}
+		// labels is optional, comma-separated names of labels defined on the project
+		if labels := data["labels"]; labels != "" {
+			taskInput.LabelIDs, err = taskService.FindLabelIDs(ctx, userID, orgID, projectID, []string{labels})
+			if err != nil {
+				return err
+			}
+		}
+
+		// original_estimate_minutes is optional, exports write it with the other time columns
+		if estimate := data["original_estimate_minutes"]; estimate != "" {
+			minutes, err := strconv.ParseInt(estimate, 10, 32)
+			if err != nil {
+				return err
+			}
+			originalEstimate := int32(minutes)
+			taskInput.OriginalEstimateMinutes = &originalEstimate
+		}
 
-		_, err = taskService.CreateTask(ctx, taskInput)
+		// columns named after custom fields of the project carry their values
+		taskInput.CustomFields, err = taskService.CustomFieldValuesByName(ctx, userID, orgID, projectID, data)
+		if err != nil {
+			return err
+		}
+
+		_, err = taskService.CreateTask(ctx, userID, orgID, taskInput)
 		if err != nil {
 			return err
 		}
@@ -129,8 +170,10 @@ func main() {
 
 	excelImporter := importer.NewExcelImporter(expectedHeaders, rowHandler)
 	sheetName := "task"
-	excelExporter := exporter.NewExcelExporter(expectedHeaders,sheetName)
-	
+	// exports name each task's parent and labels so the file can be imported again,
+	// custom field columns are added per project by the export worker
+	exportHeaders := append(slices.Clone(expectedHeaders), types.TaskExportHeaders...)
+	excelExporter := exporter.NewExcelExporter(exportHeaders, sheetName)
 	localDir := cfg.StorageConfig.ProcessDir
 	worker := NewTaskWorker(
 		excelImporter,
@@ -139,6 +182,7 @@ func main() {
 		taskService,
 		importRepo,
 		exportRepo,
+		tenantDB,
 		logger,
 		expectedHeaders,
 		sheetName,
@@ -158,6 +202,12 @@ func main() {
 		}
 	}()
 
+	scheduler := NewRecurrenceScheduler(taskService, tenantDB, logger, cfg.RecurrenceInterval, cfg.RecurrenceBatchSize)
+	go scheduler.Start(context.Background())
+
+	cleaner := NewAttachmentCleaner(taskService, storageClient, logger, cfg.CleanupInterval, cfg.CleanupBatchSize)
+	go cleaner.Start(context.Background())
+
 	// block main thread forever or until termination
 	select {}
 }
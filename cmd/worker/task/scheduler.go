@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	database "github.com/Gkemhcs/taskpilot/internal/db"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+)
+
+// SeriesService is the part of the task service the recurrence scheduler uses.
+type SeriesService interface {
+	DueTaskSeries(ctx context.Context, limit int32) ([]taskdb.ListDueTaskSeriesRow, error)
+	MaterializeSeries(ctx context.Context, userID int, orgID int, seriesID int64) error
+}
+
+// RecurrenceScheduler creates the next occurrence of recurring tasks whose
+// latest occurrence is done or past its due date.
+type RecurrenceScheduler struct {
+	TaskSvc   SeriesService
+	DB        *database.TenantDB // binds each occurrence's queries to the series creator
+	Logger    *logrus.Logger
+	Interval  time.Duration
+	BatchSize int32
+}
+
+func NewRecurrenceScheduler(taskSvc SeriesService, tenantDB *database.TenantDB, logger *logrus.Logger, interval time.Duration, batchSize int32) *RecurrenceScheduler {
+	return &RecurrenceScheduler{
+		TaskSvc:   taskSvc,
+		DB:        tenantDB,
+		Logger:    logger,
+		Interval:  interval,
+		BatchSize: batchSize,
+	}
+}
+
+// Start runs the scheduler until ctx is cancelled.
+func (s *RecurrenceScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.Logger.Infof("⏰ Materializing recurring tasks every %s", s.Interval)
+	for {
+		s.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce creates the due occurrences of up to BatchSize series.
+func (s *RecurrenceScheduler) RunOnce(ctx context.Context) {
+	due, err := s.TaskSvc.DueTaskSeries(ctx, s.BatchSize)
+	if err != nil {
+		s.Logger.Errorf("❌ Failed to list due task series: %v", err)
+		return
+	}
+	for _, series := range due {
+		func() {
+			// every run gets its own request id so the audit log groups the
+			// writes of one occurrence
+			runCtx := database.WithRequestInfo(ctx, database.RequestInfo{ID: uuid.NewString()})
+			runCtx, release := s.DB.WithUser(runCtx, int(series.UserID))
+			defer release()
+			err := s.TaskSvc.MaterializeSeries(runCtx, int(series.UserID), int(series.OrganizationID), series.SeriesID)
+			if errors.Is(err, customErrors.ErrTaskSeriesEnded) {
+				// ended series are no longer due, so this is logged once
+				s.Logger.Warnf("⚠️ Ended series %d: %v", series.SeriesID, err)
+			} else if err != nil {
+				s.Logger.Errorf("❌ Failed to create the next occurrence of series %d: %v", series.SeriesID, err)
+			}
+		}()
+	}
+}
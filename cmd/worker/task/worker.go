@@ -6,12 +6,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/sirupsen/logrus"
 
+	database "github.com/Gkemhcs/taskpilot/internal/db"
 	"github.com/Gkemhcs/taskpilot/internal/exporter"
 	exporterdb "github.com/Gkemhcs/taskpilot/internal/exporter/gen"
 	"github.com/Gkemhcs/taskpilot/internal/importer"
@@ -19,6 +22,7 @@ import (
 
 	"github.com/Gkemhcs/taskpilot/internal/storage"
 	"github.com/Gkemhcs/taskpilot/internal/task"
+	"github.com/Gkemhcs/taskpilot/internal/types"
 )
 
 type TaskWorker struct {
@@ -28,6 +32,7 @@ type TaskWorker struct {
 	TaskSvc    task.BulkTaskService
 	ImportRepo importerdb.Querier
 	ExportRepo exporterdb.Querier
+	DB         *database.TenantDB // binds each job's queries to the user who queued it
 	Logger     *logrus.Logger
 	Headers    []string // expected headers for validation
 	SheetName  string
@@ -41,6 +46,7 @@ func NewTaskWorker(
 	taskSvc task.BulkTaskService,
 	importRepo importerdb.Querier,
 	exportRepo exporterdb.Querier,
+	tenantDB *database.TenantDB,
 	logger *logrus.Logger,
 	headers []string,
 	sheetName string,
@@ -53,6 +59,7 @@ func NewTaskWorker(
 		TaskSvc:    taskSvc,
 		ImportRepo: importRepo,
 		ExportRepo: exportRepo,
+		DB:         tenantDB,
 		Logger:     logger,
 		Headers:    headers,
 		SheetName:  sheetName,
@@ -76,7 +83,11 @@ func (w *TaskWorker) StartConsuming(ch *amqp.Channel, queueName string) error {
 				return
 			}
 
-			ctx := context.Background()
+			// Run the job's queries as the user who queued it so row-level security applies,
+			// the audit log records the job id as the request id of every write
+			jobCtx := database.WithRequestInfo(context.Background(), database.RequestInfo{ID: payload.JobID})
+			ctx, release := w.DB.WithUser(jobCtx, int(payload.UserID))
+			defer release()
 			w.Logger.Infof("📦 Job Received: %s", payload.JobID)
 
 			localPath, err := w.Storage.Download(payload.FileName)
@@ -101,7 +112,7 @@ func (w *TaskWorker) StartConsuming(ch *amqp.Channel, queueName string) error {
 				return
 			}
 
-			err = w.Importer.Import(localPath, w.Headers, int(payload.UserID))
+			err = w.Importer.Import(ctx, localPath, w.Headers, int(payload.UserID), int(payload.OrganizationID))
 			if err != nil {
 				errMsg := fmt.Sprintf("Import failed: %v", err)
 				w.Logger.Error(errMsg)
@@ -144,25 +155,64 @@ func (w *TaskWorker) StartConsumingExport(ch *amqp.Channel, queueName string) er
 				msg.Nack(false, false)
 				return
 			}
-			ctx := context.Background()
+			// Run the job's queries as the user who queued it so row-level security applies,
+			// the audit log records the job id as the request id of every write
+			jobCtx := database.WithRequestInfo(context.Background(), database.RequestInfo{ID: payload.JobID})
+			ctx, release := w.DB.WithUser(jobCtx, int(payload.UserID))
+			defer release()
 			w.Logger.Infof("📦 Export Job Received: %s", payload.JobID)
-			if err := w.Exporter.Open(payload.Filename); err != nil {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			// every custom field of the project gets a column named after it
+			fields, err := w.TaskSvc.ListCustomFields(ctx, int(payload.UserID), int(payload.OrganizationID), int(payload.ProjectID))
+			if err != nil {
+				w.failExport(ctx, payload, err)
+				msg.Nack(false, false)
+				return
+			}
+			// the time columns come first, then one per custom field
+			extraHeaders := slices.Clone(types.TaskTimeHeaders)
+			for _, field := range fields {
+				extraHeaders = append(extraHeaders, field.Name)
+			}
+			if err := w.Exporter.Open(payload.Filename, extraHeaders...); err != nil {
 				w.failExport(ctx, payload, err)
 				msg.Nack(false, false)
 				return
 			}
 
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			projects, err := w.TaskSvc.GetTasksByProjectID(ctx, int(payload.ProjectID))
+			projects, err := w.TaskSvc.GetTasksByProjectID(ctx, int(payload.UserID), int(payload.OrganizationID), int(payload.ProjectID))
 			if err != nil {
 				w.failExport(ctx, payload, err)
 				msg.Nack(false, false)
 				return
 			}
 
+			responses, err := w.TaskSvc.TaskResponses(ctx, task.SortParentsFirst(projects))
+			if err != nil {
+				w.failExport(ctx, payload, err)
+				msg.Nack(false, false)
+				return
+			}
+			titles := make(map[int64]string, len(projects))
 			for _, t := range projects {
-				row := []any{t.ID,t.ProjectID, t.Title,t.AssigneeID.Int64, t.Description, t.Status,t.Priority,t.DueDate.Time,t.CreatedAt,t.UpdatedAt}
+				titles[t.ID] = t.Title
+			}
+			for _, t := range responses {
+				labels := make([]string, len(t.Labels))
+				for i, label := range t.Labels {
+					labels[i] = label.Name
+				}
+				row := []any{t.ID, t.ProjectID, t.Title, t.AssigneeID.Int64, t.Description, t.Status, t.Priority, t.DueDate.Time, titles[t.ParentTaskID.Int64], strings.Join(labels, ","), t.CreatedAt, t.UpdatedAt}
+				row = append(row, minutesCell(t.OriginalEstimateMinutes), minutesCell(t.RemainingEstimateMinutes), t.TimeSpentMinutes)
+				for _, field := range fields {
+					value, ok := t.CustomFields[field.ID]
+					if !ok {
+						row = append(row, "")
+						continue
+					}
+					row = append(row, task.CustomFieldText(value))
+				}
 				if err := w.Exporter.AddRow(row); err != nil {
 					w.failExport(ctx, payload, err)
 					msg.Nack(false, false)
@@ -206,7 +256,6 @@ func (w *TaskWorker) StartConsumingExport(ch *amqp.Channel, queueName string) er
 	return nil
 }
 
-
 func (w *TaskWorker) failExport(ctx context.Context, payload ExportJobPayload, err error) {
 	w.ExportRepo.UpdateExportJobStatus(ctx, exporterdb.UpdateExportJobStatusParams{
 		ID:           uuid.MustParse(payload.JobID),
@@ -216,3 +265,11 @@ func (w *TaskWorker) failExport(ctx context.Context, payload ExportJobPayload, e
 	})
 	w.Logger.Errorf("❌ Export job failed: %s | %v", payload.JobID, err)
 }
+
+// minutesCell leaves the cell of a missing estimate empty.
+func minutesCell(minutes sql.NullInt32) any {
+	if !minutes.Valid {
+		return ""
+	}
+	return minutes.Int32
+}
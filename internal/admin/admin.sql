@@ -0,0 +1,55 @@
+-- name: ListUsers :many
+SELECT id, name, email, is_admin, disabled_at, email_verified_at, created_at FROM users
+ORDER BY id
+LIMIT $1 OFFSET $2;
+
+-- name: GetUser :one
+SELECT id, name, email, is_admin, disabled_at, email_verified_at, created_at FROM users WHERE id = $1;
+
+-- name: SetUserDisabled :execrows
+-- disabling keeps the original disabled_at when the user is already disabled
+UPDATE users SET disabled_at = CASE WHEN sqlc.arg('disabled')::bool THEN COALESCE(disabled_at, now()) END
+WHERE id = sqlc.arg('id');
+
+-- name: SetUserAdmin :execrows
+UPDATE users SET is_admin = $2 WHERE id = $1;
+
+-- name: PromoteFirstAdmin :execrows
+-- only succeeds while the instance has no administrator yet
+UPDATE users SET is_admin = true
+WHERE email = $1 AND NOT EXISTS (SELECT 1 FROM users WHERE is_admin);
+
+-- name: CountAdmins :one
+SELECT COUNT(*) FROM users WHERE is_admin;
+
+-- name: ListImportJobs :many
+SELECT id, file_path, importer_type, status, error_message, created_at, updated_at, user_id, organization_id FROM import_jobs
+WHERE (sqlc.narg('user_id')::int IS NULL OR user_id = sqlc.narg('user_id'))
+  AND (sqlc.narg('status')::import_job_status IS NULL OR status = sqlc.narg('status'))
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListExportJobs :many
+SELECT id, user_id, status, export_type, url, error_message, created_at, updated_at, organization_id FROM export_jobs
+WHERE (sqlc.narg('user_id')::int IS NULL OR user_id = sqlc.narg('user_id'))
+  AND (sqlc.narg('status')::export_job_status IS NULL OR status = sqlc.narg('status'))
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetProject :one
+SELECT id, user_id, name, description, color, created_at, updated_at, organization_id FROM projects WHERE id = $1;
+
+-- name: IsOrganizationMember :one
+SELECT EXISTS (SELECT 1 FROM organization_members WHERE organization_id = $1 AND user_id = $2);
+
+-- name: SetProjectOwner :exec
+UPDATE projects SET user_id = $2, updated_at = now() WHERE id = $1;
+
+-- name: UpsertProjectOwner :exec
+INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, 'OWNER')
+ON CONFLICT (project_id, user_id) DO UPDATE SET role = 'OWNER', updated_at = now();
+
+-- name: DemoteProjectOwner :exec
+-- the previous owner stays on the project as an editor
+UPDATE project_members SET role = 'EDITOR', updated_at = now()
+WHERE project_id = $1 AND user_id = $2 AND role = 'OWNER';
@@ -0,0 +1,380 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/middleware"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/Gkemhcs/taskpilot/internal/user"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func NewAdminHandler(logger *logrus.Logger, adminService *AdminService, userService user.UserResolver, jwtManager *auth.JWTManager) *AdminHandler {
+	return &AdminHandler{
+		logger:       logger,
+		adminService: adminService,
+		userService:  userService,
+		jwtManager:   jwtManager,
+	}
+}
+
+type AdminHandler struct {
+	logger       *logrus.Logger
+	adminService *AdminService
+	userService  user.UserResolver
+	jwtManager   *auth.JWTManager
+}
+
+// RegisterAdminRoutes registers the instance administration endpoints. They
+// need a login session of a user with the admin role and see every tenant's
+// rows, so personal access tokens are never accepted.
+func RegisterAdminRoutes(r *gin.RouterGroup, handler *AdminHandler, binder middleware.TenantBinder) {
+	adminGroup := r.Group("/admin",
+		middleware.JWTAuthMiddleware(handler.logger, handler.jwtManager),
+		middleware.RequireSession(),
+		middleware.RequireAdmin(handler.logger, binder),
+	)
+	{
+		adminGroup.GET("/users", handler.ListUsers)
+		adminGroup.GET("/users/:id", handler.GetUser)
+		adminGroup.POST("/users/:id/disable", handler.DisableUser)
+		adminGroup.POST("/users/:id/enable", handler.EnableUser)
+		adminGroup.POST("/users/:id/admin", handler.GrantAdmin)
+		adminGroup.DELETE("/users/:id/admin", handler.RevokeAdmin)
+		adminGroup.GET("/jobs/imports", handler.ListImportJobs)
+		adminGroup.GET("/jobs/exports", handler.ListExportJobs)
+		adminGroup.POST("/projects/:id/transfer", handler.TransferProject)
+	}
+}
+
+// ListUsers lists every user of the instance.
+// @Summary      List users
+// @Description  Lists every user with their admin and disabled state, ordered by id. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        limit   query     int  false  "Page size (default 20, max 100)"
+// @Param        offset  query     int  false  "Number of users to skip"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  utils.ErrorResponse
+// @Failure      403     {object}  utils.ErrorResponse
+// @Router       /api/v1/admin/users [get]
+// @Security BearerAuth
+func (a *AdminHandler) ListUsers(c *gin.Context) {
+	var page types.Page
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidPagination.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	users, err := a.adminService.ListUsers(ctx, page)
+	if err != nil {
+		a.logger.Errorf("unable to list users %v", err)
+		utils.Error(c, adminErrorStatus(err), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, users)
+}
+
+// GetUser returns a single user.
+// @Summary      Get user
+// @Description  Returns a user with their admin and disabled state. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/admin/users/{id} [get]
+// @Security BearerAuth
+func (a *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := a.targetUserID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	found, err := a.adminService.GetUser(ctx, userID)
+	if err != nil {
+		a.logger.Errorf("unable to get user %d %v", userID, err)
+		utils.Error(c, adminErrorStatus(err), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, found)
+}
+
+// DisableUser disables an account
+// @Summary      Disable user
+// @Description  Disables an account and logs it out everywhere. Disabled users cannot log in and their personal access tokens stop working. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Failure      409  {object}  utils.ErrorResponse
+// @Router       /api/v1/admin/users/{id}/disable [post]
+// @Security BearerAuth
+func (a *AdminHandler) DisableUser(c *gin.Context) {
+	a.setDisabled(c, true)
+}
+
+// EnableUser re-enables a disabled account
+// @Summary      Enable user
+// @Description  Re-enables a disabled account so it can log in again. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/admin/users/{id}/enable [post]
+// @Security BearerAuth
+func (a *AdminHandler) EnableUser(c *gin.Context) {
+	a.setDisabled(c, false)
+}
+
+func (a *AdminHandler) setDisabled(c *gin.Context, disabled bool) {
+	userID, ok := a.targetUserID(c)
+	if !ok {
+		return
+	}
+	adminID, ok := a.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	updated, err := a.adminService.SetUserDisabled(ctx, adminID, userID, disabled)
+	if err != nil {
+		a.logger.Errorf("unable to change the disabled state of user %d %v", userID, err)
+		utils.Error(c, adminErrorStatus(err), err.Error())
+		return
+	}
+	if disabled {
+		if err := a.jwtManager.RevokeUser(ctx, userID); err != nil {
+			a.logger.Errorf("unable to revoke the sessions of user %d %v", userID, err)
+			utils.Error(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	a.logger.Infof("user %d disabled=%t by admin %d", userID, disabled, adminID)
+	utils.Success(c, http.StatusOK, updated)
+}
+
+// GrantAdmin makes a user an administrator
+// @Summary      Grant admin role
+// @Description  Makes a user an administrator. The role is added to their tokens from their next login or token refresh. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/admin/users/{id}/admin [post]
+// @Security BearerAuth
+func (a *AdminHandler) GrantAdmin(c *gin.Context) {
+	a.setAdmin(c, true)
+}
+
+// RevokeAdmin removes the administrator role from a user
+// @Summary      Revoke admin role
+// @Description  Removes the administrator role and logs the user out everywhere so no token keeps the role. Admins cannot demote themselves. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Failure      409  {object}  utils.ErrorResponse
+// @Router       /api/v1/admin/users/{id}/admin [delete]
+// @Security BearerAuth
+func (a *AdminHandler) RevokeAdmin(c *gin.Context) {
+	a.setAdmin(c, false)
+}
+
+func (a *AdminHandler) setAdmin(c *gin.Context, isAdmin bool) {
+	userID, ok := a.targetUserID(c)
+	if !ok {
+		return
+	}
+	adminID, ok := a.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	updated, err := a.adminService.SetUserAdmin(ctx, adminID, userID, isAdmin)
+	if err != nil {
+		a.logger.Errorf("unable to change the admin role of user %d %v", userID, err)
+		utils.Error(c, adminErrorStatus(err), err.Error())
+		return
+	}
+	if !isAdmin {
+		if err := a.jwtManager.RevokeUser(ctx, userID); err != nil {
+			a.logger.Errorf("unable to revoke the sessions of user %d %v", userID, err)
+			utils.Error(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	a.logger.Infof("user %d admin=%t by admin %d", userID, isAdmin, adminID)
+	utils.Success(c, http.StatusOK, updated)
+}
+
+// ListImportJobs lists import jobs of every user
+// @Summary      List import jobs
+// @Description  Lists the import jobs of every user, newest first. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        user_id  query     int     false  "Only jobs of this user"
+// @Param        status   query     string  false  "pending, in_progress, completed or failed"
+// @Param        limit    query     int     false  "Page size (default 20, max 100)"
+// @Param        offset   query     int     false  "Number of jobs to skip"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      403      {object}  utils.ErrorResponse
+// @Router       /api/v1/admin/jobs/imports [get]
+// @Security BearerAuth
+func (a *AdminHandler) ListImportJobs(c *gin.Context) {
+	var filter JobFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	jobs, err := a.adminService.ListImportJobs(ctx, filter)
+	if err != nil {
+		a.logger.Errorf("unable to list import jobs %v", err)
+		utils.Error(c, adminErrorStatus(err), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, jobs)
+}
+
+// ListExportJobs lists export jobs of every user
+// @Summary      List export jobs
+// @Description  Lists the export jobs of every user, newest first. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        user_id  query     int     false  "Only jobs of this user"
+// @Param        status   query     string  false  "pending, processing, completed or failed"
+// @Param        limit    query     int     false  "Page size (default 20, max 100)"
+// @Param        offset   query     int     false  "Number of jobs to skip"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      403      {object}  utils.ErrorResponse
+// @Router       /api/v1/admin/jobs/exports [get]
+// @Security BearerAuth
+func (a *AdminHandler) ListExportJobs(c *gin.Context) {
+	var filter JobFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	jobs, err := a.adminService.ListExportJobs(ctx, filter)
+	if err != nil {
+		a.logger.Errorf("unable to list export jobs %v", err)
+		utils.Error(c, adminErrorStatus(err), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, jobs)
+}
+
+// TransferProject hands a project over to a new owner
+// @Summary      Transfer project
+// @Description  Makes another member of the project's organization the owner of the project, e.g. when its owner was disabled. The previous owner stays on the project as an editor. Admin only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                     true  "Project ID"
+// @Param        request  body      TransferProjectRequest  true  "Email of the new owner"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      403      {object}  utils.ErrorResponse
+// @Failure      404      {object}  utils.ErrorResponse
+// @Router       /api/v1/admin/projects/{id}/transfer [post]
+// @Security BearerAuth
+func (a *AdminHandler) TransferProject(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || projectID <= 0 {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidProjectId.Error())
+		return
+	}
+	var request TransferProjectRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	adminID, ok := a.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	newOwner, err := a.userService.GetUserByEmail(ctx, request.Email)
+	if err != nil {
+		a.logger.Errorf("unable to find user %s %v", request.Email, err)
+		utils.Error(c, http.StatusNotFound, customErrors.USER_NOT_FOUND.Error())
+		return
+	}
+	project, err := a.adminService.TransferProject(ctx, projectID, int(newOwner.ID))
+	if err != nil {
+		a.logger.Errorf("unable to transfer project %d %v", projectID, err)
+		utils.Error(c, adminErrorStatus(err), err.Error())
+		return
+	}
+	a.logger.Infof("project %d transferred to user %d by admin %d", projectID, newOwner.ID, adminID)
+	utils.Success(c, http.StatusOK, project)
+}
+
+func (a *AdminHandler) userID(c *gin.Context) (int, bool) {
+	val, exists := c.Get("userID")
+	if !exists {
+		a.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return 0, false
+	}
+	userID, ok := val.(int)
+	if !ok {
+		a.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return 0, false
+	}
+	return userID, true
+}
+
+func (a *AdminHandler) targetUserID(c *gin.Context) (int, bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidAccountID.Error())
+		return 0, false
+	}
+	return userID, true
+}
+
+// adminErrorStatus maps validation errors to 400, unknown users to 404 and
+// everything else through utils.ErrorStatus.
+func adminErrorStatus(err error) int {
+	switch err {
+	case customErrors.ErrInvalidPagination, customErrors.ErrInvalidJobStatus, customErrors.ErrNotOrganizationMember:
+		return http.StatusBadRequest
+	case customErrors.USER_NOT_FOUND:
+		return http.StatusNotFound
+	}
+	return utils.ErrorStatus(err, http.StatusInternalServerError)
+}
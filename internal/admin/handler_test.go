@@ -0,0 +1,134 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	admindb "github.com/Gkemhcs/taskpilot/internal/admin/gen"
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	"github.com/Gkemhcs/taskpilot/internal/db"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/Gkemhcs/taskpilot/internal/user"
+	userdb "github.com/Gkemhcs/taskpilot/internal/user/gen"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// accountStatuses is an auth.AccountStatusReader backed by a map.
+type accountStatuses map[int]auth.AccountStatus
+
+func (a accountStatuses) AccountStatus(_ context.Context, userID int) (auth.AccountStatus, error) {
+	return a[userID], nil
+}
+
+func SetupNewAdminHandler() (*gin.Engine, *MockAdminRepo, *user.MockUserRepo, *auth.JWTManager) {
+	jwtManager := auth.NewJWTManager(auth.CreateJwtManagerParams{
+		AccessTokenDuration:  10 * time.Minute,
+		RefreshTokenDuration: 10 * time.Hour,
+		AccessTokenKey:       "rnk3mkrk3rk3rk3",
+		RefreshTokenKey:      "21ieh12iei21eji12e",
+		Accounts: accountStatuses{
+			1:   {IsAdmin: true},
+			101: {},
+			303: {Disabled: true},
+		},
+	})
+	repo := new(MockAdminRepo)
+	userMockRepo := new(user.MockUserRepo)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	handler := NewAdminHandler(logger, NewAdminService(repo), user.NewUserService(userMockRepo), jwtManager)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	v1 := r.Group("/api/v1")
+	// the handlers only talk to the mock repo, so the binder never opens a connection
+	RegisterAdminRoutes(v1, handler, db.NewTenantDB(nil))
+	return r, repo, userMockRepo, jwtManager
+}
+
+func TestAdminHandlers(t *testing.T) {
+	r, repo, userMockRepo, jwtManager := SetupNewAdminHandler()
+	adminTokens, err := jwtManager.Generate(context.TODO(), 1, "root", "root@taskpilot.dev")
+	require.NoError(t, err)
+	userTokens, err := jwtManager.Generate(context.TODO(), 101, "dev", "dev@taskpilot.dev")
+	require.NoError(t, err)
+	admin, member := adminTokens.AccessToken, userTokens.AccessToken
+
+	repo.On("ListUsers", mock.Anything, admindb.ListUsersParams{Limit: types.DefaultPageSize}).Return([]admindb.ListUsersRow{{ID: 1, IsAdmin: true}}, nil)
+	repo.On("GetUser", mock.Anything, int32(101)).Return(admindb.GetUserRow{ID: 101}, nil)
+	repo.On("GetUser", mock.Anything, mock.Anything).Return(admindb.GetUserRow{}, sql.ErrNoRows)
+	repo.On("SetUserDisabled", mock.Anything, admindb.SetUserDisabledParams{ID: 101, Disabled: true}).Return(int64(1), nil)
+	repo.On("ListExportJobs", mock.Anything, mock.Anything).Return([]admindb.ExportJob{}, nil)
+	repo.On("GetProject", mock.Anything, int64(5)).Return(admindb.Project{ID: 5, UserID: 101, OrganizationID: 7}, nil)
+	repo.On("IsOrganizationMember", mock.Anything, mock.Anything).Return(false, nil)
+	userMockRepo.On("GetUserByEmail", mock.Anything, "ops@taskpilot.dev").Return(userdb.User{ID: 404, Email: "ops@taskpilot.dev"}, nil)
+	userMockRepo.On("GetUserByEmail", mock.Anything, mock.Anything).Return(userdb.User{}, sql.ErrNoRows)
+
+	testCases := []struct {
+		testName           string
+		method             string
+		path               string
+		token              string
+		body               any
+		expectedStatusCode int
+	}{
+		{testName: "admin lists users", method: http.MethodGet, path: "/api/v1/admin/users", token: admin, expectedStatusCode: http.StatusOK},
+		{testName: "non admin is rejected", method: http.MethodGet, path: "/api/v1/admin/users", token: member, expectedStatusCode: http.StatusForbidden},
+		{testName: "page size is limited", method: http.MethodGet, path: "/api/v1/admin/users?limit=500", token: admin, expectedStatusCode: http.StatusBadRequest},
+		{testName: "get unknown user", method: http.MethodGet, path: "/api/v1/admin/users/404", token: admin, expectedStatusCode: http.StatusNotFound},
+		{testName: "invalid user id", method: http.MethodGet, path: "/api/v1/admin/users/abc", token: admin, expectedStatusCode: http.StatusBadRequest},
+		{testName: "disable a user", method: http.MethodPost, path: "/api/v1/admin/users/101/disable", token: admin, expectedStatusCode: http.StatusOK},
+		{testName: "admin cannot disable themselves", method: http.MethodPost, path: "/api/v1/admin/users/1/disable", token: admin, expectedStatusCode: http.StatusConflict},
+		{testName: "admin cannot demote themselves", method: http.MethodDelete, path: "/api/v1/admin/users/1/admin", token: admin, expectedStatusCode: http.StatusConflict},
+		{testName: "list export jobs", method: http.MethodGet, path: "/api/v1/admin/jobs/exports?status=processing", token: admin, expectedStatusCode: http.StatusOK},
+		{testName: "invalid job status", method: http.MethodGet, path: "/api/v1/admin/jobs/imports?status=done", token: admin, expectedStatusCode: http.StatusBadRequest},
+		{testName: "transfer to an unknown user", method: http.MethodPost, path: "/api/v1/admin/projects/5/transfer", token: admin, body: TransferProjectRequest{Email: "ghost@taskpilot.dev"}, expectedStatusCode: http.StatusNotFound},
+		{testName: "transfer outside the organization", method: http.MethodPost, path: "/api/v1/admin/projects/5/transfer", token: admin, body: TransferProjectRequest{Email: "ops@taskpilot.dev"}, expectedStatusCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			var body io.Reader
+			if tc.body != nil {
+				payload, _ := json.Marshal(tc.body)
+				body = bytes.NewReader(payload)
+			}
+			req, _ := http.NewRequest(tc.method, tc.path, body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestAdminClaim(t *testing.T) {
+	_, _, _, jwtManager := SetupNewAdminHandler()
+
+	t.Run("admins get the admin claim", func(t *testing.T) {
+		tokens, err := jwtManager.Generate(context.TODO(), 1, "root", "root@taskpilot.dev")
+		require.NoError(t, err)
+		claims, err := jwtManager.Verify(tokens.AccessToken)
+		require.NoError(t, err)
+		assert.True(t, claims.IsAdmin)
+	})
+
+	t.Run("disabled users get no tokens", func(t *testing.T) {
+		_, err := jwtManager.Generate(context.TODO(), 303, "gone", "gone@taskpilot.dev")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"context"
+
+	admindb "github.com/Gkemhcs/taskpilot/internal/admin/gen"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAdminRepo is a mock implementation of the admindb.Querier interface
+type MockAdminRepo struct {
+	mock.Mock
+}
+
+func (m *MockAdminRepo) CountAdmins(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAdminRepo) DemoteProjectOwner(ctx context.Context, arg admindb.DemoteProjectOwnerParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockAdminRepo) GetProject(ctx context.Context, id int64) (admindb.Project, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(admindb.Project), args.Error(1)
+}
+
+func (m *MockAdminRepo) GetUser(ctx context.Context, id int32) (admindb.GetUserRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(admindb.GetUserRow), args.Error(1)
+}
+
+func (m *MockAdminRepo) IsOrganizationMember(ctx context.Context, arg admindb.IsOrganizationMemberParams) (bool, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(bool), args.Error(1)
+}
+
+func (m *MockAdminRepo) ListExportJobs(ctx context.Context, arg admindb.ListExportJobsParams) ([]admindb.ExportJob, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]admindb.ExportJob), args.Error(1)
+}
+
+func (m *MockAdminRepo) ListImportJobs(ctx context.Context, arg admindb.ListImportJobsParams) ([]admindb.ImportJob, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]admindb.ImportJob), args.Error(1)
+}
+
+func (m *MockAdminRepo) ListUsers(ctx context.Context, arg admindb.ListUsersParams) ([]admindb.ListUsersRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]admindb.ListUsersRow), args.Error(1)
+}
+
+func (m *MockAdminRepo) PromoteFirstAdmin(ctx context.Context, email string) (int64, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAdminRepo) SetProjectOwner(ctx context.Context, arg admindb.SetProjectOwnerParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockAdminRepo) SetUserAdmin(ctx context.Context, arg admindb.SetUserAdminParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAdminRepo) SetUserDisabled(ctx context.Context, arg admindb.SetUserDisabledParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAdminRepo) UpsertProjectOwner(ctx context.Context, arg admindb.UpsertProjectOwnerParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
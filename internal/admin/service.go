@@ -0,0 +1,221 @@
+// Package admin lets instance administrators manage every user, inspect every
+// import and export job and hand projects over to a new owner. Its queries run
+// with row-level security bypassed, so every route must sit behind
+// middleware.RequireAdmin.
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	admindb "github.com/Gkemhcs/taskpilot/internal/admin/gen"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+func NewAdminService(repo admindb.Querier) *AdminService {
+	return &AdminService{
+		repo: repo,
+	}
+}
+
+// AdminService implements the instance administration use cases. It does not
+// check the caller's role; the admin routes do that before calling it.
+type AdminService struct {
+	repo admindb.Querier
+}
+
+// ListUsers returns a page of every user of the instance, ordered by id.
+func (a *AdminService) ListUsers(ctx context.Context, page types.Page) ([]admindb.ListUsersRow, error) {
+	limit, offset, err := page.Bounds()
+	if err != nil {
+		return nil, err
+	}
+	users, err := a.repo.ListUsers(ctx, admindb.ListUsersParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []admindb.ListUsersRow{}
+	}
+	return users, nil
+}
+
+// GetUser returns a single user including its admin and disabled state.
+func (a *AdminService) GetUser(ctx context.Context, userID int) (*admindb.GetUserRow, error) {
+	user, err := a.repo.GetUser(ctx, int32(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.USER_NOT_FOUND
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetUserDisabled disables or re-enables userID on behalf of adminID. Admins
+// cannot disable themselves. The caller is expected to revoke the sessions of
+// a disabled user.
+func (a *AdminService) SetUserDisabled(ctx context.Context, adminID int, userID int, disabled bool) (*admindb.GetUserRow, error) {
+	if disabled && adminID == userID {
+		return nil, customErrors.ErrCannotModifySelf
+	}
+	rows, err := a.repo.SetUserDisabled(ctx, admindb.SetUserDisabledParams{ID: int32(userID), Disabled: disabled})
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, customErrors.USER_NOT_FOUND
+	}
+	return a.GetUser(ctx, userID)
+}
+
+// SetUserAdmin grants or revokes the admin role of userID on behalf of
+// adminID. Admins cannot demote themselves, so the instance always keeps at
+// least one admin.
+func (a *AdminService) SetUserAdmin(ctx context.Context, adminID int, userID int, isAdmin bool) (*admindb.GetUserRow, error) {
+	if !isAdmin && adminID == userID {
+		return nil, customErrors.ErrCannotModifySelf
+	}
+	rows, err := a.repo.SetUserAdmin(ctx, admindb.SetUserAdminParams{ID: int32(userID), IsAdmin: isAdmin})
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, customErrors.USER_NOT_FOUND
+	}
+	return a.GetUser(ctx, userID)
+}
+
+// BootstrapAdmin makes the user with email the first admin of the instance.
+// It fails with ErrAdminAlreadyExists once any admin exists; further admins
+// are appointed through the admin API.
+func (a *AdminService) BootstrapAdmin(ctx context.Context, email string) error {
+	rows, err := a.repo.PromoteFirstAdmin(ctx, email)
+	if err != nil {
+		return err
+	}
+	if rows > 0 {
+		return nil
+	}
+	admins, err := a.repo.CountAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	if admins > 0 {
+		return customErrors.ErrAdminAlreadyExists
+	}
+	return customErrors.USER_NOT_FOUND
+}
+
+// ListImportJobs returns import jobs of every user, newest first, optionally
+// narrowed to one user or status.
+func (a *AdminService) ListImportJobs(ctx context.Context, filter JobFilter) ([]admindb.ImportJob, error) {
+	limit, offset, err := filter.Page.Bounds()
+	if err != nil {
+		return nil, err
+	}
+	params := admindb.ListImportJobsParams{
+		UserID: filter.userID(),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if filter.Status != nil {
+		status := admindb.ImportJobStatus(*filter.Status)
+		if !importJobStatuses[status] {
+			return nil, customErrors.ErrInvalidJobStatus
+		}
+		params.Status = admindb.NullImportJobStatus{ImportJobStatus: status, Valid: true}
+	}
+	jobs, err := a.repo.ListImportJobs(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if jobs == nil {
+		jobs = []admindb.ImportJob{}
+	}
+	return jobs, nil
+}
+
+// ListExportJobs returns export jobs of every user, newest first, optionally
+// narrowed to one user or status.
+func (a *AdminService) ListExportJobs(ctx context.Context, filter JobFilter) ([]admindb.ExportJob, error) {
+	limit, offset, err := filter.Page.Bounds()
+	if err != nil {
+		return nil, err
+	}
+	params := admindb.ListExportJobsParams{
+		UserID: filter.userID(),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if filter.Status != nil {
+		status := admindb.ExportJobStatus(*filter.Status)
+		if !exportJobStatuses[status] {
+			return nil, customErrors.ErrInvalidJobStatus
+		}
+		params.Status = admindb.NullExportJobStatus{ExportJobStatus: status, Valid: true}
+	}
+	jobs, err := a.repo.ListExportJobs(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if jobs == nil {
+		jobs = []admindb.ExportJob{}
+	}
+	return jobs, nil
+}
+
+// TransferProject makes newOwnerID the owner of projectID, e.g. when the
+// previous owner was disabled. The new owner must belong to the project's
+// organization; the previous owner stays on the project as an editor.
+func (a *AdminService) TransferProject(ctx context.Context, projectID int, newOwnerID int) (*admindb.Project, error) {
+	project, err := a.repo.GetProject(ctx, int64(projectID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrProjectIDNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if project.UserID == int32(newOwnerID) {
+		return &project, nil
+	}
+	member, err := a.repo.IsOrganizationMember(ctx, admindb.IsOrganizationMemberParams{
+		OrganizationID: project.OrganizationID,
+		UserID:         int32(newOwnerID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, customErrors.ErrNotOrganizationMember
+	}
+
+	previousOwner := project.UserID
+	if err := a.repo.SetProjectOwner(ctx, admindb.SetProjectOwnerParams{ID: project.ID, UserID: int32(newOwnerID)}); err != nil {
+		return nil, err
+	}
+	if err := a.repo.UpsertProjectOwner(ctx, admindb.UpsertProjectOwnerParams{ProjectID: project.ID, UserID: int32(newOwnerID)}); err != nil {
+		return nil, err
+	}
+	if err := a.repo.DemoteProjectOwner(ctx, admindb.DemoteProjectOwnerParams{ProjectID: project.ID, UserID: previousOwner}); err != nil {
+		return nil, err
+	}
+	project.UserID = int32(newOwnerID)
+	return &project, nil
+}
+
+var importJobStatuses = map[admindb.ImportJobStatus]bool{
+	admindb.ImportJobStatusPending:    true,
+	admindb.ImportJobStatusInProgress: true,
+	admindb.ImportJobStatusCompleted:  true,
+	admindb.ImportJobStatusFailed:     true,
+}
+
+var exportJobStatuses = map[admindb.ExportJobStatus]bool{
+	admindb.ExportJobStatusPending:    true,
+	admindb.ExportJobStatusProcessing: true,
+	admindb.ExportJobStatusCompleted:  true,
+	admindb.ExportJobStatusFailed:     true,
+}
@@ -0,0 +1,141 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	admindb "github.com/Gkemhcs/taskpilot/internal/admin/gen"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSetUserDisabled(t *testing.T) {
+	repo := new(MockAdminRepo)
+	service := NewAdminService(repo)
+	repo.On("SetUserDisabled", mock.Anything, admindb.SetUserDisabledParams{ID: 202, Disabled: true}).Return(int64(1), nil)
+	repo.On("SetUserDisabled", mock.Anything, mock.Anything).Return(int64(0), nil)
+	repo.On("GetUser", mock.Anything, int32(202)).Return(admindb.GetUserRow{ID: 202}, nil)
+
+	testCases := []struct {
+		name          string
+		adminID       int
+		userID        int
+		expectedError error
+	}{
+		{name: "admin disables a user", adminID: 1, userID: 202},
+		{name: "admin cannot disable themselves", adminID: 1, userID: 1, expectedError: customErrors.ErrCannotModifySelf},
+		{name: "unknown user", adminID: 1, userID: 404, expectedError: customErrors.USER_NOT_FOUND},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := service.SetUserDisabled(context.TODO(), tc.adminID, tc.userID, true)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestSetUserAdmin(t *testing.T) {
+	repo := new(MockAdminRepo)
+	service := NewAdminService(repo)
+
+	t.Run("admin cannot demote themselves", func(t *testing.T) {
+		_, err := service.SetUserAdmin(context.TODO(), 1, 1, false)
+		assert.Equal(t, customErrors.ErrCannotModifySelf, err)
+		repo.AssertNotCalled(t, "SetUserAdmin", mock.Anything, mock.Anything)
+	})
+
+	t.Run("admin promotes a user", func(t *testing.T) {
+		repo.On("SetUserAdmin", mock.Anything, admindb.SetUserAdminParams{ID: 202, IsAdmin: true}).Return(int64(1), nil).Once()
+		repo.On("GetUser", mock.Anything, int32(202)).Return(admindb.GetUserRow{ID: 202, IsAdmin: true}, nil).Once()
+
+		user, err := service.SetUserAdmin(context.TODO(), 1, 202, true)
+		assert.NoError(t, err)
+		assert.True(t, user.IsAdmin)
+	})
+}
+
+func TestBootstrapAdmin(t *testing.T) {
+	testCases := []struct {
+		name          string
+		promoted      int64
+		admins        int64
+		expectedError error
+	}{
+		{name: "first admin is promoted", promoted: 1},
+		{name: "an admin already exists", admins: 1, expectedError: customErrors.ErrAdminAlreadyExists},
+		{name: "unknown email", expectedError: customErrors.USER_NOT_FOUND},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockAdminRepo)
+			repo.On("PromoteFirstAdmin", mock.Anything, "root@taskpilot.dev").Return(tc.promoted, nil)
+			repo.On("CountAdmins", mock.Anything).Return(tc.admins, nil)
+
+			err := NewAdminService(repo).BootstrapAdmin(context.TODO(), "root@taskpilot.dev")
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestListJobs(t *testing.T) {
+	repo := new(MockAdminRepo)
+	service := NewAdminService(repo)
+	failed := "failed"
+	userID := int32(7)
+	repo.On("ListImportJobs", mock.Anything, admindb.ListImportJobsParams{
+		UserID: sql.NullInt32{Int32: 7, Valid: true},
+		Status: admindb.NullImportJobStatus{ImportJobStatus: admindb.ImportJobStatusFailed, Valid: true},
+		Limit:  types.DefaultPageSize,
+	}).Return([]admindb.ImportJob(nil), nil)
+
+	t.Run("filters are passed to the query", func(t *testing.T) {
+		jobs, err := service.ListImportJobs(context.TODO(), JobFilter{UserID: &userID, Status: &failed})
+		assert.NoError(t, err)
+		assert.NotNil(t, jobs)
+	})
+
+	t.Run("statuses of the other job type are rejected", func(t *testing.T) {
+		processing := "processing"
+		_, err := service.ListImportJobs(context.TODO(), JobFilter{Status: &processing})
+		assert.Equal(t, customErrors.ErrInvalidJobStatus, err)
+	})
+
+	t.Run("page size is limited", func(t *testing.T) {
+		limit := int32(types.MaxPageSize + 1)
+		_, err := service.ListExportJobs(context.TODO(), JobFilter{Page: types.Page{Limit: &limit}})
+		assert.Equal(t, customErrors.ErrInvalidPagination, err)
+	})
+}
+
+func TestTransferProject(t *testing.T) {
+	repo := new(MockAdminRepo)
+	service := NewAdminService(repo)
+	repo.On("GetProject", mock.Anything, int64(5)).Return(admindb.Project{ID: 5, UserID: 101, OrganizationID: 7}, nil)
+	repo.On("GetProject", mock.Anything, mock.Anything).Return(admindb.Project{}, sql.ErrNoRows)
+	repo.On("IsOrganizationMember", mock.Anything, admindb.IsOrganizationMemberParams{OrganizationID: 7, UserID: 202}).Return(true, nil)
+	repo.On("IsOrganizationMember", mock.Anything, mock.Anything).Return(false, nil)
+
+	t.Run("owner changes and the previous owner becomes an editor", func(t *testing.T) {
+		repo.On("SetProjectOwner", mock.Anything, admindb.SetProjectOwnerParams{ID: 5, UserID: 202}).Return(nil).Once()
+		repo.On("UpsertProjectOwner", mock.Anything, admindb.UpsertProjectOwnerParams{ProjectID: 5, UserID: 202}).Return(nil).Once()
+		repo.On("DemoteProjectOwner", mock.Anything, admindb.DemoteProjectOwnerParams{ProjectID: 5, UserID: 101}).Return(nil).Once()
+
+		project, err := service.TransferProject(context.TODO(), 5, 202)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(202), project.UserID)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("new owner must belong to the organization", func(t *testing.T) {
+		_, err := service.TransferProject(context.TODO(), 5, 303)
+		assert.Equal(t, customErrors.ErrNotOrganizationMember, err)
+	})
+
+	t.Run("unknown project", func(t *testing.T) {
+		_, err := service.TransferProject(context.TODO(), 9, 202)
+		assert.Equal(t, customErrors.ErrProjectIDNotExist, err)
+	})
+}
@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"database/sql"
+
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+// JobFilter narrows the job listings to one user or status.
+type JobFilter struct {
+	types.Page
+	UserID *int32  `form:"user_id"`
+	Status *string `form:"status"`
+}
+
+func (f JobFilter) userID() sql.NullInt32 {
+	if f.UserID == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *f.UserID, Valid: true}
+}
+
+type UpdateUserRequest struct {
+	IsAdmin  *bool `json:"is_admin"`
+	Disabled *bool `json:"disabled"`
+}
+
+type TransferProjectRequest struct {
+	Email string `json:"email" binding:"required"`
+}
@@ -0,0 +1,17 @@
+-- name: ListAuditEvents :many
+SELECT id, actor_id, action, entity_type, entity_id, project_id, before, after, request_id, ip, created_at FROM audit_events
+WHERE (sqlc.narg('actor_id')::int IS NULL OR actor_id = sqlc.narg('actor_id'))
+  AND (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action'))
+  AND (sqlc.narg('entity_type')::text IS NULL OR entity_type = sqlc.narg('entity_type'))
+  AND (sqlc.narg('entity_id')::text IS NULL OR entity_id = sqlc.narg('entity_id'))
+  AND (sqlc.narg('project_id')::bigint IS NULL OR project_id = sqlc.narg('project_id'))
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_to')::timestamptz IS NULL OR created_at < sqlc.narg('created_to'))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListProjectAuditEvents :many
+SELECT id, actor_id, action, entity_type, entity_id, project_id, before, after, request_id, ip, created_at FROM audit_events
+WHERE project_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
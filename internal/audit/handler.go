@@ -0,0 +1,153 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/middleware"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func NewAuditHandler(logger *logrus.Logger, auditService *AuditService, jwtManager *auth.JWTManager) *AuditHandler {
+	return &AuditHandler{
+		logger:       logger,
+		auditService: auditService,
+		jwtManager:   jwtManager,
+	}
+}
+
+type AuditHandler struct {
+	logger       *logrus.Logger
+	auditService *AuditService
+	jwtManager   *auth.JWTManager
+}
+
+// RegisterAuditRoutes registers the instance-wide audit log for admins and the
+// history of a single project for its members.
+func RegisterAuditRoutes(r *gin.RouterGroup, handler *AuditHandler, binder middleware.TenantBinder, orgResolver middleware.OrganizationResolver) {
+	r.GET("/audit",
+		middleware.JWTAuthMiddleware(handler.logger, handler.jwtManager),
+		middleware.RequireSession(),
+		middleware.RequireAdmin(handler.logger, binder),
+		handler.ListEvents,
+	)
+	r.GET("/projects/:id/audit",
+		middleware.JWTAuthMiddleware(handler.logger, handler.jwtManager),
+		middleware.RequireScope(handler.logger, "projects"),
+		middleware.RequireOrganization(handler.logger, orgResolver),
+		handler.ListProjectEvents,
+	)
+}
+
+// ListEvents lists the audit log of the whole instance
+// @Summary      List audit events
+// @Description  Lists every recorded create, update and delete, newest first. Admin only.
+// @Tags         audit
+// @Produce      json
+// @Param        actor_id     query     int     false  "Only events caused by this user"
+// @Param        action       query     string  false  "create, update or delete"
+// @Param        entity_type  query     string  false  "user, project, project_member, task, import_job or export_job"
+// @Param        entity_id    query     string  false  "Only events of this entity, requires entity_type to be meaningful"
+// @Param        project_id   query     int     false  "Only events of this project, its members and tasks"
+// @Param        from         query     string  false  "Only events at or after this RFC 3339 time"
+// @Param        to           query     string  false  "Only events before this RFC 3339 time"
+// @Param        limit        query     int     false  "Page size (default 20, max 100)"
+// @Param        offset       query     int     false  "Number of events to skip"
+// @Success      200          {object}  map[string]interface{}
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      403          {object}  utils.ErrorResponse
+// @Router       /api/v1/audit [get]
+// @Security BearerAuth
+func (a *AuditHandler) ListEvents(c *gin.Context) {
+	var filter Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	events, err := a.auditService.ListEvents(ctx, filter)
+	if err != nil {
+		a.logger.Errorf("unable to list audit events %v", err)
+		utils.Error(c, auditErrorStatus(err), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, events)
+}
+
+// ListProjectEvents lists the history of a project
+// @Summary      List project history
+// @Description  Lists every recorded change to the project, its members and its tasks, newest first
+// @Tags         audit
+// @Produce      json
+// @Param        id      path      int  true   "Project ID"
+// @Param        limit   query     int  false  "Page size (default 20, max 100)"
+// @Param        offset  query     int  false  "Number of events to skip"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  utils.ErrorResponse
+// @Failure      403     {object}  utils.ErrorResponse
+// @Failure      404     {object}  utils.ErrorResponse
+// @Router       /api/v1/projects/{id}/audit [get]
+// @Security BearerAuth
+func (a *AuditHandler) ListProjectEvents(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidProjectId.Error())
+		return
+	}
+	var page types.Page
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	userID, ok := a.userID(c)
+	if !ok {
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	events, err := a.auditService.ListProjectEvents(ctx, userID, orgID, projectID, page)
+	if err != nil {
+		a.logger.Errorf("unable to list the history of project %d %v", projectID, err)
+		utils.Error(c, auditErrorStatus(err), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, events)
+}
+
+func (a *AuditHandler) userID(c *gin.Context) (int, bool) {
+	val, exists := c.Get("userID")
+	if !exists {
+		a.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return 0, false
+	}
+	userID, ok := val.(int)
+	if !ok {
+		a.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return 0, false
+	}
+	return userID, true
+}
+
+// auditErrorStatus maps validation errors to 400 and everything else through
+// utils.ErrorStatus.
+func auditErrorStatus(err error) int {
+	switch err {
+	case customErrors.ErrInvalidPagination, customErrors.ErrInvalidAuditAction, customErrors.ErrInvalidAuditEntityType:
+		return http.StatusBadRequest
+	}
+	return utils.ErrorStatus(err, http.StatusInternalServerError)
+}
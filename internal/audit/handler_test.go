@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	auditdb "github.com/Gkemhcs/taskpilot/internal/audit/gen"
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	"github.com/Gkemhcs/taskpilot/internal/db"
+	"github.com/Gkemhcs/taskpilot/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// accountStatuses is an auth.AccountStatusReader backed by a map.
+type accountStatuses map[int]auth.AccountStatus
+
+func (a accountStatuses) AccountStatus(_ context.Context, userID int) (auth.AccountStatus, error) {
+	return a[userID], nil
+}
+
+func TestAuditHandlers(t *testing.T) {
+	jwtManager := auth.NewJWTManager(auth.CreateJwtManagerParams{
+		AccessTokenDuration:  10 * time.Minute,
+		RefreshTokenDuration: 10 * time.Hour,
+		AccessTokenKey:       "rnk3mkrk3rk3rk3",
+		RefreshTokenKey:      "21ieh12iei21eji12e",
+		Accounts:             accountStatuses{1: {IsAdmin: true}},
+	})
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo := new(MockAuditRepo)
+	repo.On("ListAuditEvents", mock.Anything, mock.Anything).Return([]auditdb.AuditEvent{}, nil)
+	repo.On("ListProjectAuditEvents", mock.Anything, mock.Anything).Return([]auditdb.AuditEvent{}, nil)
+	resolver := new(middleware.MockOrganizationResolver)
+	resolver.On("ResolveOrganization", mock.Anything, mock.Anything, 0).Return(7, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	// the handlers only talk to the mock repo, so the binder never opens a connection
+	RegisterAuditRoutes(r.Group("/api/v1"), NewAuditHandler(logger, NewAuditService(repo, newAuthorizer()), jwtManager), db.NewTenantDB(nil), resolver)
+
+	adminTokens, err := jwtManager.Generate(context.TODO(), 1, "root", "root@taskpilot.dev")
+	require.NoError(t, err)
+	memberTokens, err := jwtManager.Generate(context.TODO(), 101, "dev", "dev@taskpilot.dev")
+	require.NoError(t, err)
+	admin, member := adminTokens.AccessToken, memberTokens.AccessToken
+
+	testCases := []struct {
+		testName           string
+		path               string
+		token              string
+		expectedStatusCode int
+	}{
+		{testName: "admin lists the audit log", path: "/api/v1/audit?entity_type=task&entity_id=42&action=delete", token: admin, expectedStatusCode: http.StatusOK},
+		{testName: "members cannot list the audit log", path: "/api/v1/audit", token: member, expectedStatusCode: http.StatusForbidden},
+		{testName: "invalid time filter", path: "/api/v1/audit?from=yesterday", token: admin, expectedStatusCode: http.StatusBadRequest},
+		{testName: "invalid action filter", path: "/api/v1/audit?action=read", token: admin, expectedStatusCode: http.StatusBadRequest},
+		{testName: "member reads project history", path: "/api/v1/projects/5/audit", token: member, expectedStatusCode: http.StatusOK},
+		{testName: "outsider reads project history", path: "/api/v1/projects/999/audit", token: member, expectedStatusCode: http.StatusForbidden},
+		{testName: "invalid project id", path: "/api/v1/projects/abc/audit", token: member, expectedStatusCode: http.StatusBadRequest},
+		{testName: "page size is limited", path: "/api/v1/projects/5/audit?limit=500", token: member, expectedStatusCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code, w.Body.String())
+		})
+	}
+}
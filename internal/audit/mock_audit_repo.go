@@ -0,0 +1,23 @@
+package audit
+
+import (
+	"context"
+
+	auditdb "github.com/Gkemhcs/taskpilot/internal/audit/gen"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditRepo is a mock implementation of the auditdb.Querier interface
+type MockAuditRepo struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepo) ListAuditEvents(ctx context.Context, arg auditdb.ListAuditEventsParams) ([]auditdb.AuditEvent, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]auditdb.AuditEvent), args.Error(1)
+}
+
+func (m *MockAuditRepo) ListProjectAuditEvents(ctx context.Context, arg auditdb.ListProjectAuditEventsParams) ([]auditdb.AuditEvent, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]auditdb.AuditEvent), args.Error(1)
+}
@@ -0,0 +1,104 @@
+// Package audit exposes the append-only audit log. Events are not written by
+// the services: triggers on the audited tables record every insert, update and
+// delete together with the actor, request id and client ip that db.TenantDB
+// binds to the connection, so rows written by the import workers or cascading
+// deletes are logged as well.
+package audit
+
+import (
+	"context"
+	"database/sql"
+
+	auditdb "github.com/Gkemhcs/taskpilot/internal/audit/gen"
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+// Actions are the values of audit_events.action.
+var actions = map[string]bool{"create": true, "update": true, "delete": true}
+
+// entityTypes are the values of audit_events.entity_type, one per audited table.
+var entityTypes = map[string]bool{
+	"user":           true,
+	"project":        true,
+	"project_member": true,
+	"task":           true,
+	"import_job":     true,
+	"export_job":     true,
+}
+
+func NewAuditService(repo auditdb.Querier, authorizer authz.Authorizer) *AuditService {
+	return &AuditService{
+		repo:       repo,
+		authorizer: authorizer,
+	}
+}
+
+// AuditService reads the audit log. ListEvents does not check the caller's
+// role; the admin route does that before calling it.
+type AuditService struct {
+	repo       auditdb.Querier
+	authorizer authz.Authorizer
+}
+
+// ListEvents returns a page of audit events of the whole instance, newest first.
+func (a *AuditService) ListEvents(ctx context.Context, filter Filter) ([]auditdb.AuditEvent, error) {
+	limit, offset, err := filter.Page.Bounds()
+	if err != nil {
+		return nil, err
+	}
+	if filter.Action != nil && !actions[*filter.Action] {
+		return nil, customErrors.ErrInvalidAuditAction
+	}
+	if filter.EntityType != nil && !entityTypes[*filter.EntityType] {
+		return nil, customErrors.ErrInvalidAuditEntityType
+	}
+	params := auditdb.ListAuditEventsParams{
+		Action:      nullString(filter.Action),
+		EntityType:  nullString(filter.EntityType),
+		EntityID:    nullString(filter.EntityID),
+		CreatedFrom: nullTime(filter.From),
+		CreatedTo:   nullTime(filter.To),
+		Limit:       limit,
+		Offset:      offset,
+	}
+	if filter.ActorID != nil {
+		params.ActorID = sql.NullInt32{Int32: *filter.ActorID, Valid: true}
+	}
+	if filter.ProjectID != nil {
+		params.ProjectID = sql.NullInt64{Int64: *filter.ProjectID, Valid: true}
+	}
+	events, err := a.repo.ListAuditEvents(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []auditdb.AuditEvent{}
+	}
+	return events, nil
+}
+
+// ListProjectEvents returns the history of a project, its members and its
+// tasks, newest first. Any member of the project may read it.
+func (a *AuditService) ListProjectEvents(ctx context.Context, userID int, orgID int, projectID int, page types.Page) ([]auditdb.AuditEvent, error) {
+	if err := a.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	limit, offset, err := page.Bounds()
+	if err != nil {
+		return nil, err
+	}
+	events, err := a.repo.ListProjectAuditEvents(ctx, auditdb.ListProjectAuditEventsParams{
+		ProjectID: sql.NullInt64{Int64: int64(projectID), Valid: true},
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []auditdb.AuditEvent{}
+	}
+	return events, nil
+}
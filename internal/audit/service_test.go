@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	auditdb "github.com/Gkemhcs/taskpilot/internal/audit/gen"
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newAuthorizer returns an authorizer for which project 999 belongs to
+// another user and every other project belongs to user 101.
+func newAuthorizer() authz.Authorizer {
+	authzRepo := new(authz.MockAuthzRepo)
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 999, UserID: 101, OrganizationID: 7}).Return(authzdb.GetProjectRoleRow{ID: 999, UserID: 4321}, nil)
+	authzRepo.On("GetProjectRole", mock.Anything, mock.Anything).Return(authzdb.GetProjectRoleRow{UserID: 101}, nil)
+	return authz.NewAuthorizationService(authzRepo)
+}
+
+func TestListEvents(t *testing.T) {
+	repo := new(MockAuditRepo)
+	service := NewAuditService(repo, newAuthorizer())
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	actorID, action, entityType, entityID := int32(101), "delete", "task", "42"
+	repo.On("ListAuditEvents", mock.Anything, auditdb.ListAuditEventsParams{
+		ActorID:     sql.NullInt32{Int32: 101, Valid: true},
+		Action:      sql.NullString{String: "delete", Valid: true},
+		EntityType:  sql.NullString{String: "task", Valid: true},
+		EntityID:    sql.NullString{String: "42", Valid: true},
+		CreatedFrom: sql.NullTime{Time: from, Valid: true},
+		Limit:       types.DefaultPageSize,
+	}).Return([]auditdb.AuditEvent(nil), nil)
+
+	t.Run("filters are passed to the query", func(t *testing.T) {
+		events, err := service.ListEvents(context.TODO(), Filter{
+			ActorID:    &actorID,
+			Action:     &action,
+			EntityType: &entityType,
+			EntityID:   &entityID,
+			From:       &from,
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, events)
+	})
+
+	negative := int32(-1)
+	testCases := []struct {
+		name          string
+		filter        Filter
+		expectedError error
+	}{
+		{name: "unknown action", filter: Filter{Action: &entityType}, expectedError: customErrors.ErrInvalidAuditAction},
+		{name: "unknown entity type", filter: Filter{EntityType: &action}, expectedError: customErrors.ErrInvalidAuditEntityType},
+		{name: "negative offset", filter: Filter{Page: types.Page{Offset: &negative}}, expectedError: customErrors.ErrInvalidPagination},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := service.ListEvents(context.TODO(), tc.filter)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestListProjectEvents(t *testing.T) {
+	repo := new(MockAuditRepo)
+	service := NewAuditService(repo, newAuthorizer())
+	repo.On("ListProjectAuditEvents", mock.Anything, auditdb.ListProjectAuditEventsParams{
+		ProjectID: sql.NullInt64{Int64: 5, Valid: true},
+		Limit:     types.DefaultPageSize,
+	}).Return([]auditdb.AuditEvent{{ID: 1, Action: "delete", EntityType: "task", EntityID: "42"}}, nil)
+
+	t.Run("members read the history of their project", func(t *testing.T) {
+		events, err := service.ListProjectEvents(context.TODO(), 101, 7, 5, types.Page{})
+		assert.NoError(t, err)
+		assert.Len(t, events, 1)
+	})
+
+	t.Run("outsiders are rejected", func(t *testing.T) {
+		_, err := service.ListProjectEvents(context.TODO(), 101, 7, 999, types.Page{})
+		assert.Equal(t, customErrors.ErrForbidden, err)
+		repo.AssertNumberOfCalls(t, "ListProjectAuditEvents", 1)
+	})
+}
@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+// Filter narrows the instance-wide audit log. Every field is optional; From is
+// inclusive and To exclusive.
+type Filter struct {
+	types.Page
+	ActorID    *int32     `form:"actor_id"`
+	Action     *string    `form:"action"`
+	EntityType *string    `form:"entity_type"`
+	EntityID   *string    `form:"entity_id"`
+	ProjectID  *int64     `form:"project_id"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to"   time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterJWKSRoute exposes the access token verification keys at
+// /.well-known/jwks.json. The route is public and lives outside /api/v1 so
+// that standard JWT libraries in other services can discover it.
+func RegisterJWKSRoute(router gin.IRoutes, jwtManager *JWTManager) {
+	router.GET("/.well-known/jwks.json", JWKSHandler(jwtManager))
+}
+
+// JWKSHandler returns the public keys that verify TaskPilot access tokens.
+func JWKSHandler(jwtManager *JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Verifiers may cache the set briefly; new keys are published before they activate
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, jwtManager.JWKS())
+	}
+}
@@ -1,107 +1,317 @@
-// GenerateAccessTokenFromRefresh generates a new access token using a valid refresh token.
-// It verifies the provided refresh token, extracts the user claims, and issues a new access token
-// for the user. Returns the new access token string or an error if the refresh token is invalid.
+// Package auth issues and verifies the access and refresh tokens used by the API.
+// Refresh tokens are rotated on every use and grouped into families so that a
+// leaked refresh token can be detected and the whole session revoked.
+// Access tokens are signed with RS256 or EdDSA keys identified by a kid header
+// and published as a JWKS, so other services can verify them without sharing a
+// secret. HS256 with a shared secret is still supported for deployments that
+// have not configured signing keys yet.
 package auth
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
+
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // JWTManager handles creation and verification of JWT tokens.
 type JWTManager struct {
-	
-	accessTokenSecretKey     string    
-	refreshTokenSecretKey string 
-	refreshTokenDuration time.Duration    // Secret key used to sign tokens
-	accessTokenDuration time.Duration // Duration for which the token is valid
+	accessTokenSecretKey  string
+	refreshTokenSecretKey string
+	refreshTokenDuration  time.Duration                    // Secret key used to sign tokens
+	accessTokenDuration   time.Duration                    // Duration for which the token is valid
+	store                 TokenStore                       // Refresh token families and access token denylist, nil when stateless
+	keys                  *keySet                          // Asymmetric access token keys, empty when only HS256 is configured
+	issuer                string                           // iss claim of issued access tokens, omitted when empty
+	personalAccessTokens  PersonalAccessTokenAuthenticator // Resolves tpat_ bearer tokens, nil when disabled
+	accounts              AccountStatusReader              // Supplies the admin claim and refuses disabled accounts, nil when unchecked
 }
 
 // NewJWTManager creates a new JWTManager with the given secret key and token duration.
 func NewJWTManager(params CreateJwtManagerParams) *JWTManager {
 	return &JWTManager{
-		accessTokenSecretKey: params.AccessTokenKey,
-		refreshTokenDuration: params.RefreshTokenDuration,
-		accessTokenDuration: params.AccessTokenDuration,
+		accessTokenSecretKey:  params.AccessTokenKey,
+		refreshTokenDuration:  params.RefreshTokenDuration,
+		accessTokenDuration:   params.AccessTokenDuration,
 		refreshTokenSecretKey: params.RefreshTokenKey,
+		store:                 params.TokenStore,
+		keys:                  newKeySet(params.SigningKeys),
+		issuer:                params.Issuer,
+		personalAccessTokens:  params.PersonalAccessTokens,
+		accounts:              params.Accounts,
 	}
 }
 
 // Generate creates a new JWT Access token for a user with the given userID and username.
-func (j *JWTManager)GenerateAccessToken(userID int,username string ,email string )(string,error){
+func (j *JWTManager) GenerateAccessToken(userID int, username string, email string) (string, error) {
+	return j.generateAccessToken(userID, username, email, "", 0, false)
+}
+
+// generateAccessToken signs an access token with a unique jti, tied to familyID when one is given.
+// A non-zero orgID selects the organization requests act in.
+func (j *JWTManager) generateAccessToken(userID int, username string, email string, familyID string, orgID int, isAdmin bool) (string, error) {
 	claims := &UserClaims{
 		UserID:   userID,
 		Username: username,
-		Email : email ,
+		Email:    email,
+		FamilyID: familyID,
+		OrgID:    orgID,
+		IsAdmin:  isAdmin,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),                                          // Unique token id (jti) used for revocation
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessTokenDuration)), // Set token expiration
-			IssuedAt:  jwt.NewNumericDate(time.Now()),                      // Set token issue time
+			IssuedAt:  jwt.NewNumericDate(time.Now()),                            // Set token issue time
+			Issuer:    j.issuer,
 		},
 	}
 
+	// Prefer the currently active asymmetric key, falling back to the shared secret
+	if key := j.keys.current(time.Now()); key != nil {
+		token := jwt.NewWithClaims(key.signingMethod(), claims)
+		token.Header["kid"] = key.ID
+		return token.SignedString(key.PrivateKey)
+	}
+	if j.accessTokenSecretKey == "" {
+		return "", customErrors.ErrNoActiveSigningKey
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(j.accessTokenSecretKey)) // Sign and return the token
 }
 
-
 // Generate creates a new JWT  Refreshtoken for a user with the given userID and username.
-func (j *JWTManager)GenerateRefreshToken(userID int,username string ,email string )(string,error){
+// The token starts a new family but is not recorded in the token store; use Generate for logins.
+func (j *JWTManager) GenerateRefreshToken(userID int, username string, email string) (string, error) {
+	token, _, err := j.generateRefreshToken(userID, username, email, uuid.NewString(), 0)
+	return token, err
+}
+
+// generateRefreshToken signs a refresh token belonging to familyID and returns it with its jti.
+func (j *JWTManager) generateRefreshToken(userID int, username string, email string, familyID string, orgID int) (string, string, error) {
+	jti := uuid.NewString()
 	claims := &UserClaims{
 		UserID:   userID,
 		Username: username,
-		Email : email ,
+		Email:    email,
+		FamilyID: familyID,
+		OrgID:    orgID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,                                                        // Unique token id (jti) used for one-time use
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.refreshTokenDuration)), // Set token expiration
-			IssuedAt:  jwt.NewNumericDate(time.Now()),                      // Set token issue time
+			IssuedAt:  jwt.NewNumericDate(time.Now()),                             // Set token issue time
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.refreshTokenSecretKey)) // Sign and return the token
+	signed, err := token.SignedString([]byte(j.refreshTokenSecretKey)) // Sign and return the token
+	return signed, jti, err
 }
 
 // Generate creates a new JWT Access token and Refresh token for a user with the given userID and username.
-func (j *JWTManager) Generate(userID int, username string,email string ) (*GenerateJwtResponse, error) {
-	refreshToken,err:=j.GenerateRefreshToken(userID,username,email)
-	if err!=nil{
-		return nil,err 
+// Every call starts a new refresh token family, i.e. a new session.
+func (j *JWTManager) Generate(ctx context.Context, userID int, username string, email string) (*GenerateJwtResponse, error) {
+	return j.issuePair(ctx, userID, username, email, uuid.NewString(), 0)
+}
+
+// GenerateForOrganization starts a new session like Generate whose tokens act
+// in orgID unless a request names another organization. The caller must have
+// checked that the user is a member of orgID.
+func (j *JWTManager) GenerateForOrganization(ctx context.Context, userID int, username string, email string, orgID int) (*GenerateJwtResponse, error) {
+	return j.issuePair(ctx, userID, username, email, uuid.NewString(), orgID)
+}
+
+// issuePair signs an access/refresh token pair in familyID and records the refresh token as unused.
+// Disabled accounts get customErrors.ErrAccountDisabled instead of tokens.
+func (j *JWTManager) issuePair(ctx context.Context, userID int, username string, email string, familyID string, orgID int) (*GenerateJwtResponse, error) {
+	var status AccountStatus
+	if j.accounts != nil {
+		var err error
+		status, err = j.accounts.AccountStatus(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if status.Disabled {
+			return nil, customErrors.ErrAccountDisabled
+		}
+	}
+	refreshToken, jti, err := j.generateRefreshToken(userID, username, email, familyID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if j.store != nil {
+		if err := j.store.SaveRefreshToken(ctx, jti, j.refreshTokenDuration); err != nil {
+			return nil, err
+		}
 	}
-	accessToken,err:=j.GenerateAccessToken(userID,username,email)
-	if err!=nil{
-		return nil,err
+	accessToken, err := j.generateAccessToken(userID, username, email, familyID, orgID, status.IsAdmin)
+	if err != nil {
+		return nil, err
 	}
 	return &GenerateJwtResponse{
 		RefreshToken: refreshToken,
-		AccessToken: accessToken,
-	},err
+		AccessToken:  accessToken,
+	}, nil
+}
 
+// RotateRefreshToken exchanges a valid refresh token for a new access/refresh token pair.
+// Each refresh token may be used once; presenting an already used refresh token is
+// treated as theft and revokes the whole family, logging out every holder of it.
+func (j *JWTManager) RotateRefreshToken(ctx context.Context, refreshToken string) (*GenerateJwtResponse, error) {
+	userClaims, err := j.VerifyRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if j.store != nil {
+		revoked, err := j.store.IsFamilyRevoked(ctx, userClaims.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, customErrors.ErrRefreshTokenRevoked
+		}
+		revoked, err = j.issuedBeforeUserRevocation(ctx, userClaims)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, customErrors.ErrRefreshTokenRevoked
+		}
+		err = j.store.ConsumeRefreshToken(ctx, userClaims.ID)
+		if errors.Is(err, customErrors.ErrRefreshTokenReused) {
+			if revokeErr := j.store.RevokeFamily(ctx, userClaims.FamilyID, j.refreshTokenDuration); revokeErr != nil {
+				return nil, revokeErr
+			}
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return j.issuePair(ctx, userClaims.UserID, userClaims.Username, userClaims.Email, userClaims.FamilyID, userClaims.OrgID)
+}
 
+// Revoke logs out the session behind accessClaims: the access token is denylisted
+// until it expires and its refresh token family is revoked. If refreshToken is
+// given and belongs to the same user, its family is revoked as well.
+func (j *JWTManager) Revoke(ctx context.Context, accessClaims *UserClaims, refreshToken string) error {
+	if j.store == nil {
+		return nil
+	}
+	if accessClaims.ExpiresAt != nil {
+		if err := j.store.DenyAccessToken(ctx, accessClaims.ID, time.Until(accessClaims.ExpiresAt.Time)); err != nil {
+			return err
+		}
+	}
+	families := []string{accessClaims.FamilyID}
+	if refreshToken != "" {
+		refreshClaims, err := j.VerifyRefreshToken(refreshToken)
+		if err != nil {
+			return err
+		}
+		if refreshClaims.UserID != accessClaims.UserID {
+			return customErrors.ErrForbidden
+		}
+		families = append(families, refreshClaims.FamilyID)
+	}
+	for _, familyID := range families {
+		if familyID == "" {
+			continue
+		}
+		if err := j.store.RevokeFamily(ctx, familyID, j.refreshTokenDuration); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+// IsRevoked reports whether a verified access token was logged out, either on
+// its own or because its refresh token family was revoked.
+func (j *JWTManager) IsRevoked(ctx context.Context, claims *UserClaims) (bool, error) {
+	if j.store == nil {
+		return false, nil
+	}
+	denied, err := j.store.IsAccessTokenDenied(ctx, claims.ID)
+	if err != nil || denied {
+		return denied, err
+	}
+	if claims.FamilyID != "" {
+		revoked, err := j.store.IsFamilyRevoked(ctx, claims.FamilyID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	return j.issuedBeforeUserRevocation(ctx, claims)
+}
+
+// RevokeUser logs the user out everywhere, e.g. after a password reset: every
+// access and refresh token issued before now stops working.
+func (j *JWTManager) RevokeUser(ctx context.Context, userID int) error {
+	if j.store == nil {
+		return nil
+	}
+	// refresh tokens outlive access tokens, so they bound how long the marker is needed
+	ttl := max(j.refreshTokenDuration, j.accessTokenDuration)
+	return j.store.RevokeUserTokens(ctx, userID, time.Now(), ttl)
+}
 
+// issuedBeforeUserRevocation reports whether the token predates a RevokeUser call.
+func (j *JWTManager) issuedBeforeUserRevocation(ctx context.Context, claims *UserClaims) (bool, error) {
+	revokedBefore, err := j.store.UserTokensRevokedBefore(ctx, claims.UserID)
+	if err != nil || revokedBefore.IsZero() || claims.IssuedAt == nil {
+		return false, err
+	}
+	// iat has second precision, tokens issued in the same second as the revocation stay valid
+	return claims.IssuedAt.Time.Before(revokedBefore.Truncate(time.Second)), nil
+}
 
-// GenerateAccessTokenFromRefresh generates a new access token from a valid refresh token.
-// It verifies the refresh token, extracts user claims, and issues a new access token.
-// Returns the new access token string or an error if the refresh token is invalid.
-func (j *JWTManager) GenerateAccessTokenFromRefresh(refreshToken string)(string,error){
-	userClaims,err:=j.VerifyRefreshToken(refreshToken)
-	if err!=nil{
-		return "",err
+// accessTokenKeyFunc resolves the verification key of an access token. Asymmetric
+// tokens are looked up by their kid and must use the algorithm of that key;
+// HS256 tokens are only accepted while a shared secret is configured and no
+// asymmetric key is active yet, as they are only issued then.
+func (j *JWTManager) accessTokenKeyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if j.accessTokenSecretKey == "" || j.keys.current(time.Now()) != nil {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(j.accessTokenSecretKey), nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keys.byID[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
 	}
-	accessToken,err:=j.GenerateAccessToken(userClaims.UserID,userClaims.Username,userClaims.Email)
-	if err!=nil{
-		return "",err
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.New("unexpected signing method")
 	}
-	return accessToken,nil 
+	return key.PrivateKey.Public(), nil
 }
 
+// VerifyPersonalAccessToken resolves a personal access token to the claims of
+// its owner, restricted to the scopes granted to the token.
+func (j *JWTManager) VerifyPersonalAccessToken(ctx context.Context, token string) (*UserClaims, error) {
+	if j.personalAccessTokens == nil {
+		return nil, customErrors.ErrInvalidPersonalAccessToken
+	}
+	return j.personalAccessTokens.AuthenticatePersonalAccessToken(ctx, token)
+}
+
+// JWKS returns the public keys that verify access tokens, including keys
+// scheduled to become active later so verifiers can cache them in advance.
+func (j *JWTManager) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range j.keys.keys {
+		jwks.Keys = append(jwks.Keys, key.jwk())
+	}
+	return jwks
+}
 
 // VerifyRefreshToken  verifies the jwt refresh token is valid  and returns claims if it is valid
 // It checks the signing method and expiration, returning an error if the token is invalid.
 // It returns the user claims if the token is valid.
-func(j *JWTManager) VerifyRefreshToken(refreshToken string)(*UserClaims,error){
+func (j *JWTManager) VerifyRefreshToken(refreshToken string) (*UserClaims, error) {
 
 	token, err := jwt.ParseWithClaims(
 		refreshToken,
@@ -120,13 +330,14 @@ func(j *JWTManager) VerifyRefreshToken(refreshToken string)(*UserClaims,error){
 	}
 
 	claims, ok := token.Claims.(*UserClaims)
-	
+
 	if !ok || !token.Valid {
 		return nil, errors.New("invalid token")
 	}
 
 	return claims, nil
 }
+
 // Verify parses and validates a JWT token string and returns the claims if valid.
 // It checks the signing method and expiration, returning an error if the token is invalid.
 // If the token is valid, it returns the user claims contained in the token.
@@ -134,30 +345,22 @@ func (j *JWTManager) Verify(tokenString string) (*UserClaims, error) {
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&UserClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			// Ensure the signing method is HMAC
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(j.accessTokenSecretKey), nil
-		},
+		j.accessTokenKeyFunc,
+		jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA, jwt.SigningMethodHS256.Alg()}),
 	)
-	if errors.Is(err,jwt.ErrTokenExpired){
-		return nil,customErrors.ErrTokenExpired
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, customErrors.ErrTokenExpired
 	}
 
 	if err != nil {
 		return nil, err
 	}
-	
-	claims, ok := token.Claims.(*UserClaims)
-
 
+	claims, ok := token.Claims.(*UserClaims)
 
 	if !ok || !token.Valid {
 		return nil, errors.New("invalid token")
 	}
-	
 
 	return claims, nil
 }
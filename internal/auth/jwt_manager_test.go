@@ -1,37 +1,45 @@
 package auth_test
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base32"
+	"encoding/pem"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Gkemhcs/taskpilot/internal/auth"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
-	
+	"github.com/stretchr/testify/mock"
 )
 
 var jwtManager *auth.JWTManager
 
-
-
-func getAccessTokenString(userID int,email string,userName string )(string){
-	tokenString,err:=jwtManager.GenerateAccessToken(userID,userName,email)
-	if err!=nil{
-		return  ""
+func getAccessTokenString(userID int, email string, userName string) string {
+	tokenString, err := jwtManager.GenerateAccessToken(userID, userName, email)
+	if err != nil {
+		return ""
 	}
-	return tokenString 
+	return tokenString
 }
 
-func getRefreshTokenString(userID int,email string,userName string )(string){
-	tokenString,err:=jwtManager.GenerateRefreshToken(userID,userName,email)
-	if err!=nil{
-		return  ""
+func getRefreshTokenString(userID int, email string, userName string) string {
+	tokenString, err := jwtManager.GenerateRefreshToken(userID, userName, email)
+	if err != nil {
+		return ""
 	}
-	return tokenString 
+	return tokenString
 }
 
-
-
 func TestMain(m *testing.M) {
 	params := auth.CreateJwtManagerParams{
 		AccessTokenDuration:  2 * time.Minute,
@@ -44,15 +52,13 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-
 func TestGenerate(t *testing.T) {
-	
 
 	userID := 101
 	username := "gkemhcs"
 	email := "mani@gkemhcs.com"
 
-	tokens, err := jwtManager.Generate(userID, username, email)
+	tokens, err := jwtManager.Generate(context.TODO(), userID, username, email)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, tokens.AccessToken)
 	assert.NotEmpty(t, tokens.RefreshToken)
@@ -70,53 +76,51 @@ func TestGenerate(t *testing.T) {
 	assert.Equal(t, userID, refreshClaims.UserID)
 }
 
-func TestGenerateAccessToken(t *testing.T){
+func TestGenerateAccessToken(t *testing.T) {
 
 	userID := 101
 	username := "gkemhcs"
 	email := "mani@gkemhcs.com"
-	_,err:=jwtManager.GenerateAccessToken(userID,username,email)
+	_, err := jwtManager.GenerateAccessToken(userID, username, email)
 	assert.NoError(t, err)
 }
 
-
-
-
-func TestGenerateAccessTokenFromRefresh(t *testing.T){
-	testCases:=[]struct{
-		name string 
-		refreshToken string 
-		expectedError bool 
+func TestRotateRefreshToken(t *testing.T) {
+	testCases := []struct {
+		name          string
+		refreshToken  string
+		expectedError bool
 	}{{
 		"Valid Refresh Token",
-		getRefreshTokenString(123,"gudi@gmail","eswar"),
-		false ,
+		getRefreshTokenString(123, "gudi@gmail", "eswar"),
+		false,
 	},
-	{
-		"Invalid Refresh Token",
-		"dknfinfknk",
-		true,
-	}}
-	for _,test := range testCases {
-		
-		_,err:=jwtManager.GenerateAccessTokenFromRefresh(test.refreshToken)
+		{
+			"Invalid Refresh Token",
+			"dknfinfknk",
+			true,
+		}}
+	for _, test := range testCases {
+
+		tokens, err := jwtManager.RotateRefreshToken(context.TODO(), test.refreshToken)
 		if test.expectedError {
 			assert.Error(t, err, test.name)
 		} else {
 			assert.NoError(t, err, test.name)
+			assert.NotEqual(t, test.refreshToken, tokens.RefreshToken, test.name)
 		}
-	} 
+	}
 }
-func TestVerify(t *testing.T){
+func TestVerify(t *testing.T) {
 
-	testCases := []struct{
-		name string 
-		tokenString string 
+	testCases := []struct {
+		name          string
+		tokenString   string
 		expectedError bool
 	}{
 		{
 			"Valid Access Token",
-			getAccessTokenString(1234,"gudi@gmail","koti"),
+			getAccessTokenString(1234, "gudi@gmail", "koti"),
 			false,
 		},
 		{
@@ -134,19 +138,18 @@ func TestVerify(t *testing.T){
 		}
 	}
 
-
 }
 
-func TestVerifyRefreshToken(t *testing.T){
+func TestVerifyRefreshToken(t *testing.T) {
 
-	testCases := []struct{
-		name string 
-		tokenString string 
+	testCases := []struct {
+		name          string
+		tokenString   string
 		expectedError bool
 	}{
 		{
 			"Valid Access Token",
-			getRefreshTokenString(1234,"gudi@gmail","koti"),
+			getRefreshTokenString(1234, "gudi@gmail", "koti"),
 			false,
 		},
 		{
@@ -164,5 +167,464 @@ func TestVerifyRefreshToken(t *testing.T){
 		}
 	}
 
-	
-}
\ No newline at end of file
+}
+
+func newStatefulJWTManager(store auth.TokenStore) *auth.JWTManager {
+	return auth.NewJWTManager(auth.CreateJwtManagerParams{
+		AccessTokenDuration:  2 * time.Minute,
+		RefreshTokenDuration: 24 * time.Hour,
+		AccessTokenKey:       "ffj3if3ifjeifnefn",
+		RefreshTokenKey:      "2ijij2ifi2fj32ii2ji",
+		TokenStore:           store,
+	})
+}
+
+func TestRefreshTokenRotationWithStore(t *testing.T) {
+	store := new(auth.MockTokenStore)
+	manager := newStatefulJWTManager(store)
+	store.On("SaveRefreshToken", mock.Anything, mock.Anything, 24*time.Hour).Return(nil)
+	store.On("UserTokensRevokedBefore", mock.Anything, 101).Return(time.Time{}, nil)
+
+	tokens, err := manager.Generate(context.TODO(), 101, "gkemhcs", "mani@gkemhcs.com")
+	assert.NoError(t, err)
+	refreshClaims, err := manager.VerifyRefreshToken(tokens.RefreshToken)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, refreshClaims.ID)
+	assert.NotEmpty(t, refreshClaims.FamilyID)
+
+	t.Run("first use rotates within the same family", func(t *testing.T) {
+		store.On("IsFamilyRevoked", mock.Anything, refreshClaims.FamilyID).Return(false, nil).Once()
+		store.On("ConsumeRefreshToken", mock.Anything, refreshClaims.ID).Return(nil).Once()
+
+		rotated, err := manager.RotateRefreshToken(context.TODO(), tokens.RefreshToken)
+		assert.NoError(t, err)
+		rotatedClaims, err := manager.VerifyRefreshToken(rotated.RefreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, refreshClaims.FamilyID, rotatedClaims.FamilyID)
+		assert.NotEqual(t, refreshClaims.ID, rotatedClaims.ID)
+	})
+
+	t.Run("reuse revokes the whole family", func(t *testing.T) {
+		store.On("IsFamilyRevoked", mock.Anything, refreshClaims.FamilyID).Return(false, nil).Once()
+		store.On("ConsumeRefreshToken", mock.Anything, refreshClaims.ID).Return(customErrors.ErrRefreshTokenReused).Once()
+		store.On("RevokeFamily", mock.Anything, refreshClaims.FamilyID, 24*time.Hour).Return(nil).Once()
+
+		_, err := manager.RotateRefreshToken(context.TODO(), tokens.RefreshToken)
+		assert.Equal(t, customErrors.ErrRefreshTokenReused, err)
+		store.AssertCalled(t, "RevokeFamily", mock.Anything, refreshClaims.FamilyID, 24*time.Hour)
+	})
+
+	t.Run("revoked family cannot rotate", func(t *testing.T) {
+		store.On("IsFamilyRevoked", mock.Anything, refreshClaims.FamilyID).Return(true, nil).Once()
+
+		_, err := manager.RotateRefreshToken(context.TODO(), tokens.RefreshToken)
+		assert.Equal(t, customErrors.ErrRefreshTokenRevoked, err)
+	})
+}
+
+func TestIsRevoked(t *testing.T) {
+	store := new(auth.MockTokenStore)
+	manager := newStatefulJWTManager(store)
+	store.On("SaveRefreshToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	tokens, err := manager.Generate(context.TODO(), 101, "gkemhcs", "mani@gkemhcs.com")
+	assert.NoError(t, err)
+	claims, err := manager.Verify(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, claims.ID)
+
+	store.On("IsAccessTokenDenied", mock.Anything, claims.ID).Return(false, nil)
+	store.On("IsFamilyRevoked", mock.Anything, claims.FamilyID).Return(true, nil)
+
+	revoked, err := manager.IsRevoked(context.TODO(), claims)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func newRSASigningKey(t *testing.T, id string, activeFrom time.Time) *auth.SigningKey {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	key, err := auth.NewSigningKey(id, privateKey, activeFrom)
+	assert.NoError(t, err)
+	return key
+}
+
+func newEd25519SigningKey(t *testing.T, id string, activeFrom time.Time) *auth.SigningKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	key, err := auth.NewSigningKey(id, privateKey, activeFrom)
+	assert.NoError(t, err)
+	return key
+}
+
+func newAsymmetricJWTManager(secret string, keys ...*auth.SigningKey) *auth.JWTManager {
+	return auth.NewJWTManager(auth.CreateJwtManagerParams{
+		AccessTokenDuration:  2 * time.Minute,
+		RefreshTokenDuration: 24 * time.Hour,
+		AccessTokenKey:       secret,
+		RefreshTokenKey:      "2ijij2ifi2fj32ii2ji",
+		SigningKeys:          keys,
+		Issuer:               "taskpilot",
+	})
+}
+
+func TestAsymmetricAccessTokens(t *testing.T) {
+	testCases := []struct {
+		name string
+		key  *auth.SigningKey
+	}{
+		{"RS256", newRSASigningKey(t, "rsa-1", time.Time{})},
+		{"EdDSA", newEd25519SigningKey(t, "ed-1", time.Time{})},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := newAsymmetricJWTManager("", tc.key)
+
+			tokenString, err := manager.GenerateAccessToken(101, "gkemhcs", "mani@gkemhcs.com")
+			assert.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(tokenString, &auth.UserClaims{})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.name, parsed.Method.Alg())
+			assert.Equal(t, tc.key.ID, parsed.Header["kid"])
+
+			claims, err := manager.Verify(tokenString)
+			assert.NoError(t, err)
+			assert.Equal(t, 101, claims.UserID)
+			assert.Equal(t, "taskpilot", claims.Issuer)
+		})
+	}
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	now := time.Now()
+	oldKey := newRSASigningKey(t, "2026-01", now.Add(-48*time.Hour))
+	currentKey := newEd25519SigningKey(t, "2026-02", now.Add(-time.Hour))
+	scheduledKey := newRSASigningKey(t, "2026-03", now.Add(24*time.Hour))
+
+	beforeRotation := newAsymmetricJWTManager("", oldKey)
+	oldToken, err := beforeRotation.GenerateAccessToken(101, "gkemhcs", "mani@gkemhcs.com")
+	assert.NoError(t, err)
+
+	manager := newAsymmetricJWTManager("", scheduledKey, oldKey, currentKey)
+
+	t.Run("newest active key signs", func(t *testing.T) {
+		tokenString, err := manager.GenerateAccessToken(101, "gkemhcs", "mani@gkemhcs.com")
+		assert.NoError(t, err)
+		parsed, _, err := jwt.NewParser().ParseUnverified(tokenString, &auth.UserClaims{})
+		assert.NoError(t, err)
+		assert.Equal(t, currentKey.ID, parsed.Header["kid"])
+	})
+
+	t.Run("tokens signed with a previous key still verify", func(t *testing.T) {
+		_, err := manager.Verify(oldToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("tokens from removed keys are rejected", func(t *testing.T) {
+		_, err := newAsymmetricJWTManager("", currentKey).Verify(oldToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("jwks publishes every configured key", func(t *testing.T) {
+		jwks := manager.JWKS()
+		kids := []string{}
+		for _, key := range jwks.Keys {
+			kids = append(kids, key.Kid)
+			assert.Equal(t, "sig", key.Use)
+			if key.Alg == auth.AlgorithmEdDSA {
+				assert.Equal(t, "OKP", key.Kty)
+				assert.Equal(t, "Ed25519", key.Crv)
+				assert.NotEmpty(t, key.X)
+			} else {
+				assert.Equal(t, "RSA", key.Kty)
+				assert.NotEmpty(t, key.N)
+				assert.Equal(t, "AQAB", key.E)
+			}
+		}
+		assert.Equal(t, []string{"2026-01", "2026-02", "2026-03"}, kids)
+	})
+
+	t.Run("no active key and no secret", func(t *testing.T) {
+		_, err := newAsymmetricJWTManager("", scheduledKey).GenerateAccessToken(101, "gkemhcs", "mani@gkemhcs.com")
+		assert.Equal(t, customErrors.ErrNoActiveSigningKey, err)
+	})
+}
+
+func TestVerifyHS256AccessTokens(t *testing.T) {
+	hsToken := getAccessTokenString(101, "mani@gkemhcs.com", "gkemhcs")
+
+	// Until the first key is active the shared secret keeps signing tokens
+	_, err := newAsymmetricJWTManager("ffj3if3ifjeifnefn", newRSASigningKey(t, "rsa-1", time.Now().Add(time.Hour))).Verify(hsToken)
+	assert.NoError(t, err)
+
+	_, err = newAsymmetricJWTManager("", newRSASigningKey(t, "rsa-1", time.Now().Add(time.Hour))).Verify(hsToken)
+	assert.Error(t, err)
+
+	// Once it is, a token signed with the secret is no longer accepted
+	_, err = newAsymmetricJWTManager("ffj3if3ifjeifnefn", newRSASigningKey(t, "rsa-1", time.Time{})).Verify(hsToken)
+	assert.Error(t, err)
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	rsaPath := filepath.Join(dir, "rsa.pem")
+	assert.NoError(t, os.WriteFile(rsaPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), 0o600))
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	edBytes, err := x509.MarshalPKCS8PrivateKey(edKey)
+	assert.NoError(t, err)
+	edPath := filepath.Join(dir, "ed25519.pem")
+	assert.NoError(t, os.WriteFile(edPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: edBytes}), 0o600))
+
+	key, err := auth.LoadSigningKey("rsa", rsaPath, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, auth.AlgorithmRS256, key.Algorithm)
+
+	key, err = auth.LoadSigningKey("ed", edPath, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, auth.AlgorithmEdDSA, key.Algorithm)
+
+	_, err = auth.LoadSigningKey("missing", filepath.Join(dir, "missing.pem"), time.Time{})
+	assert.Error(t, err)
+}
+
+func TestPersonalAccessTokenScopes(t *testing.T) {
+	token, hash, err := auth.GeneratePersonalAccessToken()
+	assert.NoError(t, err)
+	assert.True(t, auth.IsPersonalAccessToken(token))
+	assert.Equal(t, auth.HashPersonalAccessToken(token), hash)
+	assert.Len(t, hash, 64)
+
+	granted := []string{auth.ScopeTasksWrite, auth.ScopeProjectsRead}
+	assert.True(t, auth.HasScope(granted, auth.ScopeTasksRead), "write implies read")
+	assert.True(t, auth.HasScope(granted, auth.ScopeTasksWrite))
+	assert.True(t, auth.HasScope(granted, auth.ScopeProjectsRead))
+	assert.False(t, auth.HasScope(granted, auth.ScopeProjectsWrite))
+	assert.False(t, auth.HasScope(granted, auth.ScopeImportWrite))
+	assert.False(t, auth.IsValidScope("users:admin"))
+
+	// without an authenticator personal access tokens are rejected outright
+	_, err = jwtManager.VerifyPersonalAccessToken(context.TODO(), token)
+	assert.Equal(t, customErrors.ErrInvalidPersonalAccessToken, err)
+}
+
+func TestRevokeUser(t *testing.T) {
+	store := new(auth.MockTokenStore)
+	manager := newStatefulJWTManager(store)
+	store.On("SaveRefreshToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	store.On("IsAccessTokenDenied", mock.Anything, mock.Anything).Return(false, nil)
+	store.On("IsFamilyRevoked", mock.Anything, mock.Anything).Return(false, nil)
+
+	tokens, err := manager.Generate(context.TODO(), 101, "gkemhcs", "mani@gkemhcs.com")
+	assert.NoError(t, err)
+	claims, err := manager.Verify(tokens.AccessToken)
+	assert.NoError(t, err)
+
+	store.On("RevokeUserTokens", mock.Anything, 101, mock.Anything, 24*time.Hour).Return(nil).Once()
+	assert.NoError(t, manager.RevokeUser(context.TODO(), 101))
+
+	t.Run("tokens issued before the revocation are revoked", func(t *testing.T) {
+		store.On("UserTokensRevokedBefore", mock.Anything, 101).Return(claims.IssuedAt.Time.Add(2*time.Second), nil).Once()
+		revoked, err := manager.IsRevoked(context.TODO(), claims)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+
+		store.On("UserTokensRevokedBefore", mock.Anything, 101).Return(claims.IssuedAt.Time.Add(2*time.Second), nil).Once()
+		_, err = manager.RotateRefreshToken(context.TODO(), tokens.RefreshToken)
+		assert.Equal(t, customErrors.ErrRefreshTokenRevoked, err)
+	})
+
+	t.Run("tokens issued in the same second stay valid", func(t *testing.T) {
+		store.On("UserTokensRevokedBefore", mock.Anything, 101).Return(claims.IssuedAt.Time, nil).Once()
+		revoked, err := manager.IsRevoked(context.TODO(), claims)
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+}
+
+func TestValidateTOTP(t *testing.T) {
+	// RFC 6238 SHA1 test vectors, truncated to six digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, v := range vectors {
+		step, ok := auth.ValidateTOTP(secret, v.code, time.Unix(v.unix, 0))
+		assert.True(t, ok, "code at %d", v.unix)
+		assert.Equal(t, v.unix/30, step)
+	}
+
+	// a code from the previous time step is still accepted, one from two steps ago is not
+	_, ok := auth.ValidateTOTP(secret, "081804", time.Unix(1111111109+30, 0))
+	assert.True(t, ok)
+	_, ok = auth.ValidateTOTP(secret, "081804", time.Unix(1111111109+60, 0))
+	assert.False(t, ok)
+	_, ok = auth.ValidateTOTP(secret, "12345", time.Unix(59, 0))
+	assert.False(t, ok)
+
+	generated, err := auth.GenerateTOTPSecret()
+	assert.NoError(t, err)
+	uri, err := url.Parse(auth.TOTPURI("TaskPilot", "koti@taskpilot.dev", generated))
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/TaskPilot:koti@taskpilot.dev", uri.Path)
+	assert.Equal(t, generated, uri.Query().Get("secret"))
+	assert.Equal(t, "TaskPilot", uri.Query().Get("issuer"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := auth.GenerateRecoveryCodes(auth.RecoveryCodeCount)
+	assert.NoError(t, err)
+	assert.Len(t, codes, auth.RecoveryCodeCount)
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`, code)
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+	// users may retype codes without dashes or in upper case
+	typed := strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))
+	assert.Equal(t, auth.HashRecoveryCode(codes[0]), auth.HashRecoveryCode(typed))
+	assert.NotEqual(t, auth.HashRecoveryCode(codes[0]), auth.HashRecoveryCode(codes[1]))
+}
+
+func TestMFAChallenge(t *testing.T) {
+	challenge, err := jwtManager.GenerateMFAChallenge(42)
+	assert.NoError(t, err)
+
+	userID, err := jwtManager.VerifyMFAChallenge(challenge)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, userID)
+
+	// a challenge is not a session
+	_, err = jwtManager.Verify(challenge)
+	assert.Error(t, err)
+	_, err = jwtManager.VerifyRefreshToken(challenge)
+	assert.Error(t, err)
+
+	// and session tokens are not challenges
+	_, err = jwtManager.VerifyMFAChallenge(getRefreshTokenString(42, "koti@taskpilot.dev", "koti"))
+	assert.ErrorIs(t, err, customErrors.ErrInvalidMFAChallenge)
+	_, err = jwtManager.VerifyMFAChallenge(getAccessTokenString(42, "koti@taskpilot.dev", "koti"))
+	assert.ErrorIs(t, err, customErrors.ErrInvalidMFAChallenge)
+}
+
+func TestLoginGuard(t *testing.T) {
+	ctx := context.Background()
+	policy := auth.LoginGuardPolicy{
+		Window:          15 * time.Minute,
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        8 * time.Second,
+		MaxFailures:     10,
+		IPMaxFailures:   50,
+		LockoutDuration: 15 * time.Minute,
+	}
+
+	t.Run("failures back off exponentially and then lock the account", func(t *testing.T) {
+		expected := map[int64]time.Duration{4: time.Second, 5: 2 * time.Second, 6: 4 * time.Second, 7: 8 * time.Second, 8: 8 * time.Second, 9: 8 * time.Second}
+		for failures := int64(1); failures <= 10; failures++ {
+			store := new(auth.MockLoginAttemptStore)
+			guard := auth.NewLoginGuard(store, policy)
+			store.On("RecordFailure", ctx, "account:koti@taskpilot.dev", policy.Window).Return(failures, nil)
+			store.On("RecordFailure", ctx, "ip:10.0.0.1", policy.Window).Return(int64(1), nil)
+			store.On("Block", ctx, mock.Anything, mock.Anything).Return(nil)
+
+			assert.NoError(t, guard.RecordFailure(ctx, " Koti@TaskPilot.dev", "10.0.0.1", auth.LoginFailureInvalidCredentials))
+
+			switch {
+			case failures == 10:
+				store.AssertCalled(t, "Block", ctx, "lockout:account:koti@taskpilot.dev", policy.LockoutDuration)
+			case expected[failures] > 0:
+				store.AssertCalled(t, "Block", ctx, "account:koti@taskpilot.dev", expected[failures])
+			default:
+				store.AssertNotCalled(t, "Block", mock.Anything, mock.Anything, mock.Anything)
+			}
+		}
+	})
+
+	t.Run("locked account gets a distinct error", func(t *testing.T) {
+		store := new(auth.MockLoginAttemptStore)
+		guard := auth.NewLoginGuard(store, policy)
+		store.On("BlockedFor", ctx, "lockout:account:koti@taskpilot.dev").Return(12*time.Minute, nil)
+
+		wait, err := guard.Check(ctx, "koti@taskpilot.dev", "10.0.0.1")
+		assert.ErrorIs(t, err, customErrors.ErrAccountLocked)
+		assert.Equal(t, 12*time.Minute, wait)
+	})
+
+	t.Run("backoff and blocked ips are throttled", func(t *testing.T) {
+		store := new(auth.MockLoginAttemptStore)
+		guard := auth.NewLoginGuard(store, policy)
+		store.On("BlockedFor", ctx, "lockout:account:koti@taskpilot.dev").Return(time.Duration(0), nil)
+		store.On("BlockedFor", ctx, "account:koti@taskpilot.dev").Return(time.Duration(0), nil)
+		store.On("BlockedFor", ctx, "ip:10.0.0.1").Return(3*time.Minute, nil)
+
+		wait, err := guard.Check(ctx, "koti@taskpilot.dev", "10.0.0.1")
+		assert.ErrorIs(t, err, customErrors.ErrTooManyLoginAttempts)
+		assert.Equal(t, 3*time.Minute, wait)
+	})
+
+	t.Run("ip is locked when crossing its threshold", func(t *testing.T) {
+		store := new(auth.MockLoginAttemptStore)
+		guard := auth.NewLoginGuard(store, policy)
+		store.On("RecordFailure", ctx, "account:nobody@taskpilot.dev", policy.Window).Return(int64(1), nil)
+		store.On("RecordFailure", ctx, "ip:10.0.0.1", policy.Window).Return(int64(50), nil)
+		store.On("Block", ctx, "ip:10.0.0.1", policy.LockoutDuration).Return(nil)
+
+		assert.NoError(t, guard.RecordFailure(ctx, "nobody@taskpilot.dev", "10.0.0.1", auth.LoginFailureInvalidCredentials))
+		store.AssertCalled(t, "Block", ctx, "ip:10.0.0.1", policy.LockoutDuration)
+	})
+
+	t.Run("success clears only the account", func(t *testing.T) {
+		store := new(auth.MockLoginAttemptStore)
+		guard := auth.NewLoginGuard(store, policy)
+		store.On("Reset", ctx, "account:koti@taskpilot.dev").Return(nil)
+
+		assert.NoError(t, guard.RecordSuccess(ctx, "koti@taskpilot.dev"))
+		store.AssertNumberOfCalls(t, "Reset", 1)
+	})
+
+	t.Run("nil guard allows everything", func(t *testing.T) {
+		var guard *auth.LoginGuard
+		_, err := guard.Check(ctx, "koti@taskpilot.dev", "10.0.0.1")
+		assert.NoError(t, err)
+		assert.NoError(t, guard.RecordFailure(ctx, "koti@taskpilot.dev", "10.0.0.1", auth.LoginFailureInvalidCredentials))
+		assert.NoError(t, guard.RecordSuccess(ctx, "koti@taskpilot.dev"))
+	})
+}
+
+func TestGenerateForOrganization(t *testing.T) {
+	tokens, err := jwtManager.GenerateForOrganization(context.TODO(), 101, "gkemhcs", "mani@gkemhcs.com", 42)
+	assert.NoError(t, err)
+	claims, err := jwtManager.Verify(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, claims.OrgID)
+
+	// the selected organization survives refresh token rotation
+	rotated, err := jwtManager.RotateRefreshToken(context.TODO(), tokens.RefreshToken)
+	assert.NoError(t, err)
+	claims, err = jwtManager.Verify(rotated.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, claims.OrgID)
+
+	// plain logins act in the personal workspace
+	tokens, err = jwtManager.Generate(context.TODO(), 101, "gkemhcs", "mani@gkemhcs.com")
+	assert.NoError(t, err)
+	claims, err = jwtManager.Verify(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Zero(t, claims.OrgID)
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey is an asymmetric key used to sign access tokens. Its ID is sent
+// as the kid header so verifiers can pick the matching public key.
+type SigningKey struct {
+	ID         string
+	Algorithm  string        // RS256 or EdDSA, derived from the key type
+	PrivateKey crypto.Signer // *rsa.PrivateKey or ed25519.PrivateKey
+	ActiveFrom time.Time     // Key signs new tokens from this time on; zero means immediately
+}
+
+// NewSigningKey wraps an RSA or Ed25519 private key and infers its algorithm.
+func NewSigningKey(id string, privateKey crypto.Signer, activeFrom time.Time) (*SigningKey, error) {
+	if id == "" {
+		return nil, errors.New("signing key id must not be empty")
+	}
+	key := &SigningKey{
+		ID:         id,
+		PrivateKey: privateKey,
+		ActiveFrom: activeFrom,
+	}
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("signing key %q: RSA keys must be at least 2048 bits", id)
+		}
+		key.Algorithm = AlgorithmRS256
+	case ed25519.PrivateKey:
+		key.Algorithm = AlgorithmEdDSA
+	default:
+		return nil, fmt.Errorf("signing key %q: unsupported key type %T", id, privateKey)
+	}
+	return key, nil
+}
+
+// LoadSigningKey reads a PEM encoded RSA (PKCS#1 or PKCS#8) or Ed25519 (PKCS#8)
+// private key from path.
+func LoadSigningKey(id string, path string, activeFrom time.Time) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %q: %w", id, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %q: no PEM block found in %s", id, path)
+	}
+
+	var parsed any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("signing key %q: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing key %q: %w", id, err)
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("signing key %q: unsupported key type %T", id, parsed)
+	}
+	return NewSigningKey(id, signer, activeFrom)
+}
+
+// signingMethod returns the jwt signing method matching the key algorithm.
+func (k *SigningKey) signingMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// keySet holds every configured key. All of them are accepted for
+// verification and published in the JWKS, while only the most recently
+// activated one signs new tokens. Publishing a key before its ActiveFrom
+// gives other services time to fetch it before tokens signed with it appear.
+type keySet struct {
+	keys []*SigningKey // Sorted by ActiveFrom, oldest first
+	byID map[string]*SigningKey
+}
+
+// newKeySet indexes keys by ID. Key IDs are expected to be unique; if one is
+// repeated the first key with that ID wins.
+func newKeySet(keys []*SigningKey) *keySet {
+	set := &keySet{
+		byID: make(map[string]*SigningKey, len(keys)),
+	}
+	for _, key := range keys {
+		if _, exists := set.byID[key.ID]; exists {
+			continue
+		}
+		set.byID[key.ID] = key
+		set.keys = append(set.keys, key)
+	}
+	sort.SliceStable(set.keys, func(i, j int) bool {
+		return set.keys[i].ActiveFrom.Before(set.keys[j].ActiveFrom)
+	})
+	return set
+}
+
+// current returns the key that should sign tokens at now, or nil if no key
+// has been activated yet.
+func (s *keySet) current(now time.Time) *SigningKey {
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if !s.keys[i].ActiveFrom.After(now) {
+			return s.keys[i]
+		}
+	}
+	return nil
+}
+
+// JWK is a single public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS is the JSON Web Key Set served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// jwk converts the public half of the key to its JWK representation.
+func (k *SigningKey) jwk() JWK {
+	encode := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{
+		Kid: k.ID,
+		Use: "sig",
+		Alg: k.Algorithm,
+	}
+	switch pub := k.PrivateKey.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encode(pub.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encode(pub)
+	}
+	return jwk
+}
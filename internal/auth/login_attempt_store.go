@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailuresKeyPrefix = "auth:login:failures:" // key -> failed attempts in the current window
+	loginBlockedKeyPrefix  = "auth:login:blocked:"  // presence blocks the key until it expires
+)
+
+// NewRedisLoginAttemptStore creates a LoginAttemptStore backed by Redis, so
+// every API replica sees the same counters.
+func NewRedisLoginAttemptStore(client *redis.Client) *RedisLoginAttemptStore {
+	return &RedisLoginAttemptStore{
+		client: client,
+	}
+}
+
+// RedisLoginAttemptStore is the Redis backed LoginAttemptStore used by the API server.
+type RedisLoginAttemptStore struct {
+	client *redis.Client
+}
+
+// RecordFailure increments the failure counter, starting its window on the first failure.
+func (r *RedisLoginAttemptStore) RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, loginFailuresKeyPrefix+key)
+		// NX keeps the window fixed instead of sliding with every failure
+		pipe.ExpireNX(ctx, loginFailuresKeyPrefix+key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Block sets a marker that expires after d.
+func (r *RedisLoginAttemptStore) Block(ctx context.Context, key string, d time.Duration) error {
+	return r.client.Set(ctx, loginBlockedKeyPrefix+key, 1, d).Err()
+}
+
+// BlockedFor returns the remaining lifetime of the block marker.
+func (r *RedisLoginAttemptStore) BlockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, loginBlockedKeyPrefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// PTTL reports missing keys as negative durations
+	return max(ttl, 0), nil
+}
+
+// Reset deletes the failure counter and the block marker.
+func (r *RedisLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	return r.client.Del(ctx, loginFailuresKeyPrefix+key, loginBlockedKeyPrefix+key).Err()
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MFAChallengeDuration is how long a user has to enter their second factor
+// after the password was accepted.
+const MFAChallengeDuration = 5 * time.Minute
+
+// mfaChallengeAudience keeps challenge tokens from being accepted anywhere else.
+const mfaChallengeAudience = "mfa-challenge"
+
+// mfaChallengeClaims are the claims of the token returned by a password login
+// when the account has a second factor. It only proves the password was correct.
+type mfaChallengeClaims struct {
+	UserID int `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// GenerateMFAChallenge signs a short-lived token that stands in for the token
+// pair until the user's second factor has been verified.
+func (j *JWTManager) GenerateMFAChallenge(userID int) (string, error) {
+	now := time.Now()
+	claims := &mfaChallengeClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{mfaChallengeAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(MFAChallengeDuration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(j.mfaChallengeKey())
+}
+
+// VerifyMFAChallenge validates a challenge token and returns the user it was issued to.
+func (j *JWTManager) VerifyMFAChallenge(challenge string) (int, error) {
+	claims := &mfaChallengeClaims{}
+	_, err := jwt.ParseWithClaims(
+		challenge,
+		claims,
+		func(token *jwt.Token) (interface{}, error) {
+			return j.mfaChallengeKey(), nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(mfaChallengeAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.UserID == 0 {
+		return 0, customErrors.ErrInvalidMFAChallenge
+	}
+	return claims.UserID, nil
+}
+
+// mfaChallengeKey derives the challenge signing key from the refresh token
+// secret, so a challenge token never verifies as a refresh or access token.
+func (j *JWTManager) mfaChallengeKey() []byte {
+	mac := hmac.New(sha256.New, []byte(j.refreshTokenSecretKey))
+	mac.Write([]byte(mfaChallengeAudience))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTokenStore is a mock implementation of the TokenStore interface
+type MockTokenStore struct {
+	mock.Mock
+}
+
+func (m *MockTokenStore) SaveRefreshToken(ctx context.Context, jti string, ttl time.Duration) error {
+	args := m.Called(ctx, jti, ttl)
+	return args.Error(0)
+}
+
+func (m *MockTokenStore) ConsumeRefreshToken(ctx context.Context, jti string) error {
+	args := m.Called(ctx, jti)
+	return args.Error(0)
+}
+
+func (m *MockTokenStore) RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error {
+	args := m.Called(ctx, familyID, ttl)
+	return args.Error(0)
+}
+
+func (m *MockTokenStore) IsFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	args := m.Called(ctx, familyID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTokenStore) DenyAccessToken(ctx context.Context, jti string, ttl time.Duration) error {
+	args := m.Called(ctx, jti, ttl)
+	return args.Error(0)
+}
+
+func (m *MockTokenStore) IsAccessTokenDenied(ctx context.Context, jti string) (bool, error) {
+	args := m.Called(ctx, jti)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTokenStore) RevokeUserTokens(ctx context.Context, userID int, before time.Time, ttl time.Duration) error {
+	args := m.Called(ctx, userID, before, ttl)
+	return args.Error(0)
+}
+
+func (m *MockTokenStore) UserTokensRevokedBefore(ctx context.Context, userID int) (time.Time, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(time.Time), args.Error(1)
+}
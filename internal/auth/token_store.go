@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	refreshTokenKeyPrefix  = "auth:refresh:"        // refresh_token jti -> "unused" | "used"
+	revokedFamilyKeyPrefix = "auth:family:revoked:" // presence marks a revoked refresh token family
+	deniedAccessKeyPrefix  = "auth:access:denied:"  // presence marks a revoked access token jti
+	revokedUserKeyPrefix   = "auth:user:revoked:"   // user id -> unix time before which all tokens are revoked
+
+	refreshTokenUnused = "unused"
+	refreshTokenUsed   = "used"
+)
+
+// NewRedisTokenStore creates a TokenStore that keeps refresh token state and
+// the access token denylist in Redis. Every key expires together with the
+// token it describes, so the store never needs to be cleaned up.
+func NewRedisTokenStore(client *redis.Client) *RedisTokenStore {
+	return &RedisTokenStore{
+		client: client,
+	}
+}
+
+// RedisTokenStore is the Redis backed TokenStore used by the API server.
+type RedisTokenStore struct {
+	client *redis.Client
+}
+
+// SaveRefreshToken records a freshly issued refresh token as unused.
+func (r *RedisTokenStore) SaveRefreshToken(ctx context.Context, jti string, ttl time.Duration) error {
+	return r.client.Set(ctx, refreshTokenKeyPrefix+jti, refreshTokenUnused, ttl).Err()
+}
+
+// ConsumeRefreshToken atomically flips the token from unused to used.
+func (r *RedisTokenStore) ConsumeRefreshToken(ctx context.Context, jti string) error {
+	// SET ... XX GET KEEPTTL only touches existing keys and hands back the previous state
+	previous, err := r.client.SetArgs(ctx, refreshTokenKeyPrefix+jti, refreshTokenUsed, redis.SetArgs{
+		Mode:    "XX",
+		Get:     true,
+		KeepTTL: true,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return customErrors.ErrRefreshTokenRevoked
+	}
+	if err != nil {
+		return err
+	}
+	if previous != refreshTokenUnused {
+		return customErrors.ErrRefreshTokenReused
+	}
+	return nil
+}
+
+// RevokeFamily marks every refresh and access token of the family as revoked.
+func (r *RedisTokenStore) RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error {
+	return r.client.Set(ctx, revokedFamilyKeyPrefix+familyID, 1, ttl).Err()
+}
+
+// IsFamilyRevoked reports whether RevokeFamily was called for the family.
+func (r *RedisTokenStore) IsFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	n, err := r.client.Exists(ctx, revokedFamilyKeyPrefix+familyID).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// DenyAccessToken adds the access token jti to the denylist until it would have expired anyway.
+func (r *RedisTokenStore) DenyAccessToken(ctx context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	return r.client.Set(ctx, deniedAccessKeyPrefix+jti, 1, ttl).Err()
+}
+
+// IsAccessTokenDenied reports whether the access token jti is on the denylist.
+func (r *RedisTokenStore) IsAccessTokenDenied(ctx context.Context, jti string) (bool, error) {
+	n, err := r.client.Exists(ctx, deniedAccessKeyPrefix+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RevokeUserTokens revokes every token of the user issued before the given time.
+func (r *RedisTokenStore) RevokeUserTokens(ctx context.Context, userID int, before time.Time, ttl time.Duration) error {
+	return r.client.Set(ctx, revokedUserKeyPrefix+strconv.Itoa(userID), before.Unix(), ttl).Err()
+}
+
+// UserTokensRevokedBefore returns the time set by RevokeUserTokens, or the zero time.
+func (r *RedisTokenStore) UserTokensRevokedBefore(ctx context.Context, userID int) (time.Time, error) {
+	unix, err := r.client.Get(ctx, revokedUserKeyPrefix+strconv.Itoa(userID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(unix, 0), nil
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -8,22 +9,80 @@ import (
 
 // UserClaims defines the custom JWT claims for authenticated users.
 type UserClaims struct {
-	UserID               int    `json:"user_id"` // Unique user ID
-	Username             string `json:"name"`    // Username of the user
-	Email                string `json:"email"`
-	jwt.RegisteredClaims        // Standard JWT claims (exp, iat, etc.)
+	UserID               int      `json:"user_id"` // Unique user ID
+	Username             string   `json:"name"`    // Username of the user
+	Email                string   `json:"email"`
+	FamilyID             string   `json:"fid,omitempty"`      // Refresh token family the token was issued from
+	OrgID                int      `json:"org_id,omitempty"`   // Organization selected for the session, 0 for the personal workspace
+	IsAdmin              bool     `json:"is_admin,omitempty"` // Instance administrator, allowed on /api/v1/admin
+	Scopes               []string `json:"-"`                  // Granted scopes, only set for personal access tokens
+	jwt.RegisteredClaims          // Standard JWT claims (exp, iat, jti, etc.)
 }
 
-
-type CreateJwtManagerParams struct{
-	AccessTokenDuration time.Duration
+type CreateJwtManagerParams struct {
+	AccessTokenDuration  time.Duration
 	RefreshTokenDuration time.Duration
-	AccessTokenKey  string 
-	RefreshTokenKey string 
+	AccessTokenKey       string
+	RefreshTokenKey      string
+	TokenStore           TokenStore                       // Optional; without it tokens are stateless and cannot be rotated or revoked
+	SigningKeys          []*SigningKey                    // Optional RS256/EdDSA keys for access tokens; AccessTokenKey is only used as a fallback
+	Issuer               string                           // Optional iss claim set on access tokens
+	PersonalAccessTokens PersonalAccessTokenAuthenticator // Optional; without it personal access tokens are rejected
+	Accounts             AccountStatusReader              // Optional; without it tokens carry no admin claim and disabled accounts are not checked
 }
 
+// AccountStatus is the state of an account that is baked into its tokens.
+type AccountStatus struct {
+	IsAdmin  bool
+	Disabled bool
+}
+
+// AccountStatusReader looks up the current status of an account whenever a
+// token pair is issued or rotated, so a demoted or disabled user loses the
+// admin claim or the session at the next refresh at the latest.
+type AccountStatusReader interface {
+	AccountStatus(ctx context.Context, userID int) (AccountStatus, error)
+}
 
 type GenerateJwtResponse struct {
-	AccessToken string 
-	RefreshToken string 
-}
\ No newline at end of file
+	AccessToken  string
+	RefreshToken string
+}
+
+// TokenStore keeps the server-side state needed for refresh token rotation
+// and revocation. Refresh tokens issued from one login form a family; using a
+// refresh token twice revokes the whole family.
+type TokenStore interface {
+	// SaveRefreshToken records a newly issued refresh token as unused for ttl.
+	SaveRefreshToken(ctx context.Context, jti string, ttl time.Duration) error
+	// ConsumeRefreshToken marks the refresh token as used. It returns
+	// customErrors.ErrRefreshTokenReused if it was already used and
+	// customErrors.ErrRefreshTokenRevoked if it is unknown or expired.
+	ConsumeRefreshToken(ctx context.Context, jti string) error
+	// RevokeFamily revokes every token carrying familyID for ttl.
+	RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error
+	// IsFamilyRevoked reports whether the family has been revoked.
+	IsFamilyRevoked(ctx context.Context, familyID string) (bool, error)
+	// DenyAccessToken puts a single access token on the denylist for ttl.
+	DenyAccessToken(ctx context.Context, jti string, ttl time.Duration) error
+	// IsAccessTokenDenied reports whether the access token is on the denylist.
+	IsAccessTokenDenied(ctx context.Context, jti string) (bool, error)
+	// RevokeUserTokens revokes every token of the user issued before the given time, for ttl.
+	RevokeUserTokens(ctx context.Context, userID int, before time.Time, ttl time.Duration) error
+	// UserTokensRevokedBefore returns the time set by RevokeUserTokens, or the zero time.
+	UserTokensRevokedBefore(ctx context.Context, userID int) (time.Time, error)
+}
+
+// LoginAttemptStore counts failed logins and holds temporary blocks, keyed by
+// account or client IP.
+type LoginAttemptStore interface {
+	// RecordFailure counts a failed attempt for key and returns the number of
+	// failures since the first one in the current window.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error)
+	// Block rejects attempts for key during d.
+	Block(ctx context.Context, key string, d time.Duration) error
+	// BlockedFor returns how long key stays blocked, zero when it is not.
+	BlockedFor(ctx context.Context, key string) (time.Duration, error)
+	// Reset forgets the failures and block of key.
+	Reset(ctx context.Context, key string) error
+}
@@ -0,0 +1,12 @@
+-- name: GetProjectRole :one
+SELECT p.id, p.user_id, pm.role
+FROM projects p
+LEFT JOIN project_members pm ON pm.project_id = p.id AND pm.user_id = $2
+WHERE p.id = $1 AND p.organization_id = $3;
+
+-- name: GetTaskProjectRole :one
+SELECT t.project_id, p.user_id, pm.role
+FROM tasks t
+JOIN projects p ON p.id = t.project_id
+LEFT JOIN project_members pm ON pm.project_id = t.project_id AND pm.user_id = $2
+WHERE t.id = $1 AND p.organization_id = $3;
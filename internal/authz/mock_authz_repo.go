@@ -0,0 +1,23 @@
+package authz
+
+import (
+	"context"
+
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuthzRepo is a mock implementation of the authzdb.Querier interface
+type MockAuthzRepo struct {
+	mock.Mock
+}
+
+func (m *MockAuthzRepo) GetProjectRole(ctx context.Context, arg authzdb.GetProjectRoleParams) (authzdb.GetProjectRoleRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(authzdb.GetProjectRoleRow), args.Error(1)
+}
+
+func (m *MockAuthzRepo) GetTaskProjectRole(ctx context.Context, arg authzdb.GetTaskProjectRoleParams) (authzdb.GetTaskProjectRoleRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(authzdb.GetTaskProjectRoleRow), args.Error(1)
+}
@@ -0,0 +1,94 @@
+package authz
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+)
+
+// NewAuthorizationService creates an Authorizer backed by the membership queries in authz.sql.
+func NewAuthorizationService(repo authzdb.Querier) *AuthorizationService {
+	return &AuthorizationService{
+		repo: repo,
+	}
+}
+
+// AuthorizationService grants access to a project and its tasks based on the
+// caller's role in project_members. The user recorded in projects.user_id is
+// always treated as an owner, even if their membership row is missing.
+// Projects of another organization than the caller's current one are
+// reported as missing, so IDs cannot be probed across tenants.
+type AuthorizationService struct {
+	repo authzdb.Querier // Role lookups joined across projects and project_members
+}
+
+// AuthorizeProject verifies that the project exists in orgID and userID's role allows action.
+func (a *AuthorizationService) AuthorizeProject(ctx context.Context, userID int, orgID int, projectID int, action Action) error {
+	row, err := a.repo.GetProjectRole(ctx, authzdb.GetProjectRoleParams{
+		ID:             int64(projectID),
+		UserID:         int32(userID),
+		OrganizationID: int64(orgID),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return customErrors.ErrProjectIDNotExist
+	}
+	if err != nil {
+		return err
+	}
+	return checkRole(effectiveRole(userID, row.UserID, row.Role), action)
+}
+
+// AuthorizeTask verifies that the task exists in orgID and userID's role in its parent project allows action.
+func (a *AuthorizationService) AuthorizeTask(ctx context.Context, userID int, orgID int, taskID int, action Action) error {
+	row, err := a.repo.GetTaskProjectRole(ctx, authzdb.GetTaskProjectRoleParams{
+		ID:             int64(taskID),
+		UserID:         int32(userID),
+		OrganizationID: int64(orgID),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return customErrors.ErrTaskNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return checkRole(effectiveRole(userID, row.UserID, row.Role), action)
+}
+
+// effectiveRole resolves the caller's role, promoting the project creator to owner.
+func effectiveRole(userID int, ownerID int32, role authzdb.NullProjectRole) authzdb.NullProjectRole {
+	if int(ownerID) == userID {
+		return authzdb.NullProjectRole{ProjectRole: authzdb.ProjectRoleOWNER, Valid: true}
+	}
+	return role
+}
+
+// checkRole reports whether role permits action. A missing role means the
+// caller is not a member of the project at all.
+func checkRole(role authzdb.NullProjectRole, action Action) error {
+	if !role.Valid {
+		return customErrors.ErrForbidden
+	}
+	if roleAllows(role.ProjectRole, action) {
+		return nil
+	}
+	return customErrors.ErrForbidden
+}
+
+// roleAllows reports whether a project member with role may perform action.
+func roleAllows(role authzdb.ProjectRole, action Action) bool {
+	switch action {
+	case ActionRead:
+		return true
+	case ActionComment:
+		return role == authzdb.ProjectRoleOWNER || role == authzdb.ProjectRoleEDITOR || role == authzdb.ProjectRoleCOMMENTER
+	case ActionWrite:
+		return role == authzdb.ProjectRoleOWNER || role == authzdb.ProjectRoleEDITOR
+	case ActionManage:
+		return role == authzdb.ProjectRoleOWNER
+	default:
+		return false
+	}
+}
@@ -0,0 +1,198 @@
+package authz
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var authzService *AuthorizationService
+var mockRepo *MockAuthzRepo
+
+// orgID is the organization every lookup in these tests is scoped to
+const orgID = 7
+
+func TestMain(m *testing.M) {
+	mockRepo = new(MockAuthzRepo)
+	authzService = NewAuthorizationService(mockRepo)
+	os.Exit(m.Run())
+}
+
+func role(r authzdb.ProjectRole) authzdb.NullProjectRole {
+	return authzdb.NullProjectRole{ProjectRole: r, Valid: true}
+}
+
+func TestAuthorizeProject(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		userID        int
+		projectID     int
+		action        Action
+		returnRow     authzdb.GetProjectRoleRow
+		returnError   error
+		expectedError error
+	}{
+		{
+			testName:      "creator can manage project without membership row",
+			userID:        1234,
+			projectID:     24,
+			action:        ActionManage,
+			returnRow:     authzdb.GetProjectRoleRow{ID: 24, UserID: 1234},
+			expectedError: nil,
+		},
+		{
+			testName:      "non member is forbidden",
+			userID:        99,
+			projectID:     24,
+			action:        ActionRead,
+			returnRow:     authzdb.GetProjectRoleRow{ID: 24, UserID: 1234},
+			expectedError: customErrors.ErrForbidden,
+		},
+		{
+			testName:      "viewer can read",
+			userID:        99,
+			projectID:     24,
+			action:        ActionRead,
+			returnRow:     authzdb.GetProjectRoleRow{ID: 24, UserID: 1234, Role: role(authzdb.ProjectRoleVIEWER)},
+			expectedError: nil,
+		},
+		{
+			testName:      "viewer cannot write",
+			userID:        99,
+			projectID:     24,
+			action:        ActionWrite,
+			returnRow:     authzdb.GetProjectRoleRow{ID: 24, UserID: 1234, Role: role(authzdb.ProjectRoleVIEWER)},
+			expectedError: customErrors.ErrForbidden,
+		},
+		{
+			testName:      "editor can write",
+			userID:        99,
+			projectID:     24,
+			action:        ActionWrite,
+			returnRow:     authzdb.GetProjectRoleRow{ID: 24, UserID: 1234, Role: role(authzdb.ProjectRoleEDITOR)},
+			expectedError: nil,
+		},
+		{
+			testName:      "editor cannot manage members",
+			userID:        99,
+			projectID:     24,
+			action:        ActionManage,
+			returnRow:     authzdb.GetProjectRoleRow{ID: 24, UserID: 1234, Role: role(authzdb.ProjectRoleEDITOR)},
+			expectedError: customErrors.ErrForbidden,
+		},
+		{
+			testName:      "missing project",
+			userID:        1234,
+			projectID:     404,
+			action:        ActionRead,
+			returnRow:     authzdb.GetProjectRoleRow{},
+			returnError:   sql.ErrNoRows,
+			expectedError: customErrors.ErrProjectIDNotExist,
+		},
+		{
+			testName:      "db error",
+			userID:        1234,
+			projectID:     24,
+			action:        ActionRead,
+			returnRow:     authzdb.GetProjectRoleRow{},
+			returnError:   errors.New("db down"),
+			expectedError: errors.New("db down"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			mockRepo.Calls = nil
+			mockRepo.ExpectedCalls = nil
+			params := authzdb.GetProjectRoleParams{ID: int64(tc.projectID), UserID: int32(tc.userID), OrganizationID: orgID}
+			mockRepo.On("GetProjectRole", mock.Anything, params).Return(tc.returnRow, tc.returnError)
+
+			err := authzService.AuthorizeProject(context.TODO(), tc.userID, orgID, tc.projectID, tc.action)
+			assert.Equal(t, tc.expectedError, err)
+			mockRepo.AssertCalled(t, "GetProjectRole", mock.Anything, params)
+		})
+	}
+}
+
+func TestAuthorizeTask(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		userID        int
+		taskID        int
+		action        Action
+		returnRow     authzdb.GetTaskProjectRoleRow
+		returnError   error
+		expectedError error
+	}{
+		{
+			testName:      "owner of parent project can update task",
+			userID:        1234,
+			taskID:        101,
+			action:        ActionWrite,
+			returnRow:     authzdb.GetTaskProjectRoleRow{ProjectID: 24, UserID: 1234},
+			expectedError: nil,
+		},
+		{
+			testName:      "commenter can comment but not write",
+			userID:        99,
+			taskID:        101,
+			action:        ActionComment,
+			returnRow:     authzdb.GetTaskProjectRoleRow{ProjectID: 24, UserID: 1234, Role: role(authzdb.ProjectRoleCOMMENTER)},
+			expectedError: nil,
+		},
+		{
+			testName:      "commenter cannot write",
+			userID:        99,
+			taskID:        101,
+			action:        ActionWrite,
+			returnRow:     authzdb.GetTaskProjectRoleRow{ProjectID: 24, UserID: 1234, Role: role(authzdb.ProjectRoleCOMMENTER)},
+			expectedError: customErrors.ErrForbidden,
+		},
+		{
+			testName:      "non member is forbidden",
+			userID:        99,
+			taskID:        101,
+			action:        ActionRead,
+			returnRow:     authzdb.GetTaskProjectRoleRow{ProjectID: 24, UserID: 1234},
+			expectedError: customErrors.ErrForbidden,
+		},
+		{
+			testName:      "task of another organization is missing",
+			userID:        1234,
+			taskID:        102,
+			action:        ActionRead,
+			returnRow:     authzdb.GetTaskProjectRoleRow{},
+			returnError:   sql.ErrNoRows,
+			expectedError: customErrors.ErrTaskNotFound,
+		},
+		{
+			testName:      "missing task",
+			userID:        1234,
+			taskID:        404,
+			action:        ActionRead,
+			returnRow:     authzdb.GetTaskProjectRoleRow{},
+			returnError:   sql.ErrNoRows,
+			expectedError: customErrors.ErrTaskNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			mockRepo.Calls = nil
+			mockRepo.ExpectedCalls = nil
+			params := authzdb.GetTaskProjectRoleParams{ID: int64(tc.taskID), UserID: int32(tc.userID), OrganizationID: orgID}
+			mockRepo.On("GetTaskProjectRole", mock.Anything, params).Return(tc.returnRow, tc.returnError)
+
+			err := authzService.AuthorizeTask(context.TODO(), tc.userID, orgID, tc.taskID, tc.action)
+			assert.Equal(t, tc.expectedError, err)
+			mockRepo.AssertCalled(t, "GetTaskProjectRole", mock.Anything, params)
+		})
+	}
+}
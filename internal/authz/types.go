@@ -0,0 +1,26 @@
+package authz
+
+import "context"
+
+// Action describes what the caller intends to do with a resource.
+type Action string
+
+const (
+	ActionRead    Action = "read"    // Viewing a project, task or job
+	ActionComment Action = "comment" // Discussing a task without changing it
+	ActionWrite   Action = "write"   // Creating, updating or deleting tasks and project details
+	ActionManage  Action = "manage"  // Managing members or deleting the project itself
+)
+
+// Authorizer is consulted by the domain services before every read or write
+// on a project or on anything that hangs off a project (tasks, exports).
+// Implementations return customErrors.ErrProjectIDNotExist / ErrTaskNotFound
+// when the resource is missing or belongs to another organization than orgID,
+// and customErrors.ErrForbidden when it exists but the caller's project role
+// does not allow the action.
+type Authorizer interface {
+	// AuthorizeProject checks that userID may perform action on the project.
+	AuthorizeProject(ctx context.Context, userID int, orgID int, projectID int, action Action) error
+	// AuthorizeTask checks that userID may perform action on the task's parent project.
+	AuthorizeTask(ctx context.Context, userID int, orgID int, taskID int, action Action) error
+}
@@ -12,27 +12,77 @@ type RabbitMQPublisherConfig struct {
 	RoutingKey string
 }
 
+// JWTSigningKeyConfig points at a PEM private key used to sign access tokens.
+// The key starts signing at ActiveFrom; until then it is only published.
+type JWTSigningKeyConfig struct {
+	ID         string
+	Path       string
+	ActiveFrom time.Time
+}
+
+// OIDCConfig configures single sign-on; it is disabled when IssuerURL is empty.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// MailConfig selects how account emails are delivered. Mailer is "log" to
+// only log messages, or "smtp" to send them through the SMTP relay.
+type MailConfig struct {
+	Mailer                    string
+	SMTPHost                  string
+	SMTPPort                  string
+	SMTPUsername              string
+	SMTPPassword              string
+	From                      string
+	AppBaseURL                string // Frontend URL the emailed links point to
+	PasswordResetTokenTTL     time.Duration
+	EmailVerificationTokenTTL time.Duration
+}
+
+// LoginProtectionConfig bounds password and second factor guessing.
+type LoginProtectionConfig struct {
+	MaxFailures     int // Failures per account before it is locked
+	IPMaxFailures   int // Failures per client IP before it is locked
+	LockoutDuration time.Duration
+}
+
+// AttachmentConfig bounds the files users attach to tasks.
+type AttachmentConfig struct {
+	MaxSize      int64    // Largest file in bytes
+	ContentTypes []string // Allowed content types, image/* allows every image
+	LinkExpiry   time.Duration
+}
+
 type Config struct {
-	Port                 string
-	DBHost               string
-	DBPort               string
-	DBUser               string
-	DBPassword           string
-	DBName               string
-	HOST                 string
-	JWTAccessTokenSecret string
+	Port                  string
+	DBHost                string
+	DBPort                string
+	DBUser                string
+	DBPassword            string
+	DBName                string
+	HOST                  string
+	JWTAccessTokenSecret  string
 	JWTRefreshTokenSecret string
-	AccessTokenDuration  time.Duration
-	RefreshTokenDuration time.Duration
-	RedisHost            string
-	RedisPort            string
-	StorageType         string // e.g., "local", "gcp"
+	JWTSigningKeys        []JWTSigningKeyConfig // Parsed from JWT_SIGNING_KEYS
+	JWTIssuer             string
+	OIDC                  OIDCConfig
+	Mail                  MailConfig
+	LoginProtection       LoginProtectionConfig
+	AccessTokenDuration   time.Duration
+	RefreshTokenDuration  time.Duration
+	RedisHost             string
+	RedisPort             string
+	StorageType           string // e.g., "local", "gcp"
 	// Importer-related configs
-	StorageConfig     storage.StorageConfig
-	RabbitMQURL       string
-	ProjectPublisher  RabbitMQPublisherConfig
-	TaskPublisher     RabbitMQPublisherConfig
+	StorageConfig          storage.StorageConfig
+	Attachments            AttachmentConfig
+	RabbitMQURL            string
+	ProjectPublisher       RabbitMQPublisherConfig
+	TaskPublisher          RabbitMQPublisherConfig
 	ProjectExportPublisher RabbitMQPublisherConfig
-	TaskExportPublisher RabbitMQPublisherConfig
-
-}
\ No newline at end of file
+	TaskExportPublisher    RabbitMQPublisherConfig
+}
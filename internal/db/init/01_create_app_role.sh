@@ -0,0 +1,9 @@
+#!/bin/sh
+# Creates the role the API server and the workers connect as, when the
+# database is first initialised. It is no superuser and does not bypass
+# row-level security; migration 000034 grants it access to the tables.
+set -e
+
+psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" -v password="$APP_DB_PASSWORD" <<'EOSQL'
+CREATE ROLE taskpilot_app LOGIN NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE PASSWORD :'password';
+EOSQL
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS project_members;
+DROP TYPE IF EXISTS project_role;
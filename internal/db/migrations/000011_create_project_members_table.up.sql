@@ -0,0 +1,16 @@
+CREATE TYPE project_role AS ENUM ('OWNER', 'EDITOR', 'VIEWER', 'COMMENTER');
+
+CREATE TABLE project_members (
+    project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role project_role NOT NULL DEFAULT 'VIEWER',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX idx_project_members_user_id ON project_members(user_id);
+
+-- every existing project owner becomes the OWNER member of their project
+INSERT INTO project_members (project_id, user_id, role)
+SELECT id, user_id, 'OWNER' FROM projects;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_lower_email_prefix;
+DROP INDEX IF EXISTS idx_users_lower_name_prefix;
+
+-- fails while any email is longer than 30 characters
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(30);
@@ -0,0 +1,6 @@
+-- 254 characters is the longest address SMTP can deliver to
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(254);
+
+-- case-insensitive prefix search used to pick assignees
+CREATE INDEX idx_users_lower_name_prefix ON users (lower(name) text_pattern_ops);
+CREATE INDEX idx_users_lower_email_prefix ON users (lower(email) text_pattern_ops);
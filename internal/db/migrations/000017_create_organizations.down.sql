@@ -0,0 +1,12 @@
+ALTER TABLE export_jobs DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE import_jobs DROP COLUMN IF EXISTS organization_id;
+
+DROP INDEX IF EXISTS idx_projects_organization_id;
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS unique_organization_project_name;
+-- fails while two workspaces have a project with the same name
+ALTER TABLE projects ADD CONSTRAINT unique_user_project_name UNIQUE (name);
+ALTER TABLE projects DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
+DROP TYPE IF EXISTS organization_role;
@@ -0,0 +1,61 @@
+CREATE TYPE organization_role AS ENUM ('OWNER', 'ADMIN', 'MEMBER');
+
+-- a workspace that owns projects; personal_for marks the workspace every user gets for themselves
+CREATE TABLE organizations (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    personal_for INT UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE organization_members (
+    organization_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role organization_role NOT NULL DEFAULT 'MEMBER',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX idx_organization_members_user_id ON organization_members(user_id);
+
+-- every existing user gets a personal workspace holding the projects they created
+INSERT INTO organizations (name, personal_for, created_by)
+SELECT name || '''s workspace', id, id FROM users;
+
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT id, personal_for, 'OWNER' FROM organizations;
+
+ALTER TABLE projects ADD COLUMN organization_id BIGINT REFERENCES organizations(id) ON DELETE CASCADE;
+
+UPDATE projects p SET organization_id = o.id
+FROM organizations o
+WHERE o.personal_for = p.user_id;
+
+ALTER TABLE projects ALTER COLUMN organization_id SET NOT NULL;
+
+-- people a project was shared with keep access as members of the creator's workspace
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT DISTINCT p.organization_id, pm.user_id, 'MEMBER'::organization_role
+FROM project_members pm
+JOIN projects p ON p.id = pm.project_id
+ON CONFLICT DO NOTHING;
+
+-- 000003 made project names unique across every user, they only need to be unique within a workspace
+ALTER TABLE projects DROP CONSTRAINT unique_user_project_name;
+ALTER TABLE projects ADD CONSTRAINT unique_organization_project_name UNIQUE (organization_id, name);
+
+CREATE INDEX idx_projects_organization_id ON projects(organization_id);
+
+-- jobs run in the workspace they were started from
+ALTER TABLE import_jobs ADD COLUMN organization_id BIGINT REFERENCES organizations(id) ON DELETE CASCADE;
+UPDATE import_jobs j SET organization_id = o.id FROM organizations o WHERE o.personal_for = j.user_id;
+-- import_jobs.user_id has no foreign key, jobs of deleted users have no workspace to move to
+DELETE FROM import_jobs WHERE organization_id IS NULL;
+ALTER TABLE import_jobs ALTER COLUMN organization_id SET NOT NULL;
+
+ALTER TABLE export_jobs ADD COLUMN organization_id BIGINT REFERENCES organizations(id) ON DELETE CASCADE;
+UPDATE export_jobs j SET organization_id = o.id FROM organizations o WHERE o.personal_for = j.user_id;
+ALTER TABLE export_jobs ALTER COLUMN organization_id SET NOT NULL;
@@ -0,0 +1,16 @@
+DROP POLICY IF EXISTS export_jobs_owner ON export_jobs;
+DROP POLICY IF EXISTS import_jobs_owner ON import_jobs;
+DROP POLICY IF EXISTS tasks_tenant_isolation ON tasks;
+DROP POLICY IF EXISTS projects_tenant_isolation ON projects;
+
+ALTER TABLE export_jobs NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE export_jobs DISABLE ROW LEVEL SECURITY;
+ALTER TABLE import_jobs NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE import_jobs DISABLE ROW LEVEL SECURITY;
+ALTER TABLE tasks NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE tasks DISABLE ROW LEVEL SECURITY;
+ALTER TABLE projects NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE projects DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS app_rls_bypassed();
+DROP FUNCTION IF EXISTS app_current_user_id();
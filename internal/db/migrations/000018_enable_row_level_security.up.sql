@@ -0,0 +1,43 @@
+-- the user a connection acts for, set per request by db.TenantDB; NULL when nobody is bound
+CREATE FUNCTION app_current_user_id() RETURNS INT
+LANGUAGE sql STABLE AS $$
+    SELECT NULLIF(current_setting('app.current_user_id', true), '')::INT
+$$;
+
+-- migrations and maintenance scripts run SET app.bypass_rls = 'on' to see every row
+CREATE FUNCTION app_rls_bypassed() RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT COALESCE(current_setting('app.bypass_rls', true), '') = 'on'
+$$;
+
+ALTER TABLE projects ENABLE ROW LEVEL SECURITY;
+ALTER TABLE projects FORCE ROW LEVEL SECURITY;
+ALTER TABLE tasks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tasks FORCE ROW LEVEL SECURITY;
+ALTER TABLE import_jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE import_jobs FORCE ROW LEVEL SECURITY;
+ALTER TABLE export_jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE export_jobs FORCE ROW LEVEL SECURITY;
+
+-- projects are visible to every member of the organization that owns them,
+-- project roles are still checked by the application on top of this
+CREATE POLICY projects_tenant_isolation ON projects
+    USING (app_rls_bypassed() OR organization_id IN (
+        SELECT organization_id FROM organization_members WHERE user_id = app_current_user_id()
+    ))
+    WITH CHECK (app_rls_bypassed() OR organization_id IN (
+        SELECT organization_id FROM organization_members WHERE user_id = app_current_user_id()
+    ));
+
+-- the subquery is filtered by the projects policy, so tasks follow their project
+CREATE POLICY tasks_tenant_isolation ON tasks
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM projects p WHERE p.id = tasks.project_id));
+
+CREATE POLICY import_jobs_owner ON import_jobs
+    USING (app_rls_bypassed() OR user_id = app_current_user_id())
+    WITH CHECK (app_rls_bypassed() OR user_id = app_current_user_id());
+
+CREATE POLICY export_jobs_owner ON export_jobs
+    USING (app_rls_bypassed() OR user_id = app_current_user_id())
+    WITH CHECK (app_rls_bypassed() OR user_id = app_current_user_id());
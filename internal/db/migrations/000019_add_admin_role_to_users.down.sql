@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_is_admin;
+ALTER TABLE users DROP COLUMN IF EXISTS disabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS is_admin;
@@ -0,0 +1,7 @@
+-- instance administrators manage every user, job and project through /api/v1/admin
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT false;
+
+-- disabled users cannot log in and their tokens stop working
+ALTER TABLE users ADD COLUMN disabled_at TIMESTAMP;
+
+CREATE INDEX idx_users_is_admin ON users(is_admin) WHERE is_admin;
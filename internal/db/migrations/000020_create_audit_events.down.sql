@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS audit_export_jobs ON export_jobs;
+DROP TRIGGER IF EXISTS audit_import_jobs ON import_jobs;
+DROP TRIGGER IF EXISTS audit_tasks ON tasks;
+DROP TRIGGER IF EXISTS audit_project_members ON project_members;
+DROP TRIGGER IF EXISTS audit_projects ON projects;
+DROP TRIGGER IF EXISTS audit_users ON users;
+DROP FUNCTION IF EXISTS audit_record();
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
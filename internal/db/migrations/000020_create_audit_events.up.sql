@@ -0,0 +1,106 @@
+-- audit_events is an append-only history of every write to the audited tables.
+-- actor_id has no foreign key so the history outlives deleted users.
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id INT,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    project_id BIGINT,
+    before JSONB NOT NULL DEFAULT 'null',
+    after JSONB NOT NULL DEFAULT 'null',
+    request_id TEXT,
+    ip TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_events_entity ON audit_events(entity_type, entity_id);
+CREATE INDEX idx_audit_events_project_id ON audit_events(project_id, id) WHERE project_id IS NOT NULL;
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id);
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at);
+
+CREATE FUNCTION audit_events_append_only() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END
+$$;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+-- audit_record writes one event per changed row. TG_ARGV[0] is the entity type,
+-- the remaining arguments name columns that must never reach the log.
+-- The actor, request id and client ip are the session settings db.TenantDB
+-- binds for every request and job.
+CREATE FUNCTION audit_record() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+DECLARE
+    before_row JSONB := 'null';
+    after_row JSONB := 'null';
+    subject JSONB;
+    event_action TEXT;
+    i INT;
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        before_row := to_jsonb(OLD);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        after_row := to_jsonb(NEW);
+    END IF;
+    FOR i IN 1 .. TG_NARGS - 1 LOOP
+        IF before_row <> 'null' THEN
+            before_row := before_row - TG_ARGV[i];
+        END IF;
+        IF after_row <> 'null' THEN
+            after_row := after_row - TG_ARGV[i];
+        END IF;
+    END LOOP;
+    event_action := CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'UPDATE' THEN 'update' ELSE 'delete' END;
+    subject := CASE WHEN after_row <> 'null' THEN after_row ELSE before_row END;
+
+    INSERT INTO audit_events (actor_id, action, entity_type, entity_id, project_id, before, after, request_id, ip)
+    VALUES (
+        app_current_user_id(),
+        event_action,
+        TG_ARGV[0],
+        -- project members have no id of their own
+        COALESCE(subject->>'id', (subject->>'project_id') || ':' || (subject->>'user_id')),
+        CASE WHEN TG_TABLE_NAME = 'projects' THEN (subject->>'id')::BIGINT ELSE (subject->>'project_id')::BIGINT END,
+        before_row,
+        after_row,
+        NULLIF(current_setting('app.request_id', true), ''),
+        NULLIF(current_setting('app.client_ip', true), '')
+    );
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER audit_users
+    AFTER INSERT OR UPDATE OR DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION audit_record('user', 'hashed_password');
+
+CREATE TRIGGER audit_projects
+    AFTER INSERT OR UPDATE OR DELETE ON projects
+    FOR EACH ROW EXECUTE FUNCTION audit_record('project');
+
+CREATE TRIGGER audit_project_members
+    AFTER INSERT OR UPDATE OR DELETE ON project_members
+    FOR EACH ROW EXECUTE FUNCTION audit_record('project_member');
+
+CREATE TRIGGER audit_tasks
+    AFTER INSERT OR UPDATE OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION audit_record('task');
+
+CREATE TRIGGER audit_import_jobs
+    AFTER INSERT OR UPDATE OR DELETE ON import_jobs
+    FOR EACH ROW EXECUTE FUNCTION audit_record('import_job');
+
+CREATE TRIGGER audit_export_jobs
+    AFTER INSERT OR UPDATE OR DELETE ON export_jobs
+    FOR EACH ROW EXECUTE FUNCTION audit_record('export_job');
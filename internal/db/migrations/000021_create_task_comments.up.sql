@@ -0,0 +1,40 @@
+-- comments on a task; replies point at a top-level comment of the same task,
+-- replies to replies are rejected by the application
+CREATE TABLE task_comments (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    parent_id BIGINT REFERENCES task_comments(id) ON DELETE CASCADE,
+    author_id INT REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    edited_at TIMESTAMP
+);
+
+CREATE INDEX idx_task_comments_task_id ON task_comments(task_id, id);
+CREATE INDEX idx_task_comments_parent_id ON task_comments(parent_id);
+
+-- every edit keeps the body it replaced
+CREATE TABLE task_comment_edits (
+    id BIGSERIAL PRIMARY KEY,
+    comment_id BIGINT NOT NULL REFERENCES task_comments(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    edited_by INT REFERENCES users(id) ON DELETE SET NULL,
+    edited_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_task_comment_edits_comment_id ON task_comment_edits(comment_id, id);
+
+ALTER TABLE task_comments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_comments FORCE ROW LEVEL SECURITY;
+ALTER TABLE task_comment_edits ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_comment_edits FORCE ROW LEVEL SECURITY;
+
+-- comments follow their task, which follows its project
+CREATE POLICY task_comments_tenant_isolation ON task_comments
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_comments.task_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_comments.task_id));
+
+CREATE POLICY task_comment_edits_tenant_isolation ON task_comment_edits
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM task_comments c WHERE c.id = task_comment_edits.comment_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM task_comments c WHERE c.id = task_comment_edits.comment_id));
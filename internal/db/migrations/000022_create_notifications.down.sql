@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notifications;
+DROP TYPE IF EXISTS notification_kind;
@@ -0,0 +1,32 @@
+CREATE TYPE notification_kind AS ENUM ('mention', 'assignment');
+
+-- in-app notifications; a user is notified when they are assigned a task or
+-- mentioned as @email in a task description or comment
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id INT REFERENCES users(id) ON DELETE SET NULL,
+    kind notification_kind NOT NULL,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    comment_id BIGINT REFERENCES task_comments(id) ON DELETE CASCADE,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications(user_id, id);
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+ALTER TABLE notifications ENABLE ROW LEVEL SECURITY;
+ALTER TABLE notifications FORCE ROW LEVEL SECURITY;
+
+-- recipients see and mark their own notifications
+CREATE POLICY notifications_recipient ON notifications
+    USING (app_rls_bypassed() OR user_id = app_current_user_id())
+    WITH CHECK (app_rls_bypassed() OR user_id = app_current_user_id());
+
+-- and the current user notifies others about tasks they can see; inserts must
+-- not use RETURNING since the sender cannot read the rows back
+CREATE POLICY notifications_sender ON notifications FOR INSERT
+    WITH CHECK (app_rls_bypassed() OR (
+        actor_id = app_current_user_id() AND EXISTS (SELECT 1 FROM tasks t WHERE t.id = notifications.task_id)
+    ));
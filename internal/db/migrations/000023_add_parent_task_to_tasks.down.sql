@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_parent_task_id;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_parent_task_not_self;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_parent_task_fkey;
+ALTER TABLE tasks DROP COLUMN IF EXISTS parent_task_id;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_id_project_id_key;
@@ -0,0 +1,12 @@
+-- lets (parent_task_id, project_id) reference a task of the same project
+ALTER TABLE tasks ADD CONSTRAINT tasks_id_project_id_key UNIQUE (id, project_id);
+
+ALTER TABLE tasks ADD COLUMN parent_task_id BIGINT;
+
+-- a subtask belongs to the project of its parent and is deleted with it;
+-- longer cycles are rejected by the application
+ALTER TABLE tasks ADD CONSTRAINT tasks_parent_task_fkey
+    FOREIGN KEY (parent_task_id, project_id) REFERENCES tasks(id, project_id) ON DELETE CASCADE;
+ALTER TABLE tasks ADD CONSTRAINT tasks_parent_task_not_self CHECK (parent_task_id <> id);
+
+CREATE INDEX idx_tasks_parent_task_id ON tasks(parent_task_id);
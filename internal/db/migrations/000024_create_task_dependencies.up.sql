@@ -0,0 +1,24 @@
+-- task_id is blocked by blocked_by_id; both belong to project_id, so a link
+-- never crosses projects and goes away with either task. Longer cycles are
+-- rejected by the application
+CREATE TABLE task_dependencies (
+    task_id BIGINT NOT NULL,
+    blocked_by_id BIGINT NOT NULL,
+    project_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, blocked_by_id),
+    FOREIGN KEY (task_id, project_id) REFERENCES tasks(id, project_id) ON DELETE CASCADE,
+    FOREIGN KEY (blocked_by_id, project_id) REFERENCES tasks(id, project_id) ON DELETE CASCADE,
+    CONSTRAINT task_dependencies_not_self CHECK (task_id <> blocked_by_id)
+);
+
+CREATE INDEX idx_task_dependencies_blocked_by_id ON task_dependencies(blocked_by_id);
+CREATE INDEX idx_task_dependencies_project_id ON task_dependencies(project_id);
+
+ALTER TABLE task_dependencies ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_dependencies FORCE ROW LEVEL SECURITY;
+
+-- dependencies follow their tasks, which follow their project
+CREATE POLICY task_dependencies_tenant_isolation ON task_dependencies
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_dependencies.task_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_dependencies.task_id));
@@ -0,0 +1,39 @@
+-- labels are defined per project; names are unique per project regardless of case
+CREATE TABLE labels (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '#808080' CHECK (color ~ '^#[0-9a-f]{6}$'),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    -- lets (label_id, project_id) reference a label of the task's project
+    CONSTRAINT labels_id_project_id_key UNIQUE (id, project_id)
+);
+
+CREATE UNIQUE INDEX idx_labels_project_id_name ON labels(project_id, lower(name));
+
+-- a task only carries labels of its own project and loses them with either
+CREATE TABLE task_labels (
+    task_id BIGINT NOT NULL,
+    label_id BIGINT NOT NULL,
+    project_id BIGINT NOT NULL,
+    PRIMARY KEY (task_id, label_id),
+    FOREIGN KEY (task_id, project_id) REFERENCES tasks(id, project_id) ON DELETE CASCADE,
+    FOREIGN KEY (label_id, project_id) REFERENCES labels(id, project_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_labels_label_id ON task_labels(label_id);
+
+ALTER TABLE labels ENABLE ROW LEVEL SECURITY;
+ALTER TABLE labels FORCE ROW LEVEL SECURITY;
+ALTER TABLE task_labels ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_labels FORCE ROW LEVEL SECURITY;
+
+-- labels follow their project, task labels their task
+CREATE POLICY labels_tenant_isolation ON labels
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM projects p WHERE p.id = labels.project_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM projects p WHERE p.id = labels.project_id));
+
+CREATE POLICY task_labels_tenant_isolation ON task_labels
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_labels.task_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_labels.task_id));
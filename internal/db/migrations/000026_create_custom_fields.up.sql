@@ -0,0 +1,47 @@
+CREATE TYPE custom_field_type AS ENUM ('text', 'number', 'date', 'single_select', 'multi_select', 'user');
+
+-- custom fields are defined per project; names are unique per project regardless
+-- of case and options only apply to the select types
+CREATE TABLE custom_fields (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    field_type custom_field_type NOT NULL,
+    options TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    -- lets (field_id, project_id) reference a field of the task's project
+    CONSTRAINT custom_fields_id_project_id_key UNIQUE (id, project_id)
+);
+
+CREATE UNIQUE INDEX idx_custom_fields_project_id_name ON custom_fields(project_id, lower(name));
+
+-- values are stored as JSON: a string for text, date (YYYY-MM-DD) and
+-- single-select fields, a number for number and user fields and an array of
+-- strings for multi-select fields
+CREATE TABLE task_custom_field_values (
+    task_id BIGINT NOT NULL,
+    field_id BIGINT NOT NULL,
+    project_id BIGINT NOT NULL,
+    value JSONB NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, field_id),
+    FOREIGN KEY (task_id, project_id) REFERENCES tasks(id, project_id) ON DELETE CASCADE,
+    FOREIGN KEY (field_id, project_id) REFERENCES custom_fields(id, project_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_custom_field_values_field_id ON task_custom_field_values(field_id);
+
+ALTER TABLE custom_fields ENABLE ROW LEVEL SECURITY;
+ALTER TABLE custom_fields FORCE ROW LEVEL SECURITY;
+ALTER TABLE task_custom_field_values ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_custom_field_values FORCE ROW LEVEL SECURITY;
+
+-- fields follow their project, values their task
+CREATE POLICY custom_fields_tenant_isolation ON custom_fields
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM projects p WHERE p.id = custom_fields.project_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM projects p WHERE p.id = custom_fields.project_id));
+
+CREATE POLICY task_custom_field_values_tenant_isolation ON task_custom_field_values
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_custom_field_values.task_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_custom_field_values.task_id));
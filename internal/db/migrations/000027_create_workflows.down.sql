@@ -0,0 +1,22 @@
+CREATE TYPE task_status AS ENUM ('TODO', 'IN_PROGRESS', 'DONE');
+
+SET app.bypass_rls = 'on';
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_project_id_status_fkey;
+
+-- custom statuses fall back to the fixed status of their category
+UPDATE tasks t
+SET status = CASE ws.category WHEN 'open' THEN 'TODO' WHEN 'in_progress' THEN 'IN_PROGRESS' ELSE 'DONE' END
+FROM workflow_statuses ws
+WHERE ws.project_id = t.project_id AND ws.name = t.status;
+
+ALTER TABLE tasks ALTER COLUMN status TYPE task_status USING status::task_status;
+ALTER TABLE tasks ALTER COLUMN status SET DEFAULT 'TODO';
+
+RESET app.bypass_rls;
+
+DROP TRIGGER IF EXISTS projects_default_workflow ON projects;
+DROP FUNCTION IF EXISTS projects_default_workflow();
+DROP FUNCTION IF EXISTS create_default_workflow(BIGINT);
+DROP TABLE IF EXISTS workflow_statuses;
+DROP TYPE IF EXISTS status_category;
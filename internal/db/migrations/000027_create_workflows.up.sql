@@ -0,0 +1,59 @@
+CREATE TYPE status_category AS ENUM ('open', 'in_progress', 'done');
+
+-- every project has its own ordered list of statuses; new tasks start in the
+-- first one and transitions lists the statuses a task may move to from here
+CREATE TABLE workflow_statuses (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(30) NOT NULL CHECK (name ~ '^[A-Z][A-Z0-9_]*$'),
+    category status_category NOT NULL,
+    position INT NOT NULL,
+    transitions TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    -- lets (project_id, status) of a task reference a status of its project
+    CONSTRAINT workflow_statuses_project_id_name_key UNIQUE (project_id, name)
+);
+
+ALTER TABLE workflow_statuses ENABLE ROW LEVEL SECURITY;
+ALTER TABLE workflow_statuses FORCE ROW LEVEL SECURITY;
+
+-- statuses follow their project
+CREATE POLICY workflow_statuses_tenant_isolation ON workflow_statuses
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM projects p WHERE p.id = workflow_statuses.project_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM projects p WHERE p.id = workflow_statuses.project_id));
+
+-- the statuses every project starts with, matching the former task_status enum
+CREATE FUNCTION create_default_workflow(project BIGINT) RETURNS VOID
+LANGUAGE sql AS $$
+    INSERT INTO workflow_statuses (project_id, name, category, position, transitions) VALUES
+        (project, 'TODO', 'open', 0, '{IN_PROGRESS,DONE}'),
+        (project, 'IN_PROGRESS', 'in_progress', 1, '{TODO,DONE}'),
+        (project, 'DONE', 'done', 2, '{TODO,IN_PROGRESS}')
+$$;
+
+CREATE FUNCTION projects_default_workflow() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    PERFORM create_default_workflow(NEW.id);
+    RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER projects_default_workflow
+    AFTER INSERT ON projects
+    FOR EACH ROW EXECUTE FUNCTION projects_default_workflow();
+
+-- existing projects get the default workflow; RLS would otherwise hide them
+SET app.bypass_rls = 'on';
+
+SELECT create_default_workflow(id) FROM projects;
+
+ALTER TABLE tasks ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE tasks ALTER COLUMN status TYPE VARCHAR(30) USING status::text;
+ALTER TABLE tasks ADD CONSTRAINT tasks_project_id_status_fkey
+    FOREIGN KEY (project_id, status) REFERENCES workflow_statuses(project_id, name);
+
+RESET app.bypass_rls;
+
+DROP TYPE task_status;
@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS due_task_series(INT);
+DROP INDEX IF EXISTS idx_tasks_series_id;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_series_fkey;
+ALTER TABLE tasks DROP COLUMN IF EXISTS series_id;
+DROP TABLE IF EXISTS task_series;
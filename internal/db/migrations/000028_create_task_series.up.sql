@@ -0,0 +1,60 @@
+-- a series repeats a task by an RFC 5545 recurrence rule. Every occurrence is
+-- a task of its own; the series holds what the next one is created from and
+-- when it is due
+CREATE TABLE task_series (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    created_by INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    assignee_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    priority task_priority NOT NULL,
+    rule TEXT NOT NULL,
+    -- the first occurrence of the rule; occurrences counts those created since
+    starts_at TIMESTAMP NOT NULL,
+    occurrences INT NOT NULL DEFAULT 1,
+    -- NULL once UNTIL or COUNT ends the series
+    next_due_at TIMESTAMP,
+    latest_task_id BIGINT REFERENCES tasks(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    -- lets (series_id, project_id) of a task reference a series of its project
+    CONSTRAINT task_series_id_project_id_key UNIQUE (id, project_id)
+);
+
+ALTER TABLE tasks ADD COLUMN series_id BIGINT;
+
+-- occurrences stay as plain tasks when their series is deleted
+ALTER TABLE tasks ADD CONSTRAINT tasks_series_fkey
+    FOREIGN KEY (series_id, project_id) REFERENCES task_series(id, project_id) ON DELETE SET NULL (series_id);
+
+CREATE INDEX idx_tasks_series_id ON tasks(series_id);
+CREATE INDEX idx_task_series_next_due_at ON task_series(next_due_at) WHERE next_due_at IS NOT NULL;
+
+ALTER TABLE task_series ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_series FORCE ROW LEVEL SECURITY;
+
+-- series follow their project
+CREATE POLICY task_series_tenant_isolation ON task_series
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM projects p WHERE p.id = task_series.project_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM projects p WHERE p.id = task_series.project_id));
+
+-- series whose next occurrence is due: the latest occurrence is done, its due
+-- date has passed or it was deleted. The scheduler runs outside any user's
+-- scope, so the function sees every series and only hands out the ids it
+-- needs to create the occurrence as the user who set the series up.
+CREATE FUNCTION due_task_series(max_series INT)
+RETURNS TABLE (series_id BIGINT, user_id INT, organization_id BIGINT)
+LANGUAGE sql STABLE
+SET app.bypass_rls = 'on'
+AS $$
+    SELECT s.id, s.created_by, p.organization_id
+    FROM task_series s
+    JOIN projects p ON p.id = s.project_id
+    LEFT JOIN tasks t ON t.id = s.latest_task_id
+    LEFT JOIN workflow_statuses ws ON ws.project_id = t.project_id AND ws.name = t.status
+    WHERE s.next_due_at IS NOT NULL
+      AND (t.id IS NULL OR t.due_date <= now() OR ws.category = 'done')
+    ORDER BY s.next_due_at
+    LIMIT max_series
+$$;
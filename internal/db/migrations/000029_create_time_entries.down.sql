@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS time_entries;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS remaining_estimate_minutes,
+    DROP COLUMN IF EXISTS original_estimate_minutes;
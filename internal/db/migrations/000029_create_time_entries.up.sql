@@ -0,0 +1,33 @@
+-- estimates are in minutes; logging time uses up the remaining estimate
+ALTER TABLE tasks
+    ADD COLUMN original_estimate_minutes INT CONSTRAINT tasks_original_estimate_not_negative CHECK (original_estimate_minutes >= 0),
+    ADD COLUMN remaining_estimate_minutes INT CONSTRAINT tasks_remaining_estimate_not_negative CHECK (remaining_estimate_minutes >= 0);
+
+-- time a user spent on a task, logged by hand or with a timer. A running
+-- timer has no ended_at yet, and a user runs one timer at a time
+CREATE TABLE time_entries (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL,
+    project_id BIGINT NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP,
+    minutes INT GENERATED ALWAYS AS (round(EXTRACT(EPOCH FROM ended_at - started_at) / 60)::int) STORED,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    FOREIGN KEY (task_id, project_id) REFERENCES tasks(id, project_id) ON DELETE CASCADE,
+    CONSTRAINT time_entries_ended_after_start CHECK (ended_at >= started_at)
+);
+
+CREATE UNIQUE INDEX idx_time_entries_running_timer ON time_entries(user_id) WHERE ended_at IS NULL;
+CREATE INDEX idx_time_entries_task_id ON time_entries(task_id);
+CREATE INDEX idx_time_entries_project_id_started_at ON time_entries(project_id, started_at);
+CREATE INDEX idx_time_entries_user_id_started_at ON time_entries(user_id, started_at);
+
+ALTER TABLE time_entries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE time_entries FORCE ROW LEVEL SECURITY;
+
+-- time entries follow their tasks, which follow their project
+CREATE POLICY time_entries_tenant_isolation ON time_entries
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = time_entries.task_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = time_entries.task_id));
@@ -0,0 +1,25 @@
+-- files attached to a task. The file itself lives in the storage backend
+-- under storage_key; the row keeps what was uploaded and by whom
+CREATE TABLE task_attachments (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL,
+    project_id BIGINT NOT NULL,
+    uploader_id INT REFERENCES users(id) ON DELETE SET NULL,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL CONSTRAINT task_attachments_size_not_negative CHECK (size_bytes >= 0),
+    checksum TEXT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    FOREIGN KEY (task_id, project_id) REFERENCES tasks(id, project_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_attachments_task_id ON task_attachments(task_id);
+
+ALTER TABLE task_attachments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_attachments FORCE ROW LEVEL SECURITY;
+
+-- attachments follow their tasks, which follow their project
+CREATE POLICY task_attachments_tenant_isolation ON task_attachments
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_attachments.task_id))
+    WITH CHECK (app_rls_bypassed() OR EXISTS (SELECT 1 FROM tasks t WHERE t.id = task_attachments.task_id));
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS task_changes_record ON tasks;
+DROP FUNCTION IF EXISTS task_changes_record();
+DROP TABLE IF EXISTS task_changes;
@@ -0,0 +1,70 @@
+-- task_changes is the field-level history of tasks: one row when a task is
+-- created or deleted, and one per changed field when it is updated. Like
+-- audit_events it has no foreign keys so the history outlives deleted tasks,
+-- and values are kept as text.
+CREATE TABLE task_changes (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL,
+    project_id BIGINT NOT NULL,
+    task_title TEXT NOT NULL,
+    actor_id INT,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    field TEXT,
+    old_value TEXT,
+    new_value TEXT,
+    changed_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT task_changes_field_on_update CHECK ((action = 'update') = (field IS NOT NULL))
+);
+
+CREATE INDEX idx_task_changes_task_id ON task_changes(task_id, id);
+CREATE INDEX idx_task_changes_project_id ON task_changes(project_id, id);
+
+-- task_changes_record writes the changes of a task row. The arguments name the
+-- columns whose updates are recorded; the actor is the user db.TenantDB binds.
+CREATE FUNCTION task_changes_record() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+DECLARE
+    old_row JSONB;
+    new_row JSONB;
+    column_name TEXT;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_changes (task_id, project_id, task_title, actor_id, action)
+        VALUES (NEW.id, NEW.project_id, NEW.title, app_current_user_id(), 'create');
+        RETURN NULL;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO task_changes (task_id, project_id, task_title, actor_id, action)
+        VALUES (OLD.id, OLD.project_id, OLD.title, app_current_user_id(), 'delete');
+        RETURN NULL;
+    END IF;
+    old_row := to_jsonb(OLD);
+    new_row := to_jsonb(NEW);
+    FOREACH column_name IN ARRAY TG_ARGV LOOP
+        IF old_row -> column_name IS DISTINCT FROM new_row -> column_name THEN
+            INSERT INTO task_changes (task_id, project_id, task_title, actor_id, action, field, old_value, new_value)
+            VALUES (NEW.id, NEW.project_id, NEW.title, app_current_user_id(), 'update', column_name, old_row ->> column_name, new_row ->> column_name);
+        END IF;
+    END LOOP;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER task_changes_record
+    AFTER INSERT OR UPDATE OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION task_changes_record(
+        'title', 'description', 'status', 'priority', 'due_date', 'assignee_id', 'parent_task_id',
+        'original_estimate_minutes', 'remaining_estimate_minutes'
+    );
+
+ALTER TABLE task_changes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE task_changes FORCE ROW LEVEL SECURITY;
+
+-- changes are read with their project. They are written as the acting user
+-- rather than checked against the project, which is already gone when its
+-- tasks are deleted with it. There is no update or delete policy; the
+-- history is append-only.
+CREATE POLICY task_changes_read ON task_changes FOR SELECT
+    USING (app_rls_bypassed() OR EXISTS (SELECT 1 FROM projects p WHERE p.id = task_changes.project_id));
+CREATE POLICY task_changes_write ON task_changes FOR INSERT
+    WITH CHECK (app_rls_bypassed() OR actor_id IS NOT DISTINCT FROM app_current_user_id());
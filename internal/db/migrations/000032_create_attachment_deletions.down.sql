@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS dequeue_attachment_deletion(TEXT);
+DROP FUNCTION IF EXISTS queued_attachment_deletions(INT);
+DROP TRIGGER IF EXISTS attachment_deletions_queue ON task_attachments;
+DROP FUNCTION IF EXISTS attachment_deletions_queue();
+DROP TABLE IF EXISTS attachment_deletions;
@@ -0,0 +1,50 @@
+-- attachment_deletions queues the storage keys of deleted attachments until
+-- the task worker has removed their files. A trigger fills it, so the
+-- attachments deleted with their task, project or organization are queued
+-- in the same transaction as the rows themselves.
+CREATE TABLE attachment_deletions (
+    storage_key TEXT PRIMARY KEY,
+    queued_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_attachment_deletions_queued_at ON attachment_deletions(queued_at);
+
+CREATE FUNCTION attachment_deletions_queue() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    INSERT INTO attachment_deletions (storage_key) VALUES (OLD.storage_key);
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER attachment_deletions_queue
+    AFTER DELETE ON task_attachments
+    FOR EACH ROW EXECUTE FUNCTION attachment_deletions_queue();
+
+ALTER TABLE attachment_deletions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE attachment_deletions FORCE ROW LEVEL SECURITY;
+
+-- whoever deletes an attachment queues its key. Keys are only read and
+-- removed by the functions below, which bypass the policies.
+CREATE POLICY attachment_deletions_queue ON attachment_deletions FOR INSERT
+    WITH CHECK (true);
+CREATE POLICY attachment_deletions_bypass ON attachment_deletions
+    USING (app_rls_bypassed())
+    WITH CHECK (app_rls_bypassed());
+
+-- the worker runs outside any user's scope, so these functions see the whole
+-- queue. It lists the oldest keys, deletes their files and dequeues them.
+CREATE FUNCTION queued_attachment_deletions(max_keys INT)
+RETURNS TABLE (storage_key TEXT)
+LANGUAGE sql STABLE
+SET app.bypass_rls = 'on'
+AS $$
+    SELECT d.storage_key FROM attachment_deletions d ORDER BY d.queued_at LIMIT max_keys
+$$;
+
+CREATE FUNCTION dequeue_attachment_deletion(key TEXT) RETURNS VOID
+LANGUAGE sql
+SET app.bypass_rls = 'on'
+AS $$
+    DELETE FROM attachment_deletions WHERE attachment_deletions.storage_key = key
+$$;
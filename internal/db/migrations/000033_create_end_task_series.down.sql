@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS end_task_series(BIGINT);
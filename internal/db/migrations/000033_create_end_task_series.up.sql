@@ -0,0 +1,10 @@
+-- a series whose creator can no longer create tasks in its project is ended,
+-- so the scheduler stops retrying it. The creator is no longer allowed to
+-- see the series, so the function bypasses the policies; it only ever clears
+-- next_due_at.
+CREATE FUNCTION end_task_series(series_id BIGINT) RETURNS VOID
+LANGUAGE sql
+SET app.bypass_rls = 'on'
+AS $$
+    UPDATE task_series SET next_due_at = NULL, updated_at = now() WHERE task_series.id = series_id
+$$;
@@ -0,0 +1,6 @@
+-- the role itself is left, other databases of the cluster may use it
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE USAGE, SELECT ON SEQUENCES FROM taskpilot_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM taskpilot_app;
+REVOKE ALL ON ALL SEQUENCES IN SCHEMA public FROM taskpilot_app;
+REVOKE ALL ON ALL TABLES IN SCHEMA public FROM taskpilot_app;
+REVOKE USAGE ON SCHEMA public FROM taskpilot_app;
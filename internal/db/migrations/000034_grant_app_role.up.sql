@@ -0,0 +1,23 @@
+-- the API server and the workers connect as taskpilot_app. Unlike the owner
+-- of the tables, who runs the migrations, it is no superuser and does not
+-- bypass row-level security, so the policies apply to every query it makes.
+-- The database setup creates it with a password (see internal/db/init);
+-- where it has not, it is created here and needs
+-- ALTER ROLE taskpilot_app PASSWORD '...' before it can log in.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'taskpilot_app') THEN
+        CREATE ROLE taskpilot_app LOGIN NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE;
+    END IF;
+END
+$$;
+
+GRANT USAGE ON SCHEMA public TO taskpilot_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO taskpilot_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO taskpilot_app;
+-- the migrations table is only for the migration role
+REVOKE ALL ON TABLE schema_migrations FROM taskpilot_app;
+
+-- and the tables and sequences of later migrations
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO taskpilot_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO taskpilot_app;
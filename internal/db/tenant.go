@@ -0,0 +1,304 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CurrentUserSetting is the session variable the row-level security policies
+// read to decide which rows a connection can see.
+const CurrentUserSetting = "app.current_user_id"
+
+// BypassSetting is the session variable that lets a connection see every row
+// of the protected tables. Only the admin API turns it on.
+const BypassSetting = "app.bypass_rls"
+
+// RequestIDSetting and ClientIPSetting carry the RequestInfo of a scope's
+// context to the connection, where the audit triggers copy them into every
+// event they record.
+const (
+	RequestIDSetting = "app.request_id"
+	ClientIPSetting  = "app.client_ip"
+)
+
+type tenantScopeKey struct{}
+
+type requestInfoKey struct{}
+
+// RequestInfo identifies the HTTP request or background job a statement runs
+// for. IP is empty for jobs.
+type RequestInfo struct {
+	ID string
+	IP string
+}
+
+// WithRequestInfo attaches info to ctx. A scope bound from ctx applies it to
+// its connection, even before the scope knows its user, so writes made by
+// anonymous requests such as sign-ups are attributed too.
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFrom returns the RequestInfo attached to ctx, if any.
+func RequestInfoFrom(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
+
+// TenantDB wraps *sql.DB so every query made for a user runs on a connection
+// where CurrentUserSetting holds that user's id. It satisfies the DBTX
+// interface of every sqlc package, so repositories are built from it the same
+// way they are built from *sql.DB.
+//
+// Queries made without a scope in the context, or before the scope knows its
+// user, go to the pool as usual and see no rows of the protected tables.
+type TenantDB struct {
+	db *sql.DB
+}
+
+// NewTenantDB wraps db.
+func NewTenantDB(db *sql.DB) *TenantDB {
+	return &TenantDB{db: db}
+}
+
+// tenantScope pins one connection to one user for the lifetime of a request
+// or a job.
+type tenantScope struct {
+	mu     sync.Mutex
+	userID func() (int, bool)
+	bypass bool
+	conn   *sql.Conn
+	bound  bool    // whether conn already carries the user
+	tx     *sql.Tx // open on conn while InTx runs
+}
+
+// Bind returns a context that routes queries through a connection bound to
+// the user returned by userID. userID is asked again on every query until it
+// reports a user, so Bind can wrap a request before authentication has run.
+// release must be called once the context is no longer used.
+func (t *TenantDB) Bind(ctx context.Context, userID func() (int, bool)) (context.Context, func()) {
+	scope := &tenantScope{userID: userID}
+	return context.WithValue(ctx, tenantScopeKey{}, scope), func() { t.release(scope) }
+}
+
+// WithUser binds ctx to a user that is already known, as the workers do for
+// every job they pick up.
+func (t *TenantDB) WithUser(ctx context.Context, userID int) (context.Context, func()) {
+	return t.Bind(ctx, func() (int, bool) { return userID, true })
+}
+
+// WithAdmin binds ctx to an administrator whose queries bypass the row-level
+// security policies. Callers must have checked the admin role first.
+func (t *TenantDB) WithAdmin(ctx context.Context, userID int) (context.Context, func()) {
+	scope := &tenantScope{userID: func() (int, bool) { return userID, true }, bypass: true}
+	return context.WithValue(ctx, tenantScopeKey{}, scope), func() { t.release(scope) }
+}
+
+// ExecContext implements DBTX.
+func (t *TenantDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	q, err := t.querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return q.ExecContext(ctx, query, args...)
+}
+
+// PrepareContext implements DBTX.
+func (t *TenantDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	q, err := t.querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return q.PrepareContext(ctx, query)
+}
+
+// QueryContext implements DBTX.
+func (t *TenantDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	q, err := t.querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return q.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext implements DBTX. *sql.Row cannot carry an error from
+// binding the connection, so the query falls back to the pool where the
+// policies hide every protected row instead of leaking them.
+func (t *TenantDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	q, err := t.querier(ctx)
+	if err != nil {
+		return t.db.QueryRowContext(ctx, query, args...)
+	}
+	return q.QueryRowContext(ctx, query, args...)
+}
+
+// InTx runs fn in a transaction on the connection of the scope of ctx, so
+// the queries fn makes through ctx are committed together, or rolled back
+// together when fn fails. A transaction already open on the scope is joined.
+// Without a scope, fn gets one of its own that binds no user.
+func (t *TenantDB) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	scope, ok := ctx.Value(tenantScopeKey{}).(*tenantScope)
+	if !ok {
+		var release func()
+		ctx, release = t.Bind(ctx, func() (int, bool) { return 0, false })
+		defer release()
+		scope = ctx.Value(tenantScopeKey{}).(*tenantScope)
+	}
+	if scope.transaction() != nil {
+		return fn(ctx)
+	}
+	conn, err := t.conn(ctx)
+	if err != nil {
+		return err
+	}
+	scope.mu.Lock()
+	if conn == nil {
+		if conn, err = t.db.Conn(ctx); err != nil {
+			scope.mu.Unlock()
+			return err
+		}
+		scope.conn = conn
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		scope.mu.Unlock()
+		return err
+	}
+	scope.tx = tx
+	scope.mu.Unlock()
+	defer func() {
+		_ = tx.Rollback() // a no-op once committed
+		scope.mu.Lock()
+		scope.tx = nil
+		scope.mu.Unlock()
+	}()
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier is where a query runs: the pool, a scope's connection or the
+// transaction open on it.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// querier returns where the queries of ctx run: the transaction open on its
+// scope, the connection bound to its user or, without either, the pool.
+func (t *TenantDB) querier(ctx context.Context) (querier, error) {
+	if scope, ok := ctx.Value(tenantScopeKey{}).(*tenantScope); ok {
+		if tx := scope.transaction(); tx != nil {
+			return tx, nil
+		}
+	}
+	conn, err := t.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if conn == nil {
+		return t.db, nil
+	}
+	return conn, nil
+}
+
+// transaction returns the transaction InTx has open on the scope, if any.
+func (s *tenantScope) transaction() *sql.Tx {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tx
+}
+
+// conn returns the connection bound to the user of ctx, binding one on first
+// use. It returns nil when ctx has no scope, or the scope has neither a user
+// nor request info yet.
+func (t *TenantDB) conn(ctx context.Context) (*sql.Conn, error) {
+	scope, ok := ctx.Value(tenantScopeKey{}).(*tenantScope)
+	if !ok {
+		return nil, nil
+	}
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	if scope.bound {
+		return scope.conn, nil
+	}
+	var settings []string
+	userID, known := scope.userID()
+	if known {
+		bypass := "off"
+		if scope.bypass {
+			bypass = "on"
+		}
+		settings = append(settings, CurrentUserSetting, strconv.Itoa(userID), BypassSetting, bypass)
+	}
+	conn := scope.conn
+	if conn == nil {
+		info, ok := RequestInfoFrom(ctx)
+		if !known && !ok {
+			return nil, nil
+		}
+		if ok {
+			settings = append(settings, RequestIDSetting, info.ID, ClientIPSetting, info.IP)
+		}
+		var err error
+		if conn, err = t.db.Conn(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if len(settings) > 0 {
+		if _, err := conn.ExecContext(ctx, setConfig(len(settings)/2), stringArgs(settings)...); err != nil {
+			if scope.conn == nil {
+				conn.Close()
+			}
+			return nil, err
+		}
+	}
+	scope.conn, scope.bound = conn, known
+	return conn, nil
+}
+
+// setConfig returns a statement that sets n session variables, given as
+// alternating name and value arguments.
+func setConfig(n int) string {
+	calls := make([]string, n)
+	for i := range calls {
+		calls[i] = fmt.Sprintf("set_config($%d, $%d, false)", 2*i+1, 2*i+2)
+	}
+	return "SELECT " + strings.Join(calls, ", ")
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// resetSettings clears everything a scope sets on its connection.
+const resetSettings = "RESET " + CurrentUserSetting + "; RESET " + BypassSetting +
+	"; RESET " + RequestIDSetting + "; RESET " + ClientIPSetting
+
+// release clears the user from the scope's connection and returns it to the
+// pool. A connection that cannot be cleared is discarded so the next request
+// never inherits another user's id.
+func (t *TenantDB) release(scope *tenantScope) {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	if scope.conn == nil {
+		return
+	}
+	if _, err := scope.conn.ExecContext(context.Background(), resetSettings); err != nil {
+		_ = scope.conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	scope.conn.Close()
+	scope.conn, scope.bound = nil, false
+}
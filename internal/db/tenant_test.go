@@ -0,0 +1,294 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDriver remembers every statement run against it, tagged with the
+// connection it ran on.
+type recordingDriver struct {
+	mu       sync.Mutex
+	conns    int
+	log      []recordedStmt
+	failNext bool
+}
+
+type recordedStmt struct {
+	conn  int
+	query string
+	args  []driver.NamedValue
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.conns++
+	return &recordingConn{driver: d, id: d.conns}, nil
+}
+
+func (d *recordingDriver) record(conn int, query string, args []driver.NamedValue) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.failNext {
+		d.failNext = false
+		return errors.New("statement failed")
+	}
+	d.log = append(d.log, recordedStmt{conn: conn, query: query, args: args})
+	return nil
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+	id     int
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *recordingConn) Close() error { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	if err := c.driver.record(c.id, "BEGIN", nil); err != nil {
+		return nil, err
+	}
+	return recordingTx{c}, nil
+}
+
+type recordingTx struct{ conn *recordingConn }
+
+func (tx recordingTx) Commit() error   { return tx.conn.driver.record(tx.conn.id, "COMMIT", nil) }
+func (tx recordingTx) Rollback() error { return tx.conn.driver.record(tx.conn.id, "ROLLBACK", nil) }
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.driver.record(c.id, query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.driver.record(c.id, query, args); err != nil {
+		return nil, err
+	}
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return []string{"id"} }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+type recordingConnector struct{ driver *recordingDriver }
+
+func (c recordingConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open("") }
+func (c recordingConnector) Driver() driver.Driver                        { return c.driver }
+
+func newRecordingDB(t *testing.T) (*TenantDB, *recordingDriver) {
+	t.Helper()
+	drv := &recordingDriver{}
+	sqlDB := sql.OpenDB(recordingConnector{driver: drv})
+	t.Cleanup(func() { sqlDB.Close() })
+	return NewTenantDB(sqlDB), drv
+}
+
+func TestTenantDBBindsConnectionToUser(t *testing.T) {
+	tenantDB, drv := newRecordingDB(t)
+
+	ctx, release := tenantDB.WithUser(context.Background(), 42)
+	_, err := tenantDB.ExecContext(ctx, "UPDATE projects SET name = $1", "x")
+	require.NoError(t, err)
+	rows, err := tenantDB.QueryContext(ctx, "SELECT id FROM tasks")
+	require.NoError(t, err)
+	rows.Close()
+	release()
+
+	require.Len(t, drv.log, 4)
+	assert.Equal(t, "SELECT set_config($1, $2, false), set_config($3, $4, false)", drv.log[0].query)
+	assert.Equal(t, CurrentUserSetting, drv.log[0].args[0].Value)
+	assert.Equal(t, "42", drv.log[0].args[1].Value)
+	assert.Equal(t, BypassSetting, drv.log[0].args[2].Value)
+	assert.Equal(t, "off", drv.log[0].args[3].Value)
+	assert.Equal(t, "UPDATE projects SET name = $1", drv.log[1].query)
+	assert.Equal(t, "SELECT id FROM tasks", drv.log[2].query)
+	assert.Equal(t, resetSettings, drv.log[3].query)
+	for _, stmt := range drv.log {
+		assert.Equal(t, drv.log[0].conn, stmt.conn, "every statement must run on the bound connection")
+	}
+}
+
+func TestTenantDBWaitsForUser(t *testing.T) {
+	tenantDB, drv := newRecordingDB(t)
+
+	userID, known := 0, false
+	ctx, release := tenantDB.Bind(context.Background(), func() (int, bool) { return userID, known })
+
+	_, err := tenantDB.ExecContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+	userID, known = 7, true
+	_, err = tenantDB.ExecContext(ctx, "SELECT 2")
+	require.NoError(t, err)
+	release()
+
+	require.Len(t, drv.log, 4)
+	assert.Equal(t, "SELECT 1", drv.log[0].query)
+	assert.Equal(t, "7", drv.log[1].args[1].Value)
+	assert.Equal(t, "SELECT 2", drv.log[2].query)
+	assert.Equal(t, resetSettings, drv.log[3].query)
+}
+
+func TestTenantDBWithAdminBypassesPolicies(t *testing.T) {
+	tenantDB, drv := newRecordingDB(t)
+
+	ctx, release := tenantDB.WithAdmin(context.Background(), 1)
+	_, err := tenantDB.ExecContext(ctx, "SELECT id FROM import_jobs")
+	require.NoError(t, err)
+	release()
+
+	require.Len(t, drv.log, 3)
+	assert.Equal(t, "1", drv.log[0].args[1].Value)
+	assert.Equal(t, "on", drv.log[0].args[3].Value)
+	assert.Equal(t, resetSettings, drv.log[2].query)
+}
+
+func TestTenantDBWithoutScopeUsesPool(t *testing.T) {
+	tenantDB, drv := newRecordingDB(t)
+
+	_, err := tenantDB.ExecContext(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+
+	require.Len(t, drv.log, 1)
+	assert.Equal(t, "SELECT 1", drv.log[0].query)
+}
+
+func TestTenantDBBindFailureIsReturned(t *testing.T) {
+	tenantDB, drv := newRecordingDB(t)
+
+	ctx, release := tenantDB.WithUser(context.Background(), 42)
+	defer release()
+	drv.failNext = true
+	_, err := tenantDB.ExecContext(ctx, "DELETE FROM tasks")
+
+	assert.Error(t, err)
+	assert.Empty(t, drv.log, "the statement must not run when the user could not be bound")
+}
+
+func TestTenantDBAppliesRequestInfo(t *testing.T) {
+	tenantDB, drv := newRecordingDB(t)
+
+	ctx := WithRequestInfo(context.Background(), RequestInfo{ID: "req-1", IP: "10.0.0.1"})
+	ctx, release := tenantDB.WithUser(ctx, 42)
+	_, err := tenantDB.ExecContext(ctx, "DELETE FROM tasks")
+	require.NoError(t, err)
+	release()
+
+	require.Len(t, drv.log, 3)
+	assert.Equal(t, "SELECT set_config($1, $2, false), set_config($3, $4, false), set_config($5, $6, false), set_config($7, $8, false)", drv.log[0].query)
+	assert.Equal(t, "42", drv.log[0].args[1].Value)
+	assert.Equal(t, RequestIDSetting, drv.log[0].args[4].Value)
+	assert.Equal(t, "req-1", drv.log[0].args[5].Value)
+	assert.Equal(t, ClientIPSetting, drv.log[0].args[6].Value)
+	assert.Equal(t, "10.0.0.1", drv.log[0].args[7].Value)
+}
+
+func TestTenantDBAnonymousRequestKeepsConnection(t *testing.T) {
+	tenantDB, drv := newRecordingDB(t)
+
+	userID, known := 0, false
+	ctx := WithRequestInfo(context.Background(), RequestInfo{ID: "req-2", IP: "10.0.0.2"})
+	ctx, release := tenantDB.Bind(ctx, func() (int, bool) { return userID, known })
+
+	_, err := tenantDB.ExecContext(ctx, "INSERT INTO users")
+	require.NoError(t, err)
+	userID, known = 7, true
+	_, err = tenantDB.ExecContext(ctx, "SELECT 2")
+	require.NoError(t, err)
+	release()
+
+	require.Len(t, drv.log, 5)
+	assert.Equal(t, "req-2", drv.log[0].args[1].Value)
+	assert.Equal(t, "INSERT INTO users", drv.log[1].query)
+	assert.Equal(t, CurrentUserSetting, drv.log[2].args[0].Value)
+	assert.Equal(t, "7", drv.log[2].args[1].Value)
+	assert.Equal(t, "SELECT 2", drv.log[3].query)
+	assert.Equal(t, resetSettings, drv.log[4].query)
+	for _, stmt := range drv.log {
+		assert.Equal(t, drv.log[0].conn, stmt.conn, "the user must be bound to the connection the request already uses")
+	}
+}
+
+func TestTenantDBInTxCommitsOnBoundConnection(t *testing.T) {
+	tenantDB, drv := newRecordingDB(t)
+
+	ctx, release := tenantDB.WithUser(context.Background(), 42)
+	err := tenantDB.InTx(ctx, func(ctx context.Context) error {
+		if _, err := tenantDB.ExecContext(ctx, "UPDATE tasks SET title = $1", "x"); err != nil {
+			return err
+		}
+		// a nested transaction joins the open one
+		return tenantDB.InTx(ctx, func(ctx context.Context) error {
+			_, err := tenantDB.ExecContext(ctx, "DELETE FROM task_labels")
+			return err
+		})
+	})
+	require.NoError(t, err)
+	_, err = tenantDB.ExecContext(ctx, "SELECT 1")
+	require.NoError(t, err)
+	release()
+
+	require.Len(t, drv.log, 7)
+	assert.Equal(t, "42", drv.log[0].args[1].Value)
+	assert.Equal(t, "BEGIN", drv.log[1].query)
+	assert.Equal(t, "UPDATE tasks SET title = $1", drv.log[2].query)
+	assert.Equal(t, "DELETE FROM task_labels", drv.log[3].query)
+	assert.Equal(t, "COMMIT", drv.log[4].query)
+	assert.Equal(t, "SELECT 1", drv.log[5].query)
+	assert.Equal(t, resetSettings, drv.log[6].query)
+	for _, stmt := range drv.log {
+		assert.Equal(t, drv.log[0].conn, stmt.conn, "the transaction must run on the bound connection")
+	}
+}
+
+func TestTenantDBInTxRollsBackOnError(t *testing.T) {
+	tenantDB, drv := newRecordingDB(t)
+
+	ctx, release := tenantDB.WithUser(context.Background(), 42)
+	failed := errors.New("labels failed")
+	err := tenantDB.InTx(ctx, func(ctx context.Context) error {
+		if _, err := tenantDB.ExecContext(ctx, "UPDATE tasks SET title = $1", "x"); err != nil {
+			return err
+		}
+		return failed
+	})
+	release()
+
+	assert.Equal(t, failed, err)
+	require.Len(t, drv.log, 5)
+	assert.Equal(t, "BEGIN", drv.log[1].query)
+	assert.Equal(t, "ROLLBACK", drv.log[3].query)
+	assert.Equal(t, resetSettings, drv.log[4].query)
+}
+
+func TestTenantDBInTxWithoutScope(t *testing.T) {
+	tenantDB, drv := newRecordingDB(t)
+
+	err := tenantDB.InTx(context.Background(), func(ctx context.Context) error {
+		_, err := tenantDB.ExecContext(ctx, "SELECT 1")
+		return err
+	})
+	require.NoError(t, err)
+
+	require.Len(t, drv.log, 4)
+	assert.Equal(t, "BEGIN", drv.log[0].query)
+	assert.Equal(t, "SELECT 1", drv.log[1].query)
+	assert.Equal(t, "COMMIT", drv.log[2].query)
+	assert.Equal(t, resetSettings, drv.log[3].query)
+}
@@ -6,10 +6,11 @@ import (
 	"github.com/lib/pq"
 )
 
-
 const (
-    UniqueViolationErr = pq.ErrorCode("23505")
+	UniqueViolationErr     = pq.ErrorCode("23505")
+	ForeignKeyViolationErr = pq.ErrorCode("23503")
 )
+
 // Custom error variables for user authentication and request validation
 var USER_NOT_FOUND = errors.New("user not found")
 
@@ -41,29 +42,26 @@ var ErrUserIDNotFoundInContext = errors.New("UserID is missing from context, try
 var ErrProjectNotExist = errors.New("sorry the project name you are searching for isn't found")
 var ErrProjectIDNotExist = errors.New("sorry the project id you are searching for isn't found")
 var ErrProjectsEmpty = errors.New("projects are empty")
-var ErrProjectAlreadyExists=errors.New("project already exists")
+var ErrProjectAlreadyExists = errors.New("project already exists")
 
 var ErrTokenExpired = errors.New("access token has expired")
 
-var ErrTaskNotFound=errors.New("sorry the task not found")
-
-var ErrAssigneeMissingFromBody=errors.New("assignee email  is missing from body")
-var ErrMissingDueDate=errors.New("Due date is missing from body")
-
-var ErrorTaskTitleMissing=errors.New("task title is missing from request body")
-
-var ErrMissingProjectID=errors.New("project id is missing from request body")
+var ErrTaskNotFound = errors.New("sorry the task not found")
 
-var ErrInvalidTaskID=errors.New("Invalid Task Id Entered")
+var ErrAssigneeMissingFromBody = errors.New("assignee email  is missing from body")
+var ErrMissingDueDate = errors.New("Due date is missing from body")
 
-var ErrTaskAlreadyExists=errors.New("Task Already exists")
+var ErrorTaskTitleMissing = errors.New("task title is missing from request body")
 
-var ErrTasksAreEmpty=errors.New("not tasks under the project id you mentioned")
+var ErrMissingProjectID = errors.New("project id is missing from request body")
 
+var ErrInvalidTaskID = errors.New("Invalid Task Id Entered")
 
-var ErrParentProjectIDNotFound=errors.New("The corresponding project id  doesnt exist")
+var ErrTaskAlreadyExists = errors.New("Task Already exists")
 
+var ErrTasksAreEmpty = errors.New("not tasks under the project id you mentioned")
 
+var ErrParentProjectIDNotFound = errors.New("The corresponding project id  doesnt exist")
 
 var ErrCreatingImportJob = errors.New("failed to create import job")
 
@@ -75,18 +73,144 @@ var ErrWhileEnqueuingImportJob = errors.New("failed to enqueue import job try af
 
 var ErrGoogleApplicationCredentialsNotSet = errors.New("GOOGLE_APPLICATION_CREDENTIALS environment variable is not set")
 
+var ErrLoadingServiceAccountFile = errors.New("failed to load service account file, check the path and permissions")
 
-var ErrLoadingServiceAccountFile=errors.New("failed to load service account file, check the path and permissions")
-
-
-var ErrInvalidServiceAccountFile=errors.New("failed to unmarshal service account file, check the file format")
-
+var ErrInvalidServiceAccountFile = errors.New("failed to unmarshal service account file, check the file format")
 
 var ErrGeneratingSignedURL = errors.New("failed to generate signed URL for file download")
 
 var ErrCreatingExportJob = errors.New("failed to create export job")
 
-
 var ErrWhileEnqueuingExportJob = errors.New("failed to enqueue export job, try after sometime")
 
-var ErrInvalidJobID=errors.New("invalid job id try passing valid id")
\ No newline at end of file
+var ErrInvalidJobID = errors.New("invalid job id try passing valid id")
+
+var ErrForbidden = errors.New("you do not have permission to access this resource")
+
+var ErrImportJobNotFound = errors.New("import job not found")
+
+var ErrExportJobNotFound = errors.New("export job not found")
+
+var ErrInvalidProjectRole = errors.New("invalid project role, allowed roles are owner, editor, viewer and commenter")
+
+var ErrProjectMemberAlreadyExists = errors.New("user is already a member of this project")
+
+var ErrProjectMemberNotFound = errors.New("project member not found")
+
+var ErrCannotModifyProjectOwner = errors.New("the project owner cannot be removed or have their role changed")
+
+var ErrAssigneeNotProjectMember = errors.New("assignee must be a member of the project")
+
+var ErrInvalidMemberID = errors.New("invalid member id")
+
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected, all sessions issued from it have been revoked")
+
+var ErrRefreshTokenRevoked = errors.New("refresh token has been revoked or has expired")
+
+var ErrTokenRevoked = errors.New("access token has been revoked")
+var ErrNoActiveSigningKey = errors.New("no active access token signing key is configured")
+var ErrInvalidPersonalAccessToken = errors.New("personal access token is invalid, expired or revoked")
+var ErrInsufficientScope = errors.New("token does not have the scope required for this request")
+var ErrPersonalAccessTokenNotAllowed = errors.New("personal access tokens cannot be used for this request")
+var ErrInvalidScope = errors.New("invalid personal access token scope")
+var ErrPersonalAccessTokenNotFound = errors.New("personal access token not found")
+var ErrInvalidTokenExpiry = errors.New("expires_at must be in the future")
+var ErrInvalidTokenID = errors.New("invalid token id")
+var ErrOIDCInvalidState = errors.New("single sign-on login has expired or was already used, please try again")
+var ErrOIDCInvalidIDToken = errors.New("invalid id token from identity provider")
+var ErrOIDCExchangeFailed = errors.New("identity provider rejected the authorization code")
+var ErrOIDCEmailNotVerified = errors.New("identity provider did not confirm the email address is verified")
+var ErrOIDCNotConfigured = errors.New("single sign-on is not configured")
+var ErrInvalidAccountToken = errors.New("token is invalid, expired or has already been used")
+var ErrInvalidMFAChallenge = errors.New("two-factor login has expired, please log in again")
+var ErrInvalidMFACode = errors.New("invalid two-factor authentication code")
+var ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+var ErrMFANotEnrolled = errors.New("two-factor authentication enrollment has not been started")
+var ErrMFANotEnabled = errors.New("two-factor authentication is not enabled")
+var ErrAccountLocked = errors.New("account is temporarily locked after too many failed login attempts, try again later")
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+var ErrInvalidEmail = errors.New("email address is invalid or longer than 254 characters")
+var ErrMissingCurrentPassword = errors.New("current_password is required to change the email address")
+var ErrNothingToUpdate = errors.New("nothing to update, provide a name or an email")
+var ErrOwnsSharedProjects = errors.New("transfer or delete the projects shared with other members before deleting the account")
+var ErrSearchQueryTooShort = errors.New("search query must be at least 3 characters")
+var ErrOrganizationNotFound = errors.New("organization not found")
+var ErrInvalidOrganizationID = errors.New("invalid organization id")
+var ErrOrganizationNotResolved = errors.New("organization is missing from context")
+var ErrMissingOrganizationName = errors.New("organization name is missing in request body")
+var ErrInvalidOrganizationRole = errors.New("invalid organization role, allowed roles are owner, admin and member")
+var ErrOrganizationMemberAlreadyExists = errors.New("user is already a member of this organization")
+var ErrOrganizationMemberNotFound = errors.New("organization member not found")
+var ErrLastOrganizationOwner = errors.New("an organization needs at least one owner")
+var ErrPersonalOrganization = errors.New("a personal workspace cannot be deleted or left by its owner")
+var ErrNotOrganizationMember = errors.New("user must be a member of the organization first")
+var ErrAccountDisabled = errors.New("this account has been disabled, contact an administrator")
+var ErrAdminRequired = errors.New("this request requires an administrator")
+var ErrCannotModifySelf = errors.New("administrators cannot disable or demote themselves")
+var ErrAdminAlreadyExists = errors.New("an administrator already exists, manage admins through the admin API")
+var ErrInvalidJobStatus = errors.New("invalid job status")
+var ErrInvalidPagination = errors.New("limit must be between 1 and 100 and offset cannot be negative")
+var ErrInvalidAccountID = errors.New("invalid user id")
+var ErrInvalidAuditAction = errors.New("invalid audit action, allowed actions are create, update and delete")
+var ErrInvalidAuditEntityType = errors.New("invalid entity type, allowed types are user, project, project_member, task, import_job and export_job")
+var ErrCommentNotFound = errors.New("comment not found")
+var ErrInvalidCommentID = errors.New("invalid comment id")
+var ErrInvalidCommentBody = errors.New("comment body must be between 1 and 10000 characters")
+var ErrNestedCommentReply = errors.New("replies can only be made to top-level comments")
+var ErrNotCommentAuthor = errors.New("only the author can edit this comment")
+var ErrNotificationNotFound = errors.New("notification not found")
+var ErrInvalidNotificationID = errors.New("invalid notification id")
+var ErrParentTaskNotFound = errors.New("parent task not found")
+var ErrCrossProjectParent = errors.New("a subtask must belong to the project of its parent task")
+var ErrTaskHierarchyCycle = errors.New("a task cannot be moved under itself or one of its subtasks")
+var ErrDependencyNotFound = errors.New("task dependency not found")
+var ErrDependencyAlreadyExists = errors.New("the task is already blocked by this task")
+var ErrBlockingTaskNotFound = errors.New("blocking task not found")
+var ErrCrossProjectDependency = errors.New("a task can only be blocked by tasks of its own project")
+var ErrDependencyCycle = errors.New("this dependency would make the task wait for itself")
+var ErrTaskBlocked = errors.New("the task cannot be marked as done while it is blocked by open tasks")
+var ErrLabelNotFound = errors.New("label not found")
+var ErrLabelAlreadyExists = errors.New("a label with this name already exists in the project")
+var ErrInvalidLabelID = errors.New("invalid label id")
+var ErrInvalidLabelName = errors.New("label name must be between 1 and 50 characters and cannot contain commas")
+var ErrInvalidLabelColor = errors.New("label color must be a hex color like #1f883d")
+var ErrInvalidLabelMatch = errors.New("invalid label_match, allowed values are any and all")
+var ErrCustomFieldNotFound = errors.New("custom field not found")
+var ErrCustomFieldAlreadyExists = errors.New("a custom field with this name already exists in the project")
+var ErrInvalidCustomFieldID = errors.New("invalid custom field id")
+var ErrInvalidCustomFieldName = errors.New("custom field name must be between 1 and 50 characters")
+var ErrCustomFieldNameReserved = errors.New("custom fields cannot be named after a column of task imports and exports")
+var ErrInvalidCustomFieldType = errors.New("invalid custom field type, allowed types are text, number, date, single_select, multi_select and user")
+var ErrInvalidCustomFieldOptions = errors.New("select fields need between 1 and 100 distinct options of up to 100 characters without commas, other fields take none")
+var ErrInvalidCustomFieldValue = errors.New("invalid custom field value")
+var ErrInvalidCustomFieldFilter = errors.New("invalid custom_field filter, expected <field id>:<value>")
+var ErrInvalidSortOrder = errors.New("invalid sort_order, allowed values are asc and desc")
+var ErrUnknownStatus = errors.New("the project's workflow has no such status")
+var ErrStatusTransitionNotAllowed = errors.New("the project's workflow does not allow this status change")
+var ErrInvalidWorkflow = errors.New("a workflow needs between 1 and 30 statuses with unique names of up to 30 upper-case letters, digits or underscores, categories open, in_progress or done, transitions to other statuses of the workflow, and an open first status")
+var ErrWorkflowStatusInUse = errors.New("statuses still used by tasks cannot be removed from the workflow")
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule, supported are FREQ=DAILY, WEEKLY or MONTHLY with INTERVAL, BYDAY and either UNTIL or COUNT")
+var ErrTaskAlreadyRecurring = errors.New("the task already belongs to a recurring series")
+var ErrTaskSeriesNotFound = errors.New("the task does not belong to a recurring series")
+var ErrNextOccurrenceDelayed = errors.New("the task was updated but the next occurrence of its series could not be created yet")
+var ErrTaskSeriesEnded = errors.New("the series was ended because its creator can no longer create tasks in its project")
+var ErrInvalidEstimate = errors.New("estimates must be between 0 and 1000000 minutes")
+var ErrTimerAlreadyRunning = errors.New("you already have a running timer, stop it before starting another")
+var ErrNoRunningTimer = errors.New("you have no running timer on this task")
+var ErrTimeEntryNotFound = errors.New("time entry not found")
+var ErrInvalidTimeEntryID = errors.New("invalid time entry id")
+var ErrInvalidTimeEntry = errors.New("a time entry needs between 1 and 1440 minutes and cannot start in the future")
+var ErrInvalidTimeRange = errors.New("from and to must be dates such as 2026-10-01, to not before from and at most 366 days apart")
+var ErrInvalidSignedURL = errors.New("invalid or tampered download link")
+var ErrSignedURLExpired = errors.New("the download link has expired")
+var ErrStorageLinksNotConfigured = errors.New("local storage needs STORAGE_BASE_URL and STORAGE_SIGNING_KEY to link to files")
+var ErrFileNotFound = errors.New("file not found")
+var ErrFileRequired = errors.New("a file is required in the file form field")
+var ErrAttachmentsDisabled = errors.New("attachments are not enabled on this server")
+var ErrAttachmentNotFound = errors.New("attachment not found")
+var ErrInvalidAttachmentID = errors.New("invalid attachment id")
+var ErrInvalidAttachmentName = errors.New("attachment file names must be between 1 and 255 characters")
+var ErrEmptyAttachment = errors.New("the attached file is empty")
+var ErrAttachmentTooLarge = errors.New("the attached file is larger than the attachment size limit")
+var ErrAttachmentTypeNotAllowed = errors.New("files of this type cannot be attached")
+var ErrAttachmentFileKept = errors.New("the attachment was deleted but its file is still queued for deletion")
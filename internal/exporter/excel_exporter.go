@@ -35,13 +35,14 @@ func NewExcelExporter(headers []string, sheetName string) *ExcelExporter {
 }
 
 // Open initializes the Excel file and writes the headers to the first row.
+// Extra headers are written after the created_at and updated_at columns.
 // @Summary Open Excel file for export
 // @Description Initializes the Excel file and writes headers to the first row
 // @Tags Exporter
 // @Param filename path string true "Output filename"
 // @Success 200 {string} string "Excel file opened successfully"
 // @Failure 500 {string} string "Failed to open Excel file"
-func (e *ExcelExporter) Open(filename string) error {
+func (e *ExcelExporter) Open(filename string, extraHeaders ...string) error {
 	e.fileMutex.Lock()
 	defer e.fileMutex.Unlock()
 
@@ -54,6 +55,7 @@ func (e *ExcelExporter) Open(filename string) error {
 	allHeaders := e.headers
 	allHeaders = append([]string{"id"}, e.headers...)
 	allHeaders = append(allHeaders, []string{"created_at", "updated_at"}...)
+	allHeaders = append(allHeaders, extraHeaders...)
 
 	// Write headers to the first row of the sheet
 	for i, h := range allHeaders {
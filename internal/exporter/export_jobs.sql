@@ -1,7 +1,7 @@
 -- name: CreateExportJob :one
-INSERT INTO export_jobs (id, user_id, export_type)
-VALUES ($1, $2, $3)
-RETURNING id, user_id, export_type, status, url, error_message, created_at, updated_at;
+INSERT INTO export_jobs (id, user_id, export_type, organization_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id, user_id, export_type, status, url, error_message, created_at, updated_at, organization_id;
 
 -- name: UpdateExportJobStatus :exec
 UPDATE export_jobs
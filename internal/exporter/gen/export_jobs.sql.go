@@ -13,30 +13,37 @@ import (
 )
 
 const createExportJob = `-- name: CreateExportJob :one
-INSERT INTO export_jobs (id, user_id, export_type)
-VALUES ($1, $2, $3)
-RETURNING id, user_id, export_type, status, url, error_message, created_at, updated_at
+INSERT INTO export_jobs (id, user_id, export_type, organization_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id, user_id, export_type, status, url, error_message, created_at, updated_at, organization_id
 `
 
 type CreateExportJobParams struct {
-	ID         uuid.UUID  `json:"id"`
-	UserID     int32      `json:"user_id"`
-	ExportType ExportType `json:"export_type"`
+	ID             uuid.UUID  `json:"id"`
+	UserID         int32      `json:"user_id"`
+	ExportType     ExportType `json:"export_type"`
+	OrganizationID int64      `json:"organization_id"`
 }
 
 type CreateExportJobRow struct {
-	ID           uuid.UUID       `json:"id"`
-	UserID       int32           `json:"user_id"`
-	ExportType   ExportType      `json:"export_type"`
-	Status       ExportJobStatus `json:"status"`
-	Url          sql.NullString  `json:"url"`
-	ErrorMessage sql.NullString  `json:"error_message"`
-	CreatedAt    sql.NullTime    `json:"created_at"`
-	UpdatedAt    sql.NullTime    `json:"updated_at"`
+	ID             uuid.UUID       `json:"id"`
+	UserID         int32           `json:"user_id"`
+	ExportType     ExportType      `json:"export_type"`
+	Status         ExportJobStatus `json:"status"`
+	Url            sql.NullString  `json:"url"`
+	ErrorMessage   sql.NullString  `json:"error_message"`
+	CreatedAt      sql.NullTime    `json:"created_at"`
+	UpdatedAt      sql.NullTime    `json:"updated_at"`
+	OrganizationID int64           `json:"organization_id"`
 }
 
 func (q *Queries) CreateExportJob(ctx context.Context, arg CreateExportJobParams) (CreateExportJobRow, error) {
-	row := q.db.QueryRowContext(ctx, createExportJob, arg.ID, arg.UserID, arg.ExportType)
+	row := q.db.QueryRowContext(ctx, createExportJob,
+		arg.ID,
+		arg.UserID,
+		arg.ExportType,
+		arg.OrganizationID,
+	)
 	var i CreateExportJobRow
 	err := row.Scan(
 		&i.ID,
@@ -47,6 +54,7 @@ func (q *Queries) CreateExportJob(ctx context.Context, arg CreateExportJobParams
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrganizationID,
 	)
 	return i, err
 }
@@ -54,7 +62,7 @@ func (q *Queries) CreateExportJob(ctx context.Context, arg CreateExportJobParams
 const getExportJobStatus = `-- name: GetExportJobStatus :one
 
 
-SELECT id, user_id, status, export_type, url, error_message, created_at, updated_at FROM export_jobs 
+SELECT id, user_id, status, export_type, url, error_message, created_at, updated_at, organization_id FROM export_jobs 
 WHERE user_id=$1 AND id=$2
 `
 
@@ -75,6 +83,7 @@ func (q *Queries) GetExportJobStatus(ctx context.Context, arg GetExportJobStatus
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrganizationID,
 	)
 	return i, err
 }
@@ -29,9 +29,9 @@ type ExportHandler struct {
 	logger  *logrus.Logger
 }
 
-func RegisterExportHandler(handler *ExportHandler, router *gin.RouterGroup, jwtManager *auth.JWTManager) {
+func RegisterExportHandler(handler *ExportHandler, router *gin.RouterGroup, jwtManager *auth.JWTManager, orgResolver middleware.OrganizationResolver) {
 	exportGroup := router.Group("/export")
-	exportGroup.Use(middleware.JWTAuthMiddleware(handler.logger, jwtManager))
+	exportGroup.Use(middleware.JWTAuthMiddleware(handler.logger, jwtManager), middleware.RequireScope(handler.logger, "export"), middleware.RequireOrganization(handler.logger, orgResolver))
 	{
 		exportGroup.POST("/projects", handler.ExportProject)
 		exportGroup.POST("/tasks", handler.ExportTask)
@@ -71,6 +71,10 @@ func (exporter *ExportHandler) ExportProject(c *gin.Context) {
 		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
 		return
 	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	// 1. Extract original file name and extension
 	filename := "project-" + strconv.Itoa(userID) + ".xlsx"
 	ext := filepath.Ext(filename)
@@ -82,7 +86,7 @@ func (exporter *ExportHandler) ExportProject(c *gin.Context) {
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	jobID, err := exporter.service.ExportProjectExcel(ctx, uniqueFilename, userID)
+	jobID, err := exporter.service.ExportProjectExcel(ctx, uniqueFilename, userID, orgID)
 	if err != nil {
 		exporter.logger.Errorf("Error exporting project: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -104,6 +108,8 @@ func (exporter *ExportHandler) ExportProject(c *gin.Context) {
 // @Param        request  body      ExportTaskRequest true  "Export task request"
 // @Success      201   {object}  map[string]string
 // @Failure      400   {object}  utils.ErrorResponse
+// @Failure      403   {object}  utils.ErrorResponse
+// @Failure      404   {object}  utils.ErrorResponse
 // @Failure      500   {object}  utils.ErrorResponse
 // @Router       /api/v1/export/tasks [post]
 // @Security BearerAuth
@@ -125,6 +131,10 @@ func (exporter *ExportHandler) ExportTask(c *gin.Context) {
 		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
 		return
 	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	// 1. Extract original file name and extension
 	filename := "task-" + strconv.Itoa(userID) + ".xlsx"
 	ext := filepath.Ext(filename)
@@ -136,10 +146,10 @@ func (exporter *ExportHandler) ExportTask(c *gin.Context) {
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	jobID, err := exporter.service.ExportTaskExcel(ctx, uniqueFilename, userID, request.ProjectID)
+	jobID, err := exporter.service.ExportTaskExcel(ctx, uniqueFilename, userID, orgID, request.ProjectID)
 	if err != nil {
 		exporter.logger.Errorf("Error exporting project: %v", err)
-		c.JSON(http.StatusInternalServerError, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusInternalServerError), err.Error())
 		return
 	}
 	utils.Success(c, http.StatusCreated, map[string]string{
@@ -157,6 +167,7 @@ func (exporter *ExportHandler) ExportTask(c *gin.Context) {
 // @Param        jobId  path      string  true  "Export job ID"
 // @Success      200   {object}  map[string]any
 // @Failure      400   {object}  utils.ErrorResponse
+// @Failure      404   {object}  utils.ErrorResponse
 // @Failure      500   {object}  utils.ErrorResponse
 // @Router       /api/v1/export/status/{jobId} [get]
 // @Security BearerAuth
@@ -180,7 +191,7 @@ func (exporter *ExportHandler) GetExportStatus(c *gin.Context) {
 	exportJob, err := exporter.service.GetExportStatus(ctx, userID, jobId)
 	if err != nil {
 		exporter.logger.Errorf("%v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 	exporter.logger.Infof("%s job status was %s", exportJob.ID, exportJob.Status)
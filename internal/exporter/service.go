@@ -2,8 +2,11 @@ package exporter
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 
+	"github.com/Gkemhcs/taskpilot/internal/authz"
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	exporterdb "github.com/Gkemhcs/taskpilot/internal/exporter/gen"
 
@@ -15,6 +18,7 @@ import (
 // It interacts with the database and message queue to manage export jobs for projects and tasks.
 type ExportService struct {
 	repo             exporterdb.Querier // SQLC-generated DB interface for export jobs
+	authorizer       authz.Authorizer   // Ownership checks for the exported project
 	projectPublisher Publisher          // Publishes project export jobs to RabbitMQ
 	taskPublisher    Publisher          // Publishes task export jobs to RabbitMQ
 	logger           *logrus.Logger     // Logger for error/info reporting
@@ -22,24 +26,26 @@ type ExportService struct {
 
 // NewExportService constructs an ExportService with DB, publishers, and logger.
 func NewExportService(
-	repo exporterdb.Querier, projectPublisher, taskPublisher Publisher,
+	repo exporterdb.Querier, authorizer authz.Authorizer, projectPublisher, taskPublisher Publisher,
 	logger *logrus.Logger) *ExportService {
 	return &ExportService{
 		repo:             repo,
+		authorizer:       authorizer,
 		projectPublisher: projectPublisher,
 		taskPublisher:    taskPublisher,
 		logger:           logger,
 	}
 }
 
-// ExportProjectExcel creates a new export job for a project's data in Excel format.
+// ExportProjectExcel creates a new export job for the projects of userID in the organization, in Excel format.
 // It saves the job in the DB and publishes it to the project export queue.
-func (s *ExportService) ExportProjectExcel(ctx context.Context, fileName string, userID int) (string, error) {
+func (s *ExportService) ExportProjectExcel(ctx context.Context, fileName string, userID int, orgID int) (string, error) {
 	exportID := uuid.New()
 	params := exporterdb.CreateExportJobParams{
-		ID:         exportID,
-		ExportType: exporterdb.ExportTypeProjectExcel,
-		UserID:     int32(userID),
+		ID:             exportID,
+		ExportType:     exporterdb.ExportTypeProjectExcel,
+		UserID:         int32(userID),
+		OrganizationID: int64(orgID),
 	}
 	_, err := s.repo.CreateExportJob(ctx, params)
 	if err != nil {
@@ -49,10 +55,11 @@ func (s *ExportService) ExportProjectExcel(ctx context.Context, fileName string,
 
 	// Prepare and publish the export job message to the queue
 	msg := ExportJobMessage{
-		JobID:    exportID.String(),
-		Filename: fileName,
-		Type:     "project_excel",
-		UserID:   int64(userID),
+		JobID:          exportID.String(),
+		Filename:       fileName,
+		Type:           "project_excel",
+		UserID:         int64(userID),
+		OrganizationID: int64(orgID),
 	}
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -66,12 +73,17 @@ func (s *ExportService) ExportProjectExcel(ctx context.Context, fileName string,
 
 // ExportTaskExcel creates a new export job for tasks of a project in Excel format.
 // It saves the job in the DB and publishes it to the task export queue.
-func (s *ExportService) ExportTaskExcel(ctx context.Context, fileName string, userID int, projectid int) (string, error) {
+// The caller must be allowed to read the project being exported.
+func (s *ExportService) ExportTaskExcel(ctx context.Context, fileName string, userID int, orgID int, projectid int) (string, error) {
+	if err := s.authorizer.AuthorizeProject(ctx, userID, orgID, projectid, authz.ActionRead); err != nil {
+		return "", err
+	}
 	exportID := uuid.New()
 	params := exporterdb.CreateExportJobParams{
-		ID:         exportID,
-		ExportType: exporterdb.ExportTypeTaskExcel,
-		UserID:     int32(userID),
+		ID:             exportID,
+		ExportType:     exporterdb.ExportTypeTaskExcel,
+		UserID:         int32(userID),
+		OrganizationID: int64(orgID),
 	}
 	_, err := s.repo.CreateExportJob(ctx, params)
 	if err != nil {
@@ -80,11 +92,12 @@ func (s *ExportService) ExportTaskExcel(ctx context.Context, fileName string, us
 
 	// Prepare and publish the export job message to the queue
 	msg := ExportJobMessage{
-		JobID:     exportID.String(),
-		Filename:  fileName,
-		Type:      "task_excel",
-		UserID:    int64(userID),
-		ProjectID: int64(projectid),
+		JobID:          exportID.String(),
+		Filename:       fileName,
+		Type:           "task_excel",
+		UserID:         int64(userID),
+		ProjectID:      int64(projectid),
+		OrganizationID: int64(orgID),
 	}
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -107,6 +120,9 @@ func (s *ExportService) GetExportStatus(ctx context.Context, userId int, jobId s
 		UserID: int32(userId),
 	}
 	exportJob, err := s.repo.GetExportJobStatus(ctx, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrExportJobNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -5,8 +5,9 @@ import "context"
 // Exporter defines the interface for exporting data to a file (e.g., Excel, CSV).
 // Implementations should handle opening a file, adding rows, and saving the file to disk.
 type Exporter interface {
-	// Open initializes the export file with the given filename.
-	Open(filename string) error
+	// Open initializes the export file with the given filename. Extra headers,
+	// such as the custom fields of a project, follow the fixed columns.
+	Open(filename string, extraHeaders ...string) error
 	// AddRow appends a row of data to the export file.
 	AddRow(row []any) error
 	// Save writes the export file to the specified local directory and returns the file path.
@@ -28,9 +29,10 @@ type ExportTaskRequest struct {
 // ExportJobMessage is the message structure sent to the queue for an export job.
 // Contains metadata needed for processing and tracking the export.
 type ExportJobMessage struct {
-	JobID     string `json:"job_id"`     // Unique identifier for the export job
-	Filename  string `json:"filename"`   // Name of the file to be generated
-	Type      string `json:"type"`       // Type of export (e.g., "project_excel", "task_excel")
-	UserID    int64  `json:"user_id"`    // ID of the user requesting the export
-	ProjectID int64  `json:"project_id"` // ID of the project (if applicable)
+	JobID          string `json:"job_id"`          // Unique identifier for the export job
+	Filename       string `json:"filename"`        // Name of the file to be generated
+	Type           string `json:"type"`            // Type of export (e.g., "project_excel", "task_excel")
+	UserID         int64  `json:"user_id"`         // ID of the user requesting the export
+	ProjectID      int64  `json:"project_id"`      // ID of the project (if applicable)
+	OrganizationID int64  `json:"organization_id"` // Organization the export is scoped to
 }
@@ -1,6 +1,7 @@
 package importer
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -8,8 +9,9 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
-// RowHandlerFunc defines how to process a row.
-type RowHandlerFunc func(data map[string]string, userID int) error
+// RowHandlerFunc defines how to process a row. ctx carries the job's
+// database scope, so rows are written as the user who started the import.
+type RowHandlerFunc func(ctx context.Context, data map[string]string, userID int, orgID int) error
 
 type ExcelImporter struct {
 	ExpectedHeaders []string
@@ -24,7 +26,7 @@ func NewExcelImporter(headers []string, handler RowHandlerFunc) *ExcelImporter {
 		Mutex:           &sync.Mutex{},
 	}
 }
-func (e *ExcelImporter) Import(filePath string, _ []string,userID int) error {
+func (e *ExcelImporter) Import(ctx context.Context, filePath string, _ []string, userID int, orgID int) error {
 	f, err := excelize.OpenFile(filePath)
 	if err != nil {
 		return fmt.Errorf("open file error: %w", err)
@@ -59,7 +61,7 @@ func (e *ExcelImporter) Import(filePath string, _ []string,userID int) error {
 		}
 
 		e.Mutex.Lock()
-		err := e.HandleRow(record, userID)
+		err := e.HandleRow(ctx, record, userID, orgID)
 		e.Mutex.Unlock()
 
 		if err != nil {
@@ -14,18 +14,19 @@ import (
 
 const createImportJob = `-- name: CreateImportJob :one
 INSERT INTO import_jobs (
-  id, file_path, importer_type, user_id, status
+  id, file_path, importer_type, user_id, status, organization_id
 ) VALUES (
-  $1, $2, $3, $4, $5
-) RETURNING id, file_path, importer_type, status, error_message, created_at, updated_at, user_id
+  $1, $2, $3, $4, $5, $6
+) RETURNING id, file_path, importer_type, status, error_message, created_at, updated_at, user_id, organization_id
 `
 
 type CreateImportJobParams struct {
-	ID           uuid.UUID       `json:"id"`
-	FilePath     string          `json:"file_path"`
-	ImporterType ImportJobType   `json:"importer_type"`
-	UserID       int32           `json:"user_id"`
-	Status       ImportJobStatus `json:"status"`
+	ID             uuid.UUID       `json:"id"`
+	FilePath       string          `json:"file_path"`
+	ImporterType   ImportJobType   `json:"importer_type"`
+	UserID         int32           `json:"user_id"`
+	Status         ImportJobStatus `json:"status"`
+	OrganizationID int64           `json:"organization_id"`
 }
 
 func (q *Queries) CreateImportJob(ctx context.Context, arg CreateImportJobParams) (ImportJob, error) {
@@ -35,6 +36,7 @@ func (q *Queries) CreateImportJob(ctx context.Context, arg CreateImportJobParams
 		arg.ImporterType,
 		arg.UserID,
 		arg.Status,
+		arg.OrganizationID,
 	)
 	var i ImportJob
 	err := row.Scan(
@@ -46,12 +48,13 @@ func (q *Queries) CreateImportJob(ctx context.Context, arg CreateImportJobParams
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UserID,
+		&i.OrganizationID,
 	)
 	return i, err
 }
 
 const getImportJob = `-- name: GetImportJob :one
-SELECT id, file_path, importer_type, status, error_message, created_at, updated_at, user_id FROM import_jobs
+SELECT id, file_path, importer_type, status, error_message, created_at, updated_at, user_id, organization_id FROM import_jobs
 WHERE id = $1 and user_id = $2
 `
 
@@ -72,12 +75,13 @@ func (q *Queries) GetImportJob(ctx context.Context, arg GetImportJobParams) (Imp
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UserID,
+		&i.OrganizationID,
 	)
 	return i, err
 }
 
 const listImportJobs = `-- name: ListImportJobs :many
-SELECT id, file_path, importer_type, status, error_message, created_at, updated_at, user_id FROM import_jobs
+SELECT id, file_path, importer_type, status, error_message, created_at, updated_at, user_id, organization_id FROM import_jobs
 WHERE user_id = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -107,6 +111,7 @@ func (q *Queries) ListImportJobs(ctx context.Context, arg ListImportJobsParams)
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.UserID,
+			&i.OrganizationID,
 		); err != nil {
 			return nil, err
 		}
@@ -29,9 +29,9 @@ type ImportHandler struct {
 	logger  *logrus.Logger
 }
 
-func RegisterImporterHandler(handler *ImportHandler, router *gin.RouterGroup, jwtManager *auth.JWTManager) {
+func RegisterImporterHandler(handler *ImportHandler, router *gin.RouterGroup, jwtManager *auth.JWTManager, orgResolver middleware.OrganizationResolver) {
 	importerGroup := router.Group("/import")
-	importerGroup.Use(middleware.JWTAuthMiddleware(handler.logger, jwtManager))
+	importerGroup.Use(middleware.JWTAuthMiddleware(handler.logger, jwtManager), middleware.RequireScope(handler.logger, "import"), middleware.RequireOrganization(handler.logger, orgResolver))
 	{
 		importerGroup.POST("/projects", handler.ImportProject)
 		importerGroup.POST("/tasks", handler.ImportTask)
@@ -74,6 +74,10 @@ func (importer *ImportHandler) ImportProject(c *gin.Context) {
 		utils.Error(c, http.StatusInternalServerError, customErrors.ErrInvalidUserId.Error())
 		return
 	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	// 1. Extract original file name and extension
 	origFilename := fileHeader.Filename
 	ext := filepath.Ext(origFilename)
@@ -85,7 +89,7 @@ func (importer *ImportHandler) ImportProject(c *gin.Context) {
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	jobID, err := importer.service.ImportProjectExcel(ctx, file, uniqueFilename, userID)
+	jobID, err := importer.service.ImportProjectExcel(ctx, file, uniqueFilename, userID, orgID)
 	if err != nil {
 		importer.logger.Errorf("Error importing project: %v", err)
 		c.JSON(http.StatusInternalServerError, err.Error())
@@ -132,6 +136,10 @@ func (importer *ImportHandler) ImportTask(c *gin.Context) {
 		utils.Error(c, http.StatusInternalServerError, customErrors.ErrInvalidUserId.Error())
 		return
 	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	importer.logger.Info(userID, " : userid")
 	// 1. Extract original file name and extension
 	origFilename := fileHeader.Filename
@@ -144,7 +152,7 @@ func (importer *ImportHandler) ImportTask(c *gin.Context) {
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	jobID, err := importer.service.ImportTaskExcel(ctx, file, uniqueFilename, userID)
+	jobID, err := importer.service.ImportTaskExcel(ctx, file, uniqueFilename, userID, orgID)
 	if err != nil {
 		importer.logger.Errorf("Error importing project: %v", err)
 		c.JSON(http.StatusInternalServerError, err.Error())
@@ -164,7 +172,8 @@ func (importer *ImportHandler) ImportTask(c *gin.Context) {
 // @Produce json
 // @Param jobId path string true "Job ID"
 // @Success 200 {object} map[string]interface{} "Request succeeded"
-// @Failure 400 {object} map[string]string "Bad request or job not found"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Job not found"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router       /api/v1/import/status/{jobId} [get]
 // @Security BearerAuth
@@ -189,7 +198,7 @@ func (importer *ImportHandler) GetStatus(c *gin.Context) {
 	job, err := importer.service.Getstatus(ctx, job_id, userID)
 	if err != nil {
 		importer.logger.Errorf("%v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 	importer.logger.Infof("%s job status was %s", job.ID, job.Status)
@@ -1,8 +1,8 @@
 -- name: CreateImportJob :one
 INSERT INTO import_jobs (
-  id, file_path, importer_type, user_id, status
+  id, file_path, importer_type, user_id, status, organization_id
 ) VALUES (
-  $1, $2, $3, $4, $5
+  $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: UpdateImportJobStatus :exec
@@ -2,18 +2,18 @@ package importer
 
 import (
 	"context"
-	
 )
 
 type Importer interface {
-	Import(path string , headers []string,userID int) error
+	Import(ctx context.Context, path string, headers []string, userID int, orgID int) error
 }
 
 type ImportJobMessage struct {
-	JobID    string `json:"job_id"`
-	Filename string `json:"filename"`
-	Type     string `json:"type"`
-	UserID   int64    `json:"user_id"`
+	JobID          string `json:"job_id"`
+	Filename       string `json:"filename"`
+	Type           string `json:"type"`
+	UserID         int64  `json:"user_id"`
+	OrganizationID int64  `json:"organization_id"` // Organization the imported rows are created in
 }
 
 type Publisher interface {
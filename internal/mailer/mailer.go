@@ -0,0 +1,33 @@
+// Package mailer sends transactional email such as password reset and email
+// verification links. Implementations are chosen through configuration: SMTP
+// for real delivery and a log-only mailer for local development.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Message is a plain text email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// validate rejects messages whose headers could be used to inject extra headers.
+func (m Message) validate() error {
+	if m.To == "" {
+		return errors.New("mailer: message has no recipient")
+	}
+	if strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return errors.New("mailer: header values must not contain line breaks")
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpSink is a minimal local SMTP server that records the last message.
+type smtpSink struct {
+	listener net.Listener
+	received chan string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	sink := &smtpSink{listener: listener, received: make(chan string, 1)}
+	go sink.serve()
+	t.Cleanup(func() { listener.Close() })
+	return sink
+}
+
+func (s *smtpSink) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 sink ready")
+	var envelope []string
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			text.PrintfLine("250 sink")
+		case "MAIL", "RCPT":
+			envelope = append(envelope, line)
+			text.PrintfLine("250 ok")
+		case "DATA":
+			text.PrintfLine("354 go ahead")
+			data, _ := io.ReadAll(text.DotReader())
+			s.received <- strings.Join(envelope, "\n") + "\n" + string(data)
+			text.PrintfLine("250 queued")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	sink := newSMTPSink(t)
+	host, port, err := net.SplitHostPort(sink.listener.Addr().String())
+	require.NoError(t, err)
+
+	mailer := NewSMTPMailer(SMTPConfig{Host: host, Port: port, From: "no-reply@taskpilot.dev"})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = mailer.Send(ctx, Message{
+		To:      "eswar@gmail.com",
+		Subject: "Reset your TaskPilot password",
+		Body:    "Open this link:\nhttp://localhost/reset?token=abc",
+	})
+	require.NoError(t, err)
+
+	select {
+	case message := <-sink.received:
+		assert.Contains(t, message, "MAIL FROM:<no-reply@taskpilot.dev>")
+		assert.Contains(t, message, "RCPT TO:<eswar@gmail.com>")
+		assert.Contains(t, message, "Subject: Reset your TaskPilot password")
+		assert.Contains(t, message, "http://localhost/reset?token=abc")
+	case <-time.After(5 * time.Second):
+		t.Fatal("sink did not receive the message")
+	}
+}
+
+func TestMessageHeaderInjection(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	err := NewLogMailer(logger).Send(context.Background(), Message{
+		To:      "eswar@gmail.com\r\nBcc: attacker@example.com",
+		Subject: "hello",
+	})
+	assert.Error(t, err)
+
+	err = NewSMTPMailer(SMTPConfig{Host: "127.0.0.1", Port: "1"}).Send(context.Background(), Message{
+		To:      "eswar@gmail.com",
+		Subject: "hi\nBcc: attacker@example.com",
+	})
+	assert.Error(t, err)
+}
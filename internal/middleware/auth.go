@@ -42,12 +42,39 @@ func JWTAuthMiddleware(logger *logrus.Logger, jwtManager *auth.JWTManager) gin.H
 		}
 
 		tokenString := parts[1]
+
+		// Personal access tokens are looked up by hash instead of being verified as JWTs
+		if auth.IsPersonalAccessToken(tokenString) {
+			userClaims, err := jwtManager.VerifyPersonalAccessToken(c.Request.Context(), tokenString)
+			if errors.Is(err, customErrors.ErrInvalidPersonalAccessToken) {
+				logger.WithFields(logrus.Fields{
+					"path":   c.FullPath(),
+					"method": c.Request.Method,
+					"ip":     c.ClientIP(),
+				}).Warn("Invalid personal access token")
+				utils.Error(c, http.StatusUnauthorized, err.Error())
+				return
+			}
+			if err != nil {
+				logger.WithFields(logrus.Fields{
+					"path":   c.FullPath(),
+					"method": c.Request.Method,
+					"error":  err.Error(),
+				}).Error("Personal access token lookup failed")
+				utils.Error(c, http.StatusServiceUnavailable, "unable to verify token, try again later")
+				return
+			}
+			setUserContext(c, userClaims)
+			c.Next()
+			return
+		}
+
 		// Verify the JWT token
 		userClaims, err := jwtManager.Verify(tokenString)
-		if errors.Is(err,customErrors.ErrTokenExpired){
-			logger.Errorf("%v",customErrors.ErrTokenExpired)
-			utils.Error(c,http.StatusBadRequest,customErrors.ErrTokenExpired.Error())
-			return 
+		if errors.Is(err, customErrors.ErrTokenExpired) {
+			logger.Errorf("%v", customErrors.ErrTokenExpired)
+			utils.Error(c, http.StatusBadRequest, customErrors.ErrTokenExpired.Error())
+			return
 		}
 		if err != nil {
 			// Log and return error if token verification fails
@@ -61,10 +88,36 @@ func JWTAuthMiddleware(logger *logrus.Logger, jwtManager *auth.JWTManager) gin.H
 			return
 		}
 
-		// Attach user ID to request context for downstream handlers
-		c.Set("userID", int(userClaims.UserID))
-		c.Set("userName",userClaims.Username)
-		c.Set("email",userClaims.Email)
+		// Reject tokens that were logged out or whose session family was revoked
+		revoked, err := jwtManager.IsRevoked(c.Request.Context(), userClaims)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"path":   c.FullPath(),
+				"method": c.Request.Method,
+				"error":  err.Error(),
+			}).Error("Token revocation check failed")
+			utils.Error(c, http.StatusServiceUnavailable, "unable to verify token, try again later")
+			return
+		}
+		if revoked {
+			logger.WithFields(logrus.Fields{
+				"path":   c.FullPath(),
+				"method": c.Request.Method,
+				"ip":     c.ClientIP(),
+			}).Warn("Revoked token used")
+			utils.Error(c, http.StatusUnauthorized, customErrors.ErrTokenRevoked.Error())
+			return
+		}
+
+		setUserContext(c, userClaims)
 		c.Next()
 	}
 }
+
+// setUserContext attaches the authenticated user to the request context for downstream handlers.
+func setUserContext(c *gin.Context, userClaims *auth.UserClaims) {
+	c.Set("userID", int(userClaims.UserID))
+	c.Set("userName", userClaims.Username)
+	c.Set("email", userClaims.Email)
+	c.Set("claims", userClaims)
+}
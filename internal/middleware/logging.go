@@ -15,8 +15,9 @@ func LoggerMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 
 		// Log the incoming request
 		logger.WithFields(logrus.Fields{
-			"method": c.Request.Method,
-			"path":   c.Request.URL.Path,
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"request_id": c.GetString("requestID"),
 		}).Info("Incoming request")
 
 		c.Next()
@@ -25,10 +26,11 @@ func LoggerMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 		err := c.Errors.Last()
 
 		entry := logger.WithFields(logrus.Fields{
-			"method":  c.Request.Method,
-			"path":    c.Request.URL.Path,
-			"status":  status,
-			"latency": latency,
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"status":     status,
+			"latency":    latency,
+			"request_id": c.GetString("requestID"),
 		})
 		// Log errors or failed requests
 		if err != nil || status >= 400 {
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOrganizationResolver is a mock implementation of the OrganizationResolver interface
+type MockOrganizationResolver struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationResolver) ResolveOrganization(ctx context.Context, userID int, requested int) (int, error) {
+	args := m.Called(ctx, userID, requested)
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// OrganizationHeader selects the organization a request acts in, overriding
+// the organization stored in the token.
+const OrganizationHeader = "X-Org-ID"
+
+// OrganizationResolver decides which organization a request acts in.
+type OrganizationResolver interface {
+	// ResolveOrganization returns requested if userID is a member of it, or the
+	// user's personal workspace when requested is 0. It returns
+	// customErrors.ErrOrganizationNotFound for organizations the user is not in.
+	ResolveOrganization(ctx context.Context, userID int, requested int) (int, error)
+}
+
+// RequireOrganization resolves the organization from the X-Org-ID header, or
+// the org_id claim of the token, and sets "orgID" in the context. Requests
+// without either act in the caller's personal workspace.
+// It must run after JWTAuthMiddleware.
+func RequireOrganization(logger *logrus.Logger, resolver OrganizationResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetInt("userID")
+		requested := 0
+		if claims, ok := requestClaims(c); ok {
+			requested = claims.OrgID
+		}
+		if header := c.GetHeader(OrganizationHeader); header != "" {
+			orgID, err := strconv.Atoi(header)
+			if err != nil || orgID <= 0 {
+				utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidOrganizationID.Error())
+				return
+			}
+			requested = orgID
+		}
+
+		orgID, err := resolver.ResolveOrganization(c.Request.Context(), userID, requested)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"path":   c.FullPath(),
+				"method": c.Request.Method,
+				"userID": userID,
+				"orgID":  requested,
+				"error":  err.Error(),
+			}).Warn("Organization could not be resolved")
+			utils.Error(c, utils.ErrorStatus(err, http.StatusInternalServerError), err.Error())
+			return
+		}
+		c.Set("orgID", orgID)
+		c.Next()
+	}
+}
+
+// OrganizationID returns the organization set by RequireOrganization. When it
+// is missing the error response is written and ok is false.
+func OrganizationID(c *gin.Context) (int, bool) {
+	orgID, ok := c.Get("orgID")
+	if !ok {
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrOrganizationNotResolved.Error())
+		return 0, false
+	}
+	id, ok := orgID.(int)
+	if !ok {
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrOrganizationNotResolved.Error())
+		return 0, false
+	}
+	return id, true
+}
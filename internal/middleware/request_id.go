@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/Gkemhcs/taskpilot/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the id of a request in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID tags every request with an id, taken from the X-Request-ID header
+// when the client or a proxy sent a usable one, and echoes it back. The id and
+// the client ip are attached to the request context so the audit log records
+// which request caused a write. It must run before TenantScope.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+		ctx := db.WithRequestInfo(c.Request.Context(), db.RequestInfo{ID: requestID, IP: c.ClientIP()})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// validRequestID accepts short ids made of letters, digits, '-', '_' and '.'
+// so a client cannot smuggle arbitrary text into the audit log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
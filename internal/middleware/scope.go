@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RequireScope limits personal access tokens on a route group to the scopes
+// granted to them. Safe methods need "<resource>:read", everything else
+// "<resource>:write". Session JWTs are not scoped and always pass.
+// It must run after JWTAuthMiddleware.
+func RequireScope(logger *logrus.Logger, resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := requestClaims(c)
+		if !ok || !claims.IsPersonalAccessToken() {
+			c.Next()
+			return
+		}
+
+		required := resource + ":write"
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			required = resource + ":read"
+		}
+		if !auth.HasScope(claims.Scopes, required) {
+			logger.WithFields(logrus.Fields{
+				"path":   c.FullPath(),
+				"method": c.Request.Method,
+				"userID": claims.UserID,
+				"scope":  required,
+			}).Warn("Personal access token missing required scope")
+			utils.Error(c, http.StatusForbidden, customErrors.ErrInsufficientScope.Error())
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession rejects personal access tokens, for routes such as token
+// management and logout that must only be reachable from a login session.
+// It must run after JWTAuthMiddleware.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := requestClaims(c); ok && claims.IsPersonalAccessToken() {
+			utils.Error(c, http.StatusForbidden, customErrors.ErrPersonalAccessTokenNotAllowed.Error())
+			return
+		}
+		c.Next()
+	}
+}
+
+func requestClaims(c *gin.Context) (*auth.UserClaims, bool) {
+	val, exists := c.Get("claims")
+	if !exists {
+		return nil, false
+	}
+	claims, ok := val.(*auth.UserClaims)
+	return claims, ok
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// TenantBinder binds a request context to the user its queries run for.
+// It is implemented by db.TenantDB.
+type TenantBinder interface {
+	Bind(ctx context.Context, userID func() (int, bool)) (context.Context, func())
+	// WithAdmin binds ctx to an administrator that sees every tenant's rows.
+	WithAdmin(ctx context.Context, userID int) (context.Context, func())
+}
+
+// TenantScope makes every query of the request run as the authenticated user,
+// so the row-level security policies only expose that user's rows. The user is
+// read from "userID" when the first query runs, which lets it wrap whole route
+// groups ahead of JWTAuthMiddleware.
+func TenantScope(binder TenantBinder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, release := binder.Bind(c.Request.Context(), func() (int, bool) {
+			userID, ok := c.Get("userID")
+			if !ok {
+				return 0, false
+			}
+			id, ok := userID.(int)
+			return id, ok
+		})
+		defer release()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// RequireAdmin rejects callers whose token does not carry the admin claim and
+// lets the queries of everyone else bypass row-level security, so admins can
+// manage every tenant. It must run after JWTAuthMiddleware.
+func RequireAdmin(logger *logrus.Logger, binder TenantBinder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := requestClaims(c)
+		if !ok || !claims.IsAdmin {
+			logger.WithFields(logrus.Fields{
+				"path":   c.FullPath(),
+				"method": c.Request.Method,
+				"userID": c.GetInt("userID"),
+			}).Warn("Admin route used without the admin role")
+			utils.Error(c, http.StatusForbidden, customErrors.ErrAdminRequired.Error())
+			return
+		}
+		ctx, release := binder.WithAdmin(c.Request.Context(), claims.UserID)
+		defer release()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
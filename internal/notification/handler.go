@@ -0,0 +1,177 @@
+package notification
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/middleware"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func NewNotificationHandler(logger *logrus.Logger, notificationService *NotificationService, jwtManager *auth.JWTManager) *NotificationHandler {
+	return &NotificationHandler{
+		logger:              logger,
+		notificationService: notificationService,
+		jwtManager:          jwtManager,
+	}
+}
+
+type NotificationHandler struct {
+	logger              *logrus.Logger
+	notificationService *NotificationService
+	jwtManager          *auth.JWTManager
+}
+
+// RegisterNotificationRoutes registers the inbox of the authenticated user.
+// Notifications are about tasks, so personal access tokens need the tasks scope.
+func RegisterNotificationRoutes(r *gin.RouterGroup, handler *NotificationHandler) {
+	notificationRouter := r.Group("/notifications", middleware.JWTAuthMiddleware(handler.logger, handler.jwtManager), middleware.RequireScope(handler.logger, "tasks"))
+	{
+		notificationRouter.GET("", handler.ListNotifications)
+		notificationRouter.GET("/unread-count", handler.UnreadCount)
+		notificationRouter.POST("/read-all", handler.MarkAllRead)
+		notificationRouter.POST("/:id/read", handler.MarkRead)
+	}
+}
+
+// ListNotifications lists the caller's notifications
+// @Summary      List notifications
+// @Description  Lists the caller's assignment and mention notifications, newest first
+// @Tags         notifications
+// @Produce      json
+// @Param        unread  query     bool  false  "Only unread notifications"
+// @Param        limit   query     int   false  "Page size (default 20, max 100)"
+// @Param        offset  query     int   false  "Number of notifications to skip"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  utils.ErrorResponse
+// @Router       /api/v1/notifications [get]
+// @Security BearerAuth
+func (n *NotificationHandler) ListNotifications(c *gin.Context) {
+	var filter Filter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	userID, ok := n.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	notifications, err := n.notificationService.ListNotifications(ctx, userID, filter)
+	if err == customErrors.ErrInvalidPagination {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		n.logger.Errorf("unable to list the notifications of user %d %v", userID, err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, notifications)
+}
+
+// UnreadCount counts the caller's unread notifications
+// @Summary      Count unread notifications
+// @Description  Returns the number of notifications the caller has not read
+// @Tags         notifications
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Router       /api/v1/notifications/unread-count [get]
+// @Security BearerAuth
+func (n *NotificationHandler) UnreadCount(c *gin.Context) {
+	userID, ok := n.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	count, err := n.notificationService.UnreadCount(ctx, userID)
+	if err != nil {
+		n.logger.Errorf("unable to count the unread notifications of user %d %v", userID, err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"unread": count,
+	})
+}
+
+// MarkRead marks a notification as read
+// @Summary      Mark a notification as read
+// @Tags         notifications
+// @Produce      json
+// @Param        id   path      int  true  "Notification ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/notifications/{id}/read [post]
+// @Security BearerAuth
+func (n *NotificationHandler) MarkRead(c *gin.Context) {
+	notificationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || notificationID <= 0 {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidNotificationID.Error())
+		return
+	}
+	userID, ok := n.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := n.notificationService.MarkRead(ctx, userID, notificationID); err != nil {
+		n.logger.Errorf("unable to mark notification %d as read %v", notificationID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "notification marked as read",
+	})
+}
+
+// MarkAllRead marks every notification as read
+// @Summary      Mark all notifications as read
+// @Tags         notifications
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Router       /api/v1/notifications/read-all [post]
+// @Security BearerAuth
+func (n *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := n.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	count, err := n.notificationService.MarkAllRead(ctx, userID)
+	if err != nil {
+		n.logger.Errorf("unable to mark the notifications of user %d as read %v", userID, err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"marked": count,
+	})
+}
+
+func (n *NotificationHandler) userID(c *gin.Context) (int, bool) {
+	val, exists := c.Get("userID")
+	if !exists {
+		n.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return 0, false
+	}
+	userID, ok := val.(int)
+	if !ok {
+		n.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return 0, false
+	}
+	return userID, true
+}
@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	notificationdb "github.com/Gkemhcs/taskpilot/internal/notification/gen"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationHandlers(t *testing.T) {
+	jwtManager := auth.NewJWTManager(auth.CreateJwtManagerParams{
+		AccessTokenDuration:  10 * time.Minute,
+		RefreshTokenDuration: 10 * time.Hour,
+		AccessTokenKey:       "rnk3mkrk3rk3rk3",
+		RefreshTokenKey:      "21ieh12iei21eji12e",
+	})
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo := new(MockNotificationRepo)
+	repo.On("ListNotifications", mock.Anything, mock.Anything).Return([]notificationdb.Notification{{ID: 4, UserID: 2}}, nil)
+	repo.On("CountUnreadNotifications", mock.Anything, int32(2)).Return(int64(1), nil)
+	repo.On("MarkNotificationRead", mock.Anything, notificationdb.MarkNotificationReadParams{ID: 4, UserID: 2}).Return(int64(1), nil)
+	repo.On("MarkNotificationRead", mock.Anything, mock.Anything).Return(int64(0), nil)
+	repo.On("MarkAllNotificationsRead", mock.Anything, int32(2)).Return(int64(1), nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterNotificationRoutes(r.Group("/api/v1"), NewNotificationHandler(logger, NewNotificationService(repo, logger), jwtManager))
+
+	token, err := jwtManager.GenerateAccessToken(2, "dev", "dev@taskpilot.dev")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		testName           string
+		method             string
+		path               string
+		expectedStatusCode int
+	}{
+		{testName: "list notifications", method: http.MethodGet, path: "/api/v1/notifications?unread=true", expectedStatusCode: http.StatusOK},
+		{testName: "page size is limited", method: http.MethodGet, path: "/api/v1/notifications?limit=500", expectedStatusCode: http.StatusBadRequest},
+		{testName: "unread count", method: http.MethodGet, path: "/api/v1/notifications/unread-count", expectedStatusCode: http.StatusOK},
+		{testName: "mark a notification as read", method: http.MethodPost, path: "/api/v1/notifications/4/read", expectedStatusCode: http.StatusOK},
+		{testName: "notification of another user", method: http.MethodPost, path: "/api/v1/notifications/5/read", expectedStatusCode: http.StatusNotFound},
+		{testName: "invalid notification id", method: http.MethodPost, path: "/api/v1/notifications/abc/read", expectedStatusCode: http.StatusBadRequest},
+		{testName: "mark all as read", method: http.MethodPost, path: "/api/v1/notifications/read-all", expectedStatusCode: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			req, _ := http.NewRequestWithContext(context.TODO(), tc.method, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code, w.Body.String())
+		})
+	}
+}
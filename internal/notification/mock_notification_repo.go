@@ -0,0 +1,43 @@
+package notification
+
+import (
+	"context"
+
+	notificationdb "github.com/Gkemhcs/taskpilot/internal/notification/gen"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNotificationRepo is a mock implementation of the notificationdb.Querier interface
+type MockNotificationRepo struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepo) CountUnreadNotifications(ctx context.Context, userID int32) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepo) CreateNotifications(ctx context.Context, arg notificationdb.CreateNotificationsParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepo) ListNotifications(ctx context.Context, arg notificationdb.ListNotificationsParams) ([]notificationdb.Notification, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]notificationdb.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepo) ListTaskUsersByEmail(ctx context.Context, arg notificationdb.ListTaskUsersByEmailParams) ([]int32, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]int32), args.Error(1)
+}
+
+func (m *MockNotificationRepo) MarkAllNotificationsRead(ctx context.Context, userID int32) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepo) MarkNotificationRead(ctx context.Context, arg notificationdb.MarkNotificationReadParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
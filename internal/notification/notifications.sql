@@ -0,0 +1,29 @@
+-- name: CreateNotifications :execrows
+-- no RETURNING: the sender cannot read notifications addressed to others
+INSERT INTO notifications (user_id, actor_id, kind, task_id, comment_id)
+SELECT unnest(sqlc.arg('user_ids')::int[]), sqlc.arg('actor_id')::int, sqlc.arg('kind')::notification_kind, sqlc.arg('task_id')::bigint, sqlc.narg('comment_id')::bigint;
+
+-- name: ListTaskUsersByEmail :many
+-- the users among emails who own the task's project or are members of it
+SELECT u.id FROM users u
+JOIN tasks t ON t.id = sqlc.arg('task_id')
+JOIN projects p ON p.id = t.project_id
+WHERE lower(u.email) = ANY(sqlc.arg('emails')::text[])
+  AND (u.id = p.user_id OR EXISTS (
+    SELECT 1 FROM project_members pm WHERE pm.project_id = p.id AND pm.user_id = u.id
+  ));
+
+-- name: ListNotifications :many
+SELECT * FROM notifications
+WHERE user_id = sqlc.arg('user_id') AND (NOT sqlc.arg('unread_only')::boolean OR read_at IS NULL)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountUnreadNotifications :one
+SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: MarkNotificationRead :execrows
+UPDATE notifications SET read_at = COALESCE(read_at, now()) WHERE id = $1 AND user_id = $2;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications SET read_at = now() WHERE user_id = $1 AND read_at IS NULL;
@@ -0,0 +1,198 @@
+// Package notification keeps the in-app inbox. NotificationService subscribes
+// to the task service's events and notifies users when they are assigned a
+// task or mentioned as @email in a task description or comment.
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	notificationdb "github.com/Gkemhcs/taskpilot/internal/notification/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/sirupsen/logrus"
+)
+
+// maxMentions caps the addresses resolved per description or comment.
+const maxMentions = 50
+
+// mentionPattern matches @email preceded by the start of the text or a
+// character that cannot be part of an address, so plain addresses are not
+// mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9._%+@-])@([A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+)`)
+
+func NewNotificationService(repo notificationdb.Querier, logger *logrus.Logger) *NotificationService {
+	return &NotificationService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+type NotificationService struct {
+	repo   notificationdb.Querier
+	logger *logrus.Logger
+}
+
+// HandleTaskEvent notifies the new assignee of a created or reassigned task and
+// the project members newly mentioned in its description or in a comment.
+// Nobody is notified about their own writes. Failures are logged, the write
+// that caused the event has already happened.
+func (n *NotificationService) HandleTaskEvent(ctx context.Context, event types.TaskEvent) {
+	if err := n.notify(ctx, event); err != nil {
+		n.logger.Errorf("unable to send the notifications for %s on task %d %v", event.Kind, event.TaskID, err)
+	}
+}
+
+func (n *NotificationService) notify(ctx context.Context, event types.TaskEvent) error {
+	actorID := int32(event.ActorID)
+	var text, previousText string
+	var commentID sql.NullInt64
+	var assigned []int32
+	switch event.Kind {
+	case types.TaskCreated, types.TaskUpdated:
+		if event.Task == nil {
+			return nil
+		}
+		text = event.Task.Description
+		assignee := event.Task.AssigneeID
+		if event.Previous != nil {
+			previousText = event.Previous.Description
+		}
+		if assignee.Valid && int32(assignee.Int64) != actorID && (event.Previous == nil || event.Previous.AssigneeID != assignee) {
+			assigned = []int32{int32(assignee.Int64)}
+		}
+	case types.CommentCreated, types.CommentUpdated:
+		if event.Comment == nil {
+			return nil
+		}
+		text = event.Comment.Body
+		commentID = sql.NullInt64{Int64: event.Comment.ID, Valid: true}
+		if event.PreviousComment != nil {
+			previousText = event.PreviousComment.Body
+		}
+	default:
+		return nil
+	}
+	if err := n.send(ctx, notificationdb.NotificationKindAssignment, actorID, event.TaskID, commentID, assigned); err != nil {
+		return err
+	}
+
+	emails := newMentions(text, previousText)
+	if len(emails) == 0 {
+		return nil
+	}
+	users, err := n.repo.ListTaskUsersByEmail(ctx, notificationdb.ListTaskUsersByEmailParams{
+		TaskID: event.TaskID,
+		Emails: emails,
+	})
+	if err != nil {
+		return err
+	}
+	// an assignee mentioned in the description is only told about the assignment
+	var mentioned []int32
+	for _, userID := range users {
+		if userID != actorID && (len(assigned) == 0 || userID != assigned[0]) {
+			mentioned = append(mentioned, userID)
+		}
+	}
+	return n.send(ctx, notificationdb.NotificationKindMention, actorID, event.TaskID, commentID, mentioned)
+}
+
+func (n *NotificationService) send(ctx context.Context, kind notificationdb.NotificationKind, actorID int32, taskID int64, commentID sql.NullInt64, userIDs []int32) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	_, err := n.repo.CreateNotifications(ctx, notificationdb.CreateNotificationsParams{
+		UserIds:   userIDs,
+		ActorID:   actorID,
+		Kind:      kind,
+		TaskID:    taskID,
+		CommentID: commentID,
+	})
+	return err
+}
+
+// ListNotifications returns a page of the user's notifications, newest first.
+func (n *NotificationService) ListNotifications(ctx context.Context, userID int, filter Filter) ([]notificationdb.Notification, error) {
+	limit, offset, err := filter.Page.Bounds()
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := n.repo.ListNotifications(ctx, notificationdb.ListNotificationsParams{
+		UserID:     int32(userID),
+		UnreadOnly: filter.UnreadOnly,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []notificationdb.Notification{}
+	}
+	return notifications, nil
+}
+
+// UnreadCount returns the number of notifications the user has not read.
+func (n *NotificationService) UnreadCount(ctx context.Context, userID int) (int64, error) {
+	return n.repo.CountUnreadNotifications(ctx, int32(userID))
+}
+
+// MarkRead marks one of the user's notifications as read. Marking a read
+// notification again keeps its original read time.
+func (n *NotificationService) MarkRead(ctx context.Context, userID int, notificationID int64) error {
+	rows, err := n.repo.MarkNotificationRead(ctx, notificationdb.MarkNotificationReadParams{
+		ID:     notificationID,
+		UserID: int32(userID),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and returns
+// how many there were.
+func (n *NotificationService) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	return n.repo.MarkAllNotificationsRead(ctx, int32(userID))
+}
+
+// mentions returns the lower-cased addresses mentioned in text, in order of
+// first appearance and at most maxMentions of them.
+func mentions(text string) []string {
+	var emails []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		email := strings.ToLower(match[1])
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		emails = append(emails, email)
+		if len(emails) == maxMentions {
+			break
+		}
+	}
+	return emails
+}
+
+// newMentions returns the addresses mentioned in text but not in previous, so
+// editing a description or comment does not notify the same people again.
+func newMentions(text, previous string) []string {
+	before := make(map[string]bool)
+	for _, email := range mentions(previous) {
+		before[email] = true
+	}
+	var emails []string
+	for _, email := range mentions(text) {
+		if !before[email] {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
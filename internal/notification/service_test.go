@@ -0,0 +1,156 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"testing"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	notificationdb "github.com/Gkemhcs/taskpilot/internal/notification/gen"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newService() (*NotificationService, *MockNotificationRepo) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo := new(MockNotificationRepo)
+	return NewNotificationService(repo, logger), repo
+}
+
+func TestMentions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "mention at the start", text: "@Dev@TaskPilot.dev please review", expected: []string{"dev@taskpilot.dev"}},
+		{name: "trailing punctuation is not part of the address", text: "thanks (@ops@taskpilot.dev), cc @qa@taskpilot.dev.", expected: []string{"ops@taskpilot.dev", "qa@taskpilot.dev"}},
+		{name: "repeated mentions count once", text: "@dev@taskpilot.dev and again @DEV@taskpilot.dev", expected: []string{"dev@taskpilot.dev"}},
+		{name: "plain addresses are not mentions", text: "mail dev@taskpilot.dev or me@x@taskpilot.dev", expected: nil},
+		{name: "no domain", text: "@dev@localhost", expected: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, mentions(tc.text))
+		})
+	}
+}
+
+func TestHandleTaskEvent(t *testing.T) {
+	assignee := func(id int64) sql.NullInt64 { return sql.NullInt64{Int64: id, Valid: true} }
+	comment := sql.NullInt64{Int64: 9, Valid: true}
+
+	testCases := []struct {
+		name            string
+		event           types.TaskEvent
+		expectedEmails  []string
+		expectedCreates []notificationdb.CreateNotificationsParams
+	}{
+		{
+			name: "assignee and mentioned members of a new task",
+			event: types.TaskEvent{Kind: types.TaskCreated, ActorID: 1, TaskID: 5, Task: &taskdb.Task{
+				ID: 5, AssigneeID: assignee(2), Description: "@dev@taskpilot.dev @ops@taskpilot.dev @me@taskpilot.dev",
+			}},
+			expectedEmails: []string{"dev@taskpilot.dev", "ops@taskpilot.dev", "me@taskpilot.dev"},
+			expectedCreates: []notificationdb.CreateNotificationsParams{
+				{UserIds: []int32{2}, ActorID: 1, Kind: notificationdb.NotificationKindAssignment, TaskID: 5},
+				{UserIds: []int32{3}, ActorID: 1, Kind: notificationdb.NotificationKindMention, TaskID: 5},
+			},
+		},
+		{
+			name:  "nobody is notified about assigning themselves",
+			event: types.TaskEvent{Kind: types.TaskCreated, ActorID: 1, TaskID: 5, Task: &taskdb.Task{ID: 5, AssigneeID: assignee(1)}},
+		},
+		{
+			name: "an update without reassignment only notifies new mentions",
+			event: types.TaskEvent{Kind: types.TaskUpdated, ActorID: 1, TaskID: 5,
+				Task:     &taskdb.Task{ID: 5, AssigneeID: assignee(2), Description: "@dev@taskpilot.dev and @ops@taskpilot.dev"},
+				Previous: &taskdb.Task{ID: 5, AssigneeID: assignee(2), Description: "@dev@taskpilot.dev"},
+			},
+			expectedEmails: []string{"ops@taskpilot.dev"},
+			expectedCreates: []notificationdb.CreateNotificationsParams{
+				{UserIds: []int32{3}, ActorID: 1, Kind: notificationdb.NotificationKindMention, TaskID: 5},
+			},
+		},
+		{
+			name: "reassignment",
+			event: types.TaskEvent{Kind: types.TaskUpdated, ActorID: 1, TaskID: 5,
+				Task:     &taskdb.Task{ID: 5, AssigneeID: assignee(3)},
+				Previous: &taskdb.Task{ID: 5, AssigneeID: assignee(2)},
+			},
+			expectedCreates: []notificationdb.CreateNotificationsParams{
+				{UserIds: []int32{3}, ActorID: 1, Kind: notificationdb.NotificationKindAssignment, TaskID: 5},
+			},
+		},
+		{
+			name: "mention in a comment",
+			event: types.TaskEvent{Kind: types.CommentCreated, ActorID: 1, TaskID: 5,
+				Comment: &taskdb.TaskComment{ID: 9, TaskID: 5, Body: "@dev@taskpilot.dev can you check?"},
+			},
+			expectedEmails: []string{"dev@taskpilot.dev"},
+			expectedCreates: []notificationdb.CreateNotificationsParams{
+				{UserIds: []int32{2}, ActorID: 1, Kind: notificationdb.NotificationKindMention, TaskID: 5, CommentID: comment},
+			},
+		},
+		{
+			name: "editing a comment does not notify the same people again",
+			event: types.TaskEvent{Kind: types.CommentUpdated, ActorID: 1, TaskID: 5,
+				Comment:         &taskdb.TaskComment{ID: 9, TaskID: 5, Body: "@dev@taskpilot.dev can you check now?"},
+				PreviousComment: &taskdb.TaskComment{ID: 9, TaskID: 5, Body: "@dev@taskpilot.dev can you check?"},
+			},
+		},
+	}
+
+	// dev is user 2, ops user 3 and me, the actor, user 1
+	users := map[string]int32{"dev@taskpilot.dev": 2, "ops@taskpilot.dev": 3, "me@taskpilot.dev": 1}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newService()
+			if tc.expectedEmails != nil {
+				var found []int32
+				for _, email := range tc.expectedEmails {
+					found = append(found, users[email])
+				}
+				repo.On("ListTaskUsersByEmail", mock.Anything, notificationdb.ListTaskUsersByEmailParams{TaskID: 5, Emails: tc.expectedEmails}).Return(found, nil)
+			}
+			for _, params := range tc.expectedCreates {
+				repo.On("CreateNotifications", mock.Anything, params).Return(int64(len(params.UserIds)), nil).Once()
+			}
+
+			service.HandleTaskEvent(context.TODO(), tc.event)
+			repo.AssertExpectations(t)
+			repo.AssertNumberOfCalls(t, "CreateNotifications", len(tc.expectedCreates))
+		})
+	}
+}
+
+func TestListNotifications(t *testing.T) {
+	service, repo := newService()
+	repo.On("ListNotifications", mock.Anything, notificationdb.ListNotificationsParams{UserID: 2, UnreadOnly: true, Limit: types.DefaultPageSize}).Return([]notificationdb.Notification(nil), nil)
+
+	t.Run("unread notifications", func(t *testing.T) {
+		notifications, err := service.ListNotifications(context.TODO(), 2, Filter{UnreadOnly: true})
+		assert.NoError(t, err)
+		assert.NotNil(t, notifications)
+	})
+
+	t.Run("page size is limited", func(t *testing.T) {
+		limit := int32(types.MaxPageSize + 1)
+		_, err := service.ListNotifications(context.TODO(), 2, Filter{Page: types.Page{Limit: &limit}})
+		assert.Equal(t, customErrors.ErrInvalidPagination, err)
+	})
+}
+
+func TestMarkRead(t *testing.T) {
+	service, repo := newService()
+	repo.On("MarkNotificationRead", mock.Anything, notificationdb.MarkNotificationReadParams{ID: 4, UserID: 2}).Return(int64(1), nil)
+	repo.On("MarkNotificationRead", mock.Anything, mock.Anything).Return(int64(0), nil)
+
+	assert.NoError(t, service.MarkRead(context.TODO(), 2, 4))
+	assert.Equal(t, customErrors.ErrNotificationNotFound, service.MarkRead(context.TODO(), 3, 4))
+}
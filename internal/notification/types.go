@@ -0,0 +1,12 @@
+package notification
+
+import (
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+// Filter selects a page of the inbox, bound from the unread, limit and offset
+// query parameters.
+type Filter struct {
+	types.Page
+	UnreadOnly bool `form:"unread"`
+}
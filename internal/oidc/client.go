@@ -0,0 +1,127 @@
+// Package oidc implements the OpenID Connect authorization code flow with PKCE
+// used for single sign-on. It discovers the provider endpoints, keeps the
+// per-login state server side and verifies ID tokens against the provider JWKS.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"golang.org/x/oauth2"
+)
+
+// loginStateTTL is how long a user has to complete the login at the provider.
+const loginStateTTL = 10 * time.Minute
+
+// NewClient discovers the provider described by cfg and returns a client for
+// its authorization code flow. Pending logins are kept in store.
+func NewClient(ctx context.Context, cfg Config, store StateStore) (*Client, error) {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	p, err := discover(ctx, httpClient, cfg.IssuerURL)
+	if err != nil {
+		return nil, err
+	}
+	scopes := []string{"openid"}
+	for _, scope := range cfg.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return &Client{
+		provider: p,
+		store:    store,
+		clientID: cfg.ClientID,
+		oauth2Config: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  p.doc.AuthorizationEndpoint,
+				TokenURL: p.doc.TokenEndpoint,
+			},
+		},
+	}, nil
+}
+
+// Client runs OpenID Connect logins against a single provider.
+type Client struct {
+	provider     *provider
+	store        StateStore
+	clientID     string
+	oauth2Config *oauth2.Config
+}
+
+// AuthCodeURL starts a login: it stores a fresh state, PKCE verifier and nonce
+// and returns the provider URL the browser must be redirected to.
+func (c *Client) AuthCodeURL(ctx context.Context) (string, error) {
+	state, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	login := LoginState{
+		CodeVerifier: oauth2.GenerateVerifier(),
+		Nonce:        nonce,
+	}
+	if err := c.store.Save(ctx, state, login, loginStateTTL); err != nil {
+		return "", err
+	}
+	return c.oauth2Config.AuthCodeURL(
+		state,
+		oauth2.S256ChallengeOption(login.CodeVerifier),
+		oauth2.SetAuthURLParam("nonce", login.Nonce),
+	), nil
+}
+
+// Exchange completes a login from the provider callback. It redeems code with
+// the stored PKCE verifier and returns the identity from the verified ID token.
+func (c *Client) Exchange(ctx context.Context, state string, code string) (*Identity, error) {
+	login, err := c.store.Take(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, c.provider.httpClient)
+	token, err := c.oauth2Config.Exchange(ctx, code, oauth2.VerifierOption(login.CodeVerifier))
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		return nil, fmt.Errorf("%w: %s", customErrors.ErrOIDCExchangeFailed, retrieveErr.ErrorCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", customErrors.ErrOIDCInvalidIDToken)
+	}
+
+	claims, err := c.provider.verifyIDToken(ctx, rawIDToken, c.clientID, login.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified == true || claims.EmailVerified == "true",
+		Name:          claims.Name,
+	}, nil
+}
+
+func randomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
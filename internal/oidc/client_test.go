@@ -0,0 +1,109 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/oidc"
+	"github.com/Gkemhcs/taskpilot/internal/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// startLogin creates a client for provider and returns the auth URL together
+// with the login state the client stored for it.
+func startLogin(t *testing.T, provider *oidctest.Provider, store *oidc.MockStateStore) (*oidc.Client, string, oidc.LoginState) {
+	client, err := oidc.NewClient(context.TODO(), oidc.Config{
+		IssuerURL:    provider.Issuer(),
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		RedirectURL:  "http://localhost:8080/api/v1/users/oidc/callback",
+		Scopes:       []string{"email", "profile"},
+	}, store)
+	require.NoError(t, err)
+
+	var saved oidc.LoginState
+	store.On("Save", mock.Anything, mock.Anything, mock.Anything, 10*time.Minute).
+		Run(func(args mock.Arguments) { saved = args.Get(2).(oidc.LoginState) }).
+		Return(nil).Once()
+	authURL, err := client.AuthCodeURL(context.TODO())
+	require.NoError(t, err)
+	return client, authURL, saved
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	provider, err := oidctest.NewProvider("taskpilot", "s3cret")
+	require.NoError(t, err)
+	defer provider.Close()
+
+	t.Run("successful login", func(t *testing.T) {
+		store := new(oidc.MockStateStore)
+		client, authURL, saved := startLogin(t, provider, store)
+
+		parsed, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+		assert.Equal(t, saved.Nonce, parsed.Query().Get("nonce"))
+		assert.Contains(t, parsed.Query().Get("scope"), "openid")
+		assert.NotContains(t, authURL, saved.CodeVerifier, "the verifier never leaves the server")
+
+		state, code, err := provider.Authorize(authURL)
+		require.NoError(t, err)
+		store.On("Take", mock.Anything, state).Return(saved, nil).Once()
+
+		identity, err := client.Exchange(context.TODO(), state, code)
+		require.NoError(t, err)
+		assert.Equal(t, provider.Issuer(), identity.Issuer)
+		assert.Equal(t, provider.User.Subject, identity.Subject)
+		assert.Equal(t, provider.User.Email, identity.Email)
+		assert.True(t, identity.EmailVerified)
+	})
+
+	t.Run("wrong code verifier is rejected by the provider", func(t *testing.T) {
+		store := new(oidc.MockStateStore)
+		client, authURL, saved := startLogin(t, provider, store)
+		state, code, err := provider.Authorize(authURL)
+		require.NoError(t, err)
+
+		saved.CodeVerifier = "a-verifier-an-attacker-guessed-0123456789abcdef"
+		store.On("Take", mock.Anything, state).Return(saved, nil).Once()
+		_, err = client.Exchange(context.TODO(), state, code)
+		assert.ErrorIs(t, err, customErrors.ErrOIDCExchangeFailed)
+	})
+
+	t.Run("nonce mismatch is rejected", func(t *testing.T) {
+		store := new(oidc.MockStateStore)
+		client, authURL, saved := startLogin(t, provider, store)
+		state, code, err := provider.Authorize(authURL)
+		require.NoError(t, err)
+
+		saved.Nonce = "another-login"
+		store.On("Take", mock.Anything, state).Return(saved, nil).Once()
+		_, err = client.Exchange(context.TODO(), state, code)
+		assert.ErrorIs(t, err, customErrors.ErrOIDCInvalidIDToken)
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		store := new(oidc.MockStateStore)
+		client, _, _ := startLogin(t, provider, store)
+		store.On("Take", mock.Anything, "forged").Return(oidc.LoginState{}, customErrors.ErrOIDCInvalidState).Once()
+		_, err := client.Exchange(context.TODO(), "forged", "code")
+		assert.ErrorIs(t, err, customErrors.ErrOIDCInvalidState)
+	})
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	provider, err := oidctest.NewProvider("taskpilot", "s3cret")
+	require.NoError(t, err)
+	defer provider.Close()
+
+	_, err = oidc.NewClient(context.TODO(), oidc.Config{
+		IssuerURL: provider.Issuer() + "/",
+		ClientID:  provider.ClientID,
+	}, new(oidc.MockStateStore))
+	assert.Error(t, err)
+}
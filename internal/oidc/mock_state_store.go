@@ -0,0 +1,22 @@
+package oidc
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStateStore struct {
+	mock.Mock
+}
+
+func (m *MockStateStore) Save(ctx context.Context, state string, login LoginState, ttl time.Duration) error {
+	args := m.Called(ctx, state, login, ttl)
+	return args.Error(0)
+}
+
+func (m *MockStateStore) Take(ctx context.Context, state string) (LoginState, error) {
+	args := m.Called(ctx, state)
+	return args.Get(0).(LoginState), args.Error(1)
+}
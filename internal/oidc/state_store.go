@@ -0,0 +1,49 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const loginStateKeyPrefix = "oidc:state:"
+
+// NewRedisStateStore creates a StateStore that keeps pending logins in Redis,
+// so the callback may be served by any API instance.
+func NewRedisStateStore(client *redis.Client) *RedisStateStore {
+	return &RedisStateStore{
+		client: client,
+	}
+}
+
+// RedisStateStore is the Redis backed StateStore used by the API server.
+type RedisStateStore struct {
+	client *redis.Client
+}
+
+// Save stores the pending login under its state until ttl elapses.
+func (r *RedisStateStore) Save(ctx context.Context, state string, login LoginState, ttl time.Duration) error {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, loginStateKeyPrefix+state, data, ttl).Err()
+}
+
+// Take atomically reads and deletes the pending login.
+func (r *RedisStateStore) Take(ctx context.Context, state string) (LoginState, error) {
+	var login LoginState
+	data, err := r.client.GetDel(ctx, loginStateKeyPrefix+state).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return login, customErrors.ErrOIDCInvalidState
+	}
+	if err != nil {
+		return login, err
+	}
+	err = json.Unmarshal(data, &login)
+	return login, err
+}
@@ -0,0 +1,62 @@
+package oidc
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Config configures the OpenID Connect relying party.
+type Config struct {
+	IssuerURL    string // Provider issuer, the discovery document is read from <issuer>/.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string   // Optional for public clients, PKCE protects the code exchange either way
+	RedirectURL  string   // Must point at /api/v1/users/oidc/callback
+	Scopes       []string // "openid" is always requested
+}
+
+// Identity is the verified result of a login at the provider.
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// LoginState is what the server remembers between redirecting the browser to
+// the provider and handling the callback.
+type LoginState struct {
+	CodeVerifier string `json:"code_verifier"` // PKCE verifier, the provider only ever sees its S256 challenge
+	Nonce        string `json:"nonce"`         // Echoed in the ID token to bind it to this login
+}
+
+// StateStore keeps pending logins keyed by the OAuth state parameter.
+type StateStore interface {
+	// Save stores the login state for ttl.
+	Save(ctx context.Context, state string, login LoginState, ttl time.Duration) error
+	// Take returns and deletes the login state, so every state can be used once.
+	// It returns customErrors.ErrOIDCInvalidState if the state is unknown or expired.
+	Take(ctx context.Context, state string) (LoginState, error)
+}
+
+// discoveryDocument holds the fields of the provider's
+// /.well-known/openid-configuration that the login flow needs.
+type discoveryDocument struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+// idTokenClaims are the ID token claims used to build an Identity.
+type idTokenClaims struct {
+	Nonce           string `json:"nonce"`
+	Email           string `json:"email"`
+	EmailVerified   any    `json:"email_verified"` // Some providers send "true" as a string
+	Name            string `json:"name"`
+	AuthorizedParty string `json:"azp"`
+	jwt.RegisteredClaims
+}
@@ -0,0 +1,378 @@
+package organization
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/middleware"
+	"github.com/Gkemhcs/taskpilot/internal/user"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func NewOrganizationHandler(logger *logrus.Logger, organizationService *OrganizationService, userService user.UserResolver, jwtManager *auth.JWTManager) *OrganizationHandler {
+	return &OrganizationHandler{
+		logger:              logger,
+		organizationService: organizationService,
+		userService:         userService,
+		jwtManager:          jwtManager,
+	}
+}
+
+type OrganizationHandler struct {
+	logger              *logrus.Logger
+	organizationService *OrganizationService
+	userService         user.UserResolver
+	jwtManager          *auth.JWTManager
+}
+
+// RegisterOrganizationRoutes registers the organization endpoints. Membership
+// can only be managed from a login session, never with a personal access token.
+func RegisterOrganizationRoutes(r *gin.RouterGroup, handler *OrganizationHandler) {
+	orgGroup := r.Group("/orgs", middleware.JWTAuthMiddleware(handler.logger, handler.jwtManager), middleware.RequireSession())
+	{
+		orgGroup.POST("/", handler.CreateOrganization)
+		orgGroup.GET("/", handler.ListOrganizations)
+		orgGroup.DELETE("/:id", handler.DeleteOrganization)
+		orgGroup.POST("/:id/switch", handler.SwitchOrganization)
+		orgGroup.GET("/:id/members", handler.ListMembers)
+		orgGroup.POST("/:id/members", handler.AddMember)
+		orgGroup.PATCH("/:id/members/:userId", handler.UpdateMemberRole)
+		orgGroup.DELETE("/:id/members/:userId", handler.RemoveMember)
+	}
+}
+
+// CreateOrganization creates a shared organization owned by the caller.
+// @Summary      Create an organization
+// @Description  Creates a shared organization with the caller as owner
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CreateOrganizationRequest  true  "Organization name"
+// @Success      201      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Router       /api/v1/orgs/ [post]
+// @Security BearerAuth
+func (o *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var request CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	userID, ok := o.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	org, err := o.organizationService.CreateOrganization(ctx, userID, request.Name)
+	if err != nil {
+		o.logger.Errorf("unable to create organization %v", err)
+		utils.Error(c, organizationErrorStatus(err), err.Error())
+		return
+	}
+	o.logger.Infof("organization %d created by user %d", org.ID, userID)
+	utils.Success(c, http.StatusCreated, org)
+}
+
+// ListOrganizations lists the organizations of the caller.
+// @Summary      List my organizations
+// @Description  Lists the organizations the caller belongs to together with their role, starting with the personal workspace
+// @Tags         organizations
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Router       /api/v1/orgs/ [get]
+// @Security BearerAuth
+func (o *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	userID, ok := o.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	orgs, err := o.organizationService.ListOrganizations(ctx, userID)
+	if err != nil {
+		o.logger.Errorf("unable to list organizations of user %d %v", userID, err)
+		utils.Error(c, organizationErrorStatus(err), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, orgs)
+}
+
+// DeleteOrganization deletes an organization with all of its projects.
+// @Summary      Delete an organization
+// @Description  Deletes a shared organization together with its projects and tasks; owner only
+// @Tags         organizations
+// @Produce      json
+// @Param        id   path      int  true  "Organization ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/orgs/{id} [delete]
+// @Security BearerAuth
+func (o *OrganizationHandler) DeleteOrganization(c *gin.Context) {
+	orgID, ok := o.organizationID(c)
+	if !ok {
+		return
+	}
+	userID, ok := o.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := o.organizationService.DeleteOrganization(ctx, userID, orgID); err != nil {
+		o.logger.Errorf("unable to delete organization %d %v", orgID, err)
+		utils.Error(c, organizationErrorStatus(err), err.Error())
+		return
+	}
+	o.logger.Infof("organization %d deleted by user %d", orgID, userID)
+	utils.Success(c, http.StatusOK, map[string]interface{}{
+		"message": "organization deleted",
+	})
+}
+
+// SwitchOrganization issues tokens that act in the organization by default.
+// @Summary      Switch organization
+// @Description  Starts a new session whose tokens act in the organization without an X-Org-ID header
+// @Tags         organizations
+// @Produce      json
+// @Param        id   path      int  true  "Organization ID"
+// @Success      200  {object}  auth.GenerateJwtResponse
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/orgs/{id}/switch [post]
+// @Security BearerAuth
+func (o *OrganizationHandler) SwitchOrganization(c *gin.Context) {
+	orgID, ok := o.organizationID(c)
+	if !ok {
+		return
+	}
+	val, _ := c.Get("claims")
+	claims, ok := val.(*auth.UserClaims)
+	if !ok {
+		o.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user claims not found")
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if _, err := o.organizationService.ResolveOrganization(ctx, claims.UserID, orgID); err != nil {
+		o.logger.Errorf("user %d cannot switch to organization %d %v", claims.UserID, orgID, err)
+		utils.Error(c, organizationErrorStatus(err), err.Error())
+		return
+	}
+	tokens, err := o.jwtManager.GenerateForOrganization(ctx, claims.UserID, claims.Username, claims.Email, orgID)
+	if err != nil {
+		o.logger.Errorf("unable to generate tokens %v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, tokens)
+}
+
+// ListMembers lists the members of an organization.
+// @Summary      List organization members
+// @Description  Lists the members of an organization with their roles; any member may view them
+// @Tags         organizations
+// @Produce      json
+// @Param        id   path      int  true  "Organization ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/orgs/{id}/members [get]
+// @Security BearerAuth
+func (o *OrganizationHandler) ListMembers(c *gin.Context) {
+	orgID, ok := o.organizationID(c)
+	if !ok {
+		return
+	}
+	userID, ok := o.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	members, err := o.organizationService.ListMembers(ctx, userID, orgID)
+	if err != nil {
+		o.logger.Errorf("unable to list members of organization %d %v", orgID, err)
+		utils.Error(c, organizationErrorStatus(err), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, members)
+}
+
+// AddMember adds an existing user to an organization.
+// @Summary      Add organization member
+// @Description  Adds a user by email with the role owner, admin or member. Owners and admins manage members; only owners can add owners.
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                           true  "Organization ID"
+// @Param        request  body      AddOrganizationMemberRequest  true  "Member email and role"
+// @Success      201      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      403      {object}  utils.ErrorResponse
+// @Failure      404      {object}  utils.ErrorResponse
+// @Failure      409      {object}  utils.ErrorResponse
+// @Router       /api/v1/orgs/{id}/members [post]
+// @Security BearerAuth
+func (o *OrganizationHandler) AddMember(c *gin.Context) {
+	orgID, ok := o.organizationID(c)
+	if !ok {
+		return
+	}
+	var request AddOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	userID, ok := o.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	member, err := o.userService.GetUserByEmail(ctx, request.Email)
+	if err != nil {
+		o.logger.Errorf("unable to find user %s %v", request.Email, err)
+		utils.Error(c, http.StatusNotFound, customErrors.USER_NOT_FOUND.Error())
+		return
+	}
+	added, err := o.organizationService.AddMember(ctx, userID, orgID, int(member.ID), request.Role)
+	if err != nil {
+		o.logger.Errorf("unable to add member to organization %d %v", orgID, err)
+		utils.Error(c, organizationErrorStatus(err), err.Error())
+		return
+	}
+	o.logger.Infof("user %d added to organization %d by %d", member.ID, orgID, userID)
+	utils.Success(c, http.StatusCreated, added)
+}
+
+// UpdateMemberRole changes the role of an organization member.
+// @Summary      Update organization member role
+// @Description  Changes the role of a member. Only owners can grant or revoke the owner role and the last owner cannot be demoted.
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                              true  "Organization ID"
+// @Param        userId   path      int                              true  "Member user ID"
+// @Param        request  body      UpdateOrganizationMemberRequest  true  "New role"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      403      {object}  utils.ErrorResponse
+// @Failure      404      {object}  utils.ErrorResponse
+// @Failure      409      {object}  utils.ErrorResponse
+// @Router       /api/v1/orgs/{id}/members/{userId} [patch]
+// @Security BearerAuth
+func (o *OrganizationHandler) UpdateMemberRole(c *gin.Context) {
+	orgID, ok := o.organizationID(c)
+	if !ok {
+		return
+	}
+	memberID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidMemberID.Error())
+		return
+	}
+	var request UpdateOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	userID, ok := o.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := o.organizationService.UpdateMemberRole(ctx, userID, orgID, memberID, request.Role); err != nil {
+		o.logger.Errorf("unable to update member %d of organization %d %v", memberID, orgID, err)
+		utils.Error(c, organizationErrorStatus(err), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]interface{}{
+		"message": "member role updated",
+	})
+}
+
+// RemoveMember removes a member from an organization and all of its projects.
+// @Summary      Remove organization member
+// @Description  Removes a member from the organization and its projects. Members may remove themselves to leave.
+// @Tags         organizations
+// @Produce      json
+// @Param        id      path      int  true  "Organization ID"
+// @Param        userId  path      int  true  "Member user ID"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  utils.ErrorResponse
+// @Failure      403     {object}  utils.ErrorResponse
+// @Failure      404     {object}  utils.ErrorResponse
+// @Failure      409     {object}  utils.ErrorResponse
+// @Router       /api/v1/orgs/{id}/members/{userId} [delete]
+// @Security BearerAuth
+func (o *OrganizationHandler) RemoveMember(c *gin.Context) {
+	orgID, ok := o.organizationID(c)
+	if !ok {
+		return
+	}
+	memberID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidMemberID.Error())
+		return
+	}
+	userID, ok := o.userID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := o.organizationService.RemoveMember(ctx, userID, orgID, memberID); err != nil {
+		o.logger.Errorf("unable to remove member %d from organization %d %v", memberID, orgID, err)
+		utils.Error(c, organizationErrorStatus(err), err.Error())
+		return
+	}
+	o.logger.Infof("user %d removed from organization %d by %d", memberID, orgID, userID)
+	utils.Success(c, http.StatusOK, map[string]interface{}{
+		"message": "member removed",
+	})
+}
+
+func (o *OrganizationHandler) userID(c *gin.Context) (int, bool) {
+	val, exists := c.Get("userID")
+	if !exists {
+		o.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return 0, false
+	}
+	userID, ok := val.(int)
+	if !ok {
+		o.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return 0, false
+	}
+	return userID, true
+}
+
+func (o *OrganizationHandler) organizationID(c *gin.Context) (int, bool) {
+	orgID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || orgID <= 0 {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidOrganizationID.Error())
+		return 0, false
+	}
+	return orgID, true
+}
+
+// organizationErrorStatus maps validation errors to 400 and everything else through utils.ErrorStatus.
+func organizationErrorStatus(err error) int {
+	switch err {
+	case customErrors.ErrMissingOrganizationName, customErrors.ErrInvalidOrganizationRole, customErrors.ErrPersonalOrganization:
+		return http.StatusBadRequest
+	}
+	return utils.ErrorStatus(err, http.StatusInternalServerError)
+}
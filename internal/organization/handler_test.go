@@ -0,0 +1,135 @@
+package organization
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	"github.com/Gkemhcs/taskpilot/internal/middleware"
+	organizationdb "github.com/Gkemhcs/taskpilot/internal/organization/gen"
+	"github.com/Gkemhcs/taskpilot/internal/user"
+	userdb "github.com/Gkemhcs/taskpilot/internal/user/gen"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func SetupNewOrganizationHandler() (*gin.Engine, *MockOrganizationRepo, *user.MockUserRepo, *auth.JWTManager) {
+	jwtManager := auth.NewJWTManager(auth.CreateJwtManagerParams{
+		AccessTokenDuration:  10 * time.Minute,
+		RefreshTokenDuration: 10 * time.Hour,
+		AccessTokenKey:       "rnk3mkrk3rk3rk3",
+		RefreshTokenKey:      "21ieh12iei21eji12e",
+	})
+	repo := newOrganizationRepo()
+	userMockRepo := new(user.MockUserRepo)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	service := NewOrganizationService(repo)
+	handler := NewOrganizationHandler(logger, service, user.NewUserService(userMockRepo), jwtManager)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	v1 := r.Group("/api/v1")
+	RegisterOrganizationRoutes(v1, handler)
+	// a route behind RequireOrganization that echoes the organization it acts in
+	v1.GET("/whoami", middleware.JWTAuthMiddleware(logger, jwtManager), middleware.RequireOrganization(logger, service), func(c *gin.Context) {
+		orgID, _ := middleware.OrganizationID(c)
+		c.JSON(http.StatusOK, gin.H{"org_id": orgID})
+	})
+	return r, repo, userMockRepo, jwtManager
+}
+
+func TestOrganizationHandlers(t *testing.T) {
+	r, repo, userMockRepo, jwtManager := SetupNewOrganizationHandler()
+	owner, err := jwtManager.GenerateAccessToken(101, "owner", "owner@taskpilot.dev")
+	require.NoError(t, err)
+	outsider, err := jwtManager.GenerateAccessToken(404, "outsider", "outsider@taskpilot.dev")
+	require.NoError(t, err)
+
+	repo.On("EnsurePersonalOrganization", mock.Anything, int32(101)).Return(organizationdb.Organization{ID: 1}, nil)
+	repo.On("EnsureOrganizationMember", mock.Anything, mock.Anything).Return(nil)
+	repo.On("ListOrganizationsByUser", mock.Anything, int32(101)).Return([]organizationdb.ListOrganizationsByUserRow{
+		{ID: 1, Name: "personal", PersonalFor: sql.NullInt32{Int32: 101, Valid: true}, Role: organizationdb.OrganizationRoleOWNER},
+		{ID: 7, Name: "acme", Role: organizationdb.OrganizationRoleOWNER},
+	}, nil)
+	userMockRepo.On("GetUserByEmail", mock.Anything, "dev@taskpilot.dev").Return(userdb.User{ID: 505, Email: "dev@taskpilot.dev"}, nil)
+	userMockRepo.On("GetUserByEmail", mock.Anything, mock.Anything).Return(userdb.User{}, sql.ErrNoRows)
+	repo.On("AddOrganizationMember", mock.Anything, organizationdb.AddOrganizationMemberParams{OrganizationID: 7, UserID: 505, Role: organizationdb.OrganizationRoleADMIN}).
+		Return(organizationdb.OrganizationMember{OrganizationID: 7, UserID: 505, Role: organizationdb.OrganizationRoleADMIN}, nil)
+
+	testCases := []struct {
+		testName           string
+		method             string
+		path               string
+		token              string
+		orgHeader          string
+		body               any
+		expectedStatusCode int
+	}{
+		{testName: "list my organizations", method: http.MethodGet, path: "/api/v1/orgs/", token: owner, expectedStatusCode: http.StatusOK},
+		{testName: "create without a name", method: http.MethodPost, path: "/api/v1/orgs/", token: owner, body: map[string]string{}, expectedStatusCode: http.StatusBadRequest},
+		{testName: "add a member by email", method: http.MethodPost, path: "/api/v1/orgs/7/members", token: owner, body: AddOrganizationMemberRequest{Email: "dev@taskpilot.dev", Role: "admin"}, expectedStatusCode: http.StatusCreated},
+		{testName: "add an unknown user", method: http.MethodPost, path: "/api/v1/orgs/7/members", token: owner, body: AddOrganizationMemberRequest{Email: "ghost@taskpilot.dev", Role: "admin"}, expectedStatusCode: http.StatusNotFound},
+		{testName: "invalid member id", method: http.MethodDelete, path: "/api/v1/orgs/7/members/abc", token: owner, expectedStatusCode: http.StatusBadRequest},
+		{testName: "outsider cannot list members", method: http.MethodGet, path: "/api/v1/orgs/7/members", token: outsider, expectedStatusCode: http.StatusNotFound},
+		{testName: "personal workspace cannot be deleted", method: http.MethodDelete, path: "/api/v1/orgs/1", token: owner, expectedStatusCode: http.StatusBadRequest},
+		{testName: "outsider cannot switch", method: http.MethodPost, path: "/api/v1/orgs/7/switch", token: outsider, expectedStatusCode: http.StatusNotFound},
+		{testName: "requests act in the personal workspace by default", method: http.MethodGet, path: "/api/v1/whoami", token: owner, expectedStatusCode: http.StatusOK},
+		{testName: "X-Org-ID selects a shared organization", method: http.MethodGet, path: "/api/v1/whoami", token: owner, orgHeader: "7", expectedStatusCode: http.StatusOK},
+		{testName: "X-Org-ID of another organization", method: http.MethodGet, path: "/api/v1/whoami", token: outsider, orgHeader: "7", expectedStatusCode: http.StatusNotFound},
+		{testName: "invalid X-Org-ID", method: http.MethodGet, path: "/api/v1/whoami", token: owner, orgHeader: "acme", expectedStatusCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			var body io.Reader
+			if tc.body != nil {
+				payload, _ := json.Marshal(tc.body)
+				body = bytes.NewReader(payload)
+			}
+			req, _ := http.NewRequest(tc.method, tc.path, body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			if tc.orgHeader != "" {
+				req.Header.Set(middleware.OrganizationHeader, tc.orgHeader)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code, w.Body.String())
+		})
+	}
+
+	t.Run("switching issues tokens for the organization", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/orgs/7/switch", nil)
+		req.Header.Set("Authorization", "Bearer "+owner)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data auth.GenerateJwtResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		claims, err := jwtManager.Verify(response.Data.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, 7, claims.OrgID)
+
+		// the switched token acts in the organization without a header
+		req, _ = http.NewRequest(http.MethodGet, "/api/v1/whoami", nil)
+		req.Header.Set("Authorization", "Bearer "+response.Data.AccessToken)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.JSONEq(t, `{"org_id":7}`, w.Body.String())
+	})
+}
@@ -0,0 +1,78 @@
+package organization
+
+import (
+	"context"
+
+	organizationdb "github.com/Gkemhcs/taskpilot/internal/organization/gen"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOrganizationRepo is a mock implementation of the organizationdb.Querier interface
+type MockOrganizationRepo struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationRepo) AddOrganizationMember(ctx context.Context, arg organizationdb.AddOrganizationMemberParams) (organizationdb.OrganizationMember, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(organizationdb.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) CountOrganizationOwners(ctx context.Context, organizationID int64) (int64, error) {
+	args := m.Called(ctx, organizationID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) CreateOrganization(ctx context.Context, arg organizationdb.CreateOrganizationParams) (organizationdb.Organization, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(organizationdb.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) DeleteOrganization(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepo) EnsureOrganizationMember(ctx context.Context, arg organizationdb.EnsureOrganizationMemberParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepo) EnsurePersonalOrganization(ctx context.Context, id int32) (organizationdb.Organization, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(organizationdb.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) GetOrganizationById(ctx context.Context, id int64) (organizationdb.Organization, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(organizationdb.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) GetOrganizationMember(ctx context.Context, arg organizationdb.GetOrganizationMemberParams) (organizationdb.OrganizationMember, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(organizationdb.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) ListOrganizationMembers(ctx context.Context, organizationID int64) ([]organizationdb.ListOrganizationMembersRow, error) {
+	args := m.Called(ctx, organizationID)
+	return args.Get(0).([]organizationdb.ListOrganizationMembersRow), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) ListOrganizationsByUser(ctx context.Context, userID int32) ([]organizationdb.ListOrganizationsByUserRow, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]organizationdb.ListOrganizationsByUserRow), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) RemoveMemberFromOrganizationProjects(ctx context.Context, arg organizationdb.RemoveMemberFromOrganizationProjectsParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepo) RemoveOrganizationMember(ctx context.Context, arg organizationdb.RemoveOrganizationMemberParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrganizationRepo) UpdateOrganizationMemberRole(ctx context.Context, arg organizationdb.UpdateOrganizationMemberRoleParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,54 @@
+-- name: CreateOrganization :one
+INSERT INTO organizations (name, created_by) VALUES ($1, $2) RETURNING *;
+
+-- name: EnsurePersonalOrganization :one
+INSERT INTO organizations (name, personal_for, created_by)
+SELECT u.name || '''s workspace', u.id, u.id FROM users u WHERE u.id = $1
+ON CONFLICT (personal_for) DO UPDATE SET personal_for = EXCLUDED.personal_for
+RETURNING *;
+
+-- name: GetOrganizationById :one
+SELECT * FROM organizations WHERE id = $1;
+
+-- name: DeleteOrganization :exec
+DELETE FROM organizations WHERE id = $1;
+
+-- name: ListOrganizationsByUser :many
+SELECT o.id, o.name, o.personal_for, om.role, o.created_at
+FROM organizations o
+JOIN organization_members om ON om.organization_id = o.id
+WHERE om.user_id = $1
+ORDER BY o.personal_for IS NULL, o.name, o.id;
+
+-- name: AddOrganizationMember :one
+INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3) RETURNING *;
+
+-- name: EnsureOrganizationMember :exec
+INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: GetOrganizationMember :one
+SELECT * FROM organization_members WHERE organization_id = $1 AND user_id = $2;
+
+-- name: ListOrganizationMembers :many
+SELECT om.organization_id, om.user_id, om.role, om.created_at, u.name, u.email
+FROM organization_members om
+JOIN users u ON u.id = om.user_id
+WHERE om.organization_id = $1
+ORDER BY om.created_at, om.user_id;
+
+-- name: CountOrganizationOwners :one
+SELECT count(*) FROM organization_members WHERE organization_id = $1 AND role = 'OWNER';
+
+-- name: UpdateOrganizationMemberRole :execrows
+UPDATE organization_members
+SET role = $3, updated_at = now()
+WHERE organization_id = $1 AND user_id = $2;
+
+-- name: RemoveOrganizationMember :execrows
+DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2;
+
+-- name: RemoveMemberFromOrganizationProjects :exec
+DELETE FROM project_members
+WHERE user_id = $2
+  AND project_id IN (SELECT id FROM projects WHERE organization_id = $1);
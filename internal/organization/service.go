@@ -0,0 +1,301 @@
+// Package organization groups users and projects into workspaces. Every user
+// has a personal workspace and may create or join shared ones; projects,
+// tasks and import/export jobs always belong to exactly one organization.
+package organization
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	organizationdb "github.com/Gkemhcs/taskpilot/internal/organization/gen"
+	"github.com/lib/pq"
+)
+
+func NewOrganizationService(repo organizationdb.Querier) *OrganizationService {
+	return &OrganizationService{
+		repo: repo,
+	}
+}
+
+// OrganizationService manages organizations and their members. Owners and
+// admins manage members; only owners may grant or revoke the owner role or
+// delete the organization.
+type OrganizationService struct {
+	repo organizationdb.Querier
+}
+
+// CreateOrganization creates a shared organization with userID as its owner.
+func (o *OrganizationService) CreateOrganization(ctx context.Context, userID int, name string) (*organizationdb.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, customErrors.ErrMissingOrganizationName
+	}
+	org, err := o.repo.CreateOrganization(ctx, organizationdb.CreateOrganizationParams{
+		Name:      name,
+		CreatedBy: sql.NullInt32{Int32: int32(userID), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = o.repo.AddOrganizationMember(ctx, organizationdb.AddOrganizationMemberParams{
+		OrganizationID: org.ID,
+		UserID:         int32(userID),
+		Role:           organizationdb.OrganizationRoleOWNER,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// ListOrganizations returns the organizations userID belongs to, personal workspace first.
+func (o *OrganizationService) ListOrganizations(ctx context.Context, userID int) ([]organizationdb.ListOrganizationsByUserRow, error) {
+	if _, err := o.personalOrganization(ctx, userID); err != nil {
+		return nil, err
+	}
+	return o.repo.ListOrganizationsByUser(ctx, int32(userID))
+}
+
+// ResolveOrganization implements middleware.OrganizationResolver.
+func (o *OrganizationService) ResolveOrganization(ctx context.Context, userID int, requested int) (int, error) {
+	if requested == 0 {
+		return o.personalOrganization(ctx, userID)
+	}
+	if _, err := o.memberRole(ctx, requested, userID); err != nil {
+		return 0, err
+	}
+	return requested, nil
+}
+
+// DeleteOrganization deletes a shared organization together with its projects.
+func (o *OrganizationService) DeleteOrganization(ctx context.Context, userID int, orgID int) error {
+	role, err := o.memberRole(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if role != organizationdb.OrganizationRoleOWNER {
+		return customErrors.ErrForbidden
+	}
+	org, err := o.repo.GetOrganizationById(ctx, int64(orgID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return customErrors.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if org.PersonalFor.Valid {
+		return customErrors.ErrPersonalOrganization
+	}
+	return o.repo.DeleteOrganization(ctx, int64(orgID))
+}
+
+// ListMembers returns the members of the organization; any member may view the list.
+func (o *OrganizationService) ListMembers(ctx context.Context, userID int, orgID int) ([]organizationdb.ListOrganizationMembersRow, error) {
+	if _, err := o.memberRole(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return o.repo.ListOrganizationMembers(ctx, int64(orgID))
+}
+
+// AddMember adds memberID to the organization with the given role.
+func (o *OrganizationService) AddMember(ctx context.Context, userID int, orgID int, memberID int, role string) (*organizationdb.OrganizationMember, error) {
+	orgRole, err := mapRole(role)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.authorizeMemberChange(ctx, userID, orgID, orgRole); err != nil {
+		return nil, err
+	}
+	member, err := o.repo.AddOrganizationMember(ctx, organizationdb.AddOrganizationMemberParams{
+		OrganizationID: int64(orgID),
+		UserID:         int32(memberID),
+		Role:           orgRole,
+	})
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+		return nil, customErrors.ErrOrganizationMemberAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// UpdateMemberRole changes the role of memberID. The last owner cannot be demoted.
+func (o *OrganizationService) UpdateMemberRole(ctx context.Context, userID int, orgID int, memberID int, role string) error {
+	orgRole, err := mapRole(role)
+	if err != nil {
+		return err
+	}
+	if err := o.authorizeMemberChange(ctx, userID, orgID, orgRole); err != nil {
+		return err
+	}
+	current, err := o.memberRole(ctx, orgID, memberID)
+	if errors.Is(err, customErrors.ErrOrganizationNotFound) {
+		return customErrors.ErrOrganizationMemberNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if current == organizationdb.OrganizationRoleOWNER && orgRole != organizationdb.OrganizationRoleOWNER {
+		if err := o.authorizeMemberChange(ctx, userID, orgID, current); err != nil {
+			return err
+		}
+		if err := o.ensureAnotherOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+	rows, err := o.repo.UpdateOrganizationMemberRole(ctx, organizationdb.UpdateOrganizationMemberRoleParams{
+		OrganizationID: int64(orgID),
+		UserID:         int32(memberID),
+		Role:           orgRole,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrOrganizationMemberNotFound
+	}
+	return nil
+}
+
+// RemoveMember removes memberID from the organization and from all of its
+// projects. Members may always remove themselves, except from their personal
+// workspace, and the last owner cannot leave.
+func (o *OrganizationService) RemoveMember(ctx context.Context, userID int, orgID int, memberID int) error {
+	current, err := o.memberRole(ctx, orgID, memberID)
+	if errors.Is(err, customErrors.ErrOrganizationNotFound) {
+		// hide whether the organization exists from non-members
+		if _, err := o.memberRole(ctx, orgID, userID); err != nil {
+			return err
+		}
+		return customErrors.ErrOrganizationMemberNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if memberID != userID {
+		if err := o.authorizeMemberChange(ctx, userID, orgID, current); err != nil {
+			return err
+		}
+	}
+	org, err := o.repo.GetOrganizationById(ctx, int64(orgID))
+	if err != nil {
+		return err
+	}
+	if org.PersonalFor.Valid && int(org.PersonalFor.Int32) == memberID {
+		return customErrors.ErrPersonalOrganization
+	}
+	if current == organizationdb.OrganizationRoleOWNER {
+		if err := o.ensureAnotherOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	err = o.repo.RemoveMemberFromOrganizationProjects(ctx, organizationdb.RemoveMemberFromOrganizationProjectsParams{
+		OrganizationID: int64(orgID),
+		UserID:         int32(memberID),
+	})
+	if err != nil {
+		return err
+	}
+	rows, err := o.repo.RemoveOrganizationMember(ctx, organizationdb.RemoveOrganizationMemberParams{
+		OrganizationID: int64(orgID),
+		UserID:         int32(memberID),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrOrganizationMemberNotFound
+	}
+	return nil
+}
+
+// personalOrganization returns the personal workspace of userID, creating it
+// on first use for users that signed up after organizations were introduced.
+func (o *OrganizationService) personalOrganization(ctx context.Context, userID int) (int, error) {
+	org, err := o.repo.EnsurePersonalOrganization(ctx, int32(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, customErrors.USER_NOT_FOUND
+	}
+	if err != nil {
+		return 0, err
+	}
+	err = o.repo.EnsureOrganizationMember(ctx, organizationdb.EnsureOrganizationMemberParams{
+		OrganizationID: org.ID,
+		UserID:         int32(userID),
+		Role:           organizationdb.OrganizationRoleOWNER,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(org.ID), nil
+}
+
+// memberRole returns the role of userID in the organization, or
+// ErrOrganizationNotFound when the user is not a member.
+func (o *OrganizationService) memberRole(ctx context.Context, orgID int, userID int) (organizationdb.OrganizationRole, error) {
+	member, err := o.repo.GetOrganizationMember(ctx, organizationdb.GetOrganizationMemberParams{
+		OrganizationID: int64(orgID),
+		UserID:         int32(userID),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", customErrors.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// authorizeMemberChange checks that userID may grant or revoke role in the organization.
+func (o *OrganizationService) authorizeMemberChange(ctx context.Context, userID int, orgID int, role organizationdb.OrganizationRole) error {
+	callerRole, err := o.memberRole(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case callerRole == organizationdb.OrganizationRoleOWNER:
+		return nil
+	case callerRole == organizationdb.OrganizationRoleADMIN && role != organizationdb.OrganizationRoleOWNER:
+		return nil
+	default:
+		return customErrors.ErrForbidden
+	}
+}
+
+// ensureAnotherOwner rejects changes that would leave the organization without an owner.
+func (o *OrganizationService) ensureAnotherOwner(ctx context.Context, orgID int) error {
+	owners, err := o.repo.CountOrganizationOwners(ctx, int64(orgID))
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return customErrors.ErrLastOrganizationOwner
+	}
+	return nil
+}
+
+// mapRole converts a role from the API (owner, admin, member) into its database value.
+func mapRole(role string) (organizationdb.OrganizationRole, error) {
+	switch strings.ToLower(role) {
+	case "owner":
+		return organizationdb.OrganizationRoleOWNER, nil
+	case "admin":
+		return organizationdb.OrganizationRoleADMIN, nil
+	case "member":
+		return organizationdb.OrganizationRoleMEMBER, nil
+	default:
+		return "", customErrors.ErrInvalidOrganizationRole
+	}
+}
+
+func IsErrorCode(err error, errcode pq.ErrorCode) bool {
+	if pgerr, ok := err.(*pq.Error); ok {
+		return pgerr.Code == errcode
+	}
+	return false
+}
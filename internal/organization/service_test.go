@@ -0,0 +1,199 @@
+package organization
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	organizationdb "github.com/Gkemhcs/taskpilot/internal/organization/gen"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newOrganizationRepo returns a repo where organization 7 is shared with owner
+// 101, admin 202 and member 303, and organization 1 is the personal workspace of 101.
+func newOrganizationRepo() *MockOrganizationRepo {
+	repo := new(MockOrganizationRepo)
+	for userID, role := range map[int32]organizationdb.OrganizationRole{
+		101: organizationdb.OrganizationRoleOWNER,
+		202: organizationdb.OrganizationRoleADMIN,
+		303: organizationdb.OrganizationRoleMEMBER,
+	} {
+		repo.On("GetOrganizationMember", mock.Anything, organizationdb.GetOrganizationMemberParams{OrganizationID: 7, UserID: userID}).
+			Return(organizationdb.OrganizationMember{OrganizationID: 7, UserID: userID, Role: role}, nil)
+	}
+	repo.On("GetOrganizationMember", mock.Anything, organizationdb.GetOrganizationMemberParams{OrganizationID: 1, UserID: 101}).
+		Return(organizationdb.OrganizationMember{OrganizationID: 1, UserID: 101, Role: organizationdb.OrganizationRoleOWNER}, nil)
+	repo.On("GetOrganizationMember", mock.Anything, mock.Anything).Return(organizationdb.OrganizationMember{}, sql.ErrNoRows)
+	repo.On("GetOrganizationById", mock.Anything, int64(7)).Return(organizationdb.Organization{ID: 7, Name: "acme"}, nil)
+	repo.On("GetOrganizationById", mock.Anything, int64(1)).
+		Return(organizationdb.Organization{ID: 1, Name: "personal", PersonalFor: sql.NullInt32{Int32: 101, Valid: true}}, nil)
+	return repo
+}
+
+func TestCreateOrganization(t *testing.T) {
+	repo := newOrganizationRepo()
+	service := NewOrganizationService(repo)
+
+	t.Run("creator becomes owner", func(t *testing.T) {
+		repo.On("CreateOrganization", mock.Anything, organizationdb.CreateOrganizationParams{
+			Name:      "acme",
+			CreatedBy: sql.NullInt32{Int32: 101, Valid: true},
+		}).Return(organizationdb.Organization{ID: 7, Name: "acme"}, nil).Once()
+		repo.On("AddOrganizationMember", mock.Anything, organizationdb.AddOrganizationMemberParams{
+			OrganizationID: 7,
+			UserID:         101,
+			Role:           organizationdb.OrganizationRoleOWNER,
+		}).Return(organizationdb.OrganizationMember{}, nil).Once()
+
+		org, err := service.CreateOrganization(context.TODO(), 101, "  acme ")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), org.ID)
+	})
+
+	t.Run("name is required", func(t *testing.T) {
+		_, err := service.CreateOrganization(context.TODO(), 101, "   ")
+		assert.Equal(t, customErrors.ErrMissingOrganizationName, err)
+	})
+}
+
+func TestResolveOrganization(t *testing.T) {
+	repo := newOrganizationRepo()
+	service := NewOrganizationService(repo)
+	repo.On("EnsurePersonalOrganization", mock.Anything, int32(101)).Return(organizationdb.Organization{ID: 1}, nil)
+	repo.On("EnsureOrganizationMember", mock.Anything, organizationdb.EnsureOrganizationMemberParams{
+		OrganizationID: 1,
+		UserID:         101,
+		Role:           organizationdb.OrganizationRoleOWNER,
+	}).Return(nil)
+
+	testCases := []struct {
+		name          string
+		userID        int
+		requested     int
+		expectedOrgID int
+		expectedError error
+	}{
+		{name: "no organization selects the personal workspace", userID: 101, requested: 0, expectedOrgID: 1},
+		{name: "member selects a shared organization", userID: 303, requested: 7, expectedOrgID: 7},
+		{name: "non member cannot select an organization", userID: 404, requested: 7, expectedError: customErrors.ErrOrganizationNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orgID, err := service.ResolveOrganization(context.TODO(), tc.userID, tc.requested)
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedOrgID, orgID)
+		})
+	}
+}
+
+func TestOrganizationMembers(t *testing.T) {
+	repo := newOrganizationRepo()
+	service := NewOrganizationService(repo)
+
+	t.Run("admin adds a member", func(t *testing.T) {
+		params := organizationdb.AddOrganizationMemberParams{OrganizationID: 7, UserID: 404, Role: organizationdb.OrganizationRoleMEMBER}
+		repo.On("AddOrganizationMember", mock.Anything, params).Return(organizationdb.OrganizationMember{OrganizationID: 7, UserID: 404, Role: organizationdb.OrganizationRoleMEMBER}, nil).Once()
+
+		member, err := service.AddMember(context.TODO(), 202, 7, 404, "Member")
+		assert.NoError(t, err)
+		assert.Equal(t, organizationdb.OrganizationRoleMEMBER, member.Role)
+	})
+
+	t.Run("adding an existing member is rejected", func(t *testing.T) {
+		params := organizationdb.AddOrganizationMemberParams{OrganizationID: 7, UserID: 303, Role: organizationdb.OrganizationRoleMEMBER}
+		repo.On("AddOrganizationMember", mock.Anything, params).Return(organizationdb.OrganizationMember{}, &pq.Error{Code: "23505"}).Once()
+
+		_, err := service.AddMember(context.TODO(), 101, 7, 303, "member")
+		assert.Equal(t, customErrors.ErrOrganizationMemberAlreadyExists, err)
+	})
+
+	t.Run("only owners grant the owner role", func(t *testing.T) {
+		_, err := service.AddMember(context.TODO(), 202, 7, 404, "owner")
+		assert.Equal(t, customErrors.ErrForbidden, err)
+		err = service.UpdateMemberRole(context.TODO(), 202, 7, 303, "owner")
+		assert.Equal(t, customErrors.ErrForbidden, err)
+	})
+
+	t.Run("members cannot manage members", func(t *testing.T) {
+		_, err := service.AddMember(context.TODO(), 303, 7, 404, "member")
+		assert.Equal(t, customErrors.ErrForbidden, err)
+	})
+
+	t.Run("unknown role is rejected", func(t *testing.T) {
+		_, err := service.AddMember(context.TODO(), 101, 7, 404, "editor")
+		assert.Equal(t, customErrors.ErrInvalidOrganizationRole, err)
+	})
+
+	t.Run("last owner cannot be demoted", func(t *testing.T) {
+		repo.On("CountOrganizationOwners", mock.Anything, int64(7)).Return(int64(1), nil).Once()
+
+		err := service.UpdateMemberRole(context.TODO(), 101, 7, 101, "admin")
+		assert.Equal(t, customErrors.ErrLastOrganizationOwner, err)
+		repo.AssertNotCalled(t, "UpdateOrganizationMemberRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("owner promotes an admin", func(t *testing.T) {
+		params := organizationdb.UpdateOrganizationMemberRoleParams{OrganizationID: 7, UserID: 202, Role: organizationdb.OrganizationRoleOWNER}
+		repo.On("UpdateOrganizationMemberRole", mock.Anything, params).Return(int64(1), nil).Once()
+
+		err := service.UpdateMemberRole(context.TODO(), 101, 7, 202, "owner")
+		assert.NoError(t, err)
+	})
+
+	t.Run("removing a member also removes their project access", func(t *testing.T) {
+		repo.On("RemoveMemberFromOrganizationProjects", mock.Anything, organizationdb.RemoveMemberFromOrganizationProjectsParams{OrganizationID: 7, UserID: 303}).Return(nil).Once()
+		repo.On("RemoveOrganizationMember", mock.Anything, organizationdb.RemoveOrganizationMemberParams{OrganizationID: 7, UserID: 303}).Return(int64(1), nil).Once()
+
+		err := service.RemoveMember(context.TODO(), 202, 7, 303)
+		assert.NoError(t, err)
+		repo.AssertCalled(t, "RemoveMemberFromOrganizationProjects", mock.Anything, organizationdb.RemoveMemberFromOrganizationProjectsParams{OrganizationID: 7, UserID: 303})
+	})
+
+	t.Run("admin cannot remove an owner", func(t *testing.T) {
+		err := service.RemoveMember(context.TODO(), 202, 7, 101)
+		assert.Equal(t, customErrors.ErrForbidden, err)
+	})
+
+	t.Run("removing a non member reports not found", func(t *testing.T) {
+		err := service.RemoveMember(context.TODO(), 101, 7, 404)
+		assert.Equal(t, customErrors.ErrOrganizationMemberNotFound, err)
+	})
+
+	t.Run("outsiders cannot see the organization", func(t *testing.T) {
+		err := service.RemoveMember(context.TODO(), 404, 7, 505)
+		assert.Equal(t, customErrors.ErrOrganizationNotFound, err)
+		_, err = service.ListMembers(context.TODO(), 404, 7)
+		assert.Equal(t, customErrors.ErrOrganizationNotFound, err)
+	})
+
+	t.Run("personal workspace cannot be left", func(t *testing.T) {
+		err := service.RemoveMember(context.TODO(), 101, 1, 101)
+		assert.Equal(t, customErrors.ErrPersonalOrganization, err)
+	})
+}
+
+func TestDeleteOrganization(t *testing.T) {
+	repo := newOrganizationRepo()
+	service := NewOrganizationService(repo)
+
+	t.Run("admin cannot delete", func(t *testing.T) {
+		err := service.DeleteOrganization(context.TODO(), 202, 7)
+		assert.Equal(t, customErrors.ErrForbidden, err)
+	})
+
+	t.Run("personal workspace cannot be deleted", func(t *testing.T) {
+		err := service.DeleteOrganization(context.TODO(), 101, 1)
+		assert.Equal(t, customErrors.ErrPersonalOrganization, err)
+	})
+
+	t.Run("owner deletes", func(t *testing.T) {
+		repo.On("DeleteOrganization", mock.Anything, int64(7)).Return(nil).Once()
+
+		err := service.DeleteOrganization(context.TODO(), 101, 7)
+		assert.NoError(t, err)
+		repo.AssertCalled(t, "DeleteOrganization", mock.Anything, int64(7))
+	})
+}
@@ -0,0 +1,17 @@
+package organization
+
+// CreateOrganizationRequest is the request body for creating an organization.
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required"` // Display name, unique names are not required
+}
+
+// AddOrganizationMemberRequest invites an existing user to an organization.
+type AddOrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required"` // Email of the user to invite
+	Role  string `json:"role" binding:"required"`  // One of owner, admin, member
+}
+
+// UpdateOrganizationMemberRequest changes the role of an organization member.
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role" binding:"required"` // One of owner, admin, member
+}
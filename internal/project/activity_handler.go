@@ -0,0 +1,51 @@
+package project
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetProjectActivity lists the changes to a project's tasks.
+// @Summary      Get project activity
+// @Description  Lists a page of the field-level changes to the project's tasks, newest first, including tasks that were deleted since
+// @Tags         projects
+// @Produce      json
+// @Param        id      path      int  true   "Project ID"
+// @Param        limit   query     int  false  "Changes per page (default 20, max 100)"
+// @Param        offset  query     int  false  "Number of changes to skip"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/activity [get]
+// @Security BearerAuth
+func (p *ProjectHandler) GetProjectActivity(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	var page types.Page
+	if err := c.ShouldBindQuery(&page); err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidPagination.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	activity, err := p.taskQueryService.ProjectActivity(ctx, userID, orgID, projectID, page)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data":    activity,
+		"message": "request succeeded successfully",
+	})
+}
@@ -0,0 +1,171 @@
+package project
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ListCustomFields lists the custom fields of a project.
+// @Summary      List project custom fields
+// @Description  Lists the custom fields defined on a project in the order they were created
+// @Tags         projects
+// @Produce      json
+// @Param        id   path      int  true  "Project ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/custom-fields [get]
+// @Security BearerAuth
+func (p *ProjectHandler) ListCustomFields(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	fields, err := p.projectService.ListCustomFields(ctx, userID, orgID, projectID)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data":    fields,
+		"message": "request succeeded successfully",
+	})
+}
+
+// CreateCustomField defines a new custom field on a project.
+// @Summary      Create project custom field
+// @Description  Defines a typed custom field (text, number, date, single_select, multi_select or user) whose name is unique in the project regardless of case. Select fields need their options. Only project owners may manage custom fields.
+// @Tags         projects
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                       true  "Project ID"
+// @Param        field  body      CreateCustomFieldRequest  true  "Custom field"
+// @Success      201    {object}  map[string]interface{}
+// @Failure      400    {object}  map[string]interface{}
+// @Failure      403    {object}  map[string]interface{}
+// @Failure      404    {object}  map[string]interface{}
+// @Failure      409    {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/custom-fields [post]
+// @Security BearerAuth
+func (p *ProjectHandler) CreateCustomField(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	var req CreateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	field, err := p.projectService.CreateCustomField(ctx, userID, orgID, projectID, req)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusCreated, map[string]any{
+		"data":    field,
+		"message": "custom field created successfully",
+	})
+}
+
+// UpdateCustomField renames a custom field or replaces its options.
+// @Summary      Update project custom field
+// @Description  Renames a custom field or replaces the options of a select field. Task values that are no longer among the options are removed.
+// @Tags         projects
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                       true  "Project ID"
+// @Param        fieldId  path      int                       true  "Custom field ID"
+// @Param        field    body      UpdateCustomFieldRequest  true  "Changes"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/custom-fields/{fieldId} [patch]
+// @Security BearerAuth
+func (p *ProjectHandler) UpdateCustomField(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	fieldID, ok := customFieldIDParam(c)
+	if !ok {
+		return
+	}
+	var req UpdateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	field, err := p.projectService.UpdateCustomField(ctx, userID, orgID, projectID, fieldID, req)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data":    field,
+		"message": "custom field updated successfully",
+	})
+}
+
+// DeleteCustomField deletes a project custom field.
+// @Summary      Delete project custom field
+// @Description  Deletes a custom field and its values on every task of the project
+// @Tags         projects
+// @Produce      json
+// @Param        id       path      int  true  "Project ID"
+// @Param        fieldId  path      int  true  "Custom field ID"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/custom-fields/{fieldId} [delete]
+// @Security BearerAuth
+func (p *ProjectHandler) DeleteCustomField(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	fieldID, ok := customFieldIDParam(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := p.projectService.DeleteCustomField(ctx, userID, orgID, projectID, fieldID); err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "custom field deleted successfully",
+	})
+}
+
+func customFieldIDParam(c *gin.Context) (int64, bool) {
+	fieldID, err := strconv.ParseInt(c.Param("fieldId"), 10, 64)
+	if err != nil || fieldID <= 0 {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidCustomFieldID.Error())
+		return 0, false
+	}
+	return fieldID, true
+}
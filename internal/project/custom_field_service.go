@@ -0,0 +1,172 @@
+package project
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	projectdb "github.com/Gkemhcs/taskpilot/internal/project/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+const (
+	// maxCustomFieldNameLength matches the custom_fields.name column.
+	maxCustomFieldNameLength = 50
+	maxCustomFieldOptions    = 100
+	maxCustomFieldOptionLen  = 100
+)
+
+// CreateCustomField adds a typed custom field to the project. Only project
+// owners may define fields.
+func (p *ProjectService) CreateCustomField(ctx context.Context, userID int, orgID int, projectID int, req CreateCustomFieldRequest) (*projectdb.CustomField, error) {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionManage); err != nil {
+		return nil, err
+	}
+	name, err := customFieldName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	fieldType := projectdb.CustomFieldType(strings.ToLower(strings.TrimSpace(req.Type)))
+	if !validCustomFieldType(fieldType) {
+		return nil, customErrors.ErrInvalidCustomFieldType
+	}
+	options, err := customFieldOptions(fieldType, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	field, err := p.projectRepository.CreateCustomField(ctx, projectdb.CreateCustomFieldParams{
+		ProjectID: int64(projectID),
+		Name:      name,
+		FieldType: fieldType,
+		Options:   options,
+	})
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+		return nil, customErrors.ErrCustomFieldAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+// ListCustomFields returns the custom fields of the project in the order they
+// were defined.
+func (p *ProjectService) ListCustomFields(ctx context.Context, userID int, orgID int, projectID int) ([]projectdb.CustomField, error) {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	fields, err := p.projectRepository.ListCustomFields(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		fields = []projectdb.CustomField{}
+	}
+	return fields, nil
+}
+
+// UpdateCustomField renames a custom field or replaces the options of a
+// select field. Task values that are no longer among the options are dropped.
+func (p *ProjectService) UpdateCustomField(ctx context.Context, userID int, orgID int, projectID int, fieldID int64, req UpdateCustomFieldRequest) (*projectdb.CustomField, error) {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionManage); err != nil {
+		return nil, err
+	}
+	params := projectdb.UpdateCustomFieldParams{ID: fieldID, ProjectID: int64(projectID)}
+	if req.Name != nil {
+		name, err := customFieldName(*req.Name)
+		if err != nil {
+			return nil, err
+		}
+		params.Name = sql.NullString{String: name, Valid: true}
+	}
+	if req.Options != nil {
+		fields, err := p.projectRepository.ListCustomFields(ctx, int64(projectID))
+		if err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(fields, func(field projectdb.CustomField) bool { return field.ID == fieldID })
+		if i < 0 {
+			return nil, customErrors.ErrCustomFieldNotFound
+		}
+		if params.Options, err = customFieldOptions(fields[i].FieldType, *req.Options); err != nil {
+			return nil, err
+		}
+	}
+	field, err := p.projectRepository.UpdateCustomField(ctx, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrCustomFieldNotFound
+	}
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+		return nil, customErrors.ErrCustomFieldAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+// DeleteCustomField deletes a custom field of the project with its values.
+func (p *ProjectService) DeleteCustomField(ctx context.Context, userID int, orgID int, projectID int, fieldID int64) error {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionManage); err != nil {
+		return err
+	}
+	rows, err := p.projectRepository.DeleteCustomField(ctx, projectdb.DeleteCustomFieldParams{ID: fieldID, ProjectID: int64(projectID)})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrCustomFieldNotFound
+	}
+	return nil
+}
+
+func customFieldName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxCustomFieldNameLength {
+		return "", customErrors.ErrInvalidCustomFieldName
+	}
+	// spreadsheets name a column after every custom field
+	if types.IsTaskSheetHeader(name) {
+		return "", customErrors.ErrCustomFieldNameReserved
+	}
+	return name, nil
+}
+
+func validCustomFieldType(fieldType projectdb.CustomFieldType) bool {
+	switch fieldType {
+	case projectdb.CustomFieldTypeText, projectdb.CustomFieldTypeNumber, projectdb.CustomFieldTypeDate,
+		projectdb.CustomFieldTypeSingleSelect, projectdb.CustomFieldTypeMultiSelect, projectdb.CustomFieldTypeUser:
+		return true
+	}
+	return false
+}
+
+// customFieldOptions trims the options of a select field and checks them.
+// Commas are rejected because the Excel importer separates the values of
+// multi-select fields with them.
+func customFieldOptions(fieldType projectdb.CustomFieldType, options []string) ([]string, error) {
+	if fieldType != projectdb.CustomFieldTypeSingleSelect && fieldType != projectdb.CustomFieldTypeMultiSelect {
+		if len(options) > 0 {
+			return nil, customErrors.ErrInvalidCustomFieldOptions
+		}
+		return []string{}, nil
+	}
+	if len(options) == 0 || len(options) > maxCustomFieldOptions {
+		return nil, customErrors.ErrInvalidCustomFieldOptions
+	}
+	trimmed := make([]string, 0, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || utf8.RuneCountInString(option) > maxCustomFieldOptionLen || strings.Contains(option, ",") ||
+			slices.ContainsFunc(trimmed, func(o string) bool { return strings.EqualFold(o, option) }) {
+			return nil, customErrors.ErrInvalidCustomFieldOptions
+		}
+		trimmed = append(trimmed, option)
+	}
+	return trimmed, nil
+}
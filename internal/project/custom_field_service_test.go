@@ -0,0 +1,116 @@
+package project
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	projectdb "github.com/Gkemhcs/taskpilot/internal/project/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCustomFields(t *testing.T) {
+	repo := new(MockProjectRepo)
+	authzRepo := new(authz.MockAuthzRepo)
+	service := NewProjectService(repo, authz.NewAuthorizationService(authzRepo))
+
+	// user 101 owns project 7, user 202 is an editor
+	role := func(r authzdb.ProjectRole) authzdb.NullProjectRole {
+		return authzdb.NullProjectRole{ProjectRole: r, Valid: true}
+	}
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 7, UserID: 101, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 7, UserID: 101, Role: role(authzdb.ProjectRoleOWNER)}, nil)
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 7, UserID: 202, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 7, UserID: 101, Role: role(authzdb.ProjectRoleEDITOR)}, nil)
+
+	t.Run("create a custom field", func(t *testing.T) {
+		testCases := []struct {
+			name           string
+			request        CreateCustomFieldRequest
+			expectedParams *projectdb.CreateCustomFieldParams
+			expectedError  error
+		}{
+			{
+				name:           "number field",
+				request:        CreateCustomFieldRequest{Name: " Story points ", Type: "number"},
+				expectedParams: &projectdb.CreateCustomFieldParams{ProjectID: 7, Name: "Story points", FieldType: projectdb.CustomFieldTypeNumber, Options: []string{}},
+			},
+			{
+				name:           "select field options are trimmed",
+				request:        CreateCustomFieldRequest{Name: "Environment", Type: "single_select", Options: []string{"dev ", " prod"}},
+				expectedParams: &projectdb.CreateCustomFieldParams{ProjectID: 7, Name: "Environment", FieldType: projectdb.CustomFieldTypeSingleSelect, Options: []string{"dev", "prod"}},
+			},
+			{name: "unknown type", request: CreateCustomFieldRequest{Name: "Customer", Type: "email"}, expectedError: customErrors.ErrInvalidCustomFieldType},
+			{name: "select field without options", request: CreateCustomFieldRequest{Name: "Environment", Type: "multi_select"}, expectedError: customErrors.ErrInvalidCustomFieldOptions},
+			{name: "options differing only in case", request: CreateCustomFieldRequest{Name: "Environment", Type: "multi_select", Options: []string{"Dev", "dev"}}, expectedError: customErrors.ErrInvalidCustomFieldOptions},
+			{name: "options with commas", request: CreateCustomFieldRequest{Name: "Environment", Type: "multi_select", Options: []string{"dev,qa"}}, expectedError: customErrors.ErrInvalidCustomFieldOptions},
+			{name: "options of a text field", request: CreateCustomFieldRequest{Name: "Customer", Type: "text", Options: []string{"acme"}}, expectedError: customErrors.ErrInvalidCustomFieldOptions},
+			{name: "blank name", request: CreateCustomFieldRequest{Name: " ", Type: "text"}, expectedError: customErrors.ErrInvalidCustomFieldName},
+			{name: "named after an import column", request: CreateCustomFieldRequest{Name: "Labels ", Type: "text"}, expectedError: customErrors.ErrCustomFieldNameReserved},
+			{name: "named after an export column", request: CreateCustomFieldRequest{Name: "time_spent_minutes", Type: "number"}, expectedError: customErrors.ErrCustomFieldNameReserved},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				if tc.expectedParams != nil {
+					repo.On("CreateCustomField", mock.Anything, *tc.expectedParams).Return(projectdb.CustomField{ID: 1, ProjectID: 7, Name: tc.expectedParams.Name}, nil).Once()
+				}
+				_, err := service.CreateCustomField(context.TODO(), 101, testOrgID, 7, tc.request)
+				assert.Equal(t, tc.expectedError, err)
+			})
+		}
+	})
+
+	t.Run("names are unique in the project", func(t *testing.T) {
+		repo.On("CreateCustomField", mock.Anything, projectdb.CreateCustomFieldParams{ProjectID: 7, Name: "customer", FieldType: projectdb.CustomFieldTypeText, Options: []string{}}).Return(projectdb.CustomField{}, mockDuplicateError()).Once()
+
+		_, err := service.CreateCustomField(context.TODO(), 101, testOrgID, 7, CreateCustomFieldRequest{Name: "customer", Type: "text"})
+		assert.Equal(t, customErrors.ErrCustomFieldAlreadyExists, err)
+	})
+
+	t.Run("only owners manage custom fields", func(t *testing.T) {
+		_, err := service.CreateCustomField(context.TODO(), 202, testOrgID, 7, CreateCustomFieldRequest{Name: "customer", Type: "text"})
+		assert.Equal(t, customErrors.ErrForbidden, err)
+		assert.Equal(t, customErrors.ErrForbidden, service.DeleteCustomField(context.TODO(), 202, testOrgID, 7, 1))
+	})
+
+	t.Run("editors list custom fields", func(t *testing.T) {
+		repo.On("ListCustomFields", mock.Anything, int64(7)).Return([]projectdb.CustomField(nil), nil).Once()
+
+		fields, err := service.ListCustomFields(context.TODO(), 202, testOrgID, 7)
+		assert.NoError(t, err)
+		assert.NotNil(t, fields)
+	})
+
+	t.Run("replace the options of a select field", func(t *testing.T) {
+		fields := []projectdb.CustomField{
+			{ID: 1, ProjectID: 7, Name: "Story points", FieldType: projectdb.CustomFieldTypeNumber},
+			{ID: 2, ProjectID: 7, Name: "Environment", FieldType: projectdb.CustomFieldTypeSingleSelect, Options: []string{"dev", "prod"}},
+		}
+		repo.On("ListCustomFields", mock.Anything, int64(7)).Return(fields, nil)
+		params := projectdb.UpdateCustomFieldParams{ID: 2, ProjectID: 7, Options: []string{"dev", "staging", "prod"}}
+		repo.On("UpdateCustomField", mock.Anything, params).Return(projectdb.CustomField{ID: 2, ProjectID: 7, Options: params.Options}, nil).Once()
+
+		options := []string{"dev", "staging", "prod"}
+		field, err := service.UpdateCustomField(context.TODO(), 101, testOrgID, 7, 2, UpdateCustomFieldRequest{Options: &options})
+		assert.NoError(t, err)
+		assert.Equal(t, options, field.Options)
+
+		_, err = service.UpdateCustomField(context.TODO(), 101, testOrgID, 7, 1, UpdateCustomFieldRequest{Options: &options})
+		assert.Equal(t, customErrors.ErrInvalidCustomFieldOptions, err)
+
+		_, err = service.UpdateCustomField(context.TODO(), 101, testOrgID, 7, 9, UpdateCustomFieldRequest{Options: &options})
+		assert.Equal(t, customErrors.ErrCustomFieldNotFound, err)
+	})
+
+	t.Run("fields of another project are not found", func(t *testing.T) {
+		name := "Customer"
+		repo.On("UpdateCustomField", mock.Anything, projectdb.UpdateCustomFieldParams{ID: 9, ProjectID: 7, Name: sql.NullString{String: name, Valid: true}}).Return(projectdb.CustomField{}, sql.ErrNoRows).Once()
+		repo.On("DeleteCustomField", mock.Anything, projectdb.DeleteCustomFieldParams{ID: 9, ProjectID: 7}).Return(int64(0), nil).Once()
+
+		_, err := service.UpdateCustomField(context.TODO(), 101, testOrgID, 7, 9, UpdateCustomFieldRequest{Name: &name})
+		assert.Equal(t, customErrors.ErrCustomFieldNotFound, err)
+		assert.Equal(t, customErrors.ErrCustomFieldNotFound, service.DeleteCustomField(context.TODO(), 101, testOrgID, 7, 9))
+	})
+}
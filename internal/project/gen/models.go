@@ -7,12 +7,59 @@ package projectdb
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type CustomFieldType string
+
+const (
+	CustomFieldTypeText         CustomFieldType = "text"
+	CustomFieldTypeNumber       CustomFieldType = "number"
+	CustomFieldTypeDate         CustomFieldType = "date"
+	CustomFieldTypeSingleSelect CustomFieldType = "single_select"
+	CustomFieldTypeMultiSelect  CustomFieldType = "multi_select"
+	CustomFieldTypeUser         CustomFieldType = "user"
+)
+
+func (e *CustomFieldType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CustomFieldType(s)
+	case string:
+		*e = CustomFieldType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CustomFieldType: %T", src)
+	}
+	return nil
+}
+
+type NullCustomFieldType struct {
+	CustomFieldType CustomFieldType `json:"custom_field_type"`
+	Valid           bool            `json:"valid"` // Valid is true if CustomFieldType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCustomFieldType) Scan(value interface{}) error {
+	if value == nil {
+		ns.CustomFieldType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CustomFieldType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCustomFieldType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CustomFieldType), nil
+}
+
 type ExportJobStatus string
 
 const (
@@ -185,6 +232,91 @@ func (ns NullImportJobType) Value() (driver.Value, error) {
 	return string(ns.ImportJobType), nil
 }
 
+type NotificationKind string
+
+const (
+	NotificationKindMention    NotificationKind = "mention"
+	NotificationKindAssignment NotificationKind = "assignment"
+)
+
+func (e *NotificationKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = NotificationKind(s)
+	case string:
+		*e = NotificationKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for NotificationKind: %T", src)
+	}
+	return nil
+}
+
+type NullNotificationKind struct {
+	NotificationKind NotificationKind `json:"notification_kind"`
+	Valid            bool             `json:"valid"` // Valid is true if NotificationKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullNotificationKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.NotificationKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.NotificationKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullNotificationKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.NotificationKind), nil
+}
+
+type OrganizationRole string
+
+const (
+	OrganizationRoleOWNER  OrganizationRole = "OWNER"
+	OrganizationRoleADMIN  OrganizationRole = "ADMIN"
+	OrganizationRoleMEMBER OrganizationRole = "MEMBER"
+)
+
+func (e *OrganizationRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OrganizationRole(s)
+	case string:
+		*e = OrganizationRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OrganizationRole: %T", src)
+	}
+	return nil
+}
+
+type NullOrganizationRole struct {
+	OrganizationRole OrganizationRole `json:"organization_role"`
+	Valid            bool             `json:"valid"` // Valid is true if OrganizationRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOrganizationRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.OrganizationRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OrganizationRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOrganizationRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OrganizationRole), nil
+}
+
 type ProjectColor string
 
 const (
@@ -228,6 +360,93 @@ func (ns NullProjectColor) Value() (driver.Value, error) {
 	return string(ns.ProjectColor), nil
 }
 
+type ProjectRole string
+
+const (
+	ProjectRoleOWNER     ProjectRole = "OWNER"
+	ProjectRoleEDITOR    ProjectRole = "EDITOR"
+	ProjectRoleVIEWER    ProjectRole = "VIEWER"
+	ProjectRoleCOMMENTER ProjectRole = "COMMENTER"
+)
+
+func (e *ProjectRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProjectRole(s)
+	case string:
+		*e = ProjectRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProjectRole: %T", src)
+	}
+	return nil
+}
+
+type NullProjectRole struct {
+	ProjectRole ProjectRole `json:"project_role"`
+	Valid       bool        `json:"valid"` // Valid is true if ProjectRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProjectRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProjectRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProjectRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProjectRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProjectRole), nil
+}
+
+type StatusCategory string
+
+const (
+	StatusCategoryOpen       StatusCategory = "open"
+	StatusCategoryInProgress StatusCategory = "in_progress"
+	StatusCategoryDone       StatusCategory = "done"
+)
+
+func (e *StatusCategory) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = StatusCategory(s)
+	case string:
+		*e = StatusCategory(s)
+	default:
+		return fmt.Errorf("unsupported scan type for StatusCategory: %T", src)
+	}
+	return nil
+}
+
+type NullStatusCategory struct {
+	StatusCategory StatusCategory `json:"status_category"`
+	Valid          bool           `json:"valid"` // Valid is true if StatusCategory is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullStatusCategory) Scan(value interface{}) error {
+	if value == nil {
+		ns.StatusCategory, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.StatusCategory.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullStatusCategory) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.StatusCategory), nil
+}
+
 type TaskPriority string
 
 const (
@@ -272,98 +491,330 @@ func (ns NullTaskPriority) Value() (driver.Value, error) {
 	return string(ns.TaskPriority), nil
 }
 
-type TaskStatus string
+type UserTokenPurpose string
 
 const (
-	TaskStatusTODO       TaskStatus = "TODO"
-	TaskStatusINPROGRESS TaskStatus = "IN_PROGRESS"
-	TaskStatusDONE       TaskStatus = "DONE"
+	UserTokenPurposePASSWORDRESET     UserTokenPurpose = "PASSWORD_RESET"
+	UserTokenPurposeEMAILVERIFICATION UserTokenPurpose = "EMAIL_VERIFICATION"
 )
 
-func (e *TaskStatus) Scan(src interface{}) error {
+func (e *UserTokenPurpose) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = TaskStatus(s)
+		*e = UserTokenPurpose(s)
 	case string:
-		*e = TaskStatus(s)
+		*e = UserTokenPurpose(s)
 	default:
-		return fmt.Errorf("unsupported scan type for TaskStatus: %T", src)
+		return fmt.Errorf("unsupported scan type for UserTokenPurpose: %T", src)
 	}
 	return nil
 }
 
-type NullTaskStatus struct {
-	TaskStatus TaskStatus `json:"task_status"`
-	Valid      bool       `json:"valid"` // Valid is true if TaskStatus is not NULL
+type NullUserTokenPurpose struct {
+	UserTokenPurpose UserTokenPurpose `json:"user_token_purpose"`
+	Valid            bool             `json:"valid"` // Valid is true if UserTokenPurpose is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullTaskStatus) Scan(value interface{}) error {
+func (ns *NullUserTokenPurpose) Scan(value interface{}) error {
 	if value == nil {
-		ns.TaskStatus, ns.Valid = "", false
+		ns.UserTokenPurpose, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.TaskStatus.Scan(value)
+	return ns.UserTokenPurpose.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullTaskStatus) Value() (driver.Value, error) {
+func (ns NullUserTokenPurpose) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.TaskStatus), nil
+	return string(ns.UserTokenPurpose), nil
+}
+
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	ActorID    sql.NullInt32   `json:"actor_id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	ProjectID  sql.NullInt64   `json:"project_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  sql.NullString  `json:"request_id"`
+	Ip         sql.NullString  `json:"ip"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type CustomField struct {
+	ID        int64           `json:"id"`
+	ProjectID int64           `json:"project_id"`
+	Name      string          `json:"name"`
+	FieldType CustomFieldType `json:"field_type"`
+	Options   []string        `json:"options"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
 }
 
 type ExportJob struct {
-	ID           uuid.UUID       `json:"id"`
-	UserID       int32           `json:"user_id"`
-	Status       ExportJobStatus `json:"status"`
-	ExportType   ExportType      `json:"export_type"`
-	Url          sql.NullString  `json:"url"`
-	ErrorMessage sql.NullString  `json:"error_message"`
-	CreatedAt    sql.NullTime    `json:"created_at"`
-	UpdatedAt    sql.NullTime    `json:"updated_at"`
+	ID             uuid.UUID       `json:"id"`
+	UserID         int32           `json:"user_id"`
+	Status         ExportJobStatus `json:"status"`
+	ExportType     ExportType      `json:"export_type"`
+	Url            sql.NullString  `json:"url"`
+	ErrorMessage   sql.NullString  `json:"error_message"`
+	CreatedAt      sql.NullTime    `json:"created_at"`
+	UpdatedAt      sql.NullTime    `json:"updated_at"`
+	OrganizationID int64           `json:"organization_id"`
 }
 
 type ImportJob struct {
-	ID           uuid.UUID       `json:"id"`
-	FilePath     string          `json:"file_path"`
-	ImporterType ImportJobType   `json:"importer_type"`
-	Status       ImportJobStatus `json:"status"`
-	ErrorMessage sql.NullString  `json:"error_message"`
-	CreatedAt    sql.NullTime    `json:"created_at"`
-	UpdatedAt    sql.NullTime    `json:"updated_at"`
-	UserID       int32           `json:"user_id"`
+	ID             uuid.UUID       `json:"id"`
+	FilePath       string          `json:"file_path"`
+	ImporterType   ImportJobType   `json:"importer_type"`
+	Status         ImportJobStatus `json:"status"`
+	ErrorMessage   sql.NullString  `json:"error_message"`
+	CreatedAt      sql.NullTime    `json:"created_at"`
+	UpdatedAt      sql.NullTime    `json:"updated_at"`
+	UserID         int32           `json:"user_id"`
+	OrganizationID int64           `json:"organization_id"`
+}
+
+type Label struct {
+	ID        int64     `json:"id"`
+	ProjectID int64     `json:"project_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Notification struct {
+	ID        int64            `json:"id"`
+	UserID    int32            `json:"user_id"`
+	ActorID   sql.NullInt32    `json:"actor_id"`
+	Kind      NotificationKind `json:"kind"`
+	TaskID    int64            `json:"task_id"`
+	CommentID sql.NullInt64    `json:"comment_id"`
+	ReadAt    sql.NullTime     `json:"read_at"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+type Organization struct {
+	ID          int64         `json:"id"`
+	Name        string        `json:"name"`
+	PersonalFor sql.NullInt32 `json:"personal_for"`
+	CreatedBy   sql.NullInt32 `json:"created_by"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+type OrganizationMember struct {
+	OrganizationID int64            `json:"organization_id"`
+	UserID         int32            `json:"user_id"`
+	Role           OrganizationRole `json:"role"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+type PersonalAccessToken struct {
+	ID          int64        `json:"id"`
+	UserID      int32        `json:"user_id"`
+	Name        string       `json:"name"`
+	TokenHash   string       `json:"token_hash"`
+	TokenPrefix string       `json:"token_prefix"`
+	Scopes      []string     `json:"scopes"`
+	ExpiresAt   sql.NullTime `json:"expires_at"`
+	LastUsedAt  sql.NullTime `json:"last_used_at"`
+	RevokedAt   sql.NullTime `json:"revoked_at"`
+	CreatedAt   time.Time    `json:"created_at"`
 }
 
 type Project struct {
-	ID          int64            `json:"id"`
-	UserID      int32            `json:"user_id"`
-	Name        string           `json:"name"`
-	Description sql.NullString   `json:"description"`
-	Color       NullProjectColor `json:"color"`
-	CreatedAt   time.Time        `json:"created_at"`
-	UpdatedAt   time.Time        `json:"updated_at"`
+	ID             int64            `json:"id"`
+	UserID         int32            `json:"user_id"`
+	Name           string           `json:"name"`
+	Description    sql.NullString   `json:"description"`
+	Color          NullProjectColor `json:"color"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	OrganizationID int64            `json:"organization_id"`
+}
+
+type ProjectMember struct {
+	ProjectID int64       `json:"project_id"`
+	UserID    int32       `json:"user_id"`
+	Role      ProjectRole `json:"role"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
 }
 
 type Task struct {
+	ID                       int64         `json:"id"`
+	ProjectID                int64         `json:"project_id"`
+	AssigneeID               sql.NullInt64 `json:"assignee_id"`
+	Title                    string        `json:"title"`
+	Description              string        `json:"description"`
+	Status                   string        `json:"status"`
+	Priority                 TaskPriority  `json:"priority"`
+	DueDate                  sql.NullTime  `json:"due_date"`
+	CreatedAt                time.Time     `json:"created_at"`
+	UpdatedAt                time.Time     `json:"updated_at"`
+	ParentTaskID             sql.NullInt64 `json:"parent_task_id"`
+	SeriesID                 sql.NullInt64 `json:"series_id"`
+	OriginalEstimateMinutes  sql.NullInt32 `json:"original_estimate_minutes"`
+	RemainingEstimateMinutes sql.NullInt32 `json:"remaining_estimate_minutes"`
+}
+
+type TaskAttachment struct {
 	ID          int64         `json:"id"`
+	TaskID      int64         `json:"task_id"`
 	ProjectID   int64         `json:"project_id"`
-	AssigneeID  sql.NullInt64 `json:"assignee_id"`
-	Title       string        `json:"title"`
-	Description string        `json:"description"`
-	Status      TaskStatus    `json:"status"`
-	Priority    TaskPriority  `json:"priority"`
-	DueDate     sql.NullTime  `json:"due_date"`
+	UploaderID  sql.NullInt32 `json:"uploader_id"`
+	FileName    string        `json:"file_name"`
+	ContentType string        `json:"content_type"`
+	SizeBytes   int64         `json:"size_bytes"`
+	Checksum    string        `json:"checksum"`
+	StorageKey  string        `json:"storage_key"`
 	CreatedAt   time.Time     `json:"created_at"`
-	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+type TaskChange struct {
+	ID        int64          `json:"id"`
+	TaskID    int64          `json:"task_id"`
+	ProjectID int64          `json:"project_id"`
+	TaskTitle string         `json:"task_title"`
+	ActorID   sql.NullInt32  `json:"actor_id"`
+	Action    string         `json:"action"`
+	Field     sql.NullString `json:"field"`
+	OldValue  sql.NullString `json:"old_value"`
+	NewValue  sql.NullString `json:"new_value"`
+	ChangedAt time.Time      `json:"changed_at"`
+}
+
+type TaskComment struct {
+	ID        int64         `json:"id"`
+	TaskID    int64         `json:"task_id"`
+	ParentID  sql.NullInt64 `json:"parent_id"`
+	AuthorID  sql.NullInt32 `json:"author_id"`
+	Body      string        `json:"body"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	EditedAt  sql.NullTime  `json:"edited_at"`
+}
+
+type TaskCommentEdit struct {
+	ID        int64         `json:"id"`
+	CommentID int64         `json:"comment_id"`
+	Body      string        `json:"body"`
+	EditedBy  sql.NullInt32 `json:"edited_by"`
+	EditedAt  time.Time     `json:"edited_at"`
+}
+
+type TaskCustomFieldValue struct {
+	TaskID    int64           `json:"task_id"`
+	FieldID   int64           `json:"field_id"`
+	ProjectID int64           `json:"project_id"`
+	Value     json.RawMessage `json:"value"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type TaskDependency struct {
+	TaskID      int64     `json:"task_id"`
+	BlockedByID int64     `json:"blocked_by_id"`
+	ProjectID   int64     `json:"project_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type TaskLabel struct {
+	TaskID    int64 `json:"task_id"`
+	LabelID   int64 `json:"label_id"`
+	ProjectID int64 `json:"project_id"`
+}
+
+type TaskSeries struct {
+	ID           int64         `json:"id"`
+	ProjectID    int64         `json:"project_id"`
+	CreatedBy    int32         `json:"created_by"`
+	Title        string        `json:"title"`
+	Description  string        `json:"description"`
+	AssigneeID   sql.NullInt64 `json:"assignee_id"`
+	Priority     TaskPriority  `json:"priority"`
+	Rule         string        `json:"rule"`
+	StartsAt     time.Time     `json:"starts_at"`
+	Occurrences  int32         `json:"occurrences"`
+	NextDueAt    sql.NullTime  `json:"next_due_at"`
+	LatestTaskID sql.NullInt64 `json:"latest_task_id"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+type TimeEntry struct {
+	ID        int64         `json:"id"`
+	TaskID    int64         `json:"task_id"`
+	ProjectID int64         `json:"project_id"`
+	UserID    int32         `json:"user_id"`
+	StartedAt time.Time     `json:"started_at"`
+	EndedAt   sql.NullTime  `json:"ended_at"`
+	Minutes   sql.NullInt32 `json:"minutes"`
+	Note      string        `json:"note"`
+	CreatedAt time.Time     `json:"created_at"`
 }
 
 type User struct {
-	ID             int32     `json:"id"`
-	Email          string    `json:"email"`
-	Name           string    `json:"name"`
-	HashedPassword string    `json:"hashed_password"`
-	CreatedAt      time.Time `json:"created_at"`
+	ID              int32        `json:"id"`
+	Email           string       `json:"email"`
+	Name            string       `json:"name"`
+	HashedPassword  string       `json:"hashed_password"`
+	CreatedAt       time.Time    `json:"created_at"`
+	EmailVerifiedAt sql.NullTime `json:"email_verified_at"`
+	IsAdmin         bool         `json:"is_admin"`
+	DisabledAt      sql.NullTime `json:"disabled_at"`
+}
+
+type UserIdentity struct {
+	ID        int64     `json:"id"`
+	UserID    int32     `json:"user_id"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserRecoveryCode struct {
+	ID        int64        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type UserToken struct {
+	ID        int64            `json:"id"`
+	UserID    int32            `json:"user_id"`
+	Purpose   UserTokenPurpose `json:"purpose"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	UsedAt    sql.NullTime     `json:"used_at"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+type UserTotp struct {
+	UserID       int32        `json:"user_id"`
+	Secret       string       `json:"secret"`
+	ConfirmedAt  sql.NullTime `json:"confirmed_at"`
+	LastUsedStep int64        `json:"last_used_step"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+type WorkflowStatus struct {
+	ID          int64          `json:"id"`
+	ProjectID   int64          `json:"project_id"`
+	Name        string         `json:"name"`
+	Category    StatusCategory `json:"category"`
+	Position    int32          `json:"position"`
+	Transitions []string       `json:"transitions"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
 }
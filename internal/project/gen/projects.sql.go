@@ -8,21 +8,105 @@ package projectdb
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+const addProjectMember = `-- name: AddProjectMember :one
+INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, $3) RETURNING project_id, user_id, role, created_at, updated_at
+`
+
+type AddProjectMemberParams struct {
+	ProjectID int64       `json:"project_id"`
+	UserID    int32       `json:"user_id"`
+	Role      ProjectRole `json:"role"`
+}
+
+func (q *Queries) AddProjectMember(ctx context.Context, arg AddProjectMemberParams) (ProjectMember, error) {
+	row := q.db.QueryRowContext(ctx, addProjectMember, arg.ProjectID, arg.UserID, arg.Role)
+	var i ProjectMember
+	err := row.Scan(
+		&i.ProjectID,
+		&i.UserID,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createCustomField = `-- name: CreateCustomField :one
+INSERT INTO custom_fields (project_id, name, field_type, options) VALUES ($1, $2, $3, $4) RETURNING id, project_id, name, field_type, options, created_at, updated_at
+`
+
+type CreateCustomFieldParams struct {
+	ProjectID int64           `json:"project_id"`
+	Name      string          `json:"name"`
+	FieldType CustomFieldType `json:"field_type"`
+	Options   []string        `json:"options"`
+}
+
+func (q *Queries) CreateCustomField(ctx context.Context, arg CreateCustomFieldParams) (CustomField, error) {
+	row := q.db.QueryRowContext(ctx, createCustomField,
+		arg.ProjectID,
+		arg.Name,
+		arg.FieldType,
+		pq.Array(arg.Options),
+	)
+	var i CustomField
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.FieldType,
+		pq.Array(&i.Options),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createLabel = `-- name: CreateLabel :one
+INSERT INTO labels (project_id, name, color) VALUES ($1, $2, $3) RETURNING id, project_id, name, color, created_at, updated_at
+`
+
+type CreateLabelParams struct {
+	ProjectID int64  `json:"project_id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+}
+
+func (q *Queries) CreateLabel(ctx context.Context, arg CreateLabelParams) (Label, error) {
+	row := q.db.QueryRowContext(ctx, createLabel, arg.ProjectID, arg.Name, arg.Color)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createProject = `-- name: CreateProject :one
-INSERT INTO projects ( user_id, name, description, color) VALUES ($1,$2,$3,$4) RETURNING id, user_id, name, description, color, created_at, updated_at
+INSERT INTO projects (organization_id, user_id, name, description, color) VALUES ($1,$2,$3,$4,$5) RETURNING id, user_id, name, description, color, created_at, updated_at, organization_id
 `
 
 type CreateProjectParams struct {
-	UserID      int32            `json:"user_id"`
-	Name        string           `json:"name"`
-	Description sql.NullString   `json:"description"`
-	Color       NullProjectColor `json:"color"`
+	OrganizationID int64            `json:"organization_id"`
+	UserID         int32            `json:"user_id"`
+	Name           string           `json:"name"`
+	Description    sql.NullString   `json:"description"`
+	Color          NullProjectColor `json:"color"`
 }
 
 func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error) {
 	row := q.db.QueryRowContext(ctx, createProject,
+		arg.OrganizationID,
 		arg.UserID,
 		arg.Name,
 		arg.Description,
@@ -37,10 +121,45 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		&i.Color,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrganizationID,
 	)
 	return i, err
 }
 
+const deleteCustomField = `-- name: DeleteCustomField :execrows
+DELETE FROM custom_fields WHERE id = $1 AND project_id = $2
+`
+
+type DeleteCustomFieldParams struct {
+	ID        int64 `json:"id"`
+	ProjectID int64 `json:"project_id"`
+}
+
+func (q *Queries) DeleteCustomField(ctx context.Context, arg DeleteCustomFieldParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteCustomField, arg.ID, arg.ProjectID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteLabel = `-- name: DeleteLabel :execrows
+DELETE FROM labels WHERE id = $1 AND project_id = $2
+`
+
+type DeleteLabelParams struct {
+	ID        int64 `json:"id"`
+	ProjectID int64 `json:"project_id"`
+}
+
+func (q *Queries) DeleteLabel(ctx context.Context, arg DeleteLabelParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteLabel, arg.ID, arg.ProjectID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteProject = `-- name: DeleteProject :exec
 DELETE FROM projects WHERE id = $1
 `
@@ -51,7 +170,7 @@ func (q *Queries) DeleteProject(ctx context.Context, id int64) error {
 }
 
 const getProjectById = `-- name: GetProjectById :one
-SELECT id, user_id, name, description, color, created_at, updated_at FROM projects WHERE id=$1
+SELECT id, user_id, name, description, color, created_at, updated_at, organization_id FROM projects WHERE id=$1
 `
 
 func (q *Queries) GetProjectById(ctx context.Context, id int64) (Project, error) {
@@ -65,22 +184,23 @@ func (q *Queries) GetProjectById(ctx context.Context, id int64) (Project, error)
 		&i.Color,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrganizationID,
 	)
 	return i, err
 }
 
 const getProjectByName = `-- name: GetProjectByName :one
 
-SELECT id, user_id, name, description, color, created_at, updated_at FROM projects WHERE name=$1 AND user_id=$2
+SELECT id, user_id, name, description, color, created_at, updated_at, organization_id FROM projects WHERE organization_id=$1 AND name=$2
 `
 
 type GetProjectByNameParams struct {
-	Name   string `json:"name"`
-	UserID int32  `json:"user_id"`
+	OrganizationID int64  `json:"organization_id"`
+	Name           string `json:"name"`
 }
 
 func (q *Queries) GetProjectByName(ctx context.Context, arg GetProjectByNameParams) (Project, error) {
-	row := q.db.QueryRowContext(ctx, getProjectByName, arg.Name, arg.UserID)
+	row := q.db.QueryRowContext(ctx, getProjectByName, arg.OrganizationID, arg.Name)
 	var i Project
 	err := row.Scan(
 		&i.ID,
@@ -90,16 +210,26 @@ func (q *Queries) GetProjectByName(ctx context.Context, arg GetProjectByNamePara
 		&i.Color,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OrganizationID,
 	)
 	return i, err
 }
 
 const getProjectsByUserId = `-- name: GetProjectsByUserId :many
-SELECT id, user_id, name, description, color, created_at, updated_at FROM projects WHERE user_id=$1 ORDER BY id
+SELECT id, user_id, name, description, color, created_at, updated_at, organization_id FROM projects
+WHERE organization_id=$1
+  AND (user_id=$2
+   OR id IN (SELECT pm.project_id FROM project_members pm WHERE pm.user_id=$2))
+ORDER BY id
 `
 
-func (q *Queries) GetProjectsByUserId(ctx context.Context, userID int32) ([]Project, error) {
-	rows, err := q.db.QueryContext(ctx, getProjectsByUserId, userID)
+type GetProjectsByUserIdParams struct {
+	OrganizationID int64 `json:"organization_id"`
+	UserID         int32 `json:"user_id"`
+}
+
+func (q *Queries) GetProjectsByUserId(ctx context.Context, arg GetProjectsByUserIdParams) ([]Project, error) {
+	rows, err := q.db.QueryContext(ctx, getProjectsByUserId, arg.OrganizationID, arg.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +245,177 @@ func (q *Queries) GetProjectsByUserId(ctx context.Context, userID int32) ([]Proj
 			&i.Color,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OrganizationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isOrganizationMember = `-- name: IsOrganizationMember :one
+SELECT EXISTS (
+  SELECT 1 FROM organization_members WHERE organization_id = $1 AND user_id = $2
+)
+`
+
+type IsOrganizationMemberParams struct {
+	OrganizationID int64 `json:"organization_id"`
+	UserID         int32 `json:"user_id"`
+}
+
+func (q *Queries) IsOrganizationMember(ctx context.Context, arg IsOrganizationMemberParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isOrganizationMember, arg.OrganizationID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listCustomFields = `-- name: ListCustomFields :many
+SELECT id, project_id, name, field_type, options, created_at, updated_at FROM custom_fields WHERE project_id = $1 ORDER BY id
+`
+
+func (q *Queries) ListCustomFields(ctx context.Context, projectID int64) ([]CustomField, error) {
+	rows, err := q.db.QueryContext(ctx, listCustomFields, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CustomField
+	for rows.Next() {
+		var i CustomField
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.FieldType,
+			pq.Array(&i.Options),
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLabels = `-- name: ListLabels :many
+SELECT id, project_id, name, color, created_at, updated_at FROM labels WHERE project_id = $1 ORDER BY lower(name)
+`
+
+func (q *Queries) ListLabels(ctx context.Context, projectID int64) ([]Label, error) {
+	rows, err := q.db.QueryContext(ctx, listLabels, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Label
+	for rows.Next() {
+		var i Label
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Color,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectMembers = `-- name: ListProjectMembers :many
+SELECT pm.project_id, pm.user_id, pm.role, pm.created_at, u.name, u.email
+FROM project_members pm
+JOIN users u ON u.id = pm.user_id
+WHERE pm.project_id = $1
+ORDER BY pm.created_at, pm.user_id
+`
+
+type ListProjectMembersRow struct {
+	ProjectID int64       `json:"project_id"`
+	UserID    int32       `json:"user_id"`
+	Role      ProjectRole `json:"role"`
+	CreatedAt time.Time   `json:"created_at"`
+	Name      string      `json:"name"`
+	Email     string      `json:"email"`
+}
+
+func (q *Queries) ListProjectMembers(ctx context.Context, projectID int64) ([]ListProjectMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listProjectMembers, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProjectMembersRow
+	for rows.Next() {
+		var i ListProjectMembersRow
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.UserID,
+			&i.Role,
+			&i.CreatedAt,
+			&i.Name,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkflowStatuses = `-- name: ListWorkflowStatuses :many
+SELECT id, project_id, name, category, position, transitions, created_at, updated_at FROM workflow_statuses WHERE project_id = $1 ORDER BY position
+`
+
+func (q *Queries) ListWorkflowStatuses(ctx context.Context, projectID int64) ([]WorkflowStatus, error) {
+	rows, err := q.db.QueryContext(ctx, listWorkflowStatuses, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkflowStatus
+	for rows.Next() {
+		var i WorkflowStatus
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Category,
+			&i.Position,
+			pq.Array(&i.Transitions),
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -129,6 +430,172 @@ func (q *Queries) GetProjectsByUserId(ctx context.Context, userID int32) ([]Proj
 	return items, nil
 }
 
+const removeProjectMember = `-- name: RemoveProjectMember :execrows
+DELETE FROM project_members WHERE project_id = $1 AND user_id = $2
+`
+
+type RemoveProjectMemberParams struct {
+	ProjectID int64 `json:"project_id"`
+	UserID    int32 `json:"user_id"`
+}
+
+func (q *Queries) RemoveProjectMember(ctx context.Context, arg RemoveProjectMemberParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeProjectMember, arg.ProjectID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const replaceWorkflow = `-- name: ReplaceWorkflow :many
+WITH input AS (
+  SELECT s.name, s.category, s.position, s.transitions
+  FROM jsonb_to_recordset($1::jsonb)
+    AS s(name VARCHAR(30), category status_category, position INT, transitions TEXT[])
+), removed AS (
+  DELETE FROM workflow_statuses w
+  WHERE w.project_id = $2 AND w.name NOT IN (SELECT name FROM input)
+)
+INSERT INTO workflow_statuses (project_id, name, category, position, transitions)
+SELECT $2, name, category, position, transitions FROM input
+ON CONFLICT (project_id, name) DO UPDATE
+SET
+  category = EXCLUDED.category,
+  position = EXCLUDED.position,
+  transitions = EXCLUDED.transitions,
+  updated_at = now()
+RETURNING id, project_id, name, category, position, transitions, created_at, updated_at
+`
+
+type ReplaceWorkflowParams struct {
+	Statuses  json.RawMessage `json:"statuses"`
+	ProjectID int64           `json:"project_id"`
+}
+
+// statuses left out of the new workflow are removed by the same statement,
+// which fails while tasks are still in them
+func (q *Queries) ReplaceWorkflow(ctx context.Context, arg ReplaceWorkflowParams) ([]WorkflowStatus, error) {
+	rows, err := q.db.QueryContext(ctx, replaceWorkflow, arg.Statuses, arg.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkflowStatus
+	for rows.Next() {
+		var i WorkflowStatus
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Category,
+			&i.Position,
+			pq.Array(&i.Transitions),
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCustomField = `-- name: UpdateCustomField :one
+WITH dropped AS (
+  DELETE FROM task_custom_field_values v
+  USING custom_fields f
+  WHERE f.id = $1 AND f.project_id = $2 AND v.field_id = f.id
+    AND f.field_type = 'single_select' AND NOT (v.value #>> '{}') = ANY($3::text[])
+), trimmed AS (
+  UPDATE task_custom_field_values v
+  SET value = (
+    SELECT COALESCE(jsonb_agg(o), '[]'::jsonb) FROM jsonb_array_elements_text(v.value) o
+    WHERE o = ANY($3::text[])
+  ), updated_at = now()
+  FROM custom_fields f
+  WHERE f.id = $1 AND f.project_id = $2 AND v.field_id = f.id
+    AND f.field_type = 'multi_select' AND $3::text[] IS NOT NULL
+)
+UPDATE custom_fields
+SET
+  name = COALESCE($4, name),
+  options = COALESCE($3::text[], options),
+  updated_at = now()
+WHERE id = $1 AND project_id = $2
+RETURNING id, project_id, name, field_type, options, created_at, updated_at
+`
+
+type UpdateCustomFieldParams struct {
+	ID        int64          `json:"id"`
+	ProjectID int64          `json:"project_id"`
+	Options   []string       `json:"options"`
+	Name      sql.NullString `json:"name"`
+}
+
+// values of select fields that are no longer among the options are dropped by
+// the same statement
+func (q *Queries) UpdateCustomField(ctx context.Context, arg UpdateCustomFieldParams) (CustomField, error) {
+	row := q.db.QueryRowContext(ctx, updateCustomField,
+		arg.ID,
+		arg.ProjectID,
+		pq.Array(arg.Options),
+		arg.Name,
+	)
+	var i CustomField
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.FieldType,
+		pq.Array(&i.Options),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateLabel = `-- name: UpdateLabel :one
+UPDATE labels
+SET
+  name = COALESCE($1, name),
+  color = COALESCE($2, color),
+  updated_at = now()
+WHERE id = $3 AND project_id = $4
+RETURNING id, project_id, name, color, created_at, updated_at
+`
+
+type UpdateLabelParams struct {
+	Name      sql.NullString `json:"name"`
+	Color     sql.NullString `json:"color"`
+	ID        int64          `json:"id"`
+	ProjectID int64          `json:"project_id"`
+}
+
+func (q *Queries) UpdateLabel(ctx context.Context, arg UpdateLabelParams) (Label, error) {
+	row := q.db.QueryRowContext(ctx, updateLabel,
+		arg.Name,
+		arg.Color,
+		arg.ID,
+		arg.ProjectID,
+	)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateProject = `-- name: UpdateProject :exec
 UPDATE projects
 SET
@@ -155,3 +622,23 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) er
 	)
 	return err
 }
+
+const updateProjectMemberRole = `-- name: UpdateProjectMemberRole :execrows
+UPDATE project_members
+SET role = $3, updated_at = now()
+WHERE project_id = $1 AND user_id = $2
+`
+
+type UpdateProjectMemberRoleParams struct {
+	ProjectID int64       `json:"project_id"`
+	UserID    int32       `json:"user_id"`
+	Role      ProjectRole `json:"role"`
+}
+
+func (q *Queries) UpdateProjectMemberRole(ctx context.Context, arg UpdateProjectMemberRoleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateProjectMemberRole, arg.ProjectID, arg.UserID, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
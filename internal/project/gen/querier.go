@@ -9,12 +9,31 @@ import (
 )
 
 type Querier interface {
+	AddProjectMember(ctx context.Context, arg AddProjectMemberParams) (ProjectMember, error)
+	CreateCustomField(ctx context.Context, arg CreateCustomFieldParams) (CustomField, error)
+	CreateLabel(ctx context.Context, arg CreateLabelParams) (Label, error)
 	CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error)
+	DeleteCustomField(ctx context.Context, arg DeleteCustomFieldParams) (int64, error)
+	DeleteLabel(ctx context.Context, arg DeleteLabelParams) (int64, error)
 	DeleteProject(ctx context.Context, id int64) error
 	GetProjectById(ctx context.Context, id int64) (Project, error)
 	GetProjectByName(ctx context.Context, arg GetProjectByNameParams) (Project, error)
-	GetProjectsByUserId(ctx context.Context, userID int32) ([]Project, error)
+	GetProjectsByUserId(ctx context.Context, arg GetProjectsByUserIdParams) ([]Project, error)
+	IsOrganizationMember(ctx context.Context, arg IsOrganizationMemberParams) (bool, error)
+	ListCustomFields(ctx context.Context, projectID int64) ([]CustomField, error)
+	ListLabels(ctx context.Context, projectID int64) ([]Label, error)
+	ListProjectMembers(ctx context.Context, projectID int64) ([]ListProjectMembersRow, error)
+	ListWorkflowStatuses(ctx context.Context, projectID int64) ([]WorkflowStatus, error)
+	RemoveProjectMember(ctx context.Context, arg RemoveProjectMemberParams) (int64, error)
+	// statuses left out of the new workflow are removed by the same statement,
+	// which fails while tasks are still in them
+	ReplaceWorkflow(ctx context.Context, arg ReplaceWorkflowParams) ([]WorkflowStatus, error)
+	// values of select fields that are no longer among the options are dropped by
+	// the same statement
+	UpdateCustomField(ctx context.Context, arg UpdateCustomFieldParams) (CustomField, error)
+	UpdateLabel(ctx context.Context, arg UpdateLabelParams) (Label, error)
 	UpdateProject(ctx context.Context, arg UpdateProjectParams) error
+	UpdateProjectMemberRole(ctx context.Context, arg UpdateProjectMemberRoleParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
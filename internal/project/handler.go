@@ -11,16 +11,18 @@ import (
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	"github.com/Gkemhcs/taskpilot/internal/middleware"
 	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/Gkemhcs/taskpilot/internal/user"
 	"github.com/Gkemhcs/taskpilot/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
-func NewProjectHandler(logger *logrus.Logger, projectService *ProjectService, taskService types.TaskQueryService) *ProjectHandler {
+func NewProjectHandler(logger *logrus.Logger, projectService *ProjectService, taskService types.TaskQueryService, userService user.UserResolver) *ProjectHandler {
 	return &ProjectHandler{
 		logger:           logger,
 		projectService:   projectService,
 		taskQueryService: taskService,
+		userService:      userService,
 	}
 }
 
@@ -28,20 +30,38 @@ type ProjectHandler struct {
 	logger           *logrus.Logger
 	projectService   *ProjectService
 	taskQueryService types.TaskQueryService
+	userService      user.UserResolver
 }
 
-func RegisterProjectRoutes(r *gin.RouterGroup, handler *ProjectHandler, jwtManager *auth.JWTManager) {
+func RegisterProjectRoutes(r *gin.RouterGroup, handler *ProjectHandler, jwtManager *auth.JWTManager, orgResolver middleware.OrganizationResolver) {
 
-	projectGroup := r.Group("/projects", middleware.JWTAuthMiddleware(handler.logger, jwtManager))
+	projectGroup := r.Group("/projects", middleware.JWTAuthMiddleware(handler.logger, jwtManager), middleware.RequireScope(handler.logger, "projects"), middleware.RequireOrganization(handler.logger, orgResolver))
 	{
 		projectGroup.POST("/", handler.CreateProject)
 		projectGroup.GET("/:id", handler.GetProjectById)
 		projectGroup.GET("/", handler.GetProjectsByUserId)
 		projectGroup.PUT("/:id", handler.UpdateProject)
 		projectGroup.DELETE("/:id", handler.DeleteProject)
-		projectGroup.PATCH("/:id",handler.UpdateProject)
+		projectGroup.PATCH("/:id", handler.UpdateProject)
 		projectGroup.GET("/names/", handler.GetProjectByName)
 		projectGroup.GET("/:id/tasks", handler.GetTasksByProjectID)
+		projectGroup.GET("/:id/dependency-graph", handler.GetDependencyGraph)
+		projectGroup.GET("/:id/members", handler.ListProjectMembers)
+		projectGroup.POST("/:id/members", handler.AddProjectMember)
+		projectGroup.PATCH("/:id/members/:userId", handler.UpdateProjectMemberRole)
+		projectGroup.DELETE("/:id/members/:userId", handler.RemoveProjectMember)
+		projectGroup.GET("/:id/labels", handler.ListLabels)
+		projectGroup.POST("/:id/labels", handler.CreateLabel)
+		projectGroup.PATCH("/:id/labels/:labelId", handler.UpdateLabel)
+		projectGroup.DELETE("/:id/labels/:labelId", handler.DeleteLabel)
+		projectGroup.GET("/:id/custom-fields", handler.ListCustomFields)
+		projectGroup.POST("/:id/custom-fields", handler.CreateCustomField)
+		projectGroup.PATCH("/:id/custom-fields/:fieldId", handler.UpdateCustomField)
+		projectGroup.DELETE("/:id/custom-fields/:fieldId", handler.DeleteCustomField)
+		projectGroup.GET("/:id/workflow", handler.GetWorkflow)
+		projectGroup.PUT("/:id/workflow", handler.UpdateWorkflow)
+		projectGroup.GET("/:id/time", handler.GetProjectTime)
+		projectGroup.GET("/:id/activity", handler.GetProjectActivity)
 	}
 }
 
@@ -81,7 +101,12 @@ func (p *ProjectHandler) CreateProject(c *gin.Context) {
 		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
 		return
 	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	project.User = userID
+	project.Organization = orgID
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
@@ -113,6 +138,8 @@ func (p *ProjectHandler) CreateProject(c *gin.Context) {
 // @Param        id   path      int  true  "Project ID"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
 // @Router       /api/v1/projects/{id} [get]
 // @Security BearerAuth
 func (p *ProjectHandler) GetProjectById(c *gin.Context) {
@@ -123,13 +150,30 @@ func (p *ProjectHandler) GetProjectById(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidProjectId.Error())
 		return
 	}
+	val, exists := c.Get("userID")
+	if !exists {
+		p.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+
+	userID, ok := val.(int)
+	if !ok {
+		p.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	
-	project, err := p.projectService.GetProjectById(ctx, projectId)
+
+	project, err := p.projectService.GetProjectById(ctx, userID, orgID, projectId)
 	if err != nil {
 		p.logger.Errorf("%v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 	p.logger.Infof("Request Succeeded for %d", projectId)
@@ -164,13 +208,17 @@ func (p *ProjectHandler) GetProjectByName(c *gin.Context) {
 		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
 		return
 	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	projectName := c.Query("name")
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	project, err := p.projectService.GetProjectByName(ctx, projectName, userID)
+	project, err := p.projectService.GetProjectByName(ctx, orgID, projectName, userID)
 	if err != nil {
 		p.logger.Errorf("%v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 	utils.Success(c, http.StatusOK, map[string]any{
@@ -182,7 +230,7 @@ func (p *ProjectHandler) GetProjectByName(c *gin.Context) {
 
 // GetProjectsByUserId retrieves all projects for the authenticated user.
 // @Summary      Get all projects for user
-// @Description  Retrieves all projects for the authenticated user
+// @Description  Retrieves all projects owned by or shared with the authenticated user
 // @Tags         projects
 // @Produce      json
 // @Success      200  {object}  map[string]interface{}
@@ -204,9 +252,13 @@ func (p *ProjectHandler) GetProjectsByUserId(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, "invalid user ID type")
 		return
 	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	projects, err := p.projectService.GetProjectsByUserId(ctx, userID)
+	projects, err := p.projectService.GetProjectsByUserId(ctx, orgID, userID)
 	if err != nil {
 		p.logger.Errorf("%v", err)
 		utils.Error(c, http.StatusBadRequest, err.Error())
@@ -231,38 +283,55 @@ func (p *ProjectHandler) GetProjectsByUserId(c *gin.Context) {
 // @Param        project body      Project true  "Project update input"
 // @Success      200     {object}  map[string]interface{}
 // @Failure      400     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
 // @Router       /api/v1/projects/{id} [put]
 // @Security BearerAuth
 func (p *ProjectHandler) UpdateProject(c *gin.Context) {
-		var updateProjectRequest UpdateProjectRequest
-
-		err:=c.ShouldBindJSON(&updateProjectRequest)
-		if err!=nil{
-			p.logger.Errorf("%v",err)
-			utils.Error(c,http.StatusBadRequest,err.Error())
-			return 
-		}
-		id:=c.Param("id")
-		projectID,err:=strconv.Atoi(id)
-		if err!=nil{
-			p.logger.Errorf("%v",err)
-			utils.Error(c ,http.StatusBadRequest,customErrors.ErrInvalidProjectId.Error())
-			return 
-		}
-		updateProjectRequest.ProjectID=projectID
-		ctx,cancel:=context.WithTimeout(c.Request.Context(),5*time.Second)
-		defer cancel()
-		err=p.projectService.UpdateProject(ctx,updateProjectRequest)
-		if err!=nil{
-			p.logger.Errorf("%v",err)
-			utils.Error(c,http.StatusBadRequest,err.Error())
-			return 
-		}
-		p.logger.Info("project update call request is succeeded ")
-		utils.Success(c,http.StatusOK,map[string]any{
-			"message":"project updates successfully",
-		})
+	var updateProjectRequest UpdateProjectRequest
 
+	err := c.ShouldBindJSON(&updateProjectRequest)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	id := c.Param("id")
+	projectID, err := strconv.Atoi(id)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidProjectId.Error())
+		return
+	}
+	updateProjectRequest.ProjectID = projectID
+	val, exists := c.Get("userID")
+	if !exists {
+		p.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+	userID, ok := val.(int)
+	if !ok {
+		p.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	err = p.projectService.UpdateProject(ctx, userID, orgID, updateProjectRequest)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	p.logger.Info("project update call request is succeeded ")
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "project updates successfully",
+	})
 
 }
 
@@ -274,6 +343,8 @@ func (p *ProjectHandler) UpdateProject(c *gin.Context) {
 // @Param        id   path      int  true  "Project ID"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
 // @Router       /api/v1/projects/{id} [delete]
 // @Security BearerAuth
 func (p *ProjectHandler) DeleteProject(c *gin.Context) {
@@ -284,12 +355,29 @@ func (p *ProjectHandler) DeleteProject(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidProjectId.Error())
 		return
 	}
+	val, exists := c.Get("userID")
+	if !exists {
+		p.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+
+	userID, ok := val.(int)
+	if !ok {
+		p.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	err = p.projectService.DeleteProject(ctx, projectId)
+	err = p.projectService.DeleteProject(ctx, userID, orgID, projectId)
 	if err != nil {
 		p.logger.Errorf("%v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 	p.logger.Infof("Project %d deleted successfully", projectId)
@@ -308,6 +396,8 @@ func (p *ProjectHandler) DeleteProject(c *gin.Context) {
 // @Param        id   path      int  true  "Project ID"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
 // @Router       /api/v1/projects/{id}/tasks [get]
 // @Security BearerAuth
 func (p *ProjectHandler) GetTasksByProjectID(c *gin.Context) {
@@ -319,12 +409,29 @@ func (p *ProjectHandler) GetTasksByProjectID(c *gin.Context) {
 		return
 	}
 
+	val, exists := c.Get("userID")
+	if !exists {
+		p.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+
+	userID, ok := val.(int)
+	if !ok {
+		p.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	tasks, err := p.taskQueryService.GetTasksByProjectID(ctx, projectID)
+	tasks, err := p.taskQueryService.GetTasksByProjectID(ctx, userID, orgID, projectID)
 	if err != nil {
 		p.logger.Errorf("%v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 	p.logger.Infof("%v", tasks)
@@ -342,9 +449,301 @@ func (p *ProjectHandler) GetTasksByProjectID(c *gin.Context) {
 			"message": "projects are empty",
 		})
 	}
+	responses, err := p.taskQueryService.TaskResponses(ctx, tasks)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data":    responses,
+		"message": "request succeeded successfully",
+	})
+
+}
+
+// GetDependencyGraph returns the tasks of a project and the dependencies between them.
+// @Summary      Get the dependency graph of a project
+// @Description  Returns every task of the project with its blocked flag as nodes and every "task_id is blocked by blocked_by_id" dependency as edges, for critical-path views
+// @Tags         projects
+// @Produce      json
+// @Param        id   path      int  true  "Project ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/dependency-graph [get]
+// @Security BearerAuth
+func (p *ProjectHandler) GetDependencyGraph(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		p.logger.Errorf("%v", customErrors.ErrInvalidProjectId)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidProjectId.Error())
+		return
+	}
+	val, exists := c.Get("userID")
+	if !exists {
+		p.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+
+	userID, ok := val.(int)
+	if !ok {
+		p.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	graph, err := p.taskQueryService.DependencyGraph(ctx, userID, orgID, projectID)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data":    graph,
+		"message": "request succeeded successfully",
+	})
+}
+
+// AddProjectMember invites an existing user to the project by email.
+// @Summary      Add project member
+// @Description  Invites a user (looked up by email) to the project with the given role. Only owners may manage members.
+// @Tags         projects
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                      true  "Project ID"
+// @Param        member   body      AddProjectMemberRequest  true  "Member to invite"
+// @Success      201      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/members [post]
+// @Security BearerAuth
+func (p *ProjectHandler) AddProjectMember(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		p.logger.Errorf("%v", customErrors.ErrInvalidProjectId)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidProjectId.Error())
+		return
+	}
+	var request AddProjectMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	val, exists := c.Get("userID")
+	if !exists {
+		p.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+
+	userID, ok := val.(int)
+	if !ok {
+		p.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	invitee, err := p.userService.GetUserByEmail(ctx, request.Email)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	member, err := p.projectService.AddProjectMember(ctx, userID, orgID, projectID, int(invitee.ID), request.Role)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	p.logger.Infof("user %d added to project %d as %s", invitee.ID, projectID, member.Role)
+	utils.Success(c, http.StatusCreated, map[string]any{
+		"data":    member,
+		"message": "member added successfully",
+	})
+}
+
+// ListProjectMembers lists everyone who has access to the project.
+// @Summary      List project members
+// @Description  Lists the members of a project together with their roles
+// @Tags         projects
+// @Produce      json
+// @Param        id   path      int  true  "Project ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/members [get]
+// @Security BearerAuth
+func (p *ProjectHandler) ListProjectMembers(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		p.logger.Errorf("%v", customErrors.ErrInvalidProjectId)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidProjectId.Error())
+		return
+	}
+	val, exists := c.Get("userID")
+	if !exists {
+		p.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+
+	userID, ok := val.(int)
+	if !ok {
+		p.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	members, err := p.projectService.ListProjectMembers(ctx, userID, orgID, projectID)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
 	utils.Success(c, http.StatusOK, map[string]any{
-		"data":    tasks,
+		"data":    members,
 		"message": "request succeeded successfully",
 	})
+}
 
+// UpdateProjectMemberRole changes a member's role in the project.
+// @Summary      Update project member role
+// @Description  Changes the role of an existing project member. Only owners may manage members.
+// @Tags         projects
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                         true  "Project ID"
+// @Param        userId  path      int                         true  "Member user ID"
+// @Param        member  body      UpdateProjectMemberRequest  true  "New role"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/members/{userId} [patch]
+// @Security BearerAuth
+func (p *ProjectHandler) UpdateProjectMemberRole(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		p.logger.Errorf("%v", customErrors.ErrInvalidProjectId)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidProjectId.Error())
+		return
+	}
+	memberID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		p.logger.Errorf("%v", customErrors.ErrInvalidMemberID)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidMemberID.Error())
+		return
+	}
+	var request UpdateProjectMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	val, exists := c.Get("userID")
+	if !exists {
+		p.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+
+	userID, ok := val.(int)
+	if !ok {
+		p.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	err = p.projectService.UpdateProjectMemberRole(ctx, userID, orgID, projectID, memberID, request.Role)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "member role updated successfully",
+	})
+}
+
+// RemoveProjectMember revokes a member's access to the project.
+// @Summary      Remove project member
+// @Description  Removes a member from the project. Only owners may manage members.
+// @Tags         projects
+// @Produce      json
+// @Param        id      path      int  true  "Project ID"
+// @Param        userId  path      int  true  "Member user ID"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  map[string]interface{}
+// @Failure      403     {object}  map[string]interface{}
+// @Failure      404     {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/members/{userId} [delete]
+// @Security BearerAuth
+func (p *ProjectHandler) RemoveProjectMember(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		p.logger.Errorf("%v", customErrors.ErrInvalidProjectId)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidProjectId.Error())
+		return
+	}
+	memberID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		p.logger.Errorf("%v", customErrors.ErrInvalidMemberID)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidMemberID.Error())
+		return
+	}
+	val, exists := c.Get("userID")
+	if !exists {
+		p.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+
+	userID, ok := val.(int)
+	if !ok {
+		p.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	err = p.projectService.RemoveProjectMember(ctx, userID, orgID, projectID, memberID)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "member removed successfully",
+	})
 }
@@ -12,11 +12,14 @@ import (
 	"time"
 
 	"github.com/Gkemhcs/taskpilot/internal/auth"
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	"github.com/Gkemhcs/taskpilot/internal/middleware"
 	projectdb "github.com/Gkemhcs/taskpilot/internal/project/gen"
 	"github.com/Gkemhcs/taskpilot/internal/task"
 	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/user"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 
@@ -42,11 +45,18 @@ func SetupNewProjectHandler() (*ProjectHandler, *auth.JWTManager, *task.MockTask
 	//mocking  a task repo
 	taskMockRepo := new(task.MockTaskRepo)
 
+	// mocking the ownership lookups: project 999 belongs to another user,
+	// everything else belongs to the test user 1234
+	authzMockRepo := new(authz.MockAuthzRepo)
+	authzMockRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 999, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 999, UserID: 4321}, nil)
+	authzMockRepo.On("GetProjectRole", mock.Anything, mock.Anything).Return(authzdb.GetProjectRoleRow{UserID: 1234}, nil)
+	authorizer := authz.NewAuthorizationService(authzMockRepo)
+
 	//initialising the task query service
 
-	taskQueryService := task.NewTaskService(taskMockRepo)
+	taskQueryService := task.NewTaskService(taskMockRepo, authorizer)
 	// initialising project service to attach to handler
-	projectService := NewProjectService(projectMockRepo)
+	projectService := NewProjectService(projectMockRepo, authorizer)
 
 	// logger setup and disable logging to console/io
 
@@ -54,12 +64,26 @@ func SetupNewProjectHandler() (*ProjectHandler, *auth.JWTManager, *task.MockTask
 	logger.SetOutput(io.Discard)
 
 	// initialising project handler
-	projectHandler := NewProjectHandler(logger, projectService, taskQueryService)
+	// mocking a user repo for member invitations
+	userMockRepo := new(user.MockUserRepo)
+	userService := user.NewUserService(userMockRepo)
+
+	projectHandler := NewProjectHandler(logger, projectService, taskQueryService, userService)
 
 	return projectHandler, jwtManager, taskMockRepo, projectMockRepo, logger
 
 }
 
+// testOrgID is the organization every test request acts in
+const testOrgID = 7
+
+// organizationMiddleware resolves every request to testOrgID, as if it were the caller's personal workspace
+func organizationMiddleware(logger *logrus.Logger) gin.HandlerFunc {
+	resolver := new(middleware.MockOrganizationResolver)
+	resolver.On("ResolveOrganization", mock.Anything, mock.Anything, 0).Return(testOrgID, nil)
+	return middleware.RequireOrganization(logger, resolver)
+}
+
 // Simulated duplicate error
 func mockDuplicateError() error {
 	return &pq.Error{Code: "23505"}
@@ -72,6 +96,7 @@ func TestCreateProjectHandler(t *testing.T) {
 	jwtToken, err := jwtManager.GenerateAccessToken(1234, "test-user", "gudi@gmail.com")
 	require.NoError(t, err)
 	jwtMiddleware := middleware.JWTAuthMiddleware(logger, jwtManager)
+	orgMiddleware := organizationMiddleware(logger)
 
 	testCases := []struct {
 		testName            string
@@ -89,12 +114,14 @@ func TestCreateProjectHandler(t *testing.T) {
 			},
 			mockSetup: func() {
 				projectParams := projectdb.CreateProjectParams{
-					UserID:      int32(1234),
-					Name:        "implementing rbac for taskpilot",
-					Description: sql.NullString{String: "we need to implement rbac as a part of this project", Valid: true},
-					Color:       projectdb.NullProjectColor{ProjectColor: projectdb.ProjectColorGREEN, Valid: true},
+					OrganizationID: testOrgID,
+					UserID:         int32(1234),
+					Name:           "implementing rbac for taskpilot",
+					Description:    sql.NullString{String: "we need to implement rbac as a part of this project", Valid: true},
+					Color:          projectdb.NullProjectColor{ProjectColor: projectdb.ProjectColorGREEN, Valid: true},
 				}
 
+				projectMockRepo.On("AddProjectMember", mock.Anything, mock.Anything).Return(projectdb.ProjectMember{}, nil)
 				projectMockRepo.On("CreateProject", mock.Anything, projectParams).Return(
 					projectdb.Project{
 						UserID:      1234,
@@ -115,10 +142,11 @@ func TestCreateProjectHandler(t *testing.T) {
 			},
 			mockSetup: func() {
 				projectParams := projectdb.CreateProjectParams{
-					UserID:      int32(1234),
-					Name:        "implementing rbac for taskpilot",
-					Description: sql.NullString{String: "we need to implement rbac as a part of this project", Valid: true},
-					Color:       projectdb.NullProjectColor{ProjectColor: projectdb.ProjectColorGREEN, Valid: true},
+					OrganizationID: testOrgID,
+					UserID:         int32(1234),
+					Name:           "implementing rbac for taskpilot",
+					Description:    sql.NullString{String: "we need to implement rbac as a part of this project", Valid: true},
+					Color:          projectdb.NullProjectColor{ProjectColor: projectdb.ProjectColorGREEN, Valid: true},
 				}
 
 				projectMockRepo.On("CreateProject", mock.Anything, projectParams).Return(
@@ -153,7 +181,7 @@ func TestCreateProjectHandler(t *testing.T) {
 			apiGroup := r.Group("/api/v1")
 			userGroup := apiGroup.Group("/projects")
 
-			userGroup.POST("/", jwtMiddleware, projectHandler.CreateProject)
+			userGroup.POST("/", jwtMiddleware, orgMiddleware, projectHandler.CreateProject)
 			r.ServeHTTP(w, req)
 			if tc.expectedError != nil {
 
@@ -181,6 +209,7 @@ func TestGetProjectByIdHandler(t *testing.T) {
 
 	require.NoError(t, err)
 	jwtMiddleware := middleware.JWTAuthMiddleware(logger, jwtManager)
+	orgMiddleware := organizationMiddleware(logger)
 
 	testCases := []struct {
 		testName            string
@@ -217,11 +246,20 @@ func TestGetProjectByIdHandler(t *testing.T) {
 			mockSetup: func() {
 				projectMockRepo.On("GetProjectById", mock.Anything, int64(46)).Return(
 					projectdb.Project{},
-					customErrors.ErrProjectIDNotExist)
+					sql.ErrNoRows)
 			},
-			expectedStatusCode:  http.StatusBadRequest,
+			expectedStatusCode:  http.StatusNotFound,
 			expectedServiceCall: true,
 		},
+		{
+			testName:  "Project owned by another user",
+			projectId: 999,
+			mockSetup: func() {
+
+			},
+			expectedStatusCode:  http.StatusForbidden,
+			expectedServiceCall: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -246,7 +284,7 @@ func TestGetProjectByIdHandler(t *testing.T) {
 			apiGroup := r.Group("/api/v1")
 			userGroup := apiGroup.Group("/projects")
 
-			userGroup.GET("/:id", jwtMiddleware, projectHandler.GetProjectById)
+			userGroup.GET("/:id", jwtMiddleware, orgMiddleware, projectHandler.GetProjectById)
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, w.Code, tc.expectedStatusCode)
@@ -272,6 +310,7 @@ func TestGetProjectByNameHandler(t *testing.T) {
 
 	require.NoError(t, err)
 	jwtMiddleware := middleware.JWTAuthMiddleware(logger, jwtManager)
+	orgMiddleware := organizationMiddleware(logger)
 
 	testCases := []struct {
 		testName            string
@@ -285,8 +324,8 @@ func TestGetProjectByNameHandler(t *testing.T) {
 			projectname: "project-1",
 			mockSetup: func() {
 				params := projectdb.GetProjectByNameParams{
-					Name:   "project-1",
-					UserID: int32(1234),
+					OrganizationID: testOrgID,
+					Name:           "project-1",
 				}
 				projectMockRepo.On("GetProjectByName", mock.Anything, params).Return(
 					projectdb.Project{
@@ -302,8 +341,8 @@ func TestGetProjectByNameHandler(t *testing.T) {
 			projectname: "project-not-found",
 			mockSetup: func() {
 				params := projectdb.GetProjectByNameParams{
-					Name:   "project-not-found",
-					UserID: int32(1234),
+					OrganizationID: testOrgID,
+					Name:           "project-not-found",
 				}
 				projectMockRepo.On("GetProjectByName", mock.Anything, params).Return(
 					projectdb.Project{}, sql.ErrNoRows)
@@ -334,7 +373,7 @@ func TestGetProjectByNameHandler(t *testing.T) {
 			apiGroup := r.Group("/api/v1")
 			userGroup := apiGroup.Group("/projects")
 
-			userGroup.GET("/names", jwtMiddleware, projectHandler.GetProjectByName)
+			userGroup.GET("/names", jwtMiddleware, orgMiddleware, projectHandler.GetProjectByName)
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, w.Code, tc.expectedStatusCode)
@@ -355,6 +394,7 @@ func TestGetProjectsByUserIdHandler(t *testing.T) {
 
 	require.NoError(t, err)
 	jwtMiddleware := middleware.JWTAuthMiddleware(logger, jwtManager)
+	orgMiddleware := organizationMiddleware(logger)
 
 	testCases := []struct {
 		testName            string
@@ -365,7 +405,7 @@ func TestGetProjectsByUserIdHandler(t *testing.T) {
 		{
 			testName: "user containing projects",
 			mockSetup: func() {
-				projectMockRepo.On("GetProjectsByUserId", mock.Anything, int32(1234)).Return(
+				projectMockRepo.On("GetProjectsByUserId", mock.Anything, projectdb.GetProjectsByUserIdParams{OrganizationID: testOrgID, UserID: 1234}).Return(
 					[]projectdb.Project{
 						{
 							ID:   123,
@@ -383,7 +423,7 @@ func TestGetProjectsByUserIdHandler(t *testing.T) {
 		{
 			testName: "user doesnt contain any projects",
 			mockSetup: func() {
-				projectMockRepo.On("GetProjectsByUserId", mock.Anything, int32(1234)).Return([]projectdb.Project{}, sql.ErrNoRows)
+				projectMockRepo.On("GetProjectsByUserId", mock.Anything, projectdb.GetProjectsByUserIdParams{OrganizationID: testOrgID, UserID: 1234}).Return([]projectdb.Project{}, sql.ErrNoRows)
 			},
 			expectedStatusCode:  http.StatusBadRequest,
 			expectedServiceCall: true,
@@ -411,7 +451,7 @@ func TestGetProjectsByUserIdHandler(t *testing.T) {
 			apiGroup := r.Group("/api/v1")
 			userGroup := apiGroup.Group("/projects")
 
-			userGroup.GET("/", jwtMiddleware, projectHandler.GetProjectsByUserId)
+			userGroup.GET("/", jwtMiddleware, orgMiddleware, projectHandler.GetProjectsByUserId)
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, w.Code, tc.expectedStatusCode)
@@ -433,6 +473,7 @@ func TestDeleteProjectHandler(t *testing.T) {
 
 	require.NoError(t, err)
 	jwtMiddleware := middleware.JWTAuthMiddleware(logger, jwtManager)
+	orgMiddleware := organizationMiddleware(logger)
 	testCases := []struct {
 		testName            string
 		projectId           int
@@ -458,6 +499,15 @@ func TestDeleteProjectHandler(t *testing.T) {
 			expectedServiceCall: true,
 			expectedStatusCode:  http.StatusBadRequest,
 		},
+		{
+			testName:  "Project owned by another user",
+			projectId: 999,
+			mockSetup: func() {
+
+			},
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusForbidden,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -481,7 +531,7 @@ func TestDeleteProjectHandler(t *testing.T) {
 			apiGroup := r.Group("/api/v1")
 			userGroup := apiGroup.Group("/projects")
 
-			userGroup.DELETE("/:id", jwtMiddleware, projectHandler.DeleteProject)
+			userGroup.DELETE("/:id", jwtMiddleware, orgMiddleware, projectHandler.DeleteProject)
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, w.Code, tc.expectedStatusCode)
@@ -499,8 +549,6 @@ func TestDeleteProjectHandler(t *testing.T) {
 	}
 }
 
-
-
 func TestGetTasksByProjectIDHandler(t *testing.T) {
 	projectHandler, jwtManager, taskMockRepo, _, logger := SetupNewProjectHandler()
 
@@ -508,6 +556,7 @@ func TestGetTasksByProjectIDHandler(t *testing.T) {
 
 	require.NoError(t, err)
 	jwtMiddleware := middleware.JWTAuthMiddleware(logger, jwtManager)
+	orgMiddleware := organizationMiddleware(logger)
 	testCases := []struct {
 		testName            string
 		projectId           any
@@ -528,6 +577,7 @@ func TestGetTasksByProjectIDHandler(t *testing.T) {
 							Title: "task-2",
 						},
 					}, nil)
+				taskMockRepo.MockTaskResponses()
 			},
 			expectedServiceCall: true,
 			expectedStatusCode:  http.StatusOK,
@@ -536,7 +586,7 @@ func TestGetTasksByProjectIDHandler(t *testing.T) {
 			testName:  "valid project id ",
 			projectId: "try",
 			mockSetup: func() {
-				
+
 			},
 			expectedServiceCall: false,
 			expectedStatusCode:  http.StatusBadRequest,
@@ -546,7 +596,7 @@ func TestGetTasksByProjectIDHandler(t *testing.T) {
 			projectId: 24,
 			mockSetup: func() {
 				taskMockRepo.On("GetTasksByProjectId", mock.Anything, int64(24)).Return(
-					[]taskdb.Task{}, 
+					[]taskdb.Task{},
 					sql.ErrNoRows)
 			},
 			expectedServiceCall: true,
@@ -574,7 +624,7 @@ func TestGetTasksByProjectIDHandler(t *testing.T) {
 			apiGroup := r.Group("/api/v1")
 			userGroup := apiGroup.Group("/projects")
 
-			userGroup.GET("/:id/tasks", jwtMiddleware, projectHandler.GetTasksByProjectID)
+			userGroup.GET("/:id/tasks", jwtMiddleware, orgMiddleware, projectHandler.GetTasksByProjectID)
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, w.Code, tc.expectedStatusCode)
@@ -590,3 +640,84 @@ func TestGetTasksByProjectIDHandler(t *testing.T) {
 
 	}
 }
+
+func TestListProjectMembersHandler(t *testing.T) {
+	projectHandler, jwtManager, _, projectMockRepo, logger := SetupNewProjectHandler()
+
+	jwtToken, err := jwtManager.GenerateAccessToken(1234, "test-user", "gudi@gmail.com")
+
+	require.NoError(t, err)
+	jwtMiddleware := middleware.JWTAuthMiddleware(logger, jwtManager)
+	orgMiddleware := organizationMiddleware(logger)
+	testCases := []struct {
+		testName            string
+		projectId           any
+		mockSetup           func()
+		expectedServiceCall bool
+		expectedStatusCode  int
+	}{
+		{
+			testName:  "member lists project members",
+			projectId: 24,
+			mockSetup: func() {
+				projectMockRepo.On("ListProjectMembers", mock.Anything, int64(24)).Return(
+					[]projectdb.ListProjectMembersRow{
+						{ProjectID: 24, UserID: 1234, Role: projectdb.ProjectRoleOWNER},
+					}, nil)
+			},
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusOK,
+		},
+		{
+			testName:  "invalid project id",
+			projectId: "abc",
+			mockSetup: func() {
+
+			},
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusBadRequest,
+		},
+		{
+			testName:  "non member is forbidden",
+			projectId: 999,
+			mockSetup: func() {
+
+			},
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			projectMockRepo.Calls = nil
+			projectMockRepo.ExpectedCalls = nil
+			tc.mockSetup()
+
+			// Create request
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/projects/%v/members", tc.projectId), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+jwtToken)
+
+			// Recorder
+			w := httptest.NewRecorder()
+
+			// Set up full group routing like production
+			r := gin.Default()
+			apiGroup := r.Group("/api/v1")
+			userGroup := apiGroup.Group("/projects")
+
+			userGroup.GET("/:id/members", jwtMiddleware, orgMiddleware, projectHandler.ListProjectMembers)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, w.Code, tc.expectedStatusCode)
+
+			if tc.expectedServiceCall {
+				projectMockRepo.AssertCalled(t, "ListProjectMembers", mock.Anything, mock.Anything)
+			} else {
+				projectMockRepo.AssertNotCalled(t, "ListProjectMembers", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package project
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/middleware"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ListLabels lists the labels of a project.
+// @Summary      List project labels
+// @Description  Lists the labels defined on a project, ordered by name
+// @Tags         projects
+// @Produce      json
+// @Param        id   path      int  true  "Project ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/labels [get]
+// @Security BearerAuth
+func (p *ProjectHandler) ListLabels(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	labels, err := p.projectService.ListLabels(ctx, userID, orgID, projectID)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data":    labels,
+		"message": "request succeeded successfully",
+	})
+}
+
+// CreateLabel defines a new label on a project.
+// @Summary      Create project label
+// @Description  Defines a label with a name, unique in the project regardless of case, and a hex color. Editors and owners may manage labels.
+// @Tags         projects
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                 true  "Project ID"
+// @Param        label  body      CreateLabelRequest  true  "Label"
+// @Success      201    {object}  map[string]interface{}
+// @Failure      400    {object}  map[string]interface{}
+// @Failure      403    {object}  map[string]interface{}
+// @Failure      404    {object}  map[string]interface{}
+// @Failure      409    {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/labels [post]
+// @Security BearerAuth
+func (p *ProjectHandler) CreateLabel(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	var req CreateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	label, err := p.projectService.CreateLabel(ctx, userID, orgID, projectID, req)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusCreated, map[string]any{
+		"data":    label,
+		"message": "label created successfully",
+	})
+}
+
+// UpdateLabel renames or recolors a project label.
+// @Summary      Update project label
+// @Description  Changes the name or color of a label
+// @Tags         projects
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                 true  "Project ID"
+// @Param        labelId  path      int                 true  "Label ID"
+// @Param        label    body      UpdateLabelRequest  true  "Changes"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Failure      409      {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/labels/{labelId} [patch]
+// @Security BearerAuth
+func (p *ProjectHandler) UpdateLabel(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	labelID, ok := labelIDParam(c)
+	if !ok {
+		return
+	}
+	var req UpdateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	label, err := p.projectService.UpdateLabel(ctx, userID, orgID, projectID, labelID, req)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data":    label,
+		"message": "label updated successfully",
+	})
+}
+
+// DeleteLabel deletes a project label.
+// @Summary      Delete project label
+// @Description  Deletes a label and removes it from every task of the project
+// @Tags         projects
+// @Produce      json
+// @Param        id       path      int  true  "Project ID"
+// @Param        labelId  path      int  true  "Label ID"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]interface{}
+// @Failure      403      {object}  map[string]interface{}
+// @Failure      404      {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/labels/{labelId} [delete]
+// @Security BearerAuth
+func (p *ProjectHandler) DeleteLabel(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	labelID, ok := labelIDParam(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := p.projectService.DeleteLabel(ctx, userID, orgID, projectID, labelID); err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "label deleted successfully",
+	})
+}
+
+// projectScope reads the caller, their organization and the project id shared
+// by the routes under /projects/:id. When one is missing the error response is
+// written and ok is false.
+func (p *ProjectHandler) projectScope(c *gin.Context) (userID int, orgID int, projectID int, ok bool) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		p.logger.Errorf("%v", customErrors.ErrInvalidProjectId)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidProjectId.Error())
+		return 0, 0, 0, false
+	}
+	val, exists := c.Get("userID")
+	if !exists {
+		p.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return 0, 0, 0, false
+	}
+	userID, ok = val.(int)
+	if !ok {
+		p.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return 0, 0, 0, false
+	}
+	orgID, ok = middleware.OrganizationID(c)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	return userID, orgID, projectID, true
+}
+
+func labelIDParam(c *gin.Context) (int64, bool) {
+	labelID, err := strconv.ParseInt(c.Param("labelId"), 10, 64)
+	if err != nil || labelID <= 0 {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidLabelID.Error())
+		return 0, false
+	}
+	return labelID, true
+}
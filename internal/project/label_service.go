@@ -0,0 +1,134 @@
+package project
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	projectdb "github.com/Gkemhcs/taskpilot/internal/project/gen"
+)
+
+const (
+	// defaultLabelColor is used for labels created without a color.
+	defaultLabelColor = "#808080"
+	// maxLabelNameLength matches the labels.name column.
+	maxLabelNameLength = 50
+)
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// CreateLabel adds a label to the project; anyone who may edit its tasks may
+// define labels.
+func (p *ProjectService) CreateLabel(ctx context.Context, userID int, orgID int, projectID int, req CreateLabelRequest) (*projectdb.Label, error) {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionWrite); err != nil {
+		return nil, err
+	}
+	name, err := labelName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	color := defaultLabelColor
+	if req.Color != "" {
+		if color, err = labelColor(req.Color); err != nil {
+			return nil, err
+		}
+	}
+	label, err := p.projectRepository.CreateLabel(ctx, projectdb.CreateLabelParams{
+		ProjectID: int64(projectID),
+		Name:      name,
+		Color:     color,
+	})
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+		return nil, customErrors.ErrLabelAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// ListLabels returns the labels of the project ordered by name.
+func (p *ProjectService) ListLabels(ctx context.Context, userID int, orgID int, projectID int) ([]projectdb.Label, error) {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	labels, err := p.projectRepository.ListLabels(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+	if labels == nil {
+		labels = []projectdb.Label{}
+	}
+	return labels, nil
+}
+
+// UpdateLabel renames or recolors a label of the project.
+func (p *ProjectService) UpdateLabel(ctx context.Context, userID int, orgID int, projectID int, labelID int64, req UpdateLabelRequest) (*projectdb.Label, error) {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionWrite); err != nil {
+		return nil, err
+	}
+	params := projectdb.UpdateLabelParams{ID: labelID, ProjectID: int64(projectID)}
+	if req.Name != nil {
+		name, err := labelName(*req.Name)
+		if err != nil {
+			return nil, err
+		}
+		params.Name = sql.NullString{String: name, Valid: true}
+	}
+	if req.Color != nil {
+		color, err := labelColor(*req.Color)
+		if err != nil {
+			return nil, err
+		}
+		params.Color = sql.NullString{String: color, Valid: true}
+	}
+	label, err := p.projectRepository.UpdateLabel(ctx, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrLabelNotFound
+	}
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+		return nil, customErrors.ErrLabelAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// DeleteLabel deletes a label of the project and removes it from its tasks.
+func (p *ProjectService) DeleteLabel(ctx context.Context, userID int, orgID int, projectID int, labelID int64) error {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionWrite); err != nil {
+		return err
+	}
+	rows, err := p.projectRepository.DeleteLabel(ctx, projectdb.DeleteLabelParams{ID: labelID, ProjectID: int64(projectID)})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrLabelNotFound
+	}
+	return nil
+}
+
+// labelName trims a label name and checks its length. Commas are rejected
+// because the Excel importer separates label names with them.
+func labelName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxLabelNameLength || strings.Contains(name, ",") {
+		return "", customErrors.ErrInvalidLabelName
+	}
+	return name, nil
+}
+
+func labelColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if !labelColorPattern.MatchString(color) {
+		return "", customErrors.ErrInvalidLabelColor
+	}
+	return color, nil
+}
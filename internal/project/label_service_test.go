@@ -0,0 +1,103 @@
+package project
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	projectdb "github.com/Gkemhcs/taskpilot/internal/project/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLabels(t *testing.T) {
+	repo := new(MockProjectRepo)
+	authzRepo := new(authz.MockAuthzRepo)
+	service := NewProjectService(repo, authz.NewAuthorizationService(authzRepo))
+
+	// user 202 is an editor and user 303 a viewer of project 7
+	role := func(r authzdb.ProjectRole) authzdb.NullProjectRole {
+		return authzdb.NullProjectRole{ProjectRole: r, Valid: true}
+	}
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 7, UserID: 202, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 7, UserID: 101, Role: role(authzdb.ProjectRoleEDITOR)}, nil)
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 7, UserID: 303, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 7, UserID: 101, Role: role(authzdb.ProjectRoleVIEWER)}, nil)
+
+	t.Run("create a label", func(t *testing.T) {
+		testCases := []struct {
+			name           string
+			request        CreateLabelRequest
+			expectedParams *projectdb.CreateLabelParams
+			expectedError  error
+		}{
+			{
+				name:           "name is trimmed and color lower-cased",
+				request:        CreateLabelRequest{Name: " Bug ", Color: "#D73A4A"},
+				expectedParams: &projectdb.CreateLabelParams{ProjectID: 7, Name: "Bug", Color: "#d73a4a"},
+			},
+			{
+				name:           "color defaults to grey",
+				request:        CreateLabelRequest{Name: "chore"},
+				expectedParams: &projectdb.CreateLabelParams{ProjectID: 7, Name: "chore", Color: defaultLabelColor},
+			},
+			{name: "invalid color", request: CreateLabelRequest{Name: "bug", Color: "red"}, expectedError: customErrors.ErrInvalidLabelColor},
+			{name: "blank name", request: CreateLabelRequest{Name: "  "}, expectedError: customErrors.ErrInvalidLabelName},
+			{name: "names cannot contain commas", request: CreateLabelRequest{Name: "bug,urgent"}, expectedError: customErrors.ErrInvalidLabelName},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				if tc.expectedParams != nil {
+					repo.On("CreateLabel", mock.Anything, *tc.expectedParams).Return(projectdb.Label{ID: 1, ProjectID: 7, Name: tc.expectedParams.Name, Color: tc.expectedParams.Color}, nil).Once()
+				}
+				label, err := service.CreateLabel(context.TODO(), 202, testOrgID, 7, tc.request)
+				assert.Equal(t, tc.expectedError, err)
+				if tc.expectedParams != nil {
+					assert.Equal(t, tc.expectedParams.Color, label.Color)
+				}
+			})
+		}
+	})
+
+	t.Run("names are unique in the project", func(t *testing.T) {
+		repo.On("CreateLabel", mock.Anything, projectdb.CreateLabelParams{ProjectID: 7, Name: "BUG", Color: defaultLabelColor}).Return(projectdb.Label{}, mockDuplicateError()).Once()
+
+		_, err := service.CreateLabel(context.TODO(), 202, testOrgID, 7, CreateLabelRequest{Name: "BUG"})
+		assert.Equal(t, customErrors.ErrLabelAlreadyExists, err)
+	})
+
+	t.Run("viewers cannot manage labels", func(t *testing.T) {
+		_, err := service.CreateLabel(context.TODO(), 303, testOrgID, 7, CreateLabelRequest{Name: "bug"})
+		assert.Equal(t, customErrors.ErrForbidden, err)
+		assert.Equal(t, customErrors.ErrForbidden, service.DeleteLabel(context.TODO(), 303, testOrgID, 7, 1))
+	})
+
+	t.Run("viewers list labels", func(t *testing.T) {
+		repo.On("ListLabels", mock.Anything, int64(7)).Return([]projectdb.Label(nil), nil).Once()
+
+		labels, err := service.ListLabels(context.TODO(), 303, testOrgID, 7)
+		assert.NoError(t, err)
+		assert.NotNil(t, labels)
+	})
+
+	t.Run("recolor a label", func(t *testing.T) {
+		color := "#1F883D"
+		params := projectdb.UpdateLabelParams{ID: 1, ProjectID: 7, Color: sql.NullString{String: "#1f883d", Valid: true}}
+		repo.On("UpdateLabel", mock.Anything, params).Return(projectdb.Label{ID: 1, ProjectID: 7, Name: "bug", Color: "#1f883d"}, nil).Once()
+
+		label, err := service.UpdateLabel(context.TODO(), 202, testOrgID, 7, 1, UpdateLabelRequest{Color: &color})
+		assert.NoError(t, err)
+		assert.Equal(t, "#1f883d", label.Color)
+	})
+
+	t.Run("labels of another project are not found", func(t *testing.T) {
+		name := "feature"
+		repo.On("UpdateLabel", mock.Anything, projectdb.UpdateLabelParams{ID: 9, ProjectID: 7, Name: sql.NullString{String: name, Valid: true}}).Return(projectdb.Label{}, sql.ErrNoRows).Once()
+		repo.On("DeleteLabel", mock.Anything, projectdb.DeleteLabelParams{ID: 9, ProjectID: 7}).Return(int64(0), nil).Once()
+
+		_, err := service.UpdateLabel(context.TODO(), 202, testOrgID, 7, 9, UpdateLabelRequest{Name: &name})
+		assert.Equal(t, customErrors.ErrLabelNotFound, err)
+		assert.Equal(t, customErrors.ErrLabelNotFound, service.DeleteLabel(context.TODO(), 202, testOrgID, 7, 9))
+	})
+}
@@ -27,18 +27,92 @@ func (m *MockProjectRepo) GetProjectById(ctx context.Context, id int64) (project
 	return args.Get(0).(projectdb.Project), args.Error(1)
 }
 
-func (m *MockProjectRepo) GetProjectsByUserId(ctx context.Context, userID int32) ([]projectdb.Project, error) {
-	args := m.Called(ctx, userID)
+func (m *MockProjectRepo) GetProjectsByUserId(ctx context.Context, arg projectdb.GetProjectsByUserIdParams) ([]projectdb.Project, error) {
+	args := m.Called(ctx, arg)
 	return args.Get(0).([]projectdb.Project), args.Error(1)
 }
 
+func (m *MockProjectRepo) IsOrganizationMember(ctx context.Context, arg projectdb.IsOrganizationMemberParams) (bool, error) {
+	args := m.Called(ctx, arg)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockProjectRepo) GetProjectByName(ctx context.Context, params projectdb.GetProjectByNameParams) (projectdb.Project, error) {
 	args := m.Called(ctx, params)
 	return args.Get(0).(projectdb.Project), args.Error(1)
 }
 
-func (m *MockProjectRepo) UpdateProject(ctx context.Context, arg projectdb.UpdateProjectParams)  error{
-	args:=m.Called(ctx,arg)
+func (m *MockProjectRepo) UpdateProject(ctx context.Context, arg projectdb.UpdateProjectParams) error {
+	args := m.Called(ctx, arg)
 	return args.Error(0)
 }
 
+func (m *MockProjectRepo) AddProjectMember(ctx context.Context, arg projectdb.AddProjectMemberParams) (projectdb.ProjectMember, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(projectdb.ProjectMember), args.Error(1)
+}
+
+func (m *MockProjectRepo) ListProjectMembers(ctx context.Context, projectID int64) ([]projectdb.ListProjectMembersRow, error) {
+	args := m.Called(ctx, projectID)
+	return args.Get(0).([]projectdb.ListProjectMembersRow), args.Error(1)
+}
+
+func (m *MockProjectRepo) RemoveProjectMember(ctx context.Context, arg projectdb.RemoveProjectMemberParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockProjectRepo) UpdateProjectMemberRole(ctx context.Context, arg projectdb.UpdateProjectMemberRoleParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockProjectRepo) CreateLabel(ctx context.Context, arg projectdb.CreateLabelParams) (projectdb.Label, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(projectdb.Label), args.Error(1)
+}
+
+func (m *MockProjectRepo) ListLabels(ctx context.Context, projectID int64) ([]projectdb.Label, error) {
+	args := m.Called(ctx, projectID)
+	return args.Get(0).([]projectdb.Label), args.Error(1)
+}
+
+func (m *MockProjectRepo) UpdateLabel(ctx context.Context, arg projectdb.UpdateLabelParams) (projectdb.Label, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(projectdb.Label), args.Error(1)
+}
+
+func (m *MockProjectRepo) DeleteLabel(ctx context.Context, arg projectdb.DeleteLabelParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockProjectRepo) CreateCustomField(ctx context.Context, arg projectdb.CreateCustomFieldParams) (projectdb.CustomField, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(projectdb.CustomField), args.Error(1)
+}
+
+func (m *MockProjectRepo) ListCustomFields(ctx context.Context, projectID int64) ([]projectdb.CustomField, error) {
+	args := m.Called(ctx, projectID)
+	return args.Get(0).([]projectdb.CustomField), args.Error(1)
+}
+
+func (m *MockProjectRepo) UpdateCustomField(ctx context.Context, arg projectdb.UpdateCustomFieldParams) (projectdb.CustomField, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(projectdb.CustomField), args.Error(1)
+}
+
+func (m *MockProjectRepo) DeleteCustomField(ctx context.Context, arg projectdb.DeleteCustomFieldParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockProjectRepo) ListWorkflowStatuses(ctx context.Context, projectID int64) ([]projectdb.WorkflowStatus, error) {
+	args := m.Called(ctx, projectID)
+	return args.Get(0).([]projectdb.WorkflowStatus), args.Error(1)
+}
+
+func (m *MockProjectRepo) ReplaceWorkflow(ctx context.Context, arg projectdb.ReplaceWorkflowParams) ([]projectdb.WorkflowStatus, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]projectdb.WorkflowStatus), args.Error(1)
+}
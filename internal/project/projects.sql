@@ -1,16 +1,20 @@
 
 -- name: CreateProject :one
-INSERT INTO projects ( user_id, name, description, color) VALUES ($1,$2,$3,$4) RETURNING *;
+INSERT INTO projects (organization_id, user_id, name, description, color) VALUES ($1,$2,$3,$4,$5) RETURNING *;
 
 -- name: GetProjectById :one
 SELECT * FROM projects WHERE id=$1 ;
 
 -- name: GetProjectsByUserId :many
-SELECT * FROM projects WHERE user_id=$1 ORDER BY id;
+SELECT * FROM projects
+WHERE organization_id=$1
+  AND (user_id=$2
+   OR id IN (SELECT pm.project_id FROM project_members pm WHERE pm.user_id=$2))
+ORDER BY id;
 
 -- name: GetProjectByName :one
 
-SELECT * FROM projects WHERE name=$1 AND user_id=$2;
+SELECT * FROM projects WHERE organization_id=$1 AND name=$2;
 
 
 -- name: UpdateProject :exec
@@ -27,3 +31,102 @@ WHERE id = sqlc.arg('id');
 DELETE FROM projects WHERE id = $1;
 
 
+-- name: AddProjectMember :one
+INSERT INTO project_members (project_id, user_id, role) VALUES ($1, $2, $3) RETURNING *;
+
+-- name: ListProjectMembers :many
+SELECT pm.project_id, pm.user_id, pm.role, pm.created_at, u.name, u.email
+FROM project_members pm
+JOIN users u ON u.id = pm.user_id
+WHERE pm.project_id = $1
+ORDER BY pm.created_at, pm.user_id;
+
+-- name: UpdateProjectMemberRole :execrows
+UPDATE project_members
+SET role = $3, updated_at = now()
+WHERE project_id = $1 AND user_id = $2;
+
+-- name: RemoveProjectMember :execrows
+DELETE FROM project_members WHERE project_id = $1 AND user_id = $2;
+
+-- name: IsOrganizationMember :one
+SELECT EXISTS (
+  SELECT 1 FROM organization_members WHERE organization_id = $1 AND user_id = $2
+);
+
+-- name: CreateLabel :one
+INSERT INTO labels (project_id, name, color) VALUES ($1, $2, $3) RETURNING *;
+
+-- name: ListLabels :many
+SELECT * FROM labels WHERE project_id = $1 ORDER BY lower(name);
+
+-- name: UpdateLabel :one
+UPDATE labels
+SET
+  name = COALESCE(sqlc.narg('name'), name),
+  color = COALESCE(sqlc.narg('color'), color),
+  updated_at = now()
+WHERE id = sqlc.arg('id') AND project_id = sqlc.arg('project_id')
+RETURNING *;
+
+-- name: DeleteLabel :execrows
+DELETE FROM labels WHERE id = $1 AND project_id = $2;
+
+-- name: CreateCustomField :one
+INSERT INTO custom_fields (project_id, name, field_type, options) VALUES ($1, $2, $3, $4) RETURNING *;
+
+-- name: ListCustomFields :many
+SELECT * FROM custom_fields WHERE project_id = $1 ORDER BY id;
+
+-- name: UpdateCustomField :one
+-- values of select fields that are no longer among the options are dropped by
+-- the same statement
+WITH dropped AS (
+  DELETE FROM task_custom_field_values v
+  USING custom_fields f
+  WHERE f.id = sqlc.arg('id') AND f.project_id = sqlc.arg('project_id') AND v.field_id = f.id
+    AND f.field_type = 'single_select' AND NOT (v.value #>> '{}') = ANY(sqlc.narg('options')::text[])
+), trimmed AS (
+  UPDATE task_custom_field_values v
+  SET value = (
+    SELECT COALESCE(jsonb_agg(o), '[]'::jsonb) FROM jsonb_array_elements_text(v.value) o
+    WHERE o = ANY(sqlc.narg('options')::text[])
+  ), updated_at = now()
+  FROM custom_fields f
+  WHERE f.id = sqlc.arg('id') AND f.project_id = sqlc.arg('project_id') AND v.field_id = f.id
+    AND f.field_type = 'multi_select' AND sqlc.narg('options')::text[] IS NOT NULL
+)
+UPDATE custom_fields
+SET
+  name = COALESCE(sqlc.narg('name'), name),
+  options = COALESCE(sqlc.narg('options')::text[], options),
+  updated_at = now()
+WHERE id = sqlc.arg('id') AND project_id = sqlc.arg('project_id')
+RETURNING *;
+
+-- name: DeleteCustomField :execrows
+DELETE FROM custom_fields WHERE id = $1 AND project_id = $2;
+
+-- name: ListWorkflowStatuses :many
+SELECT * FROM workflow_statuses WHERE project_id = $1 ORDER BY position;
+
+-- name: ReplaceWorkflow :many
+-- statuses left out of the new workflow are removed by the same statement,
+-- which fails while tasks are still in them
+WITH input AS (
+  SELECT s.name, s.category, s.position, s.transitions
+  FROM jsonb_to_recordset(sqlc.arg('statuses')::jsonb)
+    AS s(name VARCHAR(30), category status_category, position INT, transitions TEXT[])
+), removed AS (
+  DELETE FROM workflow_statuses w
+  WHERE w.project_id = sqlc.arg('project_id') AND w.name NOT IN (SELECT name FROM input)
+)
+INSERT INTO workflow_statuses (project_id, name, category, position, transitions)
+SELECT sqlc.arg('project_id'), name, category, position, transitions FROM input
+ON CONFLICT (project_id, name) DO UPDATE
+SET
+  category = EXCLUDED.category,
+  position = EXCLUDED.position,
+  transitions = EXCLUDED.transitions,
+  updated_at = now()
+RETURNING *;
@@ -4,37 +4,72 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 
+	"github.com/Gkemhcs/taskpilot/internal/authz"
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	projectdb "github.com/Gkemhcs/taskpilot/internal/project/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
 	"github.com/lib/pq"
 )
 
-func NewProjectService(projectRepository projectdb.Querier) *ProjectService {
+func NewProjectService(projectRepository projectdb.Querier, authorizer authz.Authorizer) *ProjectService {
 	return &ProjectService{
 		projectRepository: projectRepository,
+		authorizer:        authorizer,
 	}
 }
 
 type ProjectService struct {
 	projectRepository projectdb.Querier
+	authorizer        authz.Authorizer // Role checks consulted before every read or write
+	transactor        types.Transactor // Runs the writes of one project together; nil runs them one by one
+}
+
+// UseTransactions writes a project and its owner membership in one
+// transaction of transactor. It is not safe to call once the service is in
+// use.
+func (p *ProjectService) UseTransactions(transactor types.Transactor) {
+	p.transactor = transactor
+}
+
+func (p *ProjectService) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if p.transactor == nil {
+		return fn(ctx)
+	}
+	return p.transactor.InTx(ctx, fn)
 }
 
 func (p *ProjectService) CreateProject(ctx context.Context, project Project) (*projectdb.Project, error) {
 
 	color := mapColor(project.Color)
 	projectParams := projectdb.CreateProjectParams{
-		UserID:      int32(project.User),
-		Name:        project.Name,
-		Description: sql.NullString{String: project.Description, Valid: project.Description != ""},
-		Color:       projectdb.NullProjectColor{ProjectColor: color, Valid: true},
+		OrganizationID: int64(project.Organization),
+		UserID:         int32(project.User),
+		Name:           project.Name,
+		Description:    sql.NullString{String: project.Description, Valid: project.Description != ""},
+		Color:          projectdb.NullProjectColor{ProjectColor: color, Valid: true},
 	}
 
-	proj, err := p.projectRepository.CreateProject(ctx, projectParams)
-	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+	var proj projectdb.Project
+	err := p.inTx(ctx, func(ctx context.Context) error {
+		var err error
+		proj, err = p.projectRepository.CreateProject(ctx, projectParams)
+		if IsErrorCode(err, customErrors.UniqueViolationErr) {
 
-		return nil, customErrors.ErrProjectAlreadyExists
-	}
+			return customErrors.ErrProjectAlreadyExists
+		}
+		if err != nil {
+			return err
+		}
+		// the creator is recorded as the first member so shared listings include them
+		_, err = p.projectRepository.AddProjectMember(ctx, projectdb.AddProjectMemberParams{
+			ProjectID: proj.ID,
+			UserID:    proj.UserID,
+			Role:      projectdb.ProjectRoleOWNER,
+		})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -42,13 +77,18 @@ func (p *ProjectService) CreateProject(ctx context.Context, project Project) (*p
 
 }
 
-func (p *ProjectService) DeleteProject(ctx context.Context, projectId int) error {
-
+func (p *ProjectService) DeleteProject(ctx context.Context, userID int, orgID int, projectId int) error {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectId, authz.ActionManage); err != nil {
+		return err
+	}
 	return p.projectRepository.DeleteProject(ctx, int64(projectId))
 
 }
 
-func (p *ProjectService) GetProjectById(ctx context.Context, projectId int) (*projectdb.Project, error) {
+func (p *ProjectService) GetProjectById(ctx context.Context, userID int, orgID int, projectId int) (*projectdb.Project, error) {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectId, authz.ActionRead); err != nil {
+		return nil, err
+	}
 	project, err := p.projectRepository.GetProjectById(ctx, int64(projectId))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, customErrors.ErrProjectIDNotExist
@@ -59,9 +99,14 @@ func (p *ProjectService) GetProjectById(ctx context.Context, projectId int) (*pr
 	return &project, nil
 
 }
-func (p *ProjectService) GetProjectsByUserId(ctx context.Context, userId int) ([]projectdb.Project, error) {
 
-	project, err := p.projectRepository.GetProjectsByUserId(ctx, int32(userId))
+// GetProjectsByUserId lists the projects userId owns or is a member of in the organization.
+func (p *ProjectService) GetProjectsByUserId(ctx context.Context, orgID int, userId int) ([]projectdb.Project, error) {
+
+	project, err := p.projectRepository.GetProjectsByUserId(ctx, projectdb.GetProjectsByUserIdParams{
+		OrganizationID: int64(orgID),
+		UserID:         int32(userId),
+	})
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, customErrors.ErrProjectIDNotExist
 	}
@@ -72,10 +117,12 @@ func (p *ProjectService) GetProjectsByUserId(ctx context.Context, userId int) ([
 
 }
 
-func (p *ProjectService) GetProjectByName(ctx context.Context, name string, userID int) (*projectdb.Project, error) {
+// GetProjectByName looks up a project by its name, which is unique within an
+// organization, and returns it if userID may read it.
+func (p *ProjectService) GetProjectByName(ctx context.Context, orgID int, name string, userID int) (*projectdb.Project, error) {
 	params := projectdb.GetProjectByNameParams{
-		Name:   name,
-		UserID: int32(userID),
+		OrganizationID: int64(orgID),
+		Name:           name,
 	}
 	project, err := p.projectRepository.GetProjectByName(ctx, params)
 
@@ -85,10 +132,19 @@ func (p *ProjectService) GetProjectByName(ctx context.Context, name string, user
 	if err != nil {
 		return nil, err
 	}
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, int(project.ID), authz.ActionRead); err != nil {
+		if errors.Is(err, customErrors.ErrProjectIDNotExist) {
+			return nil, customErrors.ErrProjectNotExist
+		}
+		return nil, err
+	}
 	return &project, nil
 }
 
-func (p *ProjectService) UpdateProject(ctx context.Context, projectUpdateRequest UpdateProjectRequest) error {
+func (p *ProjectService) UpdateProject(ctx context.Context, userID int, orgID int, projectUpdateRequest UpdateProjectRequest) error {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectUpdateRequest.ProjectID, authz.ActionWrite); err != nil {
+		return err
+	}
 
 	var updateRequestParams projectdb.UpdateProjectParams
 	if projectUpdateRequest.Color != nil {
@@ -125,7 +181,7 @@ func (p *ProjectService) UpdateProject(ctx context.Context, projectUpdateRequest
 	}
 	updateRequestParams.ID = int64(projectUpdateRequest.ProjectID)
 
-	err := p.projectRepository.UpdateProject(ctx, updateRequestParams) 
+	err := p.projectRepository.UpdateProject(ctx, updateRequestParams)
 	if IsErrorCode(err, customErrors.UniqueViolationErr) {
 
 		return customErrors.ErrTaskAlreadyExists
@@ -136,6 +192,129 @@ func (p *ProjectService) UpdateProject(ctx context.Context, projectUpdateRequest
 	return nil
 }
 
+// AddProjectMember invites memberID into the project with the given role.
+// Only owners may manage membership and the invitee must already belong to
+// the project's organization.
+func (p *ProjectService) AddProjectMember(ctx context.Context, userID int, orgID int, projectID int, memberID int, role string) (*projectdb.ProjectMember, error) {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionManage); err != nil {
+		return nil, err
+	}
+	projectRole, err := mapRole(role)
+	if err != nil {
+		return nil, err
+	}
+	inOrganization, err := p.projectRepository.IsOrganizationMember(ctx, projectdb.IsOrganizationMemberParams{
+		OrganizationID: int64(orgID),
+		UserID:         int32(memberID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !inOrganization {
+		return nil, customErrors.ErrNotOrganizationMember
+	}
+	member, err := p.projectRepository.AddProjectMember(ctx, projectdb.AddProjectMemberParams{
+		ProjectID: int64(projectID),
+		UserID:    int32(memberID),
+		Role:      projectRole,
+	})
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+		return nil, customErrors.ErrProjectMemberAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListProjectMembers returns every member of the project; any member may view the list.
+func (p *ProjectService) ListProjectMembers(ctx context.Context, userID int, orgID int, projectID int) ([]projectdb.ListProjectMembersRow, error) {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	return p.projectRepository.ListProjectMembers(ctx, int64(projectID))
+}
+
+// UpdateProjectMemberRole changes the role of an existing member.
+// The project creator always stays owner.
+func (p *ProjectService) UpdateProjectMemberRole(ctx context.Context, userID int, orgID int, projectID int, memberID int, role string) error {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionManage); err != nil {
+		return err
+	}
+	projectRole, err := mapRole(role)
+	if err != nil {
+		return err
+	}
+	if err := p.ensureNotCreator(ctx, projectID, memberID); err != nil {
+		return err
+	}
+	rows, err := p.projectRepository.UpdateProjectMemberRole(ctx, projectdb.UpdateProjectMemberRoleParams{
+		ProjectID: int64(projectID),
+		UserID:    int32(memberID),
+		Role:      projectRole,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrProjectMemberNotFound
+	}
+	return nil
+}
+
+// RemoveProjectMember revokes memberID's access to the project.
+// The project creator cannot be removed.
+func (p *ProjectService) RemoveProjectMember(ctx context.Context, userID int, orgID int, projectID int, memberID int) error {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionManage); err != nil {
+		return err
+	}
+	if err := p.ensureNotCreator(ctx, projectID, memberID); err != nil {
+		return err
+	}
+	rows, err := p.projectRepository.RemoveProjectMember(ctx, projectdb.RemoveProjectMemberParams{
+		ProjectID: int64(projectID),
+		UserID:    int32(memberID),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrProjectMemberNotFound
+	}
+	return nil
+}
+
+// ensureNotCreator rejects membership changes that target the user recorded in projects.user_id.
+func (p *ProjectService) ensureNotCreator(ctx context.Context, projectID int, memberID int) error {
+	project, err := p.projectRepository.GetProjectById(ctx, int64(projectID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return customErrors.ErrProjectIDNotExist
+	}
+	if err != nil {
+		return err
+	}
+	if int(project.UserID) == memberID {
+		return customErrors.ErrCannotModifyProjectOwner
+	}
+	return nil
+}
+
+// mapRole converts a role from the API (owner, editor, viewer, commenter) into its database value.
+func mapRole(role string) (projectdb.ProjectRole, error) {
+	switch strings.ToLower(role) {
+	case "owner":
+		return projectdb.ProjectRoleOWNER, nil
+	case "editor":
+		return projectdb.ProjectRoleEDITOR, nil
+	case "viewer":
+		return projectdb.ProjectRoleVIEWER, nil
+	case "commenter":
+		return projectdb.ProjectRoleCOMMENTER, nil
+	default:
+		return "", customErrors.ErrInvalidProjectRole
+	}
+}
+
 func mapColor(color string) projectdb.ProjectColor {
 	switch color {
 	case "green":
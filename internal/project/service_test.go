@@ -7,18 +7,27 @@ import (
 	"os"
 	"testing"
 
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	projectdb "github.com/Gkemhcs/taskpilot/internal/project/gen"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 )
 
-
-var projectService  *ProjectService 
+var projectService *ProjectService
 var mockRepo *MockProjectRepo
-func TestMain(m *testing.M){
-	mockRepo=new(MockProjectRepo)
-	projectService=NewProjectService(mockRepo)
+
+// newOwnerAuthorizer returns an authorizer for which every project belongs to ownerID.
+func newOwnerAuthorizer(ownerID int32) authz.Authorizer {
+	authzRepo := new(authz.MockAuthzRepo)
+	authzRepo.On("GetProjectRole", mock.Anything, mock.Anything).Return(authzdb.GetProjectRoleRow{UserID: ownerID}, nil)
+	return authz.NewAuthorizationService(authzRepo)
+}
+
+func TestMain(m *testing.M) {
+	mockRepo = new(MockProjectRepo)
+	projectService = NewProjectService(mockRepo, newOwnerAuthorizer(101))
 	os.Exit(m.Run())
 
 }
@@ -133,7 +142,7 @@ func TestCreateProject(t *testing.T) {
 			input: Project{
 				Name:        "Empty Color",
 				Description: "koti",
-				Color:        "",
+				Color:       "",
 				User:        101,
 			},
 			expectedParams: projectdb.CreateProjectParams{
@@ -158,37 +167,68 @@ func TestCreateProject(t *testing.T) {
 			if !tc.expectError {
 				mockRepo.On("CreateProject", mock.Anything, tc.expectedParams).
 					Return(tc.expectedProject, nil)
-			}else{
-				mockRepo.On("CreateProject",mock.Anything,tc.expectedParams).Return(nil,errors.New("error"))
+				mockRepo.On("AddProjectMember", mock.Anything, projectdb.AddProjectMemberParams{
+					ProjectID: tc.expectedProject.ID,
+					UserID:    tc.expectedProject.UserID,
+					Role:      projectdb.ProjectRoleOWNER,
+				}).Return(projectdb.ProjectMember{}, nil)
+			} else {
+				mockRepo.On("CreateProject", mock.Anything, tc.expectedParams).Return(nil, errors.New("error"))
 			}
 			result, err := projectService.CreateProject(tc.ctx, tc.input)
 
 			if tc.expectError {
 				assert.Error(t, err)
-				
+
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tc.expectedProject.ID, result.ID)
 				assert.Equal(t, tc.expectedProject.Name, result.Name)
-				
-				if tc.input.Description==""{
-					assert.Equal(t,tc.expectedParams.Description.Valid,false)
+
+				if tc.input.Description == "" {
+					assert.Equal(t, tc.expectedParams.Description.Valid, false)
 				}
 
-				if tc.input.Color==""{
-					expectedColor:= projectdb.NullProjectColor(projectdb.NullProjectColor{ProjectColor:"RED", Valid:true})
-					assert.Equal(t,tc.expectedParams.Color,expectedColor)
+				if tc.input.Color == "" {
+					expectedColor := projectdb.NullProjectColor(projectdb.NullProjectColor{ProjectColor: "RED", Valid: true})
+					assert.Equal(t, tc.expectedParams.Color, expectedColor)
 				}
-				
+
 				mockRepo.AssertCalled(t, "CreateProject", mock.Anything, tc.expectedParams)
 			}
 		})
 	}
 }
 
+// fakeTransactor marks the context it runs fn with and remembers what fn returned.
+type fakeTransactor struct{ err error }
+
+type txKey struct{}
+
+func (f *fakeTransactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.err = fn(context.WithValue(ctx, txKey{}, true))
+	return f.err
+}
+
+func TestCreateProjectInOneTransaction(t *testing.T) {
+	mockRepo := new(MockProjectRepo)
+	projectService := NewProjectService(mockRepo, newOwnerAuthorizer(101))
+	transactor := &fakeTransactor{}
+	projectService.UseTransactions(transactor)
+	inTx := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(txKey{}) != nil })
+	failed := errors.New("membership failed")
+	mockRepo.On("CreateProject", inTx, mock.Anything).Return(projectdb.Project{ID: 1, UserID: 101}, nil)
+	mockRepo.On("AddProjectMember", inTx, mock.Anything).Return(projectdb.ProjectMember{}, failed)
+
+	_, err := projectService.CreateProject(context.TODO(), Project{Name: "Green Project", User: 101})
+	assert.Equal(t, failed, err)
+	assert.Equal(t, failed, transactor.err, "the failed write must roll the transaction back")
+	mockRepo.AssertExpectations(t)
+}
+
 func TestGetProjectById(t *testing.T) {
 	mockRepo := new(MockProjectRepo)
-	projectService := NewProjectService(mockRepo)
+	projectService := NewProjectService(mockRepo, newOwnerAuthorizer(101))
 
 	testCases := []struct {
 		name          string
@@ -239,10 +279,10 @@ func TestGetProjectById(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			
+
 			tc.mockSetup()
 
-			project, err := projectService.GetProjectById(context.TODO(), tc.projectID)
+			project, err := projectService.GetProjectById(context.TODO(), 101, testOrgID, tc.projectID)
 
 			if tc.expectedError != nil {
 				assert.Error(t, err)
@@ -257,20 +297,19 @@ func TestGetProjectById(t *testing.T) {
 	}
 }
 
-
 func TestGetProjectsByUserId(t *testing.T) {
 	testCases := []struct {
 		name           string
-		userID          int
+		userID         int
 		mockSetup      func()
 		expectedError  error
 		expectedResult []projectdb.Project
 	}{
 		{
-			name:  "Valid User ID",
+			name:   "Valid User ID",
 			userID: 23,
 			mockSetup: func() {
-				mockRepo.On("GetProjectsByUserId",context.TODO(), int32(23)).Return(
+				mockRepo.On("GetProjectsByUserId", context.TODO(), projectdb.GetProjectsByUserIdParams{OrganizationID: testOrgID, UserID: 23}).Return(
 					[]projectdb.Project{
 						{
 							ID:     1,
@@ -280,7 +319,7 @@ func TestGetProjectsByUserId(t *testing.T) {
 					}, nil,
 				)
 			},
-			expectedError:  nil,
+			expectedError: nil,
 			expectedResult: []projectdb.Project{
 				{
 					ID:     1,
@@ -290,48 +329,47 @@ func TestGetProjectsByUserId(t *testing.T) {
 			},
 		},
 		{
-			name:"Non Existent User Id",
-			userID:3899,
+			name:   "Non Existent User Id",
+			userID: 3899,
 			mockSetup: func() {
-				mockRepo.On("GetProjectsByUserId",context.TODO(),int32(3899)).Return(
-					[]projectdb.Project{},customErrors.ErrUserNotExist,
+				mockRepo.On("GetProjectsByUserId", context.TODO(), projectdb.GetProjectsByUserIdParams{OrganizationID: testOrgID, UserID: 3899}).Return(
+					[]projectdb.Project{}, customErrors.ErrUserNotExist,
 				)
 
 			},
-			expectedError: customErrors.ErrUserNotExist ,
+			expectedError:  customErrors.ErrUserNotExist,
 			expectedResult: []projectdb.Project{},
 		},
 		{
-			name:"Error",
-			userID:02020,
+			name:   "Error",
+			userID: 02020,
 			mockSetup: func() {
-				mockRepo.On("GetProjectsByUserId",context.TODO(),int32(02020)).Return(
-					[]projectdb.Project{},errors.New("db error"),
+				mockRepo.On("GetProjectsByUserId", context.TODO(), projectdb.GetProjectsByUserIdParams{OrganizationID: testOrgID, UserID: 02020}).Return(
+					[]projectdb.Project{}, errors.New("db error"),
 				)
 
 			},
-			expectedError: errors.New("db error") ,
+			expectedError:  errors.New("db error"),
 			expectedResult: []projectdb.Project{},
 		},
 	}
 
-
-	for _,tc := range testCases{
+	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-		
-		tc.mockSetup()
-		projects, err := projectService.GetProjectsByUserId(context.TODO(), tc.userID)
-
-		if tc.expectedError != nil {
-			assert.Equal(t, tc.expectedError.Error(), err.Error()) // compare error values properly
-			assert.Empty(t, projects)
-		} else {
-			assert.NoError(t, err)
-			assert.Equal(t, tc.expectedResult, projects)
-		}
-
-		mockRepo.AssertCalled(t, "GetProjectsByUserId", mock.Anything, int32(tc.userID))
-	})
+
+			tc.mockSetup()
+			projects, err := projectService.GetProjectsByUserId(context.TODO(), testOrgID, tc.userID)
+
+			if tc.expectedError != nil {
+				assert.Equal(t, tc.expectedError.Error(), err.Error()) // compare error values properly
+				assert.Empty(t, projects)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, projects)
+			}
+
+			mockRepo.AssertCalled(t, "GetProjectsByUserId", mock.Anything, projectdb.GetProjectsByUserIdParams{OrganizationID: testOrgID, UserID: int32(tc.userID)})
+		})
 	}
 	// You may want to add the test loop here to run the test cases
 }
@@ -339,15 +377,15 @@ func TestGetProjectsByUserId(t *testing.T) {
 func TestGetProjectByName(t *testing.T) {
 	testCases := []struct {
 		name           string
-		userID 			int 
-		projectName 	string
+		userID         int
+		projectName    string
 		mockSetup      func(params projectdb.GetProjectByNameParams)
 		expectedError  error
 		expectedResult *projectdb.Project
 	}{
 		{
-			name: "Valid Project Name",
-			userID:344,
+			name:        "Valid Project Name",
+			userID:      101,
 			projectName: "Valid project name",
 			mockSetup: func(params projectdb.GetProjectByNameParams) {
 				mockRepo.On("GetProjectByName", context.TODO(), params).Return(projectdb.Project{
@@ -359,7 +397,7 @@ func TestGetProjectByName(t *testing.T) {
 					},
 				}, nil)
 			},
-			expectedError:  nil,
+			expectedError: nil,
 			expectedResult: &projectdb.Project{
 				Name:   "metrics dashboard setup",
 				UserID: 23,
@@ -370,39 +408,37 @@ func TestGetProjectByName(t *testing.T) {
 			},
 		},
 		{
-			name: "Non Existent Project Name",
-			userID: 672,
+			name:        "Non Existent Project Name",
+			userID:      672,
 			projectName: "Non Existent Project Name",
 			mockSetup: func(params projectdb.GetProjectByNameParams) {
 				mockRepo.On("GetProjectByName", context.TODO(), params).Return(projectdb.Project{}, customErrors.ErrProjectNotExist)
 
 			},
 			expectedError:  customErrors.ErrProjectNotExist,
-			expectedResult:nil,
-			},
-			{
-			name: "Db Error",
-			userID: 672,
+			expectedResult: nil,
+		},
+		{
+			name:        "Db Error",
+			userID:      672,
 			projectName: "Sample Project",
 			mockSetup: func(params projectdb.GetProjectByNameParams) {
 				mockRepo.On("GetProjectByName", context.TODO(), params).Return(projectdb.Project{}, errors.New("db error"))
 
 			},
 			expectedError:  errors.New("db error"),
-			expectedResult:nil,
-			},
-			
-		}
-	
+			expectedResult: nil,
+		},
+	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			params:=projectdb.GetProjectByNameParams{
-				UserID: int32(tc.userID),
-				Name: tc.projectName,
+			params := projectdb.GetProjectByNameParams{
+				OrganizationID: testOrgID,
+				Name:           tc.projectName,
 			}
 			tc.mockSetup(params)
-			result, err := projectService.GetProjectByName(context.TODO(),tc.projectName,tc.userID)
+			result, err := projectService.GetProjectByName(context.TODO(), testOrgID, tc.projectName, tc.userID)
 			if tc.expectedError != nil {
 				assert.Error(t, err)
 				assert.Nil(t, result)
@@ -415,77 +451,169 @@ func TestGetProjectByName(t *testing.T) {
 	}
 }
 
+func TestUpdateProject(t *testing.T) {
 
-
-func TestUpdateProject(t *testing.T){
-
-
-	testCases:=[]struct{
-			testName string 
-			req UpdateProjectRequest
-			expectedParams projectdb.UpdateProjectParams
-			expectedError error
+	testCases := []struct {
+		testName       string
+		req            UpdateProjectRequest
+		expectedParams projectdb.UpdateProjectParams
+		expectedError  error
 	}{
-			{
-				testName: "Valid project update",
-				req: UpdateProjectRequest{
-					ProjectID: 1234,
-					Name: func( s string)*string{return &s}("starpilot project"),
-					Color: func( s string)*string{return &s}("yellow"),
+		{
+			testName: "Valid project update",
+			req: UpdateProjectRequest{
+				ProjectID: 1234,
+				Name:      func(s string) *string { return &s }("starpilot project"),
+				Color:     func(s string) *string { return &s }("yellow"),
+			},
+			expectedParams: projectdb.UpdateProjectParams{
+				Name: sql.NullString{
+					String: "starpilot project",
+					Valid:  true,
 				},
-				expectedParams: projectdb.UpdateProjectParams{
-					Name: sql.NullString{
-						String: "starpilot project",
-						Valid: true,
-					},
-					ID: 1234,
-					Color: projectdb.NullProjectColor{
-						ProjectColor: projectdb.ProjectColorYELLOW,
-						Valid: true,
-					},
+				ID: 1234,
+				Color: projectdb.NullProjectColor{
+					ProjectColor: projectdb.ProjectColorYELLOW,
+					Valid:        true,
 				},
-				expectedError: nil ,
 			},
-			{
-				testName: "db connection error",
-				req: UpdateProjectRequest{
-					ProjectID: 1234,
-					Name: func( s string)*string{return &s}("starpilot project"),
-					Color: func( s string)*string{return &s}("yellow"),
+			expectedError: nil,
+		},
+		{
+			testName: "db connection error",
+			req: UpdateProjectRequest{
+				ProjectID: 1234,
+				Name:      func(s string) *string { return &s }("starpilot project"),
+				Color:     func(s string) *string { return &s }("yellow"),
+			},
+			expectedParams: projectdb.UpdateProjectParams{
+				Name: sql.NullString{
+					String: "starpilot project",
+					Valid:  true,
 				},
-				expectedParams: projectdb.UpdateProjectParams{
-					Name: sql.NullString{
-						String: "starpilot project",
-						Valid: true,
-					},
-					ID: 1234,
-					Color: projectdb.NullProjectColor{
-						ProjectColor: projectdb.ProjectColorYELLOW,
-						Valid: true,
-					},
+				ID: 1234,
+				Color: projectdb.NullProjectColor{
+					ProjectColor: projectdb.ProjectColorYELLOW,
+					Valid:        true,
 				},
-				expectedError: errors.New("db error") ,
 			},
+			expectedError: errors.New("db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			mockRepo.Calls = nil
+			mockRepo.ExpectedCalls = nil
 
-			
+			mockRepo.On("UpdateProject", mock.Anything, tc.expectedParams).Return(tc.expectedError)
+
+			err := projectService.UpdateProject(context.TODO(), 101, testOrgID, tc.req)
+
+			assert.Equal(t, err, tc.expectedError)
+
+			mockRepo.AssertCalled(t, "UpdateProject", mock.Anything, tc.expectedParams)
+
+		})
 	}
 
-	for _,tc := range testCases{
-		t.Run(tc.testName,func(t *testing.T){
-				mockRepo.Calls=nil 
-				mockRepo.ExpectedCalls=nil 
+}
 
-				mockRepo.On("UpdateProject",mock.Anything,tc.expectedParams).Return(tc.expectedError)
+func TestProjectAccess(t *testing.T) {
+	repo := new(MockProjectRepo)
+	service := NewProjectService(repo, newOwnerAuthorizer(4321))
 
+	_, err := service.GetProjectById(context.TODO(), 101, testOrgID, 7)
+	assert.Equal(t, customErrors.ErrForbidden, err)
 
-				err:=projectService.UpdateProject(context.TODO(),tc.req)
+	err = service.DeleteProject(context.TODO(), 101, testOrgID, 7)
+	assert.Equal(t, customErrors.ErrForbidden, err)
 
-				assert.Equal(t,err,tc.expectedError)
+	err = service.UpdateProject(context.TODO(), 101, testOrgID, UpdateProjectRequest{ProjectID: 7})
+	assert.Equal(t, customErrors.ErrForbidden, err)
 
-				mockRepo.AssertCalled(t,"UpdateProject",mock.Anything,tc.expectedParams)
+	repo.AssertNotCalled(t, "GetProjectById", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "DeleteProject", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "UpdateProject", mock.Anything, mock.Anything)
+}
 
+func TestProjectMembers(t *testing.T) {
+	repo := new(MockProjectRepo)
+	authzRepo := new(authz.MockAuthzRepo)
+	service := NewProjectService(repo, authz.NewAuthorizationService(authzRepo))
+
+	editor := authzdb.NullProjectRole{ProjectRole: authzdb.ProjectRoleEDITOR, Valid: true}
+	// project 7 was created by user 101; user 202 is an editor
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 7, UserID: 101, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 7, UserID: 101}, nil)
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 7, UserID: 202, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 7, UserID: 101, Role: editor}, nil)
+	repo.On("GetProjectById", mock.Anything, int64(7)).Return(projectdb.Project{ID: 7, UserID: 101}, nil)
+	// user 505 has an account but is not in the project's organization
+	repo.On("IsOrganizationMember", mock.Anything, projectdb.IsOrganizationMemberParams{OrganizationID: testOrgID, UserID: 505}).Return(false, nil)
+	repo.On("IsOrganizationMember", mock.Anything, mock.Anything).Return(true, nil)
+
+	t.Run("owner invites a viewer", func(t *testing.T) {
+		params := projectdb.AddProjectMemberParams{ProjectID: 7, UserID: 303, Role: projectdb.ProjectRoleVIEWER}
+		repo.On("AddProjectMember", mock.Anything, params).Return(projectdb.ProjectMember{ProjectID: 7, UserID: 303, Role: projectdb.ProjectRoleVIEWER}, nil).Once()
+
+		member, err := service.AddProjectMember(context.TODO(), 101, testOrgID, 7, 303, "Viewer")
+		assert.NoError(t, err)
+		assert.Equal(t, projectdb.ProjectRoleVIEWER, member.Role)
+	})
 
-		})
-	}
+	t.Run("inviting an existing member is rejected", func(t *testing.T) {
+		params := projectdb.AddProjectMemberParams{ProjectID: 7, UserID: 202, Role: projectdb.ProjectRoleEDITOR}
+		repo.On("AddProjectMember", mock.Anything, params).Return(projectdb.ProjectMember{}, mockDuplicateError()).Once()
+
+		_, err := service.AddProjectMember(context.TODO(), 101, testOrgID, 7, 202, "editor")
+		assert.Equal(t, customErrors.ErrProjectMemberAlreadyExists, err)
+	})
+
+	t.Run("users outside the organization cannot be invited", func(t *testing.T) {
+		_, err := service.AddProjectMember(context.TODO(), 101, testOrgID, 7, 505, "viewer")
+		assert.Equal(t, customErrors.ErrNotOrganizationMember, err)
+		repo.AssertNotCalled(t, "AddProjectMember", mock.Anything, projectdb.AddProjectMemberParams{ProjectID: 7, UserID: 505, Role: projectdb.ProjectRoleVIEWER})
+	})
+
+	t.Run("unknown role is rejected", func(t *testing.T) {
+		_, err := service.AddProjectMember(context.TODO(), 101, testOrgID, 7, 303, "admin")
+		assert.Equal(t, customErrors.ErrInvalidProjectRole, err)
+	})
+
+	t.Run("editor cannot manage members", func(t *testing.T) {
+		_, err := service.AddProjectMember(context.TODO(), 202, testOrgID, 7, 303, "viewer")
+		assert.Equal(t, customErrors.ErrForbidden, err)
+		err = service.RemoveProjectMember(context.TODO(), 202, testOrgID, 7, 303)
+		assert.Equal(t, customErrors.ErrForbidden, err)
+	})
 
-}
\ No newline at end of file
+	t.Run("creator cannot be demoted or removed", func(t *testing.T) {
+		err := service.UpdateProjectMemberRole(context.TODO(), 101, testOrgID, 7, 101, "viewer")
+		assert.Equal(t, customErrors.ErrCannotModifyProjectOwner, err)
+		err = service.RemoveProjectMember(context.TODO(), 101, testOrgID, 7, 101)
+		assert.Equal(t, customErrors.ErrCannotModifyProjectOwner, err)
+	})
+
+	t.Run("updating a non member reports not found", func(t *testing.T) {
+		params := projectdb.UpdateProjectMemberRoleParams{ProjectID: 7, UserID: 404, Role: projectdb.ProjectRoleCOMMENTER}
+		repo.On("UpdateProjectMemberRole", mock.Anything, params).Return(int64(0), nil).Once()
+
+		err := service.UpdateProjectMemberRole(context.TODO(), 101, testOrgID, 7, 404, "commenter")
+		assert.Equal(t, customErrors.ErrProjectMemberNotFound, err)
+	})
+
+	t.Run("owner removes a member", func(t *testing.T) {
+		params := projectdb.RemoveProjectMemberParams{ProjectID: 7, UserID: 202}
+		repo.On("RemoveProjectMember", mock.Anything, params).Return(int64(1), nil).Once()
+
+		err := service.RemoveProjectMember(context.TODO(), 101, testOrgID, 7, 202)
+		assert.NoError(t, err)
+	})
+
+	t.Run("members can list the project members", func(t *testing.T) {
+		repo.On("ListProjectMembers", mock.Anything, int64(7)).Return([]projectdb.ListProjectMembersRow{{ProjectID: 7, UserID: 101, Role: projectdb.ProjectRoleOWNER}}, nil).Once()
+
+		members, err := service.ListProjectMembers(context.TODO(), 202, testOrgID, 7)
+		assert.NoError(t, err)
+		assert.Len(t, members, 1)
+	})
+}
@@ -0,0 +1,51 @@
+package project
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetProjectTime adds up the time logged on a project per user.
+// @Summary      Get project time
+// @Description  Adds up the minutes logged on the project's tasks from one day to another, both included, per user with the most time first. Running timers count once they are stopped.
+// @Tags         projects
+// @Produce      json
+// @Param        id    path      int     true  "Project ID"
+// @Param        from  query     string  true  "First day, e.g. 2026-10-01"
+// @Param        to    query     string  true  "Last day, e.g. 2026-10-31"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      403   {object}  map[string]interface{}
+// @Failure      404   {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/time [get]
+// @Security BearerAuth
+func (p *ProjectHandler) GetProjectTime(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	var timeRange types.TimeRange
+	if err := c.ShouldBindQuery(&timeRange); err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidTimeRange.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	projectTime, err := p.taskQueryService.ProjectTime(ctx, userID, orgID, projectID, timeRange)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data":    projectTime,
+		"message": "request succeeded successfully",
+	})
+}
@@ -9,21 +9,72 @@ import (
 // Project represents a project entity with its basic attributes.
 // Used for creating, retrieving, and displaying project information.
 type Project struct {
-	Name        string `json:"name"`        // Name of the project
-	Description string `json:"description"` // Description of the project
-	Color       string `json:"color"`       // Color label for the project (e.g., for UI)
-	User        int    `json:"user_id"`     // ID of the user who owns the project
+	Name         string `json:"name"`            // Name of the project
+	Description  string `json:"description"`     // Description of the project
+	Color        string `json:"color"`           // Color label for the project (e.g., for UI)
+	User         int    `json:"user_id"`         // ID of the user who owns the project
+	Organization int    `json:"organization_id"` // Organization the project belongs to, set from the request context
 }
 
 // UpdateProjectRequest is used to update an existing project's details.
 // Fields are optional and only provided values will be updated.
 type UpdateProjectRequest struct {
-	ProjectID int `json:"project_id"`
+	ProjectID   int     `json:"project_id"`
 	Name        *string `json:"name"`        // New name for the project (optional)
 	Description *string `json:"description"` // New description (optional)
 	Color       *string `json:"color"`       // New color label (optional)
 }
 
+// AddProjectMemberRequest invites an existing user to a project.
+type AddProjectMemberRequest struct {
+	Email string `json:"email" binding:"required"` // Email of the user to invite
+	Role  string `json:"role" binding:"required"`  // One of owner, editor, viewer, commenter
+}
+
+// UpdateProjectMemberRequest changes the role of an existing project member.
+type UpdateProjectMemberRequest struct {
+	Role string `json:"role" binding:"required"` // One of owner, editor, viewer, commenter
+}
+
+// CreateLabelRequest defines a label of the project.
+type CreateLabelRequest struct {
+	Name  string `json:"name" binding:"required"` // Unique in the project, ignoring case
+	Color string `json:"color"`                   // Hex color such as #1f883d, grey when left out
+}
+
+// UpdateLabelRequest renames or recolors a label; only provided values are updated.
+type UpdateLabelRequest struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+// CreateCustomFieldRequest defines a custom field of the project.
+type CreateCustomFieldRequest struct {
+	Name    string   `json:"name" binding:"required"` // Unique in the project, ignoring case
+	Type    string   `json:"type" binding:"required"` // One of text, number, date, single_select, multi_select, user
+	Options []string `json:"options"`                 // Choices of single_select and multi_select fields
+}
+
+// UpdateCustomFieldRequest renames a custom field or replaces the options of a
+// select field; only provided values are updated. The type cannot be changed.
+type UpdateCustomFieldRequest struct {
+	Name    *string   `json:"name"`
+	Options *[]string `json:"options"`
+}
+
+// WorkflowStatusRequest is one status of a project workflow.
+type WorkflowStatusRequest struct {
+	Name        string   `json:"name" binding:"required"`     // Upper-case letters, digits and underscores, such as IN_REVIEW
+	Category    string   `json:"category" binding:"required"` // One of open, in_progress, done
+	Transitions []string `json:"transitions"`                 // Statuses a task in this status may move to
+}
+
+// UpdateWorkflowRequest replaces the statuses of a project workflow. Their
+// order is the order of the board and new tasks start in the first one.
+type UpdateWorkflowRequest struct {
+	Statuses []WorkflowStatusRequest `json:"statuses" binding:"required"`
+}
+
 // IProjectService defines the interface for project-related business logic.
 // Each method should be implemented to handle the corresponding project operation.
 type IProjectService interface {
@@ -37,17 +88,15 @@ type IProjectService interface {
 	// Should return the project details if found.
 	GetProjectById()
 	// GetProjectsByUserId retrieves all projects for a given user.
-	// Should return a list of projects owned by the user.
+	// Should return a list of projects owned by or shared with the user.
 	GetProjectsByUserId()
 	// UpdateProject updates the details of an existing project.
 	// Should apply changes to the specified project.
 	UpdateProject()
 }
 
-
-type BulkCreateProjectService interface{
+type BulkCreateProjectService interface {
 	// CreateProjects creates multiple projects in bulk.
 	// Should accept a slice of project creation inputs and persist them.
-	CreateProject(ctx context.Context, project Project)(*projectdb.Project, error)
+	CreateProject(ctx context.Context, project Project) (*projectdb.Project, error)
 }
-
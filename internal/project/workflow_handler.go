@@ -0,0 +1,82 @@
+package project
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetWorkflow returns the workflow of a project.
+// @Summary      Get project workflow
+// @Description  Lists the statuses of the project workflow in board order with their category (open, in_progress or done) and the statuses a task may move to from each
+// @Tags         projects
+// @Produce      json
+// @Param        id   path      int  true  "Project ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/workflow [get]
+// @Security BearerAuth
+func (p *ProjectHandler) GetWorkflow(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	workflow, err := p.projectService.GetWorkflow(ctx, userID, orgID, projectID)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data":    workflow,
+		"message": "request succeeded successfully",
+	})
+}
+
+// UpdateWorkflow replaces the workflow of a project.
+// @Summary      Update project workflow
+// @Description  Replaces the statuses of the project workflow. New tasks start in the first status, which must be in the open category. Statuses left out are removed unless tasks are still in them. Only project owners may change the workflow.
+// @Tags         projects
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                    true  "Project ID"
+// @Param        workflow  body      UpdateWorkflowRequest  true  "Statuses in board order"
+// @Success      200       {object}  map[string]interface{}
+// @Failure      400       {object}  map[string]interface{}
+// @Failure      403       {object}  map[string]interface{}
+// @Failure      404       {object}  map[string]interface{}
+// @Failure      409       {object}  map[string]interface{}
+// @Router       /api/v1/projects/{id}/workflow [put]
+// @Security BearerAuth
+func (p *ProjectHandler) UpdateWorkflow(c *gin.Context) {
+	userID, orgID, projectID, ok := p.projectScope(c)
+	if !ok {
+		return
+	}
+	var req UpdateWorkflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	workflow, err := p.projectService.UpdateWorkflow(ctx, userID, orgID, projectID, req)
+	if err != nil {
+		p.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data":    workflow,
+		"message": "workflow updated successfully",
+	})
+}
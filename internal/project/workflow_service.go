@@ -0,0 +1,109 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	projectdb "github.com/Gkemhcs/taskpilot/internal/project/gen"
+)
+
+const (
+	// maxWorkflowStatuses keeps boards readable; status names match the
+	// workflow_statuses.name column.
+	maxWorkflowStatuses     = 30
+	maxWorkflowStatusLength = 30
+)
+
+var workflowStatusName = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// GetWorkflow returns the statuses of the project workflow in board order.
+func (p *ProjectService) GetWorkflow(ctx context.Context, userID int, orgID int, projectID int) ([]projectdb.WorkflowStatus, error) {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	statuses, err := p.projectRepository.ListWorkflowStatuses(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+	if statuses == nil {
+		statuses = []projectdb.WorkflowStatus{}
+	}
+	return statuses, nil
+}
+
+// UpdateWorkflow replaces the statuses of the project workflow. Statuses that
+// are left out are removed, which is refused while tasks are still in them.
+// Only project owners may change the workflow.
+func (p *ProjectService) UpdateWorkflow(ctx context.Context, userID int, orgID int, projectID int, req UpdateWorkflowRequest) ([]projectdb.WorkflowStatus, error) {
+	if err := p.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionManage); err != nil {
+		return nil, err
+	}
+	statuses, err := workflowStatuses(req.Statuses)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(statuses)
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := p.projectRepository.ReplaceWorkflow(ctx, projectdb.ReplaceWorkflowParams{
+		Statuses:  encoded,
+		ProjectID: int64(projectID),
+	})
+	if IsErrorCode(err, customErrors.ForeignKeyViolationErr) {
+		return nil, customErrors.ErrWorkflowStatusInUse
+	}
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(workflow, func(a, b projectdb.WorkflowStatus) int { return int(a.Position - b.Position) })
+	return workflow, nil
+}
+
+// workflowStatuses normalizes the requested statuses into the rows of the
+// workflow, numbering them in the order they were given.
+func workflowStatuses(requested []WorkflowStatusRequest) ([]projectdb.WorkflowStatus, error) {
+	if len(requested) == 0 || len(requested) > maxWorkflowStatuses {
+		return nil, customErrors.ErrInvalidWorkflow
+	}
+	statuses := make([]projectdb.WorkflowStatus, 0, len(requested))
+	for i, status := range requested {
+		name := strings.ToUpper(strings.TrimSpace(status.Name))
+		category := projectdb.StatusCategory(strings.ToLower(strings.TrimSpace(status.Category)))
+		if len(name) > maxWorkflowStatusLength || !workflowStatusName.MatchString(name) || !validStatusCategory(category) ||
+			slices.ContainsFunc(statuses, func(s projectdb.WorkflowStatus) bool { return s.Name == name }) {
+			return nil, customErrors.ErrInvalidWorkflow
+		}
+		statuses = append(statuses, projectdb.WorkflowStatus{Name: name, Category: category, Position: int32(i)})
+	}
+	if statuses[0].Category != projectdb.StatusCategoryOpen {
+		return nil, customErrors.ErrInvalidWorkflow
+	}
+	for i, status := range requested {
+		transitions := []string{}
+		for _, next := range status.Transitions {
+			next = strings.ToUpper(strings.TrimSpace(next))
+			if next == statuses[i].Name || !slices.ContainsFunc(statuses, func(s projectdb.WorkflowStatus) bool { return s.Name == next }) {
+				return nil, customErrors.ErrInvalidWorkflow
+			}
+			if !slices.Contains(transitions, next) {
+				transitions = append(transitions, next)
+			}
+		}
+		statuses[i].Transitions = transitions
+	}
+	return statuses, nil
+}
+
+func validStatusCategory(category projectdb.StatusCategory) bool {
+	switch category {
+	case projectdb.StatusCategoryOpen, projectdb.StatusCategoryInProgress, projectdb.StatusCategoryDone:
+		return true
+	}
+	return false
+}
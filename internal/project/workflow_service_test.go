@@ -0,0 +1,89 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	projectdb "github.com/Gkemhcs/taskpilot/internal/project/gen"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWorkflow(t *testing.T) {
+	repo := new(MockProjectRepo)
+	authzRepo := new(authz.MockAuthzRepo)
+	service := NewProjectService(repo, authz.NewAuthorizationService(authzRepo))
+
+	// user 101 owns project 7, user 202 is an editor
+	role := func(r authzdb.ProjectRole) authzdb.NullProjectRole {
+		return authzdb.NullProjectRole{ProjectRole: r, Valid: true}
+	}
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 7, UserID: 101, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 7, UserID: 101, Role: role(authzdb.ProjectRoleOWNER)}, nil)
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 7, UserID: 202, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 7, UserID: 101, Role: role(authzdb.ProjectRoleEDITOR)}, nil)
+
+	t.Run("editors read the workflow", func(t *testing.T) {
+		repo.On("ListWorkflowStatuses", mock.Anything, int64(7)).Return([]projectdb.WorkflowStatus(nil), nil).Once()
+		workflow, err := service.GetWorkflow(context.TODO(), 202, testOrgID, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, []projectdb.WorkflowStatus{}, workflow)
+	})
+
+	t.Run("replace the workflow", func(t *testing.T) {
+		request := UpdateWorkflowRequest{Statuses: []WorkflowStatusRequest{
+			{Name: "todo", Category: "open", Transitions: []string{"in_review"}},
+			{Name: " IN_REVIEW ", Category: "In_Progress", Transitions: []string{"TODO", "DONE", "done"}},
+			{Name: "DONE", Category: "done"},
+		}}
+		repo.On("ReplaceWorkflow", mock.Anything, mock.Anything).Return([]projectdb.WorkflowStatus{
+			{Name: "DONE", Position: 2}, {Name: "TODO", Position: 0}, {Name: "IN_REVIEW", Position: 1},
+		}, nil).Once()
+
+		workflow, err := service.UpdateWorkflow(context.TODO(), 101, testOrgID, 7, request)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"TODO", "IN_REVIEW", "DONE"}, []string{workflow[0].Name, workflow[1].Name, workflow[2].Name})
+
+		params := repo.Calls[len(repo.Calls)-1].Arguments.Get(1).(projectdb.ReplaceWorkflowParams)
+		assert.Equal(t, int64(7), params.ProjectID)
+		var statuses []projectdb.WorkflowStatus
+		assert.NoError(t, json.Unmarshal(params.Statuses, &statuses))
+		assert.Equal(t, projectdb.WorkflowStatus{Name: "IN_REVIEW", Category: projectdb.StatusCategoryInProgress, Position: 1, Transitions: []string{"TODO", "DONE"}}, statuses[1])
+		assert.Equal(t, []string{}, statuses[2].Transitions)
+	})
+
+	t.Run("invalid workflows", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			statuses []WorkflowStatusRequest
+		}{
+			{name: "no statuses"},
+			{name: "first status is not open", statuses: []WorkflowStatusRequest{{Name: "DOING", Category: "in_progress"}}},
+			{name: "unknown category", statuses: []WorkflowStatusRequest{{Name: "TODO", Category: "waiting"}}},
+			{name: "invalid name", statuses: []WorkflowStatusRequest{{Name: "in review", Category: "open"}}},
+			{name: "duplicate names", statuses: []WorkflowStatusRequest{{Name: "TODO", Category: "open"}, {Name: "todo", Category: "done"}}},
+			{name: "transition to an unknown status", statuses: []WorkflowStatusRequest{{Name: "TODO", Category: "open", Transitions: []string{"BLOCKED"}}}},
+			{name: "transition to itself", statuses: []WorkflowStatusRequest{{Name: "TODO", Category: "open", Transitions: []string{"TODO"}}}},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := service.UpdateWorkflow(context.TODO(), 101, testOrgID, 7, UpdateWorkflowRequest{Statuses: tc.statuses})
+				assert.Equal(t, customErrors.ErrInvalidWorkflow, err)
+			})
+		}
+	})
+
+	t.Run("statuses still in use are kept", func(t *testing.T) {
+		repo.On("ReplaceWorkflow", mock.Anything, mock.Anything).Return([]projectdb.WorkflowStatus(nil), &pq.Error{Code: customErrors.ForeignKeyViolationErr}).Once()
+		_, err := service.UpdateWorkflow(context.TODO(), 101, testOrgID, 7, UpdateWorkflowRequest{Statuses: []WorkflowStatusRequest{{Name: "TODO", Category: "open"}}})
+		assert.Equal(t, customErrors.ErrWorkflowStatusInUse, err)
+	})
+
+	t.Run("only owners change the workflow", func(t *testing.T) {
+		_, err := service.UpdateWorkflow(context.TODO(), 202, testOrgID, 7, UpdateWorkflowRequest{Statuses: []WorkflowStatusRequest{{Name: "TODO", Category: "open"}}})
+		assert.ErrorIs(t, err, customErrors.ErrForbidden)
+	})
+}
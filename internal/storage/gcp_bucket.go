@@ -3,8 +3,11 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"mime"
 	"mime/multipart"
+	"net/url"
 	"os"
 	"path/filepath"
 	"time"
@@ -77,13 +80,15 @@ type GCPStorageClient struct {
 }
 
 // Upload uploads a file to the GCP bucket under the specified prefix and filename.
-// Sets the content type for Excel files.
+// Sets the content type from the file extension, which callers choose from the
+// content of the file, and makes the bucket serve it as an attachment.
 func (g *GCPStorageClient) Upload(file multipart.File, filename string) error {
 	ctx := context.Background()
 	objectName := filepath.Join(g.Prefix, filename)
 
 	wc := g.client.Bucket(g.bucketName).Object(objectName).NewWriter(ctx)
-	wc.ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	wc.ContentType = contentType(filename)
+	wc.ContentDisposition = contentDisposition(filepath.Base(filename))
 	_, err := io.Copy(wc, file)
 	if err != nil {
 		return err
@@ -123,18 +128,26 @@ func (g *GCPStorageClient) Download(filename string) (string, error) {
 // GenerateSignedURL creates a signed URL for accessing the file in GCP bucket.
 // The URL is valid for the specified duration.
 func (g *GCPStorageClient) GenerateSignedURL(filename string, expiresIn time.Duration) (string, error) {
+	return g.GenerateDownloadURL(filename, filepath.Base(filename), expiresIn)
+}
+
+// GenerateDownloadURL creates a signed URL that makes the bucket serve the
+// file as an attachment named downloadName.
+func (g *GCPStorageClient) GenerateDownloadURL(filename string, downloadName string, expiresIn time.Duration) (string, error) {
 	objectName := filepath.Join(g.Prefix, filename)
 
-	url, err := storage.SignedURL(g.bucketName, objectName, &storage.SignedURLOptions{
-		Method:         "GET",
-		Expires:        time.Now().Add(expiresIn),
-		GoogleAccessID: g.GoogleAccessID,
-		PrivateKey:     g.PrivateKey,
+	signedURL, err := storage.SignedURL(g.bucketName, objectName, &storage.SignedURLOptions{
+		Method:          "GET",
+		Expires:         time.Now().Add(expiresIn),
+		GoogleAccessID:  g.GoogleAccessID,
+		PrivateKey:      g.PrivateKey,
+		Scheme:          storage.SigningSchemeV4,
+		QueryParameters: url.Values{"response-content-disposition": {contentDisposition(downloadName)}},
 	})
 	if err != nil {
 		return "", customErrors.ErrGeneratingSignedURL
 	}
-	return url, nil
+	return signedURL, nil
 }
 
 // Delete removes the file from both GCP bucket and local disk.
@@ -142,12 +155,29 @@ func (g *GCPStorageClient) Delete(filename string) error {
 	ctx := context.Background()
 	objectName := filepath.Join(g.Prefix, filename)
 
-	// Delete blob from GCS
+	// Delete blob from GCS; deleting it again is not an error
 	err := g.client.Bucket(g.bucketName).Object(objectName).Delete(ctx)
-	if err != nil {
+	if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
 		return err
 	}
 
-	// Delete from local
-	return os.Remove(filepath.Join(g.LocalDir, filename))
+	// Delete from local, where only downloaded files exist
+	err = os.Remove(filepath.Join(g.LocalDir, filename))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// contentType returns the content type objects are served with, Excel for
+// the import and export spreadsheets.
+func contentType(filename string) string {
+	ext := filepath.Ext(filename)
+	if ext == ".xlsx" {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
 }
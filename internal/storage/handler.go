@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterFileRoutes serves the files of client behind the links its
+// GenerateSignedURL signs. The signature takes the place of a token, so the
+// route is public.
+func RegisterFileRoutes(router gin.IRoutes, client *LocalStorageClient) {
+	router.GET(filesPath+"/:filename", FileHandler(client))
+}
+
+// FileHandler downloads a file of local storage through a signed link
+// @Summary      Download a file
+// @Description  Downloads a file through a link signed by the server, such as a task attachment or an export, as an attachment. Only served with local storage.
+// @Tags         files
+// @Produce      octet-stream
+// @Param        filename   path      string  true  "File name"
+// @Param        name       query     string  true  "Name the file downloads as"
+// @Param        expires    query     int     true  "Unix time the link expires at"
+// @Param        signature  query     string  true  "Link signature"
+// @Success      200
+// @Failure      403        {object}  utils.ErrorResponse
+// @Failure      404        {object}  utils.ErrorResponse
+// @Router       /api/v1/files/{filename} [get]
+func FileHandler(client *LocalStorageClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Query("name")
+		path, err := client.VerifySignedURL(c.Param("filename"), name, c.Query("expires"), c.Query("signature"))
+		if err != nil {
+			utils.Error(c, http.StatusForbidden, err.Error())
+			return
+		}
+		if _, err := os.Stat(path); err != nil {
+			utils.Error(c, http.StatusNotFound, customErrors.ErrFileNotFound.Error())
+			return
+		}
+		if name == "" {
+			name = filepath.Base(path)
+		}
+		// uploads must never render in the API origin
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("Content-Disposition", contentDisposition(name))
+		c.File(path)
+	}
+}
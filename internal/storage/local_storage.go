@@ -1,13 +1,24 @@
 package storage
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"mime/multipart"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 )
 
+// filesPath is where the server serves the files signed local links point to.
+const filesPath = "/api/v1/files"
+
 // NewLocalStorageClient initializes a LocalStorageClient for local file operations.
 // Ensures both temp and process directories exist.
 func NewLocalStorageClient(tempDir, processDir string) (*LocalStorageClient, error) {
@@ -31,6 +42,8 @@ func NewLocalStorageClient(tempDir, processDir string) (*LocalStorageClient, err
 type LocalStorageClient struct {
 	TempDir    string // Directory for uploaded files
 	ProcessDir string // Directory for processed/downloaded files
+	BaseURL    string // Server URL signed links point to; links are local paths when empty
+	SigningKey []byte // Key signed links are verified with
 }
 
 // Upload saves the uploaded file to the TempDir with the given filename.
@@ -68,15 +81,68 @@ func (c *LocalStorageClient) Download(filename string) (string, error) {
 	return destPath, err
 }
 
-// GenerateSignedURL returns the local file path as a "signed URL" for local storage.
-// No actual signing is performed; this is for interface compatibility.
+// GenerateSignedURL returns a link to the uploaded file that the server
+// accepts until it expires, see RegisterFileRoutes. Without a BaseURL and
+// SigningKey it returns the local file path instead, for the workers.
 func (c *LocalStorageClient) GenerateSignedURL(filename string, inExpires time.Duration) (string, error) {
-	destPath := filepath.Join(c.ProcessDir, filename)
-	return destPath, nil
+	if !c.SignsLinks() {
+		destPath := filepath.Join(c.ProcessDir, filename)
+		return destPath, nil
+	}
+	return c.GenerateDownloadURL(filename, filepath.Base(filename), inExpires)
+}
+
+// GenerateDownloadURL is GenerateSignedURL with the name the file downloads
+// as, which is signed into the link. It fails without a BaseURL and
+// SigningKey, as a local path is of no use to a client.
+func (c *LocalStorageClient) GenerateDownloadURL(filename string, downloadName string, inExpires time.Duration) (string, error) {
+	if !c.SignsLinks() {
+		return "", customErrors.ErrStorageLinksNotConfigured
+	}
+	expires := time.Now().Add(inExpires).Unix()
+	query := url.Values{
+		"name":      {downloadName},
+		"expires":   {strconv.FormatInt(expires, 10)},
+		"signature": {c.signature(filename, downloadName, expires)},
+	}
+	return fmt.Sprintf("%s%s/%s?%s", c.BaseURL, filesPath, url.PathEscape(filename), query.Encode()), nil
+}
+
+// VerifySignedURL checks the expiry and signature of a link made by
+// GenerateDownloadURL and returns the path of the file it points to.
+func (c *LocalStorageClient) VerifySignedURL(filename string, downloadName string, expires string, signature string) (string, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || len(c.SigningKey) == 0 || filename != filepath.Base(filename) {
+		return "", customErrors.ErrInvalidSignedURL
+	}
+	if !hmac.Equal([]byte(signature), []byte(c.signature(filename, downloadName, expiresAt))) {
+		return "", customErrors.ErrInvalidSignedURL
+	}
+	if time.Now().Unix() > expiresAt {
+		return "", customErrors.ErrSignedURLExpired
+	}
+	return filepath.Join(c.TempDir, filename), nil
 }
 
-// Delete removes the file from both TempDir and ProcessDir.
+// SignsLinks reports whether the client has a BaseURL and SigningKey to sign
+// links with.
+func (c *LocalStorageClient) SignsLinks() bool {
+	return c.BaseURL != "" && len(c.SigningKey) > 0
+}
+
+func (c *LocalStorageClient) signature(filename string, downloadName string, expires int64) string {
+	mac := hmac.New(sha256.New, c.SigningKey)
+	fmt.Fprintf(mac, "%s\n%s\n%d", filename, downloadName, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Delete removes the file from both TempDir and ProcessDir. Files that were
+// never downloaded only exist in TempDir, so a missing copy is not an error.
 func (c *LocalStorageClient) Delete(filename string) error {
-	_ = os.Remove(filepath.Join(c.TempDir, filename))
-	return os.Remove(filepath.Join(c.ProcessDir, filename))
+	for _, dir := range []string{c.TempDir, c.ProcessDir} {
+		if err := os.Remove(filepath.Join(dir, filename)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalSignedURL(t *testing.T) {
+	client, err := NewLocalStorageClient(t.TempDir(), t.TempDir())
+	require.NoError(t, err)
+
+	// without a signing key links are plain paths, as the workers use them
+	link, err := client.GenerateSignedURL("export.xlsx", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(client.ProcessDir, "export.xlsx"), link)
+
+	// attachment links need one, a path is of no use to a client
+	_, err = client.GenerateDownloadURL("export.xlsx", "tasks.xlsx", time.Minute)
+	assert.Equal(t, customErrors.ErrStorageLinksNotConfigured, err)
+
+	client.BaseURL = "http://localhost:8080"
+	client.SigningKey = []byte("secret")
+	link, err = client.GenerateDownloadURL("export.xlsx", "tasks.xlsx", time.Minute)
+	require.NoError(t, err)
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/files/export.xlsx", parsed.Path)
+	expires, signature := parsed.Query().Get("expires"), parsed.Query().Get("signature")
+	assert.Equal(t, "tasks.xlsx", parsed.Query().Get("name"))
+
+	testCases := []struct {
+		name          string
+		filename      string
+		downloadName  string
+		expires       string
+		signature     string
+		expectedError error
+	}{
+		{name: "valid link", filename: "export.xlsx", downloadName: "tasks.xlsx", expires: expires, signature: signature},
+		{name: "other file", filename: "other.xlsx", downloadName: "tasks.xlsx", expires: expires, signature: signature, expectedError: customErrors.ErrInvalidSignedURL},
+		{name: "other download name", filename: "export.xlsx", downloadName: "tasks.html", expires: expires, signature: signature, expectedError: customErrors.ErrInvalidSignedURL},
+		{name: "extended expiry", filename: "export.xlsx", downloadName: "tasks.xlsx", expires: expires + "0", signature: signature, expectedError: customErrors.ErrInvalidSignedURL},
+		{name: "path outside the directory", filename: "../export.xlsx", downloadName: "tasks.xlsx", expires: expires, signature: signature, expectedError: customErrors.ErrInvalidSignedURL},
+		{name: "missing expiry", filename: "export.xlsx", downloadName: "tasks.xlsx", signature: signature, expectedError: customErrors.ErrInvalidSignedURL},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := client.VerifySignedURL(tc.filename, tc.downloadName, tc.expires, tc.signature)
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedError == nil {
+				assert.Equal(t, filepath.Join(client.TempDir, "export.xlsx"), path)
+			}
+		})
+	}
+
+	t.Run("expired link", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute).Unix()
+		_, err := client.VerifySignedURL("export.xlsx", "tasks.xlsx", strconv.FormatInt(past, 10), client.signature("export.xlsx", "tasks.xlsx", past))
+		assert.Equal(t, customErrors.ErrSignedURLExpired, err)
+	})
+}
+
+func TestFileHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	client, err := NewLocalStorageClient(t.TempDir(), t.TempDir())
+	require.NoError(t, err)
+	client.BaseURL = "http://localhost:8080"
+	client.SigningKey = []byte("secret")
+	require.NoError(t, os.WriteFile(filepath.Join(client.TempDir, "attachment_1.html"), []byte("<script>alert(1)</script>"), 0o600))
+	router := gin.New()
+	RegisterFileRoutes(router, client)
+
+	link, err := client.GenerateDownloadURL("attachment_1.html", "report.html", time.Minute)
+	require.NoError(t, err)
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, parsed.RequestURI(), nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename=report.html`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/files/attachment_1.html?name=report.html&expires=1&signature=x", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -20,6 +20,10 @@ func StorageFactory(ctx context.Context, client string, config StorageConfig) (S
 		if err != nil {
 			return nil, err
 		}
+		if config.SigningKey != "" {
+			localClient.BaseURL = config.BaseURL
+			localClient.SigningKey = []byte(config.SigningKey)
+		}
 		return localClient, nil
 	}
 }
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"mime"
 	"mime/multipart"
 	"time"
 )
@@ -16,6 +17,17 @@ type StorageClient interface {
 	Delete(filename string) error
 	// GenerateSignedURL returns a signed URL for accessing the file, valid for the given duration.
 	GenerateSignedURL(filename string, inExpires time.Duration) (string, error)
+	// GenerateDownloadURL is GenerateSignedURL for a file that downloads as
+	// downloadName rather than being displayed by the browser.
+	GenerateDownloadURL(filename string, downloadName string, inExpires time.Duration) (string, error)
+}
+
+// contentDisposition makes a file download as name instead of being displayed.
+func contentDisposition(name string) string {
+	if disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name}); disposition != "" {
+		return disposition
+	}
+	return "attachment"
 }
 
 // StorageConfig holds configuration for initializing storage clients.
@@ -25,4 +37,6 @@ type StorageConfig struct {
 	Prefix     string // Prefix for object names (e.g., "imports")
 	ProcessDir string // Directory for processing/downloaded files
 	TempDir    string // Directory for temporary/uploaded files
+	BaseURL    string // Server URL local signed links point to
+	SigningKey string // Key local links are signed with; links are local paths when empty
 }
@@ -0,0 +1,169 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is room for the form around the file in an upload.
+const multipartOverhead = 1 << 20
+
+// UploadAttachment attaches a file to a task
+// @Summary      Attach a file to a task
+// @Description  Uploads a file as an attachment of the task. The size and content type are checked against the server's limits; the content type is sniffed from the file.
+// @Tags         attachments
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id    path      int   true  "Task ID"
+// @Param        file  formData  file  true  "File to attach"
+// @Success      201   {object}  map[string]interface{}
+// @Failure      400   {object}  utils.ErrorResponse
+// @Failure      403   {object}  utils.ErrorResponse
+// @Failure      404   {object}  utils.ErrorResponse
+// @Failure      413   {object}  utils.ErrorResponse
+// @Failure      415   {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/attachments [post]
+// @Security BearerAuth
+func (t *TaskHandler) UploadAttachment(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, t.taskService.AttachmentMaxSize()+multipartOverhead)
+	file, fileHeader, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.Error(c, http.StatusRequestEntityTooLarge, customErrors.ErrAttachmentTooLarge.Error())
+			return
+		}
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrFileRequired.Error())
+		return
+	}
+	defer file.Close()
+	// uploads to a bucket take longer than the queries around them
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+	attachment, err := t.taskService.AddAttachment(ctx, userID, orgID, taskID, fileHeader.Filename, file)
+	if err != nil {
+		t.logger.Errorf("unable to attach a file to task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusCreated, attachment)
+}
+
+// ListAttachments lists the files attached to a task
+// @Summary      List task attachments
+// @Description  Lists the files attached to the task, oldest first, with their name, size, content type, checksum and uploader
+// @Tags         attachments
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/attachments [get]
+// @Security BearerAuth
+func (t *TaskHandler) ListAttachments(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	attachments, err := t.taskService.ListAttachments(ctx, userID, orgID, taskID)
+	if err != nil {
+		t.logger.Errorf("unable to list the attachments of task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, attachments)
+}
+
+// GetAttachmentLink returns a download link to an attachment
+// @Summary      Get an attachment download link
+// @Description  Returns a signed link to download the attachment and when it expires
+// @Tags         attachments
+// @Produce      json
+// @Param        id            path      int  true  "Task ID"
+// @Param        attachmentId  path      int  true  "Attachment ID"
+// @Success      200           {object}  map[string]interface{}
+// @Failure      400           {object}  utils.ErrorResponse
+// @Failure      403           {object}  utils.ErrorResponse
+// @Failure      404           {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/attachments/{attachmentId}/download [get]
+// @Security BearerAuth
+func (t *TaskHandler) GetAttachmentLink(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	attachmentID, ok := attachmentIDParam(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	link, err := t.taskService.AttachmentLink(ctx, userID, orgID, taskID, attachmentID)
+	if err != nil {
+		t.logger.Errorf("unable to link attachment %d %v", attachmentID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, link)
+}
+
+// DeleteAttachment deletes an attachment
+// @Summary      Delete an attachment
+// @Description  Deletes the attachment and its file. Uploaders may delete their own attachments, project owners any attachment.
+// @Tags         attachments
+// @Produce      json
+// @Param        id            path      int  true  "Task ID"
+// @Param        attachmentId  path      int  true  "Attachment ID"
+// @Success      200           {object}  map[string]interface{}
+// @Failure      400           {object}  utils.ErrorResponse
+// @Failure      403           {object}  utils.ErrorResponse
+// @Failure      404           {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/attachments/{attachmentId} [delete]
+// @Security BearerAuth
+func (t *TaskHandler) DeleteAttachment(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	attachmentID, ok := attachmentIDParam(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	err := t.taskService.DeleteAttachment(ctx, userID, orgID, taskID, attachmentID)
+	if errors.Is(err, customErrors.ErrAttachmentFileKept) {
+		// the attachment is gone, the task worker deletes its file later
+		t.logger.Warnf("attachment %d: %v", attachmentID, err)
+	} else if err != nil {
+		t.logger.Errorf("unable to delete attachment %d %v", attachmentID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "attachment deleted successfully",
+	})
+}
+
+func attachmentIDParam(c *gin.Context) (int64, bool) {
+	attachmentID, err := strconv.ParseInt(c.Param("attachmentId"), 10, 64)
+	if err != nil || attachmentID <= 0 {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidAttachmentID.Error())
+		return 0, false
+	}
+	return attachmentID, true
+}
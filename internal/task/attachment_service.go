@@ -0,0 +1,217 @@
+package task
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/google/uuid"
+)
+
+const maxAttachmentNameLength = 255
+
+// attachmentExtensions names stored files after their sniffed content type,
+// never the client's file name, as storage serves files by their extension.
+// Other types are stored without one and served as binary.
+var attachmentExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"application/zip": ".zip",
+	"image/bmp":       ".bmp",
+	"image/gif":       ".gif",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"text/plain":      ".txt",
+}
+
+// AddAttachment stores file on the task under fileName. Its content type is
+// sniffed from its first bytes rather than taken from the client, and its
+// SHA-256 checksum is kept next to it.
+func (t *TaskService) AddAttachment(ctx context.Context, userID int, orgID int, taskID int, fileName string, file multipart.File) (*taskdb.TaskAttachment, error) {
+	if t.storage == nil {
+		return nil, customErrors.ErrAttachmentsDisabled
+	}
+	name := filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	if name == "." || name == "/" || len(name) > maxAttachmentNameLength {
+		return nil, customErrors.ErrInvalidAttachmentName
+	}
+	task, err := t.writableTask(ctx, userID, orgID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, customErrors.ErrEmptyAttachment
+	}
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil || !t.attachments.allows(contentType) {
+		return nil, customErrors.ErrAttachmentTypeNotAllowed
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, io.LimitReader(file, t.attachments.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size > t.attachments.MaxSize {
+		return nil, customErrors.ErrAttachmentTooLarge
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("attachment_%s%s", uuid.NewString(), attachmentExtensions[contentType])
+	if err := t.storage.Upload(file, key); err != nil {
+		return nil, customErrors.ErrUploadingFile
+	}
+	attachment, err := t.taskRepository.CreateTaskAttachment(ctx, taskdb.CreateTaskAttachmentParams{
+		TaskID:      task.ID,
+		ProjectID:   task.ProjectID,
+		UploaderID:  sql.NullInt32{Int32: int32(userID), Valid: true},
+		FileName:    name,
+		ContentType: contentType,
+		SizeBytes:   size,
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		StorageKey:  key,
+	})
+	if err != nil {
+		// a file that cannot be deleted now is queued for the task worker
+		if deleteErr := t.storage.Delete(key); deleteErr != nil {
+			if queueErr := t.taskRepository.QueueAttachmentDeletion(ctx, key); queueErr != nil {
+				return nil, errors.Join(err, deleteErr, queueErr)
+			}
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// ListAttachments lists the files attached to the task, oldest first.
+func (t *TaskService) ListAttachments(ctx context.Context, userID int, orgID int, taskID int) ([]taskdb.TaskAttachment, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	attachments, err := t.taskRepository.ListTaskAttachments(ctx, int64(taskID))
+	if err != nil {
+		return nil, err
+	}
+	if attachments == nil {
+		attachments = []taskdb.TaskAttachment{}
+	}
+	return attachments, nil
+}
+
+// AttachmentLink returns a link that downloads the attachment under its file
+// name and expires after the configured LinkExpiry.
+func (t *TaskService) AttachmentLink(ctx context.Context, userID int, orgID int, taskID int, attachmentID int64) (*AttachmentLink, error) {
+	if t.storage == nil {
+		return nil, customErrors.ErrAttachmentsDisabled
+	}
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	attachment, err := t.getAttachment(ctx, taskID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(t.attachments.LinkExpiry)
+	url, err := t.storage.GenerateDownloadURL(attachment.StorageKey, attachment.FileName, t.attachments.LinkExpiry)
+	if err != nil {
+		return nil, err
+	}
+	return &AttachmentLink{URL: url, ExpiresAt: expiresAt}, nil
+}
+
+// DeleteAttachment removes an attachment and then its file. Uploaders delete
+// their own attachments and project owners anyone's.
+func (t *TaskService) DeleteAttachment(ctx context.Context, userID int, orgID int, taskID int, attachmentID int64) error {
+	if t.storage == nil {
+		return customErrors.ErrAttachmentsDisabled
+	}
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionWrite); err != nil {
+		return err
+	}
+	attachment, err := t.getAttachment(ctx, taskID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if !attachment.UploaderID.Valid || attachment.UploaderID.Int32 != int32(userID) {
+		if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionManage); err != nil {
+			return err
+		}
+	}
+	rows, err := t.taskRepository.DeleteTaskAttachment(ctx, attachment.ID)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrAttachmentNotFound
+	}
+	// deleting the row queued its file, which stays queued for the task
+	// worker if it cannot be deleted now
+	if err := t.storage.Delete(attachment.StorageKey); err != nil {
+		return fmt.Errorf("%w: %v", customErrors.ErrAttachmentFileKept, err)
+	}
+	if err := t.taskRepository.DequeueAttachmentDeletion(ctx, attachment.StorageKey); err != nil {
+		return fmt.Errorf("%w: %v", customErrors.ErrAttachmentFileKept, err)
+	}
+	return nil
+}
+
+// QueuedAttachmentDeletions lists up to limit storage keys of deleted
+// attachments whose files are still stored, across every organization, for
+// the task worker. Attachments deleted with their task, project or
+// organization are queued too.
+func (t *TaskService) QueuedAttachmentDeletions(ctx context.Context, limit int32) ([]string, error) {
+	return t.taskRepository.ListQueuedAttachmentDeletions(ctx, limit)
+}
+
+// DequeueAttachmentDeletion takes key off the queue once its file is deleted.
+func (t *TaskService) DequeueAttachmentDeletion(ctx context.Context, key string) error {
+	return t.taskRepository.DequeueAttachmentDeletion(ctx, key)
+}
+
+func (t *TaskService) getAttachment(ctx context.Context, taskID int, attachmentID int64) (*taskdb.TaskAttachment, error) {
+	attachment, err := t.taskRepository.GetTaskAttachment(ctx, taskdb.GetTaskAttachmentParams{ID: attachmentID, TaskID: int64(taskID)})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// allows reports whether files of contentType may be attached.
+func (l AttachmentLimits) allows(contentType string) bool {
+	for _, allowed := range l.ContentTypes {
+		if allowed == contentType {
+			return true
+		}
+		if family, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(contentType, family+"/") {
+			return true
+		}
+	}
+	return false
+}
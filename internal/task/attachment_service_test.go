@@ -0,0 +1,210 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/storage"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// uploadedFile stands in for the multipart.File of an upload.
+type uploadedFile struct {
+	*bytes.Reader
+}
+
+func (uploadedFile) Close() error { return nil }
+
+func newAttachmentService(t *testing.T) (*TaskService, *MockTaskRepo, *storage.LocalStorageClient) {
+	service, repo := newCommentService()
+	client, err := storage.NewLocalStorageClient(t.TempDir(), t.TempDir())
+	require.NoError(t, err)
+	client.BaseURL = "http://localhost:8080"
+	client.SigningKey = []byte("secret")
+	service.UseStorage(client, AttachmentLimits{
+		MaxSize:      1024,
+		ContentTypes: []string{"image/*", "text/plain"},
+		LinkExpiry:   15 * time.Minute,
+	})
+	return service, repo, client
+}
+
+func attachment(id int64, uploaderID int32, key string) taskdb.TaskAttachment {
+	return taskdb.TaskAttachment{
+		ID:         id,
+		TaskID:     22,
+		ProjectID:  6,
+		UploaderID: sql.NullInt32{Int32: uploaderID, Valid: true},
+		FileName:   "notes.txt",
+		StorageKey: key,
+	}
+}
+
+func TestAddAttachment(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+	notes := []byte("meeting notes")
+	sum := sha256.Sum256(notes)
+
+	testCases := []struct {
+		name          string
+		taskID        int
+		fileName      string
+		content       []byte
+		expectedName  string
+		expectedType  string
+		expectedExt   string
+		expectedError error
+	}{
+		{name: "text file", taskID: 22, fileName: "notes.txt", content: notes, expectedName: "notes.txt", expectedType: "text/plain", expectedExt: ".txt"},
+		{name: "image family", taskID: 22, fileName: "screen.PNG", content: png, expectedName: "screen.PNG", expectedType: "image/png", expectedExt: ".png"},
+		{name: "client path is dropped", taskID: 22, fileName: `C:\Users\me\notes.txt`, content: notes, expectedName: "notes.txt", expectedType: "text/plain", expectedExt: ".txt"},
+		{name: "stored by content, not extension", taskID: 22, fileName: "x.html", content: notes, expectedName: "x.html", expectedType: "text/plain", expectedExt: ".txt"},
+		{name: "type is sniffed, not taken from the name", taskID: 22, fileName: "report.txt", content: []byte("%PDF-1.7\n"), expectedError: customErrors.ErrAttachmentTypeNotAllowed},
+		{name: "larger than the limit", taskID: 22, fileName: "big.txt", content: bytes.Repeat([]byte("a"), 1025), expectedError: customErrors.ErrAttachmentTooLarge},
+		{name: "empty file", taskID: 22, fileName: "empty.txt", content: nil, expectedError: customErrors.ErrEmptyAttachment},
+		{name: "name too long", taskID: 22, fileName: strings.Repeat("a", 256), content: notes, expectedError: customErrors.ErrInvalidAttachmentName},
+		{name: "commenters cannot attach", taskID: 20, fileName: "notes.txt", content: notes, expectedError: customErrors.ErrForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo, client := newAttachmentService(t)
+			repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+			repo.On("CreateTaskAttachment", mock.Anything, mock.Anything).Return(attachment(1, 1234, "key"), nil)
+
+			_, err := service.AddAttachment(context.TODO(), 1234, testOrgID, tc.taskID, tc.fileName, uploadedFile{bytes.NewReader(tc.content)})
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedError != nil {
+				repo.AssertNotCalled(t, "CreateTaskAttachment", mock.Anything, mock.Anything)
+				return
+			}
+			params := repo.Calls[len(repo.Calls)-1].Arguments.Get(1).(taskdb.CreateTaskAttachmentParams)
+			assert.Equal(t, tc.expectedName, params.FileName)
+			assert.Equal(t, tc.expectedType, params.ContentType)
+			assert.Equal(t, tc.expectedExt, filepath.Ext(params.StorageKey))
+			assert.Equal(t, int64(len(tc.content)), params.SizeBytes)
+			assert.Equal(t, sql.NullInt32{Int32: 1234, Valid: true}, params.UploaderID)
+			stored, err := os.ReadFile(filepath.Join(client.TempDir, params.StorageKey))
+			require.NoError(t, err)
+			assert.Equal(t, tc.content, stored)
+			if tc.expectedType == "text/plain" {
+				assert.Equal(t, hex.EncodeToString(sum[:]), params.Checksum)
+			}
+		})
+	}
+}
+
+func TestAddAttachmentRemovesFileOnFailure(t *testing.T) {
+	service, repo, client := newAttachmentService(t)
+	repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+	repo.On("CreateTaskAttachment", mock.Anything, mock.Anything).Return(taskdb.TaskAttachment{}, sql.ErrConnDone)
+
+	_, err := service.AddAttachment(context.TODO(), 1234, testOrgID, 22, "notes.txt", uploadedFile{bytes.NewReader([]byte("notes"))})
+	assert.Equal(t, sql.ErrConnDone, err)
+	files, err := os.ReadDir(client.TempDir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	repo.AssertNotCalled(t, "QueueAttachmentDeletion", mock.Anything, mock.Anything)
+}
+
+// undeletableStorage keeps uploads but fails every Delete.
+type undeletableStorage struct {
+	*storage.LocalStorageClient
+}
+
+func (undeletableStorage) Delete(string) error { return os.ErrPermission }
+
+func TestAddAttachmentQueuesFileItCannotRemove(t *testing.T) {
+	service, repo, client := newAttachmentService(t)
+	service.UseStorage(undeletableStorage{client}, service.attachments)
+	repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+	repo.On("CreateTaskAttachment", mock.Anything, mock.Anything).Return(taskdb.TaskAttachment{}, sql.ErrConnDone)
+	repo.On("QueueAttachmentDeletion", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := service.AddAttachment(context.TODO(), 1234, testOrgID, 22, "notes.txt", uploadedFile{bytes.NewReader([]byte("notes"))})
+	assert.Equal(t, sql.ErrConnDone, err)
+	files, err := os.ReadDir(client.TempDir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	repo.AssertCalled(t, "QueueAttachmentDeletion", mock.Anything, files[0].Name())
+}
+
+func TestAttachmentLink(t *testing.T) {
+	service, repo, client := newAttachmentService(t)
+	repo.On("GetTaskAttachment", mock.Anything, taskdb.GetTaskAttachmentParams{ID: 1, TaskID: 22}).Return(attachment(1, 1234, "attachment_1.txt"), nil)
+	repo.On("GetTaskAttachment", mock.Anything, mock.Anything).Return(taskdb.TaskAttachment{}, sql.ErrNoRows)
+
+	link, err := service.AttachmentLink(context.TODO(), 1234, testOrgID, 22, 1)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link.URL, "http://localhost:8080/api/v1/files/attachment_1.txt?"))
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), link.ExpiresAt, time.Minute)
+	query, err := url.ParseQuery(link.URL[strings.Index(link.URL, "?")+1:])
+	require.NoError(t, err)
+	assert.Equal(t, "notes.txt", query.Get("name"))
+	path, err := client.VerifySignedURL("attachment_1.txt", "notes.txt", query.Get("expires"), query.Get("signature"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(client.TempDir, "attachment_1.txt"), path)
+
+	_, err = service.AttachmentLink(context.TODO(), 1234, testOrgID, 22, 2)
+	assert.Equal(t, customErrors.ErrAttachmentNotFound, err)
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	service, repo, client := newAttachmentService(t)
+	require.NoError(t, os.WriteFile(filepath.Join(client.TempDir, "attachment_1.txt"), []byte("notes"), 0o600))
+	repo.On("GetTaskAttachment", mock.Anything, taskdb.GetTaskAttachmentParams{ID: 1, TaskID: 22}).Return(attachment(1, 99, "attachment_1.txt"), nil)
+	repo.On("GetTaskAttachment", mock.Anything, mock.Anything).Return(taskdb.TaskAttachment{}, sql.ErrNoRows)
+	repo.On("DeleteTaskAttachment", mock.Anything, int64(1)).Return(int64(1), nil)
+	repo.On("DequeueAttachmentDeletion", mock.Anything, "attachment_1.txt").Return(nil)
+
+	// user 1234 owns project 6, so the attachments of others can go too
+	assert.NoError(t, service.DeleteAttachment(context.TODO(), 1234, testOrgID, 22, 1))
+	_, err := os.Stat(filepath.Join(client.TempDir, "attachment_1.txt"))
+	assert.True(t, os.IsNotExist(err))
+	repo.AssertCalled(t, "DequeueAttachmentDeletion", mock.Anything, "attachment_1.txt")
+	assert.Equal(t, customErrors.ErrAttachmentNotFound, service.DeleteAttachment(context.TODO(), 1234, testOrgID, 22, 2))
+}
+
+func TestDeleteAttachmentKeepsFileOnFailure(t *testing.T) {
+	service, repo, client := newAttachmentService(t)
+	require.NoError(t, os.WriteFile(filepath.Join(client.TempDir, "attachment_1.txt"), []byte("notes"), 0o600))
+	repo.On("GetTaskAttachment", mock.Anything, mock.Anything).Return(attachment(1, 1234, "attachment_1.txt"), nil)
+	repo.On("DeleteTaskAttachment", mock.Anything, int64(1)).Return(int64(0), errors.New("connection reset"))
+
+	// the file stays while its attachment does
+	assert.Error(t, service.DeleteAttachment(context.TODO(), 1234, testOrgID, 22, 1))
+	_, err := os.Stat(filepath.Join(client.TempDir, "attachment_1.txt"))
+	assert.NoError(t, err)
+}
+
+func TestDeleteAttachmentReportsQueuedFile(t *testing.T) {
+	service, repo, client := newAttachmentService(t)
+	require.NoError(t, os.WriteFile(filepath.Join(client.TempDir, "attachment_1.txt"), []byte("notes"), 0o600))
+	repo.On("GetTaskAttachment", mock.Anything, mock.Anything).Return(attachment(1, 1234, "attachment_1.txt"), nil)
+	repo.On("DeleteTaskAttachment", mock.Anything, int64(1)).Return(int64(1), nil)
+	repo.On("DequeueAttachmentDeletion", mock.Anything, "attachment_1.txt").Return(errors.New("connection reset"))
+
+	// the attachment is deleted, only its queue entry is left for the worker
+	err := service.DeleteAttachment(context.TODO(), 1234, testOrgID, 22, 1)
+	assert.ErrorIs(t, err, customErrors.ErrAttachmentFileKept)
+}
+
+func TestAttachmentsDisabled(t *testing.T) {
+	service, _ := newCommentService()
+	_, err := service.AddAttachment(context.TODO(), 1234, testOrgID, 22, "notes.txt", uploadedFile{bytes.NewReader([]byte("notes"))})
+	assert.Equal(t, customErrors.ErrAttachmentsDisabled, err)
+}
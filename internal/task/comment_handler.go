@@ -0,0 +1,199 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ListComments lists the discussion on a task
+// @Summary      List task comments
+// @Description  Lists a page of top-level comments, oldest first, each with all of its replies
+// @Tags         comments
+// @Produce      json
+// @Param        id      path      int  true   "Task ID"
+// @Param        limit   query     int  false  "Threads per page (default 20, max 100)"
+// @Param        offset  query     int  false  "Number of threads to skip"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  utils.ErrorResponse
+// @Failure      403     {object}  utils.ErrorResponse
+// @Failure      404     {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/comments [get]
+// @Security BearerAuth
+func (t *TaskHandler) ListComments(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	var page types.Page
+	if err := c.ShouldBindQuery(&page); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	threads, err := t.taskService.ListComments(ctx, userID, orgID, taskID, page)
+	if err != nil {
+		t.logger.Errorf("unable to list the comments of task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, threads)
+}
+
+// CreateComment comments on a task or replies to a comment
+// @Summary      Comment on a task
+// @Description  Adds a comment to the task. Set parent_id to reply to a top-level comment; replies cannot be replied to.
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                   true  "Task ID"
+// @Param        comment  body      CreateCommentRequest  true  "Comment"
+// @Success      201      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      403      {object}  utils.ErrorResponse
+// @Failure      404      {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/comments [post]
+// @Security BearerAuth
+func (t *TaskHandler) CreateComment(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	var req CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	comment, err := t.taskService.CreateComment(ctx, userID, orgID, taskID, req)
+	if err != nil {
+		t.logger.Errorf("unable to comment on task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusCreated, comment)
+}
+
+// UpdateComment edits a comment
+// @Summary      Edit a comment
+// @Description  Replaces the body of a comment. Only its author may edit it; the previous body is kept in the edit history.
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id         path      int                   true  "Task ID"
+// @Param        commentId  path      int                   true  "Comment ID"
+// @Param        comment    body      UpdateCommentRequest  true  "New body"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      400        {object}  utils.ErrorResponse
+// @Failure      403        {object}  utils.ErrorResponse
+// @Failure      404        {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/comments/{commentId} [patch]
+// @Security BearerAuth
+func (t *TaskHandler) UpdateComment(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	commentID, ok := commentIDParam(c)
+	if !ok {
+		return
+	}
+	var req UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	comment, err := t.taskService.UpdateComment(ctx, userID, orgID, taskID, commentID, req)
+	if err != nil {
+		t.logger.Errorf("unable to edit comment %d %v", commentID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, comment)
+}
+
+// DeleteComment deletes a comment
+// @Summary      Delete a comment
+// @Description  Deletes a comment and its replies. Authors may delete their own comments, project owners any comment.
+// @Tags         comments
+// @Produce      json
+// @Param        id         path      int  true  "Task ID"
+// @Param        commentId  path      int  true  "Comment ID"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      400        {object}  utils.ErrorResponse
+// @Failure      403        {object}  utils.ErrorResponse
+// @Failure      404        {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/comments/{commentId} [delete]
+// @Security BearerAuth
+func (t *TaskHandler) DeleteComment(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	commentID, ok := commentIDParam(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := t.taskService.DeleteComment(ctx, userID, orgID, taskID, commentID); err != nil {
+		t.logger.Errorf("unable to delete comment %d %v", commentID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "comment deleted successfully",
+	})
+}
+
+// ListCommentEdits lists the edit history of a comment
+// @Summary      List comment edits
+// @Description  Lists the earlier bodies of a comment, oldest first
+// @Tags         comments
+// @Produce      json
+// @Param        id         path      int  true  "Task ID"
+// @Param        commentId  path      int  true  "Comment ID"
+// @Success      200        {object}  map[string]interface{}
+// @Failure      400        {object}  utils.ErrorResponse
+// @Failure      403        {object}  utils.ErrorResponse
+// @Failure      404        {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/comments/{commentId}/edits [get]
+// @Security BearerAuth
+func (t *TaskHandler) ListCommentEdits(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	commentID, ok := commentIDParam(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	edits, err := t.taskService.ListCommentEdits(ctx, userID, orgID, taskID, commentID)
+	if err != nil {
+		t.logger.Errorf("unable to list the edits of comment %d %v", commentID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, edits)
+}
+
+func commentIDParam(c *gin.Context) (int64, bool) {
+	commentID, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
+	if err != nil || commentID <= 0 {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidCommentID.Error())
+		return 0, false
+	}
+	return commentID, true
+}
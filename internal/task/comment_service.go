@@ -0,0 +1,194 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+// maxCommentLength is the longest comment body, in characters.
+const maxCommentLength = 10000
+
+// CreateComment adds a comment to the task, or a reply when ParentID names a
+// top-level comment of the same task. Commenters and above may comment.
+func (t *TaskService) CreateComment(ctx context.Context, userID int, orgID int, taskID int, req CreateCommentRequest) (*taskdb.TaskComment, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionComment); err != nil {
+		return nil, err
+	}
+	body, err := commentBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	params := taskdb.CreateTaskCommentParams{
+		TaskID:   int64(taskID),
+		AuthorID: sql.NullInt32{Int32: int32(userID), Valid: true},
+		Body:     body,
+	}
+	if req.ParentID != nil {
+		parent, err := t.getComment(ctx, taskID, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.ParentID.Valid {
+			return nil, customErrors.ErrNestedCommentReply
+		}
+		params.ParentID = sql.NullInt64{Int64: parent.ID, Valid: true}
+	}
+	comment, err := t.taskRepository.CreateTaskComment(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	t.publish(ctx, types.TaskEvent{Kind: types.CommentCreated, ActorID: userID, TaskID: comment.TaskID, Comment: &comment})
+	return &comment, nil
+}
+
+// ListComments returns a page of the task's top-level comments, oldest first,
+// each with all of its replies.
+func (t *TaskService) ListComments(ctx context.Context, userID int, orgID int, taskID int, page types.Page) ([]CommentThread, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	limit, offset, err := page.Bounds()
+	if err != nil {
+		return nil, err
+	}
+	comments, err := t.taskRepository.ListTaskComments(ctx, taskdb.ListTaskCommentsParams{
+		TaskID: int64(taskID),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	threads := make([]CommentThread, len(comments))
+	if len(comments) == 0 {
+		return threads, nil
+	}
+	ids := make([]int64, len(comments))
+	position := make(map[int64]int, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+		position[comment.ID] = i
+		threads[i] = CommentThread{TaskComment: comment, Replies: []taskdb.TaskComment{}}
+	}
+	replies, err := t.taskRepository.ListTaskCommentReplies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, reply := range replies {
+		i := position[reply.ParentID.Int64]
+		threads[i].Replies = append(threads[i].Replies, reply)
+	}
+	return threads, nil
+}
+
+// UpdateComment replaces the body of a comment. Only its author may edit it,
+// and only while they may still comment on the task; the previous body is
+// kept in the comment's edit history.
+func (t *TaskService) UpdateComment(ctx context.Context, userID int, orgID int, taskID int, commentID int64, req UpdateCommentRequest) (*taskdb.TaskComment, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionComment); err != nil {
+		return nil, err
+	}
+	body, err := commentBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := t.getComment(ctx, taskID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAuthor(comment, userID) {
+		return nil, customErrors.ErrNotCommentAuthor
+	}
+	if comment.Body == body {
+		return comment, nil
+	}
+	updated, err := t.taskRepository.UpdateTaskCommentBody(ctx, taskdb.UpdateTaskCommentBodyParams{
+		EditedBy: int32(userID),
+		ID:       comment.ID,
+		Body:     body,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.publish(ctx, types.TaskEvent{Kind: types.CommentUpdated, ActorID: userID, TaskID: updated.TaskID, Comment: &updated, PreviousComment: comment})
+	return &updated, nil
+}
+
+// DeleteComment removes a comment together with its replies. Authors may
+// delete their own comments, project owners may delete any comment.
+func (t *TaskService) DeleteComment(ctx context.Context, userID int, orgID int, taskID int, commentID int64) error {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionComment); err != nil {
+		return err
+	}
+	comment, err := t.getComment(ctx, taskID, commentID)
+	if err != nil {
+		return err
+	}
+	if !isAuthor(comment, userID) {
+		if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionManage); err != nil {
+			return err
+		}
+	}
+	rows, err := t.taskRepository.DeleteTaskComment(ctx, comment.ID)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrCommentNotFound
+	}
+	return nil
+}
+
+// ListCommentEdits returns the earlier bodies of a comment, oldest first.
+func (t *TaskService) ListCommentEdits(ctx context.Context, userID int, orgID int, taskID int, commentID int64) ([]taskdb.TaskCommentEdit, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	if _, err := t.getComment(ctx, taskID, commentID); err != nil {
+		return nil, err
+	}
+	edits, err := t.taskRepository.ListTaskCommentEdits(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if edits == nil {
+		edits = []taskdb.TaskCommentEdit{}
+	}
+	return edits, nil
+}
+
+// getComment loads a comment and makes sure it belongs to taskID.
+func (t *TaskService) getComment(ctx context.Context, taskID int, commentID int64) (*taskdb.TaskComment, error) {
+	comment, err := t.taskRepository.GetTaskComment(ctx, taskdb.GetTaskCommentParams{ID: commentID, TaskID: int64(taskID)})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func isAuthor(comment *taskdb.TaskComment, userID int) bool {
+	return comment.AuthorID.Valid && int(comment.AuthorID.Int32) == userID
+}
+
+// commentBody trims body and checks its length.
+func commentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || utf8.RuneCountInString(body) > maxCommentLength {
+		return "", customErrors.ErrInvalidCommentBody
+	}
+	return body, nil
+}
@@ -0,0 +1,165 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newCommentService returns a service where user 1234 is a commenter on task
+// 20, a viewer of task 21 and the owner of task 22 and its project 6.
+func newCommentService() (*TaskService, *MockTaskRepo) {
+	authzRepo := new(authz.MockAuthzRepo)
+	role := func(r authzdb.ProjectRole) authzdb.NullProjectRole {
+		return authzdb.NullProjectRole{ProjectRole: r, Valid: true}
+	}
+	authzRepo.On("GetTaskProjectRole", mock.Anything, authzdb.GetTaskProjectRoleParams{ID: 20, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetTaskProjectRoleRow{ProjectID: 5, UserID: 99, Role: role(authzdb.ProjectRoleCOMMENTER)}, nil)
+	authzRepo.On("GetTaskProjectRole", mock.Anything, authzdb.GetTaskProjectRoleParams{ID: 21, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetTaskProjectRoleRow{ProjectID: 5, UserID: 99, Role: role(authzdb.ProjectRoleVIEWER)}, nil)
+	authzRepo.On("GetTaskProjectRole", mock.Anything, authzdb.GetTaskProjectRoleParams{ID: 22, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetTaskProjectRoleRow{ProjectID: 6, UserID: 1234}, nil)
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 6, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 6, UserID: 1234}, nil)
+	repo := new(MockTaskRepo)
+	return NewTaskService(repo, authz.NewAuthorizationService(authzRepo)), repo
+}
+
+func comment(id int64, taskID int64, authorID int32, parentID int64) taskdb.TaskComment {
+	return taskdb.TaskComment{
+		ID:       id,
+		TaskID:   taskID,
+		AuthorID: sql.NullInt32{Int32: authorID, Valid: true},
+		ParentID: sql.NullInt64{Int64: parentID, Valid: parentID != 0},
+		Body:     "looks good",
+	}
+}
+
+func TestCreateComment(t *testing.T) {
+	service, repo := newCommentService()
+	repo.On("GetTaskComment", mock.Anything, taskdb.GetTaskCommentParams{ID: 1, TaskID: 20}).Return(comment(1, 20, 99, 0), nil)
+	repo.On("GetTaskComment", mock.Anything, taskdb.GetTaskCommentParams{ID: 2, TaskID: 20}).Return(comment(2, 20, 99, 1), nil)
+	repo.On("GetTaskComment", mock.Anything, mock.Anything).Return(taskdb.TaskComment{}, sql.ErrNoRows)
+	repo.On("CreateTaskComment", mock.Anything, mock.Anything).Return(comment(3, 20, 1234, 0), nil)
+
+	one, two, three := int64(1), int64(2), int64(3)
+	testCases := []struct {
+		name           string
+		taskID         int
+		request        CreateCommentRequest
+		expectedParams *taskdb.CreateTaskCommentParams
+		expectedError  error
+	}{
+		{
+			name:    "commenter comments on a task",
+			taskID:  20,
+			request: CreateCommentRequest{Body: "  looks good \n"},
+			expectedParams: &taskdb.CreateTaskCommentParams{
+				TaskID:   20,
+				AuthorID: sql.NullInt32{Int32: 1234, Valid: true},
+				Body:     "looks good",
+			},
+		},
+		{
+			name:    "reply to a top-level comment",
+			taskID:  20,
+			request: CreateCommentRequest{Body: "agreed", ParentID: &one},
+			expectedParams: &taskdb.CreateTaskCommentParams{
+				TaskID:   20,
+				ParentID: sql.NullInt64{Int64: 1, Valid: true},
+				AuthorID: sql.NullInt32{Int32: 1234, Valid: true},
+				Body:     "agreed",
+			},
+		},
+		{name: "replies cannot be nested", taskID: 20, request: CreateCommentRequest{Body: "me too", ParentID: &two}, expectedError: customErrors.ErrNestedCommentReply},
+		{name: "reply to a comment of another task", taskID: 20, request: CreateCommentRequest{Body: "me too", ParentID: &three}, expectedError: customErrors.ErrCommentNotFound},
+		{name: "blank body", taskID: 20, request: CreateCommentRequest{Body: "   "}, expectedError: customErrors.ErrInvalidCommentBody},
+		{name: "viewers cannot comment", taskID: 21, request: CreateCommentRequest{Body: "hi"}, expectedError: customErrors.ErrForbidden},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo.Calls = nil
+			_, err := service.CreateComment(context.TODO(), 1234, testOrgID, tc.taskID, tc.request)
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedParams != nil {
+				repo.AssertCalled(t, "CreateTaskComment", mock.Anything, *tc.expectedParams)
+			} else {
+				repo.AssertNotCalled(t, "CreateTaskComment", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestListComments(t *testing.T) {
+	service, repo := newCommentService()
+	repo.On("ListTaskComments", mock.Anything, taskdb.ListTaskCommentsParams{TaskID: 21, Limit: types.DefaultPageSize}).Return([]taskdb.TaskComment{comment(1, 21, 99, 0), comment(4, 21, 99, 0)}, nil)
+	repo.On("ListTaskCommentReplies", mock.Anything, []int64{1, 4}).Return([]taskdb.TaskComment{comment(2, 21, 1234, 1), comment(3, 21, 99, 1)}, nil)
+
+	t.Run("replies are grouped under their comment", func(t *testing.T) {
+		threads, err := service.ListComments(context.TODO(), 1234, testOrgID, 21, types.Page{})
+		assert.NoError(t, err)
+		assert.Len(t, threads, 2)
+		assert.Len(t, threads[0].Replies, 2)
+		assert.Empty(t, threads[1].Replies)
+		assert.NotNil(t, threads[1].Replies)
+	})
+
+	t.Run("page size is limited", func(t *testing.T) {
+		limit := int32(types.MaxPageSize + 1)
+		_, err := service.ListComments(context.TODO(), 1234, testOrgID, 21, types.Page{Limit: &limit})
+		assert.Equal(t, customErrors.ErrInvalidPagination, err)
+	})
+}
+
+func TestUpdateComment(t *testing.T) {
+	service, repo := newCommentService()
+	repo.On("GetTaskComment", mock.Anything, taskdb.GetTaskCommentParams{ID: 1, TaskID: 20}).Return(comment(1, 20, 1234, 0), nil)
+	repo.On("GetTaskComment", mock.Anything, taskdb.GetTaskCommentParams{ID: 2, TaskID: 20}).Return(comment(2, 20, 99, 0), nil)
+	repo.On("UpdateTaskCommentBody", mock.Anything, taskdb.UpdateTaskCommentBodyParams{EditedBy: 1234, ID: 1, Body: "updated"}).Return(comment(1, 20, 1234, 0), nil)
+
+	t.Run("author edits their comment", func(t *testing.T) {
+		_, err := service.UpdateComment(context.TODO(), 1234, testOrgID, 20, 1, UpdateCommentRequest{Body: "updated"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("unchanged body is not recorded as an edit", func(t *testing.T) {
+		_, err := service.UpdateComment(context.TODO(), 1234, testOrgID, 20, 1, UpdateCommentRequest{Body: "looks good"})
+		assert.NoError(t, err)
+		repo.AssertNumberOfCalls(t, "UpdateTaskCommentBody", 1)
+	})
+
+	t.Run("only the author may edit", func(t *testing.T) {
+		_, err := service.UpdateComment(context.TODO(), 1234, testOrgID, 20, 2, UpdateCommentRequest{Body: "updated"})
+		assert.Equal(t, customErrors.ErrNotCommentAuthor, err)
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	service, repo := newCommentService()
+	repo.On("GetTaskComment", mock.Anything, taskdb.GetTaskCommentParams{ID: 1, TaskID: 20}).Return(comment(1, 20, 1234, 0), nil)
+	repo.On("GetTaskComment", mock.Anything, taskdb.GetTaskCommentParams{ID: 2, TaskID: 20}).Return(comment(2, 20, 99, 0), nil)
+	repo.On("GetTaskComment", mock.Anything, taskdb.GetTaskCommentParams{ID: 3, TaskID: 22}).Return(comment(3, 22, 99, 0), nil)
+	repo.On("DeleteTaskComment", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	testCases := []struct {
+		name          string
+		taskID        int
+		commentID     int64
+		expectedError error
+	}{
+		{name: "author deletes their comment", taskID: 20, commentID: 1},
+		{name: "commenter cannot delete someone else's comment", taskID: 20, commentID: 2, expectedError: customErrors.ErrForbidden},
+		{name: "project owner deletes any comment", taskID: 22, commentID: 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := service.DeleteComment(context.TODO(), 1234, testOrgID, tc.taskID, tc.commentID)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+	repo.AssertNotCalled(t, "DeleteTaskComment", mock.Anything, int64(2))
+}
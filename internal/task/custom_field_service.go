@@ -0,0 +1,316 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+// maxCustomTextLength limits the values of text fields.
+const maxCustomTextLength = 1000
+
+// ListCustomFields returns the custom fields of a project. Exports use it for
+// their extra columns.
+func (t *TaskService) ListCustomFields(ctx context.Context, userID int, orgID int, projectID int) ([]taskdb.CustomField, error) {
+	if err := t.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	fields, err := t.taskRepository.ListProjectCustomFields(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		fields = []taskdb.CustomField{}
+	}
+	return fields, nil
+}
+
+// CustomFieldValuesByName picks the values of the project's custom fields out
+// of an imported row, matching columns to field names regardless of case.
+// Empty cells and the base columns of imports and exports are left out.
+func (t *TaskService) CustomFieldValuesByName(ctx context.Context, userID int, orgID int, projectID int, row map[string]string) (map[int64]any, error) {
+	fields, err := t.ListCustomFields(ctx, userID, orgID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[int64]any)
+	for column, cell := range row {
+		// fields named like a base column predate the check on field names
+		if types.IsTaskSheetHeader(column) {
+			continue
+		}
+		i := slices.IndexFunc(fields, func(field taskdb.CustomField) bool { return strings.EqualFold(field.Name, strings.TrimSpace(column)) })
+		if i >= 0 && strings.TrimSpace(cell) != "" {
+			values[fields[i].ID] = cell
+		}
+	}
+	return values, nil
+}
+
+// CustomFieldText formats a stored custom field value for a spreadsheet cell,
+// the way CustomFieldValuesByName reads it back.
+func CustomFieldText(value json.RawMessage) string {
+	var decoded any
+	if err := json.Unmarshal(value, &decoded); err != nil {
+		return string(value)
+	}
+	switch v := decoded.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		options := make([]string, len(v))
+		for i, option := range v {
+			options[i] = fmt.Sprint(option)
+		}
+		return strings.Join(options, ",")
+	default:
+		return string(value)
+	}
+}
+
+// checkCustomFields validates custom field values of a task of the project and
+// returns the parameters that store them, or nil when there are none. A nil
+// value clears the field.
+func (t *TaskService) checkCustomFields(ctx context.Context, projectID int64, values map[int64]any) (*taskdb.SetTaskCustomFieldValuesParams, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	fields, err := t.taskRepository.ListProjectCustomFields(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	params := &taskdb.SetTaskCustomFieldValuesParams{
+		ProjectID:       projectID,
+		ClearedFieldIds: []int64{},
+		FieldIds:        []int64{},
+		FieldValues:     []string{},
+	}
+	ids := make([]int64, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		i := slices.IndexFunc(fields, func(field taskdb.CustomField) bool { return field.ID == id })
+		if i < 0 {
+			return nil, customErrors.ErrCustomFieldNotFound
+		}
+		value, err := customFieldValue(fields[i], values[id])
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			params.ClearedFieldIds = append(params.ClearedFieldIds, id)
+			continue
+		}
+		if userID, ok := value.(int64); ok {
+			isMember, err := t.taskRepository.IsProjectMember(ctx, taskdb.IsProjectMemberParams{ProjectID: projectID, UserID: int32(userID)})
+			if err != nil {
+				return nil, err
+			}
+			if !isMember {
+				return nil, fmt.Errorf("%w: %s must be a member of the project", customErrors.ErrInvalidCustomFieldValue, fields[i].Name)
+			}
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		params.FieldIds = append(params.FieldIds, id)
+		params.FieldValues = append(params.FieldValues, string(encoded))
+	}
+	return params, nil
+}
+
+// customFieldFilter turns the custom_field, sort_field and sort_order query
+// parameters into the arguments of ListTasksWithFilters. A filter on a
+// multi-select field matches tasks having the given option among others.
+func (t *TaskService) customFieldFilter(ctx context.Context, req *TaskFilterRequest, params *taskdb.ListTasksWithFiltersParams) error {
+	switch strings.ToLower(req.SortOrder) {
+	case "", "asc":
+	case "desc":
+		params.SortDesc = true
+	default:
+		return customErrors.ErrInvalidSortOrder
+	}
+	params.FieldIds = []int64{}
+	params.FieldValues = []string{}
+	wanted := make([]string, len(req.CustomFields))
+	for i, filter := range req.CustomFields {
+		id, value, found := strings.Cut(filter, ":")
+		fieldID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if !found || err != nil || fieldID <= 0 {
+			return customErrors.ErrInvalidCustomFieldFilter
+		}
+		params.FieldIds = append(params.FieldIds, fieldID)
+		wanted[i] = value
+	}
+	ids := slices.Clone(params.FieldIds)
+	if req.SortField != nil {
+		params.SortFieldID.Int64, params.SortFieldID.Valid = *req.SortField, true
+		ids = append(ids, *req.SortField)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	fields, err := t.taskRepository.ListCustomFieldsByID(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if len(fields) != len(ids) {
+		return customErrors.ErrCustomFieldNotFound
+	}
+	for i, fieldID := range params.FieldIds {
+		field := fields[slices.IndexFunc(fields, func(field taskdb.CustomField) bool { return field.ID == fieldID })]
+		if field.FieldType == taskdb.CustomFieldTypeMultiSelect {
+			field.FieldType = taskdb.CustomFieldTypeSingleSelect
+		}
+		value, err := customFieldValue(field, wanted[i])
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			return customErrors.ErrInvalidCustomFieldFilter
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		params.FieldValues = append(params.FieldValues, string(encoded))
+	}
+	return nil
+}
+
+// customFieldValue checks a value against the type of its field and returns it
+// the way it is stored: a string for text, date and single-select fields, a
+// float64 for number fields, the user id as int64 for user fields and a slice
+// of options for multi-select fields. Values come from JSON request bodies or,
+// as strings, from imported spreadsheets. Null, empty strings and empty lists
+// clear the field and give nil.
+func customFieldValue(field taskdb.CustomField, value any) (any, error) {
+	invalid := fmt.Errorf("%w: %s", customErrors.ErrInvalidCustomFieldValue, field.Name)
+	if s, ok := value.(string); ok && field.FieldType != taskdb.CustomFieldTypeText {
+		value = strings.TrimSpace(s)
+	}
+	if value == nil || value == "" {
+		return nil, nil
+	}
+	switch field.FieldType {
+	case taskdb.CustomFieldTypeText:
+		s, ok := value.(string)
+		if !ok || utf8.RuneCountInString(s) > maxCustomTextLength {
+			return nil, invalid
+		}
+		return s, nil
+	case taskdb.CustomFieldTypeNumber:
+		number, ok := value.(float64)
+		if s, isString := value.(string); isString {
+			var err error
+			number, err = strconv.ParseFloat(s, 64)
+			ok = err == nil
+		}
+		if !ok || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, invalid
+		}
+		return number, nil
+	case taskdb.CustomFieldTypeDate:
+		s, _ := value.(string)
+		date, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			if date, err = time.Parse(time.RFC3339, s); err != nil {
+				return nil, invalid
+			}
+		}
+		return date.Format(time.DateOnly), nil
+	case taskdb.CustomFieldTypeSingleSelect:
+		s, _ := value.(string)
+		option, ok := customFieldOption(field.Options, s)
+		if !ok {
+			return nil, invalid
+		}
+		return option, nil
+	case taskdb.CustomFieldTypeMultiSelect:
+		var chosen []string
+		switch v := value.(type) {
+		case string:
+			chosen = strings.Split(v, ",")
+		case []any:
+			for _, option := range v {
+				s, ok := option.(string)
+				if !ok {
+					return nil, invalid
+				}
+				chosen = append(chosen, s)
+			}
+		default:
+			return nil, invalid
+		}
+		// options are kept in the order the field lists them
+		var options []string
+		for _, s := range chosen {
+			if strings.TrimSpace(s) == "" {
+				continue
+			}
+			option, ok := customFieldOption(field.Options, s)
+			if !ok {
+				return nil, invalid
+			}
+			if !slices.Contains(options, option) {
+				options = append(options, option)
+			}
+		}
+		if len(options) == 0 {
+			return nil, nil
+		}
+		slices.SortFunc(options, func(a, b string) int {
+			return slices.Index(field.Options, a) - slices.Index(field.Options, b)
+		})
+		return options, nil
+	case taskdb.CustomFieldTypeUser:
+		var userID int64
+		switch v := value.(type) {
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, invalid
+			}
+			userID = int64(v)
+		case string:
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, invalid
+			}
+			userID = id
+		}
+		if userID <= 0 || userID > math.MaxInt32 {
+			return nil, invalid
+		}
+		return userID, nil
+	}
+	return nil, invalid
+}
+
+// customFieldOption finds the option of a select field matching s regardless
+// of case.
+func customFieldOption(options []string, s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	i := slices.IndexFunc(options, func(option string) bool { return strings.EqualFold(option, s) })
+	if i < 0 {
+		return "", false
+	}
+	return options[i], true
+}
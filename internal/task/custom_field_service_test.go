@@ -0,0 +1,174 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// customFields are the fields of project 6
+var customFields = []taskdb.CustomField{
+	{ID: 1, ProjectID: 6, Name: "Customer", FieldType: taskdb.CustomFieldTypeText},
+	{ID: 2, ProjectID: 6, Name: "Story points", FieldType: taskdb.CustomFieldTypeNumber},
+	{ID: 3, ProjectID: 6, Name: "Release", FieldType: taskdb.CustomFieldTypeDate},
+	{ID: 4, ProjectID: 6, Name: "Environment", FieldType: taskdb.CustomFieldTypeSingleSelect, Options: []string{"dev", "prod"}},
+	{ID: 5, ProjectID: 6, Name: "Platforms", FieldType: taskdb.CustomFieldTypeMultiSelect, Options: []string{"web", "ios", "android"}},
+	{ID: 6, ProjectID: 6, Name: "Reviewer", FieldType: taskdb.CustomFieldTypeUser},
+}
+
+func TestCustomFieldValue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		field         taskdb.CustomField
+		value         any
+		expected      any
+		expectedError bool
+	}{
+		{name: "text", field: customFields[0], value: " Acme ", expected: " Acme "},
+		{name: "text is a string", field: customFields[0], value: 5.0, expectedError: true},
+		{name: "number", field: customFields[1], value: 3.5, expected: 3.5},
+		{name: "number from a spreadsheet", field: customFields[1], value: "8", expected: 8.0},
+		{name: "not a number", field: customFields[1], value: "many", expectedError: true},
+		{name: "date", field: customFields[2], value: "2025-03-01", expected: "2025-03-01"},
+		{name: "timestamps keep their date", field: customFields[2], value: "2025-03-01T10:00:00Z", expected: "2025-03-01"},
+		{name: "not a date", field: customFields[2], value: "March", expectedError: true},
+		{name: "option regardless of case", field: customFields[3], value: "PROD", expected: "prod"},
+		{name: "unknown option", field: customFields[3], value: "qa", expectedError: true},
+		{name: "options in field order", field: customFields[4], value: []any{"android", "web", "Web"}, expected: []string{"web", "android"}},
+		{name: "options from a spreadsheet", field: customFields[4], value: "ios, web", expected: []string{"web", "ios"}},
+		{name: "empty list clears", field: customFields[4], value: []any{}, expected: nil},
+		{name: "user", field: customFields[5], value: 12.0, expected: int64(12)},
+		{name: "user id from a spreadsheet", field: customFields[5], value: "12", expected: int64(12)},
+		{name: "user ids are whole numbers", field: customFields[5], value: 1.5, expectedError: true},
+		{name: "null clears", field: customFields[1], value: nil, expected: nil},
+		{name: "empty string clears", field: customFields[2], value: " ", expected: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := customFieldValue(tc.field, tc.value)
+			if tc.expectedError {
+				assert.True(t, errors.Is(err, customErrors.ErrInvalidCustomFieldValue))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestSetCustomFields(t *testing.T) {
+	testCases := []struct {
+		name           string
+		values         map[int64]any
+		expectedParams *taskdb.SetTaskCustomFieldValuesParams
+		expectedError  error
+	}{
+		{
+			name:   "set and clear values",
+			values: map[int64]any{2: 3.0, 5: []any{"ios"}, 6: 1234.0, 1: nil},
+			expectedParams: &taskdb.SetTaskCustomFieldValuesParams{
+				TaskID:          22,
+				ProjectID:       6,
+				ClearedFieldIds: []int64{1},
+				FieldIds:        []int64{2, 5, 6},
+				FieldValues:     []string{`3`, `["ios"]`, `1234`},
+			},
+		},
+		{name: "field of another project", values: map[int64]any{9: "x"}, expectedError: customErrors.ErrCustomFieldNotFound},
+		{name: "user outside the project", values: map[int64]any{6: 99.0}, expectedError: customErrors.ErrInvalidCustomFieldValue},
+		{name: "values are kept when omitted"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newCommentService()
+			repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+			repo.On("ListProjectCustomFields", mock.Anything, int64(6)).Return(customFields, nil)
+			repo.On("IsProjectMember", mock.Anything, taskdb.IsProjectMemberParams{ProjectID: 6, UserID: 1234}).Return(true, nil)
+			repo.On("IsProjectMember", mock.Anything, mock.Anything).Return(false, nil)
+			repo.On("UpdateTask", mock.Anything, mock.Anything).Return(subtask(22, 6, 0), nil)
+			repo.On("SetTaskCustomFieldValues", mock.Anything, mock.Anything).Return(nil)
+
+			err := service.UpdateTask(context.TODO(), 1234, testOrgID, UpdateTaskRequest{ID: 22, CustomFields: tc.values})
+			assert.True(t, errors.Is(err, tc.expectedError))
+			if tc.expectedParams != nil {
+				repo.AssertCalled(t, "SetTaskCustomFieldValues", mock.Anything, *tc.expectedParams)
+			} else {
+				repo.AssertNotCalled(t, "SetTaskCustomFieldValues", mock.Anything, mock.Anything)
+			}
+			if tc.expectedError != nil {
+				repo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCustomFieldFilter(t *testing.T) {
+	service, repo := newCommentService()
+	repo.On("ListCustomFieldsByID", mock.Anything, []int64{2, 5}).Return([]taskdb.CustomField{customFields[1], customFields[4]}, nil)
+	repo.On("ListCustomFieldsByID", mock.Anything, mock.Anything).Return([]taskdb.CustomField(nil), nil)
+	sortField := int64(2)
+
+	t.Run("filter and sort", func(t *testing.T) {
+		var params taskdb.ListTasksWithFiltersParams
+		err := service.customFieldFilter(context.TODO(), &TaskFilterRequest{CustomFields: []string{"5:IOS", "2:3"}, SortField: &sortField, SortOrder: "desc"}, &params)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{5, 2}, params.FieldIds)
+		// a multi-select filter matches tasks having the option
+		assert.Equal(t, []string{`"ios"`, `3`}, params.FieldValues)
+		assert.Equal(t, int64(2), params.SortFieldID.Int64)
+		assert.True(t, params.SortDesc)
+	})
+
+	testCases := []struct {
+		name          string
+		request       TaskFilterRequest
+		expectedError error
+	}{
+		{name: "missing value", request: TaskFilterRequest{CustomFields: []string{"5"}}, expectedError: customErrors.ErrInvalidCustomFieldFilter},
+		{name: "unknown field", request: TaskFilterRequest{CustomFields: []string{"9:x"}}, expectedError: customErrors.ErrCustomFieldNotFound},
+		{name: "invalid value", request: TaskFilterRequest{CustomFields: []string{"2:many", "5:ios"}}, expectedError: customErrors.ErrInvalidCustomFieldValue},
+		{name: "invalid sort order", request: TaskFilterRequest{SortOrder: "up"}, expectedError: customErrors.ErrInvalidSortOrder},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var params taskdb.ListTasksWithFiltersParams
+			err := service.customFieldFilter(context.TODO(), &tc.request, &params)
+			assert.True(t, errors.Is(err, tc.expectedError), err)
+		})
+	}
+}
+
+func TestCustomFieldText(t *testing.T) {
+	assert.Equal(t, "Acme", CustomFieldText(json.RawMessage(`"Acme"`)))
+	assert.Equal(t, "3.5", CustomFieldText(json.RawMessage(`3.5`)))
+	assert.Equal(t, "12", CustomFieldText(json.RawMessage(`12`)))
+	assert.Equal(t, "web,ios", CustomFieldText(json.RawMessage(`["web","ios"]`)))
+}
+
+func TestCustomFieldValuesByName(t *testing.T) {
+	service, repo := newCommentService()
+	repo.On("ListProjectCustomFields", mock.Anything, int64(6)).Return(customFields, nil)
+
+	values, err := service.CustomFieldValuesByName(context.TODO(), 1234, testOrgID, 6, map[string]string{
+		"title":        "Ship the beta",
+		"story points": "5",
+		"Platforms":    "web,ios",
+		"Customer":     "",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]any{2: "5", 5: "web,ios"}, values)
+
+	// base columns never fill a field, even one named like them
+	service, repo = newCommentService()
+	repo.On("ListProjectCustomFields", mock.Anything, int64(6)).Return([]taskdb.CustomField{{ID: 7, ProjectID: 6, Name: "Status", FieldType: taskdb.CustomFieldTypeText}}, nil)
+	values, err = service.CustomFieldValuesByName(context.TODO(), 1234, testOrgID, 6, map[string]string{"status": "TODO"})
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+}
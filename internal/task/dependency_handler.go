@@ -0,0 +1,112 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ListDependencies lists the tasks a task is blocked by and the tasks it blocks
+// @Summary      List task dependencies
+// @Description  Lists the tasks the task is blocked by and the tasks it blocks
+// @Tags         tasks
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/dependencies [get]
+// @Security BearerAuth
+func (t *TaskHandler) ListDependencies(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	dependencies, err := t.taskService.ListDependencies(ctx, userID, orgID, taskID)
+	if err != nil {
+		t.logger.Errorf("unable to list the dependencies of task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, dependencies)
+}
+
+// AddDependency marks a task as blocked by another task
+// @Summary      Add a task dependency
+// @Description  Marks the task as blocked by another task of the same project. Links that would make a task wait for itself are rejected.
+// @Tags         tasks
+// @Accept       json
+// @Produce      json
+// @Param        id          path      int                   true  "Task ID"
+// @Param        dependency  body      AddDependencyRequest  true  "Blocking task"
+// @Success      201         {object}  map[string]interface{}
+// @Failure      400         {object}  utils.ErrorResponse
+// @Failure      403         {object}  utils.ErrorResponse
+// @Failure      404         {object}  utils.ErrorResponse
+// @Failure      409         {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/dependencies [post]
+// @Security BearerAuth
+func (t *TaskHandler) AddDependency(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	var req AddDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		t.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	dependency, err := t.taskService.AddDependency(ctx, userID, orgID, taskID, req.BlockedByID)
+	if err != nil {
+		t.logger.Errorf("unable to block task %d by task %d %v", taskID, req.BlockedByID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusCreated, dependency)
+}
+
+// RemoveDependency removes a task dependency
+// @Summary      Remove a task dependency
+// @Description  Stops the task from being blocked by the given task
+// @Tags         tasks
+// @Produce      json
+// @Param        id           path      int  true  "Task ID"
+// @Param        blockedById  path      int  true  "Blocking task ID"
+// @Success      200          {object}  map[string]interface{}
+// @Failure      400          {object}  utils.ErrorResponse
+// @Failure      403          {object}  utils.ErrorResponse
+// @Failure      404          {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/dependencies/{blockedById} [delete]
+// @Security BearerAuth
+func (t *TaskHandler) RemoveDependency(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	blockedByID, err := strconv.ParseInt(c.Param("blockedById"), 10, 64)
+	if err != nil || blockedByID <= 0 {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidTaskID.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := t.taskService.RemoveDependency(ctx, userID, orgID, taskID, blockedByID); err != nil {
+		t.logger.Errorf("unable to unblock task %d from task %d %v", taskID, blockedByID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "dependency removed successfully",
+	})
+}
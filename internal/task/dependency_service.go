@@ -0,0 +1,134 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+// ListDependencies returns the tasks a task is blocked by and the tasks it
+// blocks.
+func (t *TaskService) ListDependencies(ctx context.Context, userID int, orgID int, taskID int) (*TaskDependencies, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	blockedBy, err := t.taskRepository.ListTaskBlockers(ctx, int64(taskID))
+	if err != nil {
+		return nil, err
+	}
+	blocking, err := t.taskRepository.ListBlockedTasks(ctx, int64(taskID))
+	if err != nil {
+		return nil, err
+	}
+	if blockedBy == nil {
+		blockedBy = []taskdb.Task{}
+	}
+	if blocking == nil {
+		blocking = []taskdb.Task{}
+	}
+	return &TaskDependencies{BlockedBy: blockedBy, Blocking: blocking}, nil
+}
+
+// AddDependency records that taskID is blocked by blockedByID. Both tasks must
+// belong to the same project and the link must not make a task wait for
+// itself, directly or through other tasks.
+func (t *TaskService) AddDependency(ctx context.Context, userID int, orgID int, taskID int, blockedByID int64) (*taskdb.TaskDependency, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionWrite); err != nil {
+		return nil, err
+	}
+	task, err := t.taskRepository.GetTaskById(ctx, int64(taskID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	blocker, err := t.taskRepository.GetTaskById(ctx, blockedByID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrBlockingTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if blocker.ProjectID != task.ProjectID {
+		return nil, customErrors.ErrCrossProjectDependency
+	}
+	// the blocker waiting for the task, or being the task, closes a cycle
+	waitsFor, err := t.taskRepository.ListTransitiveBlockerIDs(ctx, blocker.ID)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(waitsFor, task.ID) {
+		return nil, customErrors.ErrDependencyCycle
+	}
+	dependency, err := t.taskRepository.CreateTaskDependency(ctx, taskdb.CreateTaskDependencyParams{
+		TaskID:      task.ID,
+		BlockedByID: blocker.ID,
+		ProjectID:   task.ProjectID,
+	})
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+		return nil, customErrors.ErrDependencyAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &dependency, nil
+}
+
+// RemoveDependency unblocks taskID from blockedByID.
+func (t *TaskService) RemoveDependency(ctx context.Context, userID int, orgID int, taskID int, blockedByID int64) error {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionWrite); err != nil {
+		return err
+	}
+	rows, err := t.taskRepository.DeleteTaskDependency(ctx, taskdb.DeleteTaskDependencyParams{
+		TaskID:      int64(taskID),
+		BlockedByID: blockedByID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrDependencyNotFound
+	}
+	return nil
+}
+
+// DependencyGraph returns every task of a project with its blocked flag and
+// every dependency between them, for critical-path views.
+func (t *TaskService) DependencyGraph(ctx context.Context, userID int, orgID int, projectID int) (*types.DependencyGraph, error) {
+	tasks, err := t.GetTasksByProjectID(ctx, userID, orgID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := t.TaskResponses(ctx, tasks)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := t.taskRepository.ListProjectDependencies(ctx, int64(projectID))
+	if err != nil {
+		return nil, err
+	}
+	if edges == nil {
+		edges = []taskdb.TaskDependency{}
+	}
+	return &types.DependencyGraph{Nodes: nodes, Edges: edges}, nil
+}
+
+// checkBlockers refuses to mark a task as done while a task it is blocked by
+// is still open.
+func (t *TaskService) checkBlockers(ctx context.Context, taskID int64) error {
+	open, err := t.taskRepository.CountOpenBlockers(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return customErrors.ErrTaskBlocked
+	}
+	return nil
+}
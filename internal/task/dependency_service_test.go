@@ -0,0 +1,116 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddDependency(t *testing.T) {
+	// task 22 of project 6 blocks task 30, which blocks task 31
+	service, repo := newCommentService()
+	repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+	repo.On("GetTaskById", mock.Anything, int64(23)).Return(subtask(23, 6, 0), nil)
+	repo.On("GetTaskById", mock.Anything, int64(24)).Return(subtask(24, 6, 0), nil)
+	repo.On("GetTaskById", mock.Anything, int64(31)).Return(subtask(31, 6, 0), nil)
+	repo.On("GetTaskById", mock.Anything, int64(40)).Return(subtask(40, 7, 0), nil)
+	repo.On("GetTaskById", mock.Anything, mock.Anything).Return(taskdb.Task{}, sql.ErrNoRows)
+	repo.On("ListTransitiveBlockerIDs", mock.Anything, int64(22)).Return([]int64{22}, nil)
+	repo.On("ListTransitiveBlockerIDs", mock.Anything, int64(23)).Return([]int64{23}, nil)
+	repo.On("ListTransitiveBlockerIDs", mock.Anything, int64(24)).Return([]int64{24}, nil)
+	repo.On("ListTransitiveBlockerIDs", mock.Anything, int64(31)).Return([]int64{31, 30, 22}, nil)
+	repo.On("CreateTaskDependency", mock.Anything, taskdb.CreateTaskDependencyParams{TaskID: 22, BlockedByID: 23, ProjectID: 6}).Return(taskdb.TaskDependency{TaskID: 22, BlockedByID: 23, ProjectID: 6}, nil)
+	repo.On("CreateTaskDependency", mock.Anything, taskdb.CreateTaskDependencyParams{TaskID: 22, BlockedByID: 24, ProjectID: 6}).Return(taskdb.TaskDependency{}, mockDuplicateError())
+
+	testCases := []struct {
+		name          string
+		blockedByID   int64
+		expectedError error
+	}{
+		{name: "blocked by a task of the same project", blockedByID: 23},
+		{name: "already blocked by the task", blockedByID: 24, expectedError: customErrors.ErrDependencyAlreadyExists},
+		{name: "blocked by itself", blockedByID: 22, expectedError: customErrors.ErrDependencyCycle},
+		{name: "blocked by a task waiting for it", blockedByID: 31, expectedError: customErrors.ErrDependencyCycle},
+		{name: "blocked by a task of another project", blockedByID: 40, expectedError: customErrors.ErrCrossProjectDependency},
+		{name: "missing blocking task", blockedByID: 50, expectedError: customErrors.ErrBlockingTaskNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dependency, err := service.AddDependency(context.TODO(), 1234, testOrgID, 22, tc.blockedByID)
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedError == nil {
+				assert.Equal(t, tc.blockedByID, dependency.BlockedByID)
+			}
+		})
+	}
+
+	t.Run("viewers cannot add dependencies", func(t *testing.T) {
+		_, err := service.AddDependency(context.TODO(), 1234, testOrgID, 21, 23)
+		assert.Equal(t, customErrors.ErrForbidden, err)
+	})
+}
+
+func TestRemoveDependency(t *testing.T) {
+	service, repo := newCommentService()
+	repo.On("DeleteTaskDependency", mock.Anything, taskdb.DeleteTaskDependencyParams{TaskID: 22, BlockedByID: 23}).Return(int64(1), nil)
+	repo.On("DeleteTaskDependency", mock.Anything, mock.Anything).Return(int64(0), nil)
+
+	assert.NoError(t, service.RemoveDependency(context.TODO(), 1234, testOrgID, 22, 23))
+	assert.Equal(t, customErrors.ErrDependencyNotFound, service.RemoveDependency(context.TODO(), 1234, testOrgID, 22, 24))
+}
+
+func TestCompleteBlockedTask(t *testing.T) {
+	done, inProgress := "done", "in_progress"
+	testCases := []struct {
+		name          string
+		status        string
+		openBlockers  int64
+		expectedError error
+	}{
+		{name: "done while a blocker is open", status: done, openBlockers: 1, expectedError: customErrors.ErrTaskBlocked},
+		{name: "done once every blocker is done", status: done},
+		{name: "other statuses are not checked", status: inProgress, openBlockers: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newCommentService()
+			repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+			repo.On("CountOpenBlockers", mock.Anything, int64(22)).Return(tc.openBlockers, nil)
+			repo.On("ListWorkflowStatuses", mock.Anything, int64(6)).Return(defaultWorkflow(6), nil)
+			repo.On("UpdateTask", mock.Anything, mock.Anything).Return(subtask(22, 6, 0), nil)
+
+			err := service.UpdateTask(context.TODO(), 1234, testOrgID, UpdateTaskRequest{ID: 22, Status: &tc.status})
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedError != nil {
+				repo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDependencyGraph(t *testing.T) {
+	service, repo := newCommentService()
+	tasks := []taskdb.Task{subtask(22, 6, 0), subtask(23, 6, 0)}
+	repo.On("GetTasksByProjectId", mock.Anything, int64(6)).Return(tasks, nil)
+	repo.On("CountTaskComments", mock.Anything, []int64{22, 23}).Return([]taskdb.CountTaskCommentsRow(nil), nil)
+	repo.On("CountSubtasks", mock.Anything, []int64{22, 23}).Return([]taskdb.CountSubtasksRow(nil), nil)
+	repo.On("ListBlockedTaskIDs", mock.Anything, []int64{22, 23}).Return([]int64{23}, nil)
+	repo.On("ListTaskLabels", mock.Anything, []int64{22, 23}).Return([]taskdb.ListTaskLabelsRow(nil), nil)
+	repo.On("ListTaskCustomFieldValues", mock.Anything, []int64{22, 23}).Return([]taskdb.TaskCustomFieldValue(nil), nil)
+	repo.On("SumTaskTimeSpent", mock.Anything, []int64{22, 23}).Return([]taskdb.SumTaskTimeSpentRow(nil), nil)
+	repo.On("ListProjectDependencies", mock.Anything, int64(6)).Return([]taskdb.TaskDependency{{TaskID: 23, BlockedByID: 22, ProjectID: 6}}, nil)
+
+	graph, err := service.DependencyGraph(context.TODO(), 1234, testOrgID, 6)
+	assert.NoError(t, err)
+	assert.Len(t, graph.Nodes, 2)
+	assert.False(t, graph.Nodes[0].Blocked)
+	assert.True(t, graph.Nodes[1].Blocked)
+	assert.Equal(t, []taskdb.TaskDependency{{TaskID: 23, BlockedByID: 22, ProjectID: 6}}, graph.Edges)
+}
@@ -6,16 +6,95 @@ package taskdb
 
 import (
 	"context"
+	"database/sql"
 )
 
 type Querier interface {
+	// only a series still waiting for the occurrence due at due_at advances, so
+	// an occurrence is never counted twice
+	AdvanceTaskSeries(ctx context.Context, arg AdvanceTaskSeriesParams) (int64, error)
+	CountOpenBlockers(ctx context.Context, taskID int64) (int64, error)
+	CountProjectLabels(ctx context.Context, arg CountProjectLabelsParams) (int64, error)
+	// subtasks count as done when their status is in the done category
+	CountSubtasks(ctx context.Context, taskIds []int64) ([]CountSubtasksRow, error)
+	CountTaskComments(ctx context.Context, taskIds []int64) ([]CountTaskCommentsRow, error)
 	CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error)
+	CreateTaskAttachment(ctx context.Context, arg CreateTaskAttachmentParams) (TaskAttachment, error)
+	CreateTaskComment(ctx context.Context, arg CreateTaskCommentParams) (TaskComment, error)
+	CreateTaskDependency(ctx context.Context, arg CreateTaskDependencyParams) (TaskDependency, error)
+	// the task the series is set up on becomes its first occurrence
+	CreateTaskSeries(ctx context.Context, arg CreateTaskSeriesParams) (TaskSeries, error)
+	CreateTimeEntry(ctx context.Context, arg CreateTimeEntryParams) (TimeEntry, error)
 	DeleteTask(ctx context.Context, id int64) (int64, error)
+	DeleteTaskAttachment(ctx context.Context, id int64) (int64, error)
+	DeleteTaskComment(ctx context.Context, id int64) (int64, error)
+	DeleteTaskDependency(ctx context.Context, arg DeleteTaskDependencyParams) (int64, error)
+	DeleteTaskSeries(ctx context.Context, id int64) (int64, error)
+	DeleteTimeEntry(ctx context.Context, id int64) (int64, error)
+	DequeueAttachmentDeletion(ctx context.Context, storageKey string) error
+	EndTaskSeries(ctx context.Context, id int64) error
 	GetAllTasks(ctx context.Context) ([]Task, error)
+	GetTaskAttachment(ctx context.Context, arg GetTaskAttachmentParams) (TaskAttachment, error)
 	GetTaskById(ctx context.Context, id int64) (Task, error)
+	GetTaskByTitle(ctx context.Context, arg GetTaskByTitleParams) (Task, error)
+	GetTaskComment(ctx context.Context, arg GetTaskCommentParams) (TaskComment, error)
 	GetTasksByProjectId(ctx context.Context, projectID int64) ([]Task, error)
+	GetTasksByUserId(ctx context.Context, arg GetTasksByUserIdParams) ([]Task, error)
+	GetTaskSeries(ctx context.Context, id int64) (TaskSeries, error)
+	GetTimeEntry(ctx context.Context, arg GetTimeEntryParams) (TimeEntry, error)
+	IsOrganizationMember(ctx context.Context, arg IsOrganizationMemberParams) (bool, error)
+	IsProjectMember(ctx context.Context, arg IsProjectMemberParams) (bool, error)
+	// the tasks among task_ids with at least one blocker that is not done
+	ListBlockedTaskIDs(ctx context.Context, taskIds []int64) ([]int64, error)
+	ListBlockedTasks(ctx context.Context, blockedByID int64) ([]Task, error)
+	ListCustomFieldsByID(ctx context.Context, ids []int64) ([]CustomField, error)
+	ListDueTaskSeries(ctx context.Context, maxSeries int32) ([]ListDueTaskSeriesRow, error)
+	ListLabelIDsByName(ctx context.Context, arg ListLabelIDsByNameParams) ([]int64, error)
+	// newest first, including the changes of tasks deleted since
+	ListProjectActivity(ctx context.Context, arg ListProjectActivityParams) ([]ListProjectActivityRow, error)
+	ListProjectCustomFields(ctx context.Context, projectID int64) ([]CustomField, error)
+	ListProjectDependencies(ctx context.Context, projectID int64) ([]TaskDependency, error)
+	ListQueuedAttachmentDeletions(ctx context.Context, maxKeys int32) ([]string, error)
+	ListSubtasks(ctx context.Context, parentTaskID sql.NullInt64) ([]Task, error)
+	// the task itself followed by its parent, grandparent and so on; UNION stops
+	// on a cycle
+	ListTaskAncestorIDs(ctx context.Context, id int64) ([]int64, error)
+	ListTaskAttachments(ctx context.Context, taskID int64) ([]TaskAttachment, error)
+	ListTaskBlockers(ctx context.Context, taskID int64) ([]Task, error)
+	ListTaskChanges(ctx context.Context, taskID int64) ([]ListTaskChangesRow, error)
+	ListTaskCommentEdits(ctx context.Context, commentID int64) ([]TaskCommentEdit, error)
+	ListTaskCommentReplies(ctx context.Context, parentIds []int64) ([]TaskComment, error)
+	// top-level comments only, their replies come from ListTaskCommentReplies
+	ListTaskComments(ctx context.Context, arg ListTaskCommentsParams) ([]TaskComment, error)
+	ListTaskCustomFieldValues(ctx context.Context, taskIds []int64) ([]TaskCustomFieldValue, error)
+	ListTaskLabels(ctx context.Context, taskIds []int64) ([]ListTaskLabelsRow, error)
 	ListTasksWithFilters(ctx context.Context, arg ListTasksWithFiltersParams) ([]Task, error)
-	UpdateTask(ctx context.Context, arg UpdateTaskParams) error
+	ListTimeEntries(ctx context.Context, taskID int64) ([]TimeEntry, error)
+	// the task itself followed by every task it waits for, directly or through
+	// other tasks; UNION stops on a cycle
+	ListTransitiveBlockerIDs(ctx context.Context, id int64) ([]int64, error)
+	ListWorkflowStatuses(ctx context.Context, projectID int64) ([]WorkflowStatus, error)
+	QueueAttachmentDeletion(ctx context.Context, storageKey string) error
+	// cleared_field_ids are removed, the others are inserted or replaced
+	SetTaskCustomFieldValues(ctx context.Context, arg SetTaskCustomFieldValuesParams) error
+	// replaces the labels of a task; the DELETE and INSERT run as one statement
+	SetTaskLabels(ctx context.Context, arg SetTaskLabelsParams) error
+	StartTimeEntry(ctx context.Context, arg StartTimeEntryParams) (TimeEntry, error)
+	StopTimeEntry(ctx context.Context, arg StopTimeEntryParams) (TimeEntry, error)
+	// running timers count once they are stopped
+	SumProjectTimeByUser(ctx context.Context, arg SumProjectTimeByUserParams) ([]SumProjectTimeByUserRow, error)
+	SumTaskTimeSpent(ctx context.Context, taskIds []int64) ([]SumTaskTimeSpentRow, error)
+	// running timers count once they are stopped
+	SumUserTimeByProject(ctx context.Context, arg SumUserTimeByProjectParams) ([]SumUserTimeByProjectRow, error)
+	UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, error)
+	// the replaced body is kept in task_comment_edits by the same statement
+	UpdateTaskCommentBody(ctx context.Context, arg UpdateTaskCommentBodyParams) (TaskComment, error)
+	// open occurrences take the provided values too, their titles followed by
+	// their due date like every occurrence; occurrences in the done category keep
+	// theirs. A new rule starts counting again from starts_at.
+	UpdateTaskSeries(ctx context.Context, arg UpdateTaskSeriesParams) (TaskSeries, error)
+	// never below zero, and tasks without an estimate keep none
+	UseRemainingEstimate(ctx context.Context, arg UseRemainingEstimateParams) error
 }
 
 var _ Querier = (*Queries)(nil)
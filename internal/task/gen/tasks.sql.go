@@ -8,23 +8,164 @@ package taskdb
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+const advanceTaskSeries = `-- name: AdvanceTaskSeries :execrows
+UPDATE task_series
+SET
+  occurrences = occurrences + 1,
+  next_due_at = $1,
+  latest_task_id = $2,
+  updated_at = now()
+WHERE id = $3 AND next_due_at = $4::timestamp
+`
+
+type AdvanceTaskSeriesParams struct {
+	NextDueAt    sql.NullTime `json:"next_due_at"`
+	LatestTaskID int64        `json:"latest_task_id"`
+	ID           int64        `json:"id"`
+	DueAt        time.Time    `json:"due_at"`
+}
+
+// only a series still waiting for the occurrence due at due_at advances, so
+// an occurrence is never counted twice
+func (q *Queries) AdvanceTaskSeries(ctx context.Context, arg AdvanceTaskSeriesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, advanceTaskSeries,
+		arg.NextDueAt,
+		arg.LatestTaskID,
+		arg.ID,
+		arg.DueAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countOpenBlockers = `-- name: CountOpenBlockers :one
+SELECT COUNT(*) FROM task_dependencies d
+JOIN tasks t ON t.id = d.blocked_by_id
+JOIN workflow_statuses ws ON ws.project_id = t.project_id AND ws.name = t.status
+WHERE d.task_id = $1 AND ws.category <> 'done'
+`
+
+func (q *Queries) CountOpenBlockers(ctx context.Context, taskID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOpenBlockers, taskID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countProjectLabels = `-- name: CountProjectLabels :one
+SELECT COUNT(*) FROM labels WHERE project_id = $1 AND id = ANY($2::bigint[])
+`
+
+type CountProjectLabelsParams struct {
+	ProjectID int64   `json:"project_id"`
+	LabelIds  []int64 `json:"label_ids"`
+}
+
+func (q *Queries) CountProjectLabels(ctx context.Context, arg CountProjectLabelsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countProjectLabels, arg.ProjectID, pq.Array(arg.LabelIds))
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSubtasks = `-- name: CountSubtasks :many
+SELECT t.parent_task_id::bigint AS parent_task_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE ws.category = 'done') AS done
+FROM tasks t
+JOIN workflow_statuses ws ON ws.project_id = t.project_id AND ws.name = t.status
+WHERE t.parent_task_id = ANY($1::bigint[])
+GROUP BY t.parent_task_id
+`
+
+type CountSubtasksRow struct {
+	ParentTaskID int64 `json:"parent_task_id"`
+	Total        int64 `json:"total"`
+	Done         int64 `json:"done"`
+}
+
+// subtasks count as done when their status is in the done category
+func (q *Queries) CountSubtasks(ctx context.Context, taskIds []int64) ([]CountSubtasksRow, error) {
+	rows, err := q.db.QueryContext(ctx, countSubtasks, pq.Array(taskIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountSubtasksRow
+	for rows.Next() {
+		var i CountSubtasksRow
+		if err := rows.Scan(&i.ParentTaskID, &i.Total, &i.Done); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countTaskComments = `-- name: CountTaskComments :many
+SELECT task_id, COUNT(*) AS comment_count FROM task_comments
+WHERE task_id = ANY($1::bigint[])
+GROUP BY task_id
+`
+
+type CountTaskCommentsRow struct {
+	TaskID       int64 `json:"task_id"`
+	CommentCount int64 `json:"comment_count"`
+}
+
+func (q *Queries) CountTaskComments(ctx context.Context, taskIds []int64) ([]CountTaskCommentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countTaskComments, pq.Array(taskIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountTaskCommentsRow
+	for rows.Next() {
+		var i CountTaskCommentsRow
+		if err := rows.Scan(&i.TaskID, &i.CommentCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createTask = `-- name: CreateTask :one
 
-INSERT INTO tasks (project_id, assignee_id, title, description, status, priority, due_date)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at
+INSERT INTO tasks (project_id, assignee_id, title, description, status, priority, due_date, parent_task_id, series_id, original_estimate_minutes, remaining_estimate_minutes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at, parent_task_id, series_id, original_estimate_minutes, remaining_estimate_minutes
 `
 
 type CreateTaskParams struct {
-	ProjectID   int64         `json:"project_id"`
-	AssigneeID  sql.NullInt64 `json:"assignee_id"`
-	Title       string        `json:"title"`
-	Description string        `json:"description"`
-	Status      TaskStatus    `json:"status"`
-	Priority    TaskPriority  `json:"priority"`
-	DueDate     sql.NullTime  `json:"due_date"`
+	ProjectID                int64         `json:"project_id"`
+	AssigneeID               sql.NullInt64 `json:"assignee_id"`
+	Title                    string        `json:"title"`
+	Description              string        `json:"description"`
+	Status                   string        `json:"status"`
+	Priority                 TaskPriority  `json:"priority"`
+	DueDate                  sql.NullTime  `json:"due_date"`
+	ParentTaskID             sql.NullInt64 `json:"parent_task_id"`
+	SeriesID                 sql.NullInt64 `json:"series_id"`
+	OriginalEstimateMinutes  sql.NullInt32 `json:"original_estimate_minutes"`
+	RemainingEstimateMinutes sql.NullInt32 `json:"remaining_estimate_minutes"`
 }
 
 func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error) {
@@ -36,6 +177,10 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, e
 		arg.Status,
 		arg.Priority,
 		arg.DueDate,
+		arg.ParentTaskID,
+		arg.SeriesID,
+		arg.OriginalEstimateMinutes,
+		arg.RemainingEstimateMinutes,
 	)
 	var i Task
 	err := row.Scan(
@@ -49,6 +194,209 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, e
 		&i.DueDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ParentTaskID,
+		&i.SeriesID,
+		&i.OriginalEstimateMinutes,
+		&i.RemainingEstimateMinutes,
+	)
+	return i, err
+}
+
+const createTaskAttachment = `-- name: CreateTaskAttachment :one
+INSERT INTO task_attachments (task_id, project_id, uploader_id, file_name, content_type, size_bytes, checksum, storage_key)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, task_id, project_id, uploader_id, file_name, content_type, size_bytes, checksum, storage_key, created_at
+`
+
+type CreateTaskAttachmentParams struct {
+	TaskID      int64         `json:"task_id"`
+	ProjectID   int64         `json:"project_id"`
+	UploaderID  sql.NullInt32 `json:"uploader_id"`
+	FileName    string        `json:"file_name"`
+	ContentType string        `json:"content_type"`
+	SizeBytes   int64         `json:"size_bytes"`
+	Checksum    string        `json:"checksum"`
+	StorageKey  string        `json:"storage_key"`
+}
+
+func (q *Queries) CreateTaskAttachment(ctx context.Context, arg CreateTaskAttachmentParams) (TaskAttachment, error) {
+	row := q.db.QueryRowContext(ctx, createTaskAttachment,
+		arg.TaskID,
+		arg.ProjectID,
+		arg.UploaderID,
+		arg.FileName,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Checksum,
+		arg.StorageKey,
+	)
+	var i TaskAttachment
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ProjectID,
+		&i.UploaderID,
+		&i.FileName,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Checksum,
+		&i.StorageKey,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTaskComment = `-- name: CreateTaskComment :one
+INSERT INTO task_comments (task_id, parent_id, author_id, body)
+VALUES ($1, $2, $3, $4)
+RETURNING id, task_id, parent_id, author_id, body, created_at, updated_at, edited_at
+`
+
+type CreateTaskCommentParams struct {
+	TaskID   int64         `json:"task_id"`
+	ParentID sql.NullInt64 `json:"parent_id"`
+	AuthorID sql.NullInt32 `json:"author_id"`
+	Body     string        `json:"body"`
+}
+
+func (q *Queries) CreateTaskComment(ctx context.Context, arg CreateTaskCommentParams) (TaskComment, error) {
+	row := q.db.QueryRowContext(ctx, createTaskComment,
+		arg.TaskID,
+		arg.ParentID,
+		arg.AuthorID,
+		arg.Body,
+	)
+	var i TaskComment
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ParentID,
+		&i.AuthorID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EditedAt,
+	)
+	return i, err
+}
+
+const createTaskDependency = `-- name: CreateTaskDependency :one
+INSERT INTO task_dependencies (task_id, blocked_by_id, project_id)
+VALUES ($1, $2, $3)
+RETURNING task_id, blocked_by_id, project_id, created_at
+`
+
+type CreateTaskDependencyParams struct {
+	TaskID      int64 `json:"task_id"`
+	BlockedByID int64 `json:"blocked_by_id"`
+	ProjectID   int64 `json:"project_id"`
+}
+
+func (q *Queries) CreateTaskDependency(ctx context.Context, arg CreateTaskDependencyParams) (TaskDependency, error) {
+	row := q.db.QueryRowContext(ctx, createTaskDependency, arg.TaskID, arg.BlockedByID, arg.ProjectID)
+	var i TaskDependency
+	err := row.Scan(
+		&i.TaskID,
+		&i.BlockedByID,
+		&i.ProjectID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTaskSeries = `-- name: CreateTaskSeries :one
+WITH series AS (
+  INSERT INTO task_series (project_id, created_by, title, description, assignee_id, priority, rule, starts_at, next_due_at, latest_task_id)
+  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+  RETURNING id, project_id, created_by, title, description, assignee_id, priority, rule, starts_at, occurrences, next_due_at, latest_task_id, created_at, updated_at
+), linked AS (
+  UPDATE tasks SET series_id = series.id FROM series WHERE tasks.id = series.latest_task_id
+)
+SELECT id, project_id, created_by, title, description, assignee_id, priority, rule, starts_at, occurrences, next_due_at, latest_task_id, created_at, updated_at FROM series
+`
+
+type CreateTaskSeriesParams struct {
+	ProjectID    int64         `json:"project_id"`
+	CreatedBy    int32         `json:"created_by"`
+	Title        string        `json:"title"`
+	Description  string        `json:"description"`
+	AssigneeID   sql.NullInt64 `json:"assignee_id"`
+	Priority     TaskPriority  `json:"priority"`
+	Rule         string        `json:"rule"`
+	StartsAt     time.Time     `json:"starts_at"`
+	NextDueAt    sql.NullTime  `json:"next_due_at"`
+	LatestTaskID sql.NullInt64 `json:"latest_task_id"`
+}
+
+// the task the series is set up on becomes its first occurrence
+func (q *Queries) CreateTaskSeries(ctx context.Context, arg CreateTaskSeriesParams) (TaskSeries, error) {
+	row := q.db.QueryRowContext(ctx, createTaskSeries,
+		arg.ProjectID,
+		arg.CreatedBy,
+		arg.Title,
+		arg.Description,
+		arg.AssigneeID,
+		arg.Priority,
+		arg.Rule,
+		arg.StartsAt,
+		arg.NextDueAt,
+		arg.LatestTaskID,
+	)
+	var i TaskSeries
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.CreatedBy,
+		&i.Title,
+		&i.Description,
+		&i.AssigneeID,
+		&i.Priority,
+		&i.Rule,
+		&i.StartsAt,
+		&i.Occurrences,
+		&i.NextDueAt,
+		&i.LatestTaskID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createTimeEntry = `-- name: CreateTimeEntry :one
+INSERT INTO time_entries (task_id, project_id, user_id, started_at, ended_at, note)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, task_id, project_id, user_id, started_at, ended_at, minutes, note, created_at
+`
+
+type CreateTimeEntryParams struct {
+	TaskID    int64        `json:"task_id"`
+	ProjectID int64        `json:"project_id"`
+	UserID    int32        `json:"user_id"`
+	StartedAt time.Time    `json:"started_at"`
+	EndedAt   sql.NullTime `json:"ended_at"`
+	Note      string       `json:"note"`
+}
+
+func (q *Queries) CreateTimeEntry(ctx context.Context, arg CreateTimeEntryParams) (TimeEntry, error) {
+	row := q.db.QueryRowContext(ctx, createTimeEntry,
+		arg.TaskID,
+		arg.ProjectID,
+		arg.UserID,
+		arg.StartedAt,
+		arg.EndedAt,
+		arg.Note,
+	)
+	var i TimeEntry
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Minutes,
+		&i.Note,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -65,8 +413,91 @@ func (q *Queries) DeleteTask(ctx context.Context, id int64) (int64, error) {
 	return result.RowsAffected()
 }
 
+const deleteTaskAttachment = `-- name: DeleteTaskAttachment :execrows
+DELETE FROM task_attachments WHERE id = $1
+`
+
+func (q *Queries) DeleteTaskAttachment(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTaskAttachment, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTaskComment = `-- name: DeleteTaskComment :execrows
+DELETE FROM task_comments WHERE id = $1
+`
+
+func (q *Queries) DeleteTaskComment(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTaskComment, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTaskDependency = `-- name: DeleteTaskDependency :execrows
+DELETE FROM task_dependencies WHERE task_id = $1 AND blocked_by_id = $2
+`
+
+type DeleteTaskDependencyParams struct {
+	TaskID      int64 `json:"task_id"`
+	BlockedByID int64 `json:"blocked_by_id"`
+}
+
+func (q *Queries) DeleteTaskDependency(ctx context.Context, arg DeleteTaskDependencyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTaskDependency, arg.TaskID, arg.BlockedByID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTaskSeries = `-- name: DeleteTaskSeries :execrows
+DELETE FROM task_series WHERE id = $1
+`
+
+func (q *Queries) DeleteTaskSeries(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTaskSeries, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTimeEntry = `-- name: DeleteTimeEntry :execrows
+DELETE FROM time_entries WHERE id = $1
+`
+
+func (q *Queries) DeleteTimeEntry(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTimeEntry, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const dequeueAttachmentDeletion = `-- name: DequeueAttachmentDeletion :exec
+SELECT dequeue_attachment_deletion($1::text)
+`
+
+func (q *Queries) DequeueAttachmentDeletion(ctx context.Context, storageKey string) error {
+	_, err := q.db.ExecContext(ctx, dequeueAttachmentDeletion, storageKey)
+	return err
+}
+
+const endTaskSeries = `-- name: EndTaskSeries :exec
+SELECT end_task_series($1::bigint)
+`
+
+func (q *Queries) EndTaskSeries(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, endTaskSeries, id)
+	return err
+}
+
 const getAllTasks = `-- name: GetAllTasks :many
-SELECT id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at FROM tasks ORDER BY id
+SELECT id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at, parent_task_id, series_id, original_estimate_minutes, remaining_estimate_minutes FROM tasks ORDER BY id
 `
 
 func (q *Queries) GetAllTasks(ctx context.Context) ([]Task, error) {
@@ -89,6 +520,12 @@ func (q *Queries) GetAllTasks(ctx context.Context) ([]Task, error) {
 			&i.DueDate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ParentTaskID,
+			&i.SeriesID,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
 		); err != nil {
 			return nil, err
 		}
@@ -103,8 +540,35 @@ func (q *Queries) GetAllTasks(ctx context.Context) ([]Task, error) {
 	return items, nil
 }
 
+const getTaskAttachment = `-- name: GetTaskAttachment :one
+SELECT id, task_id, project_id, uploader_id, file_name, content_type, size_bytes, checksum, storage_key, created_at FROM task_attachments WHERE id = $1 AND task_id = $2
+`
+
+type GetTaskAttachmentParams struct {
+	ID     int64 `json:"id"`
+	TaskID int64 `json:"task_id"`
+}
+
+func (q *Queries) GetTaskAttachment(ctx context.Context, arg GetTaskAttachmentParams) (TaskAttachment, error) {
+	row := q.db.QueryRowContext(ctx, getTaskAttachment, arg.ID, arg.TaskID)
+	var i TaskAttachment
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ProjectID,
+		&i.UploaderID,
+		&i.FileName,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Checksum,
+		&i.StorageKey,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getTaskById = `-- name: GetTaskById :one
-SELECT id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at FROM tasks WHERE id = $1
+SELECT id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at, parent_task_id, series_id, original_estimate_minutes, remaining_estimate_minutes FROM tasks WHERE id = $1
 `
 
 func (q *Queries) GetTaskById(ctx context.Context, id int64) (Task, error) {
@@ -121,12 +585,72 @@ func (q *Queries) GetTaskById(ctx context.Context, id int64) (Task, error) {
 		&i.DueDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ParentTaskID,
+		&i.SeriesID,
+		&i.OriginalEstimateMinutes,
+		&i.RemainingEstimateMinutes,
+	)
+	return i, err
+}
+
+const getTaskByTitle = `-- name: GetTaskByTitle :one
+SELECT id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at, parent_task_id, series_id, original_estimate_minutes, remaining_estimate_minutes FROM tasks WHERE project_id = $1 AND title = $2
+`
+
+type GetTaskByTitleParams struct {
+	ProjectID int64  `json:"project_id"`
+	Title     string `json:"title"`
+}
+
+func (q *Queries) GetTaskByTitle(ctx context.Context, arg GetTaskByTitleParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, getTaskByTitle, arg.ProjectID, arg.Title)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.AssigneeID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ParentTaskID,
+		&i.SeriesID,
+		&i.OriginalEstimateMinutes,
+		&i.RemainingEstimateMinutes,
+	)
+	return i, err
+}
+
+const getTaskComment = `-- name: GetTaskComment :one
+SELECT id, task_id, parent_id, author_id, body, created_at, updated_at, edited_at FROM task_comments WHERE id = $1 AND task_id = $2
+`
+
+type GetTaskCommentParams struct {
+	ID     int64 `json:"id"`
+	TaskID int64 `json:"task_id"`
+}
+
+func (q *Queries) GetTaskComment(ctx context.Context, arg GetTaskCommentParams) (TaskComment, error) {
+	row := q.db.QueryRowContext(ctx, getTaskComment, arg.ID, arg.TaskID)
+	var i TaskComment
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ParentID,
+		&i.AuthorID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EditedAt,
 	)
 	return i, err
 }
 
 const getTasksByProjectId = `-- name: GetTasksByProjectId :many
-SELECT id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at FROM tasks WHERE project_id = $1 ORDER BY id
+SELECT id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at, parent_task_id, series_id, original_estimate_minutes, remaining_estimate_minutes FROM tasks WHERE project_id = $1 ORDER BY id
 `
 
 func (q *Queries) GetTasksByProjectId(ctx context.Context, projectID int64) ([]Task, error) {
@@ -149,6 +673,12 @@ func (q *Queries) GetTasksByProjectId(ctx context.Context, projectID int64) ([]T
 			&i.DueDate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ParentTaskID,
+			&i.SeriesID,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
 		); err != nil {
 			return nil, err
 		}
@@ -163,39 +693,984 @@ func (q *Queries) GetTasksByProjectId(ctx context.Context, projectID int64) ([]T
 	return items, nil
 }
 
-const listTasksWithFilters = `-- name: ListTasksWithFilters :many
-SELECT id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at
-FROM tasks
-WHERE 
-    (project_id = COALESCE($1, project_id))
-  AND (assignee_id = COALESCE($2, assignee_id))
-  AND (status = ANY($3))
-  AND (priority = COALESCE($4, priority))
-  AND (due_date >= COALESCE($5, due_date))
-  AND (due_date <= COALESCE($6, due_date))
-ORDER BY due_date
-LIMIT $8 OFFSET $7
+const getTasksByUserId = `-- name: GetTasksByUserId :many
+SELECT t.id, t.project_id, t.assignee_id, t.title, t.description, t.status, t.priority, t.due_date, t.created_at, t.updated_at, t.parent_task_id, t.series_id, t.original_estimate_minutes, t.remaining_estimate_minutes FROM tasks t
+JOIN projects p ON p.id = t.project_id
+WHERE p.organization_id = $2
+  AND (p.user_id = $1
+   OR p.id IN (SELECT pm.project_id FROM project_members pm WHERE pm.user_id = $1))
+ORDER BY t.id
 `
 
-type ListTasksWithFiltersParams struct {
-	ProjectID   sql.NullInt64    `json:"project_id"`
-	AssigneeID  sql.NullInt64    `json:"assignee_id"`
-	Statuses    NullTaskStatus   `json:"statuses"`
-	Priority    NullTaskPriority `json:"priority"`
-	DueDateFrom sql.NullTime     `json:"due_date_from"`
-	DueDateTo   sql.NullTime     `json:"due_date_to"`
-	Offset      int32            `json:"offset"`
-	Limit       int32            `json:"limit"`
+type GetTasksByUserIdParams struct {
+	UserID         int32 `json:"user_id"`
+	OrganizationID int64 `json:"organization_id"`
 }
 
-func (q *Queries) ListTasksWithFilters(ctx context.Context, arg ListTasksWithFiltersParams) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, listTasksWithFilters,
+func (q *Queries) GetTasksByUserId(ctx context.Context, arg GetTasksByUserIdParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, getTasksByUserId, arg.UserID, arg.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.AssigneeID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ParentTaskID,
+			&i.SeriesID,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTaskSeries = `-- name: GetTaskSeries :one
+SELECT id, project_id, created_by, title, description, assignee_id, priority, rule, starts_at, occurrences, next_due_at, latest_task_id, created_at, updated_at FROM task_series WHERE id = $1
+`
+
+func (q *Queries) GetTaskSeries(ctx context.Context, id int64) (TaskSeries, error) {
+	row := q.db.QueryRowContext(ctx, getTaskSeries, id)
+	var i TaskSeries
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.CreatedBy,
+		&i.Title,
+		&i.Description,
+		&i.AssigneeID,
+		&i.Priority,
+		&i.Rule,
+		&i.StartsAt,
+		&i.Occurrences,
+		&i.NextDueAt,
+		&i.LatestTaskID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTimeEntry = `-- name: GetTimeEntry :one
+SELECT id, task_id, project_id, user_id, started_at, ended_at, minutes, note, created_at FROM time_entries WHERE id = $1 AND task_id = $2
+`
+
+type GetTimeEntryParams struct {
+	ID     int64 `json:"id"`
+	TaskID int64 `json:"task_id"`
+}
+
+func (q *Queries) GetTimeEntry(ctx context.Context, arg GetTimeEntryParams) (TimeEntry, error) {
+	row := q.db.QueryRowContext(ctx, getTimeEntry, arg.ID, arg.TaskID)
+	var i TimeEntry
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Minutes,
+		&i.Note,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const isOrganizationMember = `-- name: IsOrganizationMember :one
+SELECT EXISTS (
+  SELECT 1 FROM organization_members WHERE organization_id = $1 AND user_id = $2
+)
+`
+
+type IsOrganizationMemberParams struct {
+	OrganizationID int64 `json:"organization_id"`
+	UserID         int32 `json:"user_id"`
+}
+
+func (q *Queries) IsOrganizationMember(ctx context.Context, arg IsOrganizationMemberParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isOrganizationMember, arg.OrganizationID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const isProjectMember = `-- name: IsProjectMember :one
+SELECT EXISTS (
+  SELECT 1 FROM project_members WHERE project_id = $1 AND user_id = $2
+)
+`
+
+type IsProjectMemberParams struct {
+	ProjectID int64 `json:"project_id"`
+	UserID    int32 `json:"user_id"`
+}
+
+func (q *Queries) IsProjectMember(ctx context.Context, arg IsProjectMemberParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isProjectMember, arg.ProjectID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listBlockedTaskIDs = `-- name: ListBlockedTaskIDs :many
+SELECT DISTINCT d.task_id FROM task_dependencies d
+JOIN tasks t ON t.id = d.blocked_by_id
+JOIN workflow_statuses ws ON ws.project_id = t.project_id AND ws.name = t.status
+WHERE d.task_id = ANY($1::bigint[]) AND ws.category <> 'done'
+`
+
+// the tasks among task_ids with at least one blocker that is not done
+func (q *Queries) ListBlockedTaskIDs(ctx context.Context, taskIds []int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listBlockedTaskIDs, pq.Array(taskIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var task_id int64
+		if err := rows.Scan(&task_id); err != nil {
+			return nil, err
+		}
+		items = append(items, task_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBlockedTasks = `-- name: ListBlockedTasks :many
+SELECT t.id, t.project_id, t.assignee_id, t.title, t.description, t.status, t.priority, t.due_date, t.created_at, t.updated_at, t.parent_task_id, t.series_id, t.original_estimate_minutes, t.remaining_estimate_minutes FROM tasks t
+JOIN task_dependencies d ON d.task_id = t.id
+WHERE d.blocked_by_id = $1
+ORDER BY t.id
+`
+
+func (q *Queries) ListBlockedTasks(ctx context.Context, blockedByID int64) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listBlockedTasks, blockedByID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.AssigneeID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ParentTaskID,
+			&i.SeriesID,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustomFieldsByID = `-- name: ListCustomFieldsByID :many
+SELECT id, project_id, name, field_type, options, created_at, updated_at FROM custom_fields WHERE id = ANY($1::bigint[]) ORDER BY id
+`
+
+func (q *Queries) ListCustomFieldsByID(ctx context.Context, ids []int64) ([]CustomField, error) {
+	rows, err := q.db.QueryContext(ctx, listCustomFieldsByID, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CustomField
+	for rows.Next() {
+		var i CustomField
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.FieldType,
+			pq.Array(&i.Options),
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueTaskSeries = `-- name: ListDueTaskSeries :many
+SELECT series_id, user_id, organization_id FROM due_task_series($1::int)
+`
+
+type ListDueTaskSeriesRow struct {
+	SeriesID       int64 `json:"series_id"`
+	UserID         int32 `json:"user_id"`
+	OrganizationID int64 `json:"organization_id"`
+}
+
+func (q *Queries) ListDueTaskSeries(ctx context.Context, maxSeries int32) ([]ListDueTaskSeriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDueTaskSeries, maxSeries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDueTaskSeriesRow
+	for rows.Next() {
+		var i ListDueTaskSeriesRow
+		if err := rows.Scan(&i.SeriesID, &i.UserID, &i.OrganizationID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLabelIDsByName = `-- name: ListLabelIDsByName :many
+SELECT id FROM labels WHERE project_id = $1 AND lower(name) = ANY($2::text[])
+`
+
+type ListLabelIDsByNameParams struct {
+	ProjectID int64    `json:"project_id"`
+	Names     []string `json:"names"`
+}
+
+func (q *Queries) ListLabelIDsByName(ctx context.Context, arg ListLabelIDsByNameParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listLabelIDsByName, arg.ProjectID, pq.Array(arg.Names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectActivity = `-- name: ListProjectActivity :many
+SELECT tc.id, tc.task_id, tc.project_id, tc.task_title, tc.actor_id, u.name AS actor_name, tc.action, tc.field, tc.old_value, tc.new_value, tc.changed_at
+FROM task_changes tc
+LEFT JOIN users u ON u.id = tc.actor_id
+WHERE tc.project_id = $1
+ORDER BY tc.id DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListProjectActivityParams struct {
+	ProjectID int64 `json:"project_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+type ListProjectActivityRow struct {
+	ID        int64          `json:"id"`
+	TaskID    int64          `json:"task_id"`
+	ProjectID int64          `json:"project_id"`
+	TaskTitle string         `json:"task_title"`
+	ActorID   sql.NullInt32  `json:"actor_id"`
+	ActorName sql.NullString `json:"actor_name"`
+	Action    string         `json:"action"`
+	Field     sql.NullString `json:"field"`
+	OldValue  sql.NullString `json:"old_value"`
+	NewValue  sql.NullString `json:"new_value"`
+	ChangedAt time.Time      `json:"changed_at"`
+}
+
+// newest first, including the changes of tasks deleted since
+func (q *Queries) ListProjectActivity(ctx context.Context, arg ListProjectActivityParams) ([]ListProjectActivityRow, error) {
+	rows, err := q.db.QueryContext(ctx, listProjectActivity, arg.ProjectID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProjectActivityRow
+	for rows.Next() {
+		var i ListProjectActivityRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.ProjectID,
+			&i.TaskTitle,
+			&i.ActorID,
+			&i.ActorName,
+			&i.Action,
+			&i.Field,
+			&i.OldValue,
+			&i.NewValue,
+			&i.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectCustomFields = `-- name: ListProjectCustomFields :many
+SELECT id, project_id, name, field_type, options, created_at, updated_at FROM custom_fields WHERE project_id = $1 ORDER BY id
+`
+
+func (q *Queries) ListProjectCustomFields(ctx context.Context, projectID int64) ([]CustomField, error) {
+	rows, err := q.db.QueryContext(ctx, listProjectCustomFields, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CustomField
+	for rows.Next() {
+		var i CustomField
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.FieldType,
+			pq.Array(&i.Options),
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectDependencies = `-- name: ListProjectDependencies :many
+SELECT task_id, blocked_by_id, project_id, created_at FROM task_dependencies WHERE project_id = $1 ORDER BY task_id, blocked_by_id
+`
+
+func (q *Queries) ListProjectDependencies(ctx context.Context, projectID int64) ([]TaskDependency, error) {
+	rows, err := q.db.QueryContext(ctx, listProjectDependencies, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskDependency
+	for rows.Next() {
+		var i TaskDependency
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.BlockedByID,
+			&i.ProjectID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQueuedAttachmentDeletions = `-- name: ListQueuedAttachmentDeletions :many
+SELECT storage_key FROM queued_attachment_deletions($1::int)
+`
+
+func (q *Queries) ListQueuedAttachmentDeletions(ctx context.Context, maxKeys int32) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listQueuedAttachmentDeletions, maxKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var storage_key string
+		if err := rows.Scan(&storage_key); err != nil {
+			return nil, err
+		}
+		items = append(items, storage_key)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubtasks = `-- name: ListSubtasks :many
+SELECT id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at, parent_task_id, series_id, original_estimate_minutes, remaining_estimate_minutes FROM tasks WHERE parent_task_id = $1 ORDER BY id
+`
+
+func (q *Queries) ListSubtasks(ctx context.Context, parentTaskID sql.NullInt64) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listSubtasks, parentTaskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.AssigneeID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ParentTaskID,
+			&i.SeriesID,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskAncestorIDs = `-- name: ListTaskAncestorIDs :many
+WITH RECURSIVE ancestors AS (
+  SELECT id, parent_task_id FROM tasks WHERE id = $1
+  UNION
+  SELECT t.id, t.parent_task_id FROM tasks t JOIN ancestors a ON t.id = a.parent_task_id
+)
+SELECT id FROM ancestors
+`
+
+// the task itself followed by its parent, grandparent and so on; UNION stops
+// on a cycle
+func (q *Queries) ListTaskAncestorIDs(ctx context.Context, id int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskAncestorIDs, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskAttachments = `-- name: ListTaskAttachments :many
+SELECT id, task_id, project_id, uploader_id, file_name, content_type, size_bytes, checksum, storage_key, created_at FROM task_attachments WHERE task_id = $1 ORDER BY created_at, id
+`
+
+func (q *Queries) ListTaskAttachments(ctx context.Context, taskID int64) ([]TaskAttachment, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskAttachments, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskAttachment
+	for rows.Next() {
+		var i TaskAttachment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.ProjectID,
+			&i.UploaderID,
+			&i.FileName,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Checksum,
+			&i.StorageKey,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskBlockers = `-- name: ListTaskBlockers :many
+SELECT t.id, t.project_id, t.assignee_id, t.title, t.description, t.status, t.priority, t.due_date, t.created_at, t.updated_at, t.parent_task_id, t.series_id, t.original_estimate_minutes, t.remaining_estimate_minutes FROM tasks t
+JOIN task_dependencies d ON d.blocked_by_id = t.id
+WHERE d.task_id = $1
+ORDER BY t.id
+`
+
+func (q *Queries) ListTaskBlockers(ctx context.Context, taskID int64) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskBlockers, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.AssigneeID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ParentTaskID,
+			&i.SeriesID,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskChanges = `-- name: ListTaskChanges :many
+SELECT tc.id, tc.task_id, tc.project_id, tc.task_title, tc.actor_id, u.name AS actor_name, tc.action, tc.field, tc.old_value, tc.new_value, tc.changed_at
+FROM task_changes tc
+LEFT JOIN users u ON u.id = tc.actor_id
+WHERE tc.task_id = $1
+ORDER BY tc.id
+`
+
+type ListTaskChangesRow struct {
+	ID        int64          `json:"id"`
+	TaskID    int64          `json:"task_id"`
+	ProjectID int64          `json:"project_id"`
+	TaskTitle string         `json:"task_title"`
+	ActorID   sql.NullInt32  `json:"actor_id"`
+	ActorName sql.NullString `json:"actor_name"`
+	Action    string         `json:"action"`
+	Field     sql.NullString `json:"field"`
+	OldValue  sql.NullString `json:"old_value"`
+	NewValue  sql.NullString `json:"new_value"`
+	ChangedAt time.Time      `json:"changed_at"`
+}
+
+func (q *Queries) ListTaskChanges(ctx context.Context, taskID int64) ([]ListTaskChangesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskChanges, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTaskChangesRow
+	for rows.Next() {
+		var i ListTaskChangesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.ProjectID,
+			&i.TaskTitle,
+			&i.ActorID,
+			&i.ActorName,
+			&i.Action,
+			&i.Field,
+			&i.OldValue,
+			&i.NewValue,
+			&i.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskCommentEdits = `-- name: ListTaskCommentEdits :many
+SELECT id, comment_id, body, edited_by, edited_at FROM task_comment_edits WHERE comment_id = $1 ORDER BY id
+`
+
+func (q *Queries) ListTaskCommentEdits(ctx context.Context, commentID int64) ([]TaskCommentEdit, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskCommentEdits, commentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskCommentEdit
+	for rows.Next() {
+		var i TaskCommentEdit
+		if err := rows.Scan(
+			&i.ID,
+			&i.CommentID,
+			&i.Body,
+			&i.EditedBy,
+			&i.EditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskCommentReplies = `-- name: ListTaskCommentReplies :many
+SELECT id, task_id, parent_id, author_id, body, created_at, updated_at, edited_at FROM task_comments
+WHERE parent_id = ANY($1::bigint[])
+ORDER BY id
+`
+
+func (q *Queries) ListTaskCommentReplies(ctx context.Context, parentIds []int64) ([]TaskComment, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskCommentReplies, pq.Array(parentIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskComment
+	for rows.Next() {
+		var i TaskComment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.ParentID,
+			&i.AuthorID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskComments = `-- name: ListTaskComments :many
+SELECT id, task_id, parent_id, author_id, body, created_at, updated_at, edited_at FROM task_comments
+WHERE task_id = $1 AND parent_id IS NULL
+ORDER BY id
+LIMIT $2 OFFSET $3
+`
+
+type ListTaskCommentsParams struct {
+	TaskID int64 `json:"task_id"`
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+// top-level comments only, their replies come from ListTaskCommentReplies
+func (q *Queries) ListTaskComments(ctx context.Context, arg ListTaskCommentsParams) ([]TaskComment, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskComments, arg.TaskID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskComment
+	for rows.Next() {
+		var i TaskComment
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.ParentID,
+			&i.AuthorID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskCustomFieldValues = `-- name: ListTaskCustomFieldValues :many
+SELECT task_id, field_id, project_id, value, updated_at FROM task_custom_field_values
+WHERE task_id = ANY($1::bigint[])
+ORDER BY task_id, field_id
+`
+
+func (q *Queries) ListTaskCustomFieldValues(ctx context.Context, taskIds []int64) ([]TaskCustomFieldValue, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskCustomFieldValues, pq.Array(taskIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskCustomFieldValue
+	for rows.Next() {
+		var i TaskCustomFieldValue
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.FieldID,
+			&i.ProjectID,
+			&i.Value,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskLabels = `-- name: ListTaskLabels :many
+SELECT tl.task_id, l.id, l.project_id, l.name, l.color, l.created_at, l.updated_at FROM task_labels tl
+JOIN labels l ON l.id = tl.label_id
+WHERE tl.task_id = ANY($1::bigint[])
+ORDER BY tl.task_id, lower(l.name)
+`
+
+type ListTaskLabelsRow struct {
+	TaskID    int64     `json:"task_id"`
+	ID        int64     `json:"id"`
+	ProjectID int64     `json:"project_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) ListTaskLabels(ctx context.Context, taskIds []int64) ([]ListTaskLabelsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskLabels, pq.Array(taskIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTaskLabelsRow
+	for rows.Next() {
+		var i ListTaskLabelsRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Color,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTasksWithFilters = `-- name: ListTasksWithFilters :many
+SELECT id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at, parent_task_id, series_id, original_estimate_minutes, remaining_estimate_minutes
+FROM tasks
+WHERE 
+    (project_id = COALESCE($1, project_id))
+  AND (assignee_id = COALESCE($2, assignee_id))
+  AND (cardinality($3::text[]) = 0 OR status = ANY($3::text[]))
+  AND (priority = COALESCE($4, priority))
+  AND (due_date >= COALESCE($5, due_date))
+  AND (due_date <= COALESCE($6, due_date))
+  AND project_id IN (
+    SELECT id FROM projects WHERE user_id = $7
+    UNION
+    SELECT pm.project_id FROM project_members pm WHERE pm.user_id = $7
+  )
+  AND project_id IN (SELECT id FROM projects WHERE organization_id = $8)
+  -- labels are lower-cased names; a task needs one of them, or all of them
+  -- when all_labels is set
+  AND (cardinality($9::text[]) = 0 OR (
+    SELECT COUNT(DISTINCT lower(l.name)) FROM task_labels tl
+    JOIN labels l ON l.id = tl.label_id
+    WHERE tl.task_id = tasks.id AND lower(l.name) = ANY($9::text[])
+  ) >= CASE WHEN $10::boolean THEN cardinality($9::text[]) ELSE 1 END)
+  -- every custom field filter must match: field_values holds the JSON of the
+  -- wanted value, which matches equal values and multi-select values containing it
+  AND NOT EXISTS (
+    SELECT 1 FROM unnest($11::bigint[], $12::text[]) AS f(field_id, value)
+    WHERE NOT EXISTS (
+      SELECT 1 FROM task_custom_field_values v
+      WHERE v.task_id = tasks.id AND v.field_id = f.field_id AND v.value @> f.value::jsonb
+    )
+  )
+-- sort_field_id orders by the value of a custom field, tasks without a value last
+ORDER BY
+  CASE WHEN NOT $13::boolean THEN (
+    SELECT v.value FROM task_custom_field_values v WHERE v.task_id = tasks.id AND v.field_id = $14
+  ) END ASC NULLS LAST,
+  CASE WHEN $13::boolean THEN (
+    SELECT v.value FROM task_custom_field_values v WHERE v.task_id = tasks.id AND v.field_id = $14
+  ) END DESC NULLS LAST,
+  due_date
+LIMIT $16 OFFSET $15
+`
+
+type ListTasksWithFiltersParams struct {
+	ProjectID      sql.NullInt64    `json:"project_id"`
+	AssigneeID     sql.NullInt64    `json:"assignee_id"`
+	Statuses       []string         `json:"statuses"`
+	Priority       NullTaskPriority `json:"priority"`
+	DueDateFrom    sql.NullTime     `json:"due_date_from"`
+	DueDateTo      sql.NullTime     `json:"due_date_to"`
+	UserID         int32            `json:"user_id"`
+	OrganizationID int64            `json:"organization_id"`
+	Labels         []string         `json:"labels"`
+	AllLabels      bool             `json:"all_labels"`
+	FieldIds       []int64          `json:"field_ids"`
+	FieldValues    []string         `json:"field_values"`
+	SortDesc       bool             `json:"sort_desc"`
+	SortFieldID    sql.NullInt64    `json:"sort_field_id"`
+	Offset         int32            `json:"offset"`
+	Limit          int32            `json:"limit"`
+}
+
+func (q *Queries) ListTasksWithFilters(ctx context.Context, arg ListTasksWithFiltersParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listTasksWithFilters,
 		arg.ProjectID,
 		arg.AssigneeID,
-		arg.Statuses,
+		pq.Array(arg.Statuses),
 		arg.Priority,
 		arg.DueDateFrom,
 		arg.DueDateTo,
+		arg.UserID,
+		arg.OrganizationID,
+		pq.Array(arg.Labels),
+		arg.AllLabels,
+		pq.Array(arg.FieldIds),
+		pq.Array(arg.FieldValues),
+		arg.SortDesc,
+		arg.SortFieldID,
 		arg.Offset,
 		arg.Limit,
 	)
@@ -203,18 +1678,131 @@ func (q *Queries) ListTasksWithFilters(ctx context.Context, arg ListTasksWithFil
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Task
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.AssigneeID,
+			&i.Title,
+			&i.Description,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ParentTaskID,
+			&i.SeriesID,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+			&i.OriginalEstimateMinutes,
+			&i.RemainingEstimateMinutes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTimeEntries = `-- name: ListTimeEntries :many
+SELECT id, task_id, project_id, user_id, started_at, ended_at, minutes, note, created_at FROM time_entries WHERE task_id = $1 ORDER BY started_at, id
+`
+
+func (q *Queries) ListTimeEntries(ctx context.Context, taskID int64) ([]TimeEntry, error) {
+	rows, err := q.db.QueryContext(ctx, listTimeEntries, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TimeEntry
 	for rows.Next() {
-		var i Task
+		var i TimeEntry
 		if err := rows.Scan(
 			&i.ID,
+			&i.TaskID,
 			&i.ProjectID,
-			&i.AssigneeID,
-			&i.Title,
-			&i.Description,
-			&i.Status,
-			&i.Priority,
-			&i.DueDate,
+			&i.UserID,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.Minutes,
+			&i.Note,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransitiveBlockerIDs = `-- name: ListTransitiveBlockerIDs :many
+WITH RECURSIVE blockers AS (
+  SELECT $1::bigint AS id
+  UNION
+  SELECT d.blocked_by_id FROM task_dependencies d JOIN blockers b ON d.task_id = b.id
+)
+SELECT id FROM blockers
+`
+
+// the task itself followed by every task it waits for, directly or through
+// other tasks; UNION stops on a cycle
+func (q *Queries) ListTransitiveBlockerIDs(ctx context.Context, id int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listTransitiveBlockerIDs, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkflowStatuses = `-- name: ListWorkflowStatuses :many
+SELECT id, project_id, name, category, position, transitions, created_at, updated_at FROM workflow_statuses WHERE project_id = $1 ORDER BY position
+`
+
+func (q *Queries) ListWorkflowStatuses(ctx context.Context, projectID int64) ([]WorkflowStatus, error) {
+	rows, err := q.db.QueryContext(ctx, listWorkflowStatuses, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkflowStatus
+	for rows.Next() {
+		var i WorkflowStatus
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Name,
+			&i.Category,
+			&i.Position,
+			pq.Array(&i.Transitions),
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -231,7 +1819,283 @@ func (q *Queries) ListTasksWithFilters(ctx context.Context, arg ListTasksWithFil
 	return items, nil
 }
 
-const updateTask = `-- name: UpdateTask :exec
+const queueAttachmentDeletion = `-- name: QueueAttachmentDeletion :exec
+INSERT INTO attachment_deletions (storage_key) VALUES ($1)
+`
+
+func (q *Queries) QueueAttachmentDeletion(ctx context.Context, storageKey string) error {
+	_, err := q.db.ExecContext(ctx, queueAttachmentDeletion, storageKey)
+	return err
+}
+
+const setTaskCustomFieldValues = `-- name: SetTaskCustomFieldValues :exec
+WITH cleared AS (
+  DELETE FROM task_custom_field_values
+  WHERE task_id = $1 AND field_id = ANY($2::bigint[])
+)
+INSERT INTO task_custom_field_values (task_id, field_id, project_id, value)
+SELECT $1, f.field_id, $3, f.value::jsonb
+FROM unnest($4::bigint[], $5::text[]) AS f(field_id, value)
+ON CONFLICT (task_id, field_id) DO UPDATE SET value = EXCLUDED.value, updated_at = now()
+`
+
+type SetTaskCustomFieldValuesParams struct {
+	TaskID          int64    `json:"task_id"`
+	ClearedFieldIds []int64  `json:"cleared_field_ids"`
+	ProjectID       int64    `json:"project_id"`
+	FieldIds        []int64  `json:"field_ids"`
+	FieldValues     []string `json:"field_values"`
+}
+
+// cleared_field_ids are removed, the others are inserted or replaced
+func (q *Queries) SetTaskCustomFieldValues(ctx context.Context, arg SetTaskCustomFieldValuesParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskCustomFieldValues,
+		arg.TaskID,
+		pq.Array(arg.ClearedFieldIds),
+		arg.ProjectID,
+		pq.Array(arg.FieldIds),
+		pq.Array(arg.FieldValues),
+	)
+	return err
+}
+
+const setTaskLabels = `-- name: SetTaskLabels :exec
+WITH removed AS (
+  DELETE FROM task_labels
+  WHERE task_id = $1 AND label_id <> ALL($2::bigint[])
+)
+INSERT INTO task_labels (task_id, label_id, project_id)
+SELECT $1::bigint, unnest($2::bigint[]), $3::bigint
+ON CONFLICT DO NOTHING
+`
+
+type SetTaskLabelsParams struct {
+	TaskID    int64   `json:"task_id"`
+	LabelIds  []int64 `json:"label_ids"`
+	ProjectID int64   `json:"project_id"`
+}
+
+// replaces the labels of a task; the DELETE and INSERT run as one statement
+func (q *Queries) SetTaskLabels(ctx context.Context, arg SetTaskLabelsParams) error {
+	_, err := q.db.ExecContext(ctx, setTaskLabels, arg.TaskID, pq.Array(arg.LabelIds), arg.ProjectID)
+	return err
+}
+
+const startTimeEntry = `-- name: StartTimeEntry :one
+INSERT INTO time_entries (task_id, project_id, user_id, started_at, note)
+VALUES ($1, $2, $3, now(), $4)
+RETURNING id, task_id, project_id, user_id, started_at, ended_at, minutes, note, created_at
+`
+
+type StartTimeEntryParams struct {
+	TaskID    int64  `json:"task_id"`
+	ProjectID int64  `json:"project_id"`
+	UserID    int32  `json:"user_id"`
+	Note      string `json:"note"`
+}
+
+func (q *Queries) StartTimeEntry(ctx context.Context, arg StartTimeEntryParams) (TimeEntry, error) {
+	row := q.db.QueryRowContext(ctx, startTimeEntry,
+		arg.TaskID,
+		arg.ProjectID,
+		arg.UserID,
+		arg.Note,
+	)
+	var i TimeEntry
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Minutes,
+		&i.Note,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const stopTimeEntry = `-- name: StopTimeEntry :one
+UPDATE time_entries SET ended_at = now()
+WHERE task_id = $1 AND user_id = $2 AND ended_at IS NULL
+RETURNING id, task_id, project_id, user_id, started_at, ended_at, minutes, note, created_at
+`
+
+type StopTimeEntryParams struct {
+	TaskID int64 `json:"task_id"`
+	UserID int32 `json:"user_id"`
+}
+
+func (q *Queries) StopTimeEntry(ctx context.Context, arg StopTimeEntryParams) (TimeEntry, error) {
+	row := q.db.QueryRowContext(ctx, stopTimeEntry, arg.TaskID, arg.UserID)
+	var i TimeEntry
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ProjectID,
+		&i.UserID,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Minutes,
+		&i.Note,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const sumProjectTimeByUser = `-- name: SumProjectTimeByUser :many
+SELECT te.user_id, u.name, u.email, SUM(te.minutes)::bigint AS minutes
+FROM time_entries te
+JOIN users u ON u.id = te.user_id
+WHERE te.project_id = $1
+  AND te.ended_at IS NOT NULL
+  AND te.started_at >= $2
+  AND te.started_at < $3
+GROUP BY te.user_id, u.name, u.email
+ORDER BY minutes DESC, te.user_id
+`
+
+type SumProjectTimeByUserParams struct {
+	ProjectID     int64     `json:"project_id"`
+	StartedFrom   time.Time `json:"started_from"`
+	StartedBefore time.Time `json:"started_before"`
+}
+
+type SumProjectTimeByUserRow struct {
+	UserID  int32  `json:"user_id"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Minutes int64  `json:"minutes"`
+}
+
+// running timers count once they are stopped
+func (q *Queries) SumProjectTimeByUser(ctx context.Context, arg SumProjectTimeByUserParams) ([]SumProjectTimeByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, sumProjectTimeByUser, arg.ProjectID, arg.StartedFrom, arg.StartedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SumProjectTimeByUserRow
+	for rows.Next() {
+		var i SumProjectTimeByUserRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Name,
+			&i.Email,
+			&i.Minutes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumTaskTimeSpent = `-- name: SumTaskTimeSpent :many
+SELECT task_id, COALESCE(SUM(minutes), 0)::bigint AS minutes FROM time_entries
+WHERE task_id = ANY($1::bigint[])
+GROUP BY task_id
+`
+
+type SumTaskTimeSpentRow struct {
+	TaskID  int64 `json:"task_id"`
+	Minutes int64 `json:"minutes"`
+}
+
+func (q *Queries) SumTaskTimeSpent(ctx context.Context, taskIds []int64) ([]SumTaskTimeSpentRow, error) {
+	rows, err := q.db.QueryContext(ctx, sumTaskTimeSpent, pq.Array(taskIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SumTaskTimeSpentRow
+	for rows.Next() {
+		var i SumTaskTimeSpentRow
+		if err := rows.Scan(&i.TaskID, &i.Minutes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumUserTimeByProject = `-- name: SumUserTimeByProject :many
+SELECT te.project_id, p.name, SUM(te.minutes)::bigint AS minutes
+FROM time_entries te
+JOIN projects p ON p.id = te.project_id
+WHERE te.user_id = $1
+  AND te.ended_at IS NOT NULL
+  AND te.started_at >= $2
+  AND te.started_at < $3
+  AND p.organization_id = $4
+  -- only the projects the viewer can see
+  AND te.project_id IN (
+    SELECT id FROM projects WHERE user_id = $5
+    UNION
+    SELECT pm.project_id FROM project_members pm WHERE pm.user_id = $5
+  )
+GROUP BY te.project_id, p.name
+ORDER BY minutes DESC, te.project_id
+`
+
+type SumUserTimeByProjectParams struct {
+	UserID         int32     `json:"user_id"`
+	StartedFrom    time.Time `json:"started_from"`
+	StartedBefore  time.Time `json:"started_before"`
+	OrganizationID int64     `json:"organization_id"`
+	ViewerID       int32     `json:"viewer_id"`
+}
+
+type SumUserTimeByProjectRow struct {
+	ProjectID int64  `json:"project_id"`
+	Name      string `json:"name"`
+	Minutes   int64  `json:"minutes"`
+}
+
+// running timers count once they are stopped
+func (q *Queries) SumUserTimeByProject(ctx context.Context, arg SumUserTimeByProjectParams) ([]SumUserTimeByProjectRow, error) {
+	rows, err := q.db.QueryContext(ctx, sumUserTimeByProject,
+		arg.UserID,
+		arg.StartedFrom,
+		arg.StartedBefore,
+		arg.OrganizationID,
+		arg.ViewerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SumUserTimeByProjectRow
+	for rows.Next() {
+		var i SumUserTimeByProjectRow
+		if err := rows.Scan(&i.ProjectID, &i.Name, &i.Minutes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateTask = `-- name: UpdateTask :one
 
 UPDATE tasks
 SET
@@ -240,27 +2104,185 @@ SET
   due_date = COALESCE($3, due_date),
   status = COALESCE($4, status),
   priority = COALESCE($5, priority),
+  assignee_id = COALESCE($6, assignee_id),
+  parent_task_id = CASE WHEN $7::boolean THEN $8 ELSE parent_task_id END,
+  original_estimate_minutes = COALESCE($9, original_estimate_minutes),
+  remaining_estimate_minutes = COALESCE($10, remaining_estimate_minutes),
   updated_at = now()
-WHERE id = $6
+WHERE id = $11
+RETURNING id, project_id, assignee_id, title, description, status, priority, due_date, created_at, updated_at, parent_task_id, series_id, original_estimate_minutes, remaining_estimate_minutes
 `
 
 type UpdateTaskParams struct {
+	Title                    sql.NullString   `json:"title"`
+	Description              sql.NullString   `json:"description"`
+	DueDate                  sql.NullTime     `json:"due_date"`
+	Status                   sql.NullString   `json:"status"`
+	Priority                 NullTaskPriority `json:"priority"`
+	AssigneeID               sql.NullInt64    `json:"assignee_id"`
+	SetParent                bool             `json:"set_parent"`
+	ParentTaskID             sql.NullInt64    `json:"parent_task_id"`
+	OriginalEstimateMinutes  sql.NullInt32    `json:"original_estimate_minutes"`
+	RemainingEstimateMinutes sql.NullInt32    `json:"remaining_estimate_minutes"`
+	ID                       int64            `json:"id"`
+}
+
+func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, updateTask,
+		arg.Title,
+		arg.Description,
+		arg.DueDate,
+		arg.Status,
+		arg.Priority,
+		arg.AssigneeID,
+		arg.SetParent,
+		arg.ParentTaskID,
+		arg.OriginalEstimateMinutes,
+		arg.RemainingEstimateMinutes,
+		arg.ID,
+	)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.AssigneeID,
+		&i.Title,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ParentTaskID,
+		&i.SeriesID,
+		&i.OriginalEstimateMinutes,
+		&i.RemainingEstimateMinutes,
+	)
+	return i, err
+}
+
+const updateTaskCommentBody = `-- name: UpdateTaskCommentBody :one
+WITH previous AS (
+  INSERT INTO task_comment_edits (comment_id, body, edited_by)
+  SELECT c.id, c.body, $1::int FROM task_comments c WHERE c.id = $2
+)
+UPDATE task_comments
+SET body = $3, edited_at = now(), updated_at = now()
+WHERE id = $2
+RETURNING id, task_id, parent_id, author_id, body, created_at, updated_at, edited_at
+`
+
+type UpdateTaskCommentBodyParams struct {
+	EditedBy int32  `json:"edited_by"`
+	ID       int64  `json:"id"`
+	Body     string `json:"body"`
+}
+
+// the replaced body is kept in task_comment_edits by the same statement
+func (q *Queries) UpdateTaskCommentBody(ctx context.Context, arg UpdateTaskCommentBodyParams) (TaskComment, error) {
+	row := q.db.QueryRowContext(ctx, updateTaskCommentBody, arg.EditedBy, arg.ID, arg.Body)
+	var i TaskComment
+	err := row.Scan(
+		&i.ID,
+		&i.TaskID,
+		&i.ParentID,
+		&i.AuthorID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EditedAt,
+	)
+	return i, err
+}
+
+const updateTaskSeries = `-- name: UpdateTaskSeries :one
+WITH series AS (
+  UPDATE task_series
+  SET
+    title = COALESCE($1, title),
+    description = COALESCE($2, description),
+    assignee_id = COALESCE($3, assignee_id),
+    priority = COALESCE($4, priority),
+    rule = COALESCE($5, rule),
+    starts_at = COALESCE($6, starts_at),
+    occurrences = CASE WHEN $5::text IS NULL THEN occurrences ELSE 1 END,
+    next_due_at = CASE WHEN $5::text IS NULL THEN next_due_at ELSE $7 END,
+    updated_at = now()
+  WHERE id = $8
+  RETURNING id, project_id, created_by, title, description, assignee_id, priority, rule, starts_at, occurrences, next_due_at, latest_task_id, created_at, updated_at
+), occurrences AS (
+  UPDATE tasks t
+  SET
+    title = CASE WHEN $1::text IS NULL THEN t.title
+      ELSE series.title || ' (' || to_char(t.due_date, 'YYYY-MM-DD') || ')' END,
+    description = COALESCE($2, t.description),
+    assignee_id = COALESCE($3, t.assignee_id),
+    priority = COALESCE($4, t.priority),
+    updated_at = now()
+  FROM series, workflow_statuses ws
+  WHERE t.series_id = series.id AND ws.project_id = t.project_id AND ws.name = t.status AND ws.category <> 'done'
+)
+SELECT id, project_id, created_by, title, description, assignee_id, priority, rule, starts_at, occurrences, next_due_at, latest_task_id, created_at, updated_at FROM series
+`
+
+type UpdateTaskSeriesParams struct {
 	Title       sql.NullString   `json:"title"`
 	Description sql.NullString   `json:"description"`
-	DueDate     sql.NullTime     `json:"due_date"`
-	Status      NullTaskStatus   `json:"status"`
+	AssigneeID  sql.NullInt64    `json:"assignee_id"`
 	Priority    NullTaskPriority `json:"priority"`
+	Rule        sql.NullString   `json:"rule"`
+	StartsAt    sql.NullTime     `json:"starts_at"`
+	NextDueAt   sql.NullTime     `json:"next_due_at"`
 	ID          int64            `json:"id"`
 }
 
-func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) error {
-	_, err := q.db.ExecContext(ctx, updateTask,
+// open occurrences take the provided values too, their titles followed by
+// their due date like every occurrence; occurrences in the done category keep
+// theirs. A new rule starts counting again from starts_at.
+func (q *Queries) UpdateTaskSeries(ctx context.Context, arg UpdateTaskSeriesParams) (TaskSeries, error) {
+	row := q.db.QueryRowContext(ctx, updateTaskSeries,
 		arg.Title,
 		arg.Description,
-		arg.DueDate,
-		arg.Status,
+		arg.AssigneeID,
 		arg.Priority,
+		arg.Rule,
+		arg.StartsAt,
+		arg.NextDueAt,
 		arg.ID,
 	)
+	var i TaskSeries
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.CreatedBy,
+		&i.Title,
+		&i.Description,
+		&i.AssigneeID,
+		&i.Priority,
+		&i.Rule,
+		&i.StartsAt,
+		&i.Occurrences,
+		&i.NextDueAt,
+		&i.LatestTaskID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const useRemainingEstimate = `-- name: UseRemainingEstimate :exec
+UPDATE tasks
+SET remaining_estimate_minutes = GREATEST(remaining_estimate_minutes - $1::int, 0)
+WHERE id = $2 AND remaining_estimate_minutes IS NOT NULL
+`
+
+type UseRemainingEstimateParams struct {
+	Minutes int32 `json:"minutes"`
+	ID      int64 `json:"id"`
+}
+
+// never below zero, and tasks without an estimate keep none
+func (q *Queries) UseRemainingEstimate(ctx context.Context, arg UseRemainingEstimateParams) error {
+	_, err := q.db.ExecContext(ctx, useRemainingEstimate, arg.Minutes, arg.ID)
 	return err
 }
@@ -1,4 +1,3 @@
-
 package task
 
 import (
@@ -11,6 +10,7 @@ import (
 	"github.com/Gkemhcs/taskpilot/internal/auth"
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	"github.com/Gkemhcs/taskpilot/internal/middleware"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
 	"github.com/Gkemhcs/taskpilot/internal/types"
 
 	"github.com/Gkemhcs/taskpilot/internal/user"
@@ -37,14 +37,39 @@ type TaskHandler struct {
 	projectService types.ProjectReader
 }
 
-func RegisterTaskRoutes(router *gin.RouterGroup, taskHandler *TaskHandler, jwtManager *auth.JWTManager) {
-	taskRouter := router.Group("/tasks", middleware.JWTAuthMiddleware(taskHandler.logger, jwtManager))
+func RegisterTaskRoutes(router *gin.RouterGroup, taskHandler *TaskHandler, jwtManager *auth.JWTManager, orgResolver middleware.OrganizationResolver) {
+	taskRouter := router.Group("/tasks", middleware.JWTAuthMiddleware(taskHandler.logger, jwtManager), middleware.RequireScope(taskHandler.logger, "tasks"), middleware.RequireOrganization(taskHandler.logger, orgResolver))
 	{
 		taskRouter.POST("/", taskHandler.CreateTask)
 		taskRouter.GET("/:id", taskHandler.GetTaskByID)
 		taskRouter.DELETE("/:id", taskHandler.DeleteTask)
 		taskRouter.PATCH("/:id", taskHandler.UpdateTask)
 		taskRouter.GET("/filter", taskHandler.FilterTasks)
+		taskRouter.GET("/time", taskHandler.GetUserTime)
+		taskRouter.GET("/:id/comments", taskHandler.ListComments)
+		taskRouter.POST("/:id/comments", taskHandler.CreateComment)
+		taskRouter.PATCH("/:id/comments/:commentId", taskHandler.UpdateComment)
+		taskRouter.DELETE("/:id/comments/:commentId", taskHandler.DeleteComment)
+		taskRouter.GET("/:id/comments/:commentId/edits", taskHandler.ListCommentEdits)
+		taskRouter.GET("/:id/subtasks", taskHandler.ListSubtasks)
+		taskRouter.POST("/:id/subtasks", taskHandler.CreateSubtask)
+		taskRouter.GET("/:id/dependencies", taskHandler.ListDependencies)
+		taskRouter.POST("/:id/dependencies", taskHandler.AddDependency)
+		taskRouter.DELETE("/:id/dependencies/:blockedById", taskHandler.RemoveDependency)
+		taskRouter.POST("/:id/recurrence", taskHandler.SetRecurrence)
+		taskRouter.GET("/:id/series", taskHandler.GetSeries)
+		taskRouter.PATCH("/:id/series", taskHandler.UpdateSeries)
+		taskRouter.DELETE("/:id/series", taskHandler.DeleteSeries)
+		taskRouter.GET("/:id/time", taskHandler.GetTaskTime)
+		taskRouter.POST("/:id/time", taskHandler.LogTime)
+		taskRouter.POST("/:id/time/start", taskHandler.StartTimer)
+		taskRouter.POST("/:id/time/stop", taskHandler.StopTimer)
+		taskRouter.DELETE("/:id/time/:entryId", taskHandler.DeleteTimeEntry)
+		taskRouter.GET("/:id/attachments", taskHandler.ListAttachments)
+		taskRouter.POST("/:id/attachments", taskHandler.UploadAttachment)
+		taskRouter.GET("/:id/attachments/:attachmentId/download", taskHandler.GetAttachmentLink)
+		taskRouter.DELETE("/:id/attachments/:attachmentId", taskHandler.DeleteAttachment)
+		taskRouter.GET("/:id/history", taskHandler.GetTaskHistory)
 
 	}
 }
@@ -57,6 +82,8 @@ func RegisterTaskRoutes(router *gin.RouterGroup, taskHandler *TaskHandler, jwtMa
 // @Param        task  body      CreateTaskRequest true  "Task creation input"
 // @Success      201   {object}  map[string]interface{}
 // @Failure      400   {object}  map[string]interface{}
+// @Failure      403   {object}  map[string]interface{}
+// @Failure      404   {object}  map[string]interface{}
 // @Failure      500   {object}  map[string]interface{}
 // @Router       /api/v1/tasks/ [post]
 // @Security BearerAuth
@@ -69,6 +96,12 @@ func (t *TaskHandler) CreateTask(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
+	t.createTask(c, createTaskRequest)
+}
+
+// createTask validates the request, resolves the assignee and creates the
+// task, for both top-level tasks and subtasks.
+func (t *TaskHandler) createTask(c *gin.Context, createTaskRequest CreateTaskRequest) {
 	if createTaskRequest.AssigneeEmail == "" {
 		t.logger.Errorf("%v", customErrors.ErrAssigneeMissingFromBody)
 		utils.Error(c, http.StatusBadRequest, customErrors.ErrAssigneeMissingFromBody.Error())
@@ -82,9 +115,6 @@ func (t *TaskHandler) CreateTask(c *gin.Context) {
 	if createTaskRequest.Priority == "" {
 		createTaskRequest.Priority = "medium"
 	}
-	if createTaskRequest.Status == "" {
-		createTaskRequest.Status = "todo"
-	}
 	if createTaskRequest.Title == "" {
 		t.logger.Errorf("%v", customErrors.ErrorTaskTitleMissing)
 		utils.Error(c, http.StatusBadRequest, customErrors.ErrorTaskTitleMissing.Error())
@@ -95,9 +125,25 @@ func (t *TaskHandler) CreateTask(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, customErrors.ErrMissingProjectID.Error())
 		return
 	}
+	val, exists := c.Get("userID")
+	if !exists {
+		t.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrUserIDNotFoundInContext.Error())
+		return
+	}
+	userID, ok := val.(int)
+	if !ok {
+		t.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrInvalidUserId.Error())
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	_, err = t.projectService.GetProjectById(ctx, createTaskRequest.ProjectID)
+	_, err := t.projectService.GetProjectById(ctx, userID, orgID, createTaskRequest.ProjectID)
 	if errors.Is(err, customErrors.ErrProjectIDNotExist) {
 		t.logger.Errorf("%v", customErrors.ErrParentProjectIDNotFound)
 		utils.Error(c, http.StatusBadRequest, customErrors.ErrParentProjectIDNotFound.Error())
@@ -106,7 +152,7 @@ func (t *TaskHandler) CreateTask(c *gin.Context) {
 	}
 	if err != nil {
 		t.logger.Errorf("%v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 
@@ -119,24 +165,35 @@ func (t *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 	createTaskInput := CreateTaskInput{
-		ProjectID:   createTaskRequest.ProjectID,
-		AssigneeID:  int(user.ID),
-		Title:       createTaskRequest.Title,
-		Status:      createTaskRequest.Status,
-		Priority:    createTaskRequest.Priority,
-		DueDate:     createTaskRequest.DueDate,
-		Description: createTaskRequest.Description,
+		ProjectID:               createTaskRequest.ProjectID,
+		AssigneeID:              int(user.ID),
+		Title:                   createTaskRequest.Title,
+		Status:                  createTaskRequest.Status,
+		Priority:                createTaskRequest.Priority,
+		DueDate:                 createTaskRequest.DueDate,
+		Description:             createTaskRequest.Description,
+		ParentTaskID:            createTaskRequest.ParentTaskID,
+		LabelIDs:                createTaskRequest.LabelIDs,
+		CustomFields:            createTaskRequest.CustomFields,
+		Recurrence:              createTaskRequest.Recurrence,
+		OriginalEstimateMinutes: createTaskRequest.OriginalEstimateMinutes,
 	}
 	ctx2, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	task, err := t.taskService.CreateTask(ctx2, createTaskInput)
+	task, err := t.taskService.CreateTask(ctx2, userID, orgID, createTaskInput)
 	if err != nil {
 		t.logger.Errorf("%v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	responses, err := t.taskService.TaskResponses(ctx2, []taskdb.Task{*task})
+	if err != nil {
+		t.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 	utils.Success(c, http.StatusCreated, map[string]any{
-		"data":    task,
+		"data":    responses[0],
 		"message": "task created successfully",
 	})
 }
@@ -148,6 +205,8 @@ func (t *TaskHandler) CreateTask(c *gin.Context) {
 // @Param        id   path      int  true  "Task ID"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /api/v1/tasks/{id} [get]
 // @Security BearerAuth
@@ -159,21 +218,38 @@ func (t *TaskHandler) GetTaskByID(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidTaskID.Error())
 		return
 	}
+	val, exists := c.Get("userID")
+	if !exists {
+		t.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrUserIDNotFoundInContext.Error())
+		return
+	}
+	userID, ok := val.(int)
+	if !ok {
+		t.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrInvalidUserId.Error())
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	task, err := t.taskService.GetTaskByID(ctx, id)
-	if errors.Is(err, customErrors.ErrTaskNotFound) {
+	task, err := t.taskService.GetTaskByID(ctx, userID, orgID, id)
+	if err != nil {
 		t.logger.Errorf("%v", err)
-		utils.Error(c, http.StatusBadRequest, customErrors.ErrTaskNotFound.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
+	responses, err := t.taskService.TaskResponses(ctx, []taskdb.Task{*task})
 	if err != nil {
 		t.logger.Errorf("%v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 	utils.Success(c, http.StatusOK, map[string]any{
-		"data":    task,
+		"data":    responses[0],
 		"message": "request succeeded",
 	})
 
@@ -186,6 +262,8 @@ func (t *TaskHandler) GetTaskByID(c *gin.Context) {
 // @Param        id   path      int  true  "Task ID"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /api/v1/tasks/{id} [delete]
 // @Security BearerAuth
@@ -197,12 +275,28 @@ func (t *TaskHandler) DeleteTask(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidTaskID.Error())
 		return
 	}
+	val, exists := c.Get("userID")
+	if !exists {
+		t.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrUserIDNotFoundInContext.Error())
+		return
+	}
+	userID, ok := val.(int)
+	if !ok {
+		t.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrInvalidUserId.Error())
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	err = t.taskService.DeleteTask(ctx, id)
+	err = t.taskService.DeleteTask(ctx, userID, orgID, id)
 	if err != nil {
 		t.logger.Errorf("%v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 	utils.Success(c, http.StatusOK, map[string]any{
@@ -221,9 +315,25 @@ func (t *TaskHandler) DeleteTask(c *gin.Context) {
 // @Router       /api/v1/tasks/ [get]
 // @Security BearerAuth
 func (t *TaskHandler) GetAllTasks(c *gin.Context) {
+	val, exists := c.Get("userID")
+	if !exists {
+		t.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrUserIDNotFoundInContext.Error())
+		return
+	}
+	userID, ok := val.(int)
+	if !ok {
+		t.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrInvalidUserId.Error())
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
-	tasks, err := t.taskService.GetAllTasks(ctx)
+	tasks, err := t.taskService.GetAllTasks(ctx, userID, orgID)
 	if err != nil {
 		t.logger.Errorf("%v", err)
 		utils.Error(c, http.StatusBadRequest, err.Error())
@@ -237,22 +347,30 @@ func (t *TaskHandler) GetAllTasks(c *gin.Context) {
 		})
 		return
 	}
+	responses, err := t.taskService.TaskResponses(ctx, tasks)
+	if err != nil {
+		t.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
 	t.logger.Info("request succeeded")
 	utils.Success(c, http.StatusOK, map[string]any{
-		"data":    tasks,
+		"data":    responses,
 		"message": "request succeeded successfully",
 	})
 
 }
 
 // @Summary      Update task
-// @Description  Updates an existing task
+// @Description  Updates an existing task; assignee_email reassigns it to another project member
 // @Tags         tasks
 // @Accept       json
 // @Produce      json
 // @Param        task  body      UpdateTaskRequest true  "Task update input"
 // @Success      200   {object}  map[string]interface{}
 // @Failure      400   {object}  map[string]interface{}
+// @Failure      403   {object}  map[string]interface{}
+// @Failure      404   {object}  map[string]interface{}
 // @Failure      500   {object}  map[string]interface{}
 // @Router       /api/v1/tasks/ [patch]
 // @Security BearerAuth
@@ -262,20 +380,49 @@ func (t *TaskHandler) UpdateTask(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, "Invalid request body")
 		return
 	}
-	id:=c.Param("id")
-	taskID,err:=strconv.Atoi(id)
-	if err!=nil{
-		t.logger.Errorf("%v",err)
-		utils.Error(c,http.StatusBadRequest,customErrors.ErrInvalidTaskID.Error())
-		return 
+	id := c.Param("id")
+	taskID, err := strconv.Atoi(id)
+	if err != nil {
+		t.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidTaskID.Error())
+		return
 
 	}
-	req.ID=int64(taskID)
+	req.ID = int64(taskID)
+	val, exists := c.Get("userID")
+	if !exists {
+		t.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrUserIDNotFoundInContext.Error())
+		return
+	}
+	userID, ok := val.(int)
+	if !ok {
+		t.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrInvalidUserId.Error())
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
+	if req.AssigneeEmail != nil {
+		assignee, err := t.userService.GetUserByEmail(c.Request.Context(), *req.AssigneeEmail)
+		if err != nil {
+			t.logger.Errorf("%v", err)
+			utils.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		assigneeID := int64(assignee.ID)
+		req.AssigneeID = &assigneeID
+	}
 
-	err = t.taskService.UpdateTask(c.Request.Context(), req)
-	if err != nil {
+	err = t.taskService.UpdateTask(c.Request.Context(), userID, orgID, req)
+	if errors.Is(err, customErrors.ErrNextOccurrenceDelayed) {
+		// the task is updated, the scheduler creates the occurrence later
+		t.logger.Warnf("task %d: %v", req.ID, err)
+	} else if err != nil {
 		t.logger.Errorf(" error is %v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 	t.logger.Infof("task  %d updated successfully", req.ID)
@@ -291,6 +438,8 @@ func (t *TaskHandler) UpdateTask(c *gin.Context) {
 // @Param        filter query TaskFilterRequest false "Task filter parameters"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /api/v1/tasks/filter [get]
 // @Security BearerAuth
@@ -301,13 +450,70 @@ func (h *TaskHandler) FilterTasks(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, "Invalid query parameters")
 		return
 	}
+	val, exists := c.Get("userID")
+	if !exists {
+		h.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrUserIDNotFoundInContext.Error())
+		return
+	}
+	userID, ok := val.(int)
+	if !ok {
+		h.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrInvalidUserId.Error())
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
 
-	tasks, err := h.taskService.FilterTasks(c.Request.Context(), &req)
+	tasks, err := h.taskService.FilterTasks(c.Request.Context(), userID, orgID, &req)
+	if errors.Is(err, customErrors.ErrForbidden) || errors.Is(err, customErrors.ErrProjectIDNotExist) || errors.Is(err, customErrors.ErrInvalidLabelMatch) ||
+		errors.Is(err, customErrors.ErrInvalidCustomFieldFilter) || errors.Is(err, customErrors.ErrInvalidCustomFieldValue) ||
+		errors.Is(err, customErrors.ErrCustomFieldNotFound) || errors.Is(err, customErrors.ErrInvalidSortOrder) || errors.Is(err, customErrors.ErrInvalidPagination) {
+		h.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
 	if err != nil {
 		h.logger.Errorf("Failed to fetch filtered tasks: %v", err)
 		utils.Error(c, http.StatusInternalServerError, "Could not filter tasks")
 		return
 	}
+	responses, err := h.taskService.TaskResponses(c.Request.Context(), tasks)
+	if err != nil {
+		h.logger.Errorf("Failed to count task comments: %v", err)
+		utils.Error(c, http.StatusInternalServerError, "Could not filter tasks")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, responses)
+}
 
-	utils.Success(c, http.StatusOK, tasks)
+// taskScope reads the caller, their organization and the task id shared by
+// the routes under /tasks/:id. When one is missing the error response is
+// written and ok is false.
+func (t *TaskHandler) taskScope(c *gin.Context) (userID int, orgID int, taskID int, ok bool) {
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidTaskID.Error())
+		return 0, 0, 0, false
+	}
+	val, exists := c.Get("userID")
+	if !exists {
+		t.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrUserIDNotFoundInContext.Error())
+		return 0, 0, 0, false
+	}
+	userID, ok = val.(int)
+	if !ok {
+		t.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrInvalidUserId.Error())
+		return 0, 0, 0, false
+	}
+	orgID, ok = middleware.OrganizationID(c)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	return userID, orgID, taskID, true
 }
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/Gkemhcs/taskpilot/internal/auth"
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	"github.com/Gkemhcs/taskpilot/internal/middleware"
 	"github.com/Gkemhcs/taskpilot/internal/project"
@@ -44,11 +46,19 @@ func SetupNewTaskHandler() (*TaskHandler, *MockTaskRepo, *project.MockProjectRep
 	}
 	jwtManager := auth.NewJWTManager(params)
 
+	// mocking the ownership lookups: task 999 lives in a project of another user,
+	// everything else belongs to the test user 1234
+	authzMockRepo := new(authz.MockAuthzRepo)
+	authzMockRepo.On("GetTaskProjectRole", mock.Anything, authzdb.GetTaskProjectRoleParams{ID: 999, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetTaskProjectRoleRow{ProjectID: 77, UserID: 4321}, nil)
+	authzMockRepo.On("GetTaskProjectRole", mock.Anything, mock.Anything).Return(authzdb.GetTaskProjectRoleRow{UserID: 1234}, nil)
+	authzMockRepo.On("GetProjectRole", mock.Anything, mock.Anything).Return(authzdb.GetProjectRoleRow{UserID: 1234}, nil)
+	authorizer := authz.NewAuthorizationService(authzMockRepo)
+
 	//initialising task service
-	taskService := NewTaskService(taskMockRepo)
+	taskService := NewTaskService(taskMockRepo, authorizer)
 
 	//initialising taskproject service
-	projectService := project.NewProjectService(projectMockRepo)
+	projectService := project.NewProjectService(projectMockRepo, authorizer)
 
 	//user service
 	userService := user.NewUserService(userMockRepo)
@@ -63,12 +73,23 @@ func SetupNewTaskHandler() (*TaskHandler, *MockTaskRepo, *project.MockProjectRep
 
 }
 
+// testOrgID is the organization every test request acts in
+const testOrgID = 7
+
+// organizationMiddleware resolves every request to testOrgID, as if it were the caller's personal workspace
+func organizationMiddleware(logger *logrus.Logger) gin.HandlerFunc {
+	resolver := new(middleware.MockOrganizationResolver)
+	resolver.On("ResolveOrganization", mock.Anything, mock.Anything, 0).Return(testOrgID, nil)
+	return middleware.RequireOrganization(logger, resolver)
+}
+
 func TestCreateTaskHandler(t *testing.T) {
 	taskHandler, taskMockRepo, projectMockRepo, userMockRepo, jwtManager, logger := SetupNewTaskHandler()
 
 	jwtToken, err := jwtManager.GenerateAccessToken(1234, "test-user", "gudi@gmail")
 	require.NoError(t, err)
 	jwtMiddleware := middleware.JWTAuthMiddleware(logger, jwtManager)
+	orgMiddleware := organizationMiddleware(logger)
 
 	testCases := []struct {
 		testName                   string
@@ -113,13 +134,15 @@ func TestCreateTaskHandler(t *testing.T) {
 					},
 					Title:       "Adding Navbar",
 					Description: "to add  a navbar on right of home page",
-					Status:      taskdb.TaskStatusTODO,
+					Status:      "TODO",
 					Priority:    taskdb.TaskPriorityMEDIUM,
 					DueDate: sql.NullTime{
 						Time:  time.Date(2025, 7, 11, 14, 30, 0, 0, time.UTC),
 						Valid: true,
 					},
 				}
+				taskMockRepo.On("IsProjectMember", mock.Anything, mock.Anything).Return(true, nil)
+				taskMockRepo.On("ListWorkflowStatuses", mock.Anything, int64(4123)).Return(defaultWorkflow(4123), nil)
 				taskMockRepo.On("CreateTask", mock.Anything, params).Return(
 					taskdb.Task{
 						Title:     "Adding Navbar",
@@ -129,12 +152,44 @@ func TestCreateTaskHandler(t *testing.T) {
 							Valid: true,
 						},
 					}, nil)
+				taskMockRepo.MockTaskResponses()
 			},
 			expectedStatusCode:         http.StatusCreated,
 			expectedUserServiceCall:    true,
 			expectedProjectServiceCall: true,
 			expectedTaskServiceCall:    true,
 		},
+		{
+			testName: "assignee is not a project member",
+			requestBody: map[string]any{
+				"project_id":     4123,
+				"title":          "Adding Navbar",
+				"assignee_email": "outsider@gmail",
+				"description":    "to add  a navbar on right of home page",
+				"due_date":       time.Date(2025, 7, 11, 14, 30, 0, 0, time.UTC),
+			},
+			mockUserRepoSetup: func() {
+				userMockRepo.On("GetUserByEmail", mock.Anything, "outsider@gmail").Return(
+					userdb.User{
+						ID:    5678,
+						Email: "outsider@gmail",
+						Name:  "outsider",
+					}, nil)
+			},
+			mockProjectRepo: func() {
+				projectMockRepo.On("GetProjectById", mock.Anything, int64(4123)).Return(
+					projectdb.Project{
+						Name: "project-1",
+					}, nil)
+			},
+			mockTaskRepo: func() {
+				taskMockRepo.On("IsProjectMember", mock.Anything, taskdb.IsProjectMemberParams{ProjectID: 4123, UserID: 5678}).Return(false, nil)
+			},
+			expectedStatusCode:         http.StatusBadRequest,
+			expectedUserServiceCall:    true,
+			expectedProjectServiceCall: true,
+			expectedTaskServiceCall:    false,
+		},
 		{
 			testName: "valid task",
 			requestBody: map[string]any{
@@ -169,13 +224,15 @@ func TestCreateTaskHandler(t *testing.T) {
 					},
 					Title:       "Adding Navbar",
 					Description: "to add  a navbar on right of home page",
-					Status:      taskdb.TaskStatusTODO,
+					Status:      "TODO",
 					Priority:    taskdb.TaskPriorityHIGH,
 					DueDate: sql.NullTime{
 						Time:  time.Date(2025, 7, 11, 14, 30, 0, 0, time.UTC),
 						Valid: true,
 					},
 				}
+				taskMockRepo.On("IsProjectMember", mock.Anything, mock.Anything).Return(true, nil)
+				taskMockRepo.On("ListWorkflowStatuses", mock.Anything, int64(4123)).Return(defaultWorkflow(4123), nil)
 				taskMockRepo.On("CreateTask", mock.Anything, params).Return(
 					taskdb.Task{
 						Title:     "Adding Navbar",
@@ -185,6 +242,7 @@ func TestCreateTaskHandler(t *testing.T) {
 							Valid: true,
 						},
 					}, nil)
+				taskMockRepo.MockTaskResponses()
 			},
 			expectedStatusCode:         http.StatusCreated,
 			expectedUserServiceCall:    true,
@@ -225,13 +283,15 @@ func TestCreateTaskHandler(t *testing.T) {
 					},
 					Title:       "Adding Navbar",
 					Description: "to add  a navbar on right of home page",
-					Status:      taskdb.TaskStatusTODO,
+					Status:      "TODO",
 					Priority:    taskdb.TaskPriorityHIGH,
 					DueDate: sql.NullTime{
 						Time:  time.Date(2025, 7, 11, 14, 30, 0, 0, time.UTC),
 						Valid: true,
 					},
 				}
+				taskMockRepo.On("IsProjectMember", mock.Anything, mock.Anything).Return(true, nil)
+				taskMockRepo.On("ListWorkflowStatuses", mock.Anything, int64(4123)).Return(defaultWorkflow(4123), nil)
 				taskMockRepo.On("CreateTask", mock.Anything, params).Return(
 					taskdb.Task{}, mockDuplicateError())
 			},
@@ -371,7 +431,7 @@ func TestCreateTaskHandler(t *testing.T) {
 			apiGroup := r.Group("/api/v1")
 			userGroup := apiGroup.Group("/tasks")
 
-			userGroup.POST("/", jwtMiddleware, taskHandler.CreateTask)
+			userGroup.POST("/", jwtMiddleware, orgMiddleware, taskHandler.CreateTask)
 			r.ServeHTTP(w, req)
 			assert.Equal(t, w.Code, tc.expectedStatusCode)
 
@@ -397,57 +457,74 @@ func TestCreateTaskHandler(t *testing.T) {
 
 }
 
-
-
-func TestGetTaskByIDHandler(t *testing.T){
+func TestGetTaskByIDHandler(t *testing.T) {
 
 	taskHandler, taskMockRepo, _, _, jwtManager, logger := SetupNewTaskHandler()
 
 	jwtToken, err := jwtManager.GenerateAccessToken(1234, "test-user", "gudi@gmail")
 	require.NoError(t, err)
 	jwtMiddleware := middleware.JWTAuthMiddleware(logger, jwtManager)
-	
-	testCases:=[]struct{
-		testName string 
-		taskID int 
-		mockSetup func()
-		expectedStatusCode int 
-		expectedServiceCall bool 
+	orgMiddleware := organizationMiddleware(logger)
+
+	testCases := []struct {
+		testName            string
+		taskID              int
+		mockSetup           func()
+		expectedStatusCode  int
+		expectedServiceCall bool
 	}{
 		{
 			testName: "Valid task id",
-			taskID:101,
+			taskID:   101,
 			mockSetup: func() {
-				taskMockRepo.On("GetTaskById",mock.Anything,int64(101)).Return(
+				taskMockRepo.On("GetTaskById", mock.Anything, int64(101)).Return(
 					taskdb.Task{
 						Title: "task-1",
-						ID:101,},nil)
+						ID:    101}, nil)
+				taskMockRepo.On("CountTaskComments", mock.Anything, []int64{101}).Return(
+					[]taskdb.CountTaskCommentsRow{{TaskID: 101, CommentCount: 3}}, nil)
+				taskMockRepo.On("CountSubtasks", mock.Anything, []int64{101}).Return(
+					[]taskdb.CountSubtasksRow{{ParentTaskID: 101, Total: 4, Done: 1}}, nil)
+				taskMockRepo.On("ListBlockedTaskIDs", mock.Anything, []int64{101}).Return(
+					[]int64{101}, nil)
+				taskMockRepo.On("ListTaskLabels", mock.Anything, []int64{101}).Return(
+					[]taskdb.ListTaskLabelsRow{{TaskID: 101, ID: 7, Name: "bug", Color: "#d73a4a"}}, nil)
+				taskMockRepo.On("ListTaskCustomFieldValues", mock.Anything, []int64{101}).Return(
+					[]taskdb.TaskCustomFieldValue{{TaskID: 101, FieldID: 3, Value: json.RawMessage(`"high"`)}}, nil)
+				taskMockRepo.On("SumTaskTimeSpent", mock.Anything, []int64{101}).Return(
+					[]taskdb.SumTaskTimeSpentRow{{TaskID: 101, Minutes: 45}}, nil)
 			},
-			expectedStatusCode: http.StatusOK,
+			expectedStatusCode:  http.StatusOK,
 			expectedServiceCall: true,
 		},
 		{
 			testName: "Non Existent Task ID",
-			taskID:121,
+			taskID:   121,
 			mockSetup: func() {
-				taskMockRepo.On("GetTaskById",mock.Anything,int64(121)).Return(
-					taskdb.Task{},sql.ErrNoRows)
+				taskMockRepo.On("GetTaskById", mock.Anything, int64(121)).Return(
+					taskdb.Task{}, sql.ErrNoRows)
 			},
-			expectedStatusCode: http.StatusBadRequest,
+			expectedStatusCode:  http.StatusNotFound,
 			expectedServiceCall: true,
 		},
+		{
+			testName: "Task in another user's project",
+			taskID:   999,
+			mockSetup: func() {
+			},
+			expectedStatusCode:  http.StatusForbidden,
+			expectedServiceCall: false,
+		},
 	}
 
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
 
-	for _,tc := range testCases{
-			t.Run(tc.testName,func(t *testing.T){
-
-				gin.SetMode(gin.TestMode)
-				taskMockRepo.Calls=nil 
-				taskMockRepo.ExpectedCalls=nil 
-
+			gin.SetMode(gin.TestMode)
+			taskMockRepo.Calls = nil
+			taskMockRepo.ExpectedCalls = nil
 
-				tc.mockSetup()
+			tc.mockSetup()
 
 			// Create request
 			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/tasks/%v", tc.taskID), nil)
@@ -462,7 +539,7 @@ func TestGetTaskByIDHandler(t *testing.T){
 			apiGroup := r.Group("/api/v1")
 			userGroup := apiGroup.Group("/tasks")
 
-			userGroup.GET("/:id", jwtMiddleware, taskHandler.GetTaskByID)
+			userGroup.GET("/:id", jwtMiddleware, orgMiddleware, taskHandler.GetTaskByID)
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, w.Code, tc.expectedStatusCode)
@@ -475,20 +552,13 @@ func TestGetTaskByIDHandler(t *testing.T){
 
 			}
 
-
-
-			})
+		})
 
 	}
 
-
-
 }
 
-
-
-func DeleteTaskHandler(t *testing.T){
-
+func DeleteTaskHandler(t *testing.T) {
 
 	taskHandler, taskMockRepo, _, _, jwtManager, logger := SetupNewTaskHandler()
 
@@ -496,42 +566,41 @@ func DeleteTaskHandler(t *testing.T){
 
 	require.NoError(t, err)
 	jwtMiddleware := middleware.JWTAuthMiddleware(logger, jwtManager)
+	orgMiddleware := organizationMiddleware(logger)
 
-	testCases:=[]struct{
-		testName string 
-		taskID int 
-		mockSetup func()
-		expectedStatusCode int 
-		expectedServiceCall bool 
-
-	}{	
+	testCases := []struct {
+		testName            string
+		taskID              int
+		mockSetup           func()
+		expectedStatusCode  int
+		expectedServiceCall bool
+	}{
 		{
 			testName: "existing task",
-			taskID: 102,
+			taskID:   102,
 			mockSetup: func() {
-				taskMockRepo.On("DeleteTask",mock.Anything,int64(102)).Return(1,nil)
+				taskMockRepo.On("DeleteTask", mock.Anything, int64(102)).Return(1, nil)
 			},
-			expectedStatusCode: http.StatusOK,
+			expectedStatusCode:  http.StatusOK,
 			expectedServiceCall: true,
 		},
 		{
 			testName: "non-existing task",
-			taskID: 104,
+			taskID:   104,
 			mockSetup: func() {
-				taskMockRepo.On("DeleteTask",mock.Anything,int64(104)).Return(0,nil)
+				taskMockRepo.On("DeleteTask", mock.Anything, int64(104)).Return(0, nil)
 			},
-			expectedStatusCode: http.StatusBadRequest,
+			expectedStatusCode:  http.StatusBadRequest,
 			expectedServiceCall: true,
 		},
 	}
 
-	for _,tc := range testCases{
-		t.Run(tc.testName,func( t *testing.T){
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
 
 			gin.SetMode(gin.TestMode)
-			taskMockRepo.Calls=nil 
-			taskMockRepo.ExpectedCalls=nil 
-
+			taskMockRepo.Calls = nil
+			taskMockRepo.ExpectedCalls = nil
 
 			tc.mockSetup()
 
@@ -548,7 +617,7 @@ func DeleteTaskHandler(t *testing.T){
 			apiGroup := r.Group("/api/v1")
 			userGroup := apiGroup.Group("/tasks")
 
-			userGroup.DELETE("/:id", jwtMiddleware, taskHandler.DeleteTask)
+			userGroup.DELETE("/:id", jwtMiddleware, orgMiddleware, taskHandler.DeleteTask)
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, w.Code, tc.expectedStatusCode)
@@ -566,106 +635,101 @@ func DeleteTaskHandler(t *testing.T){
 
 }
 
-
-func TestUpdateTaskHandler( t *testing.T){
+func TestUpdateTaskHandler(t *testing.T) {
 
 	taskHandler, taskMockRepo, _, _, jwtManager, logger := SetupNewTaskHandler()
 	jwtToken, err := jwtManager.GenerateAccessToken(1234, "test-user", "gudi@gmail")
 	require.NoError(t, err)
 	jwtMiddleware := middleware.JWTAuthMiddleware(logger, jwtManager)
+	orgMiddleware := organizationMiddleware(logger)
 
-	testCases:=[]struct{
-		testName string 
-		requestBody map[string]any
-		taskID any
+	testCases := []struct {
+		testName       string
+		requestBody    map[string]any
+		taskID         any
 		expectedParams taskdb.UpdateTaskParams
-		mockSetup func(params taskdb.UpdateTaskParams)
-		
-		expectedServiceCall bool 
-		expectedStatusCode int 
+		mockSetup      func(params taskdb.UpdateTaskParams)
+
+		expectedServiceCall bool
+		expectedStatusCode  int
 	}{
 		{
 			testName: "Valid task update params",
 			requestBody: map[string]any{
-				"title":"valid task",
-				"description":"hello this is valid task",
+				"title":       "valid task",
+				"description": "hello this is valid task",
 			},
 			taskID: 1345,
 			expectedParams: taskdb.UpdateTaskParams{
-					ID: 1345,
-					Title: sql.NullString{
-						String:"valid task",
-						Valid: true,
-					},
-					Description: sql.NullString{
-						String: "hello this is valid task",
-						Valid: true,
-					},
+				ID: 1345,
+				Title: sql.NullString{
+					String: "valid task",
+					Valid:  true,
+				},
+				Description: sql.NullString{
+					String: "hello this is valid task",
+					Valid:  true,
+				},
 			},
 			mockSetup: func(params taskdb.UpdateTaskParams) {
-				taskMockRepo.On("UpdateTask",mock.Anything,params).Return(nil)
+				taskMockRepo.On("GetTaskById", mock.Anything, int64(1345)).Return(taskdb.Task{ID: 1345}, nil)
+				taskMockRepo.On("UpdateTask", mock.Anything, params).Return(taskdb.Task{ID: 1345}, nil)
 			},
-			expectedStatusCode: http.StatusOK,
+			expectedStatusCode:  http.StatusOK,
 			expectedServiceCall: true,
 		},
 		{
 			testName: "Invalid task update params",
 			requestBody: map[string]any{
-				"title":"duplicate task",
-				"description":"hello this is duplicate task",
+				"title":       "duplicate task",
+				"description": "hello this is duplicate task",
 			},
 			taskID: 1345,
 			expectedParams: taskdb.UpdateTaskParams{
-					ID: 1345,
-					Title: sql.NullString{
-						String:"duplicate task",
-						Valid: true,
-					},
-					Description: sql.NullString{
-						String: "hello this is duplicate task",
-						Valid: true,
-					},
+				ID: 1345,
+				Title: sql.NullString{
+					String: "duplicate task",
+					Valid:  true,
+				},
+				Description: sql.NullString{
+					String: "hello this is duplicate task",
+					Valid:  true,
+				},
 			},
 			mockSetup: func(params taskdb.UpdateTaskParams) {
-				taskMockRepo.On("UpdateTask",mock.Anything,params).Return(customErrors.ErrTaskAlreadyExists)
+				taskMockRepo.On("GetTaskById", mock.Anything, int64(1345)).Return(taskdb.Task{ID: 1345}, nil)
+				taskMockRepo.On("UpdateTask", mock.Anything, params).Return(taskdb.Task{}, customErrors.ErrTaskAlreadyExists)
 			},
-			expectedStatusCode: http.StatusBadRequest,
+			expectedStatusCode:  http.StatusBadRequest,
 			expectedServiceCall: true,
 		},
 		{
 			testName: "Invalid task id",
 			requestBody: map[string]any{
-				"title":"duplicate task",
-				"description":"hello this is duplicate task",
-			},
-			taskID: "abc1234",
-			expectedParams: taskdb.UpdateTaskParams{
-					
+				"title":       "duplicate task",
+				"description": "hello this is duplicate task",
 			},
+			taskID:         "abc1234",
+			expectedParams: taskdb.UpdateTaskParams{},
 			mockSetup: func(params taskdb.UpdateTaskParams) {
-				
+
 			},
-			expectedStatusCode: http.StatusBadRequest,
-			expectedServiceCall: false ,
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedServiceCall: false,
 		},
 	}
 
-
-	for _, tc := range testCases{
-		t.Run(tc.testName,func( t *testing.T){
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
 			gin.SetMode(gin.TestMode)
-			taskMockRepo.Calls=nil 
-			taskMockRepo.ExpectedCalls=nil 
+			taskMockRepo.Calls = nil
+			taskMockRepo.ExpectedCalls = nil
 			tc.mockSetup(tc.expectedParams)
 
-			
-
-			
-
-			body,err:=json.Marshal(tc.requestBody)
-			assert.NoError(t,err)
+			body, err := json.Marshal(tc.requestBody)
+			assert.NoError(t, err)
 
-				// Create request
+			// Create request
 			req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("/api/v1/tasks/%v", tc.taskID), bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Set("Authorization", "Bearer "+jwtToken)
@@ -678,7 +742,7 @@ func TestUpdateTaskHandler( t *testing.T){
 			apiGroup := r.Group("/api/v1")
 			userGroup := apiGroup.Group("/tasks")
 
-			userGroup.PATCH("/:id", jwtMiddleware, taskHandler.UpdateTask)
+			userGroup.PATCH("/:id", jwtMiddleware, orgMiddleware, taskHandler.UpdateTask)
 			r.ServeHTTP(w, req)
 
 			assert.Equal(t, w.Code, tc.expectedStatusCode)
@@ -691,14 +755,8 @@ func TestUpdateTaskHandler( t *testing.T){
 
 			}
 
-
-
-
-
 		})
 
 	}
 
-
 }
-
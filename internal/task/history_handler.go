@@ -0,0 +1,38 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTaskHistory returns the change history of a task
+// @Summary      Get task history
+// @Description  Lists every recorded change of the task, oldest first, with the field, old and new value, actor and time, and the seconds the task spent in each status up to now
+// @Tags         history
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/history [get]
+// @Security BearerAuth
+func (t *TaskHandler) GetTaskHistory(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	history, err := t.taskService.TaskHistory(ctx, userID, orgID, taskID)
+	if err != nil {
+		t.logger.Errorf("unable to get the history of task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, history)
+}
@@ -0,0 +1,102 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+// TaskHistory returns the changes recorded for the task, oldest first, and
+// the time it spent in each of its statuses up to now. Changes are recorded
+// by a trigger on tasks, so every write shows up whatever made it.
+func (t *TaskService) TaskHistory(ctx context.Context, userID int, orgID int, taskID int) (*TaskHistory, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	task, err := t.taskRepository.GetTaskById(ctx, int64(taskID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	changes, err := t.taskRepository.ListTaskChanges(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = []taskdb.ListTaskChangesRow{}
+	}
+	return &TaskHistory{
+		Changes:      changes,
+		TimeInStatus: timeInStatus(task, changes, time.Now().UTC()),
+	}, nil
+}
+
+// ProjectActivity lists a page of the changes to the project's tasks, newest
+// first. Deleted tasks keep their changes in the feed.
+func (t *TaskService) ProjectActivity(ctx context.Context, userID int, orgID int, projectID int, page types.Page) ([]taskdb.ListProjectActivityRow, error) {
+	limit, offset, err := page.Bounds()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	activity, err := t.taskRepository.ListProjectActivity(ctx, taskdb.ListProjectActivityParams{
+		ProjectID: int64(projectID),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if activity == nil {
+		activity = []taskdb.ListProjectActivityRow{}
+	}
+	return activity, nil
+}
+
+// timeInStatus replays the status changes of the task from its creation to
+// now. Tasks created before changes were recorded count their time up to the
+// first recorded change in the status that change left.
+func timeInStatus(task taskdb.Task, changes []taskdb.ListTaskChangesRow, now time.Time) []StatusTime {
+	var statusChanges []taskdb.ListTaskChangesRow
+	for _, change := range changes {
+		if change.Field.Valid && change.Field.String == "status" {
+			statusChanges = append(statusChanges, change)
+		}
+	}
+	status, since := task.Status, task.CreatedAt
+	if len(statusChanges) > 0 {
+		status = statusChanges[0].OldValue.String
+	}
+
+	times := []StatusTime{}
+	index := make(map[string]int)
+	visit := func(status string, from, to time.Time) {
+		i, ok := index[status]
+		if !ok {
+			i = len(times)
+			index[status] = i
+			times = append(times, StatusTime{Status: status})
+		}
+		times[i].Visits++
+		if to.After(from) {
+			times[i].Seconds += int64(to.Sub(from) / time.Second)
+		}
+	}
+	for _, change := range statusChanges {
+		visit(status, since, change.ChangedAt)
+		status, since = change.NewValue.String, change.ChangedAt
+	}
+	visit(status, since, now)
+	times[index[status]].Current = true
+	return times
+}
@@ -0,0 +1,141 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func change(field, oldValue, newValue string, at time.Time) taskdb.ListTaskChangesRow {
+	return taskdb.ListTaskChangesRow{
+		TaskID:    22,
+		ProjectID: 6,
+		Action:    "update",
+		Field:     sql.NullString{String: field, Valid: true},
+		OldValue:  sql.NullString{String: oldValue, Valid: true},
+		NewValue:  sql.NullString{String: newValue, Valid: true},
+		ChangedAt: at,
+	}
+}
+
+func TestTimeInStatus(t *testing.T) {
+	created := time.Date(2026, time.October, 1, 9, 0, 0, 0, time.UTC)
+	hour := func(h int) time.Time { return created.Add(time.Duration(h) * time.Hour) }
+
+	testCases := []struct {
+		name     string
+		status   string
+		changes  []taskdb.ListTaskChangesRow
+		now      time.Time
+		expected []StatusTime
+	}{
+		{
+			name:     "never changed",
+			status:   "TODO",
+			now:      hour(5),
+			expected: []StatusTime{{Status: "TODO", Seconds: 5 * 3600, Visits: 1, Current: true}},
+		},
+		{
+			name:   "back and forth",
+			status: "DONE",
+			changes: []taskdb.ListTaskChangesRow{
+				{Action: "create", ChangedAt: created},
+				change("status", "TODO", "IN_PROGRESS", hour(1)),
+				change("priority", "low", "high", hour(2)),
+				change("status", "IN_PROGRESS", "IN_REVIEW", hour(4)),
+				change("status", "IN_REVIEW", "IN_PROGRESS", hour(5)),
+				change("status", "IN_PROGRESS", "DONE", hour(7)),
+			},
+			now: hour(10),
+			expected: []StatusTime{
+				{Status: "TODO", Seconds: 3600, Visits: 1},
+				{Status: "IN_PROGRESS", Seconds: 5 * 3600, Visits: 2},
+				{Status: "IN_REVIEW", Seconds: 3600, Visits: 1},
+				{Status: "DONE", Seconds: 3 * 3600, Visits: 1, Current: true},
+			},
+		},
+		{
+			name:    "created before changes were recorded",
+			status:  "DONE",
+			changes: []taskdb.ListTaskChangesRow{change("status", "IN_PROGRESS", "DONE", hour(48))},
+			now:     hour(50),
+			expected: []StatusTime{
+				{Status: "IN_PROGRESS", Seconds: 48 * 3600, Visits: 1},
+				{Status: "DONE", Seconds: 2 * 3600, Visits: 1, Current: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := subtask(22, 6, 0)
+			task.Status = tc.status
+			task.CreatedAt = created
+			assert.Equal(t, tc.expected, timeInStatus(task, tc.changes, tc.now))
+		})
+	}
+}
+
+func TestTaskHistory(t *testing.T) {
+	service, repo := newCommentService()
+	task := subtask(22, 6, 0)
+	task.Status = "IN_PROGRESS"
+	task.CreatedAt = time.Now().UTC().Add(-2 * time.Hour)
+	repo.On("GetTaskById", mock.Anything, int64(22)).Return(task, nil)
+	repo.On("ListTaskChanges", mock.Anything, int64(22)).Return([]taskdb.ListTaskChangesRow{
+		change("status", "TODO", "IN_PROGRESS", task.CreatedAt.Add(time.Hour)),
+	}, nil)
+
+	history, err := service.TaskHistory(context.TODO(), 1234, testOrgID, 22)
+	require.NoError(t, err)
+	assert.Len(t, history.Changes, 1)
+	require.Len(t, history.TimeInStatus, 2)
+	assert.Equal(t, int64(3600), history.TimeInStatus[0].Seconds)
+	assert.True(t, history.TimeInStatus[1].Current)
+
+	// viewers read the history too
+	repo.On("GetTaskById", mock.Anything, int64(21)).Return(subtask(21, 5, 0), nil)
+	repo.On("ListTaskChanges", mock.Anything, int64(21)).Return([]taskdb.ListTaskChangesRow(nil), nil)
+	history, err = service.TaskHistory(context.TODO(), 1234, testOrgID, 21)
+	require.NoError(t, err)
+	assert.Empty(t, history.Changes)
+	assert.Len(t, history.TimeInStatus, 1)
+}
+
+func TestProjectActivity(t *testing.T) {
+	limit, tooMany, negative := int32(10), int32(types.MaxPageSize+1), int32(-1)
+
+	testCases := []struct {
+		name           string
+		page           types.Page
+		expectedParams taskdb.ListProjectActivityParams
+		expectedError  error
+	}{
+		{name: "default page", expectedParams: taskdb.ListProjectActivityParams{ProjectID: 6, Limit: types.DefaultPageSize}},
+		{name: "given page", page: types.Page{Limit: &limit, Offset: &limit}, expectedParams: taskdb.ListProjectActivityParams{ProjectID: 6, Limit: 10, Offset: 10}},
+		{name: "limit too large", page: types.Page{Limit: &tooMany}, expectedError: customErrors.ErrInvalidPagination},
+		{name: "negative offset", page: types.Page{Offset: &negative}, expectedError: customErrors.ErrInvalidPagination},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newCommentService()
+			repo.On("ListProjectActivity", mock.Anything, tc.expectedParams).Return([]taskdb.ListProjectActivityRow(nil), nil)
+
+			activity, err := service.ProjectActivity(context.TODO(), 1234, testOrgID, 6, tc.page)
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedError == nil {
+				assert.NotNil(t, activity)
+				repo.AssertExpectations(t)
+			}
+		})
+	}
+}
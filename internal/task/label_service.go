@@ -0,0 +1,89 @@
+package task
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+)
+
+// FindLabelIDs resolves label names of a project, ignoring case, to their ids.
+// Imports use it for the labels column.
+func (t *TaskService) FindLabelIDs(ctx context.Context, userID int, orgID int, projectID int, names []string) ([]int64, error) {
+	if err := t.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	names = labelNames(names)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	ids, err := t.taskRepository.ListLabelIDsByName(ctx, taskdb.ListLabelIDsByNameParams{
+		ProjectID: int64(projectID),
+		Names:     names,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != len(names) {
+		return nil, customErrors.ErrLabelNotFound
+	}
+	return ids, nil
+}
+
+// checkLabels removes duplicate label ids and makes sure every label belongs
+// to the project.
+func (t *TaskService) checkLabels(ctx context.Context, projectID int64, labelIDs []int64) ([]int64, error) {
+	ids := slices.Clone(labelIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	if len(ids) == 0 {
+		return []int64{}, nil
+	}
+	count, err := t.taskRepository.CountProjectLabels(ctx, taskdb.CountProjectLabelsParams{
+		ProjectID: projectID,
+		LabelIds:  ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count != int64(len(ids)) {
+		return nil, customErrors.ErrLabelNotFound
+	}
+	return ids, nil
+}
+
+// labelNames splits comma-separated label names, lower-cases them and drops
+// blanks and duplicates.
+func labelNames(values []string) []string {
+	var names []string
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// labelFilter turns the labels and label_match query parameters into the
+// arguments of ListTasksWithFilters.
+func labelFilter(req *TaskFilterRequest) ([]string, bool, error) {
+	var all bool
+	switch strings.ToLower(req.LabelMatch) {
+	case "", "any":
+	case "all":
+		all = true
+	default:
+		return nil, false, customErrors.ErrInvalidLabelMatch
+	}
+	names := labelNames(req.Labels)
+	if names == nil {
+		names = []string{}
+	}
+	return names, all, nil
+}
@@ -0,0 +1,126 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLabelFilter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		request       TaskFilterRequest
+		expectedNames []string
+		expectedAll   bool
+		expectedError error
+	}{
+		{name: "no labels", expectedNames: []string{}},
+		{name: "comma-separated names", request: TaskFilterRequest{Labels: []string{"Bug, ui", "bug"}}, expectedNames: []string{"bug", "ui"}},
+		{name: "every label must match", request: TaskFilterRequest{Labels: []string{"bug"}, LabelMatch: "ALL"}, expectedNames: []string{"bug"}, expectedAll: true},
+		{name: "invalid match", request: TaskFilterRequest{Labels: []string{"bug"}, LabelMatch: "some"}, expectedError: customErrors.ErrInvalidLabelMatch},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			names, all, err := labelFilter(&tc.request)
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedNames, names)
+			assert.Equal(t, tc.expectedAll, all)
+		})
+	}
+}
+
+func TestSetTaskLabels(t *testing.T) {
+	// labels 7 and 8 belong to project 6, label 9 to another project
+	ids := func(ids ...int64) *[]int64 { return &ids }
+	testCases := []struct {
+		name           string
+		labelIDs       *[]int64
+		expectedParams *taskdb.SetTaskLabelsParams
+		expectedError  error
+	}{
+		{
+			name:           "duplicates are dropped",
+			labelIDs:       ids(8, 7, 8),
+			expectedParams: &taskdb.SetTaskLabelsParams{TaskID: 22, LabelIds: []int64{7, 8}, ProjectID: 6},
+		},
+		{
+			name:           "an empty list removes every label",
+			labelIDs:       ids(),
+			expectedParams: &taskdb.SetTaskLabelsParams{TaskID: 22, LabelIds: []int64{}, ProjectID: 6},
+		},
+		{name: "label of another project", labelIDs: ids(7, 9), expectedError: customErrors.ErrLabelNotFound},
+		{name: "labels are kept when omitted"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newCommentService()
+			repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+			repo.On("CountProjectLabels", mock.Anything, taskdb.CountProjectLabelsParams{ProjectID: 6, LabelIds: []int64{7, 8}}).Return(int64(2), nil)
+			repo.On("CountProjectLabels", mock.Anything, taskdb.CountProjectLabelsParams{ProjectID: 6, LabelIds: []int64{7, 9}}).Return(int64(1), nil)
+			repo.On("UpdateTask", mock.Anything, mock.Anything).Return(subtask(22, 6, 0), nil)
+			repo.On("SetTaskLabels", mock.Anything, mock.Anything).Return(nil)
+
+			err := service.UpdateTask(context.TODO(), 1234, testOrgID, UpdateTaskRequest{ID: 22, LabelIDs: tc.labelIDs})
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedParams != nil {
+				repo.AssertCalled(t, "SetTaskLabels", mock.Anything, *tc.expectedParams)
+			} else {
+				repo.AssertNotCalled(t, "SetTaskLabels", mock.Anything, mock.Anything)
+			}
+			if tc.expectedError != nil {
+				repo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestFindLabelIDs(t *testing.T) {
+	service, repo := newCommentService()
+	repo.On("ListLabelIDsByName", mock.Anything, taskdb.ListLabelIDsByNameParams{ProjectID: 6, Names: []string{"bug", "ui"}}).Return([]int64{7, 8}, nil)
+	repo.On("ListLabelIDsByName", mock.Anything, taskdb.ListLabelIDsByNameParams{ProjectID: 6, Names: []string{"bug", "docs"}}).Return([]int64{7}, nil)
+
+	ids, err := service.FindLabelIDs(context.TODO(), 1234, testOrgID, 6, []string{"Bug,UI"})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{7, 8}, ids)
+
+	_, err = service.FindLabelIDs(context.TODO(), 1234, testOrgID, 6, []string{"bug,docs"})
+	assert.Equal(t, customErrors.ErrLabelNotFound, err)
+
+	ids, err = service.FindLabelIDs(context.TODO(), 1234, testOrgID, 6, []string{""})
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+type txKey struct{}
+
+// fakeTransactor marks the context it runs fn with and remembers what fn returned.
+type fakeTransactor struct{ err error }
+
+func (f *fakeTransactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.err = fn(context.WithValue(ctx, txKey{}, true))
+	return f.err
+}
+
+func TestUpdateTaskWritesInOneTransaction(t *testing.T) {
+	service, repo := newCommentService()
+	transactor := &fakeTransactor{}
+	service.UseTransactions(transactor)
+	inTx := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(txKey{}) != nil })
+	failed := errors.New("labels failed")
+	repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+	repo.On("CountProjectLabels", mock.Anything, mock.Anything).Return(int64(1), nil)
+	repo.On("UpdateTask", inTx, mock.Anything).Return(subtask(22, 6, 0), nil)
+	repo.On("SetTaskLabels", inTx, mock.Anything).Return(failed)
+
+	labels := []int64{7}
+	err := service.UpdateTask(context.TODO(), 1234, testOrgID, UpdateTaskRequest{ID: 22, LabelIDs: &labels})
+	assert.Equal(t, failed, err)
+	assert.Equal(t, failed, transactor.err, "the failed write must roll the transaction back")
+	repo.AssertExpectations(t)
+}
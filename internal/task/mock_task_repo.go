@@ -2,6 +2,7 @@ package task
 
 import (
 	"context"
+	"database/sql"
 
 	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
 	"github.com/stretchr/testify/mock"
@@ -34,16 +35,317 @@ func (m *MockTaskRepo) DeleteTask(ctx context.Context, arg int64) (int64, error)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func(m *MockTaskRepo)UpdateTask(ctx context.Context, arg taskdb.UpdateTaskParams) error{
-	args:=m.Called(ctx,arg)
+func (m *MockTaskRepo) UpdateTask(ctx context.Context, arg taskdb.UpdateTaskParams) (taskdb.Task, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.Task), args.Error(1)
+}
+func (m *MockTaskRepo) ListTasksWithFilters(ctx context.Context, arg taskdb.ListTasksWithFiltersParams) ([]taskdb.Task, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]taskdb.Task), args.Error(1)
+}
+func (m *MockTaskRepo) GetAllTasks(ctx context.Context) ([]taskdb.Task, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]taskdb.Task), args.Error(1)
+}
+
+func (m *MockTaskRepo) GetTasksByUserId(ctx context.Context, arg taskdb.GetTasksByUserIdParams) ([]taskdb.Task, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]taskdb.Task), args.Error(1)
+}
+
+func (m *MockTaskRepo) IsOrganizationMember(ctx context.Context, arg taskdb.IsOrganizationMemberParams) (bool, error) {
+	args := m.Called(ctx, arg)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTaskRepo) IsProjectMember(ctx context.Context, arg taskdb.IsProjectMemberParams) (bool, error) {
+	args := m.Called(ctx, arg)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTaskRepo) CountTaskComments(ctx context.Context, taskIds []int64) ([]taskdb.CountTaskCommentsRow, error) {
+	args := m.Called(ctx, taskIds)
+	return args.Get(0).([]taskdb.CountTaskCommentsRow), args.Error(1)
+}
+
+func (m *MockTaskRepo) CreateTaskComment(ctx context.Context, arg taskdb.CreateTaskCommentParams) (taskdb.TaskComment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TaskComment), args.Error(1)
+}
+
+func (m *MockTaskRepo) DeleteTaskComment(ctx context.Context, id int64) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) GetTaskComment(ctx context.Context, arg taskdb.GetTaskCommentParams) (taskdb.TaskComment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TaskComment), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListTaskCommentEdits(ctx context.Context, commentID int64) ([]taskdb.TaskCommentEdit, error) {
+	args := m.Called(ctx, commentID)
+	return args.Get(0).([]taskdb.TaskCommentEdit), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListTaskCommentReplies(ctx context.Context, parentIds []int64) ([]taskdb.TaskComment, error) {
+	args := m.Called(ctx, parentIds)
+	return args.Get(0).([]taskdb.TaskComment), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListTaskComments(ctx context.Context, arg taskdb.ListTaskCommentsParams) ([]taskdb.TaskComment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]taskdb.TaskComment), args.Error(1)
+}
+
+func (m *MockTaskRepo) UpdateTaskCommentBody(ctx context.Context, arg taskdb.UpdateTaskCommentBodyParams) (taskdb.TaskComment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TaskComment), args.Error(1)
+}
+
+func (m *MockTaskRepo) CountSubtasks(ctx context.Context, taskIds []int64) ([]taskdb.CountSubtasksRow, error) {
+	args := m.Called(ctx, taskIds)
+	return args.Get(0).([]taskdb.CountSubtasksRow), args.Error(1)
+}
+
+func (m *MockTaskRepo) GetTaskByTitle(ctx context.Context, arg taskdb.GetTaskByTitleParams) (taskdb.Task, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.Task), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListSubtasks(ctx context.Context, parentTaskID sql.NullInt64) ([]taskdb.Task, error) {
+	args := m.Called(ctx, parentTaskID)
+	return args.Get(0).([]taskdb.Task), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListTaskAncestorIDs(ctx context.Context, id int64) ([]int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) CreateTaskDependency(ctx context.Context, arg taskdb.CreateTaskDependencyParams) (taskdb.TaskDependency, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TaskDependency), args.Error(1)
+}
+
+func (m *MockTaskRepo) DeleteTaskDependency(ctx context.Context, arg taskdb.DeleteTaskDependencyParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListTaskBlockers(ctx context.Context, taskID int64) ([]taskdb.Task, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]taskdb.Task), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListBlockedTasks(ctx context.Context, blockedByID int64) ([]taskdb.Task, error) {
+	args := m.Called(ctx, blockedByID)
+	return args.Get(0).([]taskdb.Task), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListProjectDependencies(ctx context.Context, projectID int64) ([]taskdb.TaskDependency, error) {
+	args := m.Called(ctx, projectID)
+	return args.Get(0).([]taskdb.TaskDependency), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListTransitiveBlockerIDs(ctx context.Context, id int64) ([]int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) CountOpenBlockers(ctx context.Context, taskID int64) (int64, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListBlockedTaskIDs(ctx context.Context, taskIds []int64) ([]int64, error) {
+	args := m.Called(ctx, taskIds)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) CountProjectLabels(ctx context.Context, arg taskdb.CountProjectLabelsParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListLabelIDsByName(ctx context.Context, arg taskdb.ListLabelIDsByNameParams) ([]int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) SetTaskLabels(ctx context.Context, arg taskdb.SetTaskLabelsParams) error {
+	args := m.Called(ctx, arg)
 	return args.Error(0)
 }
-func(m *MockTaskRepo) ListTasksWithFilters(ctx context.Context, arg taskdb.ListTasksWithFiltersParams) ([]taskdb.Task, error){
-	args:=m.Called(ctx,arg)
-	return args.Get(0).([]taskdb.Task),args.Error(1)
+
+func (m *MockTaskRepo) ListTaskLabels(ctx context.Context, taskIds []int64) ([]taskdb.ListTaskLabelsRow, error) {
+	args := m.Called(ctx, taskIds)
+	return args.Get(0).([]taskdb.ListTaskLabelsRow), args.Error(1)
 }
-func(m *MockTaskRepo) GetAllTasks(ctx context.Context) ([]taskdb.Task, error){
-	args:=m.Called(ctx)
-	return args.Get(0).([]taskdb.Task),args.Error(1)
+
+func (m *MockTaskRepo) ListProjectCustomFields(ctx context.Context, projectID int64) ([]taskdb.CustomField, error) {
+	args := m.Called(ctx, projectID)
+	return args.Get(0).([]taskdb.CustomField), args.Error(1)
 }
 
+func (m *MockTaskRepo) ListCustomFieldsByID(ctx context.Context, ids []int64) ([]taskdb.CustomField, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]taskdb.CustomField), args.Error(1)
+}
+
+func (m *MockTaskRepo) SetTaskCustomFieldValues(ctx context.Context, arg taskdb.SetTaskCustomFieldValuesParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepo) ListTaskCustomFieldValues(ctx context.Context, taskIds []int64) ([]taskdb.TaskCustomFieldValue, error) {
+	args := m.Called(ctx, taskIds)
+	return args.Get(0).([]taskdb.TaskCustomFieldValue), args.Error(1)
+}
+
+// MockTaskResponses lets TaskService.TaskResponses run for any tasks, none of
+// which have comments, subtasks, open blockers, labels, custom field values or
+// logged time.
+func (m *MockTaskRepo) MockTaskResponses() {
+	m.On("CountTaskComments", mock.Anything, mock.Anything).Return([]taskdb.CountTaskCommentsRow{}, nil)
+	m.On("CountSubtasks", mock.Anything, mock.Anything).Return([]taskdb.CountSubtasksRow{}, nil)
+	m.On("ListBlockedTaskIDs", mock.Anything, mock.Anything).Return([]int64{}, nil)
+	m.On("ListTaskLabels", mock.Anything, mock.Anything).Return([]taskdb.ListTaskLabelsRow{}, nil)
+	m.On("ListTaskCustomFieldValues", mock.Anything, mock.Anything).Return([]taskdb.TaskCustomFieldValue{}, nil)
+	m.On("SumTaskTimeSpent", mock.Anything, mock.Anything).Return([]taskdb.SumTaskTimeSpentRow{}, nil)
+}
+
+func (m *MockTaskRepo) ListWorkflowStatuses(ctx context.Context, projectID int64) ([]taskdb.WorkflowStatus, error) {
+	args := m.Called(ctx, projectID)
+	return args.Get(0).([]taskdb.WorkflowStatus), args.Error(1)
+}
+
+func (m *MockTaskRepo) AdvanceTaskSeries(ctx context.Context, arg taskdb.AdvanceTaskSeriesParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) CreateTaskSeries(ctx context.Context, arg taskdb.CreateTaskSeriesParams) (taskdb.TaskSeries, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TaskSeries), args.Error(1)
+}
+
+func (m *MockTaskRepo) DeleteTaskSeries(ctx context.Context, id int64) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) EndTaskSeries(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepo) GetTaskSeries(ctx context.Context, id int64) (taskdb.TaskSeries, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(taskdb.TaskSeries), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListDueTaskSeries(ctx context.Context, maxSeries int32) ([]taskdb.ListDueTaskSeriesRow, error) {
+	args := m.Called(ctx, maxSeries)
+	return args.Get(0).([]taskdb.ListDueTaskSeriesRow), args.Error(1)
+}
+
+func (m *MockTaskRepo) UpdateTaskSeries(ctx context.Context, arg taskdb.UpdateTaskSeriesParams) (taskdb.TaskSeries, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TaskSeries), args.Error(1)
+}
+
+func (m *MockTaskRepo) CreateTimeEntry(ctx context.Context, arg taskdb.CreateTimeEntryParams) (taskdb.TimeEntry, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TimeEntry), args.Error(1)
+}
+
+func (m *MockTaskRepo) DeleteTimeEntry(ctx context.Context, id int64) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) GetTimeEntry(ctx context.Context, arg taskdb.GetTimeEntryParams) (taskdb.TimeEntry, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TimeEntry), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListTimeEntries(ctx context.Context, taskID int64) ([]taskdb.TimeEntry, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]taskdb.TimeEntry), args.Error(1)
+}
+
+func (m *MockTaskRepo) StartTimeEntry(ctx context.Context, arg taskdb.StartTimeEntryParams) (taskdb.TimeEntry, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TimeEntry), args.Error(1)
+}
+
+func (m *MockTaskRepo) StopTimeEntry(ctx context.Context, arg taskdb.StopTimeEntryParams) (taskdb.TimeEntry, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TimeEntry), args.Error(1)
+}
+
+func (m *MockTaskRepo) SumProjectTimeByUser(ctx context.Context, arg taskdb.SumProjectTimeByUserParams) ([]taskdb.SumProjectTimeByUserRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]taskdb.SumProjectTimeByUserRow), args.Error(1)
+}
+
+func (m *MockTaskRepo) SumTaskTimeSpent(ctx context.Context, taskIds []int64) ([]taskdb.SumTaskTimeSpentRow, error) {
+	args := m.Called(ctx, taskIds)
+	return args.Get(0).([]taskdb.SumTaskTimeSpentRow), args.Error(1)
+}
+
+func (m *MockTaskRepo) SumUserTimeByProject(ctx context.Context, arg taskdb.SumUserTimeByProjectParams) ([]taskdb.SumUserTimeByProjectRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]taskdb.SumUserTimeByProjectRow), args.Error(1)
+}
+
+func (m *MockTaskRepo) UseRemainingEstimate(ctx context.Context, arg taskdb.UseRemainingEstimateParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepo) CreateTaskAttachment(ctx context.Context, arg taskdb.CreateTaskAttachmentParams) (taskdb.TaskAttachment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TaskAttachment), args.Error(1)
+}
+
+func (m *MockTaskRepo) GetTaskAttachment(ctx context.Context, arg taskdb.GetTaskAttachmentParams) (taskdb.TaskAttachment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(taskdb.TaskAttachment), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListTaskAttachments(ctx context.Context, taskID int64) ([]taskdb.TaskAttachment, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]taskdb.TaskAttachment), args.Error(1)
+}
+
+func (m *MockTaskRepo) DeleteTaskAttachment(ctx context.Context, id int64) (int64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListQueuedAttachmentDeletions(ctx context.Context, maxKeys int32) ([]string, error) {
+	args := m.Called(ctx, maxKeys)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTaskRepo) QueueAttachmentDeletion(ctx context.Context, storageKey string) error {
+	args := m.Called(ctx, storageKey)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepo) DequeueAttachmentDeletion(ctx context.Context, storageKey string) error {
+	args := m.Called(ctx, storageKey)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepo) ListTaskChanges(ctx context.Context, taskID int64) ([]taskdb.ListTaskChangesRow, error) {
+	args := m.Called(ctx, taskID)
+	return args.Get(0).([]taskdb.ListTaskChangesRow), args.Error(1)
+}
+
+func (m *MockTaskRepo) ListProjectActivity(ctx context.Context, arg taskdb.ListProjectActivityParams) ([]taskdb.ListProjectActivityRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]taskdb.ListProjectActivityRow), args.Error(1)
+}
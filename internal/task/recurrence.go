@@ -0,0 +1,223 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+)
+
+const (
+	maxRecurrenceInterval = 99
+	maxRecurrenceCount    = 1000
+	// maxRecurrencePeriods bounds the search for the next occurrence, for
+	// rules such as the 31st of every other month that skip some periods.
+	maxRecurrencePeriods = 1000
+)
+
+var recurrenceDays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// RecurrenceRule is the subset of RFC 5545 recurrence rules tasks repeat by:
+// FREQ=DAILY, WEEKLY or MONTHLY with INTERVAL, BYDAY and either UNTIL or
+// COUNT. Weeks start on Monday and the first occurrence of a series is the due
+// date of the task it was set up on.
+type RecurrenceRule struct {
+	Freq     string
+	Interval int
+	ByDay    []time.Weekday
+	Until    time.Time // zero when the series has no end date
+	Count    int       // zero when the series has no occurrence limit
+}
+
+// ParseRecurrenceRule parses a rule such as FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH
+// with or without the RRULE: prefix.
+func ParseRecurrenceRule(value string) (*RecurrenceRule, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= 6 && strings.EqualFold(value[:6], "RRULE:") {
+		value = value[6:]
+	}
+	rule := &RecurrenceRule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(part)), "=")
+		if !ok || seen[key] {
+			return nil, invalidRecurrence("expected NAME=VALUE parts, each name once")
+		}
+		seen[key] = true
+		switch key {
+		case "FREQ":
+			if val != "DAILY" && val != "WEEKLY" && val != "MONTHLY" {
+				return nil, invalidRecurrence("FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+			rule.Freq = val
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 || interval > maxRecurrenceInterval {
+				return nil, invalidRecurrence(fmt.Sprintf("INTERVAL must be between 1 and %d", maxRecurrenceInterval))
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				day, ok := recurrenceDays[code]
+				if !ok {
+					return nil, invalidRecurrence("BYDAY takes MO, TU, WE, TH, FR, SA and SU")
+				}
+				if !slices.Contains(rule.ByDay, day) {
+					rule.ByDay = append(rule.ByDay, day)
+				}
+			}
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return nil, invalidRecurrence("UNTIL must be a date such as 20261231 or a UTC time such as 20261231T170000Z")
+			}
+			rule.Until = until
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 || count > maxRecurrenceCount {
+				return nil, invalidRecurrence(fmt.Sprintf("COUNT must be between 1 and %d", maxRecurrenceCount))
+			}
+			rule.Count = count
+		default:
+			return nil, invalidRecurrence(key + " is not supported")
+		}
+	}
+	if rule.Freq == "" {
+		return nil, invalidRecurrence("FREQ is required")
+	}
+	if !rule.Until.IsZero() && rule.Count > 0 {
+		return nil, invalidRecurrence("UNTIL and COUNT cannot be combined")
+	}
+	slices.SortFunc(rule.ByDay, func(a, b time.Weekday) int { return weekdayOffset(a) - weekdayOffset(b) })
+	return rule, nil
+}
+
+// String returns the rule in the normalized form series store.
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = strings.ToUpper(day.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// After returns the occurrence following prev, occurrence number n of a series
+// that started at start. ok is false once UNTIL or COUNT ends the series.
+func (r *RecurrenceRule) After(start time.Time, prev time.Time, n int32) (next time.Time, ok bool) {
+	if r.Count > 0 && int(n) >= r.Count {
+		return time.Time{}, false
+	}
+	period := r.period(start, prev)
+	period -= period % r.Interval
+	for i := 0; i < maxRecurrencePeriods; i, period = i+1, period+r.Interval {
+		for _, candidate := range r.candidates(start, period) {
+			if !candidate.After(prev) {
+				continue
+			}
+			if !r.Until.IsZero() && candidate.After(r.Until) {
+				return time.Time{}, false
+			}
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// period returns how many days, weeks or months after the one of start t falls.
+func (r *RecurrenceRule) period(start time.Time, t time.Time) int {
+	switch r.Freq {
+	case "DAILY":
+		return daysBetween(start, t)
+	case "WEEKLY":
+		return daysBetween(weekStart(start), weekStart(t)) / 7
+	default:
+		return (t.Year()-start.Year())*12 + int(t.Month()) - int(start.Month())
+	}
+}
+
+// candidates returns the occurrences in the given period in order, at the
+// time of day of start.
+func (r *RecurrenceRule) candidates(start time.Time, period int) []time.Time {
+	var days []time.Time
+	switch r.Freq {
+	case "DAILY":
+		day := start.AddDate(0, 0, period)
+		if len(r.ByDay) == 0 || slices.Contains(r.ByDay, day.Weekday()) {
+			days = append(days, day)
+		}
+	case "WEEKLY":
+		byDay := r.ByDay
+		if len(byDay) == 0 {
+			byDay = []time.Weekday{start.Weekday()}
+		}
+		for _, weekday := range byDay {
+			days = append(days, weekStart(start).AddDate(0, 0, 7*period+weekdayOffset(weekday)))
+		}
+	default:
+		first := time.Date(start.Year(), start.Month()+time.Month(period), 1, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+		if len(r.ByDay) == 0 {
+			// months without the day of start are skipped
+			day := first.AddDate(0, 0, start.Day()-1)
+			if day.Month() == first.Month() {
+				days = append(days, day)
+			}
+		}
+		for day := first; day.Month() == first.Month() && len(r.ByDay) > 0; day = day.AddDate(0, 0, 1) {
+			if slices.Contains(r.ByDay, day.Weekday()) {
+				days = append(days, day)
+			}
+		}
+	}
+	return days
+}
+
+// weekStart returns the Monday of the week of t, at the time of day of t.
+func weekStart(t time.Time) time.Time {
+	return t.AddDate(0, 0, -weekdayOffset(t.Weekday()))
+}
+
+// weekdayOffset counts the days from Monday to day.
+func weekdayOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+func daysBetween(from time.Time, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// parseUntil reads an UNTIL value; a date covers the whole day.
+func parseUntil(value string) (time.Time, error) {
+	if until, err := time.Parse("20060102T150405Z", value); err == nil {
+		return until, nil
+	}
+	until, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return until.Add(24*time.Hour - time.Second), nil
+}
+
+func invalidRecurrence(detail string) error {
+	return fmt.Errorf("%w: %s", customErrors.ErrInvalidRecurrence, detail)
+}
@@ -0,0 +1,94 @@
+package task
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected string
+		valid    bool
+	}{
+		{name: "daily", value: "FREQ=DAILY", expected: "FREQ=DAILY", valid: true},
+		{name: "prefix and lower case", value: "RRULE:freq=weekly;byday=th,mo;interval=2", expected: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", valid: true},
+		{name: "until date", value: "FREQ=MONTHLY;UNTIL=20261231", expected: "FREQ=MONTHLY;UNTIL=20261231T235959Z", valid: true},
+		{name: "count", value: "FREQ=DAILY;COUNT=5", expected: "FREQ=DAILY;COUNT=5", valid: true},
+		{name: "missing frequency", value: "INTERVAL=2"},
+		{name: "yearly frequency", value: "FREQ=YEARLY"},
+		{name: "zero interval", value: "FREQ=DAILY;INTERVAL=0"},
+		{name: "unknown day", value: "FREQ=WEEKLY;BYDAY=XX"},
+		{name: "until and count", value: "FREQ=DAILY;UNTIL=20261231;COUNT=3"},
+		{name: "repeated part", value: "FREQ=DAILY;FREQ=WEEKLY"},
+		{name: "unsupported part", value: "FREQ=MONTHLY;BYMONTHDAY=1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tc.value)
+			if !tc.valid {
+				assert.True(t, errors.Is(err, customErrors.ErrInvalidRecurrence))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, rule.String())
+		})
+	}
+}
+
+func TestRecurrenceAfter(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+	}
+	// series start on Thursday 1 October 2026 unless the case says otherwise
+	start := date(2026, time.October, 1)
+	testCases := []struct {
+		name     string
+		rule     string
+		start    time.Time
+		prev     time.Time
+		n        int32
+		expected []time.Time
+	}{
+		{name: "every other day", rule: "FREQ=DAILY;INTERVAL=2", prev: start, n: 1, expected: []time.Time{date(2026, time.October, 3), date(2026, time.October, 5)}},
+		{name: "weekdays", rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", prev: date(2026, time.October, 2), n: 2, expected: []time.Time{date(2026, time.October, 5)}},
+		{name: "weekly on the day of start", rule: "FREQ=WEEKLY", prev: start, n: 1, expected: []time.Time{date(2026, time.October, 8)}},
+		{name: "every other week on monday and thursday", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", prev: start, n: 1, expected: []time.Time{date(2026, time.October, 12), date(2026, time.October, 15), date(2026, time.October, 26)}},
+		{name: "monthly skips months without the day", rule: "FREQ=MONTHLY", start: date(2026, time.October, 31), prev: date(2026, time.October, 31), n: 1, expected: []time.Time{date(2026, time.December, 31), date(2027, time.January, 31), date(2027, time.March, 31)}},
+		{name: "monthly on fridays", rule: "FREQ=MONTHLY;INTERVAL=3;BYDAY=FR", prev: date(2026, time.October, 30), n: 1, expected: []time.Time{date(2027, time.January, 1)}},
+		{name: "count ends the series", rule: "FREQ=DAILY;COUNT=3", prev: start, n: 1, expected: []time.Time{date(2026, time.October, 2), date(2026, time.October, 3)}},
+		{name: "until ends the series", rule: "FREQ=WEEKLY;UNTIL=20261015", prev: start, n: 1, expected: []time.Time{date(2026, time.October, 8), date(2026, time.October, 15)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tc.rule)
+			assert.NoError(t, err)
+			seriesStart := start
+			if !tc.start.IsZero() {
+				seriesStart = tc.start
+			}
+			var got []time.Time
+			prev, n := tc.prev, tc.n
+			for len(got) < len(tc.expected)+1 {
+				next, ok := rule.After(seriesStart, prev, n)
+				if !ok {
+					break
+				}
+				got = append(got, next)
+				prev, n = next, n+1
+			}
+			if rule.Count > 0 || !rule.Until.IsZero() {
+				assert.Equal(t, tc.expected, got)
+			} else {
+				assert.Equal(t, tc.expected, got[:len(tc.expected)])
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SetRecurrence makes a task recur
+// @Summary      Make a task recur
+// @Description  Starts a recurring series from the task, which becomes its first occurrence. The rule is an RFC 5545 subset: FREQ=DAILY, WEEKLY or MONTHLY with INTERVAL, BYDAY and either UNTIL or COUNT. The next occurrence is created once the latest one is done or its due date has passed.
+// @Tags         tasks
+// @Accept       json
+// @Produce      json
+// @Param        id          path      int                   true  "Task ID"
+// @Param        recurrence  body      SetRecurrenceRequest  true  "Recurrence rule"
+// @Success      201         {object}  map[string]interface{}
+// @Failure      400         {object}  utils.ErrorResponse
+// @Failure      403         {object}  utils.ErrorResponse
+// @Failure      404         {object}  utils.ErrorResponse
+// @Failure      409         {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/recurrence [post]
+// @Security BearerAuth
+func (t *TaskHandler) SetRecurrence(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	var req SetRecurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		t.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	series, err := t.taskService.SetRecurrence(ctx, userID, orgID, taskID, req)
+	if err != nil {
+		t.logger.Errorf("unable to make task %d recur %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusCreated, series)
+}
+
+// GetSeries returns the recurring series of a task
+// @Summary      Get the series of a task
+// @Description  Returns the recurring series the task is an occurrence of, with its rule and the due date of the next occurrence
+// @Tags         tasks
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/series [get]
+// @Security BearerAuth
+func (t *TaskHandler) GetSeries(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	series, err := t.taskService.GetSeries(ctx, userID, orgID, taskID)
+	if err != nil {
+		t.logger.Errorf("unable to get the series of task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, series)
+}
+
+// UpdateSeries edits the recurring series of a task
+// @Summary      Edit the series of a task
+// @Description  Edits every open occurrence of the series and those created from now on; PATCH /api/v1/tasks/{id} edits this occurrence only. A new rule counts from the latest occurrence.
+// @Tags         tasks
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                  true  "Task ID"
+// @Param        series  body      UpdateSeriesRequest  true  "Changes"
+// @Success      200     {object}  map[string]interface{}
+// @Failure      400     {object}  utils.ErrorResponse
+// @Failure      403     {object}  utils.ErrorResponse
+// @Failure      404     {object}  utils.ErrorResponse
+// @Failure      409     {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/series [patch]
+// @Security BearerAuth
+func (t *TaskHandler) UpdateSeries(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	var req UpdateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		t.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if req.AssigneeEmail != nil {
+		assignee, err := t.userService.GetUserByEmail(ctx, *req.AssigneeEmail)
+		if err != nil {
+			t.logger.Errorf("%v", err)
+			utils.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		assigneeID := int64(assignee.ID)
+		req.AssigneeID = &assigneeID
+	}
+	series, err := t.taskService.UpdateSeries(ctx, userID, orgID, taskID, req)
+	if err != nil {
+		t.logger.Errorf("unable to update the series of task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, series)
+}
+
+// DeleteSeries stops a task from recurring
+// @Summary      Stop a series
+// @Description  Ends the recurring series of the task. Occurrences already created are kept as plain tasks.
+// @Tags         tasks
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/series [delete]
+// @Security BearerAuth
+func (t *TaskHandler) DeleteSeries(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := t.taskService.DeleteSeries(ctx, userID, orgID, taskID); err != nil {
+		t.logger.Errorf("unable to stop the series of task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "series stopped successfully",
+	})
+}
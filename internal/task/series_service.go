@@ -0,0 +1,334 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+)
+
+// SetRecurrence makes the task the first occurrence of a series repeating by
+// the given rule.
+func (t *TaskService) SetRecurrence(ctx context.Context, userID int, orgID int, taskID int, req SetRecurrenceRequest) (*taskdb.TaskSeries, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionWrite); err != nil {
+		return nil, err
+	}
+	rule, err := ParseRecurrenceRule(req.Rule)
+	if err != nil {
+		return nil, err
+	}
+	task, err := t.taskRepository.GetTaskById(ctx, int64(taskID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if task.SeriesID.Valid {
+		return nil, customErrors.ErrTaskAlreadyRecurring
+	}
+	if task.ParentTaskID.Valid {
+		return nil, invalidRecurrence("subtasks cannot recur")
+	}
+	if !task.DueDate.Valid {
+		return nil, invalidRecurrence("the task needs a due date to start the series from")
+	}
+	return t.startSeries(ctx, userID, &task, rule)
+}
+
+// GetSeries returns the series the task is an occurrence of.
+func (t *TaskService) GetSeries(ctx context.Context, userID int, orgID int, taskID int) (*taskdb.TaskSeries, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	_, series, err := t.seriesOf(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// UpdateSeries edits the series the task is an occurrence of. The changes
+// apply to the occurrences still open and to those created from now on; a
+// new rule counts from the due date of the latest occurrence.
+func (t *TaskService) UpdateSeries(ctx context.Context, userID int, orgID int, taskID int, req UpdateSeriesRequest) (*taskdb.TaskSeries, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionWrite); err != nil {
+		return nil, err
+	}
+	task, series, err := t.seriesOf(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	params := taskdb.UpdateTaskSeriesParams{ID: series.ID}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			return nil, customErrors.ErrorTaskTitleMissing
+		}
+		params.Title = sql.NullString{String: title, Valid: true}
+	}
+	if req.Description != nil {
+		params.Description = sql.NullString{String: *req.Description, Valid: true}
+	}
+	if req.Priority != nil {
+		params.Priority = taskdb.NullTaskPriority{TaskPriority: getPriority(*req.Priority), Valid: true}
+	}
+	if req.AssigneeID != nil {
+		isMember, err := t.taskRepository.IsProjectMember(ctx, taskdb.IsProjectMemberParams{
+			ProjectID: series.ProjectID,
+			UserID:    int32(*req.AssigneeID),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			return nil, customErrors.ErrAssigneeNotProjectMember
+		}
+		params.AssigneeID = sql.NullInt64{Int64: *req.AssigneeID, Valid: true}
+	}
+	if req.Rule != nil {
+		rule, err := ParseRecurrenceRule(*req.Rule)
+		if err != nil {
+			return nil, err
+		}
+		start := task.DueDate.Time
+		if series.LatestTaskID.Valid && series.LatestTaskID.Int64 != task.ID {
+			latest, err := t.taskRepository.GetTaskById(ctx, series.LatestTaskID.Int64)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			if err == nil && latest.DueDate.Valid {
+				start = latest.DueDate.Time
+			}
+		}
+		next, ok := rule.After(start, start, 1)
+		params.Rule = sql.NullString{String: rule.String(), Valid: true}
+		params.StartsAt = sql.NullTime{Time: start, Valid: true}
+		params.NextDueAt = sql.NullTime{Time: next, Valid: ok}
+	}
+	updated, err := t.taskRepository.UpdateTaskSeries(ctx, params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrTaskSeriesNotFound
+	}
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+		return nil, customErrors.ErrTaskAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteSeries ends the series the task is an occurrence of. Its occurrences
+// are kept as plain tasks.
+func (t *TaskService) DeleteSeries(ctx context.Context, userID int, orgID int, taskID int) error {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionWrite); err != nil {
+		return err
+	}
+	_, series, err := t.seriesOf(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	rows, err := t.taskRepository.DeleteTaskSeries(ctx, series.ID)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrTaskSeriesNotFound
+	}
+	return nil
+}
+
+// DueTaskSeries lists up to limit series whose next occurrence is due, across
+// every organization, for the recurrence scheduler.
+func (t *TaskService) DueTaskSeries(ctx context.Context, limit int32) ([]taskdb.ListDueTaskSeriesRow, error) {
+	return t.taskRepository.ListDueTaskSeries(ctx, limit)
+}
+
+// MaterializeSeries creates the next occurrence of a series as userID, who
+// needs write access to its project. A series userID can no longer create
+// tasks in is ended, so it is not retried, and ErrTaskSeriesEnded returned.
+func (t *TaskService) MaterializeSeries(ctx context.Context, userID int, orgID int, seriesID int64) error {
+	series, err := t.taskRepository.GetTaskSeries(ctx, seriesID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the series is hidden from users who left its project's organization
+		return t.endSeries(ctx, seriesID, customErrors.ErrTaskSeriesNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	err = t.materialize(ctx, userID, orgID, series)
+	if errors.Is(err, customErrors.ErrForbidden) || errors.Is(err, customErrors.ErrProjectIDNotExist) {
+		return t.endSeries(ctx, seriesID, err)
+	}
+	return err
+}
+
+// endSeries ends a series whose creator lost access to it for reason.
+func (t *TaskService) endSeries(ctx context.Context, seriesID int64, reason error) error {
+	if err := t.taskRepository.EndTaskSeries(ctx, seriesID); err != nil {
+		return errors.Join(reason, err)
+	}
+	return fmt.Errorf("%w: %v", customErrors.ErrTaskSeriesEnded, reason)
+}
+
+// startSeries makes task the first occurrence of a series repeating by rule.
+func (t *TaskService) startSeries(ctx context.Context, userID int, task *taskdb.Task, rule *RecurrenceRule) (*taskdb.TaskSeries, error) {
+	start := task.DueDate.Time
+	next, ok := rule.After(start, start, 1)
+	series, err := t.taskRepository.CreateTaskSeries(ctx, taskdb.CreateTaskSeriesParams{
+		ProjectID:    task.ProjectID,
+		CreatedBy:    int32(userID),
+		Title:        task.Title,
+		Description:  task.Description,
+		AssigneeID:   task.AssigneeID,
+		Priority:     task.Priority,
+		Rule:         rule.String(),
+		StartsAt:     start,
+		NextDueAt:    sql.NullTime{Time: next, Valid: ok},
+		LatestTaskID: sql.NullInt64{Int64: task.ID, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	task.SeriesID = sql.NullInt64{Int64: series.ID, Valid: true}
+	return &series, nil
+}
+
+// materialize creates the occurrence the series is waiting for, with the
+// labels and custom field values of the latest one, and moves the series on
+// to the occurrence after it.
+func (t *TaskService) materialize(ctx context.Context, userID int, orgID int, series taskdb.TaskSeries) error {
+	if !series.NextDueAt.Valid {
+		return nil
+	}
+	rule, err := ParseRecurrenceRule(series.Rule)
+	if err != nil {
+		return err
+	}
+	due := series.NextDueAt.Time
+	input := CreateTaskInput{
+		ProjectID:   int(series.ProjectID),
+		Title:       occurrenceTitle(series.Title, due),
+		AssigneeID:  int(series.CreatedBy),
+		Description: series.Description,
+		Priority:    strings.ToLower(string(series.Priority)),
+		DueDate:     due,
+		SeriesID:    &series.ID,
+	}
+	if series.AssigneeID.Valid {
+		input.AssigneeID = int(series.AssigneeID.Int64)
+	}
+	if series.LatestTaskID.Valid {
+		if input.LabelIDs, input.CustomFields, err = t.occurrenceValues(ctx, series.LatestTaskID.Int64); err != nil {
+			return err
+		}
+	}
+	next, ok := rule.After(series.StartsAt, due, series.Occurrences+1)
+	advance := func(ctx context.Context, task taskdb.Task) error {
+		_, err := t.taskRepository.AdvanceTaskSeries(ctx, taskdb.AdvanceTaskSeriesParams{
+			NextDueAt:    sql.NullTime{Time: next, Valid: ok},
+			LatestTaskID: task.ID,
+			ID:           series.ID,
+			DueAt:        due,
+		})
+		return err
+	}
+	// the series moves on in the transaction that creates the occurrence
+	_, err = t.createTask(ctx, userID, orgID, input, advance)
+	if !errors.Is(err, customErrors.ErrTaskAlreadyExists) {
+		return err
+	}
+	// an earlier run may have created the occurrence without moving the
+	// series on
+	existing, err := t.taskRepository.GetTaskByTitle(ctx, taskdb.GetTaskByTitleParams{ProjectID: series.ProjectID, Title: input.Title})
+	if err != nil {
+		return err
+	}
+	if existing.SeriesID.Int64 != series.ID {
+		return fmt.Errorf("%w: %s", customErrors.ErrTaskAlreadyExists, input.Title)
+	}
+	return advance(ctx, existing)
+}
+
+// completeOccurrence creates the next occurrence right away when task, just
+// moved to the done category, is the latest occurrence of its series. A
+// failure only delays it, the scheduler picks up series whose latest
+// occurrence is done, and is returned as ErrNextOccurrenceDelayed.
+func (t *TaskService) completeOccurrence(ctx context.Context, userID int, orgID int, task taskdb.Task) error {
+	if !task.SeriesID.Valid {
+		return nil
+	}
+	series, err := t.taskRepository.GetTaskSeries(ctx, task.SeriesID.Int64)
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErrors.ErrNextOccurrenceDelayed, err)
+	}
+	if series.LatestTaskID.Int64 != task.ID {
+		return nil
+	}
+	if err := t.materialize(ctx, userID, orgID, series); err != nil {
+		return fmt.Errorf("%w: %v", customErrors.ErrNextOccurrenceDelayed, err)
+	}
+	return nil
+}
+
+// seriesOf returns the task and the series it is an occurrence of.
+func (t *TaskService) seriesOf(ctx context.Context, taskID int) (*taskdb.Task, *taskdb.TaskSeries, error) {
+	task, err := t.taskRepository.GetTaskById(ctx, int64(taskID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, customErrors.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !task.SeriesID.Valid {
+		return nil, nil, customErrors.ErrTaskSeriesNotFound
+	}
+	series, err := t.taskRepository.GetTaskSeries(ctx, task.SeriesID.Int64)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, customErrors.ErrTaskSeriesNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &task, &series, nil
+}
+
+// occurrenceValues returns the labels and custom field values of an
+// occurrence in the form CreateTask takes them.
+func (t *TaskService) occurrenceValues(ctx context.Context, taskID int64) ([]int64, map[int64]any, error) {
+	labels, err := t.taskRepository.ListTaskLabels(ctx, []int64{taskID})
+	if err != nil {
+		return nil, nil, err
+	}
+	labelIDs := make([]int64, len(labels))
+	for i, label := range labels {
+		labelIDs[i] = label.ID
+	}
+	values, err := t.taskRepository.ListTaskCustomFieldValues(ctx, []int64{taskID})
+	if err != nil {
+		return nil, nil, err
+	}
+	customFields := map[int64]any{}
+	for _, value := range values {
+		var decoded any
+		if err := json.Unmarshal(value.Value, &decoded); err != nil {
+			return nil, nil, err
+		}
+		customFields[value.FieldID] = decoded
+	}
+	return labelIDs, customFields, nil
+}
+
+// occurrenceTitle names an occurrence after its series and due date, as task
+// titles are unique within a project.
+func occurrenceTitle(title string, due time.Time) string {
+	return fmt.Sprintf("%s (%s)", title, due.Format("2006-01-02"))
+}
@@ -0,0 +1,276 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// occurrence returns task id of series 9 in project 6, due on the given day
+// of October 2026.
+func occurrence(id int64, day int) taskdb.Task {
+	task := subtask(id, 6, 0)
+	task.Title = "Standup"
+	task.SeriesID = sql.NullInt64{Int64: 9, Valid: true}
+	task.DueDate = sql.NullTime{Time: time.Date(2026, time.October, day, 9, 0, 0, 0, time.UTC), Valid: true}
+	return task
+}
+
+func TestSetRecurrence(t *testing.T) {
+	due := time.Date(2026, time.October, 1, 9, 0, 0, 0, time.UTC)
+	withDueDate := subtask(22, 6, 0)
+	withDueDate.Title = "Standup"
+	withDueDate.DueDate = sql.NullTime{Time: due, Valid: true}
+
+	testCases := []struct {
+		name          string
+		task          taskdb.Task
+		rule          string
+		expectedError error
+	}{
+		{name: "weekly series", task: withDueDate, rule: "FREQ=WEEKLY;BYDAY=TH"},
+		{name: "invalid rule", task: withDueDate, rule: "FREQ=YEARLY", expectedError: customErrors.ErrInvalidRecurrence},
+		{name: "already recurring", task: occurrence(22, 1), rule: "FREQ=DAILY", expectedError: customErrors.ErrTaskAlreadyRecurring},
+		{name: "without a due date", task: subtask(22, 6, 0), rule: "FREQ=DAILY", expectedError: customErrors.ErrInvalidRecurrence},
+		{name: "subtask", task: subtask(22, 6, 21), rule: "FREQ=DAILY", expectedError: customErrors.ErrInvalidRecurrence},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newCommentService()
+			repo.On("GetTaskById", mock.Anything, int64(22)).Return(tc.task, nil)
+			repo.On("CreateTaskSeries", mock.Anything, taskdb.CreateTaskSeriesParams{
+				ProjectID:    6,
+				CreatedBy:    1234,
+				Title:        "Standup",
+				Rule:         "FREQ=WEEKLY;BYDAY=TH",
+				StartsAt:     due,
+				NextDueAt:    sql.NullTime{Time: due.AddDate(0, 0, 7), Valid: true},
+				LatestTaskID: sql.NullInt64{Int64: 22, Valid: true},
+			}).Return(taskdb.TaskSeries{ID: 9, ProjectID: 6, Rule: "FREQ=WEEKLY;BYDAY=TH"}, nil)
+
+			series, err := service.SetRecurrence(context.TODO(), 1234, testOrgID, 22, SetRecurrenceRequest{Rule: tc.rule})
+			assert.True(t, errors.Is(err, tc.expectedError))
+			if tc.expectedError == nil {
+				assert.Equal(t, int64(9), series.ID)
+			} else {
+				repo.AssertNotCalled(t, "CreateTaskSeries", mock.Anything, mock.Anything)
+			}
+		})
+	}
+
+	t.Run("viewers cannot make tasks recur", func(t *testing.T) {
+		service, _ := newCommentService()
+		_, err := service.SetRecurrence(context.TODO(), 1234, testOrgID, 21, SetRecurrenceRequest{Rule: "FREQ=DAILY"})
+		assert.Equal(t, customErrors.ErrForbidden, err)
+	})
+}
+
+func TestCompleteOccurrence(t *testing.T) {
+	series := taskdb.TaskSeries{
+		ID:           9,
+		ProjectID:    6,
+		CreatedBy:    1234,
+		Title:        "Standup",
+		Rule:         "FREQ=DAILY;COUNT=3",
+		StartsAt:     occurrence(22, 1).DueDate.Time,
+		Occurrences:  1,
+		NextDueAt:    sql.NullTime{Time: occurrence(22, 2).DueDate.Time, Valid: true},
+		LatestTaskID: sql.NullInt64{Int64: 22, Valid: true},
+	}
+	done := "done"
+
+	testCases := []struct {
+		name          string
+		latestID      int64
+		createErr     error
+		created       bool
+		expectedError error
+	}{
+		{name: "latest occurrence done", latestID: 22, created: true},
+		{name: "earlier occurrence done", latestID: 23},
+		{name: "next occurrence fails", latestID: 22, createErr: errors.New("connection reset"), expectedError: customErrors.ErrNextOccurrenceDelayed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newCommentService()
+			latest := series
+			latest.LatestTaskID = sql.NullInt64{Int64: tc.latestID, Valid: true}
+			repo.On("GetTaskById", mock.Anything, int64(22)).Return(occurrence(22, 1), nil)
+			repo.On("CountOpenBlockers", mock.Anything, int64(22)).Return(int64(0), nil)
+			repo.On("ListWorkflowStatuses", mock.Anything, int64(6)).Return(defaultWorkflow(6), nil)
+			repo.On("UpdateTask", mock.Anything, mock.Anything).Return(occurrence(22, 1), nil)
+			repo.On("GetTaskSeries", mock.Anything, int64(9)).Return(latest, nil)
+			repo.On("ListTaskLabels", mock.Anything, []int64{22}).Return([]taskdb.ListTaskLabelsRow(nil), nil)
+			repo.On("ListTaskCustomFieldValues", mock.Anything, []int64{22}).Return([]taskdb.TaskCustomFieldValue(nil), nil)
+			repo.On("IsProjectMember", mock.Anything, taskdb.IsProjectMemberParams{ProjectID: 6, UserID: 1234}).Return(true, nil)
+			repo.On("CreateTask", mock.Anything, mock.MatchedBy(func(params taskdb.CreateTaskParams) bool {
+				return params.Title == "Standup (2026-10-02)" && params.SeriesID.Int64 == 9 && params.Status == "TODO"
+			})).Return(occurrence(23, 2), tc.createErr)
+			repo.On("AdvanceTaskSeries", mock.Anything, taskdb.AdvanceTaskSeriesParams{
+				NextDueAt:    sql.NullTime{Time: occurrence(23, 3).DueDate.Time, Valid: true},
+				LatestTaskID: 23,
+				ID:           9,
+				DueAt:        occurrence(23, 2).DueDate.Time,
+			}).Return(int64(1), nil)
+
+			err := service.UpdateTask(context.TODO(), 1234, testOrgID, UpdateTaskRequest{ID: 22, Status: &done})
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			repo.AssertCalled(t, "UpdateTask", mock.Anything, mock.Anything)
+			if tc.created {
+				repo.AssertCalled(t, "AdvanceTaskSeries", mock.Anything, mock.Anything)
+			} else {
+				repo.AssertNotCalled(t, "AdvanceTaskSeries", mock.Anything, mock.Anything)
+			}
+			if tc.latestID != 22 {
+				repo.AssertNotCalled(t, "CreateTask", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestMaterializeSeries(t *testing.T) {
+	// user 1234 only views project 5
+	series := taskdb.TaskSeries{
+		ID:        9,
+		ProjectID: 5,
+		CreatedBy: 1234,
+		Title:     "Standup",
+		Rule:      "FREQ=DAILY",
+		StartsAt:  occurrence(22, 1).DueDate.Time,
+		NextDueAt: sql.NullTime{Time: occurrence(22, 2).DueDate.Time, Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		seriesErr     error
+		ended         bool
+		expectedError error
+	}{
+		{name: "creator can no longer write to the project", ended: true, expectedError: customErrors.ErrTaskSeriesEnded},
+		{name: "creator left the organization", seriesErr: sql.ErrNoRows, ended: true, expectedError: customErrors.ErrTaskSeriesEnded},
+		{name: "series unavailable", seriesErr: sql.ErrConnDone, expectedError: sql.ErrConnDone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			authzRepo := new(authz.MockAuthzRepo)
+			authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 5, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 5, UserID: 99, Role: authzdb.NullProjectRole{ProjectRole: authzdb.ProjectRoleVIEWER, Valid: true}}, nil)
+			repo := new(MockTaskRepo)
+			service := NewTaskService(repo, authz.NewAuthorizationService(authzRepo))
+			repo.On("GetTaskSeries", mock.Anything, int64(9)).Return(series, tc.seriesErr)
+			repo.On("EndTaskSeries", mock.Anything, int64(9)).Return(nil)
+
+			err := service.MaterializeSeries(context.TODO(), 1234, testOrgID, 9)
+			assert.ErrorIs(t, err, tc.expectedError)
+			repo.AssertNotCalled(t, "CreateTask", mock.Anything, mock.Anything)
+			if tc.ended {
+				repo.AssertCalled(t, "EndTaskSeries", mock.Anything, int64(9))
+			} else {
+				repo.AssertNotCalled(t, "EndTaskSeries", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestMaterializeSeriesInOneTransaction(t *testing.T) {
+	service, repo := newCommentService()
+	transactor := &fakeTransactor{}
+	service.UseTransactions(transactor)
+	inTx := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(txKey{}) != nil })
+	failed := errors.New("advance failed")
+	repo.On("GetTaskSeries", mock.Anything, int64(9)).Return(taskdb.TaskSeries{
+		ID:        9,
+		ProjectID: 6,
+		CreatedBy: 1234,
+		Title:     "Standup",
+		Rule:      "FREQ=DAILY",
+		StartsAt:  occurrence(22, 1).DueDate.Time,
+		NextDueAt: sql.NullTime{Time: occurrence(22, 2).DueDate.Time, Valid: true},
+	}, nil)
+	repo.On("IsProjectMember", mock.Anything, mock.Anything).Return(true, nil)
+	repo.On("ListWorkflowStatuses", mock.Anything, int64(6)).Return(defaultWorkflow(6), nil)
+	repo.On("CreateTask", inTx, mock.Anything).Return(occurrence(23, 2), nil)
+	repo.On("AdvanceTaskSeries", inTx, mock.Anything).Return(int64(0), failed)
+
+	err := service.MaterializeSeries(context.TODO(), 1234, testOrgID, 9)
+	assert.Equal(t, failed, err)
+	assert.Equal(t, failed, transactor.err, "the failed write must roll the transaction back")
+	repo.AssertExpectations(t)
+}
+
+func TestUpdateSeries(t *testing.T) {
+	series := taskdb.TaskSeries{
+		ID:           9,
+		ProjectID:    6,
+		CreatedBy:    1234,
+		Title:        "Standup",
+		Rule:         "FREQ=DAILY",
+		StartsAt:     occurrence(22, 1).DueDate.Time,
+		Occurrences:  2,
+		NextDueAt:    sql.NullTime{Time: occurrence(23, 3).DueDate.Time, Valid: true},
+		LatestTaskID: sql.NullInt64{Int64: 23, Valid: true},
+	}
+	rule, invalidRule, title := "FREQ=WEEKLY", "FREQ=HOURLY", "Daily sync"
+
+	testCases := []struct {
+		name          string
+		req           UpdateSeriesRequest
+		expected      taskdb.UpdateTaskSeriesParams
+		expectedError error
+	}{
+		{
+			name:     "new title",
+			req:      UpdateSeriesRequest{Title: &title},
+			expected: taskdb.UpdateTaskSeriesParams{ID: 9, Title: sql.NullString{String: title, Valid: true}},
+		},
+		{
+			name: "new rule counts from the latest occurrence",
+			req:  UpdateSeriesRequest{Rule: &rule},
+			expected: taskdb.UpdateTaskSeriesParams{
+				ID:        9,
+				Rule:      sql.NullString{String: rule, Valid: true},
+				StartsAt:  sql.NullTime{Time: occurrence(23, 2).DueDate.Time, Valid: true},
+				NextDueAt: sql.NullTime{Time: occurrence(23, 9).DueDate.Time, Valid: true},
+			},
+		},
+		{name: "invalid rule", req: UpdateSeriesRequest{Rule: &invalidRule}, expectedError: customErrors.ErrInvalidRecurrence},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newCommentService()
+			repo.On("GetTaskById", mock.Anything, int64(22)).Return(occurrence(22, 1), nil)
+			repo.On("GetTaskById", mock.Anything, int64(23)).Return(occurrence(23, 2), nil)
+			repo.On("GetTaskSeries", mock.Anything, int64(9)).Return(series, nil)
+			repo.On("UpdateTaskSeries", mock.Anything, tc.expected).Return(series, nil)
+
+			_, err := service.UpdateSeries(context.TODO(), 1234, testOrgID, 22, tc.req)
+			assert.True(t, errors.Is(err, tc.expectedError))
+			if tc.expectedError != nil {
+				repo.AssertNotCalled(t, "UpdateTaskSeries", mock.Anything, mock.Anything)
+			}
+		})
+	}
+
+	t.Run("plain tasks have no series", func(t *testing.T) {
+		service, repo := newCommentService()
+		repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+		_, err := service.UpdateSeries(context.TODO(), 1234, testOrgID, 22, UpdateSeriesRequest{Title: &title})
+		assert.Equal(t, customErrors.ErrTaskSeriesNotFound, err)
+	})
+}
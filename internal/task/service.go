@@ -6,33 +6,68 @@ import (
 	"errors"
 	"time"
 
+	"github.com/Gkemhcs/taskpilot/internal/authz"
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/storage"
 	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
 	"github.com/lib/pq"
 )
 
-func NewTaskService(taskRepo taskdb.Querier) *TaskService {
+func NewTaskService(taskRepo taskdb.Querier, authorizer authz.Authorizer) *TaskService {
 	return &TaskService{
 		taskRepository: taskRepo,
+		authorizer:     authorizer,
 	}
 
 }
 
 type TaskService struct {
 	taskRepository taskdb.Querier
+	authorizer     authz.Authorizer // Ownership checks consulted before every read or write
+	subscribers    []types.TaskEventSubscriber
+	storage        storage.StorageClient // Where attachments are kept; nil disables them
+	attachments    AttachmentLimits
+	transactor     types.Transactor // Runs the writes of one task together; nil runs them one by one
 }
 
-func getStatus(status string) taskdb.TaskStatus {
+// UseTransactions writes a task and its labels and custom field values in
+// one transaction of transactor. It is not safe to call once the service is
+// in use.
+func (t *TaskService) UseTransactions(transactor types.Transactor) {
+	t.transactor = transactor
+}
 
-	switch status {
-	case "in_progress":
-		return taskdb.TaskStatusINPROGRESS
-	case "done":
-		return taskdb.TaskStatusDONE
-	default:
-		return taskdb.TaskStatusTODO
+func (t *TaskService) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if t.transactor == nil {
+		return fn(ctx)
 	}
+	return t.transactor.InTx(ctx, fn)
+}
+
+// Subscribe registers s to be told about every task and comment write. It is
+// not safe to call once the service is in use.
+func (t *TaskService) Subscribe(s types.TaskEventSubscriber) {
+	t.subscribers = append(t.subscribers, s)
+}
+
+// UseStorage keeps the files attached to tasks in client. It is not safe to
+// call once the service is in use.
+func (t *TaskService) UseStorage(client storage.StorageClient, limits AttachmentLimits) {
+	t.storage = client
+	t.attachments = limits
+}
+
+// AttachmentMaxSize returns the largest file in bytes that can be attached
+// to a task.
+func (t *TaskService) AttachmentMaxSize() int64 {
+	return t.attachments.MaxSize
+}
 
+func (t *TaskService) publish(ctx context.Context, event types.TaskEvent) {
+	for _, s := range t.subscribers {
+		s.HandleTaskEvent(ctx, event)
+	}
 }
 
 func getPriority(priority string) taskdb.TaskPriority {
@@ -48,28 +83,119 @@ func getPriority(priority string) taskdb.TaskPriority {
 	}
 }
 
-func (t *TaskService) CreateTask(ctx context.Context, taskInput CreateTaskInput) (*taskdb.Task, error) {
+func (t *TaskService) CreateTask(ctx context.Context, userID int, orgID int, taskInput CreateTaskInput) (*taskdb.Task, error) {
+	return t.createTask(ctx, userID, orgID, taskInput, nil)
+}
+
+// createTask is CreateTask that also runs then, when given, in the
+// transaction that writes the task.
+func (t *TaskService) createTask(ctx context.Context, userID int, orgID int, taskInput CreateTaskInput, then func(ctx context.Context, task taskdb.Task) error) (*taskdb.Task, error) {
+	if err := t.authorizer.AuthorizeProject(ctx, userID, orgID, taskInput.ProjectID, authz.ActionWrite); err != nil {
+		return nil, err
+	}
+	isMember, err := t.taskRepository.IsProjectMember(ctx, taskdb.IsProjectMemberParams{
+		ProjectID: int64(taskInput.ProjectID),
+		UserID:    int32(taskInput.AssigneeID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, customErrors.ErrAssigneeNotProjectMember
+	}
 	params := taskdb.CreateTaskParams{
 		ProjectID:   int64(taskInput.ProjectID),
 		Title:       taskInput.Title,
 		DueDate:     sql.NullTime{Time: taskInput.DueDate, Valid: true},
 		Description: taskInput.Description,
 		AssigneeID:  sql.NullInt64{Int64: int64(taskInput.AssigneeID), Valid: true},
-		Status:      getStatus(taskInput.Status),
 		Priority:    getPriority(taskInput.Priority),
 	}
-	task, err := t.taskRepository.CreateTask(ctx, params)
-	if IsErrorCode(err, customErrors.UniqueViolationErr) {
-
-		return nil,customErrors.ErrTaskAlreadyExists
+	// new tasks start in the first status of the workflow unless one is given
+	status, _, err := t.workflowStatus(ctx, params.ProjectID, taskInput.Status)
+	if err != nil {
+		return nil, err
 	}
+	params.Status = status.Name
+	var rule *RecurrenceRule
+	if taskInput.Recurrence != "" {
+		if taskInput.ParentTaskID != nil {
+			return nil, invalidRecurrence("subtasks cannot recur")
+		}
+		if taskInput.DueDate.IsZero() {
+			return nil, invalidRecurrence("the task needs a due date to start the series from")
+		}
+		if rule, err = ParseRecurrenceRule(taskInput.Recurrence); err != nil {
+			return nil, err
+		}
+	}
+	if taskInput.SeriesID != nil {
+		params.SeriesID = sql.NullInt64{Int64: *taskInput.SeriesID, Valid: true}
+	}
+	if taskInput.OriginalEstimateMinutes != nil {
+		estimate, err := checkEstimate(*taskInput.OriginalEstimateMinutes)
+		if err != nil {
+			return nil, err
+		}
+		params.OriginalEstimateMinutes = estimate
+		params.RemainingEstimateMinutes = estimate
+	}
+	if taskInput.ParentTaskID != nil {
+		parent, err := t.parentTask(ctx, params.ProjectID, *taskInput.ParentTaskID)
+		if err != nil {
+			return nil, err
+		}
+		params.ParentTaskID = sql.NullInt64{Int64: parent.ID, Valid: true}
+	}
+	labelIDs, err := t.checkLabels(ctx, params.ProjectID, taskInput.LabelIDs)
 	if err != nil {
 		return nil, err
 	}
+	customFields, err := t.checkCustomFields(ctx, params.ProjectID, taskInput.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+	var task taskdb.Task
+	err = t.inTx(ctx, func(ctx context.Context) error {
+		task, err = t.taskRepository.CreateTask(ctx, params)
+		if IsErrorCode(err, customErrors.UniqueViolationErr) {
+			return customErrors.ErrTaskAlreadyExists
+		}
+		if err != nil {
+			return err
+		}
+		if len(labelIDs) > 0 {
+			if err := t.taskRepository.SetTaskLabels(ctx, taskdb.SetTaskLabelsParams{TaskID: task.ID, LabelIds: labelIDs, ProjectID: task.ProjectID}); err != nil {
+				return err
+			}
+		}
+		if customFields != nil {
+			customFields.TaskID = task.ID
+			if err := t.taskRepository.SetTaskCustomFieldValues(ctx, *customFields); err != nil {
+				return err
+			}
+		}
+		if rule != nil {
+			if _, err := t.startSeries(ctx, userID, &task, rule); err != nil {
+				return err
+			}
+		}
+		if then != nil {
+			return then(ctx, task)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.publish(ctx, types.TaskEvent{Kind: types.TaskCreated, ActorID: userID, TaskID: task.ID, Task: &task})
 	return &task, nil
 }
 
-func (t *TaskService) GetTaskByID(ctx context.Context, taskID int) (*taskdb.Task, error) {
+func (t *TaskService) GetTaskByID(ctx context.Context, userID int, orgID int, taskID int) (*taskdb.Task, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionRead); err != nil {
+		return nil, err
+	}
 	task, err := t.taskRepository.GetTaskById(ctx, int64(taskID))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, customErrors.ErrTaskNotFound
@@ -81,17 +207,23 @@ func (t *TaskService) GetTaskByID(ctx context.Context, taskID int) (*taskdb.Task
 
 }
 
-func (t *TaskService) GetTasksByProjectID(ctx context.Context, projectID int) ([]taskdb.Task, error) {
+func (t *TaskService) GetTasksByProjectID(ctx context.Context, userID int, orgID int, projectID int) ([]taskdb.Task, error) {
+	if err := t.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionRead); err != nil {
+		return nil, err
+	}
 	tasks, err := t.taskRepository.GetTasksByProjectId(ctx, int64(projectID))
-	if errors.Is(err,sql.ErrNoRows){
-		return nil,customErrors.ErrTasksAreEmpty
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrTasksAreEmpty
 	}
 	if err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
-func (t *TaskService) DeleteTask(ctx context.Context, taskID int) error {
+func (t *TaskService) DeleteTask(ctx context.Context, userID int, orgID int, taskID int) error {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionWrite); err != nil {
+		return err
+	}
 	rows, err := t.taskRepository.DeleteTask(ctx, int64(taskID))
 	if rows == 0 {
 		return customErrors.ErrTaskNotFound
@@ -104,9 +236,13 @@ func (t *TaskService) DeleteTask(ctx context.Context, taskID int) error {
 
 }
 
-func (t *TaskService) GetAllTasks(ctx context.Context) ([]taskdb.Task, error) {
+// GetAllTasks returns every task across the projects of the organization that userID can see.
+func (t *TaskService) GetAllTasks(ctx context.Context, userID int, orgID int) ([]taskdb.Task, error) {
 
-	tasks, err := t.taskRepository.GetAllTasks(ctx)
+	tasks, err := t.taskRepository.GetTasksByUserId(ctx, taskdb.GetTasksByUserIdParams{
+		UserID:         int32(userID),
+		OrganizationID: int64(orgID),
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +263,17 @@ func derefTime(t *time.Time) time.Time {
 	return *t
 }
 
-func (t *TaskService) UpdateTask(ctx context.Context, req UpdateTaskRequest) error {
+func (t *TaskService) UpdateTask(ctx context.Context, userID int, orgID int, req UpdateTaskRequest) error {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, int(req.ID), authz.ActionWrite); err != nil {
+		return err
+	}
+	previous, err := t.taskRepository.GetTaskById(ctx, req.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return customErrors.ErrTaskNotFound
+	}
+	if err != nil {
+		return err
+	}
 	updateParams := taskdb.UpdateTaskParams{
 		ID: req.ID,
 		Title: sql.NullString{
@@ -143,37 +289,124 @@ func (t *TaskService) UpdateTask(ctx context.Context, req UpdateTaskRequest) err
 			Valid: req.DueDate != nil,
 		},
 	}
-	if req.Priority!=nil{
-		updateParams.Priority=taskdb.NullTaskPriority{
+	if req.Priority != nil {
+		updateParams.Priority = taskdb.NullTaskPriority{
 			TaskPriority: getPriority(*req.Priority),
-			Valid: true,
+			Valid:        true,
 		}
-	}else{
-		updateParams.Priority=taskdb.NullTaskPriority{
+	} else {
+		updateParams.Priority = taskdb.NullTaskPriority{
 			Valid: false,
 		}
 	}
-	if req.Status!=nil{
-		updateParams.Status=taskdb.NullTaskStatus{
-			TaskStatus: getStatus(*req.Status),
-			Valid: true,
+	completed := false
+	if req.Status != nil {
+		status, workflow, err := t.workflowStatus(ctx, previous.ProjectID, *req.Status)
+		if err != nil {
+			return err
 		}
-	}else{
-		updateParams.Status=taskdb.NullTaskStatus{
-			Valid: false,
+		if err := checkTransition(workflow, previous.Status, status.Name); err != nil {
+			return err
+		}
+		if status.Category == taskdb.StatusCategoryDone && statusCategory(workflow, previous.Status) != taskdb.StatusCategoryDone {
+			if err := t.checkBlockers(ctx, previous.ID); err != nil {
+				return err
+			}
+			completed = true
+		}
+		updateParams.Status = sql.NullString{String: status.Name, Valid: true}
+	}
+	if req.AssigneeID != nil {
+		isMember, err := t.taskRepository.IsProjectMember(ctx, taskdb.IsProjectMemberParams{
+			ProjectID: previous.ProjectID,
+			UserID:    int32(*req.AssigneeID),
+		})
+		if err != nil {
+			return err
+		}
+		if !isMember {
+			return customErrors.ErrAssigneeNotProjectMember
+		}
+		updateParams.AssigneeID = sql.NullInt64{Int64: *req.AssigneeID, Valid: true}
+	}
+	if req.ParentTaskID != nil {
+		updateParams.SetParent = true
+		if *req.ParentTaskID != 0 {
+			if err := t.moveUnder(ctx, previous.ProjectID, previous.ID, *req.ParentTaskID); err != nil {
+				return err
+			}
+			updateParams.ParentTaskID = sql.NullInt64{Int64: *req.ParentTaskID, Valid: true}
+		}
+	}
+	if req.OriginalEstimateMinutes != nil {
+		if updateParams.OriginalEstimateMinutes, err = checkEstimate(*req.OriginalEstimateMinutes); err != nil {
+			return err
+		}
+		// a first estimate is also what remains
+		if !previous.RemainingEstimateMinutes.Valid {
+			updateParams.RemainingEstimateMinutes = updateParams.OriginalEstimateMinutes
 		}
 	}
-	err := t.taskRepository.UpdateTask(ctx, updateParams)
-	
+	if req.RemainingEstimateMinutes != nil {
+		if updateParams.RemainingEstimateMinutes, err = checkEstimate(*req.RemainingEstimateMinutes); err != nil {
+			return err
+		}
+	}
+	var labelIDs []int64
+	if req.LabelIDs != nil {
+		if labelIDs, err = t.checkLabels(ctx, previous.ProjectID, *req.LabelIDs); err != nil {
+			return err
+		}
+	}
+	customFields, err := t.checkCustomFields(ctx, previous.ProjectID, req.CustomFields)
+	if err != nil {
+		return err
+	}
+	var task taskdb.Task
+	err = t.inTx(ctx, func(ctx context.Context) error {
+		task, err = t.taskRepository.UpdateTask(ctx, updateParams)
+		if err != nil {
+			return err
+		}
+		if req.LabelIDs != nil {
+			if err := t.taskRepository.SetTaskLabels(ctx, taskdb.SetTaskLabelsParams{TaskID: task.ID, LabelIds: labelIDs, ProjectID: task.ProjectID}); err != nil {
+				return err
+			}
+		}
+		if customFields != nil {
+			customFields.TaskID = task.ID
+			if err := t.taskRepository.SetTaskCustomFieldValues(ctx, *customFields); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
+	t.publish(ctx, types.TaskEvent{Kind: types.TaskUpdated, ActorID: userID, TaskID: task.ID, Task: &task, Previous: &previous})
+	if completed {
+		// the update stands even when this fails
+		return t.completeOccurrence(ctx, userID, orgID, task)
+	}
 	return nil
 }
 
-func (t *TaskService) FilterTasks(ctx context.Context, req *TaskFilterRequest) ([]taskdb.Task, error) {
+// FilterTasks lists tasks matching the filters, limited to the projects of the organization that userID can see.
+func (t *TaskService) FilterTasks(ctx context.Context, userID int, orgID int, req *TaskFilterRequest) ([]taskdb.Task, error) {
+	limit, offset, err := req.Page.Bounds()
+	if err != nil {
+		return nil, err
+	}
 	var dbParams taskdb.ListTasksWithFiltersParams
+	dbParams.Limit = limit
+	dbParams.Offset = offset
+	dbParams.UserID = int32(userID)
+	dbParams.OrganizationID = int64(orgID)
 	if req.ProjectID != nil {
+		if err := t.authorizer.AuthorizeProject(ctx, userID, orgID, int(*req.ProjectID), authz.ActionRead); err != nil {
+			return nil, err
+		}
 		dbParams.ProjectID = sql.NullInt64{Int64: *req.ProjectID, Valid: true}
 	}
 	if req.AssigneeID != nil {
@@ -185,28 +418,21 @@ func (t *TaskService) FilterTasks(ctx context.Context, req *TaskFilterRequest) (
 			Valid:        true,
 		}
 	}
-	if len(req.Statuses) > 0 {
-		dbParams.Statuses = taskdb.NullTaskStatus{
-			TaskStatus: taskdb.TaskStatus(req.Statuses[0]), // TODO: update to handle slice properly
-			Valid:      true,
-		}
-	}
+	dbParams.Statuses = statusNames(req.Statuses)
 	if req.DueDateFrom != nil {
 		dbParams.DueDateFrom = sql.NullTime{Time: *req.DueDateFrom, Valid: true}
 	}
 	if req.DueDateTo != nil {
 		dbParams.DueDateTo = sql.NullTime{Time: *req.DueDateTo, Valid: true}
 	}
-
-	if req.Limit != nil {
-		dbParams.Limit = *req.Limit
-	} else {
-		dbParams.Limit = 20 // default
+	labels, allLabels, err := labelFilter(req)
+	if err != nil {
+		return nil, err
 	}
-	if req.Offset != nil {
-		dbParams.Offset = *req.Offset
-	} else {
-		dbParams.Offset = 0
+	dbParams.Labels = labels
+	dbParams.AllLabels = allLabels
+	if err := t.customFieldFilter(ctx, req, &dbParams); err != nil {
+		return nil, err
 	}
 
 	tasks, err := t.taskRepository.ListTasksWithFilters(ctx, dbParams)
@@ -9,8 +9,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	authzdb "github.com/Gkemhcs/taskpilot/internal/authz/gen"
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
 	"github.com/lib/pq"
 
 	"github.com/stretchr/testify/assert"
@@ -19,10 +22,15 @@ import (
 
 var taskService *TaskService
 var mockRepo *MockTaskRepo
+var mockAuthzRepo *authz.MockAuthzRepo
 
 func TestMain(m *testing.M) {
 	mockRepo = new(MockTaskRepo)
-	taskService = NewTaskService(mockRepo)
+	// every project and task is owned by user 1234 unless a test says otherwise
+	mockAuthzRepo = new(authz.MockAuthzRepo)
+	mockAuthzRepo.On("GetProjectRole", mock.Anything, mock.Anything).Return(authzdb.GetProjectRoleRow{UserID: 1234}, nil)
+	mockAuthzRepo.On("GetTaskProjectRole", mock.Anything, mock.Anything).Return(authzdb.GetTaskProjectRoleRow{UserID: 1234}, nil)
+	taskService = NewTaskService(mockRepo, authz.NewAuthorizationService(mockAuthzRepo))
 	os.Exit(m.Run())
 }
 
@@ -55,42 +63,6 @@ func TestDerefTime(t *testing.T) {
 	})
 }
 
-func TestGetStatus(t *testing.T) {
-	testCases := []struct {
-		testName       string
-		status         string
-		expectedOutput taskdb.TaskStatus
-	}{
-		{
-			testName:       "valid in_progress",
-			status:         "in_progress",
-			expectedOutput: taskdb.TaskStatusINPROGRESS,
-		},
-		{
-			testName:       "valid complete task",
-			status:         "done",
-			expectedOutput: taskdb.TaskStatusDONE,
-		},
-		{
-			testName:       "invalid task status",
-			status:         "working_fine",
-			expectedOutput: taskdb.TaskStatusTODO,
-		},
-		{
-			testName:       "invalid task status",
-			status:         "working_fine",
-			expectedOutput: taskdb.TaskStatusTODO,
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.testName, func(t *testing.T) {
-			output := getStatus(tc.status)
-			assert.Equal(t, output, tc.expectedOutput)
-		})
-	}
-}
-
 func TestGetPriority(t *testing.T) {
 	testCases := []struct {
 		testName       string
@@ -137,7 +109,7 @@ func TestCreateTask(t *testing.T) {
 		expectedOutput   *taskdb.Task
 		returnedError    error
 		isProjectValid   bool
-		expectedStatus   taskdb.TaskStatus
+		expectedStatus   string
 		expectedPriority taskdb.TaskPriority
 	}{{
 		testName: "valid task with high priority",
@@ -158,7 +130,7 @@ func TestCreateTask(t *testing.T) {
 				Valid: true,
 			},
 			Description: "valid task1",
-			Status:      taskdb.TaskStatusINPROGRESS,
+			Status:      "IN_PROGRESS",
 			Priority:    taskdb.TaskPriorityHIGH,
 			DueDate: sql.NullTime{
 				Time:  time.Date(2025, 7, 11, 14, 30, 0, 0, time.UTC),
@@ -176,7 +148,7 @@ func TestCreateTask(t *testing.T) {
 			},
 			Title:       "Valid task with high priority and todo status",
 			Description: "valid task1",
-			Status:      taskdb.TaskStatusINPROGRESS,
+			Status:      "IN_PROGRESS",
 			Priority:    taskdb.TaskPriorityHIGH,
 			DueDate: sql.NullTime{
 				Time:  time.Date(2025, 7, 11, 14, 30, 0, 0, time.UTC),
@@ -184,7 +156,7 @@ func TestCreateTask(t *testing.T) {
 			},
 		},
 		isProjectValid:   true,
-		expectedStatus:   taskdb.TaskStatusINPROGRESS,
+		expectedStatus:   "IN_PROGRESS",
 		expectedPriority: taskdb.TaskPriorityHIGH,
 	},
 		{
@@ -198,40 +170,41 @@ func TestCreateTask(t *testing.T) {
 				Priority:    "lower",
 				DueDate:     time.Date(2025, 7, 11, 14, 30, 0, 0, time.UTC),
 			},
+			expectedError:  customErrors.ErrUnknownStatus,
+			expectedOutput: &taskdb.Task{},
+		},
+		{
+			testName: "task without status starts in the first workflow status",
+			project: CreateTaskInput{
+				ProjectID:   1234,
+				Title:       "task without status",
+				AssigneeID:  102,
+				Description: "valid task 2",
+				Priority:    "lower",
+				DueDate:     time.Date(2025, 7, 11, 14, 30, 0, 0, time.UTC),
+			},
 			expectedParams: taskdb.CreateTaskParams{
 				ProjectID: 1234,
-				Title:     "task with wrong values for status and priority",
+				Title:     "task without status",
 				AssigneeID: sql.NullInt64{
 					Int64: 102,
 					Valid: true,
 				},
-				Description: "invalid task 1",
-				Status:      taskdb.TaskStatusTODO,
+				Description: "valid task 2",
+				Status:      "TODO",
 				Priority:    taskdb.TaskPriorityMEDIUM,
 				DueDate: sql.NullTime{
 					Time:  time.Date(2025, 7, 11, 14, 30, 0, 0, time.UTC),
 					Valid: true,
 				},
 			},
-			expectedError: nil,
-			returnedError: nil,
 			expectedOutput: &taskdb.Task{
 				ID:        1234,
 				ProjectID: 1234,
-				AssigneeID: sql.NullInt64{
-					Int64: 102,
-					Valid: true,
-				},
-				Title:       "task with wrong values for status and priority",
-				Description: "invalid task1",
-				Status:      taskdb.TaskStatusTODO,
-				Priority:    taskdb.TaskPriorityMEDIUM,
-				DueDate: sql.NullTime{
-					Time:  time.Date(2025, 7, 11, 14, 30, 0, 0, time.UTC),
-					Valid: true,
-				},
+				Status:    "TODO",
+				Priority:  taskdb.TaskPriorityMEDIUM,
 			},
-			expectedStatus:   taskdb.TaskStatusTODO,
+			expectedStatus:   "TODO",
 			expectedPriority: taskdb.TaskPriorityMEDIUM,
 		},
 		{
@@ -250,7 +223,7 @@ func TestCreateTask(t *testing.T) {
 				Title:       "Some duplicate task",
 				AssigneeID:  sql.NullInt64{Int64: 101, Valid: true},
 				Description: "already exists",
-				Status:      taskdb.TaskStatusTODO,
+				Status:      "TODO",
 				Priority:    taskdb.TaskPriorityMEDIUM,
 				DueDate:     sql.NullTime{Time: time.Date(2025, 7, 11, 14, 30, 0, 0, time.UTC), Valid: true},
 			},
@@ -264,13 +237,18 @@ func TestCreateTask(t *testing.T) {
 		t.Run(tc.testName, func(t *testing.T) {
 			mockRepo.Calls = nil
 			mockRepo.ExpectedCalls = nil
+			mockRepo.On("IsProjectMember", mock.Anything, mock.Anything).Return(true, nil)
+			mockRepo.On("ListWorkflowStatuses", mock.Anything, int64(1234)).Return(defaultWorkflow(1234), nil)
 			mockRepo.On("CreateTask", mock.Anything, tc.expectedParams).Return(*tc.expectedOutput, tc.returnedError)
 			ctx := context.TODO()
-			result, err := taskService.CreateTask(ctx, tc.project)
+			result, err := taskService.CreateTask(ctx, 1234, testOrgID, tc.project)
 
 			if tc.expectedError != nil {
-				assert.Equal(t, tc.expectedError, err)
-
+				assert.ErrorIs(t, err, tc.expectedError)
+				if tc.returnedError == nil {
+					mockRepo.AssertNotCalled(t, "CreateTask", mock.Anything, mock.Anything)
+					return
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, result.ProjectID, tc.expectedOutput.ProjectID)
@@ -337,7 +315,7 @@ func TestGetTaskByID(t *testing.T) {
 			mockRepo.On("GetTaskById", mock.Anything, tc.expectedTaskID).Return(tc.returnOutput, tc.returnError)
 
 			ctx := context.TODO()
-			result, err := taskService.GetTaskByID(ctx, tc.taskID)
+			result, err := taskService.GetTaskByID(ctx, 1234, testOrgID, tc.taskID)
 			if tc.returnError != nil {
 				assert.Equal(t, err, tc.expectedError)
 			} else {
@@ -407,7 +385,7 @@ func TestGetTasksByProjectId(t *testing.T) {
 			mockRepo.ExpectedCalls = nil
 			mockRepo.On("GetTasksByProjectId", mock.Anything, tc.expectedProjectID).Return(tc.returnResult, tc.returnError)
 
-			result, err := taskService.GetTasksByProjectID(context.TODO(), tc.projectID)
+			result, err := taskService.GetTasksByProjectID(context.TODO(), 1234, testOrgID, tc.projectID)
 			if tc.expectedError != nil {
 				assert.Equal(t, err, tc.expectedError)
 			} else {
@@ -452,7 +430,7 @@ func TestDeleteTask(t *testing.T) {
 			mockRepo.Calls = nil
 			mockRepo.ExpectedCalls = nil
 			mockRepo.On("DeleteTask", mock.Anything, tc.expectedTaskID).Return(tc.returnResult, tc.returnError)
-			err := taskService.DeleteTask(context.TODO(), tc.taskID)
+			err := taskService.DeleteTask(context.TODO(), 1234, testOrgID, tc.taskID)
 			if tc.expectedErr != nil {
 				assert.Equal(t, tc.expectedErr, err)
 			} else {
@@ -464,30 +442,29 @@ func TestDeleteTask(t *testing.T) {
 
 }
 
-
-func TestUpdateTask(t *testing.T){
-	testCases:=[]struct{
-		testName string 
+func TestUpdateTask(t *testing.T) {
+	testCases := []struct {
+		testName          string
 		updateTaskRequest UpdateTaskRequest
-		expectedParams taskdb.UpdateTaskParams
-		expectedError error
+		expectedParams    taskdb.UpdateTaskParams
+		expectedError     error
 	}{
 		{
 			testName: "valid update of task",
 			updateTaskRequest: UpdateTaskRequest{
-				ID:1234,
-				Title: func(s string) *string { return &s }("iojojo"),
-				Description: func (s string)*string{return &s}("this is valid description"),
+				ID:          1234,
+				Title:       func(s string) *string { return &s }("iojojo"),
+				Description: func(s string) *string { return &s }("this is valid description"),
 			},
 			expectedParams: taskdb.UpdateTaskParams{
 				ID: 1234,
 				Title: sql.NullString{
 					String: "iojojo",
-					Valid: true ,
+					Valid:  true,
 				},
 				Description: sql.NullString{
 					String: "this is valid description",
-					Valid: true,
+					Valid:  true,
 				},
 			},
 			expectedError: nil,
@@ -495,51 +472,162 @@ func TestUpdateTask(t *testing.T){
 		{
 			testName: "invalid update request as the task with same title already exists in the project",
 			updateTaskRequest: UpdateTaskRequest{
-				ID:1234,
+				ID:    1234,
 				Title: func(s string) *string { return &s }("iojojo"),
 			},
 			expectedParams: taskdb.UpdateTaskParams{
 				ID: 1234,
 				Title: sql.NullString{
 					String: "iojojo",
-					Valid: true ,
+					Valid:  true,
 				},
-			
 			},
 			expectedError: customErrors.ErrTaskAlreadyExists,
 		},
 		{
 			testName: "db error",
 			updateTaskRequest: UpdateTaskRequest{
-				ID:1234,
+				ID:    1234,
 				Title: func(s string) *string { return &s }("title1"),
 			},
 			expectedParams: taskdb.UpdateTaskParams{
 				ID: 1234,
 				Title: sql.NullString{
 					String: "title1",
-					Valid: true ,
+					Valid:  true,
 				},
-				
 			},
 			expectedError: errors.New("db is unable to fetch"),
 		},
 	}
 
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			mockRepo.On("GetTaskById", mock.Anything, int64(1234)).Return(taskdb.Task{ID: 1234, ProjectID: 5}, nil)
+			mockRepo.On("UpdateTask", mock.Anything, tc.expectedParams).Return(taskdb.Task{ID: 1234, ProjectID: 5}, tc.expectedError)
 
-	for _ , tc := range testCases{
-		t.Run(tc.testName,func(t *testing.T){
-			mockRepo.ExpectedCalls=nil 
-			mockRepo.Calls=nil 
-			mockRepo.On("UpdateTask",mock.Anything,tc.expectedParams).Return(tc.expectedError)
+			err := taskService.UpdateTask(context.TODO(), 1234, testOrgID, tc.updateTaskRequest)
+			assert.Equal(t, err, tc.expectedError)
 
+			mockRepo.AssertCalled(t, "UpdateTask", mock.Anything, tc.expectedParams)
 
-			err:=taskService.UpdateTask(context.TODO(),tc.updateTaskRequest)
-			assert.Equal(t,err,tc.expectedError)
+		})
+	}
+}
+
+func TestTaskAccess(t *testing.T) {
+	authzRepo := new(authz.MockAuthzRepo)
+	repo := new(MockTaskRepo)
+	service := NewTaskService(repo, authz.NewAuthorizationService(authzRepo))
+
+	authzRepo.On("GetTaskProjectRole", mock.Anything, authzdb.GetTaskProjectRoleParams{ID: 11, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetTaskProjectRoleRow{ProjectID: 5, UserID: 99}, nil)
+	authzRepo.On("GetTaskProjectRole", mock.Anything, authzdb.GetTaskProjectRoleParams{ID: 12, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetTaskProjectRoleRow{}, sql.ErrNoRows)
+	authzRepo.On("GetTaskProjectRole", mock.Anything, authzdb.GetTaskProjectRoleParams{ID: 13, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetTaskProjectRoleRow{ProjectID: 5, UserID: 99, Role: authzdb.NullProjectRole{ProjectRole: authzdb.ProjectRoleVIEWER, Valid: true}}, nil)
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 5, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 5, UserID: 99}, nil)
+	authzRepo.On("GetProjectRole", mock.Anything, authzdb.GetProjectRoleParams{ID: 6, UserID: 1234, OrganizationID: testOrgID}).Return(authzdb.GetProjectRoleRow{ID: 6, UserID: 99, Role: authzdb.NullProjectRole{ProjectRole: authzdb.ProjectRoleEDITOR, Valid: true}}, nil)
+	repo.On("IsProjectMember", mock.Anything, taskdb.IsProjectMemberParams{ProjectID: 6, UserID: 777}).Return(false, nil)
+
+	t.Run("task in another user's project is forbidden", func(t *testing.T) {
+		_, err := service.GetTaskByID(context.TODO(), 1234, testOrgID, 11)
+		assert.Equal(t, customErrors.ErrForbidden, err)
+		err = service.DeleteTask(context.TODO(), 1234, testOrgID, 11)
+		assert.Equal(t, customErrors.ErrForbidden, err)
+	})
+
+	t.Run("missing task is not found", func(t *testing.T) {
+		_, err := service.GetTaskByID(context.TODO(), 1234, testOrgID, 12)
+		assert.Equal(t, customErrors.ErrTaskNotFound, err)
+	})
 
-			mockRepo.AssertCalled(t,"UpdateTask",mock.Anything,tc.expectedParams)
+	t.Run("creating a task in another user's project is forbidden", func(t *testing.T) {
+		_, err := service.CreateTask(context.TODO(), 1234, testOrgID, CreateTaskInput{ProjectID: 5, Title: "sneaky"})
+		assert.Equal(t, customErrors.ErrForbidden, err)
+	})
 
+	t.Run("viewer can read a shared task but not delete it", func(t *testing.T) {
+		repo.On("GetTaskById", mock.Anything, int64(13)).Return(taskdb.Task{ID: 13}, nil).Once()
+		_, err := service.GetTaskByID(context.TODO(), 1234, testOrgID, 13)
+		assert.NoError(t, err)
+		err = service.DeleteTask(context.TODO(), 1234, testOrgID, 13)
+		assert.Equal(t, customErrors.ErrForbidden, err)
+	})
 
+	t.Run("editor cannot assign a task to a non member", func(t *testing.T) {
+		_, err := service.CreateTask(context.TODO(), 1234, testOrgID, CreateTaskInput{ProjectID: 6, Title: "shared", AssigneeID: 777})
+		assert.Equal(t, customErrors.ErrAssigneeNotProjectMember, err)
+	})
+
+	repo.AssertNotCalled(t, "GetTaskById", mock.Anything, int64(11))
+	repo.AssertNotCalled(t, "DeleteTask", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "CreateTask", mock.Anything, mock.Anything)
+}
+
+// eventRecorder is a types.TaskEventSubscriber that keeps every event.
+type eventRecorder []types.TaskEvent
+
+func (e *eventRecorder) HandleTaskEvent(_ context.Context, event types.TaskEvent) {
+	*e = append(*e, event)
+}
+
+func TestUpdateTaskAssignee(t *testing.T) {
+	repo := new(MockTaskRepo)
+	service := NewTaskService(repo, authz.NewAuthorizationService(mockAuthzRepo))
+	events := new(eventRecorder)
+	service.Subscribe(events)
+
+	previous := taskdb.Task{ID: 30, ProjectID: 5, AssigneeID: sql.NullInt64{Int64: 2, Valid: true}}
+	repo.On("GetTaskById", mock.Anything, int64(30)).Return(previous, nil)
+	repo.On("IsProjectMember", mock.Anything, taskdb.IsProjectMemberParams{ProjectID: 5, UserID: 3}).Return(true, nil)
+	repo.On("IsProjectMember", mock.Anything, mock.Anything).Return(false, nil)
+	updated := taskdb.Task{ID: 30, ProjectID: 5, AssigneeID: sql.NullInt64{Int64: 3, Valid: true}}
+	repo.On("UpdateTask", mock.Anything, taskdb.UpdateTaskParams{ID: 30, AssigneeID: sql.NullInt64{Int64: 3, Valid: true}}).Return(updated, nil)
+
+	t.Run("reassign to a project member", func(t *testing.T) {
+		assigneeID := int64(3)
+		err := service.UpdateTask(context.TODO(), 1234, testOrgID, UpdateTaskRequest{ID: 30, AssigneeID: &assigneeID})
+		assert.NoError(t, err)
+		assert.Equal(t, eventRecorder{{Kind: types.TaskUpdated, ActorID: 1234, TaskID: 30, Task: &updated, Previous: &previous}}, *events)
+	})
+
+	t.Run("reassign outside the project", func(t *testing.T) {
+		assigneeID := int64(777)
+		err := service.UpdateTask(context.TODO(), 1234, testOrgID, UpdateTaskRequest{ID: 30, AssigneeID: &assigneeID})
+		assert.Equal(t, customErrors.ErrAssigneeNotProjectMember, err)
+		assert.Len(t, *events, 1)
+	})
+}
+
+func TestFilterTasksPagination(t *testing.T) {
+	limit, tooMany := int32(50), int32(types.MaxPageSize+1)
+
+	testCases := []struct {
+		name           string
+		page           types.Page
+		expectedLimit  int32
+		expectedOffset int32
+		expectedError  error
+	}{
+		{name: "default page", expectedLimit: types.DefaultPageSize},
+		{name: "given page", page: types.Page{Limit: &limit, Offset: &limit}, expectedLimit: 50, expectedOffset: 50},
+		{name: "limit too large", page: types.Page{Limit: &tooMany}, expectedError: customErrors.ErrInvalidPagination},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newCommentService()
+			repo.On("ListTasksWithFilters", mock.Anything, mock.Anything).Return([]taskdb.Task{}, nil)
+
+			_, err := service.FilterTasks(context.TODO(), 1234, testOrgID, &TaskFilterRequest{Page: tc.page})
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedError != nil {
+				repo.AssertNotCalled(t, "ListTasksWithFilters", mock.Anything, mock.Anything)
+				return
+			}
+			params := repo.Calls[len(repo.Calls)-1].Arguments.Get(1).(taskdb.ListTasksWithFiltersParams)
+			assert.Equal(t, tc.expectedLimit, params.Limit)
+			assert.Equal(t, tc.expectedOffset, params.Offset)
 		})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,85 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ListSubtasks lists the direct subtasks of a task
+// @Summary      List subtasks
+// @Description  Lists the direct subtasks of a task, oldest first, each with its own comment count and subtask roll-up
+// @Tags         tasks
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/subtasks [get]
+// @Security BearerAuth
+func (t *TaskHandler) ListSubtasks(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	subtasks, err := t.taskService.ListSubtasks(ctx, userID, orgID, taskID)
+	if err != nil {
+		t.logger.Errorf("unable to list the subtasks of task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	responses, err := t.taskService.TaskResponses(ctx, subtasks)
+	if err != nil {
+		t.logger.Errorf("unable to summarise the subtasks of task %d %v", taskID, err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, responses)
+}
+
+// CreateSubtask creates a task under another task
+// @Summary      Create a subtask
+// @Description  Creates a task as a subtask of the task in the path. project_id may be left out and defaults to the parent's project; subtasks cannot belong to another project.
+// @Tags         tasks
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                true  "Parent task ID"
+// @Param        task  body      CreateTaskRequest  true  "Subtask"
+// @Success      201   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      403   {object}  map[string]interface{}
+// @Failure      404   {object}  map[string]interface{}
+// @Router       /api/v1/tasks/{id}/subtasks [post]
+// @Security BearerAuth
+func (t *TaskHandler) CreateSubtask(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	var req CreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		t.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	parent, err := t.taskService.GetTaskByID(ctx, userID, orgID, taskID)
+	if err != nil {
+		t.logger.Errorf("%v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	if req.ProjectID == 0 {
+		req.ProjectID = int(parent.ProjectID)
+	}
+	req.ParentTaskID = &parent.ID
+	t.createTask(c, req)
+}
@@ -0,0 +1,192 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+// ListSubtasks returns the direct subtasks of a task, oldest first.
+func (t *TaskService) ListSubtasks(ctx context.Context, userID int, orgID int, taskID int) ([]taskdb.Task, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	subtasks, err := t.taskRepository.ListSubtasks(ctx, sql.NullInt64{Int64: int64(taskID), Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	if subtasks == nil {
+		subtasks = []taskdb.Task{}
+	}
+	return subtasks, nil
+}
+
+// FindTaskByTitle returns the task of the project with the given title. Imports
+// use it to resolve the parent_title column.
+func (t *TaskService) FindTaskByTitle(ctx context.Context, userID int, orgID int, projectID int, title string) (*taskdb.Task, error) {
+	if err := t.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	task, err := t.taskRepository.GetTaskByTitle(ctx, taskdb.GetTaskByTitleParams{ProjectID: int64(projectID), Title: title})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// TaskResponses attaches the number of comments, replies included, the
+// roll-up of the direct subtasks, the blocked flag, the labels, the custom
+// field values and the time spent to every task. Callers must have checked
+// that the tasks may be read.
+func (t *TaskService) TaskResponses(ctx context.Context, tasks []taskdb.Task) ([]types.TaskResponse, error) {
+	responses := make([]types.TaskResponse, len(tasks))
+	if len(tasks) == 0 {
+		return responses, nil
+	}
+	ids := make([]int64, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	counts, err := t.taskRepository.CountTaskComments(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	subtasks, err := t.taskRepository.CountSubtasks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	blockedIDs, err := t.taskRepository.ListBlockedTaskIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	taskLabels, err := t.taskRepository.ListTaskLabels(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	values, err := t.taskRepository.ListTaskCustomFieldValues(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	timeSpent, err := t.taskRepository.SumTaskTimeSpent(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	customFields := make(map[int64]map[int64]json.RawMessage, len(tasks))
+	for _, value := range values {
+		if customFields[value.TaskID] == nil {
+			customFields[value.TaskID] = make(map[int64]json.RawMessage)
+		}
+		customFields[value.TaskID][value.FieldID] = value.Value
+	}
+	labels := make(map[int64][]taskdb.Label, len(tasks))
+	for _, label := range taskLabels {
+		labels[label.TaskID] = append(labels[label.TaskID], taskdb.Label{
+			ID:        label.ID,
+			ProjectID: label.ProjectID,
+			Name:      label.Name,
+			Color:     label.Color,
+			CreatedAt: label.CreatedAt,
+			UpdatedAt: label.UpdatedAt,
+		})
+	}
+	comments := make(map[int64]int64, len(counts))
+	for _, count := range counts {
+		comments[count.TaskID] = count.CommentCount
+	}
+	rollups := make(map[int64]types.SubtaskRollup, len(subtasks))
+	for _, count := range subtasks {
+		rollups[count.ParentTaskID] = types.SubtaskRollup{
+			Total:   count.Total,
+			Done:    count.Done,
+			Percent: count.Done * 100 / count.Total,
+		}
+	}
+	minutes := make(map[int64]int64, len(timeSpent))
+	for _, spent := range timeSpent {
+		minutes[spent.TaskID] = spent.Minutes
+	}
+	for i, task := range tasks {
+		responses[i] = types.TaskResponse{
+			Task:             task,
+			CommentCount:     comments[task.ID],
+			Subtasks:         rollups[task.ID],
+			Blocked:          slices.Contains(blockedIDs, task.ID),
+			Labels:           labels[task.ID],
+			CustomFields:     customFields[task.ID],
+			TimeSpentMinutes: minutes[task.ID],
+		}
+		if responses[i].Labels == nil {
+			responses[i].Labels = []taskdb.Label{}
+		}
+		if responses[i].CustomFields == nil {
+			responses[i].CustomFields = map[int64]json.RawMessage{}
+		}
+	}
+	return responses, nil
+}
+
+// SortParentsFirst orders tasks so every parent comes before its subtasks,
+// keeping the original order otherwise. Exports use it so that importing the
+// file again can resolve each parent_title to a task created earlier.
+func SortParentsFirst(tasks []taskdb.Task) []taskdb.Task {
+	children := make(map[int64][]taskdb.Task)
+	inSet := make(map[int64]bool, len(tasks))
+	for _, task := range tasks {
+		inSet[task.ID] = true
+	}
+	var sorted []taskdb.Task
+	for _, task := range tasks {
+		if task.ParentTaskID.Valid && inSet[task.ParentTaskID.Int64] {
+			children[task.ParentTaskID.Int64] = append(children[task.ParentTaskID.Int64], task)
+		} else {
+			sorted = append(sorted, task)
+		}
+	}
+	for i := 0; i < len(sorted); i++ {
+		sorted = append(sorted, children[sorted[i].ID]...)
+	}
+	return sorted
+}
+
+// parentTask loads the task a new or moved task goes under and makes sure it
+// belongs to the same project.
+func (t *TaskService) parentTask(ctx context.Context, projectID int64, parentID int64) (*taskdb.Task, error) {
+	parent, err := t.taskRepository.GetTaskById(ctx, parentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrParentTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if parent.ProjectID != projectID {
+		return nil, customErrors.ErrCrossProjectParent
+	}
+	return &parent, nil
+}
+
+// moveUnder checks that taskID can be moved under parentID: the parent exists
+// in the same project and is neither the task itself nor one of its subtasks.
+func (t *TaskService) moveUnder(ctx context.Context, projectID int64, taskID int64, parentID int64) error {
+	parent, err := t.parentTask(ctx, projectID, parentID)
+	if err != nil {
+		return err
+	}
+	ancestors, err := t.taskRepository.ListTaskAncestorIDs(ctx, parent.ID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(ancestors, taskID) {
+		return customErrors.ErrTaskHierarchyCycle
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func subtask(id int64, projectID int64, parentID int64) taskdb.Task {
+	return taskdb.Task{
+		ID:           id,
+		ProjectID:    projectID,
+		Status:       "TODO",
+		ParentTaskID: sql.NullInt64{Int64: parentID, Valid: parentID != 0},
+	}
+}
+
+func TestTaskResponses(t *testing.T) {
+	service, repo := newCommentService()
+	repo.On("CountTaskComments", mock.Anything, []int64{1, 2}).Return([]taskdb.CountTaskCommentsRow{{TaskID: 2, CommentCount: 5}}, nil)
+	repo.On("CountSubtasks", mock.Anything, []int64{1, 2}).Return([]taskdb.CountSubtasksRow{{ParentTaskID: 1, Total: 3, Done: 1}}, nil)
+	repo.On("ListBlockedTaskIDs", mock.Anything, []int64{1, 2}).Return([]int64{2}, nil)
+	repo.On("ListTaskLabels", mock.Anything, []int64{1, 2}).Return([]taskdb.ListTaskLabelsRow{{TaskID: 1, ID: 7, ProjectID: 6, Name: "bug", Color: "#d73a4a"}}, nil)
+	repo.On("ListTaskCustomFieldValues", mock.Anything, []int64{1, 2}).Return([]taskdb.TaskCustomFieldValue{{TaskID: 2, FieldID: 3, ProjectID: 6, Value: json.RawMessage(`5`)}}, nil)
+	repo.On("SumTaskTimeSpent", mock.Anything, []int64{1, 2}).Return([]taskdb.SumTaskTimeSpentRow{{TaskID: 1, Minutes: 90}}, nil)
+
+	responses, err := service.TaskResponses(context.TODO(), []taskdb.Task{{ID: 1}, {ID: 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.TaskResponse{
+		{Task: taskdb.Task{ID: 1}, Subtasks: types.SubtaskRollup{Total: 3, Done: 1, Percent: 33}, Labels: []taskdb.Label{{ID: 7, ProjectID: 6, Name: "bug", Color: "#d73a4a"}}, CustomFields: map[int64]json.RawMessage{}, TimeSpentMinutes: 90},
+		{Task: taskdb.Task{ID: 2}, CommentCount: 5, Blocked: true, Labels: []taskdb.Label{}, CustomFields: map[int64]json.RawMessage{3: json.RawMessage(`5`)}},
+	}, responses)
+
+	responses, err = service.TaskResponses(context.TODO(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, responses)
+}
+
+func TestListSubtasks(t *testing.T) {
+	service, repo := newCommentService()
+	repo.On("ListSubtasks", mock.Anything, sql.NullInt64{Int64: 21, Valid: true}).Return([]taskdb.Task(nil), nil)
+
+	subtasks, err := service.ListSubtasks(context.TODO(), 1234, testOrgID, 21)
+	assert.NoError(t, err)
+	assert.NotNil(t, subtasks)
+}
+
+func TestMoveTaskUnderParent(t *testing.T) {
+	// task 22 of project 6 has the subtask 30, which has the subtask 31
+	service, repo := newCommentService()
+	repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+	repo.On("GetTaskById", mock.Anything, int64(23)).Return(subtask(23, 6, 0), nil)
+	repo.On("GetTaskById", mock.Anything, int64(31)).Return(subtask(31, 6, 30), nil)
+	repo.On("GetTaskById", mock.Anything, int64(40)).Return(subtask(40, 7, 0), nil)
+	repo.On("GetTaskById", mock.Anything, mock.Anything).Return(taskdb.Task{}, sql.ErrNoRows)
+	repo.On("ListTaskAncestorIDs", mock.Anything, int64(22)).Return([]int64{22}, nil)
+	repo.On("ListTaskAncestorIDs", mock.Anything, int64(23)).Return([]int64{23}, nil)
+	repo.On("ListTaskAncestorIDs", mock.Anything, int64(31)).Return([]int64{31, 30, 22}, nil)
+	repo.On("UpdateTask", mock.Anything, mock.Anything).Return(subtask(22, 6, 23), nil)
+
+	id := func(id int64) *int64 { return &id }
+	testCases := []struct {
+		name           string
+		parentID       *int64
+		expectedParams *taskdb.UpdateTaskParams
+		expectedError  error
+	}{
+		{
+			name:           "move under a task of the same project",
+			parentID:       id(23),
+			expectedParams: &taskdb.UpdateTaskParams{ID: 22, SetParent: true, ParentTaskID: sql.NullInt64{Int64: 23, Valid: true}},
+		},
+		{
+			name:           "detach from the parent",
+			parentID:       id(0),
+			expectedParams: &taskdb.UpdateTaskParams{ID: 22, SetParent: true},
+		},
+		{name: "move under itself", parentID: id(22), expectedError: customErrors.ErrTaskHierarchyCycle},
+		{name: "move under its own subtask", parentID: id(31), expectedError: customErrors.ErrTaskHierarchyCycle},
+		{name: "move under a task of another project", parentID: id(40), expectedError: customErrors.ErrCrossProjectParent},
+		{name: "missing parent", parentID: id(50), expectedError: customErrors.ErrParentTaskNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo.Calls = nil
+			err := service.UpdateTask(context.TODO(), 1234, testOrgID, UpdateTaskRequest{ID: 22, ParentTaskID: tc.parentID})
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedParams != nil {
+				repo.AssertCalled(t, "UpdateTask", mock.Anything, *tc.expectedParams)
+			} else {
+				repo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSortParentsFirst(t *testing.T) {
+	tasks := []taskdb.Task{subtask(3, 1, 2), subtask(2, 1, 1), subtask(4, 1, 9), subtask(1, 1, 0)}
+
+	var ids []int64
+	for _, task := range SortParentsFirst(tasks) {
+		ids = append(ids, task.ID)
+	}
+	// task 4's parent is not exported, so it is treated as a top-level task
+	assert.Equal(t, []int64{4, 1, 2, 3}, ids)
+}
@@ -1,8 +1,8 @@
 
 -- name: CreateTask :one
 
-INSERT INTO tasks (project_id, assignee_id, title, description, status, priority, due_date)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO tasks (project_id, assignee_id, title, description, status, priority, due_date, parent_task_id, series_id, original_estimate_minutes, remaining_estimate_minutes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING *;
 
 -- name: GetTaskById :one
@@ -17,13 +17,31 @@ SELECT * FROM tasks WHERE project_id = $1 ORDER BY id;
 -- name: GetAllTasks :many
 SELECT * FROM tasks ORDER BY id;
 
+-- name: GetTasksByUserId :many
+SELECT t.* FROM tasks t
+JOIN projects p ON p.id = t.project_id
+WHERE p.organization_id = $2
+  AND (p.user_id = $1
+   OR p.id IN (SELECT pm.project_id FROM project_members pm WHERE pm.user_id = $1))
+ORDER BY t.id;
+
+-- name: IsProjectMember :one
+SELECT EXISTS (
+  SELECT 1 FROM project_members WHERE project_id = $1 AND user_id = $2
+);
+
+-- name: IsOrganizationMember :one
+SELECT EXISTS (
+  SELECT 1 FROM organization_members WHERE organization_id = $1 AND user_id = $2
+);
+
 -- name: DeleteTask :execrows
 DELETE FROM tasks WHERE id = $1;
 
 
 
 
--- name: UpdateTask :exec
+-- name: UpdateTask :one
 
 UPDATE tasks
 SET
@@ -32,8 +50,13 @@ SET
   due_date = COALESCE(sqlc.narg('due_date'), due_date),
   status = COALESCE(sqlc.narg('status'), status),
   priority = COALESCE(sqlc.narg('priority'), priority),
+  assignee_id = COALESCE(sqlc.narg('assignee_id'), assignee_id),
+  parent_task_id = CASE WHEN sqlc.arg('set_parent')::boolean THEN sqlc.narg('parent_task_id') ELSE parent_task_id END,
+  original_estimate_minutes = COALESCE(sqlc.narg('original_estimate_minutes'), original_estimate_minutes),
+  remaining_estimate_minutes = COALESCE(sqlc.narg('remaining_estimate_minutes'), remaining_estimate_minutes),
   updated_at = now()
-WHERE id = sqlc.arg('id');
+WHERE id = sqlc.arg('id')
+RETURNING *;
 
 
 
@@ -45,11 +68,41 @@ FROM tasks
 WHERE 
     (project_id = COALESCE(sqlc.narg('project_id'), project_id))
   AND (assignee_id = COALESCE(sqlc.narg('assignee_id'), assignee_id))
-  AND (status = ANY(sqlc.narg('statuses')))
+  AND (cardinality(sqlc.arg('statuses')::text[]) = 0 OR status = ANY(sqlc.arg('statuses')::text[]))
   AND (priority = COALESCE(sqlc.narg('priority'), priority))
   AND (due_date >= COALESCE(sqlc.narg('due_date_from'), due_date))
   AND (due_date <= COALESCE(sqlc.narg('due_date_to'), due_date))
-ORDER BY due_date
+  AND project_id IN (
+    SELECT id FROM projects WHERE user_id = sqlc.arg('user_id')
+    UNION
+    SELECT pm.project_id FROM project_members pm WHERE pm.user_id = sqlc.arg('user_id')
+  )
+  AND project_id IN (SELECT id FROM projects WHERE organization_id = sqlc.arg('organization_id'))
+  -- labels are lower-cased names; a task needs one of them, or all of them
+  -- when all_labels is set
+  AND (cardinality(sqlc.arg('labels')::text[]) = 0 OR (
+    SELECT COUNT(DISTINCT lower(l.name)) FROM task_labels tl
+    JOIN labels l ON l.id = tl.label_id
+    WHERE tl.task_id = tasks.id AND lower(l.name) = ANY(sqlc.arg('labels')::text[])
+  ) >= CASE WHEN sqlc.arg('all_labels')::boolean THEN cardinality(sqlc.arg('labels')::text[]) ELSE 1 END)
+  -- every custom field filter must match: field_values holds the JSON of the
+  -- wanted value, which matches equal values and multi-select values containing it
+  AND NOT EXISTS (
+    SELECT 1 FROM unnest(sqlc.arg('field_ids')::bigint[], sqlc.arg('field_values')::text[]) AS f(field_id, value)
+    WHERE NOT EXISTS (
+      SELECT 1 FROM task_custom_field_values v
+      WHERE v.task_id = tasks.id AND v.field_id = f.field_id AND v.value @> f.value::jsonb
+    )
+  )
+-- sort_field_id orders by the value of a custom field, tasks without a value last
+ORDER BY
+  CASE WHEN NOT sqlc.arg('sort_desc')::boolean THEN (
+    SELECT v.value FROM task_custom_field_values v WHERE v.task_id = tasks.id AND v.field_id = sqlc.narg('sort_field_id')
+  ) END ASC NULLS LAST,
+  CASE WHEN sqlc.arg('sort_desc')::boolean THEN (
+    SELECT v.value FROM task_custom_field_values v WHERE v.task_id = tasks.id AND v.field_id = sqlc.narg('sort_field_id')
+  ) END DESC NULLS LAST,
+  due_date
 LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 
@@ -59,3 +112,332 @@ LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 
 
+-- name: CountTaskComments :many
+SELECT task_id, COUNT(*) AS comment_count FROM task_comments
+WHERE task_id = ANY(sqlc.arg('task_ids')::bigint[])
+GROUP BY task_id;
+
+-- name: CreateTaskComment :one
+INSERT INTO task_comments (task_id, parent_id, author_id, body)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetTaskComment :one
+SELECT * FROM task_comments WHERE id = $1 AND task_id = $2;
+
+-- name: ListTaskComments :many
+-- top-level comments only, their replies come from ListTaskCommentReplies
+SELECT * FROM task_comments
+WHERE task_id = $1 AND parent_id IS NULL
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: ListTaskCommentReplies :many
+SELECT * FROM task_comments
+WHERE parent_id = ANY(sqlc.arg('parent_ids')::bigint[])
+ORDER BY id;
+
+-- name: UpdateTaskCommentBody :one
+-- the replaced body is kept in task_comment_edits by the same statement
+WITH previous AS (
+  INSERT INTO task_comment_edits (comment_id, body, edited_by)
+  SELECT c.id, c.body, sqlc.arg('edited_by')::int FROM task_comments c WHERE c.id = sqlc.arg('id')
+)
+UPDATE task_comments
+SET body = sqlc.arg('body'), edited_at = now(), updated_at = now()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ListTaskCommentEdits :many
+SELECT * FROM task_comment_edits WHERE comment_id = $1 ORDER BY id;
+
+-- name: DeleteTaskComment :execrows
+DELETE FROM task_comments WHERE id = $1;
+
+-- name: GetTaskByTitle :one
+SELECT * FROM tasks WHERE project_id = $1 AND title = $2;
+
+-- name: ListSubtasks :many
+SELECT * FROM tasks WHERE parent_task_id = $1 ORDER BY id;
+
+-- name: CountSubtasks :many
+-- subtasks count as done when their status is in the done category
+SELECT t.parent_task_id::bigint AS parent_task_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE ws.category = 'done') AS done
+FROM tasks t
+JOIN workflow_statuses ws ON ws.project_id = t.project_id AND ws.name = t.status
+WHERE t.parent_task_id = ANY(sqlc.arg('task_ids')::bigint[])
+GROUP BY t.parent_task_id;
+
+-- name: ListTaskAncestorIDs :many
+-- the task itself followed by its parent, grandparent and so on; UNION stops
+-- on a cycle
+WITH RECURSIVE ancestors AS (
+  SELECT id, parent_task_id FROM tasks WHERE id = $1
+  UNION
+  SELECT t.id, t.parent_task_id FROM tasks t JOIN ancestors a ON t.id = a.parent_task_id
+)
+SELECT id FROM ancestors;
+
+-- name: CreateTaskDependency :one
+INSERT INTO task_dependencies (task_id, blocked_by_id, project_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: DeleteTaskDependency :execrows
+DELETE FROM task_dependencies WHERE task_id = $1 AND blocked_by_id = $2;
+
+-- name: ListTaskBlockers :many
+SELECT t.* FROM tasks t
+JOIN task_dependencies d ON d.blocked_by_id = t.id
+WHERE d.task_id = $1
+ORDER BY t.id;
+
+-- name: ListBlockedTasks :many
+SELECT t.* FROM tasks t
+JOIN task_dependencies d ON d.task_id = t.id
+WHERE d.blocked_by_id = $1
+ORDER BY t.id;
+
+-- name: ListProjectDependencies :many
+SELECT * FROM task_dependencies WHERE project_id = $1 ORDER BY task_id, blocked_by_id;
+
+-- name: ListTransitiveBlockerIDs :many
+-- the task itself followed by every task it waits for, directly or through
+-- other tasks; UNION stops on a cycle
+WITH RECURSIVE blockers AS (
+  SELECT sqlc.arg('id')::bigint AS id
+  UNION
+  SELECT d.blocked_by_id FROM task_dependencies d JOIN blockers b ON d.task_id = b.id
+)
+SELECT id FROM blockers;
+
+-- name: CountOpenBlockers :one
+SELECT COUNT(*) FROM task_dependencies d
+JOIN tasks t ON t.id = d.blocked_by_id
+JOIN workflow_statuses ws ON ws.project_id = t.project_id AND ws.name = t.status
+WHERE d.task_id = $1 AND ws.category <> 'done';
+
+-- name: ListBlockedTaskIDs :many
+-- the tasks among task_ids with at least one blocker that is not done
+SELECT DISTINCT d.task_id FROM task_dependencies d
+JOIN tasks t ON t.id = d.blocked_by_id
+JOIN workflow_statuses ws ON ws.project_id = t.project_id AND ws.name = t.status
+WHERE d.task_id = ANY(sqlc.arg('task_ids')::bigint[]) AND ws.category <> 'done';
+
+-- name: CountProjectLabels :one
+SELECT COUNT(*) FROM labels WHERE project_id = $1 AND id = ANY(sqlc.arg('label_ids')::bigint[]);
+
+-- name: ListLabelIDsByName :many
+SELECT id FROM labels WHERE project_id = $1 AND lower(name) = ANY(sqlc.arg('names')::text[]);
+
+-- name: SetTaskLabels :exec
+-- replaces the labels of a task; the DELETE and INSERT run as one statement
+WITH removed AS (
+  DELETE FROM task_labels
+  WHERE task_id = sqlc.arg('task_id') AND label_id <> ALL(sqlc.arg('label_ids')::bigint[])
+)
+INSERT INTO task_labels (task_id, label_id, project_id)
+SELECT sqlc.arg('task_id')::bigint, unnest(sqlc.arg('label_ids')::bigint[]), sqlc.arg('project_id')::bigint
+ON CONFLICT DO NOTHING;
+
+-- name: ListTaskLabels :many
+SELECT tl.task_id, l.* FROM task_labels tl
+JOIN labels l ON l.id = tl.label_id
+WHERE tl.task_id = ANY(sqlc.arg('task_ids')::bigint[])
+ORDER BY tl.task_id, lower(l.name);
+
+-- name: ListProjectCustomFields :many
+SELECT * FROM custom_fields WHERE project_id = $1 ORDER BY id;
+
+-- name: ListCustomFieldsByID :many
+SELECT * FROM custom_fields WHERE id = ANY(sqlc.arg('ids')::bigint[]) ORDER BY id;
+
+-- name: SetTaskCustomFieldValues :exec
+-- cleared_field_ids are removed, the others are inserted or replaced
+WITH cleared AS (
+  DELETE FROM task_custom_field_values
+  WHERE task_id = sqlc.arg('task_id') AND field_id = ANY(sqlc.arg('cleared_field_ids')::bigint[])
+)
+INSERT INTO task_custom_field_values (task_id, field_id, project_id, value)
+SELECT sqlc.arg('task_id'), f.field_id, sqlc.arg('project_id'), f.value::jsonb
+FROM unnest(sqlc.arg('field_ids')::bigint[], sqlc.arg('field_values')::text[]) AS f(field_id, value)
+ON CONFLICT (task_id, field_id) DO UPDATE SET value = EXCLUDED.value, updated_at = now();
+
+-- name: ListTaskCustomFieldValues :many
+SELECT * FROM task_custom_field_values
+WHERE task_id = ANY(sqlc.arg('task_ids')::bigint[])
+ORDER BY task_id, field_id;
+
+-- name: ListWorkflowStatuses :many
+SELECT * FROM workflow_statuses WHERE project_id = $1 ORDER BY position;
+
+-- name: CreateTaskSeries :one
+-- the task the series is set up on becomes its first occurrence
+WITH series AS (
+  INSERT INTO task_series (project_id, created_by, title, description, assignee_id, priority, rule, starts_at, next_due_at, latest_task_id)
+  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+  RETURNING *
+), linked AS (
+  UPDATE tasks SET series_id = series.id FROM series WHERE tasks.id = series.latest_task_id
+)
+SELECT * FROM series;
+
+-- name: GetTaskSeries :one
+SELECT * FROM task_series WHERE id = $1;
+
+-- name: UpdateTaskSeries :one
+-- open occurrences take the provided values too, their titles followed by
+-- their due date like every occurrence; occurrences in the done category keep
+-- theirs. A new rule starts counting again from starts_at.
+WITH series AS (
+  UPDATE task_series
+  SET
+    title = COALESCE(sqlc.narg('title'), title),
+    description = COALESCE(sqlc.narg('description'), description),
+    assignee_id = COALESCE(sqlc.narg('assignee_id'), assignee_id),
+    priority = COALESCE(sqlc.narg('priority'), priority),
+    rule = COALESCE(sqlc.narg('rule'), rule),
+    starts_at = COALESCE(sqlc.narg('starts_at'), starts_at),
+    occurrences = CASE WHEN sqlc.narg('rule')::text IS NULL THEN occurrences ELSE 1 END,
+    next_due_at = CASE WHEN sqlc.narg('rule')::text IS NULL THEN next_due_at ELSE sqlc.narg('next_due_at') END,
+    updated_at = now()
+  WHERE id = sqlc.arg('id')
+  RETURNING *
+), occurrences AS (
+  UPDATE tasks t
+  SET
+    title = CASE WHEN sqlc.narg('title')::text IS NULL THEN t.title
+      ELSE series.title || ' (' || to_char(t.due_date, 'YYYY-MM-DD') || ')' END,
+    description = COALESCE(sqlc.narg('description'), t.description),
+    assignee_id = COALESCE(sqlc.narg('assignee_id'), t.assignee_id),
+    priority = COALESCE(sqlc.narg('priority'), t.priority),
+    updated_at = now()
+  FROM series, workflow_statuses ws
+  WHERE t.series_id = series.id AND ws.project_id = t.project_id AND ws.name = t.status AND ws.category <> 'done'
+)
+SELECT * FROM series;
+
+-- name: AdvanceTaskSeries :execrows
+-- only a series still waiting for the occurrence due at due_at advances, so
+-- an occurrence is never counted twice
+UPDATE task_series
+SET
+  occurrences = occurrences + 1,
+  next_due_at = sqlc.narg('next_due_at'),
+  latest_task_id = sqlc.arg('latest_task_id'),
+  updated_at = now()
+WHERE id = sqlc.arg('id') AND next_due_at = sqlc.arg('due_at')::timestamp;
+
+-- name: DeleteTaskSeries :execrows
+DELETE FROM task_series WHERE id = $1;
+
+-- name: EndTaskSeries :exec
+SELECT end_task_series(sqlc.arg('id')::bigint);
+
+-- name: ListDueTaskSeries :many
+SELECT series_id, user_id, organization_id FROM due_task_series(sqlc.arg('max_series')::int);
+
+-- name: StartTimeEntry :one
+INSERT INTO time_entries (task_id, project_id, user_id, started_at, note)
+VALUES ($1, $2, $3, now(), $4)
+RETURNING *;
+
+-- name: StopTimeEntry :one
+UPDATE time_entries SET ended_at = now()
+WHERE task_id = $1 AND user_id = $2 AND ended_at IS NULL
+RETURNING *;
+
+-- name: CreateTimeEntry :one
+INSERT INTO time_entries (task_id, project_id, user_id, started_at, ended_at, note)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetTimeEntry :one
+SELECT * FROM time_entries WHERE id = $1 AND task_id = $2;
+
+-- name: ListTimeEntries :many
+SELECT * FROM time_entries WHERE task_id = $1 ORDER BY started_at, id;
+
+-- name: DeleteTimeEntry :execrows
+DELETE FROM time_entries WHERE id = $1;
+
+-- name: SumTaskTimeSpent :many
+SELECT task_id, COALESCE(SUM(minutes), 0)::bigint AS minutes FROM time_entries
+WHERE task_id = ANY(sqlc.arg('task_ids')::bigint[])
+GROUP BY task_id;
+
+-- name: UseRemainingEstimate :exec
+-- never below zero, and tasks without an estimate keep none
+UPDATE tasks
+SET remaining_estimate_minutes = GREATEST(remaining_estimate_minutes - sqlc.arg('minutes')::int, 0)
+WHERE id = sqlc.arg('id') AND remaining_estimate_minutes IS NOT NULL;
+
+-- name: SumProjectTimeByUser :many
+-- running timers count once they are stopped
+SELECT te.user_id, u.name, u.email, SUM(te.minutes)::bigint AS minutes
+FROM time_entries te
+JOIN users u ON u.id = te.user_id
+WHERE te.project_id = sqlc.arg('project_id')
+  AND te.ended_at IS NOT NULL
+  AND te.started_at >= sqlc.arg('started_from')
+  AND te.started_at < sqlc.arg('started_before')
+GROUP BY te.user_id, u.name, u.email
+ORDER BY minutes DESC, te.user_id;
+
+-- name: SumUserTimeByProject :many
+-- running timers count once they are stopped
+SELECT te.project_id, p.name, SUM(te.minutes)::bigint AS minutes
+FROM time_entries te
+JOIN projects p ON p.id = te.project_id
+WHERE te.user_id = sqlc.arg('user_id')
+  AND te.ended_at IS NOT NULL
+  AND te.started_at >= sqlc.arg('started_from')
+  AND te.started_at < sqlc.arg('started_before')
+  AND p.organization_id = sqlc.arg('organization_id')
+  -- only the projects the viewer can see
+  AND te.project_id IN (
+    SELECT id FROM projects WHERE user_id = sqlc.arg('viewer_id')
+    UNION
+    SELECT pm.project_id FROM project_members pm WHERE pm.user_id = sqlc.arg('viewer_id')
+  )
+GROUP BY te.project_id, p.name
+ORDER BY minutes DESC, te.project_id;
+
+-- name: CreateTaskAttachment :one
+INSERT INTO task_attachments (task_id, project_id, uploader_id, file_name, content_type, size_bytes, checksum, storage_key)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetTaskAttachment :one
+SELECT * FROM task_attachments WHERE id = $1 AND task_id = $2;
+
+-- name: ListTaskAttachments :many
+SELECT * FROM task_attachments WHERE task_id = $1 ORDER BY created_at, id;
+
+-- name: DeleteTaskAttachment :execrows
+DELETE FROM task_attachments WHERE id = $1;
+
+-- name: QueueAttachmentDeletion :exec
+INSERT INTO attachment_deletions (storage_key) VALUES ($1);
+
+-- name: ListQueuedAttachmentDeletions :many
+SELECT storage_key FROM queued_attachment_deletions(sqlc.arg('max_keys')::int);
+
+-- name: DequeueAttachmentDeletion :exec
+SELECT dequeue_attachment_deletion(sqlc.arg('storage_key')::text);
+
+-- name: ListTaskChanges :many
+SELECT tc.id, tc.task_id, tc.project_id, tc.task_title, tc.actor_id, u.name AS actor_name, tc.action, tc.field, tc.old_value, tc.new_value, tc.changed_at
+FROM task_changes tc
+LEFT JOIN users u ON u.id = tc.actor_id
+WHERE tc.task_id = $1
+ORDER BY tc.id;
+
+-- name: ListProjectActivity :many
+-- newest first, including the changes of tasks deleted since
+SELECT tc.id, tc.task_id, tc.project_id, tc.task_title, tc.actor_id, u.name AS actor_name, tc.action, tc.field, tc.old_value, tc.new_value, tc.changed_at
+FROM task_changes tc
+LEFT JOIN users u ON u.id = tc.actor_id
+WHERE tc.project_id = $1
+ORDER BY tc.id DESC
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,226 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/middleware"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTaskTime returns the estimates of a task and the time logged on it
+// @Summary      Get the time of a task
+// @Description  Returns the original and remaining estimates of the task in minutes, every time entry logged on it, oldest first, and the minutes spent, running timers excluded
+// @Tags         time
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/time [get]
+// @Security BearerAuth
+func (t *TaskHandler) GetTaskTime(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	taskTime, err := t.taskService.TaskTime(ctx, userID, orgID, taskID)
+	if err != nil {
+		t.logger.Errorf("unable to get the time of task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, taskTime)
+}
+
+// LogTime records time spent on a task
+// @Summary      Log time on a task
+// @Description  Records minutes spent on the task, starting at started_at or ending now, and takes them off its remaining estimate. Entries cannot end in the future.
+// @Tags         time
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int             true  "Task ID"
+// @Param        time  body      LogTimeRequest  true  "Time spent"
+// @Success      201   {object}  map[string]interface{}
+// @Failure      400   {object}  utils.ErrorResponse
+// @Failure      403   {object}  utils.ErrorResponse
+// @Failure      404   {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/time [post]
+// @Security BearerAuth
+func (t *TaskHandler) LogTime(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	var req LogTimeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		t.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	entry, err := t.taskService.LogTime(ctx, userID, orgID, taskID, req)
+	if err != nil {
+		t.logger.Errorf("unable to log time on task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusCreated, entry)
+}
+
+// StartTimer starts a timer on a task
+// @Summary      Start a timer
+// @Description  Starts a timer for the caller on the task. Each user runs one timer at a time.
+// @Tags         time
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                true   "Task ID"
+// @Param        timer  body      StartTimerRequest  false  "Note on the work"
+// @Success      201    {object}  map[string]interface{}
+// @Failure      400    {object}  utils.ErrorResponse
+// @Failure      403    {object}  utils.ErrorResponse
+// @Failure      404    {object}  utils.ErrorResponse
+// @Failure      409    {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/time/start [post]
+// @Security BearerAuth
+func (t *TaskHandler) StartTimer(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	var req StartTimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		t.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	entry, err := t.taskService.StartTimer(ctx, userID, orgID, taskID, req)
+	if err != nil {
+		t.logger.Errorf("unable to start a timer on task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusCreated, entry)
+}
+
+// StopTimer stops the caller's timer on a task
+// @Summary      Stop a timer
+// @Description  Stops the caller's running timer on the task and takes the time off its remaining estimate
+// @Tags         time
+// @Produce      json
+// @Param        id   path      int  true  "Task ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/time/stop [post]
+// @Security BearerAuth
+func (t *TaskHandler) StopTimer(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	entry, err := t.taskService.StopTimer(ctx, userID, orgID, taskID)
+	if err != nil {
+		t.logger.Errorf("unable to stop the timer on task %d %v", taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, entry)
+}
+
+// DeleteTimeEntry removes a time entry of a task
+// @Summary      Delete a time entry
+// @Description  Removes a time entry; users delete their own entries and project owners anyone's
+// @Tags         time
+// @Produce      json
+// @Param        id       path      int  true  "Task ID"
+// @Param        entryId  path      int  true  "Time entry ID"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      403      {object}  utils.ErrorResponse
+// @Failure      404      {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/{id}/time/{entryId} [delete]
+// @Security BearerAuth
+func (t *TaskHandler) DeleteTimeEntry(c *gin.Context) {
+	userID, orgID, taskID, ok := t.taskScope(c)
+	if !ok {
+		return
+	}
+	entryID, err := strconv.ParseInt(c.Param("entryId"), 10, 64)
+	if err != nil || entryID <= 0 {
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidTimeEntryID.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := t.taskService.DeleteTimeEntry(ctx, userID, orgID, taskID, entryID); err != nil {
+		t.logger.Errorf("unable to delete time entry %d of task %d %v", entryID, taskID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "time entry deleted successfully",
+	})
+}
+
+// GetUserTime adds up the time a user logged per project
+// @Summary      Get the time of a user
+// @Description  Adds up the minutes a user logged from one day to another, both included, per project of the organization the caller can see; the caller's own time by default
+// @Tags         time
+// @Produce      json
+// @Param        from     query     string  true   "First day, e.g. 2026-10-01"
+// @Param        to       query     string  true   "Last day, e.g. 2026-10-31"
+// @Param        user_id  query     int     false  "User whose time to add up, a member of the organization"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      404      {object}  utils.ErrorResponse
+// @Router       /api/v1/tasks/time [get]
+// @Security BearerAuth
+func (t *TaskHandler) GetUserTime(c *gin.Context) {
+	val, exists := c.Get("userID")
+	if !exists {
+		t.logger.Errorf("%v", customErrors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrUserIDNotFoundInContext.Error())
+		return
+	}
+	userID, ok := val.(int)
+	if !ok {
+		t.logger.Errorf("%v", customErrors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, customErrors.ErrInvalidUserId.Error())
+		return
+	}
+	orgID, ok := middleware.OrganizationID(c)
+	if !ok {
+		return
+	}
+	var req UserTimeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		t.logger.Errorf("%v", err)
+		utils.Error(c, http.StatusBadRequest, customErrors.ErrInvalidTimeRange.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	userTime, err := t.taskService.UserTime(ctx, userID, orgID, req)
+	if err != nil {
+		t.logger.Errorf("unable to add up the time of user %d %v", userID, err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, userTime)
+}
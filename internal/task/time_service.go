@@ -0,0 +1,281 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/authz"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+)
+
+const (
+	maxEstimateMinutes  = 1000000
+	maxTimeEntryMinutes = 24 * 60
+	maxTimeRangeDays    = 366
+)
+
+// TaskTime returns the estimates of the task and the time logged on it.
+func (t *TaskService) TaskTime(ctx context.Context, userID int, orgID int, taskID int) (*TaskTime, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	task, err := t.taskRepository.GetTaskById(ctx, int64(taskID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	entries, err := t.taskRepository.ListTimeEntries(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	taskTime := &TaskTime{
+		OriginalEstimateMinutes:  task.OriginalEstimateMinutes,
+		RemainingEstimateMinutes: task.RemainingEstimateMinutes,
+		Entries:                  entries,
+	}
+	if taskTime.Entries == nil {
+		taskTime.Entries = []taskdb.TimeEntry{}
+	}
+	for _, entry := range entries {
+		taskTime.SpentMinutes += int64(entry.Minutes.Int32)
+	}
+	return taskTime, nil
+}
+
+// StartTimer starts a timer for userID on the task. A user runs one timer at
+// a time, across every task.
+func (t *TaskService) StartTimer(ctx context.Context, userID int, orgID int, taskID int, req StartTimerRequest) (*taskdb.TimeEntry, error) {
+	task, err := t.writableTask(ctx, userID, orgID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := t.taskRepository.StartTimeEntry(ctx, taskdb.StartTimeEntryParams{
+		TaskID:    task.ID,
+		ProjectID: task.ProjectID,
+		UserID:    int32(userID),
+		Note:      req.Note,
+	})
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+		return nil, customErrors.ErrTimerAlreadyRunning
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// StopTimer stops the timer userID runs on the task and takes the time off
+// its remaining estimate.
+func (t *TaskService) StopTimer(ctx context.Context, userID int, orgID int, taskID int) (*taskdb.TimeEntry, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionWrite); err != nil {
+		return nil, err
+	}
+	var entry taskdb.TimeEntry
+	err := t.inTx(ctx, func(ctx context.Context) error {
+		var err error
+		entry, err = t.taskRepository.StopTimeEntry(ctx, taskdb.StopTimeEntryParams{TaskID: int64(taskID), UserID: int32(userID)})
+		if errors.Is(err, sql.ErrNoRows) {
+			return customErrors.ErrNoRunningTimer
+		}
+		if err != nil {
+			return err
+		}
+		return t.useEstimate(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// LogTime records time userID spent on the task and takes it off the
+// remaining estimate.
+func (t *TaskService) LogTime(ctx context.Context, userID int, orgID int, taskID int, req LogTimeRequest) (*taskdb.TimeEntry, error) {
+	if req.Minutes < 1 || req.Minutes > maxTimeEntryMinutes {
+		return nil, customErrors.ErrInvalidTimeEntry
+	}
+	duration := time.Duration(req.Minutes) * time.Minute
+	now := time.Now().UTC()
+	startedAt := now.Add(-duration)
+	if req.StartedAt != nil {
+		startedAt = req.StartedAt.UTC()
+	}
+	// logged time is time already spent, so it has to end by now
+	if startedAt.Add(duration).After(now) {
+		return nil, customErrors.ErrInvalidTimeEntry
+	}
+	task, err := t.writableTask(ctx, userID, orgID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	var entry taskdb.TimeEntry
+	err = t.inTx(ctx, func(ctx context.Context) error {
+		entry, err = t.taskRepository.CreateTimeEntry(ctx, taskdb.CreateTimeEntryParams{
+			TaskID:    task.ID,
+			ProjectID: task.ProjectID,
+			UserID:    int32(userID),
+			StartedAt: startedAt,
+			EndedAt:   sql.NullTime{Time: startedAt.Add(duration), Valid: true},
+			Note:      req.Note,
+		})
+		if err != nil {
+			return err
+		}
+		return t.useEstimate(ctx, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// DeleteTimeEntry removes a time entry of the task. Users delete their own
+// entries and project owners anyone's. The remaining estimate is left as it is.
+func (t *TaskService) DeleteTimeEntry(ctx context.Context, userID int, orgID int, taskID int, entryID int64) error {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionWrite); err != nil {
+		return err
+	}
+	entry, err := t.taskRepository.GetTimeEntry(ctx, taskdb.GetTimeEntryParams{ID: entryID, TaskID: int64(taskID)})
+	if errors.Is(err, sql.ErrNoRows) {
+		return customErrors.ErrTimeEntryNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if entry.UserID != int32(userID) {
+		if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionManage); err != nil {
+			return err
+		}
+	}
+	rows, err := t.taskRepository.DeleteTimeEntry(ctx, entry.ID)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrTimeEntryNotFound
+	}
+	return nil
+}
+
+// ProjectTime adds up the time logged on the project over the range per user.
+func (t *TaskService) ProjectTime(ctx context.Context, userID int, orgID int, projectID int, timeRange types.TimeRange) (*types.ProjectTime, error) {
+	if err := checkTimeRange(timeRange); err != nil {
+		return nil, err
+	}
+	if err := t.authorizer.AuthorizeProject(ctx, userID, orgID, projectID, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	users, err := t.taskRepository.SumProjectTimeByUser(ctx, taskdb.SumProjectTimeByUserParams{
+		ProjectID:     int64(projectID),
+		StartedFrom:   timeRange.From,
+		StartedBefore: timeRange.To.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	projectTime := &types.ProjectTime{
+		ProjectID: int64(projectID),
+		From:      timeRange.From,
+		To:        timeRange.To,
+		Users:     users,
+	}
+	if projectTime.Users == nil {
+		projectTime.Users = []taskdb.SumProjectTimeByUserRow{}
+	}
+	for _, user := range users {
+		projectTime.TotalMinutes += user.Minutes
+	}
+	return projectTime, nil
+}
+
+// UserTime adds up the time a user logged over the range per project, on the
+// projects of the organization that userID can see. Other users must be
+// members of the organization.
+func (t *TaskService) UserTime(ctx context.Context, userID int, orgID int, req UserTimeRequest) (*UserTime, error) {
+	if err := checkTimeRange(req.TimeRange); err != nil {
+		return nil, err
+	}
+	target := int32(userID)
+	if req.UserID != nil && *req.UserID != target {
+		isMember, err := t.taskRepository.IsOrganizationMember(ctx, taskdb.IsOrganizationMemberParams{
+			OrganizationID: int64(orgID),
+			UserID:         *req.UserID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			return nil, customErrors.ErrOrganizationMemberNotFound
+		}
+		target = *req.UserID
+	}
+	projects, err := t.taskRepository.SumUserTimeByProject(ctx, taskdb.SumUserTimeByProjectParams{
+		UserID:         target,
+		StartedFrom:    req.From,
+		StartedBefore:  req.To.AddDate(0, 0, 1),
+		OrganizationID: int64(orgID),
+		ViewerID:       int32(userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	userTime := &UserTime{
+		UserID:   target,
+		From:     req.From,
+		To:       req.To,
+		Projects: projects,
+	}
+	if userTime.Projects == nil {
+		userTime.Projects = []taskdb.SumUserTimeByProjectRow{}
+	}
+	for _, project := range projects {
+		userTime.TotalMinutes += project.Minutes
+	}
+	return userTime, nil
+}
+
+// writableTask returns the task once userID may write to it.
+func (t *TaskService) writableTask(ctx context.Context, userID int, orgID int, taskID int) (*taskdb.Task, error) {
+	if err := t.authorizer.AuthorizeTask(ctx, userID, orgID, taskID, authz.ActionWrite); err != nil {
+		return nil, err
+	}
+	task, err := t.taskRepository.GetTaskById(ctx, int64(taskID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// useEstimate takes a finished entry off the remaining estimate of its task.
+func (t *TaskService) useEstimate(ctx context.Context, entry taskdb.TimeEntry) error {
+	if entry.Minutes.Int32 == 0 {
+		return nil
+	}
+	return t.taskRepository.UseRemainingEstimate(ctx, taskdb.UseRemainingEstimateParams{
+		Minutes: entry.Minutes.Int32,
+		ID:      entry.TaskID,
+	})
+}
+
+func checkEstimate(minutes int32) (sql.NullInt32, error) {
+	if minutes < 0 || minutes > maxEstimateMinutes {
+		return sql.NullInt32{}, customErrors.ErrInvalidEstimate
+	}
+	return sql.NullInt32{Int32: minutes, Valid: true}, nil
+}
+
+func checkTimeRange(timeRange types.TimeRange) error {
+	if timeRange.To.Before(timeRange.From) || timeRange.To.Sub(timeRange.From) > maxTimeRangeDays*24*time.Hour {
+		return customErrors.ErrInvalidTimeRange
+	}
+	return nil
+}
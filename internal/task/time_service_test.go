@@ -0,0 +1,274 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func timeEntry(id int64, userID int32, minutes int32) taskdb.TimeEntry {
+	return taskdb.TimeEntry{
+		ID:        id,
+		TaskID:    22,
+		ProjectID: 6,
+		UserID:    userID,
+		Minutes:   sql.NullInt32{Int32: minutes, Valid: minutes > 0},
+	}
+}
+
+func TestTaskTime(t *testing.T) {
+	service, repo := newCommentService()
+	task := subtask(22, 6, 0)
+	task.OriginalEstimateMinutes = sql.NullInt32{Int32: 120, Valid: true}
+	task.RemainingEstimateMinutes = sql.NullInt32{Int32: 30, Valid: true}
+	repo.On("GetTaskById", mock.Anything, int64(22)).Return(task, nil)
+	// the last entry is a running timer
+	repo.On("ListTimeEntries", mock.Anything, int64(22)).Return([]taskdb.TimeEntry{timeEntry(1, 1234, 60), timeEntry(2, 99, 30), timeEntry(3, 1234, 0)}, nil)
+
+	taskTime, err := service.TaskTime(context.TODO(), 1234, testOrgID, 22)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(90), taskTime.SpentMinutes)
+	assert.Equal(t, task.RemainingEstimateMinutes, taskTime.RemainingEstimateMinutes)
+	assert.Len(t, taskTime.Entries, 3)
+}
+
+func TestTimer(t *testing.T) {
+	t.Run("start", func(t *testing.T) {
+		service, repo := newCommentService()
+		repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+		repo.On("StartTimeEntry", mock.Anything, taskdb.StartTimeEntryParams{TaskID: 22, ProjectID: 6, UserID: 1234, Note: "review"}).Return(timeEntry(1, 1234, 0), nil)
+
+		entry, err := service.StartTimer(context.TODO(), 1234, testOrgID, 22, StartTimerRequest{Note: "review"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), entry.ID)
+	})
+
+	t.Run("start while another timer runs", func(t *testing.T) {
+		service, repo := newCommentService()
+		repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+		repo.On("StartTimeEntry", mock.Anything, mock.Anything).Return(taskdb.TimeEntry{}, mockDuplicateError())
+
+		_, err := service.StartTimer(context.TODO(), 1234, testOrgID, 22, StartTimerRequest{})
+		assert.Equal(t, customErrors.ErrTimerAlreadyRunning, err)
+	})
+
+	t.Run("stop uses the remaining estimate", func(t *testing.T) {
+		service, repo := newCommentService()
+		repo.On("StopTimeEntry", mock.Anything, taskdb.StopTimeEntryParams{TaskID: 22, UserID: 1234}).Return(timeEntry(1, 1234, 25), nil)
+		repo.On("UseRemainingEstimate", mock.Anything, taskdb.UseRemainingEstimateParams{Minutes: 25, ID: 22}).Return(nil)
+
+		entry, err := service.StopTimer(context.TODO(), 1234, testOrgID, 22)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(25), entry.Minutes.Int32)
+		repo.AssertCalled(t, "UseRemainingEstimate", mock.Anything, taskdb.UseRemainingEstimateParams{Minutes: 25, ID: 22})
+	})
+
+	t.Run("stop rolls back when the estimate cannot be used", func(t *testing.T) {
+		service, repo := newCommentService()
+		transactor := &fakeTransactor{}
+		service.UseTransactions(transactor)
+		inTx := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(txKey{}) != nil })
+		failed := errors.New("estimate failed")
+		repo.On("StopTimeEntry", inTx, mock.Anything).Return(timeEntry(1, 1234, 25), nil)
+		repo.On("UseRemainingEstimate", inTx, mock.Anything).Return(failed)
+
+		_, err := service.StopTimer(context.TODO(), 1234, testOrgID, 22)
+		assert.Equal(t, failed, err)
+		assert.Equal(t, failed, transactor.err, "the failed write must roll the transaction back")
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("stop without a running timer", func(t *testing.T) {
+		service, repo := newCommentService()
+		repo.On("StopTimeEntry", mock.Anything, mock.Anything).Return(taskdb.TimeEntry{}, sql.ErrNoRows)
+
+		_, err := service.StopTimer(context.TODO(), 1234, testOrgID, 22)
+		assert.Equal(t, customErrors.ErrNoRunningTimer, err)
+	})
+
+	t.Run("commenters cannot track time", func(t *testing.T) {
+		service, _ := newCommentService()
+		_, err := service.StartTimer(context.TODO(), 1234, testOrgID, 20, StartTimerRequest{})
+		assert.Equal(t, customErrors.ErrForbidden, err)
+	})
+}
+
+func TestLogTime(t *testing.T) {
+	startedAt := time.Date(2026, time.October, 5, 9, 0, 0, 0, time.UTC)
+	future := time.Now().Add(time.Hour)
+	recent := time.Now().Add(-10 * time.Minute)
+
+	testCases := []struct {
+		name          string
+		req           LogTimeRequest
+		expectedError error
+	}{
+		{name: "time from a given start", req: LogTimeRequest{Minutes: 45, StartedAt: &startedAt, Note: "pairing"}},
+		{name: "no minutes", req: LogTimeRequest{Minutes: 0}, expectedError: customErrors.ErrInvalidTimeEntry},
+		{name: "more than a day", req: LogTimeRequest{Minutes: 24*60 + 1}, expectedError: customErrors.ErrInvalidTimeEntry},
+		{name: "starting in the future", req: LogTimeRequest{Minutes: 30, StartedAt: &future}, expectedError: customErrors.ErrInvalidTimeEntry},
+		{name: "ending in the future", req: LogTimeRequest{Minutes: 30, StartedAt: &recent}, expectedError: customErrors.ErrInvalidTimeEntry},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newCommentService()
+			repo.On("GetTaskById", mock.Anything, int64(22)).Return(subtask(22, 6, 0), nil)
+			repo.On("CreateTimeEntry", mock.Anything, taskdb.CreateTimeEntryParams{
+				TaskID:    22,
+				ProjectID: 6,
+				UserID:    1234,
+				StartedAt: startedAt,
+				EndedAt:   sql.NullTime{Time: startedAt.Add(45 * time.Minute), Valid: true},
+				Note:      "pairing",
+			}).Return(timeEntry(1, 1234, 45), nil)
+			repo.On("UseRemainingEstimate", mock.Anything, taskdb.UseRemainingEstimateParams{Minutes: 45, ID: 22}).Return(nil)
+
+			_, err := service.LogTime(context.TODO(), 1234, testOrgID, 22, tc.req)
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedError != nil {
+				repo.AssertNotCalled(t, "CreateTimeEntry", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDeleteTimeEntry(t *testing.T) {
+	service, repo := newCommentService()
+	repo.On("GetTimeEntry", mock.Anything, taskdb.GetTimeEntryParams{ID: 2, TaskID: 22}).Return(timeEntry(2, 99, 30), nil)
+	repo.On("GetTimeEntry", mock.Anything, mock.Anything).Return(taskdb.TimeEntry{}, sql.ErrNoRows)
+	repo.On("DeleteTimeEntry", mock.Anything, int64(2)).Return(int64(1), nil)
+
+	// user 1234 owns project 6, so the entries of others can go too
+	assert.NoError(t, service.DeleteTimeEntry(context.TODO(), 1234, testOrgID, 22, 2))
+	assert.Equal(t, customErrors.ErrTimeEntryNotFound, service.DeleteTimeEntry(context.TODO(), 1234, testOrgID, 22, 3))
+}
+
+func TestEstimates(t *testing.T) {
+	estimate := func(minutes int32) *int32 { return &minutes }
+	withRemaining := subtask(22, 6, 0)
+	withRemaining.RemainingEstimateMinutes = sql.NullInt32{Int32: 30, Valid: true}
+
+	testCases := []struct {
+		name              string
+		task              taskdb.Task
+		req               UpdateTaskRequest
+		expectedOriginal  sql.NullInt32
+		expectedRemaining sql.NullInt32
+		expectedError     error
+	}{
+		{
+			name:              "first estimate is also what remains",
+			task:              subtask(22, 6, 0),
+			req:               UpdateTaskRequest{ID: 22, OriginalEstimateMinutes: estimate(120)},
+			expectedOriginal:  sql.NullInt32{Int32: 120, Valid: true},
+			expectedRemaining: sql.NullInt32{Int32: 120, Valid: true},
+		},
+		{
+			name:             "new estimate keeps what remains",
+			task:             withRemaining,
+			req:              UpdateTaskRequest{ID: 22, OriginalEstimateMinutes: estimate(120)},
+			expectedOriginal: sql.NullInt32{Int32: 120, Valid: true},
+		},
+		{
+			name:              "remaining estimate",
+			task:              withRemaining,
+			req:               UpdateTaskRequest{ID: 22, RemainingEstimateMinutes: estimate(0)},
+			expectedRemaining: sql.NullInt32{Int32: 0, Valid: true},
+		},
+		{
+			name:          "negative estimate",
+			task:          withRemaining,
+			req:           UpdateTaskRequest{ID: 22, RemainingEstimateMinutes: estimate(-5)},
+			expectedError: customErrors.ErrInvalidEstimate,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newCommentService()
+			repo.On("GetTaskById", mock.Anything, int64(22)).Return(tc.task, nil)
+			repo.On("UpdateTask", mock.Anything, mock.MatchedBy(func(params taskdb.UpdateTaskParams) bool {
+				return params.OriginalEstimateMinutes == tc.expectedOriginal && params.RemainingEstimateMinutes == tc.expectedRemaining
+			})).Return(tc.task, nil)
+
+			err := service.UpdateTask(context.TODO(), 1234, testOrgID, tc.req)
+			assert.Equal(t, tc.expectedError, err)
+			if tc.expectedError != nil {
+				repo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTimeTotals(t *testing.T) {
+	from := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, time.October, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("per user of a project", func(t *testing.T) {
+		service, repo := newCommentService()
+		repo.On("SumProjectTimeByUser", mock.Anything, taskdb.SumProjectTimeByUserParams{
+			ProjectID:     6,
+			StartedFrom:   from,
+			StartedBefore: to.AddDate(0, 0, 1),
+		}).Return([]taskdb.SumProjectTimeByUserRow{{UserID: 1234, Minutes: 300}, {UserID: 99, Minutes: 45}}, nil)
+
+		projectTime, err := service.ProjectTime(context.TODO(), 1234, testOrgID, 6, types.TimeRange{From: from, To: to})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(345), projectTime.TotalMinutes)
+		assert.Len(t, projectTime.Users, 2)
+	})
+
+	t.Run("per project of the caller", func(t *testing.T) {
+		service, repo := newCommentService()
+		repo.On("SumUserTimeByProject", mock.Anything, taskdb.SumUserTimeByProjectParams{
+			UserID:         1234,
+			StartedFrom:    from,
+			StartedBefore:  to.AddDate(0, 0, 1),
+			OrganizationID: testOrgID,
+			ViewerID:       1234,
+		}).Return([]taskdb.SumUserTimeByProjectRow(nil), nil)
+
+		userTime, err := service.UserTime(context.TODO(), 1234, testOrgID, UserTimeRequest{TimeRange: types.TimeRange{From: from, To: to}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), userTime.TotalMinutes)
+		assert.Empty(t, userTime.Projects)
+	})
+
+	t.Run("per project of another member", func(t *testing.T) {
+		service, repo := newCommentService()
+		repo.On("IsOrganizationMember", mock.Anything, taskdb.IsOrganizationMemberParams{OrganizationID: testOrgID, UserID: 99}).Return(true, nil)
+		repo.On("IsOrganizationMember", mock.Anything, mock.Anything).Return(false, nil)
+		repo.On("SumUserTimeByProject", mock.Anything, taskdb.SumUserTimeByProjectParams{
+			UserID:         99,
+			StartedFrom:    from,
+			StartedBefore:  to.AddDate(0, 0, 1),
+			OrganizationID: testOrgID,
+			ViewerID:       1234,
+		}).Return([]taskdb.SumUserTimeByProjectRow{{ProjectID: 6, Minutes: 45}}, nil)
+
+		member, outsider := int32(99), int32(77)
+		userTime, err := service.UserTime(context.TODO(), 1234, testOrgID, UserTimeRequest{TimeRange: types.TimeRange{From: from, To: to}, UserID: &member})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(45), userTime.TotalMinutes)
+		_, err = service.UserTime(context.TODO(), 1234, testOrgID, UserTimeRequest{TimeRange: types.TimeRange{From: from, To: to}, UserID: &outsider})
+		assert.Equal(t, customErrors.ErrOrganizationMemberNotFound, err)
+		repo.AssertNumberOfCalls(t, "SumUserTimeByProject", 1)
+	})
+
+	t.Run("invalid ranges", func(t *testing.T) {
+		service, _ := newCommentService()
+		_, err := service.ProjectTime(context.TODO(), 1234, testOrgID, 6, types.TimeRange{From: to, To: from})
+		assert.Equal(t, customErrors.ErrInvalidTimeRange, err)
+		_, err = service.UserTime(context.TODO(), 1234, testOrgID, UserTimeRequest{TimeRange: types.TimeRange{From: from, To: from.AddDate(2, 0, 0)}})
+		assert.Equal(t, customErrors.ErrInvalidTimeRange, err)
+	})
+}
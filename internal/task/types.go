@@ -2,9 +2,11 @@ package task
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/Gkemhcs/taskpilot/internal/types"
 )
 
 type CreateTaskRequest struct {
@@ -12,19 +14,33 @@ type CreateTaskRequest struct {
 	Title         string    `json:"title"`
 	AssigneeEmail string    `json:"assignee_email"`
 	Description   string    `json:"description"`
-	Status        string    `json:"status"`
+	Status        string    `json:"status"` // a status of the project's workflow, its first status when empty
 	Priority      string    `json:"priority"`
 	DueDate       time.Time `json:"due_date"`
+	ParentTaskID  *int64    `json:"parent_task_id,omitempty"` // create the task as a subtask of this one
+	LabelIDs      []int64   `json:"label_ids,omitempty"`      // labels of the task's project
+	// CustomFields holds values of the project's custom fields by field id.
+	CustomFields map[int64]any `json:"custom_fields,omitempty"`
+	// Recurrence repeats the task by an RFC 5545 rule such as FREQ=WEEKLY;BYDAY=MO.
+	Recurrence string `json:"recurrence,omitempty"`
+	// OriginalEstimateMinutes is the expected effort, the remaining estimate starts from it.
+	OriginalEstimateMinutes *int32 `json:"original_estimate_minutes,omitempty"`
 }
 
 type CreateTaskInput struct {
-	ProjectID   int       `json:"project_id"`
-	Title       string    `json:"title"`
-	AssigneeID  int       `json:"assignee_id"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	Priority    string    `json:"priority"`
-	DueDate     time.Time `json:"due_date"`
+	ProjectID               int           `json:"project_id"`
+	Title                   string        `json:"title"`
+	AssigneeID              int           `json:"assignee_id"`
+	Description             string        `json:"description"`
+	Status                  string        `json:"status"`
+	Priority                string        `json:"priority"`
+	DueDate                 time.Time     `json:"due_date"`
+	ParentTaskID            *int64        `json:"parent_task_id"`
+	LabelIDs                []int64       `json:"label_ids"`
+	CustomFields            map[int64]any `json:"custom_fields"`
+	Recurrence              string        `json:"recurrence"`
+	SeriesID                *int64        `json:"series_id"` // set for the occurrences a series creates
+	OriginalEstimateMinutes *int32        `json:"original_estimate_minutes"`
 }
 
 type UpdateTaskRequest struct {
@@ -34,21 +50,149 @@ type UpdateTaskRequest struct {
 	DueDate     *time.Time `json:"due_date,omitempty"`
 	Status      *string    `json:"status,omitempty"`
 	Priority    *string    `json:"priority,omitempty"`
+	// AssigneeEmail reassigns the task; the handler resolves it to AssigneeID.
+	AssigneeEmail *string `json:"assignee_email,omitempty"`
+	AssigneeID    *int64  `json:"-"`
+	// ParentTaskID moves the task under another task of its project, 0 makes it a top-level task again.
+	ParentTaskID *int64 `json:"parent_task_id,omitempty"`
+	// LabelIDs replaces the labels of the task, an empty list removes them all.
+	LabelIDs *[]int64 `json:"label_ids,omitempty"`
+	// CustomFields sets the listed custom field values by field id, null clears a value.
+	CustomFields map[int64]any `json:"custom_fields,omitempty"`
+	// Estimates are in minutes; logging time on the task lowers the remaining one.
+	OriginalEstimateMinutes  *int32 `json:"original_estimate_minutes,omitempty"`
+	RemainingEstimateMinutes *int32 `json:"remaining_estimate_minutes,omitempty"`
 }
 type TaskFilterRequest struct {
+	types.Page
 	ProjectID   *int64     `form:"project_id"`
 	AssigneeID  *int64     `form:"assignee_id"`
 	Statuses    []string   `form:"statuses"` // comma-separated in URL
 	Priority    *string    `form:"priority"`
 	DueDateFrom *time.Time `form:"due_date_from" time_format:"2006-01-02T15:04:05Z07:00"`
 	DueDateTo   *time.Time `form:"due_date_to"   time_format:"2006-01-02T15:04:05Z07:00"`
-	Limit       *int32     `form:"limit"`
-	Offset      *int32     `form:"offset"`
+	Labels      []string   `form:"labels"`      // label names, comma-separated in URL
+	LabelMatch  string     `form:"label_match"` // any (default) or all of the labels
+	// CustomFields filters on custom field values, each as <field id>:<value>
+	CustomFields []string `form:"custom_field"`
+	SortField    *int64   `form:"sort_field"` // custom field id to order by instead of the due date
+	SortOrder    string   `form:"sort_order"` // asc (default) or desc
 }
 
+type BulkTaskService interface {
+	CreateTask(ctx context.Context, userID int, orgID int, taskInput CreateTaskInput) (*taskdb.Task, error)
+	GetTasksByProjectID(ctx context.Context, userID int, orgID int, projectID int) ([]taskdb.Task, error)
+	TaskResponses(ctx context.Context, tasks []taskdb.Task) ([]types.TaskResponse, error)
+	ListCustomFields(ctx context.Context, userID int, orgID int, projectID int) ([]taskdb.CustomField, error)
+}
+
+// SetRecurrenceRequest makes a task the first occurrence of a recurring series.
+type SetRecurrenceRequest struct {
+	Rule string `json:"rule" binding:"required"` // RFC 5545 rule such as FREQ=MONTHLY;INTERVAL=1
+}
+
+// UpdateSeriesRequest edits a recurring series; only provided values are
+// updated. They apply to the occurrences created from now on and to the open
+// ones, while PATCH /tasks/{id} edits a single occurrence.
+type UpdateSeriesRequest struct {
+	Title         *string `json:"title,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	Priority      *string `json:"priority,omitempty"`
+	AssigneeEmail *string `json:"assignee_email,omitempty"`
+	AssigneeID    *int64  `json:"-"`
+	// Rule replaces the recurrence rule, counting from the latest occurrence.
+	Rule *string `json:"rule,omitempty"`
+}
+
+type AddDependencyRequest struct {
+	BlockedByID int64 `json:"blocked_by_id" binding:"required"`
+}
+
+// TaskDependencies lists the tasks a task waits for and the tasks waiting for it.
+type TaskDependencies struct {
+	BlockedBy []taskdb.Task `json:"blocked_by"`
+	Blocking  []taskdb.Task `json:"blocking"`
+}
+
+// StartTimerRequest starts a timer on a task, the body is optional.
+type StartTimerRequest struct {
+	Note string `json:"note"`
+}
+
+// LogTimeRequest records time spent on a task without a timer.
+type LogTimeRequest struct {
+	Minutes   int32      `json:"minutes" binding:"required"`
+	StartedAt *time.Time `json:"started_at,omitempty"` // when the work started, Minutes before now by default; the work must end by now
+	Note      string     `json:"note"`
+}
+
+// TaskTime is the estimates of a task and the time logged on it, oldest entry
+// first. SpentMinutes leaves out a running timer.
+type TaskTime struct {
+	OriginalEstimateMinutes  sql.NullInt32      `json:"original_estimate_minutes"`
+	RemainingEstimateMinutes sql.NullInt32      `json:"remaining_estimate_minutes"`
+	SpentMinutes             int64              `json:"spent_minutes"`
+	Entries                  []taskdb.TimeEntry `json:"entries"`
+}
 
+// UserTimeRequest selects the time a user logged over a date range, the
+// caller's own by default.
+type UserTimeRequest struct {
+	types.TimeRange
+	UserID *int32 `form:"user_id"`
+}
 
-type BulkTaskService interface{
-	CreateTask(ctx context.Context, taskInput CreateTaskInput) (*taskdb.Task, error)
-	GetTasksByProjectID(ctx context.Context, projectID int) ([]taskdb.Task, error)
-}
\ No newline at end of file
+// UserTime is the time a user logged per project over a date range, limited
+// to the projects the caller can see.
+type UserTime struct {
+	UserID       int32                            `json:"user_id"`
+	From         time.Time                        `json:"from"`
+	To           time.Time                        `json:"to"`
+	TotalMinutes int64                            `json:"total_minutes"`
+	Projects     []taskdb.SumUserTimeByProjectRow `json:"projects"`
+}
+
+// AttachmentLimits bounds the files users attach to tasks. A content type
+// such as image/* allows the whole family.
+type AttachmentLimits struct {
+	MaxSize      int64         // Largest file in bytes
+	ContentTypes []string      // Content types files may have, sniffed from their first bytes
+	LinkExpiry   time.Duration // How long download links stay valid
+}
+
+// AttachmentLink is a time-limited download link to an attachment.
+type AttachmentLink struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TaskHistory is the recorded changes of a task, oldest first, and the time
+// it spent in each of its statuses.
+type TaskHistory struct {
+	Changes      []taskdb.ListTaskChangesRow `json:"changes"`
+	TimeInStatus []StatusTime                `json:"time_in_status"`
+}
+
+// StatusTime is how long a task spent in a status over all its visits, in
+// the order the statuses were first entered.
+type StatusTime struct {
+	Status  string `json:"status"`
+	Seconds int64  `json:"seconds"`
+	Visits  int    `json:"visits"`
+	Current bool   `json:"current"` // the task is in this status now
+}
+
+type CreateCommentRequest struct {
+	Body     string `json:"body" binding:"required"`
+	ParentID *int64 `json:"parent_id,omitempty"` // reply to this top-level comment
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CommentThread is a top-level comment followed by its replies, oldest first.
+type CommentThread struct {
+	taskdb.TaskComment
+	Replies []taskdb.TaskComment `json:"replies"`
+}
@@ -0,0 +1,69 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+)
+
+// workflowStatus finds a status of the project's workflow by name, ignoring
+// case. An empty name gives the first status, which new tasks start in.
+func (t *TaskService) workflowStatus(ctx context.Context, projectID int64, name string) (*taskdb.WorkflowStatus, []taskdb.WorkflowStatus, error) {
+	workflow, err := t.taskRepository.ListWorkflowStatuses(ctx, projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" && len(workflow) > 0 {
+		return &workflow[0], workflow, nil
+	}
+	i := slices.IndexFunc(workflow, func(status taskdb.WorkflowStatus) bool { return status.Name == name })
+	if i < 0 {
+		names := make([]string, len(workflow))
+		for i, status := range workflow {
+			names[i] = status.Name
+		}
+		return nil, nil, fmt.Errorf("%w: %s, allowed statuses are %s", customErrors.ErrUnknownStatus, name, strings.Join(names, ", "))
+	}
+	return &workflow[i], workflow, nil
+}
+
+// checkTransition makes sure the workflow lets a task move from its current
+// status to the next one. Staying in the same status is always allowed.
+func checkTransition(workflow []taskdb.WorkflowStatus, from string, to string) error {
+	if from == to {
+		return nil
+	}
+	i := slices.IndexFunc(workflow, func(status taskdb.WorkflowStatus) bool { return status.Name == from })
+	if i < 0 || !slices.Contains(workflow[i].Transitions, to) {
+		return fmt.Errorf("%w: %s to %s", customErrors.ErrStatusTransitionNotAllowed, from, to)
+	}
+	return nil
+}
+
+// statusCategory returns the category of a status of the workflow.
+func statusCategory(workflow []taskdb.WorkflowStatus, name string) taskdb.StatusCategory {
+	i := slices.IndexFunc(workflow, func(status taskdb.WorkflowStatus) bool { return status.Name == name })
+	if i < 0 {
+		return ""
+	}
+	return workflow[i].Category
+}
+
+// statusNames splits comma-separated status names and upper-cases them.
+func statusNames(values []string) []string {
+	names := []string{}
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToUpper(strings.TrimSpace(name))
+			if name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,74 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func defaultWorkflow(projectID int64) []taskdb.WorkflowStatus {
+	return []taskdb.WorkflowStatus{
+		{ID: 1, ProjectID: projectID, Name: "TODO", Category: taskdb.StatusCategoryOpen, Position: 0, Transitions: []string{"IN_PROGRESS", "DONE"}},
+		{ID: 2, ProjectID: projectID, Name: "IN_PROGRESS", Category: taskdb.StatusCategoryInProgress, Position: 1, Transitions: []string{"TODO", "IN_REVIEW", "DONE"}},
+		{ID: 3, ProjectID: projectID, Name: "IN_REVIEW", Category: taskdb.StatusCategoryInProgress, Position: 2, Transitions: []string{"IN_PROGRESS", "DONE"}},
+		{ID: 4, ProjectID: projectID, Name: "DONE", Category: taskdb.StatusCategoryDone, Position: 3, Transitions: []string{"TODO"}},
+	}
+}
+
+func TestStatusTransitions(t *testing.T) {
+	testCases := []struct {
+		name           string
+		from           string
+		to             string
+		expectedStatus string
+		expectedError  error
+	}{
+		{name: "allowed transition", from: "IN_PROGRESS", to: "in_review", expectedStatus: "IN_REVIEW"},
+		{name: "transition missing from the workflow", from: "TODO", to: "IN_REVIEW", expectedError: customErrors.ErrStatusTransitionNotAllowed},
+		{name: "status missing from the workflow", from: "TODO", to: "BLOCKED", expectedError: customErrors.ErrUnknownStatus},
+		{name: "same status is always allowed", from: "IN_REVIEW", to: "IN_REVIEW", expectedStatus: "IN_REVIEW"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, repo := newCommentService()
+			task := subtask(22, 6, 0)
+			task.Status = tc.from
+			repo.On("GetTaskById", mock.Anything, int64(22)).Return(task, nil)
+			repo.On("ListWorkflowStatuses", mock.Anything, int64(6)).Return(defaultWorkflow(6), nil)
+			repo.On("UpdateTask", mock.Anything, mock.Anything).Return(task, nil)
+
+			err := service.UpdateTask(context.TODO(), 1234, testOrgID, UpdateTaskRequest{ID: 22, Status: &tc.to})
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				repo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			params := repo.Calls[len(repo.Calls)-1].Arguments.Get(1).(taskdb.UpdateTaskParams)
+			assert.Equal(t, tc.expectedStatus, params.Status.String)
+		})
+	}
+}
+
+func TestCompleteFromDoneCategory(t *testing.T) {
+	service, repo := newCommentService()
+	task := subtask(22, 6, 0)
+	task.Status = "IN_REVIEW"
+	repo.On("GetTaskById", mock.Anything, int64(22)).Return(task, nil)
+	repo.On("ListWorkflowStatuses", mock.Anything, int64(6)).Return(defaultWorkflow(6), nil)
+	repo.On("CountOpenBlockers", mock.Anything, int64(22)).Return(int64(1), nil)
+
+	done := "DONE"
+	err := service.UpdateTask(context.TODO(), 1234, testOrgID, UpdateTaskRequest{ID: 22, Status: &done})
+	assert.Equal(t, customErrors.ErrTaskBlocked, err)
+}
+
+func TestStatusNames(t *testing.T) {
+	assert.Equal(t, []string{"TODO", "IN_REVIEW"}, statusNames([]string{"todo, in_review", "TODO", ""}))
+	assert.Equal(t, []string{}, statusNames(nil))
+}
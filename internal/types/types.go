@@ -2,7 +2,12 @@ package types
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
 
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	projectdb "github.com/Gkemhcs/taskpilot/internal/project/gen"
 	taskdb "github.com/Gkemhcs/taskpilot/internal/task/gen"
 )
@@ -14,9 +19,142 @@ type IUserHandler interface {
 }
 
 type TaskQueryService interface {
-	GetTasksByProjectID(ctx context.Context, projectID int) ([]taskdb.Task, error)
+	GetTasksByProjectID(ctx context.Context, userID int, orgID int, projectID int) ([]taskdb.Task, error)
+	// TaskResponses attaches the comment count, subtask roll-up, blocked flag, labels, custom field values and time spent of every task.
+	TaskResponses(ctx context.Context, tasks []taskdb.Task) ([]TaskResponse, error)
+	DependencyGraph(ctx context.Context, userID int, orgID int, projectID int) (*DependencyGraph, error)
+	ProjectTime(ctx context.Context, userID int, orgID int, projectID int, timeRange TimeRange) (*ProjectTime, error)
+	ProjectActivity(ctx context.Context, userID int, orgID int, projectID int, page Page) ([]taskdb.ListProjectActivityRow, error)
 }
 
 type ProjectReader interface {
-	GetProjectById(ctx context.Context, projectId int) (*projectdb.Project, error)
+	GetProjectById(ctx context.Context, userID int, orgID int, projectId int) (*projectdb.Project, error)
+}
+
+// TaskResponse is a task as the API returns it, with the number of comments
+// on it, the progress of its subtasks, whether a task it depends on is still
+// open, its labels, its custom field values by field id and the minutes
+// logged on it by stopped timers and manual entries.
+type TaskResponse struct {
+	taskdb.Task
+	CommentCount     int64                     `json:"comment_count"`
+	Subtasks         SubtaskRollup             `json:"subtasks"`
+	Blocked          bool                      `json:"blocked"`
+	Labels           []taskdb.Label            `json:"labels"`
+	CustomFields     map[int64]json.RawMessage `json:"custom_fields"`
+	TimeSpentMinutes int64                     `json:"time_spent_minutes"`
+}
+
+// SubtaskRollup summarises the direct subtasks of a task. Percent is the share
+// of them that are done, rounded down, and 0 for a task without subtasks.
+type SubtaskRollup struct {
+	Total   int64 `json:"total"`
+	Done    int64 `json:"done"`
+	Percent int64 `json:"percent"`
+}
+
+// DependencyGraph is every task of a project and the dependencies between
+// them. An edge means TaskID is blocked by BlockedByID.
+type DependencyGraph struct {
+	Nodes []TaskResponse          `json:"nodes"`
+	Edges []taskdb.TaskDependency `json:"edges"`
+}
+
+// TaskEventKind names a write the task service publishes to its subscribers.
+type TaskEventKind string
+
+const (
+	TaskCreated    TaskEventKind = "task_created"
+	TaskUpdated    TaskEventKind = "task_updated"
+	CommentCreated TaskEventKind = "comment_created"
+	CommentUpdated TaskEventKind = "comment_updated"
+)
+
+// TaskEvent describes a task or comment write after it succeeded. Task events
+// carry the task, and Previous on updates; comment events carry the comment,
+// and PreviousComment on edits.
+type TaskEvent struct {
+	Kind            TaskEventKind
+	ActorID         int
+	TaskID          int64
+	Task            *taskdb.Task
+	Previous        *taskdb.Task
+	Comment         *taskdb.TaskComment
+	PreviousComment *taskdb.TaskComment
+}
+
+// TaskEventSubscriber reacts to task events. It is called synchronously after
+// the write, so it must not fail the request; subscribers log their own errors.
+type TaskEventSubscriber interface {
+	HandleTaskEvent(ctx context.Context, event TaskEvent)
+}
+
+// Transactor runs fn in a database transaction joined by every query made
+// through the context fn is given. *db.TenantDB is one.
+type Transactor interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// TimeRange selects the time entries started from From to To, both days
+// included, bound from the from and to query parameters.
+type TimeRange struct {
+	From time.Time `form:"from" binding:"required" time_format:"2006-01-02"`
+	To   time.Time `form:"to" binding:"required" time_format:"2006-01-02"`
+}
+
+// ProjectTime is the time logged on a project over a date range per user,
+// most time first.
+type ProjectTime struct {
+	ProjectID    int64                            `json:"project_id"`
+	From         time.Time                        `json:"from"`
+	To           time.Time                        `json:"to"`
+	TotalMinutes int64                            `json:"total_minutes"`
+	Users        []taskdb.SumProjectTimeByUserRow `json:"users"`
+}
+
+const (
+	// DefaultPageSize is the number of items listed when a request has no limit.
+	DefaultPageSize = 20
+	// MaxPageSize is the largest limit a list request may ask for.
+	MaxPageSize = 100
+)
+
+// Page selects a slice of a list, bound from the limit and offset query parameters.
+type Page struct {
+	Limit  *int32 `form:"limit"`
+	Offset *int32 `form:"offset"`
+}
+
+// Bounds applies the defaults and rejects limits above MaxPageSize.
+func (p Page) Bounds() (int32, int32, error) {
+	limit, offset := int32(DefaultPageSize), int32(0)
+	if p.Limit != nil {
+		limit = *p.Limit
+	}
+	if p.Offset != nil {
+		offset = *p.Offset
+	}
+	if limit < 1 || limit > MaxPageSize || offset < 0 {
+		return 0, 0, customErrors.ErrInvalidPagination
+	}
+	return limit, offset, nil
+}
+
+var (
+	// TaskImportHeaders are the columns every task import needs.
+	TaskImportHeaders = []string{"project_id", "title", "assignee_email", "description", "status", "priority", "due_date"}
+	// TaskExportHeaders are the columns exports add so the file can be
+	// imported again.
+	TaskExportHeaders = []string{"parent_title", "labels"}
+	// TaskTimeHeaders name the time columns of exports, in minutes.
+	TaskTimeHeaders = []string{"original_estimate_minutes", "remaining_estimate_minutes", "time_spent_minutes"}
+)
+
+// IsTaskSheetHeader reports whether name is a column of task imports and
+// exports rather than one named after a custom field.
+func IsTaskSheetHeader(name string) bool {
+	name = strings.TrimSpace(name)
+	matches := func(header string) bool { return strings.EqualFold(header, name) }
+	return slices.ContainsFunc(TaskImportHeaders, matches) || slices.ContainsFunc(TaskExportHeaders, matches) ||
+		slices.ContainsFunc(TaskTimeHeaders, matches)
 }
@@ -0,0 +1,126 @@
+package user
+
+import (
+	"context"
+	goerrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ForgotPassword mails a password reset link
+// @Summary      Request password reset
+// @Description  Emails a single-use password reset link. The response is the same whether or not the email is registered.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      EmailRequest true  "Account email"
+// @Success      202      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Router       /api/v1/users/password/forgot [post]
+func (u *UserHandler) ForgotPassword(c *gin.Context) {
+	var request EmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+	// unknown emails and delivery failures are only logged so the response does not reveal registered accounts
+	if err := u.accountService.RequestPasswordReset(ctx, request.Email); err != nil && !goerrors.Is(err, errors.USER_NOT_FOUND) {
+		u.logger.Errorf("unable to send password reset email %v", err)
+	}
+	utils.Success(c, http.StatusAccepted, map[string]any{
+		"message": "if the email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password with a reset token
+// @Summary      Reset password
+// @Description  Sets a new password using the token from the reset email and logs the user out of every session
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      ResetPasswordRequest true  "Reset token and new password"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Router       /api/v1/users/password/reset [post]
+func (u *UserHandler) ResetPassword(c *gin.Context) {
+	var request ResetPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	userID, err := u.accountService.ResetPassword(ctx, request.Token, request.Password)
+	if err != nil {
+		u.logger.Errorf("unable to reset password %v", err)
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := u.jwtManager.RevokeUser(ctx, userID); err != nil {
+		u.logger.Errorf("unable to revoke sessions of user %d after password reset %v", userID, err)
+	}
+	u.logger.Infof("user %d reset their password", userID)
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "password has been reset, please log in again",
+	})
+}
+
+// VerifyEmail confirms an email address
+// @Summary      Verify email
+// @Description  Marks the email address as verified using the token from the verification email
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      VerifyEmailRequest true  "Verification token"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Router       /api/v1/users/verify [post]
+func (u *UserHandler) VerifyEmail(c *gin.Context) {
+	var request VerifyEmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := u.accountService.VerifyEmail(ctx, request.Token); err != nil {
+		u.logger.Errorf("unable to verify email %v", err)
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "email verified",
+	})
+}
+
+// ResendVerificationEmail mails a new verification link
+// @Summary      Resend verification email
+// @Description  Emails a new verification link, invalidating earlier ones. The response is the same whether or not the email is registered.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      EmailRequest true  "Account email"
+// @Success      202      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Router       /api/v1/users/verify/resend [post]
+func (u *UserHandler) ResendVerificationEmail(c *gin.Context) {
+	var request EmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+	if err := u.accountService.SendEmailVerification(ctx, request.Email); err != nil && !goerrors.Is(err, errors.USER_NOT_FOUND) {
+		u.logger.Errorf("unable to send verification email %v", err)
+	}
+	utils.Success(c, http.StatusAccepted, map[string]any{
+		"message": "if the email is registered and not yet verified, a verification link has been sent",
+	})
+}
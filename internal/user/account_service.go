@@ -0,0 +1,175 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/mailer"
+	userdb "github.com/Gkemhcs/taskpilot/internal/user/gen"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AccountServiceParams configures the emailed account recovery and verification links.
+type AccountServiceParams struct {
+	BaseURL                   string // Frontend URL the emailed links point to
+	PasswordResetTokenTTL     time.Duration
+	EmailVerificationTokenTTL time.Duration
+}
+
+// NewAccountService creates an AccountService that mails its tokens with mail.
+func NewAccountService(dbQuerier userdb.Querier, mail mailer.Mailer, params AccountServiceParams) *AccountService {
+	return &AccountService{
+		userRepository: dbQuerier,
+		mailer:         mail,
+		params:         params,
+	}
+}
+
+// AccountService implements password reset and email verification. Both use
+// single-use, time-limited tokens of which only a hash is stored.
+type AccountService struct {
+	userRepository userdb.Querier
+	mailer         mailer.Mailer
+	params         AccountServiceParams
+}
+
+// SendEmailVerification mails a verification link to the user with email.
+// Already verified users are silently skipped.
+func (a *AccountService) SendEmailVerification(ctx context.Context, email string) error {
+	user, err := a.userRepository.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return customErrors.USER_NOT_FOUND
+	}
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt.Valid {
+		return nil
+	}
+	token, err := a.issueToken(ctx, user.ID, userdb.UserTokenPurposeEMAILVERIFICATION, a.params.EmailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+	return a.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your TaskPilot email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening the link below. It expires in %s.\n\n%s\n\nIf you did not create a TaskPilot account you can ignore this email.\n",
+			user.Name, a.params.EmailVerificationTokenTTL, a.link("/verify-email", token)),
+	})
+}
+
+// VerifyEmail consumes a verification token and marks the email as verified.
+func (a *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := a.consumeToken(ctx, token, userdb.UserTokenPurposeEMAILVERIFICATION)
+	if err != nil {
+		return err
+	}
+	return a.userRepository.MarkEmailVerified(ctx, userID)
+}
+
+// RequestPasswordReset mails a password reset link to the user with email.
+func (a *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := a.userRepository.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return customErrors.USER_NOT_FOUND
+	}
+	if err != nil {
+		return err
+	}
+	token, err := a.issueToken(ctx, user.ID, userdb.UserTokenPurposePASSWORDRESET, a.params.PasswordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+	return a.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your TaskPilot password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your TaskPilot account. Open the link below to choose a new one. It expires in %s and can be used once.\n\n%s\n\nIf you did not ask for this, you can ignore this email and your password stays the same.\n",
+			user.Name, a.params.PasswordResetTokenTTL, a.link("/reset-password", token)),
+	})
+}
+
+// ResetPassword consumes a reset token and sets a new password. It returns the
+// user ID so the caller can end the user's existing sessions. Receiving the
+// reset email proves ownership of the address, so the email is verified too.
+func (a *AccountService) ResetPassword(ctx context.Context, token string, newPassword string) (int, error) {
+	// hash first so an unusable password does not burn the token
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+	userID, err := a.consumeToken(ctx, token, userdb.UserTokenPurposePASSWORDRESET)
+	if err != nil {
+		return 0, err
+	}
+	err = a.userRepository.UpdateUserPassword(ctx, userdb.UpdateUserPasswordParams{
+		ID:             userID,
+		HashedPassword: string(hashedPassword),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := a.userRepository.MarkEmailVerified(ctx, userID); err != nil {
+		return 0, err
+	}
+	return int(userID), nil
+}
+
+// issueToken stores a new token for the user, superseding earlier unused ones.
+func (a *AccountService) issueToken(ctx context.Context, userID int32, purpose userdb.UserTokenPurpose, ttl time.Duration) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	err := a.userRepository.InvalidateUserTokens(ctx, userdb.InvalidateUserTokensParams{
+		UserID:  userID,
+		Purpose: purpose,
+	})
+	if err != nil {
+		return "", err
+	}
+	_, err = a.userRepository.CreateUserToken(ctx, userdb.CreateUserTokenParams{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashUserToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeToken marks a valid token as used and returns its user.
+func (a *AccountService) consumeToken(ctx context.Context, token string, purpose userdb.UserTokenPurpose) (int32, error) {
+	userID, err := a.userRepository.ConsumeUserToken(ctx, userdb.ConsumeUserTokenParams{
+		TokenHash: hashUserToken(token),
+		Purpose:   purpose,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, customErrors.ErrInvalidAccountToken
+	}
+	if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+func (a *AccountService) link(path string, token string) string {
+	return a.params.BaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
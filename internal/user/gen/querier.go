@@ -9,12 +9,42 @@ import (
 )
 
 type Querier interface {
+	ConfirmUserTOTP(ctx context.Context, arg ConfirmUserTOTPParams) (int64, error)
+	ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (int32, error)
+	// projects the user owns that other members would lose with the account
+	CountSharedOwnedProjects(ctx context.Context, userID int32) (int64, error)
+	CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (PersonalAccessToken, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
+	CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error)
+	DeleteRecoveryCodes(ctx context.Context, userID int32) error
 	DeleteUser(ctx context.Context, id int32) error
+	DeleteUserTOTP(ctx context.Context, userID int32) error
+	GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (GetPersonalAccessTokenByHashRow, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserById(ctx context.Context, id int32) (User, error)
+	GetUserByIdentity(ctx context.Context, arg GetUserByIdentityParams) (User, error)
 	GetUserByName(ctx context.Context, name string) (User, error)
+	GetUserTOTP(ctx context.Context, userID int32) (UserTotp, error)
+	// a newly mailed token supersedes every earlier unused token of the same purpose
+	InvalidateUserTokens(ctx context.Context, arg InvalidateUserTokensParams) error
+	ListPersonalAccessTokensByUser(ctx context.Context, userID int32) ([]PersonalAccessToken, error)
 	ListUsers(ctx context.Context) ([]User, error)
+	MarkEmailVerified(ctx context.Context, id int32) error
+	RevokePersonalAccessToken(ctx context.Context, arg RevokePersonalAccessTokenParams) (int64, error)
+	// only enabled users who share an organization or a project with the viewer
+	SearchUsers(ctx context.Context, arg SearchUsersParams) ([]SearchUsersRow, error)
+	// last_used_at is only refreshed once a minute to avoid a write on every request
+	TouchPersonalAccessToken(ctx context.Context, id int64) error
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	// a changed email address has to be verified again
+	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
+	// re-enrolling replaces a pending secret but never one that is already confirmed
+	UpsertUserTOTP(ctx context.Context, arg UpsertUserTOTPParams) (UserTotp, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	// only a time step newer than the last accepted one is accepted, so each code works once
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
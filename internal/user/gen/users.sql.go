@@ -7,10 +7,116 @@ package userdb
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+const confirmUserTOTP = `-- name: ConfirmUserTOTP :execrows
+UPDATE user_totp SET confirmed_at=now(), last_used_step=$2 WHERE user_id=$1 AND confirmed_at IS NULL
+`
+
+type ConfirmUserTOTPParams struct {
+	UserID       int32 `json:"user_id"`
+	LastUsedStep int64 `json:"last_used_step"`
+}
+
+func (q *Queries) ConfirmUserTOTP(ctx context.Context, arg ConfirmUserTOTPParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, confirmUserTOTP, arg.UserID, arg.LastUsedStep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const consumeUserToken = `-- name: ConsumeUserToken :one
+UPDATE user_tokens SET used_at=now()
+WHERE token_hash=$1 AND purpose=$2 AND used_at IS NULL AND expires_at > now()
+RETURNING user_id
+`
+
+type ConsumeUserTokenParams struct {
+	TokenHash string           `json:"token_hash"`
+	Purpose   UserTokenPurpose `json:"purpose"`
+}
+
+func (q *Queries) ConsumeUserToken(ctx context.Context, arg ConsumeUserTokenParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, consumeUserToken, arg.TokenHash, arg.Purpose)
+	var user_id int32
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const countSharedOwnedProjects = `-- name: CountSharedOwnedProjects :one
+SELECT COUNT(DISTINCT p.id) FROM projects p
+JOIN project_members m ON m.project_id = p.id
+WHERE p.user_id=$1 AND m.user_id <> $1
+`
+
+// projects the user owns that other members would lose with the account
+func (q *Queries) CountSharedOwnedProjects(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSharedOwnedProjects, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createPersonalAccessToken = `-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens(user_id,name,token_hash,token_prefix,scopes,expires_at)
+VALUES ($1,$2,$3,$4,$5,$6) RETURNING id, user_id, name, token_hash, token_prefix, scopes, expires_at, last_used_at, revoked_at, created_at
+`
+
+type CreatePersonalAccessTokenParams struct {
+	UserID      int32        `json:"user_id"`
+	Name        string       `json:"name"`
+	TokenHash   string       `json:"token_hash"`
+	TokenPrefix string       `json:"token_prefix"`
+	Scopes      []string     `json:"scopes"`
+	ExpiresAt   sql.NullTime `json:"expires_at"`
+}
+
+func (q *Queries) CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (PersonalAccessToken, error) {
+	row := q.db.QueryRowContext(ctx, createPersonalAccessToken,
+		arg.UserID,
+		arg.Name,
+		arg.TokenHash,
+		arg.TokenPrefix,
+		pq.Array(arg.Scopes),
+		arg.ExpiresAt,
+	)
+	var i PersonalAccessToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenHash,
+		&i.TokenPrefix,
+		pq.Array(&i.Scopes),
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :exec
+INSERT INTO user_recovery_codes(user_id,code_hash) VALUES ($1,$2)
+`
+
+type CreateRecoveryCodeParams struct {
+	UserID   int32  `json:"user_id"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error {
+	_, err := q.db.ExecContext(ctx, createRecoveryCode, arg.UserID, arg.CodeHash)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users(name,hashed_password,email) VALUES ($1,$2,$3) RETURNING id, email, name, hashed_password, created_at
+INSERT INTO users(name,hashed_password,email) VALUES ($1,$2,$3) RETURNING id, email, name, hashed_password, created_at, email_verified_at, is_admin, disabled_at
 `
 
 type CreateUserParams struct {
@@ -28,10 +134,83 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Name,
 		&i.HashedPassword,
 		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const createUserIdentity = `-- name: CreateUserIdentity :one
+INSERT INTO user_identities(user_id,issuer,subject,email) VALUES ($1,$2,$3,$4) RETURNING id, user_id, issuer, subject, email, created_at
+`
+
+type CreateUserIdentityParams struct {
+	UserID  int32  `json:"user_id"`
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+	Email   string `json:"email"`
+}
+
+func (q *Queries) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRowContext(ctx, createUserIdentity,
+		arg.UserID,
+		arg.Issuer,
+		arg.Subject,
+		arg.Email,
+	)
+	var i UserIdentity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Issuer,
+		&i.Subject,
+		&i.Email,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
+const createUserToken = `-- name: CreateUserToken :one
+INSERT INTO user_tokens(user_id,purpose,token_hash,expires_at) VALUES ($1,$2,$3,$4) RETURNING id, user_id, purpose, token_hash, expires_at, used_at, created_at
+`
+
+type CreateUserTokenParams struct {
+	UserID    int32            `json:"user_id"`
+	Purpose   UserTokenPurpose `json:"purpose"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
+
+func (q *Queries) CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error) {
+	row := q.db.QueryRowContext(ctx, createUserToken,
+		arg.UserID,
+		arg.Purpose,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Purpose,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM user_recovery_codes WHERE user_id=$1
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodes, userID)
+	return err
+}
+
 const deleteUser = `-- name: DeleteUser :exec
 DELETE FROM users WHERE id = $1
 `
@@ -41,8 +220,51 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteUserTOTP = `-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp WHERE user_id=$1
+`
+
+func (q *Queries) DeleteUserTOTP(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTOTP, userID)
+	return err
+}
+
+const getPersonalAccessTokenByHash = `-- name: GetPersonalAccessTokenByHash :one
+SELECT t.id, t.user_id, t.scopes, t.expires_at, t.revoked_at, u.name, u.email, u.disabled_at
+FROM personal_access_tokens t
+JOIN users u ON u.id = t.user_id
+WHERE t.token_hash=$1
+`
+
+type GetPersonalAccessTokenByHashRow struct {
+	ID         int64        `json:"id"`
+	UserID     int32        `json:"user_id"`
+	Scopes     []string     `json:"scopes"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	Name       string       `json:"name"`
+	Email      string       `json:"email"`
+	DisabledAt sql.NullTime `json:"disabled_at"`
+}
+
+func (q *Queries) GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (GetPersonalAccessTokenByHashRow, error) {
+	row := q.db.QueryRowContext(ctx, getPersonalAccessTokenByHash, tokenHash)
+	var i GetPersonalAccessTokenByHashRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		pq.Array(&i.Scopes),
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.Name,
+		&i.Email,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, name, hashed_password, created_at FROM users WHERE email=$1
+SELECT id, email, name, hashed_password, created_at, email_verified_at, is_admin, disabled_at FROM users WHERE email=$1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -54,13 +276,16 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Name,
 		&i.HashedPassword,
 		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
 
-SELECT id, email, name, hashed_password, created_at FROM users WHERE id=$1
+SELECT id, email, name, hashed_password, created_at, email_verified_at, is_admin, disabled_at FROM users WHERE id=$1
 `
 
 func (q *Queries) GetUserById(ctx context.Context, id int32) (User, error) {
@@ -72,12 +297,42 @@ func (q *Queries) GetUserById(ctx context.Context, id int32) (User, error) {
 		&i.Name,
 		&i.HashedPassword,
 		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const getUserByIdentity = `-- name: GetUserByIdentity :one
+SELECT u.id, u.email, u.name, u.hashed_password, u.created_at, u.email_verified_at, u.is_admin, u.disabled_at FROM users u
+JOIN user_identities i ON i.user_id = u.id
+WHERE i.issuer=$1 AND i.subject=$2
+`
+
+type GetUserByIdentityParams struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) GetUserByIdentity(ctx context.Context, arg GetUserByIdentityParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByIdentity, arg.Issuer, arg.Subject)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
 	)
 	return i, err
 }
 
 const getUserByName = `-- name: GetUserByName :one
-SELECT id, email, name, hashed_password, created_at FROM users WHERE name=$1
+SELECT id, email, name, hashed_password, created_at, email_verified_at, is_admin, disabled_at FROM users WHERE name=$1
 `
 
 func (q *Queries) GetUserByName(ctx context.Context, name string) (User, error) {
@@ -89,12 +344,85 @@ func (q *Queries) GetUserByName(ctx context.Context, name string) (User, error)
 		&i.Name,
 		&i.HashedPassword,
 		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
 	)
 	return i, err
 }
 
+const getUserTOTP = `-- name: GetUserTOTP :one
+SELECT user_id, secret, confirmed_at, last_used_step, created_at FROM user_totp WHERE user_id=$1
+`
+
+func (q *Queries) GetUserTOTP(ctx context.Context, userID int32) (UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, getUserTOTP, userID)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const invalidateUserTokens = `-- name: InvalidateUserTokens :exec
+UPDATE user_tokens SET used_at=now() WHERE user_id=$1 AND purpose=$2 AND used_at IS NULL
+`
+
+type InvalidateUserTokensParams struct {
+	UserID  int32            `json:"user_id"`
+	Purpose UserTokenPurpose `json:"purpose"`
+}
+
+// a newly mailed token supersedes every earlier unused token of the same purpose
+func (q *Queries) InvalidateUserTokens(ctx context.Context, arg InvalidateUserTokensParams) error {
+	_, err := q.db.ExecContext(ctx, invalidateUserTokens, arg.UserID, arg.Purpose)
+	return err
+}
+
+const listPersonalAccessTokensByUser = `-- name: ListPersonalAccessTokensByUser :many
+SELECT id, user_id, name, token_hash, token_prefix, scopes, expires_at, last_used_at, revoked_at, created_at FROM personal_access_tokens WHERE user_id=$1 ORDER BY created_at DESC, id DESC
+`
+
+func (q *Queries) ListPersonalAccessTokensByUser(ctx context.Context, userID int32) ([]PersonalAccessToken, error) {
+	rows, err := q.db.QueryContext(ctx, listPersonalAccessTokensByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PersonalAccessToken
+	for rows.Next() {
+		var i PersonalAccessToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.TokenHash,
+			&i.TokenPrefix,
+			pq.Array(&i.Scopes),
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, email, name, hashed_password, created_at FROM users ORDER BY id
+SELECT id, email, name, hashed_password, created_at, email_verified_at, is_admin, disabled_at FROM users ORDER BY id
 `
 
 func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
@@ -112,6 +440,96 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 			&i.Name,
 			&i.HashedPassword,
 			&i.CreatedAt,
+			&i.EmailVerifiedAt,
+			&i.IsAdmin,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markEmailVerified = `-- name: MarkEmailVerified :exec
+UPDATE users SET email_verified_at=now() WHERE id=$1 AND email_verified_at IS NULL
+`
+
+func (q *Queries) MarkEmailVerified(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, markEmailVerified, id)
+	return err
+}
+
+const revokePersonalAccessToken = `-- name: RevokePersonalAccessToken :execrows
+UPDATE personal_access_tokens SET revoked_at=now()
+WHERE id=$1 AND user_id=$2 AND revoked_at IS NULL
+`
+
+type RevokePersonalAccessTokenParams struct {
+	ID     int64 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+func (q *Queries) RevokePersonalAccessToken(ctx context.Context, arg RevokePersonalAccessTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokePersonalAccessToken, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchUsers = `-- name: SearchUsers :many
+SELECT u.id, u.name, u.email FROM users u
+WHERE (lower(u.name) LIKE $1 OR lower(u.email) LIKE $1)
+  AND u.disabled_at IS NULL
+  AND (
+    EXISTS (
+        SELECT 1 FROM organization_members mine
+        JOIN organization_members theirs ON theirs.organization_id = mine.organization_id
+        WHERE mine.user_id = $2 AND theirs.user_id = u.id
+    )
+    OR EXISTS (
+        SELECT 1 FROM project_members mine
+        JOIN project_members theirs ON theirs.project_id = mine.project_id
+        WHERE mine.user_id = $2 AND theirs.user_id = u.id
+    )
+  )
+ORDER BY u.name, u.id
+LIMIT $3
+`
+
+type SearchUsersParams struct {
+	Prefix   string `json:"prefix"`
+	ViewerID int32  `json:"viewer_id"`
+	Limit    int32  `json:"limit"`
+}
+
+type SearchUsersRow struct {
+	ID    int32  `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// only enabled users who share an organization or a project with the viewer
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]SearchUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchUsers, arg.Prefix, arg.ViewerID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchUsersRow
+	for rows.Next() {
+		var i SearchUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
 		); err != nil {
 			return nil, err
 		}
@@ -125,3 +543,122 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 	}
 	return items, nil
 }
+
+const touchPersonalAccessToken = `-- name: TouchPersonalAccessToken :exec
+UPDATE personal_access_tokens SET last_used_at=now()
+WHERE id=$1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+`
+
+// last_used_at is only refreshed once a minute to avoid a write on every request
+func (q *Queries) TouchPersonalAccessToken(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, touchPersonalAccessToken, id)
+	return err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users SET hashed_password=$2 WHERE id=$1
+`
+
+type UpdateUserPasswordParams struct {
+	ID             int32  `json:"id"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.ID, arg.HashedPassword)
+	return err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users SET
+    name=COALESCE($1, name),
+    email=COALESCE($2, email),
+    email_verified_at=CASE WHEN COALESCE($2, email) = email THEN email_verified_at END
+WHERE id=$3
+RETURNING id, email, name, hashed_password, created_at, email_verified_at, is_admin, disabled_at
+`
+
+type UpdateUserProfileParams struct {
+	Name  sql.NullString `json:"name"`
+	Email sql.NullString `json:"email"`
+	ID    int32          `json:"id"`
+}
+
+// a changed email address has to be verified again
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile, arg.Name, arg.Email, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.EmailVerifiedAt,
+		&i.IsAdmin,
+		&i.DisabledAt,
+	)
+	return i, err
+}
+
+const upsertUserTOTP = `-- name: UpsertUserTOTP :one
+INSERT INTO user_totp(user_id,secret) VALUES ($1,$2)
+ON CONFLICT (user_id) DO UPDATE SET secret=EXCLUDED.secret, last_used_step=0, created_at=now()
+WHERE user_totp.confirmed_at IS NULL
+RETURNING user_id, secret, confirmed_at, last_used_step, created_at
+`
+
+type UpsertUserTOTPParams struct {
+	UserID int32  `json:"user_id"`
+	Secret string `json:"secret"`
+}
+
+// re-enrolling replaces a pending secret but never one that is already confirmed
+func (q *Queries) UpsertUserTOTP(ctx context.Context, arg UpsertUserTOTPParams) (UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, upsertUserTOTP, arg.UserID, arg.Secret)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :execrows
+UPDATE user_recovery_codes SET used_at=now() WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL
+`
+
+type UseRecoveryCodeParams struct {
+	UserID   int32  `json:"user_id"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useRecoveryCode, arg.UserID, arg.CodeHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const useTOTPStep = `-- name: UseTOTPStep :execrows
+UPDATE user_totp SET last_used_step=$2
+WHERE user_id=$1 AND confirmed_at IS NOT NULL AND last_used_step < $2
+`
+
+type UseTOTPStepParams struct {
+	UserID       int32 `json:"user_id"`
+	LastUsedStep int64 `json:"last_used_step"`
+}
+
+// only a time step newer than the last accepted one is accepted, so each code works once
+func (q *Queries) UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, useTOTPStep, arg.UserID, arg.LastUsedStep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -2,22 +2,27 @@ package user
 
 import (
 	"context"
+	goerrors "errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Gkemhcs/taskpilot/internal/auth"
 	"github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/middleware"
 
 	"github.com/Gkemhcs/taskpilot/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
-func NewUserHandler(userService IUserService, logger *logrus.Logger, jwtManager *auth.JWTManager) *UserHandler {
+func NewUserHandler(userService IUserService, accountService IAccountService, logger *logrus.Logger, jwtManager *auth.JWTManager, loginGuard *auth.LoginGuard) *UserHandler {
 	return &UserHandler{
-		logger:      logger,
-		userService: userService,
-		jwtManager:  jwtManager,
+		logger:         logger,
+		userService:    userService,
+		accountService: accountService,
+		jwtManager:     jwtManager,
+		loginGuard:     loginGuard,
 	}
 
 }
@@ -27,14 +32,44 @@ func RegisterRoutes(rg *gin.RouterGroup, handler *UserHandler) {
 	{
 		userGroup.POST("/", handler.CreateUser)
 		userGroup.POST("/login", handler.LoginUser)
+		userGroup.POST("/login/mfa", handler.LoginUserMFA)
 		userGroup.POST("/refresh", handler.GenerateAccessTokenFromRefreshToken)
+		userGroup.POST("/logout", middleware.JWTAuthMiddleware(handler.logger, handler.jwtManager), middleware.RequireSession(), handler.LogoutUser)
+		userGroup.POST("/password/forgot", handler.ForgotPassword)
+		userGroup.POST("/password/reset", handler.ResetPassword)
+		userGroup.POST("/verify", handler.VerifyEmail)
+		userGroup.POST("/verify/resend", handler.ResendVerificationEmail)
+	}
+	// personal access tokens can only be managed from a login session, never with another token
+	tokenGroup := userGroup.Group("/tokens", middleware.JWTAuthMiddleware(handler.logger, handler.jwtManager), middleware.RequireSession())
+	{
+		tokenGroup.POST("/", handler.CreatePersonalAccessToken)
+		tokenGroup.GET("/", handler.ListPersonalAccessTokens)
+		tokenGroup.DELETE("/:id", handler.RevokePersonalAccessToken)
+	}
+	// the account itself can only be managed from a login session
+	meGroup := userGroup.Group("/me", middleware.JWTAuthMiddleware(handler.logger, handler.jwtManager), middleware.RequireSession())
+	{
+		meGroup.GET("", handler.GetProfile)
+		meGroup.PATCH("", handler.UpdateProfile)
+		meGroup.DELETE("", handler.DeleteAccount)
+		meGroup.POST("/password", handler.ChangePassword)
+	}
+	userGroup.GET("/search", middleware.JWTAuthMiddleware(handler.logger, handler.jwtManager), middleware.RequireSession(), handler.SearchUsers)
+	mfaGroup := userGroup.Group("/mfa/totp", middleware.JWTAuthMiddleware(handler.logger, handler.jwtManager), middleware.RequireSession())
+	{
+		mfaGroup.POST("/enroll", handler.EnrollTOTP)
+		mfaGroup.POST("/confirm", handler.ConfirmTOTP)
+		mfaGroup.POST("/disable", handler.DisableTOTP)
 	}
 }
 
 type UserHandler struct {
-	logger      *logrus.Logger
-	userService IUserService
-	jwtManager  *auth.JWTManager
+	logger         *logrus.Logger
+	userService    IUserService
+	accountService IAccountService
+	jwtManager     *auth.JWTManager
+	loginGuard     *auth.LoginGuard // Throttles password and second factor guessing, nil disables it
 }
 
 // CreateUser handles user registration
@@ -84,6 +119,10 @@ func (u *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 	u.logger.Infof("user creation successful")
+	// a failed email must not fail the signup, the user can ask for a new one
+	if err := u.accountService.SendEmailVerification(ctx, user.Email); err != nil {
+		u.logger.Errorf("unable to send verification email %v", err)
+	}
 	utils.Success(c, http.StatusCreated, map[string]interface{}{
 		"message":     "user created",
 		"status_code": http.StatusCreated,
@@ -92,13 +131,15 @@ func (u *UserHandler) CreateUser(c *gin.Context) {
 
 // LoginUser handles user login
 // @Summary      Login user
-// @Description  Authenticates a user and returns JWT tokens
+// @Description  Authenticates a user and returns JWT tokens. Accounts with two-factor authentication get mfa_required and an mfa_token to complete at /users/login/mfa instead.
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        user  body      User true  "User login input"
 // @Success      200   {object}  map[string]interface{}
 // @Failure      400   {object}  utils.ErrorResponse
+// @Failure      423   {object}  utils.ErrorResponse
+// @Failure      429   {object}  utils.ErrorResponse
 // @Failure      500   {object}  utils.ErrorResponse
 // @Router       /api/v1/users/login [post]
 
@@ -127,18 +168,37 @@ func (u *UserHandler) LoginUser(c *gin.Context) {
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
+	if !u.checkLoginGuard(ctx, c, user.Email) {
+		return
+	}
 	userInfo, err := u.userService.LoginUser(ctx, user.Email, user.Password)
 	if err != nil {
 		u.logger.Errorf("unable to login  %v", err)
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		if goerrors.Is(err, errors.ErrMismatchedPassword) || goerrors.Is(err, errors.USER_NOT_FOUND) {
+			u.recordLoginFailure(ctx, user.Email, c.ClientIP(), auth.LoginFailureInvalidCredentials)
+		}
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 
 	}
-	jwtTokenResponse, err := u.jwtManager.Generate(int(userInfo.ID), userInfo.Name, userInfo.Email)
+	if err := u.loginGuard.RecordSuccess(ctx, user.Email); err != nil {
+		u.logger.Errorf("unable to reset failed logins of %s %v", user.Email, err)
+	}
+	mfaEnabled, err := u.userService.IsMFAEnabled(ctx, int(userInfo.ID))
+	if err != nil {
+		u.logger.Errorf("unable to check two-factor authentication %v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if mfaEnabled {
+		u.issueMFAChallenge(c, int(userInfo.ID))
+		return
+	}
+	jwtTokenResponse, err := u.jwtManager.Generate(ctx, int(userInfo.ID), userInfo.Name, userInfo.Email)
 	if err != nil {
 		u.logger.Errorf("error while generating the token %v", err)
 
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
 		return
 	}
 	u.logger.Infof("%s logged in and token generated successfully", user.Email)
@@ -151,15 +211,16 @@ func (u *UserHandler) LoginUser(c *gin.Context) {
 
 }
 
-// GenerateAccessTokenFromRefreshToken generates a new access token from a refresh token
+// GenerateAccessTokenFromRefreshToken rotates a refresh token into a new token pair
 // @Summary      Generate access token from refresh token
-// @Description  Generates a new access token using a valid refresh token
+// @Description  Exchanges a refresh token for a new access token and a new refresh token. Each refresh token can be used only once; reusing one revokes the whole session.
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        refreshToken  body      RefreshRequest true  "Refresh token input"
 // @Success      200   {object}  map[string]interface{}
 // @Failure      400   {object}  utils.ErrorResponse
+// @Failure      401   {object}  utils.ErrorResponse
 // @Failure      500   {object}  utils.ErrorResponse
 // @Router       /api/v1/users/refresh [post]
 
@@ -171,14 +232,205 @@ func (u *UserHandler) GenerateAccessTokenFromRefreshToken(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
 		return
 	}
-	accessToken, err := u.jwtManager.GenerateAccessTokenFromRefresh(request.RefreshToken)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	tokens, err := u.jwtManager.RotateRefreshToken(ctx, request.RefreshToken)
+	if goerrors.Is(err, errors.ErrRefreshTokenReused) || goerrors.Is(err, errors.ErrRefreshTokenRevoked) {
+		u.logger.Warnf("rejected refresh token: %v", err)
+		utils.Error(c, http.StatusUnauthorized, err.Error())
+		return
+	}
 	if err != nil {
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+
+		"token":  tokens.AccessToken,
+		"tokens": tokens,
+	})
+
+}
+
+// LogoutUser revokes the caller's session
+// @Summary      Logout user
+// @Description  Revokes the current access token and its refresh token family. Optionally pass the refresh token to revoke it explicitly.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        refreshToken  body      RefreshRequest false  "Refresh token to revoke"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  utils.ErrorResponse
+// @Failure      401   {object}  utils.ErrorResponse
+// @Failure      500   {object}  utils.ErrorResponse
+// @Router       /api/v1/users/logout [post]
+// @Security BearerAuth
+func (u *UserHandler) LogoutUser(c *gin.Context) {
+	var request RefreshRequest
+	// the body is optional, an empty one simply revokes the current session
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+			return
+		}
+	}
+	val, exists := c.Get("claims")
+	if !exists {
+		u.logger.Errorf("%v", errors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, errors.ErrUserIDNotFoundInContext.Error())
+		return
+	}
+	claims, ok := val.(*auth.UserClaims)
+	if !ok {
+		u.logger.Errorf("%v", errors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, errors.ErrInvalidUserId.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := u.jwtManager.Revoke(ctx, claims, request.RefreshToken); err != nil {
+		u.logger.Errorf("unable to logout %v", err)
 		utils.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
+	u.logger.Infof("user %d logged out", claims.UserID)
 	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "logged out successfully",
+	})
+}
+
+// CreatePersonalAccessToken issues a scoped personal access token for scripts and CI
+// @Summary      Create personal access token
+// @Description  Issues a long-lived token restricted to the given scopes (projects, tasks, import, export with :read or :write). The token is only returned once.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        token  body      CreatePersonalAccessTokenRequest true  "Token name, scopes and optional expiry"
+// @Success      201    {object}  CreatePersonalAccessTokenResponse
+// @Failure      400    {object}  utils.ErrorResponse
+// @Failure      401    {object}  utils.ErrorResponse
+// @Failure      403    {object}  utils.ErrorResponse
+// @Router       /api/v1/users/tokens/ [post]
+// @Security BearerAuth
+func (u *UserHandler) CreatePersonalAccessToken(c *gin.Context) {
+	var request CreatePersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		u.logger.Errorf("incorrect request body %v", err)
+		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	val, exists := c.Get("userID")
+	if !exists {
+		u.logger.Errorf("%v", errors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+	userID, ok := val.(int)
+	if !ok {
+		u.logger.Errorf("%v", errors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	token, created, err := u.userService.CreatePersonalAccessToken(ctx, userID, request.Name, request.Scopes, request.ExpiresAt)
+	if err != nil {
+		u.logger.Errorf("unable to create personal access token %v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	u.logger.Infof("user %d created personal access token %d", userID, created.ID)
+	utils.Success(c, http.StatusCreated, map[string]any{
+		"data": CreatePersonalAccessTokenResponse{
+			Token:                       token,
+			PersonalAccessTokenResponse: NewPersonalAccessTokenResponse(*created),
+		},
+		"message": "store this token now, it will not be shown again",
+	})
+}
 
-		"token": accessToken,
+// ListPersonalAccessTokens lists the caller's personal access tokens
+// @Summary      List personal access tokens
+// @Description  Lists the caller's personal access tokens, including revoked and expired ones. Secrets are never returned.
+// @Tags         users
+// @Produce      json
+// @Success      200  {array}   PersonalAccessTokenResponse
+// @Failure      401  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      500  {object}  utils.ErrorResponse
+// @Router       /api/v1/users/tokens/ [get]
+// @Security BearerAuth
+func (u *UserHandler) ListPersonalAccessTokens(c *gin.Context) {
+	val, exists := c.Get("userID")
+	if !exists {
+		u.logger.Errorf("%v", errors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+	userID, ok := val.(int)
+	if !ok {
+		u.logger.Errorf("%v", errors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	tokens, err := u.userService.ListPersonalAccessTokens(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("unable to list personal access tokens %v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response := make([]PersonalAccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		response = append(response, NewPersonalAccessTokenResponse(token))
+	}
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data": response,
 	})
+}
 
+// RevokePersonalAccessToken revokes one of the caller's personal access tokens
+// @Summary      Revoke personal access token
+// @Description  Revokes a personal access token immediately
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int  true  "Token ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  utils.ErrorResponse
+// @Failure      401  {object}  utils.ErrorResponse
+// @Failure      403  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/users/tokens/{id} [delete]
+// @Security BearerAuth
+func (u *UserHandler) RevokePersonalAccessToken(c *gin.Context) {
+	tokenID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		u.logger.Errorf("%v", errors.ErrInvalidTokenID)
+		utils.Error(c, http.StatusBadRequest, errors.ErrInvalidTokenID.Error())
+		return
+	}
+	val, exists := c.Get("userID")
+	if !exists {
+		u.logger.Errorf("%v", errors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return
+	}
+	userID, ok := val.(int)
+	if !ok {
+		u.logger.Errorf("%v", errors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := u.userService.RevokePersonalAccessToken(ctx, userID, tokenID); err != nil {
+		u.logger.Errorf("unable to revoke personal access token %v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	u.logger.Infof("user %d revoked personal access token %d", userID, tokenID)
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "personal access token revoked",
+	})
 }
@@ -2,371 +2,364 @@ package user
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/base32"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Gkemhcs/taskpilot/internal/auth"
 
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/mailer"
+	"github.com/Gkemhcs/taskpilot/internal/oidc"
+	"github.com/Gkemhcs/taskpilot/internal/oidc/oidctest"
 	userdb "github.com/Gkemhcs/taskpilot/internal/user/gen"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
-func SetupNewUserHandler()(*UserHandler,*MockUserRepo){
+func SetupNewUserHandler() (*UserHandler, *MockUserRepo) {
 
-	
-	mockUserRepo:=new(MockUserRepo)
-	userService:=NewUserService(mockUserRepo)
+	mockUserRepo := new(MockUserRepo)
+	userService := NewUserService(mockUserRepo)
 
 	logger := logrus.New()
 	logger.SetOutput(io.Discard) // Avoid
-	
-	params:=auth.CreateJwtManagerParams{
-		AccessTokenDuration: 10*time.Minute,
-		RefreshTokenDuration: 10*time.Hour,
-		AccessTokenKey: "qkaniqifiqfi",
-		RefreshTokenKey: "fewnfewfnifnif",
 
+	params := auth.CreateJwtManagerParams{
+		AccessTokenDuration:  10 * time.Minute,
+		RefreshTokenDuration: 10 * time.Hour,
+		AccessTokenKey:       "qkaniqifiqfi",
+		RefreshTokenKey:      "fewnfewfnifnif",
+		PersonalAccessTokens: userService,
 	}
-	jwtManager:=auth.NewJWTManager(params)
-	userHandler:=NewUserHandler(userService,logger,jwtManager)
-	return userHandler,mockUserRepo
+	jwtManager := auth.NewJWTManager(params)
+	accountService := NewAccountService(mockUserRepo, mailer.NewLogMailer(logger), AccountServiceParams{
+		BaseURL:                   "http://localhost:8080",
+		PasswordResetTokenTTL:     time.Hour,
+		EmailVerificationTokenTTL: 48 * time.Hour,
+	})
+	userHandler := NewUserHandler(userService, accountService, logger, jwtManager, nil)
+	return userHandler, mockUserRepo
 
-	
 }
 
 func TestCreateUserHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	handler,mockRepo:=SetupNewUserHandler()
+	handler, mockRepo := SetupNewUserHandler()
 
-	testCases:=[]struct{
-		testName string
-		requestBody map[string]string 
-		mockSetup func(string)
-		expectedServiceCall bool 
-		expectedError error 
+	testCases := []struct {
+		testName            string
+		requestBody         map[string]string
+		mockSetup           func(string)
+		expectedServiceCall bool
+		expectedError       error
 	}{
 		{
-			testName:"Valid User",
-			requestBody :map[string]string{
-				"name":"gkemhcs",
-				"password":"dwfnie2e",
-				"email":"gudi@gmail",
+			testName: "Valid User",
+			requestBody: map[string]string{
+				"name":     "gkemhcs",
+				"password": "dwfnie2e",
+				"email":    "gudi@gmail",
 			},
-			mockSetup: func(password string ){
-				hashPassword:=getHashedPassword(password)
-				
-				mockRepo.On("CreateUser",mock.Anything,mock.Anything).Return(
-					userdb.User{
-						Name:"gkemhcs",
-						HashedPassword:hashPassword ,
-						Email: "gudi@gmail",
+			mockSetup: func(password string) {
+				hashPassword := getHashedPassword(password)
 
-					},nil,
+				mockRepo.On("CreateUser", mock.Anything, mock.Anything).Return(
+					userdb.User{
+						Name:           "gkemhcs",
+						HashedPassword: hashPassword,
+						Email:          "gudi@gmail",
+					}, nil,
+				)
+				mockRepo.On("GetUserByEmail", mock.Anything, "gudi@gmail").Return(
+					userdb.User{ID: 1, Name: "gkemhcs", Email: "gudi@gmail"}, nil,
 				)
+				mockRepo.On("InvalidateUserTokens", mock.Anything, mock.Anything).Return(nil)
+				mockRepo.On("CreateUserToken", mock.Anything, mock.Anything).Return(userdb.UserToken{}, nil)
 			},
-			expectedServiceCall: true ,
-			expectedError: nil,
+			expectedServiceCall: true,
+			expectedError:       nil,
 		},
 		{
 			testName: "Missing email",
 			requestBody: map[string]string{
-				"name":"gkemhcs",
-				"password":"welcome1234",
+				"name":     "gkemhcs",
+				"password": "welcome1234",
 			},
-			mockSetup: func(password string){
+			mockSetup: func(password string) {
 
 			},
 			expectedServiceCall: false,
-			expectedError: customErrors.ErrMissingEmail,
-		},{
+			expectedError:       customErrors.ErrMissingEmail,
+		}, {
 			testName: "missing username",
 			requestBody: map[string]string{
-				"email":"gudik@gmail",
-				"password":"e2ueh2i",
+				"email":    "gudik@gmail",
+				"password": "e2ueh2i",
 			},
-			mockSetup: func(password string ){},
+			mockSetup:           func(password string) {},
 			expectedServiceCall: false,
-			expectedError: customErrors.MISSING_USER_NAME,
-		},{
+			expectedError:       customErrors.MISSING_USER_NAME,
+		}, {
 			testName: "missing password",
 			requestBody: map[string]string{
-				"email":"gud@gmail",
-				"name": "koti",
+				"email": "gud@gmail",
+				"name":  "koti",
 			},
-			mockSetup: func(password string){},
+			mockSetup:           func(password string) {},
 			expectedServiceCall: false,
-			expectedError: customErrors.MISSING_PASSWORD,
+			expectedError:       customErrors.MISSING_PASSWORD,
 		},
-
 	}
-		for _ ,tc := range  testCases{
-
-
-			t.Run(tc.testName,func(t *testing.T){
-				mockRepo.Calls=nil
-				mockRepo.ExpectedCalls=nil
-				password:="efienfinfi"
-				pass,ok:=tc.requestBody["password"]
-				if ok{
-					password=pass
-				}
-				tc.mockSetup(password)
-				body, _ := json.Marshal(tc.requestBody)
-
-				req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/", bytes.NewReader(body))
-				req.Header.Set("Content-Type", "application/json")
-				w := httptest.NewRecorder()
-
-				// Set up full group routing like production
-				r := gin.Default()
-				apiGroup := r.Group("/api/v1")
-				userGroup := apiGroup.Group("/users")
-				userGroup.POST("/", handler.CreateUser)
-
-				r.ServeHTTP(w, req)
-				if tc.expectedError!=nil{
-					assert.Error(t,tc.expectedError,w.Code)
-				}else{
-					assert.Equal(t,http.StatusCreated,w.Code)
-				}
-
-
-				if tc.expectedServiceCall{
-					mockRepo.AssertCalled(t,"CreateUser",mock.Anything,mock.Anything)
-
-				}else{
-					mockRepo.AssertNotCalled(t,"CreateUser",mock.Anything,mock.Anything)
+	for _, tc := range testCases {
+
+		t.Run(tc.testName, func(t *testing.T) {
+			mockRepo.Calls = nil
+			mockRepo.ExpectedCalls = nil
+			password := "efienfinfi"
+			pass, ok := tc.requestBody["password"]
+			if ok {
+				password = pass
+			}
+			tc.mockSetup(password)
+			body, _ := json.Marshal(tc.requestBody)
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
 
-				}
+			// Set up full group routing like production
+			r := gin.Default()
+			apiGroup := r.Group("/api/v1")
+			userGroup := apiGroup.Group("/users")
+			userGroup.POST("/", handler.CreateUser)
 
+			r.ServeHTTP(w, req)
+			if tc.expectedError != nil {
+				assert.Error(t, tc.expectedError, w.Code)
+			} else {
+				assert.Equal(t, http.StatusCreated, w.Code)
+			}
 
-			})
-		}
-	
+			if tc.expectedServiceCall {
+				mockRepo.AssertCalled(t, "CreateUser", mock.Anything, mock.Anything)
+				mockRepo.AssertCalled(t, "CreateUserToken", mock.Anything, mock.Anything)
 
+			} else {
+				mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
 
+			}
 
+		})
+	}
 
 }
 
+func TestLoginHandler(t *testing.T) {
 
+	handler, mockRepo := SetupNewUserHandler()
 
-
-
-func TestLoginHandler(t *testing.T){
-
-	handler,mockRepo:=SetupNewUserHandler()
-
-
-	testCases:=[]struct{
-		testName string
-		requestBody map[string]string 
-		mockSetup func(string)
-		expectedServiceCall bool 
-		expectedError error 
+	testCases := []struct {
+		testName            string
+		requestBody         map[string]string
+		mockSetup           func(string)
+		expectedServiceCall bool
+		expectedError       error
 	}{
 		{
 			testName: "Valid User",
 			requestBody: map[string]string{
-				"name": "koti",
-				"email" : "gudi@gmail",
+				"name":     "koti",
+				"email":    "gudi@gmail",
 				"password": "gkem23",
 			},
 			mockSetup: func(password string) {
-				mockRepo.On("GetUserByEmail",mock.Anything,"gudi@gmail").Return(
+				mockRepo.On("GetUserByEmail", mock.Anything, "gudi@gmail").Return(
 					userdb.User{
-						Name: "koti",
-						Email: "gudi@gmail",
+						Name:           "koti",
+						Email:          "gudi@gmail",
 						HashedPassword: getHashedPassword(password),
-					},nil)
+					}, nil)
+				mockRepo.On("GetUserTOTP", mock.Anything, int32(0)).Return(userdb.UserTotp{}, sql.ErrNoRows)
 			},
-			expectedError: nil ,
-			expectedServiceCall: true,			
+			expectedError:       nil,
+			expectedServiceCall: true,
 		},
 		{
 			testName: "Missing User Name",
 			requestBody: map[string]string{
-				"email":"gudi@gmail",
-				"password":"gkem1",
+				"email":    "gudi@gmail",
+				"password": "gkem1",
 			},
-			mockSetup: func(password string){
+			mockSetup: func(password string) {
 
 			},
 			expectedServiceCall: false,
-			expectedError: customErrors.MISSING_USER_NAME,
-		},{
-			testName:"Missing Email",
+			expectedError:       customErrors.MISSING_USER_NAME,
+		}, {
+			testName: "Missing Email",
 			requestBody: map[string]string{
-				"name":"gkemhcs",
-				"password":"gkem1234",
+				"name":     "gkemhcs",
+				"password": "gkem1234",
 			},
-			mockSetup: func(password string){},
+			mockSetup:           func(password string) {},
 			expectedServiceCall: false,
-			expectedError: customErrors.ErrMissingEmail,
+			expectedError:       customErrors.ErrMissingEmail,
 		},
 		{
-			testName:"Missing Password",
+			testName: "Missing Password",
 			requestBody: map[string]string{
-				"name":"gkemhcs",
-				"email":"gudi@gmail",
+				"name":  "gkemhcs",
+				"email": "gudi@gmail",
 			},
-			mockSetup: func(password string){},
+			mockSetup:           func(password string) {},
 			expectedServiceCall: false,
-			expectedError: customErrors.MISSING_PASSWORD,
+			expectedError:       customErrors.MISSING_PASSWORD,
 		},
 		{
-			testName:"Non Existent User",
+			testName: "Non Existent User",
 			requestBody: map[string]string{
-				"name":"gkemhcs",
-				"email":"gudi@gmail",
-				"password":"gkem3u939",
+				"name":     "gkemhcs",
+				"email":    "gudi@gmail",
+				"password": "gkem3u939",
 			},
-			mockSetup: func(password string){
-				mockRepo.On("GetUserByEmail",mock.Anything,"gudi@gmail").Return(
-					userdb.User{},customErrors.USER_NOT_FOUND,
+			mockSetup: func(password string) {
+				mockRepo.On("GetUserByEmail", mock.Anything, "gudi@gmail").Return(
+					userdb.User{}, customErrors.USER_NOT_FOUND,
 				)
 			},
 			expectedServiceCall: true,
-			expectedError: customErrors.USER_NOT_FOUND,
+			expectedError:       customErrors.USER_NOT_FOUND,
 		},
 		{
-			testName:"Incorrect password",
+			testName: "Incorrect password",
 			requestBody: map[string]string{
-				"name":"gkemhcs",
-				"email":"gudi@gmail",
-				"password":"gkem3u939",
+				"name":     "gkemhcs",
+				"email":    "gudi@gmail",
+				"password": "gkem3u939",
 			},
-			mockSetup: func(password string){
-				mockRepo.On("GetUserByEmail",mock.Anything,"gudi@gmail").Return(
+			mockSetup: func(password string) {
+				mockRepo.On("GetUserByEmail", mock.Anything, "gudi@gmail").Return(
 					userdb.User{
-						Name:"gkemhcs",
-						Email:"gudi@gmail",
+						Name:           "gkemhcs",
+						Email:          "gudi@gmail",
 						HashedPassword: getHashedPassword("wrongpassword"),
-					},nil,
+					}, nil,
 				)
 			},
 			expectedServiceCall: true,
-			expectedError:customErrors.ErrMismatchedPassword,
-			
+			expectedError:       customErrors.ErrMismatchedPassword,
 		},
 		{
-			testName:"db connection failed",
+			testName: "db connection failed",
 			requestBody: map[string]string{
-				"name":"gkemhcs",
-				"email":"gudi@gmail",
-				"password":"gkem3u939",
+				"name":     "gkemhcs",
+				"email":    "gudi@gmail",
+				"password": "gkem3u939",
 			},
-			mockSetup: func(password string){
-				mockRepo.On("GetUserByEmail",mock.Anything,"gudi@gmail").Return(
-					userdb.User{},errors.New("db error"),
+			mockSetup: func(password string) {
+				mockRepo.On("GetUserByEmail", mock.Anything, "gudi@gmail").Return(
+					userdb.User{}, errors.New("db error"),
 				)
 			},
 			expectedServiceCall: true,
-			expectedError:errors.New("db error"),
-			
+			expectedError:       errors.New("db error"),
 		},
 	}
 
-	for _,tc := range testCases{
-			t.Run(tc.testName,func(t *testing.T){
-
-				mockRepo.ExpectedCalls = nil
-				mockRepo.Calls = nil
-				password:="jooojo"
-				pass,ok:=tc.requestBody["password"]
-				if ok {
-					password=pass
-				}
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
 
+			mockRepo.ExpectedCalls = nil
+			mockRepo.Calls = nil
+			password := "jooojo"
+			pass, ok := tc.requestBody["password"]
+			if ok {
+				password = pass
+			}
 
-				tc.mockSetup(password)
-				body,_:=json.Marshal(tc.requestBody)
-			
-				req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/login", bytes.NewReader(body))
-				req.Header.Set("Content-Type", "application/json")
-				w := httptest.NewRecorder()
+			tc.mockSetup(password)
+			body, _ := json.Marshal(tc.requestBody)
 
-				// Set up full group routing like production
-				r := gin.Default()
-				apiGroup := r.Group("/api/v1")
-				userGroup := apiGroup.Group("/users")
-				userGroup.POST("/login", handler.LoginUser)
-
-				r.ServeHTTP(w, req)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/login", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
 
-			 
-				if tc.expectedError!=nil{
-					assert.Error(t,tc.expectedError,w.Code)
+			// Set up full group routing like production
+			r := gin.Default()
+			apiGroup := r.Group("/api/v1")
+			userGroup := apiGroup.Group("/users")
+			userGroup.POST("/login", handler.LoginUser)
 
-				}else{
-						assert.Equal(t, http.StatusOK, w.Code)
-				}
-				
-				
-			
+			r.ServeHTTP(w, req)
 
+			if tc.expectedError != nil {
+				assert.Error(t, tc.expectedError, w.Code)
 
+			} else {
+				assert.Equal(t, http.StatusOK, w.Code)
+			}
 
-				if tc.expectedServiceCall{
-					mockRepo.AssertCalled(t,"GetUserByEmail",mock.Anything,tc.requestBody["email"])
-				}else{
-					mockRepo.AssertNotCalled(t,"GetUserByEmail",mock.Anything,tc.requestBody["email"])
-				}
-				
+			if tc.expectedServiceCall {
+				mockRepo.AssertCalled(t, "GetUserByEmail", mock.Anything, tc.requestBody["email"])
+			} else {
+				mockRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, tc.requestBody["email"])
+			}
 
-			})
+		})
 	}
 
-
-
 }
 
+func TestRefreshHandler(t *testing.T) {
+	handler, _ := SetupNewUserHandler()
+	tokenResponse, _ := handler.jwtManager.Generate(context.TODO(), 123, "koti", "eswar@gmail") // to generate access token and refresh token first
 
-func TestRefreshHandler(t *testing.T){
-	handler,_:=SetupNewUserHandler()
-	tokenResponse,_:=handler.jwtManager.Generate(123,"koti","eswar@gmail") // to generate access token and refresh token first
+	testCases := []struct {
+		testName    string
+		requestBody map[string]string
 
-	testCases:=[]struct{
-		testName string 
-		requestBody map[string]string 		
-		
 		expectedStatus int
-
 	}{
 		{
 			testName: "Valid Refresh Token",
 			requestBody: map[string]string{
-				"token":tokenResponse.RefreshToken,
+				"token": tokenResponse.RefreshToken,
 			},
 
 			expectedStatus: http.StatusOK,
 		},
 		{
 			testName: "Invalid Refresh token",
-			requestBody:map[string]string{
-				"token":"kfwkefnififi",
+			requestBody: map[string]string{
+				"token": "kfwkefnififi",
 			},
-		
+
 			expectedStatus: http.StatusBadRequest,
 		},
 	}
 
-	for _,tc := range testCases{
-		t.Run(tc.testName,func(t *testing.T){
-
-			body,_:=json.Marshal(tc.requestBody)
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
 
+			body, _ := json.Marshal(tc.requestBody)
 
 			req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/refresh", bytes.NewReader(body))
 			req.Header.Set("Content-Type", "application/json")
@@ -379,12 +372,932 @@ func TestRefreshHandler(t *testing.T){
 			userGroup.POST("/refresh", handler.GenerateAccessTokenFromRefreshToken)
 
 			r.ServeHTTP(w, req)
-		
-			assert.Equal(t,tc.expectedStatus,w.Code)
-			
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
 
 		})
 	}
 
+}
+
+func TestLogoutHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
 
-}
\ No newline at end of file
+	mockStore := new(auth.MockTokenStore)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	jwtManager := auth.NewJWTManager(auth.CreateJwtManagerParams{
+		AccessTokenDuration:  10 * time.Minute,
+		RefreshTokenDuration: 10 * time.Hour,
+		AccessTokenKey:       "qkaniqifiqfi",
+		RefreshTokenKey:      "fewnfewfnifnif",
+		TokenStore:           mockStore,
+	})
+	handler := NewUserHandler(NewUserService(new(MockUserRepo)), nil, logger, jwtManager, nil)
+
+	mockStore.On("SaveRefreshToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	tokens, err := jwtManager.Generate(context.TODO(), 123, "koti", "eswar@gmail")
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		testName       string
+		revoked        bool
+		expectedStatus int
+	}{
+		{
+			testName:       "active session is logged out",
+			revoked:        false,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName:       "revoked token is rejected",
+			revoked:        true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			mockStore.Calls = nil
+			mockStore.ExpectedCalls = nil
+			mockStore.On("IsAccessTokenDenied", mock.Anything, mock.Anything).Return(tc.revoked, nil)
+			mockStore.On("IsFamilyRevoked", mock.Anything, mock.Anything).Return(false, nil)
+			mockStore.On("UserTokensRevokedBefore", mock.Anything, 123).Return(time.Time{}, nil)
+			mockStore.On("DenyAccessToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			mockStore.On("RevokeFamily", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/logout", nil)
+			req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			RegisterRoutes(r.Group("/api/v1"), handler)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.revoked {
+				mockStore.AssertNotCalled(t, "RevokeFamily", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				mockStore.AssertCalled(t, "DenyAccessToken", mock.Anything, mock.Anything, mock.Anything)
+				mockStore.AssertCalled(t, "RevokeFamily", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPersonalAccessTokenHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler, mockRepo := SetupNewUserHandler()
+	sessionToken, err := handler.jwtManager.GenerateAccessToken(1234, "koti", "eswar@gmail")
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		testName       string
+		method         string
+		path           string
+		requestBody    map[string]any
+		bearer         string
+		mockSetup      func()
+		expectedStatus int
+		assertRepo     func(t *testing.T)
+	}{
+		{
+			testName:    "create token with session",
+			method:      http.MethodPost,
+			path:        "/api/v1/users/tokens/",
+			requestBody: map[string]any{"name": "ci", "scopes": []string{"tasks:read", "import:write", "tasks:read"}},
+			bearer:      sessionToken,
+			mockSetup: func() {
+				mockRepo.On("CreatePersonalAccessToken", mock.Anything, mock.MatchedBy(func(arg userdb.CreatePersonalAccessTokenParams) bool {
+					return arg.UserID == 1234 && len(arg.TokenHash) == 64 && len(arg.Scopes) == 2
+				})).Return(userdb.PersonalAccessToken{ID: 1, UserID: 1234, Name: "ci", Scopes: []string{"tasks:read", "import:write"}}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertCalled(t, "CreatePersonalAccessToken", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName:       "unknown scope is rejected",
+			method:         http.MethodPost,
+			path:           "/api/v1/users/tokens/",
+			requestBody:    map[string]any{"name": "ci", "scopes": []string{"users:admin"}},
+			bearer:         sessionToken,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "CreatePersonalAccessToken", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName: "personal access token cannot manage tokens",
+			method:   http.MethodGet,
+			path:     "/api/v1/users/tokens/",
+			bearer:   "tpat_Zm9vYmFy",
+			mockSetup: func() {
+				mockRepo.On("GetPersonalAccessTokenByHash", mock.Anything, auth.HashPersonalAccessToken("tpat_Zm9vYmFy")).Return(userdb.GetPersonalAccessTokenByHashRow{ID: 1, UserID: 1234, Scopes: []string{"tasks:write"}}, nil)
+				mockRepo.On("TouchPersonalAccessToken", mock.Anything, int64(1)).Return(nil)
+			},
+			expectedStatus: http.StatusForbidden,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "ListPersonalAccessTokensByUser", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName: "revoked personal access token is rejected",
+			method:   http.MethodGet,
+			path:     "/api/v1/users/tokens/",
+			bearer:   "tpat_cmV2b2tlZA",
+			mockSetup: func() {
+				mockRepo.On("GetPersonalAccessTokenByHash", mock.Anything, mock.Anything).Return(userdb.GetPersonalAccessTokenByHashRow{ID: 2, UserID: 1234, RevokedAt: sql.NullTime{Time: time.Now(), Valid: true}}, nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "TouchPersonalAccessToken", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName: "list tokens",
+			method:   http.MethodGet,
+			path:     "/api/v1/users/tokens/",
+			bearer:   sessionToken,
+			mockSetup: func() {
+				mockRepo.On("ListPersonalAccessTokensByUser", mock.Anything, int32(1234)).Return([]userdb.PersonalAccessToken{{ID: 1, Name: "ci"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			assertRepo:     func(t *testing.T) {},
+		},
+		{
+			testName: "revoking another user's token is not found",
+			method:   http.MethodDelete,
+			path:     "/api/v1/users/tokens/7",
+			bearer:   sessionToken,
+			mockSetup: func() {
+				mockRepo.On("RevokePersonalAccessToken", mock.Anything, userdb.RevokePersonalAccessTokenParams{ID: 7, UserID: 1234}).Return(int64(0), nil)
+			},
+			expectedStatus: http.StatusNotFound,
+			assertRepo:     func(t *testing.T) {},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			mockRepo.Calls = nil
+			mockRepo.ExpectedCalls = nil
+			tc.mockSetup()
+
+			var body io.Reader = http.NoBody
+			if tc.requestBody != nil {
+				data, _ := json.Marshal(tc.requestBody)
+				body = bytes.NewReader(data)
+			}
+			req, _ := http.NewRequest(tc.method, tc.path, body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tc.bearer)
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			RegisterRoutes(r.Group("/api/v1"), handler)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			tc.assertRepo(t)
+		})
+	}
+}
+
+func TestOIDCCallbackHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userHandler, mockRepo := SetupNewUserHandler()
+	provider, err := oidctest.NewProvider("taskpilot", "s3cret")
+	assert.NoError(t, err)
+	defer provider.Close()
+
+	store := new(oidc.MockStateStore)
+	client, err := oidc.NewClient(context.TODO(), oidc.Config{
+		IssuerURL:    provider.Issuer(),
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		RedirectURL:  "http://localhost:8080/api/v1/users/oidc/callback",
+	}, store)
+	assert.NoError(t, err)
+	handler := NewOIDCHandler(userHandler.userService, client, userHandler.logger, userHandler.jwtManager)
+	identity := userdb.GetUserByIdentityParams{Issuer: provider.Issuer(), Subject: provider.User.Subject}
+
+	testCases := []struct {
+		testName       string
+		emailVerified  bool
+		mockSetup      func()
+		expectedStatus int
+		assertRepo     func(t *testing.T)
+	}{
+		{
+			testName:      "first login creates and links the user",
+			emailVerified: true,
+			mockSetup: func() {
+				mockRepo.On("GetUserByIdentity", mock.Anything, identity).Return(userdb.User{}, sql.ErrNoRows)
+				mockRepo.On("GetUserByEmail", mock.Anything, provider.User.Email).Return(userdb.User{}, sql.ErrNoRows)
+				mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(arg userdb.CreateUserParams) bool {
+					return arg.Email == provider.User.Email && arg.Name == provider.User.Name && arg.HashedPassword != ""
+				})).Return(userdb.User{ID: 42, Email: provider.User.Email, Name: provider.User.Name}, nil)
+				mockRepo.On("CreateUserIdentity", mock.Anything, userdb.CreateUserIdentityParams{
+					UserID: 42, Issuer: provider.Issuer(), Subject: provider.User.Subject, Email: provider.User.Email,
+				}).Return(userdb.UserIdentity{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertCalled(t, "CreateUserIdentity", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName:      "existing user is linked by email",
+			emailVerified: true,
+			mockSetup: func() {
+				mockRepo.On("GetUserByIdentity", mock.Anything, identity).Return(userdb.User{}, sql.ErrNoRows)
+				mockRepo.On("GetUserByEmail", mock.Anything, provider.User.Email).Return(userdb.User{ID: 7, Email: provider.User.Email, Name: "koti"}, nil)
+				mockRepo.On("CreateUserIdentity", mock.Anything, mock.Anything).Return(userdb.UserIdentity{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName:      "returning identity logs in without email lookup",
+			emailVerified: false,
+			mockSetup: func() {
+				mockRepo.On("GetUserByIdentity", mock.Anything, identity).Return(userdb.User{ID: 7, Email: provider.User.Email, Name: "koti"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName:      "unverified email cannot link",
+			emailVerified: false,
+			mockSetup: func() {
+				mockRepo.On("GetUserByIdentity", mock.Anything, identity).Return(userdb.User{}, sql.ErrNoRows)
+			},
+			expectedStatus: http.StatusForbidden,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+				mockRepo.AssertNotCalled(t, "CreateUserIdentity", mock.Anything, mock.Anything)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			mockRepo.Calls = nil
+			mockRepo.ExpectedCalls = nil
+			store.Calls = nil
+			store.ExpectedCalls = nil
+			tc.mockSetup()
+			provider.User.EmailVerified = tc.emailVerified
+
+			r := gin.Default()
+			RegisterOIDCRoutes(r.Group("/api/v1"), handler)
+
+			var saved oidc.LoginState
+			store.On("Save", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) { saved = args.Get(2).(oidc.LoginState) }).Return(nil)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/users/oidc/login", nil)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusFound, w.Code)
+
+			state, code, err := provider.Authorize(w.Header().Get("Location"))
+			assert.NoError(t, err)
+			store.On("Take", mock.Anything, state).Return(saved, nil)
+
+			w = httptest.NewRecorder()
+			req, _ = http.NewRequest(http.MethodGet, "/api/v1/users/oidc/callback?state="+state+"&code="+code, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), "AccessToken")
+			}
+			tc.assertRepo(t)
+		})
+	}
+}
+
+func TestAccountHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockRepo := new(MockUserRepo)
+	mockMailer := new(mailer.MockMailer)
+	mockStore := new(auth.MockTokenStore)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	jwtManager := auth.NewJWTManager(auth.CreateJwtManagerParams{
+		AccessTokenDuration:  10 * time.Minute,
+		RefreshTokenDuration: 10 * time.Hour,
+		AccessTokenKey:       "qkaniqifiqfi",
+		RefreshTokenKey:      "fewnfewfnifnif",
+		TokenStore:           mockStore,
+	})
+	accountService := NewAccountService(mockRepo, mockMailer, AccountServiceParams{
+		BaseURL:                   "https://app.taskpilot.dev",
+		PasswordResetTokenTTL:     time.Hour,
+		EmailVerificationTokenTTL: 48 * time.Hour,
+	})
+	handler := NewUserHandler(NewUserService(mockRepo), accountService, logger, jwtManager, nil)
+
+	registered := userdb.User{ID: 7, Name: "koti", Email: "koti@taskpilot.dev"}
+	var mailedToken string
+	captureToken := func(args mock.Arguments) {
+		body := args.Get(1).(mailer.Message).Body
+		_, after, _ := strings.Cut(body, "?token=")
+		mailedToken, _, _ = strings.Cut(after, "\n")
+	}
+
+	testCases := []struct {
+		testName       string
+		path           string
+		requestBody    map[string]string
+		mockSetup      func()
+		expectedStatus int
+		verify         func(t *testing.T)
+	}{
+		{
+			testName:    "forgot password mails a reset link",
+			path:        "/api/v1/users/password/forgot",
+			requestBody: map[string]string{"email": registered.Email},
+			mockSetup: func() {
+				mockRepo.On("GetUserByEmail", mock.Anything, registered.Email).Return(registered, nil)
+				mockRepo.On("InvalidateUserTokens", mock.Anything, userdb.InvalidateUserTokensParams{
+					UserID: 7, Purpose: userdb.UserTokenPurposePASSWORDRESET,
+				}).Return(nil)
+				mockRepo.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(arg userdb.CreateUserTokenParams) bool {
+					return arg.UserID == 7 && arg.Purpose == userdb.UserTokenPurposePASSWORDRESET && len(arg.TokenHash) == 64
+				})).Return(userdb.UserToken{}, nil)
+				mockMailer.On("Send", mock.Anything, mock.MatchedBy(func(msg mailer.Message) bool {
+					return msg.To == registered.Email && strings.Contains(msg.Body, "https://app.taskpilot.dev/reset-password?token=")
+				})).Run(captureToken).Return(nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			verify: func(t *testing.T) {
+				mockMailer.AssertNumberOfCalls(t, "Send", 1)
+				stored := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(userdb.CreateUserTokenParams)
+				assert.Equal(t, hashUserToken(mailedToken), stored.TokenHash, "only the hash of the mailed token is stored")
+			},
+		},
+		{
+			testName:    "forgot password does not reveal unknown emails",
+			path:        "/api/v1/users/password/forgot",
+			requestBody: map[string]string{"email": "nobody@taskpilot.dev"},
+			mockSetup: func() {
+				mockRepo.On("GetUserByEmail", mock.Anything, "nobody@taskpilot.dev").Return(userdb.User{}, sql.ErrNoRows)
+			},
+			expectedStatus: http.StatusAccepted,
+			verify: func(t *testing.T) {
+				mockMailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName:    "reset password sets the password and ends sessions",
+			path:        "/api/v1/users/password/reset",
+			requestBody: map[string]string{"token": "reset-token", "password": "n3w-passw0rd"},
+			mockSetup: func() {
+				mockRepo.On("ConsumeUserToken", mock.Anything, userdb.ConsumeUserTokenParams{
+					TokenHash: hashUserToken("reset-token"), Purpose: userdb.UserTokenPurposePASSWORDRESET,
+				}).Return(int32(7), nil)
+				mockRepo.On("UpdateUserPassword", mock.Anything, mock.MatchedBy(func(arg userdb.UpdateUserPasswordParams) bool {
+					return arg.ID == 7 && bcrypt.CompareHashAndPassword([]byte(arg.HashedPassword), []byte("n3w-passw0rd")) == nil
+				})).Return(nil)
+				mockRepo.On("MarkEmailVerified", mock.Anything, int32(7)).Return(nil)
+				mockStore.On("RevokeUserTokens", mock.Anything, 7, mock.Anything, 10*time.Hour).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			verify: func(t *testing.T) {
+				mockStore.AssertCalled(t, "RevokeUserTokens", mock.Anything, 7, mock.Anything, 10*time.Hour)
+			},
+		},
+		{
+			testName:    "used or expired reset token is rejected",
+			path:        "/api/v1/users/password/reset",
+			requestBody: map[string]string{"token": "used-token", "password": "n3w-passw0rd"},
+			mockSetup: func() {
+				mockRepo.On("ConsumeUserToken", mock.Anything, mock.Anything).Return(int32(0), sql.ErrNoRows)
+			},
+			expectedStatus: http.StatusBadRequest,
+			verify: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "UpdateUserPassword", mock.Anything, mock.Anything)
+				mockStore.AssertNotCalled(t, "RevokeUserTokens", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName:    "verify email marks the address verified",
+			path:        "/api/v1/users/verify",
+			requestBody: map[string]string{"token": "verify-token"},
+			mockSetup: func() {
+				mockRepo.On("ConsumeUserToken", mock.Anything, userdb.ConsumeUserTokenParams{
+					TokenHash: hashUserToken("verify-token"), Purpose: userdb.UserTokenPurposeEMAILVERIFICATION,
+				}).Return(int32(7), nil)
+				mockRepo.On("MarkEmailVerified", mock.Anything, int32(7)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			verify: func(t *testing.T) {
+				mockRepo.AssertCalled(t, "MarkEmailVerified", mock.Anything, int32(7))
+			},
+		},
+		{
+			testName:    "resend skips already verified emails",
+			path:        "/api/v1/users/verify/resend",
+			requestBody: map[string]string{"email": registered.Email},
+			mockSetup: func() {
+				verified := registered
+				verified.EmailVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				mockRepo.On("GetUserByEmail", mock.Anything, registered.Email).Return(verified, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+			verify: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "CreateUserToken", mock.Anything, mock.Anything)
+				mockMailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			mockRepo.Calls = nil
+			mockRepo.ExpectedCalls = nil
+			mockMailer.Calls = nil
+			mockMailer.ExpectedCalls = nil
+			mockStore.Calls = nil
+			mockStore.ExpectedCalls = nil
+			tc.mockSetup()
+
+			body, _ := json.Marshal(tc.requestBody)
+			req, _ := http.NewRequest(http.MethodPost, tc.path, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			RegisterRoutes(r.Group("/api/v1"), handler)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			tc.verify(t)
+		})
+	}
+}
+
+func TestMFALoginHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler, mockRepo := SetupNewUserHandler()
+
+	secret, err := auth.GenerateTOTPSecret()
+	assert.NoError(t, err)
+	registered := userdb.User{
+		ID:             9,
+		Name:           "koti",
+		Email:          "koti@taskpilot.dev",
+		HashedPassword: getHashedPassword("s3cret-pass"),
+	}
+	enabled := userdb.UserTotp{
+		UserID:      9,
+		Secret:      secret,
+		ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+	codeTime := time.Now()
+
+	login := func(t *testing.T) string {
+		mockRepo.On("GetUserByEmail", mock.Anything, registered.Email).Return(registered, nil)
+		mockRepo.On("GetUserTOTP", mock.Anything, int32(9)).Return(enabled, nil)
+
+		body, _ := json.Marshal(map[string]string{"name": "koti", "email": registered.Email, "password": "s3cret-pass"})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r := gin.Default()
+		RegisterRoutes(r.Group("/api/v1"), handler)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data struct {
+				MFARequired bool   `json:"mfa_required"`
+				MFAToken    string `json:"mfa_token"`
+				Tokens      any    `json:"tokens"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.Data.MFARequired)
+		assert.Nil(t, response.Data.Tokens, "no tokens before the second factor")
+		return response.Data.MFAToken
+	}
+
+	testCases := []struct {
+		testName       string
+		code           func() string
+		mockSetup      func()
+		tamperToken    bool
+		expectedStatus int
+	}{
+		{
+			testName: "valid totp code issues tokens",
+			code: func() string {
+				return totpCodeAt(t, secret, codeTime)
+			},
+			mockSetup: func() {
+				mockRepo.On("UseTOTPStep", mock.Anything, userdb.UseTOTPStepParams{
+					UserID: 9, LastUsedStep: codeTime.Unix() / 30,
+				}).Return(int64(1), nil)
+				mockRepo.On("GetUserById", mock.Anything, int32(9)).Return(registered, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName: "replayed totp code is rejected",
+			code: func() string {
+				return totpCodeAt(t, secret, codeTime)
+			},
+			mockSetup: func() {
+				mockRepo.On("UseTOTPStep", mock.Anything, mock.Anything).Return(int64(0), nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			testName: "unused recovery code issues tokens",
+			code:     func() string { return "abcd-efgh-ijkl-mnop" },
+			mockSetup: func() {
+				mockRepo.On("UseRecoveryCode", mock.Anything, userdb.UseRecoveryCodeParams{
+					UserID: 9, CodeHash: auth.HashRecoveryCode("abcd-efgh-ijkl-mnop"),
+				}).Return(int64(1), nil)
+				mockRepo.On("GetUserById", mock.Anything, int32(9)).Return(registered, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName: "wrong code is rejected",
+			code:     func() string { return "abcd-efgh-ijkl-mnop" },
+			mockSetup: func() {
+				mockRepo.On("UseRecoveryCode", mock.Anything, mock.Anything).Return(int64(0), nil)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			testName:       "tampered challenge is rejected",
+			code:           func() string { return "000000" },
+			mockSetup:      func() {},
+			tamperToken:    true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			mockRepo.Calls = nil
+			mockRepo.ExpectedCalls = nil
+			challenge := login(t)
+			if tc.tamperToken {
+				challenge += "x"
+			}
+			tc.mockSetup()
+
+			body, _ := json.Marshal(MFALoginRequest{MFAToken: challenge, Code: tc.code()})
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/login/mfa", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r := gin.Default()
+			RegisterRoutes(r.Group("/api/v1"), handler)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), "AccessToken")
+			} else {
+				assert.NotContains(t, w.Body.String(), "AccessToken")
+			}
+		})
+	}
+}
+
+// totpCodeAt computes the RFC 6238 code of secret at the given time.
+func totpCodeAt(t *testing.T, secret string, at time.Time) string {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	assert.NoError(t, err)
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+func TestLoginHandlerBruteForceProtection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockRepo := new(MockUserRepo)
+	mockAttempts := new(auth.MockLoginAttemptStore)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	jwtManager := auth.NewJWTManager(auth.CreateJwtManagerParams{
+		AccessTokenDuration:  10 * time.Minute,
+		RefreshTokenDuration: 10 * time.Hour,
+		AccessTokenKey:       "qkaniqifiqfi",
+		RefreshTokenKey:      "fewnfewfnifnif",
+	})
+	guard := auth.NewLoginGuard(mockAttempts, auth.DefaultLoginGuardPolicy)
+	handler := NewUserHandler(NewUserService(mockRepo), nil, logger, jwtManager, guard)
+
+	registered := userdb.User{ID: 3, Name: "koti", Email: "koti@taskpilot.dev", HashedPassword: getHashedPassword("right-pass")}
+	notBlocked := func() {
+		mockAttempts.On("BlockedFor", mock.Anything, mock.Anything).Return(time.Duration(0), nil)
+	}
+
+	testCases := []struct {
+		testName       string
+		password       string
+		mockSetup      func()
+		expectedStatus int
+		retryAfter     string
+		verify         func(t *testing.T)
+	}{
+		{
+			testName: "locked account is rejected before the password is checked",
+			password: "right-pass",
+			mockSetup: func() {
+				mockAttempts.On("BlockedFor", mock.Anything, "lockout:account:koti@taskpilot.dev").Return(90*time.Second+time.Millisecond, nil)
+			},
+			expectedStatus: http.StatusLocked,
+			retryAfter:     "91",
+			verify: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName: "account in backoff is throttled",
+			password: "right-pass",
+			mockSetup: func() {
+				mockAttempts.On("BlockedFor", mock.Anything, "lockout:account:koti@taskpilot.dev").Return(time.Duration(0), nil)
+				mockAttempts.On("BlockedFor", mock.Anything, "account:koti@taskpilot.dev").Return(4*time.Second, nil)
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			retryAfter:     "4",
+			verify: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName: "wrong password is counted",
+			password: "wrong-pass",
+			mockSetup: func() {
+				notBlocked()
+				mockRepo.On("GetUserByEmail", mock.Anything, registered.Email).Return(registered, nil)
+				mockAttempts.On("RecordFailure", mock.Anything, "account:koti@taskpilot.dev", mock.Anything).Return(int64(1), nil)
+				mockAttempts.On("RecordFailure", mock.Anything, mock.Anything, mock.Anything).Return(int64(1), nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			verify: func(t *testing.T) {
+				mockAttempts.AssertCalled(t, "RecordFailure", mock.Anything, "account:koti@taskpilot.dev", mock.Anything)
+				mockAttempts.AssertNotCalled(t, "Reset", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName: "successful login clears the account failures",
+			password: "right-pass",
+			mockSetup: func() {
+				notBlocked()
+				mockRepo.On("GetUserByEmail", mock.Anything, registered.Email).Return(registered, nil)
+				mockRepo.On("GetUserTOTP", mock.Anything, int32(3)).Return(userdb.UserTotp{}, sql.ErrNoRows)
+				mockAttempts.On("Reset", mock.Anything, "account:koti@taskpilot.dev").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			verify: func(t *testing.T) {
+				mockAttempts.AssertCalled(t, "Reset", mock.Anything, "account:koti@taskpilot.dev")
+				mockAttempts.AssertNotCalled(t, "RecordFailure", mock.Anything, mock.Anything, mock.Anything)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			mockRepo.Calls = nil
+			mockRepo.ExpectedCalls = nil
+			mockAttempts.Calls = nil
+			mockAttempts.ExpectedCalls = nil
+			tc.mockSetup()
+
+			body, _ := json.Marshal(map[string]string{"name": "koti", "email": registered.Email, "password": tc.password})
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/users/login", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r := gin.Default()
+			RegisterRoutes(r.Group("/api/v1"), handler)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.retryAfter, w.Header().Get("Retry-After"))
+			tc.verify(t)
+		})
+	}
+}
+
+func TestProfileHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler, mockRepo := SetupNewUserHandler()
+	sessionToken, err := handler.jwtManager.GenerateAccessToken(9, "koti", "koti@taskpilot.dev")
+	assert.NoError(t, err)
+	registered := userdb.User{
+		ID:              9,
+		Name:            "koti",
+		Email:           "koti@taskpilot.dev",
+		HashedPassword:  getHashedPassword("s3cret-pass"),
+		EmailVerifiedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	testCases := []struct {
+		testName       string
+		method         string
+		path           string
+		requestBody    map[string]any
+		mockSetup      func()
+		expectedStatus int
+		assertRepo     func(t *testing.T)
+	}{
+		{
+			testName: "get own profile",
+			method:   http.MethodGet,
+			path:     "/api/v1/users/me",
+			mockSetup: func() {
+				mockRepo.On("GetUserById", mock.Anything, int32(9)).Return(registered, nil)
+				mockRepo.On("GetUserTOTP", mock.Anything, int32(9)).Return(userdb.UserTotp{}, sql.ErrNoRows)
+			},
+			expectedStatus: http.StatusOK,
+			assertRepo:     func(t *testing.T) {},
+		},
+		{
+			testName:    "rename without password",
+			method:      http.MethodPatch,
+			path:        "/api/v1/users/me",
+			requestBody: map[string]any{"name": "koti eswar"},
+			mockSetup: func() {
+				mockRepo.On("UpdateUserProfile", mock.Anything, userdb.UpdateUserProfileParams{
+					ID:   9,
+					Name: sql.NullString{String: "koti eswar", Valid: true},
+				}).Return(registered, nil)
+				mockRepo.On("GetUserTOTP", mock.Anything, int32(9)).Return(userdb.UserTotp{}, sql.ErrNoRows)
+			},
+			expectedStatus: http.StatusOK,
+			assertRepo:     func(t *testing.T) {},
+		},
+		{
+			testName:       "email change needs the current password",
+			method:         http.MethodPatch,
+			path:           "/api/v1/users/me",
+			requestBody:    map[string]any{"email": "koti@example.com"},
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "UpdateUserProfile", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName:       "invalid email is rejected",
+			method:         http.MethodPatch,
+			path:           "/api/v1/users/me",
+			requestBody:    map[string]any{"email": "Koti <koti@example.com>", "current_password": "s3cret-pass"},
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "UpdateUserProfile", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName:    "email change is verified again",
+			method:      http.MethodPatch,
+			path:        "/api/v1/users/me",
+			requestBody: map[string]any{"email": "a.rather.long.address.for.koti@subdomain.taskpilot.dev", "current_password": "s3cret-pass"},
+			mockSetup: func() {
+				changed := registered
+				changed.Email = "a.rather.long.address.for.koti@subdomain.taskpilot.dev"
+				changed.EmailVerifiedAt = sql.NullTime{}
+				mockRepo.On("GetUserById", mock.Anything, int32(9)).Return(registered, nil)
+				mockRepo.On("UpdateUserProfile", mock.Anything, mock.Anything).Return(changed, nil)
+				mockRepo.On("GetUserByEmail", mock.Anything, changed.Email).Return(changed, nil)
+				mockRepo.On("InvalidateUserTokens", mock.Anything, mock.Anything).Return(nil)
+				mockRepo.On("CreateUserToken", mock.Anything, mock.Anything).Return(userdb.UserToken{}, nil)
+				mockRepo.On("GetUserTOTP", mock.Anything, int32(9)).Return(userdb.UserTotp{}, sql.ErrNoRows)
+			},
+			expectedStatus: http.StatusOK,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertCalled(t, "CreateUserToken", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName:    "password change with wrong current password",
+			method:      http.MethodPost,
+			path:        "/api/v1/users/me/password",
+			requestBody: map[string]any{"current_password": "guess", "new_password": "n3w-pass"},
+			mockSetup: func() {
+				mockRepo.On("GetUserById", mock.Anything, int32(9)).Return(registered, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "UpdateUserPassword", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName:    "password change issues new tokens",
+			method:      http.MethodPost,
+			path:        "/api/v1/users/me/password",
+			requestBody: map[string]any{"current_password": "s3cret-pass", "new_password": "n3w-pass"},
+			mockSetup: func() {
+				mockRepo.On("GetUserById", mock.Anything, int32(9)).Return(registered, nil)
+				mockRepo.On("UpdateUserPassword", mock.Anything, mock.MatchedBy(func(arg userdb.UpdateUserPasswordParams) bool {
+					return arg.ID == 9 && bcrypt.CompareHashAndPassword([]byte(arg.HashedPassword), []byte("n3w-pass")) == nil
+				})).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			assertRepo:     func(t *testing.T) {},
+		},
+		{
+			testName:    "owner of shared projects cannot delete the account",
+			method:      http.MethodDelete,
+			path:        "/api/v1/users/me",
+			requestBody: map[string]any{"password": "s3cret-pass"},
+			mockSetup: func() {
+				mockRepo.On("GetUserById", mock.Anything, int32(9)).Return(registered, nil)
+				mockRepo.On("CountSharedOwnedProjects", mock.Anything, int32(9)).Return(int64(2), nil)
+			},
+			expectedStatus: http.StatusConflict,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			testName:    "delete account",
+			method:      http.MethodDelete,
+			path:        "/api/v1/users/me",
+			requestBody: map[string]any{"password": "s3cret-pass"},
+			mockSetup: func() {
+				mockRepo.On("GetUserById", mock.Anything, int32(9)).Return(registered, nil)
+				mockRepo.On("CountSharedOwnedProjects", mock.Anything, int32(9)).Return(int64(0), nil)
+				mockRepo.On("DeleteUser", mock.Anything, int32(9)).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertCalled(t, "DeleteUser", mock.Anything, int32(9))
+			},
+		},
+		{
+			testName: "search escapes wildcards",
+			method:   http.MethodGet,
+			path:     "/api/v1/users/search?q=Ko_t&limit=500",
+			mockSetup: func() {
+				mockRepo.On("SearchUsers", mock.Anything, userdb.SearchUsersParams{
+					Prefix:   `ko\_t%`,
+					ViewerID: 9,
+					Limit:    MaxSearchLimit,
+				}).Return([]userdb.SearchUsersRow{{ID: 9, Name: "ko_tester", Email: "ko_t@taskpilot.dev"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			assertRepo:     func(t *testing.T) {},
+		},
+		{
+			testName:       "search needs three characters",
+			method:         http.MethodGet,
+			path:           "/api/v1/users/search?q=ko",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			assertRepo: func(t *testing.T) {
+				mockRepo.AssertNotCalled(t, "SearchUsers", mock.Anything, mock.Anything)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			mockRepo.Calls = nil
+			mockRepo.ExpectedCalls = nil
+			tc.mockSetup()
+
+			var body io.Reader = http.NoBody
+			if tc.requestBody != nil {
+				data, _ := json.Marshal(tc.requestBody)
+				body = bytes.NewReader(data)
+			}
+			req, _ := http.NewRequest(tc.method, tc.path, body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+sessionToken)
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			RegisterRoutes(r.Group("/api/v1"), handler)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code, w.Body.String())
+			tc.assertRepo(t)
+		})
+	}
+}
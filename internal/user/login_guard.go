@@ -0,0 +1,34 @@
+package user
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// checkLoginGuard rejects the attempt with 423 or 429 and a Retry-After header
+// while account or the client IP is blocked. It reports whether to go on.
+func (u *UserHandler) checkLoginGuard(ctx context.Context, c *gin.Context, account string) bool {
+	wait, err := u.loginGuard.Check(ctx, account, c.ClientIP())
+	if err == nil {
+		return true
+	}
+	u.logger.Warnf("login attempt for %s from %s rejected %v", account, c.ClientIP(), err)
+	if wait > 0 {
+		// round up so clients never retry a moment too early
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	}
+	utils.Error(c, utils.ErrorStatus(err, http.StatusInternalServerError), err.Error())
+	return false
+}
+
+// recordLoginFailure counts a failed attempt; errors only lose a count, so they are logged.
+func (u *UserHandler) recordLoginFailure(ctx context.Context, account string, ip string, reason string) {
+	if err := u.loginGuard.RecordFailure(ctx, account, ip, reason); err != nil {
+		u.logger.Errorf("unable to record failed login of %s %v", account, err)
+	}
+}
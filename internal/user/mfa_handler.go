@@ -0,0 +1,213 @@
+package user
+
+import (
+	"context"
+	goerrors "errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	"github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// issueMFAChallenge answers a correct password login of an account with a
+// second factor: no tokens, only a short-lived challenge for /users/login/mfa.
+func (u *UserHandler) issueMFAChallenge(c *gin.Context, userID int) {
+	challenge, err := u.jwtManager.GenerateMFAChallenge(userID)
+	if err != nil {
+		u.logger.Errorf("error while generating the mfa challenge %v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	u.logger.Infof("user %d passed the password step, waiting for second factor", userID)
+	utils.Success(c, http.StatusOK, map[string]any{
+		"mfa_required": true,
+		"mfa_token":    challenge,
+		"expires_in":   int(auth.MFAChallengeDuration.Seconds()),
+		"message":      "enter the code from your authenticator app",
+	})
+}
+
+// LoginUserMFA completes a login with the second factor
+// @Summary      Complete two-factor login
+// @Description  Exchanges the mfa_token returned by /users/login and a TOTP or recovery code for JWT tokens. Each recovery code works once.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFALoginRequest true  "Challenge token and code"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      401      {object}  utils.ErrorResponse
+// @Failure      423      {object}  utils.ErrorResponse
+// @Failure      429      {object}  utils.ErrorResponse
+// @Router       /api/v1/users/login/mfa [post]
+func (u *UserHandler) LoginUserMFA(c *gin.Context) {
+	var request MFALoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	userID, err := u.jwtManager.VerifyMFAChallenge(request.MFAToken)
+	if err != nil {
+		u.logger.Errorf("invalid mfa challenge %v", err)
+		utils.Error(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	// second factor guesses are limited separately from passwords of the same account
+	account := "mfa:" + strconv.Itoa(userID)
+	if !u.checkLoginGuard(ctx, c, account) {
+		return
+	}
+	userInfo, err := u.userService.VerifyMFA(ctx, userID, request.Code)
+	if err != nil {
+		u.logger.Errorf("second factor rejected for user %d %v", userID, err)
+		if goerrors.Is(err, errors.ErrInvalidMFACode) {
+			u.recordLoginFailure(ctx, account, c.ClientIP(), auth.LoginFailureInvalidMFACode)
+		}
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	if err := u.loginGuard.RecordSuccess(ctx, account); err != nil {
+		u.logger.Errorf("unable to reset failed second factor attempts of user %d %v", userID, err)
+	}
+	jwtTokenResponse, err := u.jwtManager.Generate(ctx, int(userInfo.ID), userInfo.Name, userInfo.Email)
+	if err != nil {
+		u.logger.Errorf("error while generating the token %v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	u.logger.Infof("%s logged in with second factor", userInfo.Email)
+	utils.Success(c, http.StatusOK, map[string]any{
+		"tokens":  jwtTokenResponse,
+		"message": "login successful",
+	})
+}
+
+// EnrollTOTP starts two-factor enrollment
+// @Summary      Enroll TOTP
+// @Description  Generates a TOTP secret and returns it with an otpauth URI for authenticator apps. Two-factor authentication is enabled once confirmed.
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  EnrollTOTPResponse
+// @Failure      401  {object}  utils.ErrorResponse
+// @Failure      409  {object}  utils.ErrorResponse
+// @Router       /api/v1/users/mfa/totp/enroll [post]
+// @Security BearerAuth
+func (u *UserHandler) EnrollTOTP(c *gin.Context) {
+	userID, ok := u.sessionUserID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	secret, uri, err := u.userService.EnrollTOTP(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("unable to enroll totp %v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	u.logger.Infof("user %d started totp enrollment", userID)
+	utils.Success(c, http.StatusOK, map[string]any{
+		"data": EnrollTOTPResponse{
+			Secret:     secret,
+			OTPAuthURI: uri,
+		},
+		"message": "add the secret to your authenticator app and confirm with a code",
+	})
+}
+
+// ConfirmTOTP enables two-factor authentication
+// @Summary      Confirm TOTP
+// @Description  Enables two-factor authentication with a code from the enrolled app and returns recovery codes. The recovery codes are only shown once.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFACodeRequest true  "TOTP code"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      401      {object}  utils.ErrorResponse
+// @Failure      409      {object}  utils.ErrorResponse
+// @Router       /api/v1/users/mfa/totp/confirm [post]
+// @Security BearerAuth
+func (u *UserHandler) ConfirmTOTP(c *gin.Context) {
+	var request MFACodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	userID, ok := u.sessionUserID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	recoveryCodes, err := u.userService.ConfirmTOTP(ctx, userID, request.Code)
+	if err != nil {
+		u.logger.Errorf("unable to confirm totp %v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	u.logger.Infof("user %d enabled two-factor authentication", userID)
+	utils.Success(c, http.StatusOK, map[string]any{
+		"recovery_codes": recoveryCodes,
+		"message":        "two-factor authentication enabled, store these recovery codes now, they will not be shown again",
+	})
+}
+
+// DisableTOTP turns off two-factor authentication
+// @Summary      Disable TOTP
+// @Description  Disables two-factor authentication and deletes the recovery codes. Requires a current TOTP or recovery code.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFACodeRequest true  "TOTP or recovery code"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      401      {object}  utils.ErrorResponse
+// @Router       /api/v1/users/mfa/totp/disable [post]
+// @Security BearerAuth
+func (u *UserHandler) DisableTOTP(c *gin.Context) {
+	var request MFACodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	userID, ok := u.sessionUserID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	if err := u.userService.DisableTOTP(ctx, userID, request.Code); err != nil {
+		u.logger.Errorf("unable to disable totp %v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	u.logger.Infof("user %d disabled two-factor authentication", userID)
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "two-factor authentication disabled",
+	})
+}
+
+// sessionUserID reads the authenticated user from the context, writing the
+// error response when it is missing.
+func (u *UserHandler) sessionUserID(c *gin.Context) (int, bool) {
+	val, exists := c.Get("userID")
+	if !exists {
+		u.logger.Errorf("%v", errors.ErrUserIDNotFoundInContext)
+		utils.Error(c, http.StatusInternalServerError, "unauthenticated: user ID not found")
+		return 0, false
+	}
+	userID, ok := val.(int)
+	if !ok {
+		u.logger.Errorf("%v", errors.ErrInvalidUserId)
+		utils.Error(c, http.StatusInternalServerError, "invalid user ID type")
+		return 0, false
+	}
+	return userID, true
+}
@@ -0,0 +1,198 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	userdb "github.com/Gkemhcs/taskpilot/internal/user/gen"
+)
+
+// totpIssuer is the account label shown in authenticator apps.
+const totpIssuer = "TaskPilot"
+
+// EnrollTOTP starts TOTP enrollment by storing a new, unconfirmed secret and
+// returns it with the otpauth URI to show as a QR code. Enrolling again before
+// confirming replaces the pending secret.
+func (u *UserService) EnrollTOTP(ctx context.Context, userID int) (string, string, error) {
+	user, err := u.userRepository.GetUserById(ctx, int32(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", customErrors.USER_NOT_FOUND
+	}
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	_, err = u.userRepository.UpsertUserTOTP(ctx, userdb.UpsertUserTOTPParams{
+		UserID: user.ID,
+		Secret: secret,
+	})
+	// the upsert returns no row when a confirmed secret already exists
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", customErrors.ErrMFAAlreadyEnabled
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return secret, auth.TOTPURI(totpIssuer, user.Email, secret), nil
+}
+
+// ConfirmTOTP enables TOTP once the user proves their app produces valid codes
+// and returns freshly issued recovery codes. The codes are only shown here.
+func (u *UserService) ConfirmTOTP(ctx context.Context, userID int, code string) ([]string, error) {
+	totp, err := u.userRepository.GetUserTOTP(ctx, int32(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if totp.ConfirmedAt.Valid {
+		return nil, customErrors.ErrMFAAlreadyEnabled
+	}
+	step, ok := auth.ValidateTOTP(totp.Secret, code, time.Now())
+	if !ok {
+		return nil, customErrors.ErrInvalidMFACode
+	}
+
+	// the recovery codes are stored with the confirmation so an enabled
+	// account always has them
+	var recoveryCodes []string
+	err = u.inTx(ctx, func(ctx context.Context) error {
+		recoveryCodes, err = u.replaceRecoveryCodes(ctx, totp.UserID)
+		if err != nil {
+			return err
+		}
+		rows, err := u.userRepository.ConfirmUserTOTP(ctx, userdb.ConfirmUserTOTPParams{
+			UserID:       totp.UserID,
+			LastUsedStep: step,
+		})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return customErrors.ErrMFAAlreadyEnabled
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recoveryCodes, nil
+}
+
+// DisableTOTP turns off the second factor. A current TOTP or recovery code is
+// required so a stolen session alone cannot remove it.
+func (u *UserService) DisableTOTP(ctx context.Context, userID int, code string) error {
+	return u.inTx(ctx, func(ctx context.Context) error {
+		if err := u.verifyMFACode(ctx, int32(userID), code); err != nil {
+			return err
+		}
+		if err := u.userRepository.DeleteUserTOTP(ctx, int32(userID)); err != nil {
+			return err
+		}
+		return u.userRepository.DeleteRecoveryCodes(ctx, int32(userID))
+	})
+}
+
+// IsMFAEnabled reports whether logins of the user need a second factor.
+func (u *UserService) IsMFAEnabled(ctx context.Context, userID int) (bool, error) {
+	totp, err := u.userRepository.GetUserTOTP(ctx, int32(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return totp.ConfirmedAt.Valid, nil
+}
+
+// VerifyMFA completes a two-step login with a TOTP code or an unused recovery
+// code and returns the user to issue tokens for.
+func (u *UserService) VerifyMFA(ctx context.Context, userID int, code string) (*userdb.User, error) {
+	if err := u.verifyMFACode(ctx, int32(userID), code); err != nil {
+		return nil, err
+	}
+	user, err := u.userRepository.GetUserById(ctx, int32(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.USER_NOT_FOUND
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// verifyMFACode accepts a six digit TOTP code, each at most once, or a
+// recovery code, which is used up.
+func (u *UserService) verifyMFACode(ctx context.Context, userID int32, code string) error {
+	totp, err := u.userRepository.GetUserTOTP(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return customErrors.ErrMFANotEnabled
+	}
+	if err != nil {
+		return err
+	}
+	if !totp.ConfirmedAt.Valid {
+		return customErrors.ErrMFANotEnabled
+	}
+
+	if step, ok := auth.ValidateTOTP(totp.Secret, code, time.Now()); ok {
+		rows, err := u.userRepository.UseTOTPStep(ctx, userdb.UseTOTPStepParams{
+			UserID:       userID,
+			LastUsedStep: step,
+		})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return customErrors.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	rows, err := u.userRepository.UseRecoveryCode(ctx, userdb.UseRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: auth.HashRecoveryCode(code),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes discards the user's recovery codes and stores new ones.
+func (u *UserService) replaceRecoveryCodes(ctx context.Context, userID int32) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes(auth.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	err = u.inTx(ctx, func(ctx context.Context) error {
+		if err := u.userRepository.DeleteRecoveryCodes(ctx, userID); err != nil {
+			return err
+		}
+		for _, code := range codes {
+			err := u.userRepository.CreateRecoveryCode(ctx, userdb.CreateRecoveryCodeParams{
+				UserID:   userID,
+				CodeHash: auth.HashRecoveryCode(code),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
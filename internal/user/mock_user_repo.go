@@ -11,35 +11,146 @@ type MockUserRepo struct {
 	mock.Mock
 }
 
+func (m *MockUserRepo) CreateUser(ctx context.Context, arg userdb.CreateUserParams) (userdb.User, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(userdb.User), args.Error(1)
 
+}
+
+func (m *MockUserRepo) DeleteUser(ctx context.Context, id int32) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) GetUserByEmail(ctx context.Context, email string) (userdb.User, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(userdb.User), args.Error(1)
+}
+func (m *MockUserRepo) GetUserById(ctx context.Context, id int32) (userdb.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(userdb.User), args.Error(1)
+}
+
+func (m *MockUserRepo) GetUserByName(ctx context.Context, name string) (userdb.User, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(userdb.User), args.Error(1)
+}
+
+func (m *MockUserRepo) ListUsers(ctx context.Context) ([]userdb.User, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]userdb.User), args.Error(1)
+}
+func (m *MockUserRepo) CreatePersonalAccessToken(ctx context.Context, arg userdb.CreatePersonalAccessTokenParams) (userdb.PersonalAccessToken, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(userdb.PersonalAccessToken), args.Error(1)
+}
+
+func (m *MockUserRepo) GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (userdb.GetPersonalAccessTokenByHashRow, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(userdb.GetPersonalAccessTokenByHashRow), args.Error(1)
+}
+
+func (m *MockUserRepo) ListPersonalAccessTokensByUser(ctx context.Context, userID int32) ([]userdb.PersonalAccessToken, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]userdb.PersonalAccessToken), args.Error(1)
+}
+
+func (m *MockUserRepo) RevokePersonalAccessToken(ctx context.Context, arg userdb.RevokePersonalAccessTokenParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepo) TouchPersonalAccessToken(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) CreateUserIdentity(ctx context.Context, arg userdb.CreateUserIdentityParams) (userdb.UserIdentity, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(userdb.UserIdentity), args.Error(1)
+}
+
+func (m *MockUserRepo) GetUserByIdentity(ctx context.Context, arg userdb.GetUserByIdentityParams) (userdb.User, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(userdb.User), args.Error(1)
+}
 
-func (m *MockUserRepo) CreateUser(ctx context.Context, arg userdb.CreateUserParams) (userdb.User, error){
-	args:=m.Called(ctx,arg)
-	return args.Get(0).(userdb.User),args.Error(1)
+func (m *MockUserRepo) ConsumeUserToken(ctx context.Context, arg userdb.ConsumeUserTokenParams) (int32, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int32), args.Error(1)
+}
 
+func (m *MockUserRepo) CreateUserToken(ctx context.Context, arg userdb.CreateUserTokenParams) (userdb.UserToken, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(userdb.UserToken), args.Error(1)
+}
 
+func (m *MockUserRepo) InvalidateUserTokens(ctx context.Context, arg userdb.InvalidateUserTokensParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
 }
 
-func(m *MockUserRepo) DeleteUser(ctx context.Context, id int32) error{
-	args:=m.Called(ctx,id)
+func (m *MockUserRepo) MarkEmailVerified(ctx context.Context, id int32) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockUserRepo) GetUserByEmail(ctx context.Context, email string) (userdb.User, error){
-	args:=m.Called(ctx,email)
-	return args.Get(0).(userdb.User),args.Error(1)
+func (m *MockUserRepo) UpdateUserPassword(ctx context.Context, arg userdb.UpdateUserPasswordParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
 }
-func(m *MockUserRepo)GetUserById(ctx context.Context, id int32) (userdb.User, error){
-	args:=m.Called(ctx,id)
-	return args.Get(0).(userdb.User),args.Error(1)
+
+func (m *MockUserRepo) UpsertUserTOTP(ctx context.Context, arg userdb.UpsertUserTOTPParams) (userdb.UserTotp, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(userdb.UserTotp), args.Error(1)
+}
+
+func (m *MockUserRepo) GetUserTOTP(ctx context.Context, userID int32) (userdb.UserTotp, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(userdb.UserTotp), args.Error(1)
 }
 
-func(m *MockUserRepo)	GetUserByName(ctx context.Context, name string) (userdb.User, error){
-	args:=m.Called(ctx,name)
-	return args.Get(0).(userdb.User),args.Error(1)
+func (m *MockUserRepo) ConfirmUserTOTP(ctx context.Context, arg userdb.ConfirmUserTOTPParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
 }
 
-func(m *MockUserRepo)ListUsers(ctx context.Context) ([]userdb.User, error){
-args:=m.Called(ctx)
-return args.Get(0).([]userdb.User),args.Error(1)
-}
\ No newline at end of file
+func (m *MockUserRepo) UseTOTPStep(ctx context.Context, arg userdb.UseTOTPStepParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepo) DeleteUserTOTP(ctx context.Context, userID int32) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) CreateRecoveryCode(ctx context.Context, arg userdb.CreateRecoveryCodeParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) DeleteRecoveryCodes(ctx context.Context, userID int32) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) UseRecoveryCode(ctx context.Context, arg userdb.UseRecoveryCodeParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepo) UpdateUserProfile(ctx context.Context, arg userdb.UpdateUserProfileParams) (userdb.User, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(userdb.User), args.Error(1)
+}
+
+func (m *MockUserRepo) SearchUsers(ctx context.Context, arg userdb.SearchUsersParams) ([]userdb.SearchUsersRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]userdb.SearchUsersRow), args.Error(1)
+}
+
+func (m *MockUserRepo) CountSharedOwnedProjects(ctx context.Context, userID int32) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
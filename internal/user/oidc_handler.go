@@ -0,0 +1,127 @@
+package user
+
+import (
+	"context"
+	goerrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	"github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/oidc"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RegisterOIDCRoutes registers the single sign-on login under /users/oidc.
+func RegisterOIDCRoutes(rg *gin.RouterGroup, handler *OIDCHandler) {
+	oidcGroup := rg.Group("/users/oidc")
+	{
+		oidcGroup.GET("/login", handler.StartLogin)
+		oidcGroup.GET("/callback", handler.Callback)
+	}
+}
+
+func NewOIDCHandler(userService IUserService, client *oidc.Client, logger *logrus.Logger, jwtManager *auth.JWTManager) *OIDCHandler {
+	return &OIDCHandler{
+		logger:      logger,
+		userService: userService,
+		client:      client,
+		jwtManager:  jwtManager,
+	}
+}
+
+// OIDCHandler serves the OpenID Connect authorization code login.
+type OIDCHandler struct {
+	logger      *logrus.Logger
+	userService IUserService
+	client      *oidc.Client
+	jwtManager  *auth.JWTManager
+}
+
+// StartLogin redirects the browser to the identity provider
+// @Summary      Start single sign-on login
+// @Description  Redirects to the configured OpenID Connect provider using the authorization code flow with PKCE
+// @Tags         users
+// @Success      302
+// @Failure      500   {object}  utils.ErrorResponse
+// @Router       /api/v1/users/oidc/login [get]
+func (o *OIDCHandler) StartLogin(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	authURL, err := o.client.AuthCodeURL(ctx)
+	if err != nil {
+		o.logger.Errorf("unable to start oidc login %v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback completes the single sign-on login
+// @Summary      Single sign-on callback
+// @Description  Exchanges the authorization code, creates or links the user by verified email and returns JWT tokens
+// @Tags         users
+// @Produce      json
+// @Param        state  query     string  true  "State from the login redirect"
+// @Param        code   query     string  true  "Authorization code"
+// @Success      200    {object}  map[string]interface{}
+// @Failure      400    {object}  utils.ErrorResponse
+// @Failure      401    {object}  utils.ErrorResponse
+// @Failure      403    {object}  utils.ErrorResponse
+// @Failure      500    {object}  utils.ErrorResponse
+// @Router       /api/v1/users/oidc/callback [get]
+func (o *OIDCHandler) Callback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		o.logger.Warnf("oidc login rejected by provider: %s", providerErr)
+		utils.Error(c, http.StatusUnauthorized, "single sign-on login failed: "+providerErr)
+		return
+	}
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		utils.Error(c, http.StatusBadRequest, errors.ErrOIDCInvalidState.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+	identity, err := o.client.Exchange(ctx, state, code)
+	if goerrors.Is(err, errors.ErrOIDCInvalidState) {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if goerrors.Is(err, errors.ErrOIDCInvalidIDToken) || goerrors.Is(err, errors.ErrOIDCExchangeFailed) {
+		o.logger.Warnf("oidc login failed %v", err)
+		utils.Error(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		o.logger.Errorf("oidc code exchange failed %v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	userInfo, err := o.userService.LoginWithOIDC(ctx, identity)
+	if goerrors.Is(err, errors.ErrOIDCEmailNotVerified) {
+		o.logger.Warnf("oidc login for %s rejected: %v", identity.Email, err)
+		utils.Error(c, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		o.logger.Errorf("unable to login with oidc %v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	jwtTokenResponse, err := o.jwtManager.Generate(ctx, int(userInfo.ID), userInfo.Name, userInfo.Email)
+	if err != nil {
+		o.logger.Errorf("error while generating the token %v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusInternalServerError), err.Error())
+		return
+	}
+	o.logger.Infof("%s logged in with single sign-on", userInfo.Email)
+	utils.Success(c, http.StatusOK, map[string]interface{}{
+		"tokens":  jwtTokenResponse,
+		"message": "login successful",
+	})
+}
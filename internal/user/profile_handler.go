@@ -0,0 +1,269 @@
+package user
+
+import (
+	"context"
+	goerrors "errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Gkemhcs/taskpilot/internal/auth"
+	"github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// GetProfile returns the logged in user
+// @Summary      Get my profile
+// @Description  Returns the account of the logged in user.
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  UserProfileResponse
+// @Failure      401  {object}  utils.ErrorResponse
+// @Failure      404  {object}  utils.ErrorResponse
+// @Router       /api/v1/users/me [get]
+// @Security BearerAuth
+func (u *UserHandler) GetProfile(c *gin.Context) {
+	userID, ok := u.sessionUserID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	user, err := u.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("unable to get the profile of user %d %v", userID, err)
+		utils.Error(c, profileErrorStatus(err), err.Error())
+		return
+	}
+	mfaEnabled, err := u.userService.IsMFAEnabled(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("unable to check two-factor authentication %v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, NewUserProfileResponse(*user, mfaEnabled))
+}
+
+// UpdateProfile changes the name or email of the logged in user
+// @Summary      Update my profile
+// @Description  Updates the name and/or email; omitted fields are kept. Changing the email requires current_password and sends a new verification email.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      UpdateProfileRequest true  "Fields to change"
+// @Success      200      {object}  UserProfileResponse
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      401      {object}  utils.ErrorResponse
+// @Failure      409      {object}  utils.ErrorResponse
+// @Failure      423      {object}  utils.ErrorResponse
+// @Failure      429      {object}  utils.ErrorResponse
+// @Router       /api/v1/users/me [patch]
+// @Security BearerAuth
+func (u *UserHandler) UpdateProfile(c *gin.Context) {
+	var request UpdateProfileRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	userID, ok := u.sessionUserID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	account := reauthAccount(userID)
+	if request.Email != nil && !u.checkLoginGuard(ctx, c, account) {
+		return
+	}
+	user, err := u.userService.UpdateProfile(ctx, userID, request.Name, request.Email, request.CurrentPassword)
+	if err != nil {
+		u.logger.Errorf("unable to update the profile of user %d %v", userID, err)
+		if goerrors.Is(err, errors.ErrMismatchedPassword) {
+			u.recordLoginFailure(ctx, account, c.ClientIP(), auth.LoginFailureInvalidCredentials)
+		}
+		utils.Error(c, profileErrorStatus(err), err.Error())
+		return
+	}
+	if !user.EmailVerifiedAt.Valid && request.Email != nil {
+		// the profile is already saved, a failed email can be resent by the user
+		if err := u.accountService.SendEmailVerification(ctx, user.Email); err != nil {
+			u.logger.Errorf("unable to send verification email to %s %v", user.Email, err)
+		}
+	}
+	mfaEnabled, err := u.userService.IsMFAEnabled(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("unable to check two-factor authentication %v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	u.logger.Infof("user %d updated their profile", userID)
+	utils.Success(c, http.StatusOK, NewUserProfileResponse(*user, mfaEnabled))
+}
+
+// ChangePassword changes the password of the logged in user
+// @Summary      Change my password
+// @Description  Replaces the password after checking the current one. Every other session is logged out and a new token pair is returned.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      ChangePasswordRequest true  "Current and new password"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      423      {object}  utils.ErrorResponse
+// @Failure      429      {object}  utils.ErrorResponse
+// @Router       /api/v1/users/me/password [post]
+// @Security BearerAuth
+func (u *UserHandler) ChangePassword(c *gin.Context) {
+	var request ChangePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	userID, ok := u.sessionUserID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	account := reauthAccount(userID)
+	if !u.checkLoginGuard(ctx, c, account) {
+		return
+	}
+	if err := u.userService.ChangePassword(ctx, userID, request.CurrentPassword, request.NewPassword); err != nil {
+		u.logger.Errorf("unable to change the password of user %d %v", userID, err)
+		if goerrors.Is(err, errors.ErrMismatchedPassword) {
+			u.recordLoginFailure(ctx, account, c.ClientIP(), auth.LoginFailureInvalidCredentials)
+		}
+		utils.Error(c, profileErrorStatus(err), err.Error())
+		return
+	}
+	if err := u.loginGuard.RecordSuccess(ctx, account); err != nil {
+		u.logger.Errorf("unable to reset failed password checks of user %d %v", userID, err)
+	}
+	if err := u.jwtManager.RevokeUser(ctx, userID); err != nil {
+		u.logger.Errorf("unable to revoke the sessions of user %d %v", userID, err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	user, err := u.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		u.logger.Errorf("unable to get user %d %v", userID, err)
+		utils.Error(c, profileErrorStatus(err), err.Error())
+		return
+	}
+	tokens, err := u.jwtManager.Generate(ctx, int(user.ID), user.Name, user.Email)
+	if err != nil {
+		u.logger.Errorf("error while generating the token %v", err)
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	u.logger.Infof("user %d changed their password", userID)
+	utils.Success(c, http.StatusOK, map[string]any{
+		"tokens":  tokens,
+		"message": "password changed, other sessions have been logged out",
+	})
+}
+
+// DeleteAccount deletes the logged in user
+// @Summary      Delete my account
+// @Description  Deletes the account after checking the password, together with the projects it owns. Fails while an owned project has other members.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      DeleteAccountRequest true  "Password"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  utils.ErrorResponse
+// @Failure      409      {object}  utils.ErrorResponse
+// @Failure      423      {object}  utils.ErrorResponse
+// @Failure      429      {object}  utils.ErrorResponse
+// @Router       /api/v1/users/me [delete]
+// @Security BearerAuth
+func (u *UserHandler) DeleteAccount(c *gin.Context) {
+	var request DeleteAccountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.Error(c, http.StatusBadRequest, errors.INCORRECT_REQUEST_BODY.Error())
+		return
+	}
+	userID, ok := u.sessionUserID(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	account := reauthAccount(userID)
+	if !u.checkLoginGuard(ctx, c, account) {
+		return
+	}
+	if err := u.userService.DeleteAccount(ctx, userID, request.Password); err != nil {
+		u.logger.Errorf("unable to delete user %d %v", userID, err)
+		if goerrors.Is(err, errors.ErrMismatchedPassword) {
+			u.recordLoginFailure(ctx, account, c.ClientIP(), auth.LoginFailureInvalidCredentials)
+		}
+		utils.Error(c, profileErrorStatus(err), err.Error())
+		return
+	}
+	// the account is gone, so failing to revoke its tokens is only logged
+	if err := u.jwtManager.RevokeUser(ctx, userID); err != nil {
+		u.logger.Errorf("unable to revoke the sessions of deleted user %d %v", userID, err)
+	}
+	u.logger.Infof("user %d deleted their account", userID)
+	utils.Success(c, http.StatusOK, map[string]any{
+		"message": "account deleted",
+	})
+}
+
+// SearchUsers finds users to share projects with
+// @Summary      Search users
+// @Description  Returns users sharing an organization or a project with you whose name or email starts with q, ignoring case. q needs at least 3 characters.
+// @Tags         users
+// @Produce      json
+// @Param        q      query     string  true   "Name or email prefix"
+// @Param        limit  query     int     false  "Maximum number of users (default 10, max 50)"
+// @Success      200    {array}   userdb.SearchUsersRow
+// @Failure      400    {object}  utils.ErrorResponse
+// @Failure      401    {object}  utils.ErrorResponse
+// @Router       /api/v1/users/search [get]
+// @Security BearerAuth
+func (u *UserHandler) SearchUsers(c *gin.Context) {
+	userID, ok := u.sessionUserID(c)
+	if !ok {
+		return
+	}
+	limit := DefaultSearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			utils.Error(c, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		limit = parsed
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+	users, err := u.userService.SearchUsers(ctx, userID, c.Query("q"), limit)
+	if err != nil {
+		u.logger.Errorf("unable to search users %v", err)
+		utils.Error(c, utils.ErrorStatus(err, http.StatusBadRequest), err.Error())
+		return
+	}
+	utils.Success(c, http.StatusOK, users)
+}
+
+// reauthAccount is the login guard key for password checks of a logged in
+// user, so a stolen session cannot be used to guess the password.
+func reauthAccount(userID int) string {
+	return "reauth:" + strconv.Itoa(userID)
+}
+
+// profileErrorStatus maps errors of the profile endpoints to status codes.
+func profileErrorStatus(err error) int {
+	switch {
+	case goerrors.Is(err, errors.USER_NOT_FOUND):
+		return http.StatusNotFound
+	case goerrors.Is(err, errors.ErrUserAlreadyExists):
+		return http.StatusConflict
+	default:
+		return utils.ErrorStatus(err, http.StatusBadRequest)
+	}
+}
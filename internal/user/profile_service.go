@@ -0,0 +1,157 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/mail"
+	"strings"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	userdb "github.com/Gkemhcs/taskpilot/internal/user/gen"
+)
+
+const (
+	// maxEmailLength is the longest address SMTP can deliver to, and the size of users.email.
+	maxEmailLength = 254
+	// minSearchQueryLength keeps a search from listing every user it can see.
+	minSearchQueryLength = 3
+	// DefaultSearchLimit and MaxSearchLimit bound the users returned by SearchUsers.
+	DefaultSearchLimit = 10
+	MaxSearchLimit     = 50
+)
+
+// GetUserByID returns the user with the given id.
+func (u *UserService) GetUserByID(ctx context.Context, userID int) (*userdb.User, error) {
+	user, err := u.userRepository.GetUserById(ctx, int32(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.USER_NOT_FOUND
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateProfile changes the name and/or email of the user; nil fields are kept.
+// Changing the email needs the current password, since whoever controls the
+// email can reset the password. The new address has to be verified again.
+func (u *UserService) UpdateProfile(ctx context.Context, userID int, name *string, email *string, currentPassword string) (*userdb.User, error) {
+	if name == nil && email == nil {
+		return nil, customErrors.ErrNothingToUpdate
+	}
+	params := userdb.UpdateUserProfileParams{ID: int32(userID)}
+	if name != nil {
+		if strings.TrimSpace(*name) == "" {
+			return nil, customErrors.MISSING_USER_NAME
+		}
+		params.Name = sql.NullString{String: strings.TrimSpace(*name), Valid: true}
+	}
+	if email != nil {
+		if err := validateEmail(*email); err != nil {
+			return nil, err
+		}
+		if currentPassword == "" {
+			return nil, customErrors.ErrMissingCurrentPassword
+		}
+		if _, err := u.reauthenticate(ctx, userID, currentPassword); err != nil {
+			return nil, err
+		}
+		params.Email = sql.NullString{String: *email, Valid: true}
+	}
+
+	user, err := u.userRepository.UpdateUserProfile(ctx, params)
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+		return nil, customErrors.ErrUserAlreadyExists
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.USER_NOT_FOUND
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ChangePassword replaces the password after checking the current one.
+func (u *UserService) ChangePassword(ctx context.Context, userID int, currentPassword string, newPassword string) error {
+	if _, err := u.reauthenticate(ctx, userID, currentPassword); err != nil {
+		return err
+	}
+	hashedPassword, err := u.GeneratePasswordHash(newPassword)
+	if err != nil {
+		return err
+	}
+	return u.userRepository.UpdateUserPassword(ctx, userdb.UpdateUserPasswordParams{
+		ID:             int32(userID),
+		HashedPassword: hashedPassword,
+	})
+}
+
+// DeleteAccount deletes the user after checking their password. Projects the
+// user owns are deleted with them, so the account cannot be deleted while any
+// of those projects has other members.
+func (u *UserService) DeleteAccount(ctx context.Context, userID int, password string) error {
+	if _, err := u.reauthenticate(ctx, userID, password); err != nil {
+		return err
+	}
+	shared, err := u.userRepository.CountSharedOwnedProjects(ctx, int32(userID))
+	if err != nil {
+		return err
+	}
+	if shared > 0 {
+		return customErrors.ErrOwnsSharedProjects
+	}
+	return u.userRepository.DeleteUser(ctx, int32(userID))
+}
+
+// SearchUsers finds users whose name or email starts with query, ignoring
+// case, among the enabled users sharing an organization or a project with
+// userID.
+func (u *UserService) SearchUsers(ctx context.Context, userID int, query string, limit int) ([]userdb.SearchUsersRow, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if len([]rune(query)) < minSearchQueryLength {
+		return nil, customErrors.ErrSearchQueryTooShort
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	users, err := u.userRepository.SearchUsers(ctx, userdb.SearchUsersParams{
+		Prefix:   escapeLike(query) + "%",
+		ViewerID: int32(userID),
+		Limit:    int32(min(limit, MaxSearchLimit)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// reauthenticate checks password against the stored hash of the user.
+func (u *UserService) reauthenticate(ctx context.Context, userID int, password string) (*userdb.User, error) {
+	user, err := u.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.CheckHashedPassword(password, user.HashedPassword); err != nil {
+		return nil, customErrors.ErrMismatchedPassword
+	}
+	return user, nil
+}
+
+// validateEmail accepts a bare address such as koti@example.com.
+func validateEmail(email string) error {
+	if len(email) > maxEmailLength {
+		return customErrors.ErrInvalidEmail
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return customErrors.ErrInvalidEmail
+	}
+	return nil
+}
+
+// escapeLike escapes the LIKE wildcards in s, using the default \ escape character.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -2,11 +2,20 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Gkemhcs/taskpilot/internal/auth"
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
+	"github.com/Gkemhcs/taskpilot/internal/oidc"
+	"github.com/Gkemhcs/taskpilot/internal/types"
 	userdb "github.com/Gkemhcs/taskpilot/internal/user/gen"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -20,11 +29,28 @@ func NewUserService(dbQuerier userdb.Querier) *UserService {
 
 // UserService implements user-related business logic and interacts with the database.
 type UserService struct {
-	userRepository userdb.Querier // Database access layer for user operations
+	userRepository userdb.Querier   // Database access layer for user operations
+	transactor     types.Transactor // Runs the writes of one flow together; nil runs them one by one
+}
+
+// UseTransactions runs the writes of each MFA flow in one transaction of
+// transactor. It is not safe to call once the service is in use.
+func (u *UserService) UseTransactions(transactor types.Transactor) {
+	u.transactor = transactor
+}
+
+func (u *UserService) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if u.transactor == nil {
+		return fn(ctx)
+	}
+	return u.transactor.InTx(ctx, fn)
 }
 
 // CreateUser hashes the password and creates a new user in the database.
 func (u *UserService) CreateUser(ctx context.Context, name, email, password string) error {
+	if err := validateEmail(email); err != nil {
+		return err
+	}
 	hashedPassword, err := u.GeneratePasswordHash(password)
 	if err != nil {
 		return err
@@ -33,12 +59,11 @@ func (u *UserService) CreateUser(ctx context.Context, name, email, password stri
 	createUserParams := userdb.CreateUserParams{Name: name, HashedPassword: hashedPassword, Email: email}
 	_, err = u.userRepository.CreateUser(ctx, createUserParams)
 
-	
-	if  IsErrorCode(err, customErrors.UniqueViolationErr) {
-		
-				return customErrors.ErrUserAlreadyExists
-			}
-		
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+
+		return customErrors.ErrUserAlreadyExists
+	}
+
 	if err != nil {
 		return err
 	}
@@ -62,9 +87,25 @@ func (u *UserService) LoginUser(ctx context.Context, email string, password stri
 	if err != nil {
 		return nil, err
 	}
+	// checked after the password so the response does not reveal which accounts exist
+	if user.DisabledAt.Valid {
+		return nil, customErrors.ErrAccountDisabled
+	}
 	return user, nil
 }
 
+// AccountStatus implements auth.AccountStatusReader.
+func (u *UserService) AccountStatus(ctx context.Context, userID int) (auth.AccountStatus, error) {
+	user, err := u.userRepository.GetUserById(ctx, int32(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return auth.AccountStatus{}, customErrors.USER_NOT_FOUND
+	}
+	if err != nil {
+		return auth.AccountStatus{}, err
+	}
+	return auth.AccountStatus{IsAdmin: user.IsAdmin, Disabled: user.DisabledAt.Valid}, nil
+}
+
 func (u *UserService) GetUserByEmail(ctx context.Context, email string) (*userdb.User, error) {
 	user, err := u.userRepository.GetUserByEmail(ctx, email)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -90,11 +131,174 @@ func (u *UserService) CheckHashedPassword(password string, hashedPassword string
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
+// LoginWithOIDC resolves a verified single sign-on identity to a local user.
+// An identity seen before maps to its linked user; otherwise it is linked to
+// the user with the same email, or a new user is created. Linking or creating
+// by email requires the provider to have verified that email.
+func (u *UserService) LoginWithOIDC(ctx context.Context, identity *oidc.Identity) (*userdb.User, error) {
+	user, err := u.userRepository.GetUserByIdentity(ctx, userdb.GetUserByIdentityParams{
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+	})
+	if err == nil {
+		return &user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if !identity.EmailVerified || identity.Email == "" {
+		return nil, customErrors.ErrOIDCEmailNotVerified
+	}
+
+	user, err = u.userRepository.GetUserByEmail(ctx, identity.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		user, err = u.createOIDCUser(ctx, identity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = u.userRepository.CreateUserIdentity(ctx, userdb.CreateUserIdentityParams{
+		UserID:  user.ID,
+		Issuer:  identity.Issuer,
+		Subject: identity.Subject,
+		Email:   identity.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// createOIDCUser creates a user for a first single sign-on login. The user gets
+// a random password nobody knows, so it can only sign in through the provider.
+func (u *UserService) createOIDCUser(ctx context.Context, identity *oidc.Identity) (userdb.User, error) {
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	hashedPassword, err := u.GeneratePasswordHash(rand.Text())
+	if err != nil {
+		return userdb.User{}, err
+	}
+	user, err := u.userRepository.CreateUser(ctx, userdb.CreateUserParams{
+		Name:           name,
+		HashedPassword: hashedPassword,
+		Email:          identity.Email,
+	})
+	if IsErrorCode(err, customErrors.UniqueViolationErr) {
+		return userdb.User{}, customErrors.ErrUserAlreadyExists
+	}
+	return user, err
+}
+
+// personalAccessTokenPrefixLength is how much of a token is stored in the
+// clear so users can tell their tokens apart.
+const personalAccessTokenPrefixLength = len(auth.PersonalAccessTokenPrefix) + 4
+
+// CreatePersonalAccessToken issues a scoped token for userID. Only its hash is
+// stored, so the returned plaintext token cannot be retrieved again.
+func (u *UserService) CreatePersonalAccessToken(ctx context.Context, userID int, name string, scopes []string, expiresAt *time.Time) (string, *userdb.PersonalAccessToken, error) {
+	granted := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		if !auth.IsValidScope(scope) {
+			return "", nil, fmt.Errorf("%w: %s", customErrors.ErrInvalidScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			granted = append(granted, scope)
+		}
+	}
+	if len(granted) == 0 {
+		return "", nil, customErrors.ErrInvalidScope
+	}
+	expiry := sql.NullTime{}
+	if expiresAt != nil {
+		if !expiresAt.After(time.Now()) {
+			return "", nil, customErrors.ErrInvalidTokenExpiry
+		}
+		expiry = sql.NullTime{Time: *expiresAt, Valid: true}
+	}
+
+	token, hash, err := auth.GeneratePersonalAccessToken()
+	if err != nil {
+		return "", nil, err
+	}
+	created, err := u.userRepository.CreatePersonalAccessToken(ctx, userdb.CreatePersonalAccessTokenParams{
+		UserID:      int32(userID),
+		Name:        name,
+		TokenHash:   hash,
+		TokenPrefix: token[:personalAccessTokenPrefixLength],
+		Scopes:      granted,
+		ExpiresAt:   expiry,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &created, nil
+}
+
+// ListPersonalAccessTokens returns every token of userID, including revoked and expired ones.
+func (u *UserService) ListPersonalAccessTokens(ctx context.Context, userID int) ([]userdb.PersonalAccessToken, error) {
+	tokens, err := u.userRepository.ListPersonalAccessTokensByUser(ctx, int32(userID))
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RevokePersonalAccessToken revokes one of userID's tokens. Tokens of other
+// users and already revoked tokens are reported as not found.
+func (u *UserService) RevokePersonalAccessToken(ctx context.Context, userID int, tokenID int64) error {
+	rows, err := u.userRepository.RevokePersonalAccessToken(ctx, userdb.RevokePersonalAccessTokenParams{
+		ID:     tokenID,
+		UserID: int32(userID),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return customErrors.ErrPersonalAccessTokenNotFound
+	}
+	return nil
+}
+
+// AuthenticatePersonalAccessToken implements auth.PersonalAccessTokenAuthenticator.
+// Unknown, revoked and expired tokens all yield ErrInvalidPersonalAccessToken.
+func (u *UserService) AuthenticatePersonalAccessToken(ctx context.Context, token string) (*auth.UserClaims, error) {
+	row, err := u.userRepository.GetPersonalAccessTokenByHash(ctx, auth.HashPersonalAccessToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErrors.ErrInvalidPersonalAccessToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if row.RevokedAt.Valid || row.DisabledAt.Valid || (row.ExpiresAt.Valid && !row.ExpiresAt.Time.After(time.Now())) {
+		return nil, customErrors.ErrInvalidPersonalAccessToken
+	}
+	if err := u.userRepository.TouchPersonalAccessToken(ctx, row.ID); err != nil {
+		return nil, err
+	}
 
+	claims := &auth.UserClaims{
+		UserID:   int(row.UserID),
+		Username: row.Name,
+		Email:    row.Email,
+		Scopes:   append([]string{}, row.Scopes...),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID: strconv.FormatInt(row.ID, 10),
+		},
+	}
+	if row.ExpiresAt.Valid {
+		claims.ExpiresAt = jwt.NewNumericDate(row.ExpiresAt.Time)
+	}
+	return claims, nil
+}
 
 func IsErrorCode(err error, errcode pq.ErrorCode) bool {
-        if pgerr, ok := err.(*pq.Error); ok {
-                return pgerr.Code == errcode
-        }
-        return false
-}
\ No newline at end of file
+	if pgerr, ok := err.(*pq.Error); ok {
+		return pgerr.Code == errcode
+	}
+	return false
+}
@@ -2,9 +2,11 @@ package user
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	userdb "github.com/Gkemhcs/taskpilot/internal/user/gen"
@@ -178,6 +180,22 @@ func TestLoginUser(t *testing.T) {
 			},
 			expectedError:  customErrors.USER_NOT_FOUND,
 			expectedResult: nil,
+		}, {
+			name:           "Disabled user",
+			email:          "disabled@gmail.com",
+			password:       "gkemhcs",
+			hashedPassword: getHashedPassword("gkemhcs"),
+			mockSetup: func() {
+				mockRepo.On("GetUserByEmail", mock.Anything, "disabled@gmail.com").Return(
+					userdb.User{
+						Name:           "disabled",
+						HashedPassword: getHashedPassword("gkemhcs"),
+						Email:          "disabled@gmail.com",
+						DisabledAt:     sql.NullTime{Time: time.Now(), Valid: true},
+					}, nil)
+			},
+			expectedError:  customErrors.ErrAccountDisabled,
+			expectedResult: nil,
 		},
 	}
 
@@ -199,3 +217,32 @@ func TestLoginUser(t *testing.T) {
 	}
 
 }
+
+// fakeTransactor marks the context it runs fn with and remembers what fn returned.
+type fakeTransactor struct{ err error }
+
+type txKey struct{}
+
+func (f *fakeTransactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.err = fn(context.WithValue(ctx, txKey{}, true))
+	return f.err
+}
+
+func TestDisableTOTPInOneTransaction(t *testing.T) {
+	mockRepo := new(MockUserRepo)
+	userService := NewUserService(mockRepo)
+	transactor := &fakeTransactor{}
+	userService.UseTransactions(transactor)
+	inTx := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(txKey{}) != nil })
+	failed := errors.New("delete failed")
+	mockRepo.On("GetUserTOTP", inTx, int32(7)).Return(userdb.UserTotp{UserID: 7, Secret: "JBSWY3DPEHPK3PXP", ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: true}}, nil)
+	mockRepo.On("UseRecoveryCode", inTx, mock.Anything).Return(int64(1), nil)
+	mockRepo.On("DeleteUserTOTP", inTx, int32(7)).Return(nil)
+	mockRepo.On("DeleteRecoveryCodes", inTx, int32(7)).Return(failed)
+
+	// the used recovery code and the deleted secret are rolled back together
+	err := userService.DisableTOTP(context.TODO(), 7, "recovery-code")
+	assert.Equal(t, failed, err)
+	assert.Equal(t, failed, transactor.err, "the failed write must roll the transaction back")
+	mockRepo.AssertExpectations(t)
+}
@@ -2,7 +2,10 @@ package user
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
+	"github.com/Gkemhcs/taskpilot/internal/oidc"
 	userdb "github.com/Gkemhcs/taskpilot/internal/user/gen"
 )
 
@@ -17,6 +20,28 @@ type User struct {
 type IUserService interface {
 	CreateUser(ctx context.Context, username, password, email string) error
 	LoginUser(ctx context.Context, email, password string) (*userdb.User, error)
+	CreatePersonalAccessToken(ctx context.Context, userID int, name string, scopes []string, expiresAt *time.Time) (string, *userdb.PersonalAccessToken, error)
+	ListPersonalAccessTokens(ctx context.Context, userID int) ([]userdb.PersonalAccessToken, error)
+	RevokePersonalAccessToken(ctx context.Context, userID int, tokenID int64) error
+	LoginWithOIDC(ctx context.Context, identity *oidc.Identity) (*userdb.User, error)
+	EnrollTOTP(ctx context.Context, userID int) (string, string, error)
+	ConfirmTOTP(ctx context.Context, userID int, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID int, code string) error
+	IsMFAEnabled(ctx context.Context, userID int) (bool, error)
+	VerifyMFA(ctx context.Context, userID int, code string) (*userdb.User, error)
+	GetUserByID(ctx context.Context, userID int) (*userdb.User, error)
+	UpdateProfile(ctx context.Context, userID int, name *string, email *string, currentPassword string) (*userdb.User, error)
+	ChangePassword(ctx context.Context, userID int, currentPassword string, newPassword string) error
+	DeleteAccount(ctx context.Context, userID int, password string) error
+	SearchUsers(ctx context.Context, userID int, query string, limit int) ([]userdb.SearchUsersRow, error)
+}
+
+// IAccountService defines the emailed password reset and email verification flows.
+type IAccountService interface {
+	SendEmailVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, newPassword string) (int, error)
 }
 
 type UserResolver interface {
@@ -26,3 +51,122 @@ type UserResolver interface {
 type RefreshRequest struct {
 	RefreshToken string `json:"token"`
 }
+
+// CreatePersonalAccessTokenRequest is the request body for issuing a personal access token.
+type CreatePersonalAccessTokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"` // e.g. tasks:read, projects:write, import:write
+	ExpiresAt *time.Time `json:"expires_at"`                      // Optional, the token never expires when omitted
+}
+
+// PersonalAccessTokenResponse describes a personal access token without its secret.
+type PersonalAccessTokenResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // First characters of the token, to recognise it
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatePersonalAccessTokenResponse is returned once when a token is created;
+// Token is the only time the secret is shown.
+type CreatePersonalAccessTokenResponse struct {
+	Token string `json:"token"`
+	PersonalAccessTokenResponse
+}
+
+// NewPersonalAccessTokenResponse converts a stored token to its API representation.
+func NewPersonalAccessTokenResponse(token userdb.PersonalAccessToken) PersonalAccessTokenResponse {
+	nullTime := func(t sql.NullTime) *time.Time {
+		if !t.Valid {
+			return nil
+		}
+		return &t.Time
+	}
+	return PersonalAccessTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.TokenPrefix,
+		Scopes:     token.Scopes,
+		ExpiresAt:  nullTime(token.ExpiresAt),
+		LastUsedAt: nullTime(token.LastUsedAt),
+		RevokedAt:  nullTime(token.RevokedAt),
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+// EmailRequest is the request body for flows that start from an email address.
+type EmailRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// ResetPasswordRequest is the request body for completing a password reset.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// VerifyEmailRequest is the request body for confirming an email address.
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// MFACodeRequest carries a six digit TOTP code, or a recovery code where accepted.
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFALoginRequest is the second step of a login for accounts with TOTP enabled.
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"` // Challenge token returned by /users/login
+	Code     string `json:"code" binding:"required"`      // TOTP code or recovery code
+}
+
+// EnrollTOTPResponse holds the secret to add to an authenticator app.
+type EnrollTOTPResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// UserProfileResponse is the caller's own account as returned by /users/me.
+type UserProfileResponse struct {
+	ID            int32     `json:"id"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	MFAEnabled    bool      `json:"mfa_enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// NewUserProfileResponse converts a user row into its API representation.
+func NewUserProfileResponse(user userdb.User, mfaEnabled bool) UserProfileResponse {
+	return UserProfileResponse{
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		MFAEnabled:    mfaEnabled,
+		CreatedAt:     user.CreatedAt,
+	}
+}
+
+// UpdateProfileRequest is the request body for PATCH /users/me; omitted fields are kept.
+type UpdateProfileRequest struct {
+	Name            *string `json:"name"`
+	Email           *string `json:"email"`
+	CurrentPassword string  `json:"current_password"` // Required when changing the email
+}
+
+// ChangePasswordRequest is the request body for changing the password of a logged in user.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// DeleteAccountRequest confirms account deletion with the password.
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
@@ -18,4 +18,117 @@ SELECT * FROM users WHERE email=$1;
 DELETE FROM users WHERE id = $1;
 
 -- name: ListUsers :many
-SELECT * FROM users ORDER BY id;
\ No newline at end of file
+SELECT * FROM users ORDER BY id;
+
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens(user_id,name,token_hash,token_prefix,scopes,expires_at)
+VALUES ($1,$2,$3,$4,$5,$6) RETURNING *;
+
+-- name: ListPersonalAccessTokensByUser :many
+SELECT * FROM personal_access_tokens WHERE user_id=$1 ORDER BY created_at DESC, id DESC;
+
+-- name: GetPersonalAccessTokenByHash :one
+SELECT t.id, t.user_id, t.scopes, t.expires_at, t.revoked_at, u.name, u.email, u.disabled_at
+FROM personal_access_tokens t
+JOIN users u ON u.id = t.user_id
+WHERE t.token_hash=$1;
+
+-- name: TouchPersonalAccessToken :exec
+-- last_used_at is only refreshed once a minute to avoid a write on every request
+UPDATE personal_access_tokens SET last_used_at=now()
+WHERE id=$1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
+
+-- name: RevokePersonalAccessToken :execrows
+UPDATE personal_access_tokens SET revoked_at=now()
+WHERE id=$1 AND user_id=$2 AND revoked_at IS NULL;
+
+-- name: GetUserByIdentity :one
+SELECT u.* FROM users u
+JOIN user_identities i ON i.user_id = u.id
+WHERE i.issuer=$1 AND i.subject=$2;
+
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities(user_id,issuer,subject,email) VALUES ($1,$2,$3,$4) RETURNING *;
+
+-- name: CreateUserToken :one
+INSERT INTO user_tokens(user_id,purpose,token_hash,expires_at) VALUES ($1,$2,$3,$4) RETURNING *;
+
+-- name: InvalidateUserTokens :exec
+-- a newly mailed token supersedes every earlier unused token of the same purpose
+UPDATE user_tokens SET used_at=now() WHERE user_id=$1 AND purpose=$2 AND used_at IS NULL;
+
+-- name: ConsumeUserToken :one
+UPDATE user_tokens SET used_at=now()
+WHERE token_hash=$1 AND purpose=$2 AND used_at IS NULL AND expires_at > now()
+RETURNING user_id;
+
+-- name: MarkEmailVerified :exec
+UPDATE users SET email_verified_at=now() WHERE id=$1 AND email_verified_at IS NULL;
+
+-- name: UpdateUserPassword :exec
+UPDATE users SET hashed_password=$2 WHERE id=$1;
+
+-- name: UpsertUserTOTP :one
+-- re-enrolling replaces a pending secret but never one that is already confirmed
+INSERT INTO user_totp(user_id,secret) VALUES ($1,$2)
+ON CONFLICT (user_id) DO UPDATE SET secret=EXCLUDED.secret, last_used_step=0, created_at=now()
+WHERE user_totp.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetUserTOTP :one
+SELECT * FROM user_totp WHERE user_id=$1;
+
+-- name: ConfirmUserTOTP :execrows
+UPDATE user_totp SET confirmed_at=now(), last_used_step=$2 WHERE user_id=$1 AND confirmed_at IS NULL;
+
+-- name: UseTOTPStep :execrows
+-- only a time step newer than the last accepted one is accepted, so each code works once
+UPDATE user_totp SET last_used_step=$2
+WHERE user_id=$1 AND confirmed_at IS NOT NULL AND last_used_step < $2;
+
+-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp WHERE user_id=$1;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO user_recovery_codes(user_id,code_hash) VALUES ($1,$2);
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM user_recovery_codes WHERE user_id=$1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE user_recovery_codes SET used_at=now() WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL;
+
+-- name: UpdateUserProfile :one
+-- a changed email address has to be verified again
+UPDATE users SET
+    name=COALESCE(sqlc.narg('name'), name),
+    email=COALESCE(sqlc.narg('email'), email),
+    email_verified_at=CASE WHEN COALESCE(sqlc.narg('email'), email) = email THEN email_verified_at END
+WHERE id=sqlc.arg('id')
+RETURNING *;
+
+-- name: SearchUsers :many
+-- only enabled users who share an organization or a project with the viewer
+SELECT u.id, u.name, u.email FROM users u
+WHERE (lower(u.name) LIKE sqlc.arg('prefix') OR lower(u.email) LIKE sqlc.arg('prefix'))
+  AND u.disabled_at IS NULL
+  AND (
+    EXISTS (
+        SELECT 1 FROM organization_members mine
+        JOIN organization_members theirs ON theirs.organization_id = mine.organization_id
+        WHERE mine.user_id = sqlc.arg('viewer_id') AND theirs.user_id = u.id
+    )
+    OR EXISTS (
+        SELECT 1 FROM project_members mine
+        JOIN project_members theirs ON theirs.project_id = mine.project_id
+        WHERE mine.user_id = sqlc.arg('viewer_id') AND theirs.user_id = u.id
+    )
+  )
+ORDER BY u.name, u.id
+LIMIT sqlc.arg('limit');
+
+-- name: CountSharedOwnedProjects :one
+-- projects the user owns that other members would lose with the account
+SELECT COUNT(DISTINCT p.id) FROM projects p
+JOIN project_members m ON m.project_id = p.id
+WHERE p.user_id=$1 AND m.user_id <> $1;
@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"errors"
+	"net/http"
+
+	customErrors "github.com/Gkemhcs/taskpilot/internal/errors"
 	"github.com/gin-gonic/gin"
 )
 
@@ -31,3 +35,64 @@ func Error(c *gin.Context, statusCode int, message string) {
 		"message":    message,
 	})
 }
+
+// ErrorStatus maps authorization and lookup errors to 403/404 so every handler
+// reports them the same way. Any other error gets the fallback status code.
+func ErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, customErrors.ErrForbidden),
+		errors.Is(err, customErrors.ErrAccountDisabled),
+		errors.Is(err, customErrors.ErrAdminRequired),
+		errors.Is(err, customErrors.ErrNotCommentAuthor):
+		return http.StatusForbidden
+	case errors.Is(err, customErrors.ErrProjectIDNotExist),
+		errors.Is(err, customErrors.ErrTaskNotFound),
+		errors.Is(err, customErrors.ErrImportJobNotFound),
+		errors.Is(err, customErrors.ErrExportJobNotFound),
+		errors.Is(err, customErrors.ErrProjectMemberNotFound),
+		errors.Is(err, customErrors.ErrPersonalAccessTokenNotFound),
+		errors.Is(err, customErrors.ErrOrganizationNotFound),
+		errors.Is(err, customErrors.ErrOrganizationMemberNotFound),
+		errors.Is(err, customErrors.ErrCommentNotFound),
+		errors.Is(err, customErrors.ErrNotificationNotFound),
+		errors.Is(err, customErrors.ErrDependencyNotFound),
+		errors.Is(err, customErrors.ErrLabelNotFound),
+		errors.Is(err, customErrors.ErrCustomFieldNotFound),
+		errors.Is(err, customErrors.ErrTaskSeriesNotFound),
+		errors.Is(err, customErrors.ErrNoRunningTimer),
+		errors.Is(err, customErrors.ErrTimeEntryNotFound),
+		errors.Is(err, customErrors.ErrAttachmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, customErrors.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, customErrors.ErrAttachmentTypeNotAllowed):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, customErrors.ErrAttachmentsDisabled):
+		return http.StatusNotImplemented
+	case errors.Is(err, customErrors.ErrInvalidMFACode),
+		errors.Is(err, customErrors.ErrInvalidMFAChallenge):
+		return http.StatusUnauthorized
+	case errors.Is(err, customErrors.ErrAccountLocked):
+		return http.StatusLocked
+	case errors.Is(err, customErrors.ErrTooManyLoginAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, customErrors.ErrProjectMemberAlreadyExists),
+		errors.Is(err, customErrors.ErrMFAAlreadyEnabled),
+		errors.Is(err, customErrors.ErrOwnsSharedProjects),
+		errors.Is(err, customErrors.ErrOrganizationMemberAlreadyExists),
+		errors.Is(err, customErrors.ErrLastOrganizationOwner),
+		errors.Is(err, customErrors.ErrCannotModifySelf),
+		errors.Is(err, customErrors.ErrAdminAlreadyExists),
+		errors.Is(err, customErrors.ErrDependencyAlreadyExists),
+		errors.Is(err, customErrors.ErrTaskBlocked),
+		errors.Is(err, customErrors.ErrLabelAlreadyExists),
+		errors.Is(err, customErrors.ErrCustomFieldAlreadyExists),
+		errors.Is(err, customErrors.ErrStatusTransitionNotAllowed),
+		errors.Is(err, customErrors.ErrWorkflowStatusInUse),
+		errors.Is(err, customErrors.ErrTaskAlreadyRecurring),
+		errors.Is(err, customErrors.ErrTimerAlreadyRunning):
+		return http.StatusConflict
+	default:
+		return fallback
+	}
+}